@@ -13,12 +13,32 @@ const morgan = require('morgan');
 const {
 	indexRouter
 } = require('./route')
+const {
+	prefix,
+	contractRouter
+} = require('./route/contract')
+const {
+	adminRouter
+} = require('./route/admin')
+const {
+	explorerRouter
+} = require('./route/explorer')
+const {
+	attachEventStream
+} = require('./util/EventStream')
+const service = require('./service')
+const {
+	httpMetrics,
+	metricsHandler
+} = require('./util/Metrics')
 
 app.use(express.json())
 app.use(express.urlencoded({
 	extended: false
 }))
 app.use(morgan('dev'))
+app.use(httpMetrics)
+app.get('/metrics', metricsHandler)
 app.use(
 	"/api-docs",
 	swaggerUi.serve,
@@ -26,7 +46,12 @@ app.use(
 );
 
 app.use('/', indexRouter)
+app.use(prefix, contractRouter)
+app.use('/explorer', explorerRouter)
+app.use('/admin', adminRouter)
 
-app.listen(8080, () => {
+const server = app.listen(8080, () => {
 	console.log('8080 server online');
-})
\ No newline at end of file
+})
+
+attachEventStream(server, service)
\ No newline at end of file
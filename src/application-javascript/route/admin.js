@@ -0,0 +1,455 @@
+/*
+ * Admin dashboard backend. Every route requires an admin bearer token, is
+ * submitted with the admin's mapped Fabric identity and is written to the
+ * audit log.
+ */
+
+const express = require('express');
+const router = express.Router();
+const { getResult } = require('../util/MyUtil');
+const { codeOf, statusOf } = require('../util/Errors');
+const { audit, authenticate, contractFor, readAudit, toCSV } = require('../util/Admin');
+
+router.use(authenticate);
+
+function privileged(action, argsOf) {
+  return async (req, res) => {
+    let args;
+    try {
+      args = argsOf(req);
+    } catch (error) {
+      return res.status(400).json({ error: error.message });
+    }
+
+    try {
+      const result = await contractFor(req.admin).submitTransaction(action, args);
+      audit(req.admin, action, args);
+      return res.status(200).json(result && result.length ? getResult(true, result) : null);
+    } catch (error) {
+      audit(req.admin, action, args, error);
+      return res.status(statusOf(error)).json({ error: error.message, code: codeOf(error) });
+    }
+  };
+}
+
+function requireString(value, name) {
+  if (typeof value !== 'string' || value === '') throw new Error(`${name} must be a non-empty string`);
+  return value;
+}
+
+function optionalString(value, name) {
+  if (value === undefined) return '';
+  if (typeof value !== 'string') throw new Error(`${name} must be a string`);
+  return value;
+}
+
+function requireNumber(value, name) {
+  if (typeof value !== 'number') throw new Error(`${name} must be a number`);
+  return `${value}`;
+}
+
+function requireArray(value, name) {
+  if (!Array.isArray(value) || value.length === 0) throw new Error(`${name} must be a non-empty array`);
+  return JSON.stringify(value);
+}
+
+function requireBoolean(value, name) {
+  if (typeof value !== 'boolean') throw new Error(`${name} must be a boolean`);
+  return `${value}`;
+}
+
+// the mapped identity must be an org admin (certificate OU "admin")
+router.post(
+  '/initialize',
+  privileged('Initialize', () => [])
+);
+
+// stores accounts in hash-bucketed shards; the count cannot be changed later
+router.post(
+  '/initialize/sharded',
+  privileged('InitializeSharded', (req) => [requireNumber(req.body.shards, 'shards')])
+);
+
+// mints amount to the pool account every epoch, a Go duration such as
+// "24h"; anyone can then call MintEmission
+router.post(
+  '/initialize/emission',
+  privileged('InitializeWithEmission', (req) => [
+    requireString(req.body.pool, 'pool'),
+    requireNumber(req.body.amount, 'amount'),
+    requireString(req.body.epoch, 'epoch'),
+  ])
+);
+
+router.post(
+  '/user',
+  privileged('CreateUser', (req) => [
+    requireString(req.body.userId, 'userId'),
+    requireString(req.body.type, 'type'),
+    requireNumber(req.body.balance, 'balance'),
+  ])
+);
+
+// one chunk of at most 500 accounts; resubmitting a committed chunk is
+// harmless. Larger artifacts are loaded with cmd/tokenbootstrap.
+// The mapped identity must be an org admin (certificate OU "admin")
+router.post(
+  '/bootstrap',
+  privileged('BootstrapLedger', (req) => [requireArray(req.body.accounts, 'accounts')])
+);
+
+router.delete(
+  '/user/:userId',
+  privileged('DeleteUser', (req) => [requireString(req.params.userId, 'userId')])
+);
+
+router.put(
+  '/balance',
+  privileged('SetBalance', (req) => [requireString(req.body.userId, 'userId'), requireNumber(req.body.balance, 'balance')])
+);
+
+// the mapped identity must be an org admin (certificate OU "admin")
+router.put(
+  '/hot/:userId',
+  privileged('SetHotAccount', (req) => [requireString(req.params.userId, 'userId'), requireBoolean(req.body.hot, 'hot')])
+);
+
+router.post(
+  '/prune/:userId',
+  privileged('PruneDeltas', (req) => [requireString(req.params.userId, 'userId')])
+);
+
+// records already stored are converted with cmd/tokenmigrate.
+// The mapped identity must be an org admin (certificate OU "admin")
+router.put(
+  '/encoding',
+  privileged('SetRecordEncoding', (req) => [requireString(req.body.encoding, 'encoding')])
+);
+
+// PEM certificate of the admin whose cmd/tokenclone dumps this ledger imports
+router.put(
+  '/import-signer',
+  privileged('SetImportSigner', (req) => [requireString(req.body.certificate, 'certificate')])
+);
+
+// run once after `peer lifecycle chaincode commit` of a new version
+router.post(
+  '/upgrade',
+  privileged('PostUpgrade', () => [])
+);
+
+// end of the window in which the legacy Transfer event is still emitted, an
+// RFC 3339 time; an empty string ends it now
+router.put(
+  '/legacy-events',
+  privileged('SetLegacyEventWindow', (req) => {
+    if (typeof req.body.until !== 'string') throw new Error('until must be a string');
+    return [req.body.until];
+  })
+);
+
+// detail of transaction events: minimal, standard or verbose
+router.put(
+  '/event-verbosity',
+  privileged('SetEventVerbosity', (req) => [requireString(req.body.verbosity, 'verbosity')])
+);
+
+// transfers of more than the threshold emit LargeTransfer and are recorded
+// for compliance review; 0 stops flagging
+router.put(
+  '/large-transfer-threshold',
+  privileged('SetLargeTransferThreshold', (req) => [requireNumber(req.body.threshold, 'threshold')])
+);
+
+// transfers of more than the threshold must carry their originator and
+// beneficiary in the transient field travelRule; 0 requires none
+router.put(
+  '/travel-rule-threshold',
+  privileged('SetTravelRuleThreshold', (req) => [requireNumber(req.body.threshold, 'threshold')])
+);
+
+// transfers of more than the threshold wait for the recipient's or a
+// compliance officer's confirmation for at most window, a Go duration such
+// as "72h"; a 0 threshold and empty window turn confirmation off
+router.put(
+  '/confirmation-policy',
+  privileged('SetConfirmationPolicy', (req) => {
+    if (typeof req.body.window !== 'string') throw new Error('window must be a string');
+    return [requireNumber(req.body.threshold, 'threshold'), req.body.window];
+  })
+);
+
+// caps on transfers from an account; 0 for both limits removes them, the
+// policy is "reject" or "approve"
+router.put(
+  '/users/:userId/limits',
+  privileged('SetTransferLimit', (req) => [
+    req.params.userId,
+    requireNumber(req.body.maxTransfer, 'maxTransfer'),
+    requireNumber(req.body.dailyLimit, 'dailyLimit'),
+    requireString(req.body.policy, 'policy'),
+  ])
+);
+
+// lets an account's balance go negative by up to limit; 0 removes the
+// credit line
+router.put(
+  '/users/:userId/credit-line',
+  privileged('SetCreditLine', (req) => [req.params.userId, requireNumber(req.body.limit, 'limit')])
+);
+
+// places an account under parent, keeping its transfers within the tree
+// if restricted; an empty parent detaches it
+router.put(
+  '/users/:userId/parent',
+  privileged('SetParentAccount', (req) => [
+    req.params.userId,
+    optionalString(req.body.parent, 'parent'),
+    requireBoolean(req.body.restricted, 'restricted'),
+  ])
+);
+
+// freezes an account for a reason code, optionally until expiresAt, an
+// RFC 3339 time
+router.put(
+  '/users/:userId/suspension',
+  privileged('SuspendAccount', (req) => [
+    req.params.userId,
+    requireString(req.body.reason, 'reason'),
+    optionalString(req.body.note, 'note'),
+    optionalString(req.body.expiresAt, 'expiresAt'),
+  ])
+);
+
+router.delete(
+  '/users/:userId/suspension',
+  privileged('LiftSuspension', (req) => [req.params.userId])
+);
+
+router.put(
+  '/users/:userId/suspension/appeal',
+  privileged('SetAppealNote', (req) => [req.params.userId, requireString(req.body.note, 'note')])
+);
+
+// accounts idle for dormancy can be flagged and, grace after, swept to
+// account; both are Go durations such as "720h", an empty dormancy turns
+// escheatment off
+router.put(
+  '/escheatment-policy',
+  privileged('SetEscheatmentPolicy', (req) => [
+    optionalString(req.body.dormancy, 'dormancy'),
+    optionalString(req.body.grace, 'grace'),
+    optionalString(req.body.account, 'account'),
+  ])
+);
+
+// sweeps a flagged dormant account to the escheatment account
+router.post(
+  '/users/:userId/escheat',
+  privileged('EscheatAccount', (req) => [req.params.userId])
+);
+
+// balances decay by rate basis points a year from their last change; a 0
+// rate turns demurrage off
+router.put(
+  '/demurrage',
+  privileged('SetDemurrage', (req) => [requireNumber(req.body.rate, 'rate')])
+);
+
+// accounts of the type earn rate basis points a year from when interest
+// last accrued on them; a 0 rate stops them earning
+router.put(
+  '/interest/:accountType',
+  privileged('SetInterest', (req) => [req.params.accountType, requireNumber(req.body.rate, 'rate')])
+);
+
+// settles the interest due on an account
+router.post(
+  '/users/:userId/interest',
+  privileged('AccrueInterest', (req) => [req.params.userId])
+);
+
+// records the balances of accounts, an array of account ids, as the
+// snapshot's weights
+router.post(
+  '/snapshots/:snapshotId',
+  privileged('TakeSnapshot', (req) => {
+    if (!Array.isArray(req.body.accounts)) throw new Error('accounts must be an array');
+    return [req.params.snapshotId, JSON.stringify(req.body.accounts)];
+  })
+);
+
+// pays the next chunk of a distribution of amount from source over a
+// snapshot; repeat until the response is done
+router.post(
+  '/snapshots/:snapshotId/distribute',
+  privileged('DistributeProRata', (req) => [
+    requireString(req.body.source, 'source'),
+    requireNumber(req.body.amount, 'amount'),
+    req.params.snapshotId,
+  ])
+);
+
+// opens the airdrop of the entitlements with merkle root, hex encoded, paid
+// from source; an empty root closes it
+router.put(
+  '/airdrop',
+  privileged('PublishAirdrop', (req) => [
+    optionalString(req.body.root, 'root'),
+    optionalString(req.body.source, 'source'),
+  ])
+);
+
+// publishes the whitelist of the accounts with merkle root, hex encoded; an
+// empty root removes it
+router.put(
+  '/whitelists/:name',
+  privileged('PublishWhitelist', (req) => [req.params.name, optionalString(req.body.root, 'root')])
+);
+
+// pays bonus from pool to the referrer of an account on its first transfer
+// of at least minTransfer; cap and maxPerReferrer are 0 for no limit, a 0
+// bonus ends the program
+router.put(
+  '/referral-program',
+  privileged('SetReferralProgram', (req) => [
+    optionalString(req.body.pool, 'pool'),
+    requireNumber(req.body.bonus, 'bonus'),
+    requireNumber(req.body.minTransfer || 0, 'minTransfer'),
+    requireNumber(req.body.cap || 0, 'cap'),
+    requireNumber(req.body.maxPerReferrer || 0, 'maxPerReferrer'),
+  ])
+);
+
+// credits buyers rate basis points of transfers to seller accounts from
+// pool; a 0 rate ends cashback
+router.put(
+  '/cashback',
+  privileged('SetCashback', (req) => [optionalString(req.body.pool, 'pool'), requireNumber(req.body.rate, 'rate')])
+);
+
+// names the account a seller's payouts go to
+router.put(
+  '/users/:userId/settlement-account',
+  privileged('SetSettlementAccount', (req) => [req.params.userId, requireString(req.body.account, 'account')])
+);
+
+// pays out the balances of sellers, an array of seller accounts, for the
+// period; a seller is paid once per period
+router.post(
+  '/settlements/:period',
+  privileged('SettleSellers', (req) => [req.params.period, requireArray(req.body.sellers, 'sellers')])
+);
+
+// registers a currency besides the base token, with no supply
+router.post(
+  '/currencies/:symbol',
+  privileged('RegisterCurrency', (req) => [req.params.symbol, requireString(req.body.name, 'name')])
+);
+
+// mints amount of a currency to account, or burns it from account
+router.post(
+  '/currencies/:symbol/mint',
+  privileged('MintCurrency', (req) => [
+    req.params.symbol,
+    requireString(req.body.account, 'account'),
+    requireNumber(req.body.amount, 'amount'),
+  ])
+);
+
+router.post(
+  '/currencies/:symbol/burn',
+  privileged('BurnCurrency', (req) => [
+    req.params.symbol,
+    requireString(req.body.account, 'account'),
+    requireNumber(req.body.amount, 'amount'),
+  ])
+);
+
+// publishes the rate of a currency in another, scaled by 1000000
+router.put(
+  '/rates/:fromCurrency/:toCurrency',
+  privileged('SetRate', (req) => [req.params.fromCurrency, req.params.toCurrency, requireNumber(req.body.rate, 'rate')])
+);
+
+// conversions keep spread basis points of what they would mint
+router.put(
+  '/conversion-spread',
+  privileged('SetConversionSpread', (req) => [requireNumber(req.body.spread, 'spread')])
+);
+
+// attests the reserves backing a currency in a period, with the auditor and
+// the hex encoded SHA-256 of its report
+router.post(
+  '/currencies/:symbol/attestations/:period',
+  privileged('AttestReserves', (req) => [
+    req.params.symbol,
+    req.params.period,
+    requireNumber(req.body.amount, 'amount'),
+    requireString(req.body.auditor, 'auditor'),
+    requireString(req.body.reportHash, 'reportHash'),
+  ])
+);
+
+// scales every balance by factorNumerator/factorDenominator
+router.post(
+  '/rebase',
+  privileged('Rebase', (req) => [
+    requireNumber(req.body.factorNumerator, 'factorNumerator'),
+    requireNumber(req.body.factorDenominator, 'factorDenominator'),
+  ])
+);
+
+// makes an account the treasury of the admin's organization, paying up to
+// threshold without approval
+router.put(
+  '/treasuries/:account',
+  privileged('SetTreasury', (req) => [req.params.account, requireNumber(req.body.threshold, 'threshold')])
+);
+
+// executes or drops a treasury payment waiting for approval
+router.post(
+  '/treasury-payments/:paymentId/approve',
+  privileged('ApproveTreasuryPayment', (req) => [req.params.paymentId])
+);
+
+router.post(
+  '/treasury-payments/:paymentId/reject',
+  privileged('RejectTreasuryPayment', (req) => [req.params.paymentId])
+);
+
+// rejects every later call signed by a compromised certificate, named by
+// the SHA-256 fingerprint of its DER encoding, until it is reinstated
+router.put(
+  '/revoked-certificates/:fingerprint',
+  privileged('RevokeCertificate', (req) => [req.params.fingerprint, optionalString(req.body.reason, 'reason')])
+);
+
+router.delete(
+  '/revoked-certificates/:fingerprint',
+  privileged('ReinstateCertificate', (req) => [req.params.fingerprint])
+);
+
+// executes or drops a transfer submitted with RequestTransfer, identified
+// by the request's transaction id
+router.post(
+  '/transfer-requests/:requestId/approve',
+  privileged('ApproveTransfer', (req) => [req.params.requestId])
+);
+
+router.post(
+  '/transfer-requests/:requestId/reject',
+  privileged('RejectTransfer', (req) => [req.params.requestId])
+);
+
+// GET /admin/audit?from=2021-01-01T00:00:00Z&to=...&format=csv
+router.get('/audit', (req, res) => {
+  const entries = readAudit(req.query.from, req.query.to);
+  if (req.query.format === 'csv') {
+    res.set('Content-Type', 'text/csv');
+    return res.status(200).send(toCSV(entries));
+  }
+  return res.status(200).json(entries);
+});
+
+exports.adminRouter = router;
@@ -0,0 +1,64 @@
+/*
+ * Routes generated from openapi.json, which `go generate` in ../chaincode-go
+ * derives from the contract metadata. Each transaction gets one route, so the
+ * HTTP surface follows the chaincode without hand-written handlers. Queries
+ * are open; submits need an admin bearer token and are signed as that
+ * admin's identity and audited, as on the admin routes.
+ */
+
+const express = require('express');
+const router = express.Router();
+const { getResult } = require('../util/MyUtil');
+const { statusOf } = require('../util/Errors');
+const { audit, authenticate, contractFor } = require('../util/Admin');
+const spec = require('../openapi.json');
+
+const { evaluateTransaction } = require('../service.js');
+
+const prefix = '/api';
+
+function transactionArgs(operation, values) {
+  return operation['x-parameters'].map((name) => {
+    if (values[name] === undefined) throw new Error(`missing parameter ${name}`);
+    return typeof values[name] === 'string' ? values[name] : JSON.stringify(values[name]);
+  });
+}
+
+function handler(operation, invoke, valuesOf) {
+  return async (req, res) => {
+    let args;
+    try {
+      args = transactionArgs(operation, valuesOf(req));
+    } catch (error) {
+      return res.status(400).json(getResult(false, error.message));
+    }
+
+    try {
+      const result = await invoke(operation['x-transaction'], args, req);
+      return res.status(200).json(result && result.length ? getResult(true, result) : null);
+    } catch (error) {
+      return res.status(statusOf(error)).json(getResult(false, error.message));
+    }
+  };
+}
+
+async function submitAsAdmin(name, args, req) {
+  try {
+    const result = await contractFor(req.admin).submitTransaction(name, args);
+    audit(req.admin, name, args);
+    return result;
+  } catch (error) {
+    audit(req.admin, name, args, error);
+    throw error;
+  }
+}
+
+for (const [path, item] of Object.entries(spec.paths)) {
+  const route = path.substring(prefix.length);
+  if (item.get) router.get(route, handler(item.get, evaluateTransaction, (req) => req.query));
+  if (item.post) router.post(route, authenticate, handler(item.post, submitAsAdmin, (req) => req.body));
+}
+
+exports.prefix = prefix;
+exports.contractRouter = router;
+exports.spec = spec;
@@ -0,0 +1,24 @@
+/*
+ * Forwards /explorer requests to the explorer service (src/client/cmd/explorer),
+ * which serves them from its block index.
+ */
+
+const express = require('express');
+const router = express.Router();
+const axios = require('axios').default;
+
+const explorerUrl = process.env.EXPLORER_URL || 'http://localhost:8081';
+
+router.get('/*', async (req, res) => {
+  try {
+    const response = await axios.get(`${explorerUrl}/explorer${req.path}`, {
+      params: req.query,
+      validateStatus: () => true,
+    });
+    return res.status(response.status).json(response.data);
+  } catch (error) {
+    return res.status(502).json({ error: `explorer unavailable: ${error.message}` });
+  }
+});
+
+exports.explorerRouter = router;
@@ -7,14 +7,14 @@
 
 const express = require('express');
 const router = express.Router();
-const { getResult } = require('../util/MyUtil');
+const { getResult, statementToCSV } = require('../util/MyUtil');
 const axios = require('axios').default;
 
 // peer list
 const peerList = ['peer0.org1.example.com', 'peer0.org2.example.com'];
 
 // chaincode
-const { createUser, deleteUser, getTransaction, getUser, setBalance, transferFrom } = require('../service.js');
+const { createUser, deleteUser, generateStatement, getTransaction, getTransactionsByCorrelationId, getUser, setBalance, transferFrom } = require('../service.js');
 
 /**
  * @swagger
@@ -179,7 +179,7 @@ router.put('/balance', async (req, res) => {
  *      produces:
  *      - application/json
  *      requestBody:
- *        description: "Transaction object"
+ *        description: "Transaction object; a transfer over the travel rule threshold also needs travelRule, {originator, beneficiary} each with a name and optional address and id; an optional correlationId traces a flow across transactions"
  *        required: true
  *        content:
  *          application/json:
@@ -199,11 +199,14 @@ router.post('/transfer', async (req, res) => {
   try {
     if (typeof req.body['from'] !== 'string' || typeof req.body['to'] !== 'string' || typeof req.body['value'] !== 'number')
       throw new Error();
+    if (req.body['travelRule'] !== undefined && (typeof req.body['travelRule'] !== 'object' || req.body['travelRule'] === null))
+      throw new Error();
+    if (req.body['correlationId'] !== undefined && typeof req.body['correlationId'] !== 'string') throw new Error();
     const from = req.body.from;
     const to = req.body.to;
     const value = req.body.value;
 
-    let result = await transferFrom(from, to, value);
+    let result = await transferFrom(from, to, value, req.body.travelRule, req.body.correlationId);
     return res.status(200).json(getResult(true, result));
   } catch (error) {
     return res.status(400).json(getResult(false, error));
@@ -247,6 +250,90 @@ router.get('/transaction/:txId', async (req, res) => {
   }
 });
 
+/**
+ * @swagger
+ *  /correlation/{correlationId}/transactions:
+ *    get:
+ *      tags:
+ *      - APIs
+ *      description: correlation id 로 기록된 트렌젝션들을 가져온다.
+ *      summary: Get the transactions of a correlation id
+ *      produces:
+ *      - application/json
+ *      parameters:
+ *      - name: correlationId
+ *        in: path
+ *        description: "Correlation id passed with the transactions"
+ *        required: true
+ *        type: string
+ *      responses:
+ *        200:
+ *          description: Successful operation
+ *          content:
+ *            application/json:
+ *              schema:
+ *                type: array
+ *                items:
+ *                  $ref: '#/components/schemas/Transaction'
+ *        400:
+ *          description: Invalid params
+ */
+router.get('/correlation/:correlationId/transactions', async (req, res) => {
+  try {
+    let result = await getTransactionsByCorrelationId(req.params.correlationId);
+    return res.status(200).json(getResult(true, result));
+  } catch (error) {
+    return res.status(400).json(getResult(false, error));
+  }
+});
+
+/**
+ * @swagger
+ *  /user/{userId}/statement:
+ *    get:
+ *      tags:
+ *      - APIs
+ *      description: 기간 내 잔액 변동 내역을 반환한다. format=csv 이면 CSV
+ *      summary: Get an account statement
+ *      produces:
+ *      - application/json
+ *      - text/csv
+ *      parameters:
+ *      - name: userId
+ *        in: path
+ *        required: true
+ *      - name: from
+ *        in: query
+ *        description: first day, YYYY-MM-DD
+ *        required: true
+ *      - name: to
+ *        in: query
+ *        description: last day, YYYY-MM-DD
+ *        required: true
+ *      - name: format
+ *        in: query
+ *        description: json or csv
+ *      responses:
+ *        200:
+ *          description: Successful operation
+ *        400:
+ *          description: Invalid params
+ */
+router.get('/user/:userId/statement', async (req, res) => {
+  const { from, to, format } = req.query;
+
+  try {
+    const statement = getResult(true, await generateStatement(req.params.userId, from, to));
+    if (format === 'csv') {
+      res.set('Content-Type', 'text/csv');
+      return res.status(200).send(statementToCSV(statement));
+    }
+    return res.status(200).json(statement);
+  } catch (error) {
+    return res.status(400).json(getResult(false, error));
+  }
+});
+
 /**
  * @swagger
  *  /health-check:
@@ -313,15 +400,32 @@ exports.indexRouter = router;
  *        properties:
  *          txId:
  *            type: string
+ *          type:
+ *            type: string
+ *            enum: [transfer, mint, burn]
  *          from:
  *            type: string
  *          to:
  *            type: string
  *          value:
  *            type: integer
+ *          txTimestamp:
+ *            type: string
+ *            format: date-time
+ *          channelId:
+ *            type: string
+ *          initiatorMspId:
+ *            type: string
+ *          initiator:
+ *            type: string
  *        example:
  *          txId: 47552c4081e0cd919ecd4d090d07293376b686ebd11db20e4934c54a661080f5
+ *          type: transfer
  *          from: TestUser
  *          to: TestSeller
  *          value: 1000
+ *          txTimestamp: 2021-03-04T05:06:07.123456789Z
+ *          channelId: mychannel
+ *          initiatorMspId: Org1MSP
+ *          initiator: eDUwOTo6Q049VXNlcjFAb3JnMS5leGFtcGxlLmNvbQ==
  */
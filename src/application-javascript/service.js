@@ -23,8 +23,14 @@ exports.getUser = async function (userId) {
 };
 
 // func (s *SmartContract) TransferFrom(ctx contractapi.TransactionContextInterface, from string, to string, value int) (*Transaction, error)
-exports.transferFrom = async function (from, to, value) {
-  return await c.submitTransaction('TransferFrom', [from, to, value]);
+// travelRule, the originator and beneficiary of a transfer over the travel
+// rule threshold, and correlationId, tracing a flow across transactions, go
+// in the transient field
+exports.transferFrom = async function (from, to, value, travelRule, correlationId) {
+  const transient = {};
+  if (travelRule) transient.travelRule = Buffer.from(JSON.stringify(travelRule));
+  if (correlationId) transient.correlationId = Buffer.from(correlationId);
+  return await c.submitTransaction('TransferFrom', [from, to, value], Object.keys(transient).length ? transient : undefined);
 };
 
 // func (s *SmartContract) UserExist(ctx contractapi.TransactionContextInterface, id string) (bool, error)
@@ -36,3 +42,26 @@ exports.userExist = async function (userId) {
 exports.getTransaction = async function (txid) {
   return await c.evaluateTransaction('GetTransaction', [txid]);
 };
+
+// func (s *SmartContract) GetTransactionsByCorrelationID(ctx contractapi.TransactionContextInterface, id string) ([]*Transaction, error)
+exports.getTransactionsByCorrelationId = async function (correlationId) {
+  return await c.evaluateTransaction('GetTransactionsByCorrelationID', [correlationId]);
+};
+
+// func (s *SmartContract) GenerateStatement(ctx contractapi.TransactionContextInterface, account string, fromDate string, toDate string) (*Statement, error)
+exports.generateStatement = async function (userId, from, to) {
+  return await c.evaluateTransaction('GenerateStatement', [userId, from, to]);
+};
+
+// generic access used by the routes generated from openapi.json
+exports.submitTransaction = async function (name, args) {
+  return await c.submitTransaction(name, args);
+};
+
+exports.evaluateTransaction = async function (name, args) {
+  return await c.evaluateTransaction(name, args);
+};
+
+exports.addContractListener = async function (listener) {
+  return await c.addContractListener(listener);
+};
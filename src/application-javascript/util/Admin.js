@@ -0,0 +1,86 @@
+const crypto = require('crypto');
+const fs = require('fs');
+const path = require('path');
+const { Contract } = require('./Contract.js');
+
+// config/admins.json maps API tokens to dashboard admins and the wallet
+// identity their calls are signed with:
+// [{ "token": "...", "name": "alice", "identity": "admin" }]
+const adminsPath = path.resolve(__dirname, '..', 'config', 'admins.json');
+const auditPath = process.env.AUDIT_LOG || path.resolve(__dirname, '..', 'audit.log');
+
+let admins;
+const contracts = new Map();
+
+function loadAdmins() {
+  if (!admins) {
+    admins = fs.existsSync(adminsPath) ? JSON.parse(fs.readFileSync(adminsPath, 'utf8')) : [];
+  }
+  return admins;
+}
+
+// compares the digests of the tokens in constant time, so neither the
+// content nor the length of a token leaks through the response time
+function tokenMatches(token, expected) {
+  const digest = (value) => crypto.createHash('sha256').update(String(value)).digest();
+  return crypto.timingSafeEqual(digest(token), digest(expected));
+}
+
+// express middleware rejecting requests without a known admin bearer token
+exports.authenticate = function (req, res, next) {
+  const header = req.get('Authorization') || '';
+  const token = header.startsWith('Bearer ') ? header.substring('Bearer '.length) : '';
+  const admin = loadAdmins().find((a) => token && a.token && tokenMatches(token, a.token));
+  if (!admin) {
+    return res.status(401).json({ error: 'unauthorized' });
+  }
+
+  req.admin = { name: admin.name, identity: admin.identity };
+  next();
+};
+
+// contract connected as the admin's Fabric identity, so the chaincode sees
+// the admin's certificate rather than the shared application user
+exports.contractFor = function (admin) {
+  if (!contracts.has(admin.identity)) {
+    contracts.set(admin.identity, new Contract(admin.identity));
+  }
+  return contracts.get(admin.identity);
+};
+
+exports.audit = function (admin, action, args, error) {
+  const entry = {
+    time: new Date().toISOString(),
+    admin: admin.name,
+    identity: admin.identity,
+    action,
+    args,
+    result: error ? 'failure' : 'success',
+  };
+  if (error) entry.error = `${error.message || error}`;
+
+  fs.appendFileSync(auditPath, `${JSON.stringify(entry)}\n`);
+  console.log(`admin audit: ${JSON.stringify(entry)}`);
+};
+
+// entries between from and to (ISO timestamps, both optional)
+exports.readAudit = function (from, to) {
+  if (!fs.existsSync(auditPath)) return [];
+
+  return fs
+    .readFileSync(auditPath, 'utf8')
+    .split('\n')
+    .filter((line) => line)
+    .map((line) => JSON.parse(line))
+    .filter((entry) => (!from || entry.time >= from) && (!to || entry.time <= to));
+};
+
+exports.toCSV = function (entries) {
+  const columns = ['time', 'admin', 'identity', 'action', 'args', 'result', 'error'];
+  const escape = (value) => {
+    const text = value === undefined ? '' : typeof value === 'string' ? value : JSON.stringify(value);
+    return `"${text.replace(/"/g, '""')}"`;
+  };
+
+  return [columns.join(',')].concat(entries.map((entry) => columns.map((c) => escape(entry[c])).join(','))).join('\n');
+};
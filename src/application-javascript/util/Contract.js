@@ -12,16 +12,23 @@ const FabricCAServices = require('fabric-ca-client');
 const path = require('path');
 const { buildCAClient, registerAndEnrollUser, enrollAdmin } = require('./CAUtil.js');
 const { buildCCPOrg1, buildWallet } = require('./AppUtil.js');
+const metrics = require('./Metrics.js');
+const { isTransient } = require('./Errors.js');
 
 const channelName = 'mychannel';
 const chaincodeName = 'basic';
 const mspOrg1 = 'Org1MSP';
 const walletPath = path.join(__dirname, '../wallet');
 const org1UserId = 'appUser';
+// attempts for a submit that keeps failing with MVCC_READ_CONFLICT or
+// STATE_UNAVAILABLE
+const maxSubmitAttempts = 3;
 
 exports.Contract = class {
-  constructor() {
-    this.setup();
+  // identity is the wallet label transactions are signed with
+  constructor(identity = org1UserId) {
+    this.identity = identity;
+    this.ready = this.setup();
   }
 
   async setup() {
@@ -55,7 +62,7 @@ exports.Contract = class {
         // signed by this user using the credentials stored in the wallet.
         await gateway.connect(ccp, {
           wallet,
-          identity: org1UserId,
+          identity: this.identity,
           discovery: {
             enabled: true,
             asLocalhost: false,
@@ -67,21 +74,62 @@ exports.Contract = class {
 
         // Get the contract from the network.
         this.contract = network.getContract(chaincodeName);
-      } finally {
-        // Disconnect from the gateway when the application is closing
+        // The gateway stays connected so contract event listeners keep receiving events
+        this.gateway = gateway;
+      } catch (error) {
+        // Disconnect from the gateway when the setup failed
         // This will close all connections to the network
         gateway.disconnect();
+        throw error;
       }
     } catch (error) {
       console.error(`******** FAILED to run the application: ${error}`);
     }
   }
 
-  async submitTransaction(name, args) {
-    return await this.contract.submitTransaction(name, ...args);
+  // transient, if given, maps names to the Buffers passed in the proposal's
+  // transient field, kept off the ledger
+  async submitTransaction(name, args, transient) {
+    await this.ready;
+    for (let attempt = 1; ; attempt++) {
+      const end = metrics.submitDuration.startTimer({ transaction: name });
+      try {
+        const result = transient
+          ? await this.contract.createTransaction(name).setTransient(transient).submit(...args)
+          : await this.contract.submitTransaction(name, ...args);
+        end({ result: 'success' });
+        return result;
+      } catch (error) {
+        end({ result: 'failure' });
+        if (metrics.isMvccConflict(error)) {
+          metrics.mvccConflicts.inc({ transaction: name });
+        } else if (metrics.isEndorsementFailure(error)) {
+          metrics.endorsementFailures.inc({ transaction: name });
+        }
+        if (isTransient(error) && attempt < maxSubmitAttempts) {
+          metrics.retries.inc({ transaction: name });
+          continue;
+        }
+        throw error;
+      }
+    }
+  }
+
+  async addContractListener(listener) {
+    await this.ready;
+    return await this.contract.addContractListener(listener);
   }
 
   async evaluateTransaction(name, args) {
-    return await this.contract.evaluateTransaction(name, ...args);
+    await this.ready;
+    const end = metrics.evaluateDuration.startTimer({ transaction: name });
+    try {
+      const result = await this.contract.evaluateTransaction(name, ...args);
+      end({ result: 'success' });
+      return result;
+    } catch (error) {
+      end({ result: 'failure' });
+      throw error;
+    }
   }
 };
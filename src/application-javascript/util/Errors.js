@@ -0,0 +1,48 @@
+/*
+ * Maps chaincode errors to HTTP statuses. Coded contract errors carry their
+ * code in brackets, e.g. "[ACCOUNT_NOT_FOUND] user carol does not exist",
+ * and the SDK passes the message through inside its own error text. The
+ * codes are defined in chaincode-go/chaincode/errors.go.
+ */
+
+const statusByCode = {
+  INVALID_ARGUMENT: 400,
+  UNAUTHORIZED: 403,
+  ACCOUNT_NOT_FOUND: 404,
+  TRANSACTION_NOT_FOUND: 404,
+  ACCOUNT_EXISTS: 409,
+  NOT_INITIALIZED: 409,
+  ALREADY_INITIALIZED: 409,
+  INSUFFICIENT_BALANCE: 422,
+  BALANCE_OVERFLOW: 422,
+  LIMIT_EXCEEDED: 422,
+  ACCOUNT_FROZEN: 423,
+  CORRUPT_RECORD: 500,
+  INTERNAL: 500,
+  STATE_UNAVAILABLE: 503,
+};
+
+const codePattern = /\[([A-Z][A-Z_]*)\]/;
+
+// codeOf returns the contract error code in error's message, or undefined
+exports.codeOf = function (error) {
+  const match = codePattern.exec(`${error && error.message}`);
+  return match && statusByCode[match[1]] ? match[1] : undefined;
+};
+
+// isTransient reports whether a failed submit may succeed unchanged: an
+// MVCC conflict, or a world state failure (see chaincode.Transient).
+// Everything else is a rejection of the request itself.
+exports.isTransient = function (error) {
+  return exports.codeOf(error) === 'STATE_UNAVAILABLE' || `${error && error.message}`.includes('MVCC_READ_CONFLICT');
+};
+
+// statusOf returns the HTTP status for a failed transaction. Uncoded errors
+// are gateway or network failures, except MVCC conflicts left after the
+// submit retries, which the client may resubmit.
+exports.statusOf = function (error) {
+  const code = exports.codeOf(error);
+  if (code) return statusByCode[code];
+  if (`${error && error.message}`.includes('MVCC_READ_CONFLICT')) return 409;
+  return 500;
+};
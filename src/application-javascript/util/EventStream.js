@@ -0,0 +1,98 @@
+const WebSocket = require('ws');
+
+// token events pushed to browsers, the legacy Transfer and the typed events
+// that replace it, and BalanceChanged; other chaincode events are ignored
+const tokenEvents = [
+  'Transfer', 'Transferred', 'Minted', 'Burned', 'Bootstrapped', 'LargeTransfer', 'Cashback', 'CreditDrawn', 'CreditRepaid',
+  'Refunded', 'BalanceChanged',
+];
+// stop writing to a socket once this many bytes are queued
+const highWaterMark = 1024 * 1024;
+
+function decode(event) {
+  let payload;
+  try {
+    payload = JSON.parse(event.payload.toString());
+  } catch (error) {
+    return null;
+  }
+
+  return {
+    type: event.eventName,
+    txId: event.getTransactionEvent().transactionId,
+    blockNumber: event.getTransactionEvent().getBlockEvent().blockNumber.toString(),
+    from: payload.from,
+    to: payload.to,
+    value: payload.value,
+    // {account, before, after, deleted} of each account whose balance changed
+    balanceChanges: payload.balanceChanges || [],
+  };
+}
+
+function matches(client, message) {
+  if (client.accounts.size === 0) return true;
+  return client.accounts.has(message.from) || client.accounts.has(message.to) ||
+    message.balanceChanges.some((change) => client.accounts.has(change.account));
+}
+
+function send(client, message) {
+  if (client.socket.readyState !== WebSocket.OPEN) return;
+
+  // slow consumers lose events instead of growing the server's memory; they
+  // are told how many were dropped once their buffer drains
+  if (client.socket.bufferedAmount > highWaterMark) {
+    client.dropped++;
+    return;
+  }
+  if (client.dropped > 0) {
+    client.socket.send(JSON.stringify({ type: 'Dropped', count: client.dropped }));
+    client.dropped = 0;
+  }
+  client.socket.send(JSON.stringify(message));
+}
+
+function subscribe(client, data) {
+  let request;
+  try {
+    request = JSON.parse(data);
+  } catch (error) {
+    client.socket.send(JSON.stringify({ type: 'Error', message: 'invalid JSON' }));
+    return;
+  }
+
+  if (Array.isArray(request.subscribe)) request.subscribe.forEach((account) => client.accounts.add(`${account}`));
+  if (Array.isArray(request.unsubscribe)) request.unsubscribe.forEach((account) => client.accounts.delete(`${account}`));
+  client.socket.send(JSON.stringify({ type: 'Subscribed', accounts: Array.from(client.accounts) }));
+}
+
+// Serves /ws/events on server. Clients optionally filter by account with
+// ?account=alice&account=bob or by sending {"subscribe": ["alice"]} and
+// {"unsubscribe": ["alice"]}; without a filter every token event is pushed.
+exports.attachEventStream = function (server, contract) {
+  const wss = new WebSocket.Server({ server, path: '/ws/events' });
+  const clients = new Set();
+
+  wss.on('connection', (socket, req) => {
+    const url = new URL(req.url, 'http://localhost');
+    const client = { socket, accounts: new Set(url.searchParams.getAll('account')), dropped: 0 };
+    clients.add(client);
+
+    socket.on('message', (data) => subscribe(client, data));
+    socket.on('close', () => clients.delete(client));
+  });
+
+  contract.addContractListener(async (event) => {
+    if (!tokenEvents.includes(event.eventName)) return;
+
+    const message = decode(event);
+    if (!message) return;
+
+    clients.forEach((client) => {
+      if (matches(client, message)) send(client, message);
+    });
+  }).catch((error) => {
+    console.error(`******** FAILED to listen for contract events: ${error}`);
+  });
+
+  return wss;
+};
@@ -0,0 +1,77 @@
+const client = require('prom-client');
+
+const register = new client.Registry();
+client.collectDefaultMetrics({ register });
+
+const httpDuration = new client.Histogram({
+  name: 'token_http_request_duration_seconds',
+  help: 'REST API request latency',
+  labelNames: ['method', 'route', 'status'],
+  registers: [register],
+});
+
+const submitDuration = new client.Histogram({
+  name: 'token_submit_duration_seconds',
+  help: 'Latency of submitted chaincode transactions until commit',
+  labelNames: ['transaction', 'result'],
+  buckets: [0.1, 0.25, 0.5, 1, 2, 5, 10, 30],
+  registers: [register],
+});
+
+const evaluateDuration = new client.Histogram({
+  name: 'token_evaluate_duration_seconds',
+  help: 'Latency of evaluated chaincode queries',
+  labelNames: ['transaction', 'result'],
+  registers: [register],
+});
+
+const endorsementFailures = new client.Counter({
+  name: 'token_endorsement_failures_total',
+  help: 'Submitted transactions rejected during endorsement',
+  labelNames: ['transaction'],
+  registers: [register],
+});
+
+const mvccConflicts = new client.Counter({
+  name: 'token_mvcc_conflicts_total',
+  help: 'Submitted transactions invalidated with MVCC_READ_CONFLICT',
+  labelNames: ['transaction'],
+  registers: [register],
+});
+
+const retries = new client.Counter({
+  name: 'token_transaction_retries_total',
+  help: 'Submitted transactions retried after an MVCC conflict or STATE_UNAVAILABLE',
+  labelNames: ['transaction'],
+  registers: [register],
+});
+
+exports.register = register;
+exports.submitDuration = submitDuration;
+exports.evaluateDuration = evaluateDuration;
+exports.endorsementFailures = endorsementFailures;
+exports.mvccConflicts = mvccConflicts;
+exports.retries = retries;
+
+exports.isMvccConflict = function (error) {
+  return `${error && error.message}`.includes('MVCC_READ_CONFLICT');
+};
+
+exports.isEndorsementFailure = function (error) {
+  return Array.isArray(error && error.responses) || `${error && error.message}`.includes('endorsement');
+};
+
+// express middleware recording the latency of every request
+exports.httpMetrics = function (req, res, next) {
+  const end = httpDuration.startTimer();
+  res.on('finish', () => {
+    const route = req.route ? req.baseUrl + req.route.path : 'unmatched';
+    end({ method: req.method, route, status: res.statusCode });
+  });
+  next();
+};
+
+exports.metricsHandler = async function (req, res) {
+  res.set('Content-Type', register.contentType);
+  res.end(await register.metrics());
+};
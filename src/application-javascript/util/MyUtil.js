@@ -7,3 +7,18 @@ exports.getResult = function (isSuccess, rs, debug = 0) {
   if (debug) console.log(`Error: ${rs}`);
   return rs;
 };
+
+// statementToCSV renders a GenerateStatement result as CSV: one row per
+// line, between an opening and a closing balance row
+exports.statementToCSV = function (statement) {
+  const columns = ['txTimestamp', 'txId', 'type', 'counterparty', 'amount', 'balance'];
+  const escape = (value) => `"${`${value === undefined ? '' : value}`.replace(/"/g, '""')}"`;
+  const row = (line) => columns.map((c) => escape(line[c])).join(',');
+
+  return [
+    columns.join(','),
+    row({ txTimestamp: statement.from, type: 'opening', balance: statement.openingBalance }),
+    ...statement.lines.map(row),
+    row({ txTimestamp: statement.to, type: 'closing', balance: statement.closingBalance }),
+  ].join('\n');
+};
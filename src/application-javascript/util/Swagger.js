@@ -17,4 +17,12 @@ const options = {
   apis: ['./route/index.js'],
 };
 
-exports.specs = swaggerJsdoc(options);
+const specs = swaggerJsdoc(options);
+
+// merge the routes generated from the contract metadata
+const generated = require('../openapi.json');
+specs.paths = Object.assign({}, specs.paths, generated.paths);
+specs.components = specs.components || {};
+specs.components.schemas = Object.assign({}, specs.components.schemas, generated.components.schemas);
+
+exports.specs = specs;
@@ -0,0 +1,34 @@
+/*
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+'use strict';
+
+const { WorkloadModuleBase } = require('@hyperledger/caliper-core');
+const { accountId, createAccounts } = require('./helper');
+
+// Reads a random account, the contract's balance query
+class GetUserWorkload extends WorkloadModuleBase {
+	async initializeWorkloadModule(workerIndex, totalWorkers, roundIndex, roundArguments, sutAdapter, sutContext) {
+		await super.initializeWorkloadModule(workerIndex, totalWorkers, roundIndex, roundArguments, sutAdapter, sutContext);
+		await createAccounts(this.sutAdapter, this.roundArguments, this.workerIndex);
+	}
+
+	async submitTransaction() {
+		const index = Math.floor(Math.random() * this.roundArguments.accounts);
+
+		await this.sutAdapter.sendRequests({
+			contractId: this.roundArguments.contractId,
+			contractFunction: 'GetUser',
+			contractArguments: [accountId(this.workerIndex, index)],
+			invokerIdentity: this.roundArguments.invokerIdentity,
+			readOnly: true,
+		});
+	}
+}
+
+function createWorkloadModule() {
+	return new GetUserWorkload();
+}
+
+module.exports.createWorkloadModule = createWorkloadModule;
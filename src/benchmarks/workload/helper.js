@@ -0,0 +1,34 @@
+/*
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+'use strict';
+
+// accountId names the accounts created by a worker, so workers never share
+// (and never conflict on) the same keys unless a round asks for it
+exports.accountId = function (workerIndex, index) {
+	return `caliper_${workerIndex}_${index}`;
+};
+
+// createAccounts registers the round's accounts for this worker. Accounts
+// left over from an earlier run are reset to the initial balance.
+exports.createAccounts = async function (sutAdapter, args, workerIndex) {
+	for (let i = 0; i < args.accounts; i++) {
+		const id = exports.accountId(workerIndex, i);
+		const exists = await sutAdapter.sendRequests({
+			contractId: args.contractId,
+			contractFunction: 'UserExist',
+			contractArguments: [id],
+			invokerIdentity: args.invokerIdentity,
+			readOnly: true,
+		});
+
+		await sutAdapter.sendRequests({
+			contractId: args.contractId,
+			contractFunction: exists.GetStatus() === 'success' ? 'SetBalance' : 'CreateUser',
+			contractArguments: exists.GetStatus() === 'success' ? [id, `${args.initialBalance}`] : [id, 'user', `${args.initialBalance}`],
+			invokerIdentity: args.invokerIdentity,
+			readOnly: false,
+		});
+	}
+};
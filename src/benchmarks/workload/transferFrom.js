@@ -0,0 +1,41 @@
+/*
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+'use strict';
+
+const { WorkloadModuleBase } = require('@hyperledger/caliper-core');
+const { accountId, createAccounts } = require('./helper');
+
+// Transfers 1 token between random accounts of the worker. With
+// hotAccount set, every transfer debits account 0 instead, measuring the
+// MVCC conflict cost of a single hot sender.
+class TransferFromWorkload extends WorkloadModuleBase {
+	async initializeWorkloadModule(workerIndex, totalWorkers, roundIndex, roundArguments, sutAdapter, sutContext) {
+		await super.initializeWorkloadModule(workerIndex, totalWorkers, roundIndex, roundArguments, sutAdapter, sutContext);
+		await createAccounts(this.sutAdapter, this.roundArguments, this.workerIndex);
+	}
+
+	async submitTransaction() {
+		const accounts = this.roundArguments.accounts;
+		const from = this.roundArguments.hotAccount ? 0 : Math.floor(Math.random() * accounts);
+		let to = Math.floor(Math.random() * (accounts - 1));
+		if (to >= from) {
+			to++;
+		}
+
+		await this.sutAdapter.sendRequests({
+			contractId: this.roundArguments.contractId,
+			contractFunction: 'TransferFrom',
+			contractArguments: [accountId(this.workerIndex, from), accountId(this.workerIndex, to), '1'],
+			invokerIdentity: this.roundArguments.invokerIdentity,
+			readOnly: false,
+		});
+	}
+}
+
+function createWorkloadModule() {
+	return new TransferFromWorkload();
+}
+
+module.exports.createWorkloadModule = createWorkloadModule;
@@ -0,0 +1,55 @@
+package chaincode
+
+import (
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+	"github.com/kkiu1756/my_fabric/src/chaincode-go/validation"
+)
+
+// Account is everything a client needs to show an account, read in one
+// query instead of GetUser followed by PendingDeltas
+type Account struct {
+	ID   string `json:"userId"`
+	Type string `json:"type"`
+	// Balance is the spendable balance
+	Balance int `json:"balance"`
+	// Pending is the sum of the credits to a hot account that PruneDeltas
+	// has not yet folded into Balance, 0 for other accounts
+	Pending int  `json:"pending"`
+	Hot     bool `json:"hot"`
+}
+
+// GetAccount returns the account stored under id with its pending
+// credits. It reads the account record once and queries the deltas only
+// for a hot account.
+func (s *SmartContract) GetAccount(ctx contractapi.TransactionContextInterface, id string) (*Account, error) {
+	if err := validate(validation.ID("id", id)); err != nil {
+		return nil, err
+	}
+
+	user, err := GetUser(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	err = rebaseUser(ctx, user)
+	if err != nil {
+		return nil, err
+	}
+	err = withInterest(ctx, user)
+	if err != nil {
+		return nil, err
+	}
+	err = netOfDemurrage(ctx, user)
+	if err != nil {
+		return nil, err
+	}
+
+	account := &Account{ID: user.ID, Type: user.Type, Balance: user.Balance, Hot: user.Hot}
+	if user.Hot {
+		account.Pending, _, err = pendingDeltas(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return account, nil
+}
@@ -0,0 +1,43 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package chaincode_test
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/kkiu1756/my_fabric/src/chaincode-go/chaincode"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetAccount(t *testing.T) {
+	stub := newMockStub(t)
+	invokeTx(t, stub, "hot", "SetHotAccount", "bob", "true")
+	invokeTx(t, stub, "credit", "TransferFrom", "alice", "bob", "5")
+
+	var account chaincode.Account
+	require.NoError(t, json.Unmarshal(invokeTx(t, stub, "read", "GetAccount", "bob"), &account))
+	assert.Equal(t, chaincode.Account{ID: "bob", Type: "user", Balance: 20, Pending: 5, Hot: true}, account)
+
+	require.NoError(t, json.Unmarshal(invokeTx(t, stub, "read", "GetAccount", "alice"), &account))
+	assert.Equal(t, chaincode.Account{ID: "alice", Type: "user", Balance: 95}, account)
+}
+
+func TestGetAccountReadsOnce(t *testing.T) {
+	_, stub := newContext(ledger(t))
+	ctx := &chaincode.TransactionContext{}
+	ctx.SetStub(stub)
+
+	account, err := (&chaincode.SmartContract{}).GetAccount(ctx, "alice")
+	require.NoError(t, err)
+	assert.Equal(t, 100, account.Balance)
+	assert.Equal(t, 2, stub.GetStateCallCount(), "the configuration and the account record")
+	assert.Zero(t, stub.GetStateByPartialCompositeKeyCallCount(), "only hot accounts have deltas")
+
+	_, err = (&chaincode.SmartContract{}).GetAccount(ctx, "carol")
+	assert.True(t, errors.Is(err, chaincode.ErrAccountNotFound))
+}
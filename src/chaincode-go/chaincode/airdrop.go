@@ -0,0 +1,176 @@
+package chaincode
+
+import (
+	"crypto/sha256"
+	"strconv"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+	"github.com/kkiu1756/my_fabric/src/chaincode-go/validation"
+)
+
+// An org admin publishes an airdrop as the merkle root of its (account,
+// amount) entitlements and the account it is paid from. Each entitled
+// account holder then claims their amount once with ClaimAirdrop and a
+// proof of inclusion, so the airdrop writes nothing until it is claimed.
+//
+// A leaf is SHA-256(0x00 || account || 0x00 || amount in decimal), see
+// merkle.go for the tree. Claims are keyed by the root, so publishing a new
+// root starts a new airdrop.
+
+// airdropClaimObjectType keys the claims by root and account
+const airdropClaimObjectType = "airdropclaim"
+
+// Airdrop is the published airdrop
+type Airdrop struct {
+	// Root is the hex encoded merkle root of the entitlements
+	Root string `json:"root"`
+	// Source is the account claims are paid from
+	Source string `json:"source"`
+	// PublishedAt is the proposal timestamp of the publication, RFC 3339
+	PublishedAt string `json:"publishedAt"`
+}
+
+// PublishAirdrop opens the airdrop of the entitlements with merkle root,
+// hex encoded, paid from source, replacing the airdrop published before.
+// An empty root closes the airdrop. Only an org admin can call it.
+func (s *SmartContract) PublishAirdrop(ctx contractapi.TransactionContextInterface, root string, source string) (*Initialization, error) {
+	if root != "" {
+		err := validate(
+			validation.AccountID("source", source),
+			digestError("root", root),
+		)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if err := requireInitialized(ctx); err != nil {
+		return nil, err
+	}
+	if _, err := requireAdmin(ctx, "publish an airdrop"); err != nil {
+		return nil, err
+	}
+
+	init, err := ledgerConfig(ctx)
+	if err != nil {
+		return nil, err
+	}
+	init.Airdrop = nil
+	if root != "" {
+		if _, err := newWriteBatch(ctx).getUser(source); err != nil {
+			return nil, err
+		}
+		timestamp, err := txTimestamp(ctx.GetStub())
+		if err != nil {
+			return nil, err
+		}
+		init.Airdrop = &Airdrop{Root: root, Source: source, PublishedAt: timestamp}
+	}
+
+	err = putState(ctx, InitializationKey, init)
+	if err != nil {
+		return nil, err
+	}
+
+	return init, nil
+}
+
+// ClaimAirdrop pays the caller's account its entitlement of amount in the
+// published airdrop. proof is a JSON array of the hex encoded sibling
+// hashes from the entitlement's leaf to the root.
+func (s *SmartContract) ClaimAirdrop(ctx contractapi.TransactionContextInterface, amount int, proof string) (*Transaction, error) {
+	if err := validate(validation.Amount("amount", amount)); err != nil {
+		return nil, err
+	}
+	siblings, err := decodeMerkleProof(proof)
+	if err != nil {
+		return nil, err
+	}
+	if err := requireInitialized(ctx); err != nil {
+		return nil, err
+	}
+
+	init, err := ledgerConfig(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if init.Airdrop == nil {
+		return nil, newError(CodeInvalidArgument, "no airdrop is published")
+	}
+	caller, err := callerIdentity(ctx)
+	if err != nil {
+		return nil, err
+	}
+	account := caller.CommonName
+	if !isAccountHolder(ctx, account) {
+		return nil, newError(CodeUnauthorized, "only the holder of %s can claim its airdrop", account)
+	}
+
+	if merkleRoot(AirdropLeaf(account, amount), siblings) != init.Airdrop.Root {
+		return nil, newError(CodeUnauthorized, "the proof does not show %s is entitled to %d", account, amount)
+	}
+
+	batch := newWriteBatch(ctx)
+	key, err := ctx.GetStub().CreateCompositeKey(airdropClaimObjectType, []string{init.Airdrop.Root, account})
+	if err != nil {
+		return nil, err
+	}
+	claimed, err := batch.getState(key)
+	if err != nil {
+		return nil, err
+	}
+	if claimed != nil {
+		return nil, newError(CodeInvalidArgument, "%s has already claimed the airdrop", account)
+	}
+
+	transaction, err := transferHelper(batch, init.Airdrop.Source, account, amount, false)
+	if err != nil {
+		return nil, err
+	}
+	err = batch.putState(key, transaction.TXID)
+	if err != nil {
+		return nil, err
+	}
+
+	err = batch.flush()
+	if err != nil {
+		return nil, err
+	}
+
+	return transaction, nil
+}
+
+// AirdropClaimed reports whether account has claimed the published airdrop
+func (s *SmartContract) AirdropClaimed(ctx contractapi.TransactionContextInterface, account string) (bool, error) {
+	if err := validate(validation.ID("account", account)); err != nil {
+		return false, err
+	}
+
+	init, err := ledgerConfig(ctx)
+	if err != nil {
+		return false, err
+	}
+	if init == nil || init.Airdrop == nil {
+		return false, newError(CodeInvalidArgument, "no airdrop is published")
+	}
+	key, err := ctx.GetStub().CreateCompositeKey(airdropClaimObjectType, []string{init.Airdrop.Root, account})
+	if err != nil {
+		return false, err
+	}
+	data, err := getState(ctx, key)
+	if err != nil {
+		return false, err
+	}
+
+	return data != nil, nil
+}
+
+// AirdropLeaf returns the merkle leaf of the entitlement of account to
+// amount
+func AirdropLeaf(account string, amount int) []byte {
+	h := sha256.New()
+	h.Write([]byte{0})
+	h.Write([]byte(account))
+	h.Write([]byte{0})
+	h.Write([]byte(strconv.Itoa(amount)))
+	return h.Sum(nil)
+}
@@ -0,0 +1,107 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package chaincode_test
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"testing"
+
+	"github.com/kkiu1756/my_fabric/src/chaincode-go/chaincode"
+	"github.com/kkiu1756/my_fabric/src/chaincode-go/tokentest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// #########
+// HELPERS
+// #########
+
+// airdropLedger returns an admin's ledger with a pool of 100 and an
+// airdrop of 10 to alice, 20 to bob and 30 to carol from it, and the
+// proofs of their entitlements
+func airdropLedger(t *testing.T) (*tokentest.Ledger, map[string]string) {
+	l := adminLedger(t).
+		WithAccount("pool", "treasury", 100).
+		WithAccount("alice", "user", 0).
+		WithAccount("bob", "user", 0).
+		WithAccount("carol", "user", 0)
+
+	alice := chaincode.AirdropLeaf("alice", 10)
+	bob := chaincode.AirdropLeaf("bob", 20)
+	carol := chaincode.AirdropLeaf("carol", 30)
+	pair := chaincode.MerkleNode(alice, bob)
+	root := chaincode.MerkleNode(pair, carol)
+
+	_, err := (&chaincode.SmartContract{}).PublishAirdrop(l.Context, hex.EncodeToString(root), "pool")
+	require.NoError(t, err)
+	return l, map[string]string{
+		"alice": proofJSON(t, bob, carol),
+		"bob":   proofJSON(t, alice, carol),
+		"carol": proofJSON(t, pair),
+	}
+}
+
+func proofJSON(t *testing.T, hashes ...[]byte) string {
+	proof := make([]string, len(hashes))
+	for i, hash := range hashes {
+		proof[i] = hex.EncodeToString(hash)
+	}
+	data, err := json.Marshal(proof)
+	require.NoError(t, err)
+	return string(data)
+}
+
+// #########
+// TESTS
+// #########
+
+func TestClaimAirdrop(t *testing.T) {
+	l, proofs := airdropLedger(t)
+	contract := &chaincode.SmartContract{}
+
+	for account, amount := range map[string]int{"alice": 10, "bob": 20, "carol": 30} {
+		l.WithCaller("Org1MSP", account, "client").WithTxID("claim-" + account)
+		_, err := contract.ClaimAirdrop(l.Context, amount, proofs[account])
+		require.NoError(t, err)
+		l.AssertTransaction("claim-"+account, "pool", account, amount)
+		l.AssertBalance(account, amount)
+
+		claimed, err := contract.AirdropClaimed(l.Context, account)
+		require.NoError(t, err)
+		assert.True(t, claimed)
+	}
+	l.AssertBalance("pool", 40)
+
+	l.WithCaller("Org1MSP", "carol", "client")
+	_, err := contract.ClaimAirdrop(l.Context, 30, proofs["carol"])
+	assert.EqualError(t, err, "[INVALID_ARGUMENT] carol has already claimed the airdrop")
+}
+
+func TestClaimAirdropRejects(t *testing.T) {
+	l, proofs := airdropLedger(t)
+	contract := &chaincode.SmartContract{}
+	l.WithCaller("Org1MSP", "alice", "client")
+
+	_, err := contract.ClaimAirdrop(l.Context, 11, proofs["alice"])
+	assert.EqualError(t, err, "[UNAUTHORIZED] the proof does not show alice is entitled to 11")
+	_, err = contract.ClaimAirdrop(l.Context, 20, proofs["bob"])
+	assert.EqualError(t, err, "[UNAUTHORIZED] the proof does not show alice is entitled to 20")
+	_, err = contract.ClaimAirdrop(l.Context, 10, `["00"]`)
+	assert.EqualError(t, err, "[INVALID_ARGUMENT] invalid proof[0]: must be a hex encoded SHA-256 digest")
+
+	claimed, err := contract.AirdropClaimed(l.Context, "alice")
+	require.NoError(t, err)
+	assert.False(t, claimed)
+
+	_, err = contract.PublishAirdrop(l.Context, "", "")
+	assert.EqualError(t, err, "[UNAUTHORIZED] only an organization admin can publish an airdrop")
+	l.WithCaller("Org1MSP", "Admin@org1.example.com", chaincode.AdminOU)
+	_, err = contract.PublishAirdrop(l.Context, "", "")
+	require.NoError(t, err)
+	l.WithCaller("Org1MSP", "alice", "client")
+	_, err = contract.ClaimAirdrop(l.Context, 10, proofs["alice"])
+	assert.EqualError(t, err, "[INVALID_ARGUMENT] no airdrop is published")
+}
@@ -0,0 +1,196 @@
+package chaincode
+
+import (
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+	"github.com/kkiu1756/my_fabric/src/chaincode-go/validation"
+)
+
+// The holder of an account registers readable names for it, such as
+// "alice.sales", with RegisterAlias. An alias names one account and is
+// unique among aliases and account ids alike, so TransferFrom,
+// ProposeTransfer, RequestTransfer and TransferReversible take either for
+// their accounts and record the account id. The holder of the account an
+// alias names can hand it to another account with TransferAlias.
+
+// aliasObjectType keys the aliases by name
+const aliasObjectType = "alias"
+
+// Alias is a name of an account
+type Alias struct {
+	Name    string `json:"name"`
+	Account string `json:"account"`
+	// UpdatedAt is the proposal timestamp of the alias's registration or
+	// last transfer, RFC 3339
+	UpdatedAt string `json:"updatedAt"`
+}
+
+// RegisterAlias registers name as an alias of account. Only the holder of
+// account can call it.
+func (s *SmartContract) RegisterAlias(ctx contractapi.TransactionContextInterface, name string, account string) (*Alias, error) {
+	err := validate(
+		validation.AccountID("name", name),
+		validation.ID("account", account),
+	)
+	if err != nil {
+		return nil, err
+	}
+	if err := requireInitialized(ctx); err != nil {
+		return nil, err
+	}
+	if err := requireAccountHolder(ctx, account, "register its aliases"); err != nil {
+		return nil, err
+	}
+
+	batch := newWriteBatch(ctx)
+	key, existing, err := accountAlias(batch, name)
+	if err != nil {
+		return nil, err
+	}
+	if existing != nil {
+		return nil, newError(CodeInvalidArgument, "alias %s is taken", name)
+	}
+	idKey, err := accountKey(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	if record, err := batch.getState(idKey); err != nil || record != nil {
+		if err == nil {
+			err = newError(CodeInvalidArgument, "alias %s is the id of an account", name)
+		}
+		return nil, err
+	}
+	if _, err := batch.getUser(account); err != nil {
+		return nil, err
+	}
+
+	return putAlias(batch, key, &Alias{Name: name, Account: account})
+}
+
+// TransferAlias makes alias name an alias of account instead. Only the
+// holder of the account it names can call it.
+func (s *SmartContract) TransferAlias(ctx contractapi.TransactionContextInterface, name string, account string) (*Alias, error) {
+	err := validate(
+		validation.ID("name", name),
+		validation.ID("account", account),
+	)
+	if err != nil {
+		return nil, err
+	}
+	if err := requireInitialized(ctx); err != nil {
+		return nil, err
+	}
+
+	batch := newWriteBatch(ctx)
+	key, alias, err := registeredAlias(batch, name)
+	if err != nil {
+		return nil, err
+	}
+	if err := requireAccountHolder(ctx, alias.Account, "transfer its aliases"); err != nil {
+		return nil, err
+	}
+	if alias.Account == account {
+		return nil, newError(CodeInvalidArgument, "alias %s already names %s", name, account)
+	}
+	if _, err := batch.getUser(account); err != nil {
+		return nil, err
+	}
+	alias.Account = account
+
+	return putAlias(batch, key, alias)
+}
+
+// GetAlias returns the alias name
+func (s *SmartContract) GetAlias(ctx contractapi.TransactionContextInterface, name string) (*Alias, error) {
+	if err := validate(validation.ID("name", name)); err != nil {
+		return nil, err
+	}
+
+	_, alias, err := registeredAlias(newWriteBatch(ctx), name)
+	return alias, err
+}
+
+// resolveAccounts returns the account ids of from and to, each an account
+// id or an alias
+func resolveAccounts(ctx contractapi.TransactionContextInterface, from string, to string) (string, string, error) {
+	batch := newWriteBatch(ctx)
+	ids := []string{from, to}
+	for i, id := range ids {
+		_, alias, err := accountAlias(batch, id)
+		if err != nil {
+			return "", "", err
+		}
+		if alias != nil {
+			ids[i] = alias.Account
+		}
+	}
+
+	return ids[0], ids[1], nil
+}
+
+// putAlias stamps alias with the proposal timestamp and stores it
+func putAlias(batch *writeBatch, key string, alias *Alias) (*Alias, error) {
+	timestamp, err := txTimestamp(batch.ctx.GetStub())
+	if err != nil {
+		return nil, err
+	}
+	alias.UpdatedAt = timestamp
+	err = batch.putState(key, alias)
+	if err != nil {
+		return nil, err
+	}
+
+	err = batch.flush()
+	if err != nil {
+		return nil, err
+	}
+
+	return alias, nil
+}
+
+// accountAlias reads the alias name, nil if there is none, and returns its
+// key
+func accountAlias(batch *writeBatch, name string) (string, *Alias, error) {
+	key, err := batch.ctx.GetStub().CreateCompositeKey(aliasObjectType, []string{name})
+	if err != nil {
+		return "", nil, err
+	}
+	data, err := batch.getState(key)
+	if err != nil || data == nil {
+		return key, nil, err
+	}
+
+	var alias Alias
+	err = decodeRecord(key, data, &alias, "name", "account")
+	if err != nil {
+		return "", nil, err
+	}
+
+	return key, &alias, nil
+}
+
+// requireNoAlias fails if id is the name of an alias, which an account
+// cannot take
+func requireNoAlias(batch *writeBatch, id string) error {
+	_, alias, err := accountAlias(batch, id)
+	if err != nil {
+		return err
+	}
+	if alias != nil {
+		return newError(CodeAccountExists, "%s is an alias of %s", id, alias.Account)
+	}
+
+	return nil
+}
+
+// registeredAlias is accountAlias failing if there is none
+func registeredAlias(batch *writeBatch, name string) (string, *Alias, error) {
+	key, alias, err := accountAlias(batch, name)
+	if err == nil && alias == nil {
+		err = newError(CodeInvalidArgument, "alias %s is not registered", name)
+	}
+	if err != nil {
+		return "", nil, err
+	}
+
+	return key, alias, nil
+}
@@ -0,0 +1,53 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package chaincode_test
+
+import (
+	"testing"
+
+	"github.com/kkiu1756/my_fabric/src/chaincode-go/chaincode"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAlias(t *testing.T) {
+	l := adminLedger(t).
+		WithAccount("alice", "user", 100).
+		WithAccount("alice2", "user", 0).
+		WithAccount("bob", "user", 0)
+	contract := &chaincode.SmartContract{}
+
+	l.WithCaller("Org1MSP", "alice", "client")
+	_, err := contract.RegisterAlias(l.Context, "alice.sales", "alice")
+	require.NoError(t, err)
+
+	l.WithTxID("pay")
+	transaction, err := contract.TransferFrom(l.Context, "alice.sales", "bob", 30)
+	require.NoError(t, err)
+	assert.Equal(t, "alice", transaction.From, "the transaction records the account id")
+	l.AssertBalance("alice", 70)
+
+	l.WithCaller("Org1MSP", "bob", "client")
+	_, err = contract.RegisterAlias(l.Context, "alice.sales", "bob")
+	assert.EqualError(t, err, "[INVALID_ARGUMENT] alias alice.sales is taken")
+	_, err = contract.RegisterAlias(l.Context, "alice", "bob")
+	assert.EqualError(t, err, "[INVALID_ARGUMENT] alias alice is the id of an account")
+	_, err = contract.TransferAlias(l.Context, "alice.sales", "bob")
+	assert.EqualError(t, err, "[UNAUTHORIZED] only the holder of alice can transfer its aliases")
+
+	l.WithCaller("Org1MSP", "alice", "client")
+	alias, err := contract.TransferAlias(l.Context, "alice.sales", "alice2")
+	require.NoError(t, err)
+	assert.Equal(t, "alice2", alias.Account)
+	_, err = contract.TransferFrom(l.Context, "bob", "alice.sales", 10)
+	require.NoError(t, err)
+	l.AssertBalance("alice2", 10)
+
+	l.WithCaller("Org1MSP", "Admin@org1.example.com", chaincode.AdminOU)
+	_, err = contract.CreateUser(l.Context, "alice.sales", "user", 0)
+	assert.EqualError(t, err, "[ACCOUNT_EXISTS] alice.sales is an alias of alice2")
+	_, err = contract.GetAlias(l.Context, "bob.sales")
+	assert.EqualError(t, err, "[INVALID_ARGUMENT] alias bob.sales is not registered")
+}
@@ -0,0 +1,24 @@
+package chaincode
+
+// Bounds of int; math.MaxInt needs Go 1.17
+const (
+	maxInt = int(^uint(0) >> 1)
+	minInt = -maxInt - 1
+)
+
+// addBalance returns a + b, or false if the sum does not fit in an int
+func addBalance(a int, b int) (int, bool) {
+	if (b > 0 && a > maxInt-b) || (b < 0 && a < minInt-b) {
+		return 0, false
+	}
+	return a + b, true
+}
+
+// subBalance returns a - b, or false if the difference does not fit in an
+// int
+func subBalance(a int, b int) (int, bool) {
+	if (b < 0 && a > maxInt+b) || (b > 0 && a < minInt+b) {
+		return 0, false
+	}
+	return a - b, true
+}
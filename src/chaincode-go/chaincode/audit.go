@@ -0,0 +1,157 @@
+package chaincode
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+	"github.com/hyperledger/fabric-protos-go/ledger/queryresult"
+	"github.com/hyperledger/fabric-protos-go/peer"
+	"github.com/kkiu1756/my_fabric/src/chaincode-go/validation"
+)
+
+// Every action changing an account other than a transfer or a prune
+// appends an AuditRecord to the account's trail, under the key
+// auditObjectType~account~seq. No method rewrites or deletes audit records,
+// and the trail outlives the account. The next seq of each account is kept
+// under auditSeqObjectType~account.
+const (
+	auditObjectType    = "audit"
+	auditSeqObjectType = "auditseq"
+)
+
+// Audited actions
+const (
+	AuditCreate     = "create"
+	AuditSetBalance = "setBalance"
+	AuditDelete     = "delete"
+	AuditSetHot     = "setHot"
+	AuditBootstrap  = "bootstrap"
+	AuditSetLimit   = "setLimit"
+	// AuditApproveTransfer records an admin executing a transfer over the
+	// sender's limits, see limits.go
+	AuditApproveTransfer = "approveTransfer"
+	// AuditSuspend and AuditLiftSuspension record the freezing and
+	// unfreezing of an account, see suspend.go
+	AuditSuspend        = "suspend"
+	AuditLiftSuspension = "liftSuspension"
+	// AuditFlagDormant, AuditClearDormant and AuditEscheat record the
+	// escheatment of a dormant account, see escheat.go
+	AuditFlagDormant  = "flagDormant"
+	AuditClearDormant = "clearDormant"
+	AuditEscheat      = "escheat"
+	// AuditSetCreditLine records an admin changing an account's credit
+	// line, see credit.go
+	AuditSetCreditLine = "setCreditLine"
+)
+
+// AuditRecord is one entry of an account's audit trail
+type AuditRecord struct {
+	Account string `json:"account"`
+	// Seq numbers the account's records from 0
+	Seq    int    `json:"seq"`
+	Action string `json:"action"`
+	// Before and After are the account's balance around the action
+	Before int `json:"before"`
+	After  int `json:"after"`
+	// Detail describes a change other than the balance
+	Detail string `json:"detail,omitempty" metadata:"detail,optional"`
+	TXID   string `json:"txId"`
+	// Timestamp is the client's proposal timestamp in RFC 3339 format
+	Timestamp string `json:"txTimestamp"`
+	// ActorMSPID and Actor identify the submitting client, as in Transaction
+	ActorMSPID string `json:"actorMspId"`
+	Actor      string `json:"actor"`
+}
+
+// AuditPage is one page of an account's audit trail
+type AuditPage struct {
+	Records []*AuditRecord `json:"records"`
+	// Bookmark continues the trail, empty after its last page
+	Bookmark string `json:"bookmark"`
+}
+
+// appendAudit buffers the next record of account's audit trail
+func appendAudit(batch *writeBatch, account string, action string, before int, after int, detail string) error {
+	stub := batch.ctx.GetStub()
+	seqKey, err := stub.CreateCompositeKey(auditSeqObjectType, []string{account})
+	if err != nil {
+		return err
+	}
+	data, err := batch.getState(seqKey)
+	if err != nil {
+		return err
+	}
+	seq := 0
+	if data != nil {
+		if err := json.Unmarshal(data, &seq); err != nil {
+			return corrupt(seqKey, err)
+		}
+	}
+
+	timestamp, err := txTimestamp(stub)
+	if err != nil {
+		return err
+	}
+	record := AuditRecord{
+		Account:    account,
+		Seq:        seq,
+		Action:     action,
+		Before:     before,
+		After:      after,
+		Detail:     detail,
+		TXID:       stub.GetTxID(),
+		Timestamp:  timestamp,
+		ActorMSPID: creatorMSPID(stub),
+		Actor:      creatorID(stub),
+	}
+
+	key, err := stub.CreateCompositeKey(auditObjectType, []string{account, fmt.Sprintf("%010d", seq)})
+	if err != nil {
+		return err
+	}
+	err = batch.putState(key, record)
+	if err != nil {
+		return err
+	}
+
+	return batch.putState(seqKey, seq+1)
+}
+
+// GetAuditTrail returns a page of at most pageSize records of account's
+// audit trail, oldest first, from bookmark, which is empty for the first
+// page
+func (s *SmartContract) GetAuditTrail(ctx contractapi.TransactionContextInterface, account string, pageSize int, bookmark string) (*AuditPage, error) {
+	if err := validate(validation.ID("account", account)); err != nil {
+		return nil, err
+	}
+	pageSize, err := queryPolicy.pageSize(pageSize)
+	if err != nil {
+		return nil, err
+	}
+
+	stub := ctx.GetStub()
+	query := func(pageSize int32, bookmark string) (shim.StateQueryIteratorInterface, *peer.QueryResponseMetadata, error) {
+		return stub.GetStateByPartialCompositeKeyWithPagination(auditObjectType, []string{account}, pageSize, bookmark)
+	}
+
+	page := &AuditPage{Records: []*AuditRecord{}}
+	page.Bookmark, err = queryPolicy.scan(query, account, pageSize, bookmark, page.add)
+	if err != nil {
+		return nil, err
+	}
+
+	return page, nil
+}
+
+// add decodes an audit record of the trail and adds it to the page
+func (page *AuditPage) add(kv *queryresult.KV) error {
+	var record AuditRecord
+	err := decodeRecord(kv.Key, kv.Value, &record, "account", "seq", "action", "txId")
+	if err != nil {
+		return err
+	}
+	page.Records = append(page.Records, &record)
+	return nil
+}
@@ -0,0 +1,119 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package chaincode_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+	"github.com/kkiu1756/my_fabric/src/chaincode-go/chaincode"
+	"github.com/kkiu1756/my_fabric/src/chaincode-go/tokentest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// #########
+// HELPERS
+// #########
+
+// auditTrail reads account's whole trail, a page of pageSize at a time
+func auditTrail(t *testing.T, l *tokentest.Ledger, account string, pageSize int) []*chaincode.AuditRecord {
+	var records []*chaincode.AuditRecord
+	bookmark := ""
+	for {
+		page, err := (&chaincode.SmartContract{}).GetAuditTrail(l.Context, account, pageSize, bookmark)
+		require.NoError(t, err)
+		records = append(records, page.Records...)
+		if page.Bookmark == "" {
+			return records
+		}
+		bookmark = page.Bookmark
+	}
+}
+
+// #########
+// TESTS
+// #########
+
+func TestAuditTrail(t *testing.T) {
+	l := adminLedger(t).WithTimestamp(time.Date(2021, 3, 1, 12, 0, 0, 0, time.UTC))
+	paginate(l.Stub, l.State)
+	l.Stub.GetStateByPartialCompositeKeyStub = func(objectType string, attributes []string) (shim.StateQueryIteratorInterface, error) {
+		it, _, err := l.Stub.GetStateByPartialCompositeKeyWithPagination(objectType, attributes, 1000, "")
+		return it, err
+	}
+	contract := &chaincode.SmartContract{}
+
+	_, err := contract.CreateUser(l.Context, "alice", "user", 100)
+	require.NoError(t, err)
+	l.WithTxID("tx2")
+	_, err = contract.SetBalance(l.Context, "alice", 40)
+	require.NoError(t, err)
+	l.WithTxID("tx3")
+	_, err = contract.SetHotAccount(l.Context, "alice", true)
+	require.NoError(t, err)
+	l.WithTxID("tx4")
+	require.NoError(t, contract.DeleteUser(l.Context, "alice"))
+	l.WithTxID("tx5")
+	_, err = contract.CreateUser(l.Context, "alice", "merchant", 5)
+	require.NoError(t, err)
+
+	records := auditTrail(t, l, "alice", 2)
+	require.Len(t, records, 5, "the trail outlives the deleted account")
+	var actions []string
+	for i, record := range records {
+		assert.Equal(t, i, record.Seq)
+		assert.Equal(t, "alice", record.Account)
+		assert.Equal(t, "Org1MSP", record.ActorMSPID)
+		assert.NotEmpty(t, record.Actor)
+		assert.Equal(t, "2021-03-01T12:00:00Z", record.Timestamp)
+		actions = append(actions, record.Action)
+	}
+	assert.Equal(t, []string{chaincode.AuditCreate, chaincode.AuditSetBalance, chaincode.AuditSetHot, chaincode.AuditDelete, chaincode.AuditCreate}, actions)
+	assert.Equal(t, chaincode.AuditRecord{
+		Account: "alice", Seq: 1, Action: chaincode.AuditSetBalance, Before: 100, After: 40, TXID: "tx2",
+		Timestamp: "2021-03-01T12:00:00Z", ActorMSPID: records[1].ActorMSPID, Actor: records[1].Actor,
+	}, *records[1])
+	assert.Equal(t, "hot", records[2].Detail)
+	assert.Equal(t, 40, records[3].Before)
+	assert.Equal(t, 0, records[3].After)
+	assert.Equal(t, "tx5", records[4].TXID)
+
+	assert.Empty(t, auditTrail(t, l, "bob", 0), "an account never created has an empty trail")
+}
+
+func TestAuditTrailTransfers(t *testing.T) {
+	l := adminLedger(t)
+	paginate(l.Stub, l.State)
+	contract := &chaincode.SmartContract{}
+	for _, id := range []string{"alice", "bob"} {
+		_, err := contract.CreateUser(l.Context, id, "user", 100)
+		require.NoError(t, err)
+	}
+
+	_, err := contract.TransferFrom(l.Context, "alice", "bob", 30)
+	require.NoError(t, err)
+	assert.Len(t, auditTrail(t, l, "alice", 0), 1, "a transfer is in the transaction records, not the trail")
+}
+
+func TestAuditTrailBootstrap(t *testing.T) {
+	l := adminLedger(t)
+	paginate(l.Stub, l.State)
+	contract := &chaincode.SmartContract{}
+
+	_, err := contract.BootstrapLedger(l.Context, bootstrapJSON(t, chaincode.User{ID: "treasury", Type: "issuer", Balance: 1000}))
+	require.NoError(t, err)
+	records := auditTrail(t, l, "treasury", 0)
+	require.Len(t, records, 1)
+	assert.Equal(t, chaincode.AuditBootstrap, records[0].Action)
+	assert.Equal(t, 1000, records[0].After)
+}
+
+func TestGetAuditTrailInvalid(t *testing.T) {
+	l := adminLedger(t)
+	_, err := (&chaincode.SmartContract{}).GetAuditTrail(l.Context, "", 0, "")
+	assert.EqualError(t, err, "[INVALID_ARGUMENT] invalid account: must not be empty")
+}
@@ -0,0 +1,233 @@
+package chaincode
+
+import (
+	"strings"
+	"unicode"
+
+	"github.com/hyperledger/fabric-chaincode-go/pkg/cid"
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+	"github.com/hyperledger/fabric-protos-go/ledger/queryresult"
+	"github.com/hyperledger/fabric-protos-go/peer"
+	"github.com/kkiu1756/my_fabric/src/chaincode-go/validation"
+)
+
+// AuditorOU is the organizational unit of regulator certificates. An
+// auditor can read any account, transaction and audit trail, but only
+// through the Inspect transactions, each of which logs the read on the
+// ledger under auditorReadObjectType~txid. Every other function, including
+// every write, is rejected before it runs.
+//
+// The log is only written when the auditor's client submits the Inspect
+// transaction: a peer cannot tell an evaluated proposal from one that will
+// be ordered, so auditor gateways must submit them.
+const AuditorOU = "auditor"
+
+const auditorReadObjectType = "auditorread"
+
+// auditorTransactions are the only functions an auditor can call
+var auditorTransactions = map[string]bool{
+	"InspectAccount":     true,
+	"InspectTransaction": true,
+	"InspectAuditTrail":  true,
+}
+
+// AuditorRead logs one read by an auditor
+type AuditorRead struct {
+	TXID string `json:"txId"`
+	// Timestamp is the client's proposal timestamp in RFC 3339 format
+	Timestamp  string `json:"txTimestamp"`
+	ActorMSPID string `json:"actorMspId"`
+	Actor      string `json:"actor"`
+	// Function is the Inspect transaction and Target the account or
+	// transaction it read
+	Function string `json:"function"`
+	Target   string `json:"target"`
+}
+
+// AuditorReadPage is one page of the auditor read log
+type AuditorReadPage struct {
+	Reads []*AuditorRead `json:"reads"`
+	// Bookmark continues the log, empty after its last page
+	Bookmark string `json:"bookmark"`
+}
+
+// GetBeforeTransaction returns the hook the contract runs before every
+// function, which rejects revoked certificates, see revocation.go, and
+// invalid correlation ids, see correlation.go, and confines auditors to the
+// Inspect transactions
+func (s *SmartContract) GetBeforeTransaction() interface{} {
+	return beforeTransaction
+}
+
+func beforeTransaction(ctx contractapi.TransactionContextInterface) error {
+	if err := checkRevoked(ctx); err != nil {
+		return err
+	}
+	if err := checkCorrelationID(ctx); err != nil {
+		return err
+	}
+	if !isAuditor(ctx.GetStub()) {
+		return nil
+	}
+
+	fn := calledFunction(ctx.GetStub())
+	if !auditorTransactions[fn] {
+		return newError(CodeUnauthorized, "an auditor cannot call %s", fn)
+	}
+
+	return nil
+}
+
+// calledFunction returns the name of the invoked function without its
+// contract namespace, capitalized as the contract API dispatches it
+func calledFunction(stub shim.ChaincodeStubInterface) string {
+	fn, _ := stub.GetFunctionAndParameters()
+	if i := strings.LastIndex(fn, ":"); i >= 0 {
+		fn = fn[i+1:]
+	}
+	if fn == "" {
+		return fn
+	}
+
+	r := []rune(fn)
+	r[0] = unicode.ToUpper(r[0])
+	return string(r)
+}
+
+// isAuditor reports whether the client certificate carries AuditorOU
+func isAuditor(stub shim.ChaincodeStubInterface) bool {
+	caller, err := cid.New(stub)
+	if err != nil {
+		return false
+	}
+
+	auditor, err := caller.HasOUValue(AuditorOU)
+	return err == nil && auditor
+}
+
+// requireAuditor fails with UNAUTHORIZED unless the client is an auditor.
+// action completes the error message "only an auditor can ...".
+func requireAuditor(ctx contractapi.TransactionContextInterface, action string) error {
+	if !isAuditor(ctx.GetStub()) {
+		return newError(CodeUnauthorized, "only an auditor can %s", action)
+	}
+
+	return nil
+}
+
+// logAuditorRead writes the read of target by the current Inspect
+// transaction to the auditor read log
+func logAuditorRead(ctx contractapi.TransactionContextInterface, target string) error {
+	stub := ctx.GetStub()
+	timestamp, err := txTimestamp(stub)
+	if err != nil {
+		return err
+	}
+
+	key, err := stub.CreateCompositeKey(auditorReadObjectType, []string{stub.GetTxID()})
+	if err != nil {
+		return err
+	}
+
+	return putState(ctx, key, AuditorRead{
+		TXID:       stub.GetTxID(),
+		Timestamp:  timestamp,
+		ActorMSPID: creatorMSPID(stub),
+		Actor:      creatorID(stub),
+		Function:   calledFunction(stub),
+		Target:     target,
+	})
+}
+
+// InspectAccount returns the account stored under id, like GetAccount, and
+// logs the read. Only an auditor can call it.
+func (s *SmartContract) InspectAccount(ctx contractapi.TransactionContextInterface, id string) (*Account, error) {
+	if err := requireAuditor(ctx, "inspect accounts"); err != nil {
+		return nil, err
+	}
+
+	account, err := s.GetAccount(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	return account, logAuditorRead(ctx, id)
+}
+
+// InspectTransaction returns the transaction record txid, like
+// GetTransaction, and logs the read. Only an auditor can call it.
+func (s *SmartContract) InspectTransaction(ctx contractapi.TransactionContextInterface, txid string) (*Transaction, error) {
+	if err := requireAuditor(ctx, "inspect transactions"); err != nil {
+		return nil, err
+	}
+
+	transaction, err := s.GetTransaction(ctx, txid)
+	if err != nil {
+		return nil, err
+	}
+
+	return transaction, logAuditorRead(ctx, txid)
+}
+
+// InspectAuditTrail returns a page of account's audit trail, like
+// GetAuditTrail, and logs the read. As it writes the log, it reads the
+// trail with an unpaginated query. Only an auditor can call it.
+func (s *SmartContract) InspectAuditTrail(ctx contractapi.TransactionContextInterface, account string, pageSize int, bookmark string) (*AuditPage, error) {
+	if err := requireAuditor(ctx, "inspect audit trails"); err != nil {
+		return nil, err
+	}
+	if err := validate(validation.ID("account", account)); err != nil {
+		return nil, err
+	}
+	pageSize, err := queryPolicy.pageSize(pageSize)
+	if err != nil {
+		return nil, err
+	}
+
+	iterator, err := ctx.GetStub().GetStateByPartialCompositeKey(auditObjectType, []string{account})
+	if err != nil {
+		return nil, &StateError{Op: OpQueryState, Key: account, Err: err}
+	}
+	page := &AuditPage{Records: []*AuditRecord{}}
+	page.Bookmark, err = unpaginatedPage(iterator, pageSize, bookmark, false, page.add)
+	if err != nil {
+		return nil, err
+	}
+
+	return page, logAuditorRead(ctx, account)
+}
+
+// GetAuditorReads returns a page of at most pageSize entries of the auditor
+// read log, in transaction id order, from bookmark, which is empty for the
+// first page. Only an org admin can call it.
+func (s *SmartContract) GetAuditorReads(ctx contractapi.TransactionContextInterface, pageSize int, bookmark string) (*AuditorReadPage, error) {
+	if _, err := requireAdmin(ctx, "read the auditor log"); err != nil {
+		return nil, err
+	}
+	pageSize, err := queryPolicy.pageSize(pageSize)
+	if err != nil {
+		return nil, err
+	}
+
+	stub := ctx.GetStub()
+	query := func(pageSize int32, bookmark string) (shim.StateQueryIteratorInterface, *peer.QueryResponseMetadata, error) {
+		return stub.GetStateByPartialCompositeKeyWithPagination(auditorReadObjectType, []string{}, pageSize, bookmark)
+	}
+
+	page := &AuditorReadPage{Reads: []*AuditorRead{}}
+	page.Bookmark, err = queryPolicy.scan(query, auditorReadObjectType, pageSize, bookmark, func(kv *queryresult.KV) error {
+		var read AuditorRead
+		err := decodeRecord(kv.Key, kv.Value, &read, "txId", "function", "target")
+		if err != nil {
+			return err
+		}
+		page.Reads = append(page.Reads, &read)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return page, nil
+}
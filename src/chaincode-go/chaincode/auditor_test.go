@@ -0,0 +1,123 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package chaincode_test
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+	"github.com/kkiu1756/my_fabric/src/chaincode-go/chaincode"
+	"github.com/kkiu1756/my_fabric/src/chaincode-go/tokentest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// #########
+// TESTS
+// #########
+
+func TestAuditorConfinedToInspect(t *testing.T) {
+	stub := newMockStub(t)
+	stub.Creator = tokentest.Identity(t, "RegulatorMSP", "regulator", chaincode.AuditorOU)
+
+	for _, call := range [][]string{
+		{"TransferFrom", "alice", "bob", "1"},
+		{"SetBalance", "alice", "0"},
+		{"CreateUser", "carol", "user", "5"},
+		{"GetUser", "alice"},
+		{"getAccount", "alice"},
+	} {
+		invocation := [][]byte{}
+		for _, arg := range call {
+			invocation = append(invocation, []byte(arg))
+		}
+		resp := stub.MockInvoke("denied", invocation)
+		assert.Equal(t, int32(shim.ERROR), resp.Status, call[0])
+		assert.Contains(t, resp.Message, "[UNAUTHORIZED] an auditor cannot call ", call[0])
+	}
+	assert.Equal(t, 100, stubBalance(t, stub, "alice"))
+
+	var account chaincode.Account
+	require.NoError(t, json.Unmarshal(invokeTx(t, stub, "read1", "InspectAccount", "alice"), &account))
+	assert.Equal(t, chaincode.Account{ID: "alice", Type: "user", Balance: 100}, account)
+
+	var read chaincode.AuditorRead
+	require.NoError(t, json.Unmarshal(stub.State["\x00auditorread\x00read1\x00"], &read))
+	assert.Equal(t, "read1", read.TXID)
+	assert.Equal(t, "RegulatorMSP", read.ActorMSPID)
+	assert.Equal(t, "InspectAccount", read.Function)
+	assert.Equal(t, "alice", read.Target)
+}
+
+func TestInspectRequiresAuditor(t *testing.T) {
+	stub := newMockStub(t)
+
+	resp := stub.MockInvoke("read1", [][]byte{[]byte("InspectAccount"), []byte("alice")})
+	assert.Equal(t, int32(shim.ERROR), resp.Status)
+	assert.Equal(t, "[UNAUTHORIZED] only an auditor can inspect accounts", resp.Message)
+	assert.NotContains(t, stub.State, "\x00auditorread\x00read1\x00")
+}
+
+func TestInspectTransaction(t *testing.T) {
+	l := tokentest.NewLedger(t).
+		WithTransaction("pay", "alice", "bob", 30).
+		WithCaller("RegulatorMSP", "regulator", chaincode.AuditorOU)
+	l.Stub.GetFunctionAndParametersReturns("InspectTransaction", []string{"pay"})
+	contract := &chaincode.SmartContract{}
+
+	tx, err := contract.InspectTransaction(l.Context, "pay")
+	require.NoError(t, err)
+	assert.Equal(t, 30, tx.Value)
+
+	_, err = contract.InspectTransaction(l.Context, "missing")
+	assert.EqualError(t, err, "[TRANSACTION_NOT_FOUND] the transaction missing does not exist")
+
+	l.WithCaller("Org1MSP", "Admin@org1.example.com", chaincode.AdminOU)
+	paginate(l.Stub, l.State)
+	page, err := contract.GetAuditorReads(l.Context, 0, "")
+	require.NoError(t, err)
+	require.Len(t, page.Reads, 1, "a failed read is not logged")
+	assert.Equal(t, "InspectTransaction", page.Reads[0].Function)
+	assert.Equal(t, "pay", page.Reads[0].Target)
+
+	l.WithCaller("RegulatorMSP", "regulator", chaincode.AuditorOU)
+	_, err = contract.GetAuditorReads(l.Context, 0, "")
+	assert.EqualError(t, err, "[UNAUTHORIZED] only an organization admin can read the auditor log")
+}
+
+func TestInspectAuditTrail(t *testing.T) {
+	l := adminLedger(t).WithTimestamp(time.Date(2021, 3, 1, 12, 0, 0, 0, time.UTC))
+	paginate(l.Stub, l.State)
+	contract := &chaincode.SmartContract{}
+	_, err := contract.CreateUser(l.Context, "alice", "user", 100)
+	require.NoError(t, err)
+	_, err = contract.SetBalance(l.WithTxID("tx2").Context, "alice", 40)
+	require.NoError(t, err)
+	_, err = contract.SetBalance(l.WithTxID("tx3").Context, "alice", 50)
+	require.NoError(t, err)
+
+	l.RejectWritesAfterPagination().
+		WithCaller("RegulatorMSP", "regulator", chaincode.AuditorOU).
+		WithTxID("read1")
+	l.Stub.GetFunctionAndParametersReturns("InspectAuditTrail", []string{"alice", "2", ""})
+	page, err := contract.InspectAuditTrail(l.Context, "alice", 2, "")
+	require.NoError(t, err)
+	require.Len(t, page.Records, 2)
+	assert.Equal(t, chaincode.AuditCreate, page.Records[0].Action)
+	assert.NotEmpty(t, page.Bookmark)
+
+	var read chaincode.AuditorRead
+	require.NoError(t, json.Unmarshal(l.State["\x00auditorread\x00read1\x00"], &read), "should log the read")
+	assert.Equal(t, "InspectAuditTrail", read.Function)
+	assert.Equal(t, "alice", read.Target)
+
+	page, err = contract.InspectAuditTrail(l.WithTxID("read2").Context, "alice", 2, page.Bookmark)
+	require.NoError(t, err)
+	require.Len(t, page.Records, 1)
+	assert.Equal(t, 2, page.Records[0].Seq)
+	assert.Empty(t, page.Bookmark)
+}
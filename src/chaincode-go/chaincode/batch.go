@@ -0,0 +1,159 @@
+package chaincode
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// writeBatch buffers the state changes of one method. Reads see the
+// buffered writes, a key written several times is written once, and flush
+// applies the changes in key order, so the stub calls of a transaction do
+// not depend on the order the method touched its keys in.
+type writeBatch struct {
+	ctx contractapi.TransactionContextInterface
+	// writes maps each changed key to its new value, nil for a deletion
+	writes map[string][]byte
+	// codec encodes the written records, read from the ledger
+	// configuration on the first write
+	codec Codec
+	// treasury is the treasury account a payment of the method may debit,
+	// see treasury.go
+	treasury string
+	// held is the event of the last transaction recorded in the batch that
+	// later typed events join instead of replacing, see emitTypedEvent
+	held *heldEvent
+}
+
+func newWriteBatch(ctx contractapi.TransactionContextInterface) *writeBatch {
+	return &writeBatch{ctx: ctx, writes: map[string][]byte{}}
+}
+
+// getState reads key, seeing the batch's own writes
+func (b *writeBatch) getState(key string) ([]byte, error) {
+	if value, ok := b.writes[key]; ok {
+		return value, nil
+	}
+
+	return getState(b.ctx, key)
+}
+
+// putState buffers the encoding of value under key
+func (b *writeBatch) putState(key string, value interface{}) error {
+	if b.codec == nil {
+		codec, err := recordCodec(b.ctx)
+		if err != nil {
+			return err
+		}
+		b.codec = codec
+	}
+
+	data, err := b.codec.Marshal(value)
+	if err != nil {
+		return fmt.Errorf("failed to obtain JSON encoding of %s: %w", key, err)
+	}
+
+	b.writes[key] = data
+	return nil
+}
+
+// delState buffers the deletion of key
+func (b *writeBatch) delState(key string) {
+	b.writes[key] = nil
+}
+
+// getUser reads an account like GetUser, seeing the batch's own writes
+func (b *writeBatch) getUser(id string) (*User, error) {
+	key, err := accountKey(b.ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	data, err := b.getState(key)
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := userFromRecord(id, data)
+	if err != nil {
+		return nil, err
+	}
+
+	err = rebaseUser(b.ctx, user)
+	if err != nil {
+		return nil, err
+	}
+
+	return user, accrueInterest(b, user)
+}
+
+// putUser buffers the account record of user under its key, in the
+// current rebase generation, with the statement lines of a rebase and of
+// interest settled by reading it, ranks its balance, see holders.go, and
+// notes the balance change for the transaction's event, see changes.go
+func (b *writeBatch) putUser(user *User) error {
+	key, err := accountKey(b.ctx, user.ID)
+	if err != nil {
+		return err
+	}
+	user.Rebase, err = currentRebase(b.ctx)
+	if err != nil {
+		return err
+	}
+	if user.rebased != 0 {
+		err = indexBalanceChange(b, user.ID, LineRebase, "", user.rebased)
+		if err != nil {
+			return err
+		}
+		user.rebased = 0
+	}
+	if user.accrued != 0 {
+		err = indexBalanceChange(b, user.ID, LineInterest, "", user.accrued)
+		if err != nil {
+			return err
+		}
+		user.accrued = 0
+	}
+	err = recordAccrualTime(b, user)
+	if err != nil {
+		return err
+	}
+	previous, err := b.getState(key)
+	if err != nil {
+		return err
+	}
+	err = rankHolder(b, user, previous)
+	if err != nil {
+		return err
+	}
+	noteBalanceChange(b.ctx, user.ID, previous, user.Balance)
+
+	return b.putState(key, user)
+}
+
+// flush writes the buffered changes in key order and empties the batch. It
+// stops at the first failure; the peer then discards the whole write set.
+func (b *writeBatch) flush() error {
+	keys := make([]string, 0, len(b.writes))
+	for key := range b.writes {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	stub := b.ctx.GetStub()
+	for _, key := range keys {
+		value := b.writes[key]
+		if value == nil {
+			if err := stub.DelState(key); err != nil {
+				return &StateError{Op: OpDeleteState, Key: key, Err: err}
+			}
+			continue
+		}
+		if err := stub.PutState(key, value); err != nil {
+			return &StateError{Op: OpWriteState, Key: key, Err: err}
+		}
+	}
+
+	b.writes = map[string][]byte{}
+	return nil
+}
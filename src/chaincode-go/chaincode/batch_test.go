@@ -0,0 +1,58 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package chaincode_test
+
+import (
+	"sort"
+	"testing"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+	"github.com/kkiu1756/my_fabric/src/chaincode-go/chaincode"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestWritesFlushedInKeyOrder checks every method writes each key once and
+// in key order, whatever order it changed them in
+func TestWritesFlushedInKeyOrder(t *testing.T) {
+	contract := &chaincode.SmartContract{}
+	tests := []struct {
+		name string
+		tx   transactionFunc
+		keys []string
+	}{
+		{"transfer to a lower key", transfer("zed", "alice", 10), []string{"\x00accountcount\x00user\x0003\x00tx1\x00", "\x00accountcount\x00user\x0004\x00tx1\x00", "\x00holderrank\x0009007199254740881\x00alice\x00", "\x00holderrank\x0009007199254740901\x00zed\x00", "\x00txindex\x00alice\x00\x00tx1\x00", "\x00txindex\x00zed\x00\x00tx1\x00", "alice", "tx1", "zed"}},
+		{"transfer to a higher key", transfer("alice", "zed", 10), []string{"\x00accountcount\x00user\x0003\x00tx1\x00", "\x00accountcount\x00user\x0004\x00tx1\x00", "\x00holderrank\x0009007199254740881\x00zed\x00", "\x00holderrank\x0009007199254740901\x00alice\x00", "\x00txindex\x00alice\x00\x00tx1\x00", "\x00txindex\x00zed\x00\x00tx1\x00", "alice", "tx1", "zed"}},
+		{"create user", func(ctx contractapi.TransactionContextInterface) (interface{}, error) {
+			return contract.CreateUser(ctx, "carol", "user", 10)
+		}, []string{"\x00accountcount\x00user\x0003\x00tx1\x00", "\x00accounttype\x00user\x00carol\x00", "\x00audit\x00carol\x000000000000\x00", "\x00auditseq\x00carol\x00", "\x00holderrank\x0009007199254740981\x00carol\x00", "\x00txindex\x00carol\x00\x00tx1\x00", "carol", "tx1"}},
+		{"set balance", func(ctx contractapi.TransactionContextInterface) (interface{}, error) {
+			return contract.SetBalance(ctx, "zed", 10)
+		}, []string{"\x00accountcount\x00user\x0003\x00tx1\x00", "\x00audit\x00zed\x000000000000\x00", "\x00auditseq\x00zed\x00", "\x00holderrank\x0009007199254740981\x00zed\x00", "\x00txindex\x00zed\x00\x00tx1\x00", "tx1", "zed"}},
+		{"delete user", func(ctx contractapi.TransactionContextInterface) (interface{}, error) {
+			return nil, contract.DeleteUser(ctx, "zed")
+		}, []string{"\x00accounttype\x00user\x00zed\x00", "\x00audit\x00zed\x000000000000\x00", "\x00auditseq\x00zed\x00", "\x00txindex\x00zed\x00\x00tx1\x00", "zed"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, stub := newContext(accounts(t, map[string]int{"alice": 100, "zed": 100}))
+			var keys []string
+			stub.PutStateStub = func(key string, value []byte) error {
+				keys = append(keys, key)
+				return nil
+			}
+			stub.DelStateStub = func(key string) error {
+				keys = append(keys, key)
+				return nil
+			}
+
+			_, err := tt.tx(ctx)
+			require.NoError(t, err)
+			assert.Equal(t, tt.keys, keys)
+			assert.True(t, sort.StringsAreSorted(keys))
+		})
+	}
+}
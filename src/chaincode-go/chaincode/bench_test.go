@@ -0,0 +1,146 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package chaincode_test
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"testing"
+
+	"github.com/kkiu1756/my_fabric/src/chaincode-go/chaincode"
+)
+
+// These benchmarks run transfers through the MVCC simulation in
+// mvcc_test.go and report how many commit per block. Debiting one treasury
+// account serialises every transfer on its key, so at most one commits per
+// block whatever the block size; spreading the same load over disjoint
+// accounts lets the whole block commit. Crediting one collector account
+// serialises the same way unless it is marked hot, when credits become
+// delta keys (delta.go) and the whole block commits. Run with
+//
+//	go test ./chaincode -run '^$' -bench Transfers
+
+// #########
+// HELPERS
+// #########
+
+// layout selects which accounts the benchmarked transfers touch
+type layout int
+
+const (
+	// every transfer debits the treasury
+	hotSender layout = iota
+	// transfer i moves between its own pair of accounts
+	spread
+	// every transfer credits the collector
+	hotRecipient
+	// every transfer credits the collector, marked hot
+	deltaRecipient
+)
+
+// benchmarkTransfers commits b.N transfers in blocks of blockSize,
+// re-endorsing conflicting ones into the next block
+func benchmarkTransfers(b *testing.B, accountLayout layout, blockSize int) {
+	chaincode.SetLogger(slog.New(slog.NewJSONHandler(io.Discard, nil)))
+	defer chaincode.SetLogger(slog.Default())
+
+	balances := map[string]int{"treasury": b.N, "collector": 0}
+	senders := make([]string, b.N)
+	recipients := make([]string, b.N)
+	for i := 0; i < b.N; i++ {
+		senders[i] = fmt.Sprintf("sender%d", i)
+		recipients[i] = fmt.Sprintf("recipient%d", i)
+		switch accountLayout {
+		case hotSender:
+			senders[i] = "treasury"
+			balances[recipients[i]] = 0
+		case spread:
+			balances[senders[i]] = 1
+			balances[recipients[i]] = 0
+		case hotRecipient, deltaRecipient:
+			balances[senders[i]] = 1
+			recipients[i] = "collector"
+		}
+	}
+	state := accounts(b, balances)
+	if accountLayout == deltaRecipient {
+		state["collector"] = hotUserJSON(b, "collector", 0)
+	}
+	l := newVersionedLedger(state)
+
+	pending := make([]int, b.N)
+	for i := range pending {
+		pending[i] = i
+	}
+
+	b.ResetTimer()
+	blocks, conflicts := 0, 0
+	for len(pending) > 0 {
+		n := blockSize
+		if n > len(pending) {
+			n = len(pending)
+		}
+
+		block := make([]endorsement, n)
+		for j, i := range pending[:n] {
+			block[j] = l.endorse(fmt.Sprintf("tx%d-%d", i, blocks), transfer(senders[i], recipients[i], 1))
+		}
+
+		retry := make([]int, 0, len(pending))
+		for j, code := range l.commit(block...) {
+			switch code {
+			case valid:
+			case mvccConflict:
+				conflicts++
+				retry = append(retry, pending[j])
+			default:
+				b.Fatalf("transfer %d: %s: %v", pending[j], code, block[j].err)
+			}
+		}
+		pending = append(retry, pending[n:]...)
+		blocks++
+	}
+	b.StopTimer()
+
+	b.ReportMetric(float64(b.N)/float64(blocks), "transfers/block")
+	b.ReportMetric(float64(conflicts)/float64(b.N), "conflicts/transfer")
+}
+
+// #########
+// BENCHMARKS
+// #########
+
+func BenchmarkTransfersHotAccount(b *testing.B) {
+	for _, size := range []int{10, 100} {
+		b.Run(fmt.Sprintf("block=%d", size), func(b *testing.B) {
+			benchmarkTransfers(b, hotSender, size)
+		})
+	}
+}
+
+func BenchmarkTransfersSpreadAccounts(b *testing.B) {
+	for _, size := range []int{10, 100} {
+		b.Run(fmt.Sprintf("block=%d", size), func(b *testing.B) {
+			benchmarkTransfers(b, spread, size)
+		})
+	}
+}
+
+func BenchmarkTransfersHotRecipient(b *testing.B) {
+	for _, size := range []int{10, 100} {
+		b.Run(fmt.Sprintf("block=%d", size), func(b *testing.B) {
+			benchmarkTransfers(b, hotRecipient, size)
+		})
+	}
+}
+
+func BenchmarkTransfersDeltaRecipient(b *testing.B) {
+	for _, size := range []int{10, 100} {
+		b.Run(fmt.Sprintf("block=%d", size), func(b *testing.B) {
+			benchmarkTransfers(b, deltaRecipient, size)
+		})
+	}
+}
@@ -0,0 +1,157 @@
+package chaincode
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+	"github.com/kkiu1756/my_fabric/src/chaincode-go/validation"
+)
+
+// MaxBootstrapAccounts is the most accounts one BootstrapLedger call
+// creates. Larger artifacts are loaded in chunks, one transaction each, so
+// no proposal or write set grows past what a peer handles comfortably.
+const MaxBootstrapAccounts = 500
+
+// BootstrapResult reports what one BootstrapLedger chunk did
+type BootstrapResult struct {
+	// Created counts the accounts written by this call
+	Created int `json:"created"`
+	// Existing counts the accounts already present with the same record,
+	// from an earlier attempt at the same chunk
+	Existing int `json:"existing"`
+}
+
+// BootstrapLedger creates the initial accounts listed in accountsJSON, a
+// JSON array of account records as GetUser returns them, and writes them
+// as CreateUser does. No account can be hot or take the name of an alias;
+// mark hot accounts with SetHotAccount after the load. A chunk is all or
+// nothing, and accounts already stored with an identical record are
+// skipped, so a load that stopped part way continues by resubmitting the
+// chunks from the first one that did not commit. Only an org admin can
+// call it.
+//
+// The chunk is recorded as one Transaction of type bootstrap whose value is
+// the total balance created.
+func (s *SmartContract) BootstrapLedger(ctx contractapi.TransactionContextInterface, accountsJSON string) (*BootstrapResult, error) {
+	if err := requireInitialized(ctx); err != nil {
+		return nil, err
+	}
+	if _, err := requireAdmin(ctx, "bootstrap the ledger"); err != nil {
+		return nil, err
+	}
+
+	accounts, err := decodeBootstrap(accountsJSON)
+	if err != nil {
+		return nil, err
+	}
+
+	generation, err := currentRebase(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	batch := newWriteBatch(ctx)
+	result := &BootstrapResult{}
+	total := 0
+	for _, account := range accounts {
+		account.Rebase = generation
+		key, err := accountKey(ctx, account.ID)
+		if err != nil {
+			return nil, err
+		}
+		existing, err := batch.getState(key)
+		if err != nil {
+			return nil, err
+		}
+		if existing != nil {
+			user, err := userFromRecord(account.ID, existing)
+			if err != nil || *user != account {
+				return nil, newError(CodeAccountExists, "user %s exist with a different record", account.ID)
+			}
+			result.Existing++
+			continue
+		}
+
+		err = requireNoAlias(batch, account.ID)
+		if err != nil {
+			return nil, err
+		}
+		user := account
+		err = batch.putUser(&user)
+		if err != nil {
+			return nil, err
+		}
+		err = appendAudit(batch, account.ID, AuditBootstrap, 0, account.Balance, "")
+		if err != nil {
+			return nil, err
+		}
+		if account.Balance > 0 {
+			err = indexBalanceChange(batch, account.ID, string(TransactionBootstrap), "", account.Balance)
+			if err != nil {
+				return nil, err
+			}
+		}
+		result.Created++
+
+		var ok bool
+		total, ok = addBalance(total, account.Balance)
+		if !ok {
+			return nil, newError(CodeBalanceOverflow, "total bootstrap balance overflows")
+		}
+	}
+
+	if result.Created > 0 {
+		_, err = recordTransaction(batch, TransactionBootstrap, "", "", total)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	err = batch.flush()
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// decodeBootstrap strictly decodes and validates a bootstrap chunk
+func decodeBootstrap(accountsJSON string) ([]User, error) {
+	decoder := json.NewDecoder(bytes.NewReader([]byte(accountsJSON)))
+	decoder.DisallowUnknownFields()
+
+	var accounts []User
+	if err := decoder.Decode(&accounts); err != nil {
+		return nil, validate(&validation.Error{Field: "accounts", Reason: err.Error()})
+	}
+	if len(accounts) == 0 {
+		return nil, validate(&validation.Error{Field: "accounts", Reason: "must not be empty"})
+	}
+	if len(accounts) > MaxBootstrapAccounts {
+		return nil, validate(&validation.Error{Field: "accounts", Reason: fmt.Sprintf("must hold at most %d accounts, split the artifact into chunks", MaxBootstrapAccounts)})
+	}
+
+	seen := make(map[string]bool, len(accounts))
+	for i, account := range accounts {
+		field := fmt.Sprintf("accounts[%d]", i)
+		err := validate(
+			validation.AccountID(field+".userId", account.ID),
+			validation.ID(field+".type", account.Type),
+			validation.Amount(field+".balance", account.Balance),
+		)
+		if err != nil {
+			return nil, err
+		}
+		if account.Hot {
+			return nil, validate(&validation.Error{Field: field + ".hot", Reason: "must be false, mark hot accounts with SetHotAccount after the load"})
+		}
+		if seen[account.ID] {
+			return nil, validate(&validation.Error{Field: field + ".userId", Reason: fmt.Sprintf("duplicate account %s", account.ID)})
+		}
+		seen[account.ID] = true
+	}
+
+	return accounts, nil
+}
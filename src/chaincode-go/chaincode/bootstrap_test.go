@@ -0,0 +1,156 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package chaincode_test
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/kkiu1756/my_fabric/src/chaincode-go/chaincode"
+	"github.com/kkiu1756/my_fabric/src/chaincode-go/tokentest"
+	"github.com/kkiu1756/my_fabric/src/chaincode-go/validation"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// #########
+// HELPERS
+// #########
+
+func adminLedger(t *testing.T) *tokentest.Ledger {
+	return tokentest.NewLedger(t).WithCaller("Org1MSP", "Admin@org1.example.com", chaincode.AdminOU)
+}
+
+func bootstrapJSON(t *testing.T, accounts ...chaincode.User) string {
+	data, err := json.Marshal(accounts)
+	require.NoError(t, err)
+	return string(data)
+}
+
+// #########
+// TESTS
+// #########
+
+func TestBootstrapLedger(t *testing.T) {
+	l := adminLedger(t)
+	contract := &chaincode.SmartContract{}
+	chunk := bootstrapJSON(t,
+		chaincode.User{ID: "treasury", Type: "issuer", Balance: 1000},
+		chaincode.User{ID: "alice", Type: "user", Balance: 100},
+		chaincode.User{ID: "bob", Type: "user"},
+	)
+
+	result, err := contract.BootstrapLedger(l.Context, chunk)
+	require.NoError(t, err)
+	assert.Equal(t, &chaincode.BootstrapResult{Created: 3}, result)
+	l.AssertBalance("treasury", 1000)
+	l.AssertBalance("alice", 100)
+	l.AssertBalance("bob", 0)
+	tx := l.Transaction(tokentest.DefaultTxID)
+	assert.Equal(t, chaincode.TransactionBootstrap, tx.Type)
+	assert.Equal(t, 1100, tx.Value)
+
+	l.WithTxID("retry")
+	result, err = contract.BootstrapLedger(l.Context, chunk)
+	require.NoError(t, err)
+	assert.Equal(t, &chaincode.BootstrapResult{Existing: 3}, result, "a resubmitted chunk should change nothing")
+	assert.NotContains(t, l.State, "retry", "nothing created, nothing to record")
+
+	l.WithTxID("next")
+	result, err = contract.BootstrapLedger(l.Context, bootstrapJSON(t,
+		chaincode.User{ID: "bob", Type: "user"},
+		chaincode.User{ID: "carol", Type: "user", Balance: 5},
+	))
+	require.NoError(t, err)
+	assert.Equal(t, &chaincode.BootstrapResult{Created: 1, Existing: 1}, result)
+	l.AssertBalance("carol", 5)
+}
+
+func TestBootstrapLedgerRejects(t *testing.T) {
+	tooMany := make([]chaincode.User, chaincode.MaxBootstrapAccounts+1)
+	for i := range tooMany {
+		tooMany[i] = chaincode.User{ID: fmt.Sprintf("user%d", i), Type: "user"}
+	}
+
+	tests := []struct {
+		name     string
+		accounts string
+		err      string
+	}{
+		{"not an array", `{"userId":"alice"}`, "[INVALID_ARGUMENT] invalid accounts: json: cannot unmarshal object into Go value of type []chaincode.User"},
+		{"unknown field", `[{"userId":"carol","type":"user","balance":1,"owner":"x"}]`, `[INVALID_ARGUMENT] invalid accounts: json: unknown field "owner"`},
+		{"empty", `[]`, "[INVALID_ARGUMENT] invalid accounts: must not be empty"},
+		{"too many", bootstrapJSON(t, tooMany...), "[INVALID_ARGUMENT] invalid accounts: must hold at most 500 accounts, split the artifact into chunks"},
+		{"reserved id", `[{"userId":"_carol","type":"user","balance":1}]`, `[INVALID_ARGUMENT] invalid accounts[0].userId: must not start with "_", which is reserved`},
+		{"negative balance", `[{"userId":"carol","type":"user","balance":-1}]`, "[INVALID_ARGUMENT] invalid accounts[0].balance: must not be negative"},
+		{
+			"duplicate", `[{"userId":"carol","type":"user","balance":1},{"userId":"carol","type":"user","balance":2}]`,
+			"[INVALID_ARGUMENT] invalid accounts[1].userId: duplicate account carol",
+		},
+		{
+			"hot", `[{"userId":"carol","type":"fees","balance":1,"hot":true}]`,
+			"[INVALID_ARGUMENT] invalid accounts[0].hot: must be false, mark hot accounts with SetHotAccount after the load",
+		},
+		{
+			"different existing record", `[{"userId":"carol","type":"user","balance":1},{"userId":"alice","type":"user","balance":1}]`,
+			"[ACCOUNT_EXISTS] user alice exist with a different record",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l := adminLedger(t).WithAccount("alice", "user", 100)
+
+			_, err := (&chaincode.SmartContract{}).BootstrapLedger(l.Context, tt.accounts)
+			assert.EqualError(t, err, tt.err)
+			if strings.HasPrefix(tt.err, "[INVALID_ARGUMENT]") {
+				assert.True(t, errors.Is(err, validation.ErrInvalidArgument))
+			}
+			assert.Zero(t, l.Stub.PutStateCallCount(), "a rejected chunk should write nothing")
+		})
+	}
+}
+
+func TestBootstrapLedgerRejectsAliases(t *testing.T) {
+	l := adminLedger(t).WithAccount("alice", "user", 100)
+	contract := &chaincode.SmartContract{}
+	l.WithCaller("Org1MSP", "alice", "client")
+	_, err := contract.RegisterAlias(l.Context, "carol", "alice")
+	require.NoError(t, err)
+
+	l.WithCaller("Org1MSP", "Admin@org1.example.com", chaincode.AdminOU)
+	_, err = contract.BootstrapLedger(l.Context, `[{"userId":"dave","type":"user","balance":1},{"userId":"carol","type":"user","balance":1}]`)
+	assert.EqualError(t, err, "[ACCOUNT_EXISTS] carol is an alias of alice")
+	l.AssertNoAccount("dave")
+}
+
+func TestBootstrapLedgerStartsInterest(t *testing.T) {
+	l := adminLedger(t).WithTimestamp(demurrageTime)
+	contract := &chaincode.SmartContract{}
+	year := 365 * 24 * time.Hour
+	_, err := contract.SetInterest(l.Context, "saver", 1000)
+	require.NoError(t, err)
+
+	l.WithTimestamp(demurrageTime.Add(year))
+	_, err = contract.BootstrapLedger(l.Context, `[{"userId":"alice","type":"saver","balance":10000}]`)
+	require.NoError(t, err)
+
+	l.WithTimestamp(demurrageTime.Add(2 * year))
+	user, err := contract.GetUser(l.Context, "alice")
+	require.NoError(t, err)
+	assert.Equal(t, 11000, user.Balance, "interest should accrue from the bootstrap")
+}
+
+func TestBootstrapLedgerRequiresAdmin(t *testing.T) {
+	l := tokentest.NewLedger(t).WithCaller("Org1MSP", "User1@org1.example.com", "client")
+
+	_, err := (&chaincode.SmartContract{}).BootstrapLedger(l.Context, `[{"userId":"carol","type":"user","balance":1}]`)
+	assert.EqualError(t, err, "[UNAUTHORIZED] only an organization admin can bootstrap the ledger")
+	l.AssertNoAccount("carol")
+}
@@ -0,0 +1,142 @@
+package chaincode
+
+import (
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+	"github.com/kkiu1756/my_fabric/src/chaincode-go/validation"
+)
+
+// Once an org admin sets a cashback policy, a transfer to an account of
+// type SellerType credits the sender a share of its value from the
+// policy's pool, unless the pool is short. Like the referral bonus, the
+// cashback is paid inside the transfer, as statement lines of type
+// LineCashback, and its Cashback event replaces the transfer's typed event
+// and embeds the transfer. A legacy Transfer or LargeTransfer event stays,
+// with the cashback fields added, see events.go.
+
+// SellerType is the account type that purchases earn cashback on
+const SellerType = "seller"
+
+// LineCashback is the type of the statement lines of a cashback
+const LineCashback = "cashback"
+
+// MaxCashbackRate is 100%, in basis points
+const MaxCashbackRate = 10000
+
+// EventCashback replaces the typed event of a transfer that paid cashback
+const EventCashback = "Cashback"
+
+// cashbackObjectType keys the cashback paid on a transfer by its
+// transaction id, so its refunds can claw it back, see refund.go
+const cashbackObjectType = "cashback"
+
+// CashbackPolicy pays Rate basis points of purchases from Pool
+type CashbackPolicy struct {
+	Pool string `json:"pool"`
+	Rate int    `json:"rate"`
+}
+
+// cashbackPayment records the cashback paid on a transfer
+type cashbackPayment struct {
+	Pool     string `json:"pool"`
+	Cashback int    `json:"cashback"`
+}
+
+// cashbackEvent is the payload of EventCashback
+type cashbackEvent struct {
+	*Transaction
+	Cashback int    `json:"cashback"`
+	Pool     string `json:"pool"`
+}
+
+// SetCashback pays buyers rate basis points of their transfers to seller
+// accounts from pool; a 0 rate ends cashback. Only an org admin can call
+// it.
+func (s *SmartContract) SetCashback(ctx contractapi.TransactionContextInterface, pool string, rate int) (*Initialization, error) {
+	if rate < 0 || rate > MaxCashbackRate {
+		return nil, validate(&validation.Error{Field: "rate", Reason: "must be between 0 and 10000 basis points"})
+	}
+	if rate > 0 {
+		if err := validate(validation.AccountID("pool", pool)); err != nil {
+			return nil, err
+		}
+	}
+	if err := requireInitialized(ctx); err != nil {
+		return nil, err
+	}
+	if _, err := requireAdmin(ctx, "change cashback"); err != nil {
+		return nil, err
+	}
+
+	init, err := ledgerConfig(ctx)
+	if err != nil {
+		return nil, err
+	}
+	init.Cashback = nil
+	if rate > 0 {
+		if _, err := newWriteBatch(ctx).getUser(pool); err != nil {
+			return nil, err
+		}
+		init.Cashback = &CashbackPolicy{Pool: pool, Rate: rate}
+	}
+
+	err = putState(ctx, InitializationKey, init)
+	if err != nil {
+		return nil, err
+	}
+
+	return init, nil
+}
+
+// payCashback buffers the cashback a transfer earns, if any, and emits its
+// event
+func payCashback(batch *writeBatch, transaction *Transaction) error {
+	init, err := ledgerConfig(batch.ctx)
+	if err != nil || init == nil || init.Cashback == nil {
+		return err
+	}
+	policy := init.Cashback
+	// split the value so the product cannot overflow
+	cashback := transaction.Value/MaxCashbackRate*policy.Rate + transaction.Value%MaxCashbackRate*policy.Rate/MaxCashbackRate
+	if cashback == 0 || transaction.From == policy.Pool {
+		return nil
+	}
+
+	seller, err := batch.getUser(transaction.To)
+	if err != nil || seller.Type != SellerType {
+		return err
+	}
+	pool, err := batch.getUser(policy.Pool)
+	if err != nil {
+		return err
+	}
+	err = settleDemurrage(batch, pool)
+	if err != nil {
+		return err
+	}
+	if pool.Balance < cashback {
+		return batch.putUser(pool)
+	}
+	pool.Balance -= cashback
+	err = batch.putUser(pool)
+	if err != nil {
+		return err
+	}
+	err = indexBalanceChange(batch, policy.Pool, LineCashback, transaction.From, -cashback)
+	if err != nil {
+		return err
+	}
+	err = creditShare(batch, transaction.From, LineCashback, policy.Pool, cashback)
+	if err != nil {
+		return err
+	}
+	key, err := batch.ctx.GetStub().CreateCompositeKey(cashbackObjectType, []string{transaction.TXID})
+	if err != nil {
+		return err
+	}
+	err = batch.putState(key, cashbackPayment{Pool: policy.Pool, Cashback: cashback})
+	if err != nil {
+		return err
+	}
+
+	return emitTypedEvent(batch, EventCashback, cashbackEvent{transaction, cashback, policy.Pool})
+}
@@ -0,0 +1,121 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package chaincode_test
+
+import (
+	"testing"
+
+	"github.com/kkiu1756/my_fabric/src/chaincode-go/chaincode"
+	"github.com/kkiu1756/my_fabric/src/chaincode-go/tokentest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCashback(t *testing.T) {
+	l := initializedLedger(t).
+		WithAccount("rewards", "pool", 2).
+		WithAccount("alice", "user", 100).
+		WithAccount("bob", "user", 0).
+		WithAccount("shop", chaincode.SellerType, 0)
+	contract := &chaincode.SmartContract{}
+	_, err := contract.SetCashback(l.Context, "rewards", 500)
+	require.NoError(t, err)
+
+	l.WithTxID("buy")
+	transaction, err := contract.TransferFrom(l.Context, "alice", "shop", 40)
+	require.NoError(t, err)
+	l.AssertBalance("alice", 62)
+	l.AssertBalance("shop", 40)
+	l.AssertBalance("rewards", 0)
+	l.AssertEvent(chaincode.EventCashback, map[string]interface{}{
+		"txId": "buy", "type": "transfer", "from": "alice", "to": "shop", "value": 40,
+		"txTimestamp": transaction.Timestamp, "channelId": transaction.ChannelID,
+		"initiatorMspId": transaction.InitiatorMSPID, "initiator": transaction.Initiator,
+		"cashback": 2, "pool": "rewards",
+	})
+
+	l.WithTxID("gift")
+	_, err = contract.TransferFrom(l.Context, "alice", "bob", 40)
+	require.NoError(t, err)
+	l.AssertBalance("alice", 22)
+	l.AssertEvent(chaincode.EventTransferred, l.Transaction("gift"))
+
+	l.WithTxID("short")
+	_, err = contract.TransferFrom(l.Context, "alice", "shop", 20)
+	require.NoError(t, err)
+	l.AssertBalance("alice", 2)
+	l.AssertBalance("rewards", 0)
+	l.AssertEvent(chaincode.EventTransferred, l.Transaction("short"))
+	l.AssertTotal(102)
+}
+
+func TestCashbackKeepsLegacyTransfer(t *testing.T) {
+	contract := &chaincode.SmartContract{}
+	withCashback := func(l *tokentest.Ledger) *tokentest.Ledger {
+		l.WithAccount("rewards", "pool", 10).WithAccount("alice", "user", 100).WithAccount("shop", chaincode.SellerType, 0)
+		_, err := contract.SetCashback(l.Context, "rewards", 500)
+		require.NoError(t, err)
+		return l
+	}
+
+	l := withCashback(adminLedger(t))
+	transaction, err := contract.TransferFrom(l.WithTxID("buy").Context, "alice", "shop", 40)
+	require.NoError(t, err)
+	l.AssertBalance("alice", 62)
+	l.AssertEvent(chaincode.EventTransfer, map[string]interface{}{
+		"txId": "buy", "type": "transfer", "from": "alice", "to": "shop", "value": 40,
+		"txTimestamp": transaction.Timestamp, "channelId": transaction.ChannelID,
+		"initiatorMspId": transaction.InitiatorMSPID, "initiator": transaction.Initiator,
+		"cashback": 2, "pool": "rewards", "events": []string{chaincode.EventCashback},
+	})
+
+	l = withCashback(initializedLedger(t))
+	_, err = contract.SetLegacyEventWindow(l.Context, "2999-01-01T00:00:00Z")
+	require.NoError(t, err)
+	transaction, err = contract.TransferFrom(l.WithTxID("buy").Context, "alice", "shop", 40)
+	require.NoError(t, err)
+	l.AssertEvent(chaincode.EventTransfer, map[string]interface{}{
+		"txId": "buy", "type": "transfer", "from": "alice", "to": "shop", "value": 40,
+		"txTimestamp": transaction.Timestamp, "channelId": transaction.ChannelID,
+		"initiatorMspId": transaction.InitiatorMSPID, "initiator": transaction.Initiator,
+		"event": chaincode.EventTransferred, "cashback": 2, "pool": "rewards", "events": []string{chaincode.EventCashback},
+	})
+}
+
+func TestCashbackKeepsLargeTransfer(t *testing.T) {
+	l := initializedLedger(t).
+		WithAccount("rewards", "pool", 10).
+		WithAccount("alice", "user", 100).
+		WithAccount("shop", chaincode.SellerType, 0)
+	contract := &chaincode.SmartContract{}
+	_, err := contract.SetCashback(l.Context, "rewards", 500)
+	require.NoError(t, err)
+	_, err = contract.SetLargeTransferThreshold(l.Context, 30)
+	require.NoError(t, err)
+
+	transaction, err := contract.TransferFrom(l.WithTxID("buy").Context, "alice", "shop", 40)
+	require.NoError(t, err)
+	l.AssertBalance("alice", 62)
+	l.AssertEvent(chaincode.EventLargeTransfer, map[string]interface{}{
+		"txId": "buy", "type": "transfer", "from": "alice", "to": "shop", "value": 40,
+		"txTimestamp": transaction.Timestamp, "channelId": transaction.ChannelID,
+		"initiatorMspId": transaction.InitiatorMSPID, "initiator": transaction.Initiator,
+		"threshold": 30, "cashback": 2, "pool": "rewards", "events": []string{chaincode.EventCashback},
+	})
+}
+
+func TestSetCashbackRejects(t *testing.T) {
+	l := adminLedger(t)
+	contract := &chaincode.SmartContract{}
+
+	_, err := contract.SetCashback(l.Context, "rewards", 10001)
+	assert.EqualError(t, err, "[INVALID_ARGUMENT] invalid rate: must be between 0 and 10000 basis points")
+	_, err = contract.SetCashback(l.Context, "rewards", 100)
+	assert.EqualError(t, err, "[ACCOUNT_NOT_FOUND] user rewards does not exist")
+
+	l.WithCaller("Org1MSP", "alice", "client")
+	_, err = contract.SetCashback(l.Context, "", 0)
+	assert.EqualError(t, err, "[UNAUTHORIZED] only an organization admin can change cashback")
+}
@@ -0,0 +1,245 @@
+package chaincode
+
+import (
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+	"github.com/hyperledger/fabric-protos-go/ledger/queryresult"
+	"github.com/hyperledger/fabric-protos-go/peer"
+	"github.com/kkiu1756/my_fabric/src/chaincode-go/validation"
+)
+
+// The holder of a seller account keeps its catalog with AddItem,
+// UpdateItem and RemoveItem. An item is keyed item~seller~id, so
+// ListItems pages through one seller's catalog, and Purchase checks its
+// price and takes one from its stock in the purchase's transaction, see
+// purchase.go.
+
+// itemObjectType keys the catalog items by seller and item id
+const itemObjectType = "item"
+
+// Item is an item of a seller's catalog
+type Item struct {
+	Seller string `json:"seller"`
+	ID     string `json:"id"`
+	Name   string `json:"name"`
+	Price  int    `json:"price"`
+	// Stock is the number of items left to sell
+	Stock int `json:"stock"`
+	// ListedAt is the proposal timestamp of AddItem, RFC 3339
+	ListedAt string `json:"listedAt"`
+}
+
+// ItemPage is one page of a seller's catalog
+type ItemPage struct {
+	Items []*Item `json:"items"`
+	// Bookmark continues the listing, empty after the last page
+	Bookmark string `json:"bookmark"`
+}
+
+// AddItem adds itemID, name, to the catalog of seller at price with stock
+// items to sell. Only the holder of the seller account can call it.
+func (s *SmartContract) AddItem(ctx contractapi.TransactionContextInterface, sellerID string, itemID string, name string, price int, stock int) (*Item, error) {
+	if err := validateItem(sellerID, itemID, name, price, stock); err != nil {
+		return nil, err
+	}
+	if err := requireInitialized(ctx); err != nil {
+		return nil, err
+	}
+	if err := requireAccountHolder(ctx, sellerID, "change its catalog"); err != nil {
+		return nil, err
+	}
+
+	batch := newWriteBatch(ctx)
+	seller, err := batch.getUser(sellerID)
+	if err != nil {
+		return nil, err
+	}
+	if seller.Type != SellerType {
+		return nil, newError(CodeInvalidArgument, "account %s is not a seller", sellerID)
+	}
+	key, existing, err := catalogItem(batch, sellerID, itemID)
+	if err != nil {
+		return nil, err
+	}
+	if existing != nil {
+		return nil, newError(CodeInvalidArgument, "%s already lists item %s", sellerID, itemID)
+	}
+
+	timestamp, err := txTimestamp(ctx.GetStub())
+	if err != nil {
+		return nil, err
+	}
+	item := &Item{Seller: sellerID, ID: itemID, Name: name, Price: price, Stock: stock, ListedAt: timestamp}
+	err = batch.putState(key, item)
+	if err != nil {
+		return nil, err
+	}
+
+	err = batch.flush()
+	if err != nil {
+		return nil, err
+	}
+
+	return item, nil
+}
+
+// UpdateItem sets the name, price and stock of itemID of seller. Only the
+// holder of the seller account can call it.
+func (s *SmartContract) UpdateItem(ctx contractapi.TransactionContextInterface, sellerID string, itemID string, name string, price int, stock int) (*Item, error) {
+	if err := validateItem(sellerID, itemID, name, price, stock); err != nil {
+		return nil, err
+	}
+	if err := requireInitialized(ctx); err != nil {
+		return nil, err
+	}
+	if err := requireAccountHolder(ctx, sellerID, "change its catalog"); err != nil {
+		return nil, err
+	}
+
+	batch := newWriteBatch(ctx)
+	key, item, err := listedItem(batch, sellerID, itemID)
+	if err != nil {
+		return nil, err
+	}
+	item.Name, item.Price, item.Stock = name, price, stock
+	err = batch.putState(key, item)
+	if err != nil {
+		return nil, err
+	}
+
+	err = batch.flush()
+	if err != nil {
+		return nil, err
+	}
+
+	return item, nil
+}
+
+// RemoveItem removes itemID from the catalog of seller. The receipts of
+// its purchases remain. Only the holder of the seller account can call it.
+func (s *SmartContract) RemoveItem(ctx contractapi.TransactionContextInterface, sellerID string, itemID string) error {
+	err := validate(
+		validation.ID("sellerId", sellerID),
+		validation.ID("itemId", itemID),
+	)
+	if err != nil {
+		return err
+	}
+	if err := requireInitialized(ctx); err != nil {
+		return err
+	}
+	if err := requireAccountHolder(ctx, sellerID, "change its catalog"); err != nil {
+		return err
+	}
+
+	batch := newWriteBatch(ctx)
+	key, _, err := listedItem(batch, sellerID, itemID)
+	if err != nil {
+		return err
+	}
+	batch.delState(key)
+
+	return batch.flush()
+}
+
+// GetItem returns itemID of seller
+func (s *SmartContract) GetItem(ctx contractapi.TransactionContextInterface, sellerID string, itemID string) (*Item, error) {
+	err := validate(
+		validation.ID("sellerId", sellerID),
+		validation.ID("itemId", itemID),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	_, item, err := listedItem(newWriteBatch(ctx), sellerID, itemID)
+	if err != nil {
+		return nil, err
+	}
+
+	return item, nil
+}
+
+// ListItems returns a page of the catalog of seller in item id order
+func (s *SmartContract) ListItems(ctx contractapi.TransactionContextInterface, sellerID string, pageSize int, bookmark string) (*ItemPage, error) {
+	if err := validate(validation.ID("sellerId", sellerID)); err != nil {
+		return nil, err
+	}
+	pageSize, err := queryPolicy.pageSize(pageSize)
+	if err != nil {
+		return nil, err
+	}
+
+	stub := ctx.GetStub()
+	query := func(pageSize int32, bookmark string) (shim.StateQueryIteratorInterface, *peer.QueryResponseMetadata, error) {
+		return stub.GetStateByPartialCompositeKeyWithPagination(itemObjectType, []string{sellerID}, pageSize, bookmark)
+	}
+
+	page := &ItemPage{Items: []*Item{}}
+	page.Bookmark, err = queryPolicy.scan(query, itemObjectType, pageSize, bookmark, func(kv *queryresult.KV) error {
+		var item Item
+		if err := decodeRecord(kv.Key, kv.Value, &item, "seller", "id", "price", "stock"); err != nil {
+			return err
+		}
+		page.Items = append(page.Items, &item)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return page, nil
+}
+
+// validateItem checks the arguments of AddItem and UpdateItem
+func validateItem(sellerID string, itemID string, name string, price int, stock int) error {
+	errs := []error{
+		validation.ID("sellerId", sellerID),
+		validation.ID("itemId", itemID),
+		validation.Memo("name", name),
+		validation.Amount("price", price),
+		validation.Amount("stock", stock),
+	}
+	if name == "" {
+		errs = append(errs, &validation.Error{Field: "name", Reason: "must not be empty"})
+	}
+	if price == 0 {
+		errs = append(errs, &validation.Error{Field: "price", Reason: "must be positive"})
+	}
+
+	return validate(errs...)
+}
+
+// catalogItem reads itemID of seller, nil if the seller does not list it,
+// and returns its key
+func catalogItem(batch *writeBatch, sellerID string, itemID string) (string, *Item, error) {
+	key, err := batch.ctx.GetStub().CreateCompositeKey(itemObjectType, []string{sellerID, itemID})
+	if err != nil {
+		return "", nil, err
+	}
+	data, err := batch.getState(key)
+	if err != nil || data == nil {
+		return key, nil, err
+	}
+
+	var item Item
+	err = decodeRecord(key, data, &item, "seller", "id", "price", "stock")
+	if err != nil {
+		return "", nil, err
+	}
+
+	return key, &item, nil
+}
+
+// listedItem is catalogItem failing if the seller does not list the item
+func listedItem(batch *writeBatch, sellerID string, itemID string) (string, *Item, error) {
+	key, item, err := catalogItem(batch, sellerID, itemID)
+	if err == nil && item == nil {
+		err = newError(CodeInvalidArgument, "%s lists no item %s", sellerID, itemID)
+	}
+	if err != nil {
+		return "", nil, err
+	}
+
+	return key, item, nil
+}
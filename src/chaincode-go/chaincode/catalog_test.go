@@ -0,0 +1,83 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package chaincode_test
+
+import (
+	"testing"
+
+	"github.com/kkiu1756/my_fabric/src/chaincode-go/chaincode"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCatalog(t *testing.T) {
+	l := adminLedger(t).
+		WithAccount("shop", chaincode.SellerType, 0).
+		WithAccount("store", chaincode.SellerType, 0)
+	paginate(l.Stub, l.State)
+	contract := &chaincode.SmartContract{}
+
+	l.WithCaller("Org1MSP", "shop", "client")
+	for _, id := range []string{"lamp", "desk", "chair"} {
+		_, err := contract.AddItem(l.Context, "shop", id, "A "+id, 30, 5)
+		require.NoError(t, err)
+	}
+	l.WithCaller("Org1MSP", "store", "client")
+	_, err := contract.AddItem(l.Context, "store", "lamp", "Floor lamp", 50, 1)
+	require.NoError(t, err)
+
+	l.WithCaller("Org1MSP", "shop", "client")
+	item, err := contract.UpdateItem(l.Context, "shop", "desk", "Standing desk", 120, 0)
+	require.NoError(t, err)
+	assert.Equal(t, "Standing desk", item.Name)
+	err = contract.RemoveItem(l.Context, "shop", "chair")
+	require.NoError(t, err)
+
+	page, err := contract.ListItems(l.Context, "shop", 1, "")
+	require.NoError(t, err)
+	require.Len(t, page.Items, 1)
+	assert.Equal(t, "desk", page.Items[0].ID)
+	assert.Equal(t, 0, page.Items[0].Stock)
+	assert.NotEmpty(t, page.Bookmark)
+
+	page, err = contract.ListItems(l.Context, "shop", 1, page.Bookmark)
+	require.NoError(t, err)
+	require.Len(t, page.Items, 1)
+	assert.Equal(t, "lamp", page.Items[0].ID)
+	assert.Equal(t, "shop", page.Items[0].Seller, "a seller's listing holds only its items")
+
+	_, err = contract.GetItem(l.Context, "shop", "chair")
+	assert.EqualError(t, err, "[INVALID_ARGUMENT] shop lists no item chair")
+}
+
+func TestCatalogRejects(t *testing.T) {
+	l := adminLedger(t).
+		WithAccount("alice", "user", 100).
+		WithAccount("shop", chaincode.SellerType, 0)
+	contract := &chaincode.SmartContract{}
+
+	l.WithCaller("Org1MSP", "alice", "client")
+	_, err := contract.AddItem(l.Context, "shop", "lamp", "Desk lamp", 30, 1)
+	assert.EqualError(t, err, "[UNAUTHORIZED] only the holder of shop can change its catalog")
+	_, err = contract.AddItem(l.Context, "alice", "lamp", "Desk lamp", 30, 1)
+	assert.EqualError(t, err, "[INVALID_ARGUMENT] account alice is not a seller")
+
+	l.WithCaller("Org1MSP", "shop", "client")
+	_, err = contract.AddItem(l.Context, "shop", "lamp", "", 30, 1)
+	assert.EqualError(t, err, "[INVALID_ARGUMENT] invalid name: must not be empty")
+	_, err = contract.AddItem(l.Context, "shop", "lamp", "Desk lamp", 0, 1)
+	assert.EqualError(t, err, "[INVALID_ARGUMENT] invalid price: must be positive")
+	_, err = contract.AddItem(l.Context, "shop", "lamp", "Desk lamp", 30, -1)
+	assert.EqualError(t, err, "[INVALID_ARGUMENT] invalid stock: must not be negative")
+
+	_, err = contract.AddItem(l.Context, "shop", "lamp", "Desk lamp", 30, 1)
+	require.NoError(t, err)
+	_, err = contract.AddItem(l.Context, "shop", "lamp", "Desk lamp", 30, 1)
+	assert.EqualError(t, err, "[INVALID_ARGUMENT] shop already lists item lamp")
+	_, err = contract.UpdateItem(l.Context, "shop", "desk", "Desk", 30, 1)
+	assert.EqualError(t, err, "[INVALID_ARGUMENT] shop lists no item desk")
+	err = contract.RemoveItem(l.Context, "shop", "desk")
+	assert.EqualError(t, err, "[INVALID_ARGUMENT] shop lists no item desk")
+}
@@ -0,0 +1,208 @@
+package chaincode
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// The accounts ranked in the holder index, see holders.go, are counted by
+// type and balance bucket. A transaction moving accounts between buckets
+// writes its changes to the counts under its own key,
+// accountCountObjectType~type~bucket~txid, so concurrent transactions never
+// conflict on a counter, and GetAccountDistribution adds them up.
+// CompactAccountCounts folds them into one entry per counter. Like the
+// index, the buckets hold balances as last written.
+const accountCountObjectType = "accountcount"
+
+// Balance buckets: BucketNegative and BucketZero, then one bucket per
+// number of decimal digits, "1-9", "10-99" and so on
+const (
+	BucketNegative = "negative"
+	BucketZero     = "0"
+)
+
+// AccountDistribution counts the accounts by type and balance bucket
+type AccountDistribution struct {
+	Accounts int                 `json:"accounts"`
+	Types    []*AccountTypeCount `json:"types"`
+}
+
+// AccountTypeCount counts the accounts of one type
+type AccountTypeCount struct {
+	Type     string `json:"type"`
+	Accounts int    `json:"accounts"`
+	// Buckets are the non-empty balance buckets, smallest first
+	Buckets []*BucketCount `json:"buckets"`
+}
+
+// BucketCount counts the accounts of a type in one balance bucket
+type BucketCount struct {
+	Bucket   string `json:"bucket"`
+	Accounts int    `json:"accounts"`
+}
+
+// accountCount is a change to the count of accounts of Type in Bucket, a
+// key attribute of balanceBucket
+type accountCount struct {
+	Type   string `json:"type"`
+	Bucket string `json:"bucket"`
+	Count  int    `json:"count"`
+}
+
+// GetAccountDistribution returns the account counts by type and balance
+// bucket
+func (s *SmartContract) GetAccountDistribution(ctx contractapi.TransactionContextInterface) (*AccountDistribution, error) {
+	counts, _, err := accountCounts(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return distributionOf(counts), nil
+}
+
+// distributionOf groups counts, in key order, by type
+func distributionOf(counts []*counter) *AccountDistribution {
+	distribution := &AccountDistribution{Types: []*AccountTypeCount{}}
+	for _, counter := range counts {
+		if counter.count == 0 {
+			continue
+		}
+		n := len(distribution.Types)
+		if n == 0 || distribution.Types[n-1].Type != counter.accountType {
+			distribution.Types = append(distribution.Types, &AccountTypeCount{Type: counter.accountType, Buckets: []*BucketCount{}})
+			n++
+		}
+		types := distribution.Types[n-1]
+		types.Buckets = append(types.Buckets, &BucketCount{Bucket: bucketLabel(counter.bucket), Accounts: counter.count})
+		types.Accounts += counter.count
+		distribution.Accounts += counter.count
+	}
+
+	return distribution
+}
+
+// CompactAccountCounts folds the count changes written by each transaction
+// into one entry per counter. Only an org admin can call it.
+func (s *SmartContract) CompactAccountCounts(ctx contractapi.TransactionContextInterface) (*AccountDistribution, error) {
+	if err := requireInitialized(ctx); err != nil {
+		return nil, err
+	}
+	if _, err := requireAdmin(ctx, "compact account counts"); err != nil {
+		return nil, err
+	}
+
+	counts, keys, err := accountCounts(ctx)
+	if err != nil {
+		return nil, err
+	}
+	batch := newWriteBatch(ctx)
+	for _, key := range keys {
+		batch.delState(key)
+	}
+	for _, counter := range counts {
+		if counter.count == 0 {
+			continue
+		}
+		err = countAccounts(batch, counter.accountType, counter.bucket, counter.count)
+		if err != nil {
+			return nil, err
+		}
+	}
+	err = batch.flush()
+	if err != nil {
+		return nil, err
+	}
+
+	return distributionOf(counts), nil
+}
+
+// counter is the sum of the changes to one count
+type counter struct {
+	accountType string
+	bucket      string
+	count       int
+}
+
+// accountCounts sums the committed count changes per counter, in key order,
+// and returns their keys
+func accountCounts(ctx contractapi.TransactionContextInterface) ([]*counter, []string, error) {
+	var counts []*counter
+	var keys []string
+	var change accountCount
+	err := forEachRecord(ctx, accountCountObjectType, accountCountObjectType, []string{}, &change, []string{"type", "bucket", "count"}, func(key string) error {
+		n := len(counts)
+		if n == 0 || counts[n-1].accountType != change.Type || counts[n-1].bucket != change.Bucket {
+			counts = append(counts, &counter{accountType: change.Type, bucket: change.Bucket})
+			n++
+		}
+		counts[n-1].count += change.Count
+		keys = append(keys, key)
+		return nil
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return counts, keys, nil
+}
+
+// countAccount buffers a change of n to the count of accountType in the
+// bucket of balance
+func countAccount(batch *writeBatch, accountType string, balance int, n int) error {
+	return countAccounts(batch, accountType, balanceBucket(balance), n)
+}
+
+// countAccounts buffers a change of n to the count of accountType in
+// bucket under the current transaction's key, adding to a change the
+// transaction buffered before
+func countAccounts(batch *writeBatch, accountType string, bucket string, n int) error {
+	stub := batch.ctx.GetStub()
+	key, err := stub.CreateCompositeKey(accountCountObjectType, []string{accountType, bucket, stub.GetTxID()})
+	if err != nil {
+		return err
+	}
+	if data, ok := batch.writes[key]; ok && data != nil {
+		var buffered accountCount
+		if err := json.Unmarshal(data, &buffered); err != nil {
+			return corrupt(key, err)
+		}
+		n += buffered.Count
+	}
+
+	return batch.putState(key, accountCount{Type: accountType, Bucket: bucket, Count: n})
+}
+
+// balanceBucket returns the key attribute of the bucket of balance, which
+// sorts the buckets by balance
+func balanceBucket(balance int) string {
+	switch {
+	case balance < 0:
+		return "00"
+	case balance == 0:
+		return "01"
+	}
+
+	return fmt.Sprintf("%02d", len(strconv.Itoa(balance))+1)
+}
+
+// bucketLabel returns the name of the bucket with key attribute bucket
+func bucketLabel(bucket string) string {
+	index, err := strconv.Atoi(bucket)
+	switch {
+	case err != nil:
+		return bucket
+	case index == 0:
+		return BucketNegative
+	case index == 1:
+		return BucketZero
+	}
+
+	low := 1
+	for i := 2; i < index; i++ {
+		low *= 10
+	}
+	return fmt.Sprintf("%d-%d", low, low*10-1)
+}
@@ -0,0 +1,71 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package chaincode_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+	"github.com/kkiu1756/my_fabric/src/chaincode-go/chaincode"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetAccountDistribution(t *testing.T) {
+	l := adminLedger(t)
+	paginate(l.Stub, l.State)
+	l.Stub.GetStateByPartialCompositeKeyStub = func(objectType string, attributes []string) (shim.StateQueryIteratorInterface, error) {
+		it, _, err := l.Stub.GetStateByPartialCompositeKeyWithPagination(objectType, attributes, 1000, "")
+		return it, err
+	}
+	contract := &chaincode.SmartContract{}
+
+	for _, account := range []struct {
+		id, accountType string
+		balance         int
+	}{{"alice", "user", 100}, {"bob", "user", 5}, {"shop", "merchant", 0}} {
+		l.WithTxID("create-" + account.id)
+		_, err := contract.CreateUser(l.Context, account.id, account.accountType, account.balance)
+		require.NoError(t, err)
+	}
+	l.WithTxID("pay")
+	_, err := contract.TransferFrom(l.Context, "alice", "bob", 95)
+	require.NoError(t, err)
+
+	expected := &chaincode.AccountDistribution{
+		Accounts: 3,
+		Types: []*chaincode.AccountTypeCount{
+			{Type: "merchant", Accounts: 1, Buckets: []*chaincode.BucketCount{{Bucket: chaincode.BucketZero, Accounts: 1}}},
+			{Type: "user", Accounts: 2, Buckets: []*chaincode.BucketCount{{Bucket: "1-9", Accounts: 1}, {Bucket: "100-999", Accounts: 1}}},
+		},
+	}
+	distribution, err := contract.GetAccountDistribution(l.Context)
+	require.NoError(t, err)
+	assert.Equal(t, expected, distribution)
+
+	countKeys := func() int {
+		n := 0
+		for key := range l.State {
+			if strings.HasPrefix(key, "\x00accountcount\x00") {
+				n++
+			}
+		}
+		return n
+	}
+	assert.Equal(t, 5, countKeys())
+	l.WithTxID("compact")
+	distribution, err = contract.CompactAccountCounts(l.Context)
+	require.NoError(t, err)
+	assert.Equal(t, expected, distribution)
+	assert.Equal(t, 3, countKeys(), "one entry per non-empty counter")
+	distribution, err = contract.GetAccountDistribution(l.Context)
+	require.NoError(t, err)
+	assert.Equal(t, expected, distribution)
+
+	l.WithCaller("Org1MSP", "alice", "client")
+	_, err = contract.CompactAccountCounts(l.Context)
+	assert.EqualError(t, err, "[UNAUTHORIZED] only an organization admin can compact account counts")
+}
@@ -0,0 +1,99 @@
+package chaincode
+
+import (
+	"encoding/json"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// Every account record a transaction writes or deletes is a balance
+// change, from the balance stored before the transaction to the balance it
+// stores. As a Fabric transaction carries a single event, the changes are
+// added as balanceChanges to the event the transaction emits, and a
+// transaction that emits none emits EventBalanceChanged with them, so a
+// cache of balances can follow the ledger from events alone. An account
+// changed several times in one transaction is listed once. Credits pending
+// on a hot account, see delta.go, do not change its stored balance; they
+// are listed when they are pruned. A record written with its balance
+// unchanged is not listed. Only ledgers with verbose events list balance
+// changes, see verbosity.go.
+
+// EventBalanceChanged is the event of a transaction that changes balances
+// and emits no other event
+const EventBalanceChanged = "BalanceChanged"
+
+// BalanceChange is the change of one account's stored balance by a
+// transaction
+type BalanceChange struct {
+	Account string `json:"account"`
+	// Before is 0 for an account the transaction created
+	Before int `json:"before"`
+	// After is 0 for an account the transaction deleted
+	After   int  `json:"after"`
+	Deleted bool `json:"deleted,omitempty"`
+}
+
+// balanceChangedEvent is the payload of EventBalanceChanged
+type balanceChangedEvent struct {
+	BalanceChanges []*BalanceChange `json:"balanceChanges"`
+}
+
+// GetAfterTransaction returns the hook the contract runs after every
+// function, which adds the balance changes to the transaction's event
+func (s *SmartContract) GetAfterTransaction() interface{} {
+	return afterTransaction
+}
+
+func afterTransaction(ctx contractapi.TransactionContextInterface) error {
+	recorder, ok := ctx.(changeRecorder)
+	if !ok {
+		return nil
+	}
+	recorded, event, payloadJSON := recorder.recorded()
+	changes := []*BalanceChange{}
+	for _, change := range recorded {
+		if change.Before != change.After || change.Deleted {
+			changes = append(changes, change)
+		}
+	}
+	if len(changes) == 0 {
+		return nil
+	}
+	verbosity, err := eventVerbosity(ctx)
+	if err != nil || verbosity != VerbosityVerbose {
+		return err
+	}
+	if event == "" {
+		return emitEvent(ctx, EventBalanceChanged, balanceChangedEvent{changes})
+	}
+
+	// an event payload that is not an object has no room for the changes
+	data, ok := withField(payloadJSON, "balanceChanges", changes)
+	if !ok {
+		return nil
+	}
+	return emitEvent(ctx, event, json.RawMessage(data))
+}
+
+// noteBalanceChange notes the change of account from its stored record
+// previous, nil for a new account, to balance for the transaction's event
+func noteBalanceChange(ctx contractapi.TransactionContextInterface, account string, previous []byte, balance int) {
+	recorder, ok := ctx.(changeRecorder)
+	if !ok {
+		return
+	}
+
+	change := BalanceChange{Account: account, After: balance}
+	if stored, err := userFromRecord(account, previous); err == nil {
+		change.Before = stored.Balance
+	}
+	recorder.recordChange(change)
+}
+
+// noteDeletion notes the deletion of account, stored with balance, for
+// the transaction's event
+func noteDeletion(ctx contractapi.TransactionContextInterface, account string, balance int) {
+	if recorder, ok := ctx.(changeRecorder); ok {
+		recorder.recordChange(BalanceChange{Account: account, Before: balance, Deleted: true})
+	}
+}
@@ -0,0 +1,91 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package chaincode_test
+
+import (
+	"testing"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+	"github.com/kkiu1756/my_fabric/src/chaincode-go/chaincode"
+	"github.com/kkiu1756/my_fabric/src/chaincode-go/tokentest"
+	"github.com/stretchr/testify/require"
+)
+
+// changeContext returns a contract API context on the stub of l, which
+// collects balance changes unlike l's mock context, and the after hook
+func changeContext(l *tokentest.Ledger) (*chaincode.TransactionContext, func(contractapi.TransactionContextInterface) error) {
+	ctx := new(chaincode.TransactionContext)
+	ctx.SetStub(l.Stub)
+	after := (&chaincode.SmartContract{}).GetAfterTransaction().(func(contractapi.TransactionContextInterface) error)
+	return ctx, after
+}
+
+func TestBalanceChangesJoinTheEvent(t *testing.T) {
+	l := tokentest.NewLedger(t).
+		WithAccount("alice", "user", 100).
+		WithAccount("bob", "user", 20)
+	ctx, after := changeContext(l)
+	contract := &chaincode.SmartContract{}
+
+	_, err := contract.TransferFrom(ctx, "alice", "bob", 30)
+	require.NoError(t, err)
+	l.AssertEvent(chaincode.EventTransfer, map[string]interface{}{"from": "alice", "to": "bob", "value": 30})
+
+	require.NoError(t, after(ctx))
+	l.AssertEvent(chaincode.EventTransfer, map[string]interface{}{
+		"from": "alice", "to": "bob", "value": 30,
+		"balanceChanges": []map[string]interface{}{
+			{"account": "alice", "before": 100, "after": 70},
+			{"account": "bob", "before": 20, "after": 50},
+		},
+	})
+}
+
+func TestBalanceChangedWithoutEvent(t *testing.T) {
+	l := tokentest.NewLedger(t).
+		WithAccount("alice", "user", 100).
+		WithAccount("bob", "user", 20)
+	ctx, after := changeContext(l)
+	contract := &chaincode.SmartContract{}
+
+	require.NoError(t, contract.DeleteUser(ctx, "bob"))
+	l.AssertNoEvent()
+	require.NoError(t, after(ctx))
+	l.AssertEvent(chaincode.EventBalanceChanged, map[string]interface{}{
+		"balanceChanges": []map[string]interface{}{
+			{"account": "bob", "before": 20, "after": 0, "deleted": true},
+		},
+	})
+}
+
+func TestBalanceChangesMerged(t *testing.T) {
+	l := tokentest.NewLedger(t).
+		WithAccount("alice", "user", 100).
+		WithAccount("bob", "user", 20)
+	ctx, after := changeContext(l)
+	contract := &chaincode.SmartContract{}
+
+	_, err := contract.TransferFrom(ctx, "alice", "bob", 30)
+	require.NoError(t, err)
+	require.NoError(t, contract.DeleteUser(ctx, "bob"))
+
+	require.NoError(t, after(ctx))
+	l.AssertEvent(chaincode.EventTransfer, map[string]interface{}{
+		"from": "alice", "to": "bob", "value": 30,
+		"balanceChanges": []map[string]interface{}{
+			{"account": "alice", "before": 100, "after": 70},
+			{"account": "bob", "before": 20, "after": 0, "deleted": true},
+		},
+	})
+}
+
+func TestBalanceChangesNeedCollectingContext(t *testing.T) {
+	l := tokentest.NewLedger(t).WithAccount("alice", "user", 100)
+	after := (&chaincode.SmartContract{}).GetAfterTransaction().(func(contractapi.TransactionContextInterface) error)
+
+	require.NoError(t, (&chaincode.SmartContract{}).DeleteUser(l.Context, "alice"))
+	require.NoError(t, after(l.Context))
+	l.AssertNoEvent()
+}
@@ -0,0 +1,487 @@
+package chaincode
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"strings"
+
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+	"github.com/hyperledger/fabric-protos-go/ledger/queryresult"
+	"github.com/hyperledger/fabric-protos-go/peer"
+	"github.com/kkiu1756/my_fabric/src/chaincode-go/validation"
+)
+
+// A ledger is cloned into a fresh deployment, such as a staging channel,
+// by exporting its state with ExportState and importing the pages with
+// ImportState. The exporting admin signs each page's checksum with their
+// key; the target only imports pages signed by the certificate its admin
+// registered with SetImportSigner.
+
+// Sections of the state, exported one after the other
+const (
+	// SectionRecords holds the records under simple keys: transactions,
+	// and accounts on an unsharded ledger
+	SectionRecords = "records"
+	// SectionAccounts holds the accounts of a sharded ledger
+	SectionAccounts = "accounts"
+	// SectionDeltas holds the pending credits to hot accounts
+	SectionDeltas = "deltas"
+	// SectionAudit holds the audit trails, SectionAuditSeq the next
+	// sequence number of each
+	SectionAudit    = "audit"
+	SectionAuditSeq = "auditSeq"
+	// SectionAuditorReads holds the auditor read log
+	SectionAuditorReads = "auditorReads"
+	// SectionLimits holds the transfer limits, SectionSpends the debits
+	// counted towards daily limits and SectionPendingTransfers the
+	// transfers awaiting approval
+	SectionLimits           = "limits"
+	SectionSpends           = "spends"
+	SectionPendingTransfers = "pendingTransfers"
+	// SectionReviews holds the large transfer reviews
+	SectionReviews = "reviews"
+	// SectionIndex holds the statement index
+	SectionIndex = "index"
+	// SectionConfirmations holds the transfers awaiting confirmation
+	SectionConfirmations = "confirmations"
+	// SectionSuspensions holds the account suspensions
+	SectionSuspensions = "suspensions"
+	// SectionHolders holds the account holders set by recoveries,
+	// SectionGuardians the guardian sets and SectionRecoveries the
+	// recoveries underway
+	SectionHolders    = "holders"
+	SectionGuardians  = "guardians"
+	SectionRecoveries = "recoveries"
+	// SectionPayments holds the payment requests awaiting their payers
+	SectionPayments = "payments"
+	// SectionReversibles holds the reversible transfers in escrow
+	SectionReversibles = "reversibles"
+	// SectionDisputes holds the disputes of reversible transfers
+	SectionDisputes = "disputes"
+	// SectionDormancy holds the dormancy flags
+	SectionDormancy = "dormancy"
+	// SectionDemurrage holds the times balances last changed under
+	// demurrage
+	SectionDemurrage = "demurrage"
+	// SectionSnapshots holds the balance snapshots
+	SectionSnapshots = "snapshots"
+	// SectionDistributions holds the progress of pro rata distributions
+	SectionDistributions = "distributions"
+	// SectionAirdropClaims holds the airdrop claims
+	SectionAirdropClaims = "airdropClaims"
+	// SectionWhitelists holds the whitelist roots
+	SectionWhitelists = "whitelists"
+	// SectionReferrals holds the referrals
+	SectionReferrals = "referrals"
+	// SectionReferrers holds the counts of rewarded referrals
+	SectionReferrers = "referrers"
+	// SectionSettlementAccounts holds the sellers' settlement accounts
+	SectionSettlementAccounts = "settlementAccounts"
+	// SectionSettlementPeriods holds the totals of settlement periods
+	SectionSettlementPeriods = "settlementPeriods"
+	// SectionPayouts holds the seller payouts
+	SectionPayouts = "payouts"
+	// SectionItems holds the sellers' catalog items
+	SectionItems = "items"
+	// SectionReceipts holds the purchase receipts
+	SectionReceipts = "receipts"
+	// SectionRefunds holds the refunds of transfers
+	SectionRefunds = "refunds"
+	// SectionCurrencies holds the registered currencies
+	SectionCurrencies = "currencies"
+	// SectionCurrencyBalances holds the balances in the currencies
+	SectionCurrencyBalances = "currencyBalances"
+	// SectionCurrencyTransactions holds the currency operations
+	SectionCurrencyTransactions = "currencyTransactions"
+	// SectionRates holds the published currency rates
+	SectionRates = "rates"
+	// SectionAttestations holds the reserve attestations
+	SectionAttestations = "attestations"
+	// SectionRebases holds the rebase history
+	SectionRebases = "rebases"
+	// SectionInterest holds the times interest last accrued on accounts
+	SectionInterest = "interest"
+	// SectionCreditLines holds the credit lines of accounts
+	SectionCreditLines = "creditLines"
+	// SectionGiftCards holds the gift cards
+	SectionGiftCards = "giftCards"
+	// SectionAliases holds the account aliases
+	SectionAliases = "aliases"
+	// SectionWallets holds the named wallets of client identities
+	SectionWallets = "wallets"
+	// SectionHierarchy holds the links of accounts to their parents
+	SectionHierarchy = "hierarchy"
+	// SectionTreasuries and SectionTreasuryPayments hold the treasury
+	// accounts and their payments
+	SectionTreasuries       = "treasuries"
+	SectionTreasuryPayments = "treasuryPayments"
+	// SectionDelegates holds the delegates of accounts
+	SectionDelegates = "delegates"
+	// SectionRevokedCertificates holds the revoked client certificates
+	SectionRevokedCertificates = "revokedCertificates"
+	// SectionHolderRanks holds the index of accounts by balance
+	SectionHolderRanks = "holderRanks"
+	// SectionAccountCounts holds the changes to the account counts
+	SectionAccountCounts = "accountCounts"
+	// SectionCorrelations holds the index of transactions by correlation id
+	SectionCorrelations = "correlations"
+	// SectionAccountTypes and SectionTransactionTimes hold the indexes of
+	// accounts by type and of transactions by time
+	SectionAccountTypes     = "accountTypes"
+	SectionTransactionTimes = "transactionTimes"
+)
+
+// Sections lists every section in export order
+var Sections = []string{
+	SectionRecords, SectionAccounts, SectionDeltas, SectionAudit, SectionAuditSeq,
+	SectionAuditorReads, SectionLimits, SectionSpends, SectionPendingTransfers, SectionReviews,
+	SectionIndex, SectionConfirmations, SectionSuspensions, SectionHolders, SectionGuardians,
+	SectionRecoveries, SectionPayments, SectionReversibles, SectionDisputes, SectionDormancy,
+	SectionDemurrage, SectionSnapshots, SectionDistributions, SectionAirdropClaims,
+	SectionWhitelists, SectionReferrals, SectionReferrers, SectionSettlementAccounts, SectionSettlementPeriods,
+	SectionPayouts, SectionItems, SectionReceipts, SectionRefunds, SectionCurrencies,
+	SectionCurrencyBalances, SectionCurrencyTransactions, SectionRates, SectionAttestations,
+	SectionRebases, SectionInterest, SectionCreditLines, SectionGiftCards,
+	SectionAliases, SectionWallets, SectionHierarchy, SectionTreasuries, SectionTreasuryPayments,
+	SectionDelegates, SectionRevokedCertificates, SectionHolderRanks,
+	SectionAccountCounts, SectionCorrelations, SectionAccountTypes, SectionTransactionTimes,
+}
+
+// exportSections maps each section to the object type of its composite
+// keys, "" for simple keys
+var exportSections = map[string]string{
+	SectionRecords:              "",
+	SectionAccounts:             accountObjectType,
+	SectionDeltas:               deltaObjectType,
+	SectionAudit:                auditObjectType,
+	SectionAuditSeq:             auditSeqObjectType,
+	SectionAuditorReads:         auditorReadObjectType,
+	SectionLimits:               limitObjectType,
+	SectionSpends:               spendObjectType,
+	SectionPendingTransfers:     pendingTransferObjectType,
+	SectionReviews:              reviewObjectType,
+	SectionIndex:                txIndexObjectType,
+	SectionConfirmations:        confirmationObjectType,
+	SectionSuspensions:          suspensionObjectType,
+	SectionHolders:              holderObjectType,
+	SectionGuardians:            guardianObjectType,
+	SectionRecoveries:           recoveryObjectType,
+	SectionPayments:             paymentObjectType,
+	SectionReversibles:          reversibleObjectType,
+	SectionDisputes:             disputeObjectType,
+	SectionDormancy:             dormancyObjectType,
+	SectionDemurrage:            demurrageObjectType,
+	SectionSnapshots:            snapshotObjectType,
+	SectionDistributions:        distributionObjectType,
+	SectionAirdropClaims:        airdropClaimObjectType,
+	SectionWhitelists:           whitelistObjectType,
+	SectionReferrals:            referralObjectType,
+	SectionReferrers:            referrerObjectType,
+	SectionSettlementAccounts:   settlementAccountObjectType,
+	SectionSettlementPeriods:    settlementPeriodObjectType,
+	SectionPayouts:              payoutObjectType,
+	SectionItems:                itemObjectType,
+	SectionReceipts:             receiptObjectType,
+	SectionRefunds:              refundObjectType,
+	SectionCurrencies:           currencyObjectType,
+	SectionCurrencyBalances:     currencyBalanceObjectType,
+	SectionCurrencyTransactions: currencyTxObjectType,
+	SectionRates:                rateObjectType,
+	SectionAttestations:         attestationObjectType,
+	SectionRebases:              rebaseObjectType,
+	SectionInterest:             interestObjectType,
+	SectionCreditLines:          creditLineObjectType,
+	SectionGiftCards:            giftCardObjectType,
+	SectionAliases:              aliasObjectType,
+	SectionWallets:              walletObjectType,
+	SectionHierarchy:            hierarchyObjectType,
+	SectionTreasuries:           treasuryObjectType,
+	SectionTreasuryPayments:     treasuryPaymentObjectType,
+	SectionDelegates:            delegateObjectType,
+	SectionRevokedCertificates:  revokedCertObjectType,
+	SectionHolderRanks:          holderRankObjectType,
+	SectionAccountCounts:        accountCountObjectType,
+	SectionCorrelations:         correlationObjectType,
+	SectionAccountTypes:         accountTypeObjectType,
+	SectionTransactionTimes:     txTimeObjectType,
+}
+
+// importSignerKey stores the certificate ImportState verifies pages with
+const importSignerKey = "\x00config\x00importSigner\x00"
+
+// StateRecord is a world state entry as stored
+type StateRecord struct {
+	Key   string `json:"key"`
+	Value []byte `json:"value"`
+}
+
+// StatePage is one page of an ExportState dump
+type StatePage struct {
+	Section string `json:"section"`
+	// Shards and Schema describe the exported ledger, the target must match
+	Shards  int           `json:"shards"`
+	Schema  int           `json:"schema"`
+	Records []StateRecord `json:"records"`
+	// Checksum is the hex SHA-256 digest of the fields above, the digest
+	// the exporter signs
+	Checksum string `json:"checksum"`
+	// Bookmark continues the section, empty after its last page
+	Bookmark string `json:"bookmark"`
+}
+
+// ImportResult reports what one ImportState page did
+type ImportResult struct {
+	// Imported counts the records written by this call
+	Imported int `json:"imported"`
+	// Existing counts the records already stored with the same value,
+	// from an earlier attempt at the same page
+	Existing int `json:"existing"`
+}
+
+// ExportState returns a page of at most pageSize records of section, one of
+// Sections, from bookmark, which is empty for the first page. Only an org
+// admin can call it.
+func (s *SmartContract) ExportState(ctx contractapi.TransactionContextInterface, section string, pageSize int, bookmark string) (*StatePage, error) {
+	objectType, ok := exportSections[section]
+	if !ok {
+		return nil, validate(&validation.Error{Field: "section", Reason: "must be one of " + strings.Join(Sections, ", ")})
+	}
+	if err := requireInitialized(ctx); err != nil {
+		return nil, err
+	}
+	if _, err := requireAdmin(ctx, "export the ledger"); err != nil {
+		return nil, err
+	}
+	pageSize, err := queryPolicy.pageSize(pageSize)
+	if err != nil {
+		return nil, err
+	}
+
+	page, err := newStatePage(ctx, section)
+	if err != nil {
+		return nil, err
+	}
+
+	stub := ctx.GetStub()
+	query := func(pageSize int32, bookmark string) (shim.StateQueryIteratorInterface, *peer.QueryResponseMetadata, error) {
+		if objectType == "" {
+			return stub.GetStateByRangeWithPagination("", "", pageSize, bookmark)
+		}
+		return stub.GetStateByPartialCompositeKeyWithPagination(objectType, []string{}, pageSize, bookmark)
+	}
+
+	page.Bookmark, err = queryPolicy.scan(query, section, pageSize, bookmark, func(kv *queryresult.KV) error {
+		page.Records = append(page.Records, StateRecord{Key: kv.Key, Value: kv.Value})
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	page.Checksum = page.checksum()
+
+	return page, nil
+}
+
+// SetImportSigner registers the PEM encoded certificate whose ECDSA key
+// signs the pages ImportState accepts, that of the admin exporting the
+// source ledger. Only an org admin can call it.
+func (s *SmartContract) SetImportSigner(ctx contractapi.TransactionContextInterface, certificatePEM string) error {
+	if _, err := parseSigner([]byte(certificatePEM)); err != nil {
+		return validate(&validation.Error{Field: "certificate", Reason: err.Error()})
+	}
+	if err := requireInitialized(ctx); err != nil {
+		return err
+	}
+	if _, err := requireAdmin(ctx, "set the import signer"); err != nil {
+		return err
+	}
+
+	err := ctx.GetStub().PutState(importSignerKey, []byte(certificatePEM))
+	if err != nil {
+		return &StateError{Op: OpWriteState, Key: importSignerKey, Err: err}
+	}
+
+	return nil
+}
+
+// ImportState writes the records of an ExportState page, given as JSON, to
+// a fresh deployment whose shard count and schema match the source. The
+// page's checksum must match its records, and signature, the base64 ASN.1
+// ECDSA signature of the checksum digest, must verify against the
+// certificate registered with SetImportSigner. Records already stored with
+// the same value are skipped, so an import that stopped part way is
+// resumed by resubmitting its pages; a record stored with a different value
+// fails the page. Only an org admin can call it.
+func (s *SmartContract) ImportState(ctx contractapi.TransactionContextInterface, pageJSON string, signature string) (*ImportResult, error) {
+	if err := requireInitialized(ctx); err != nil {
+		return nil, err
+	}
+	if _, err := requireAdmin(ctx, "import the ledger"); err != nil {
+		return nil, err
+	}
+
+	page, err := decodeStatePage(pageJSON)
+	if err != nil {
+		return nil, err
+	}
+	err = verifyStatePage(ctx, page, signature)
+	if err != nil {
+		return nil, err
+	}
+
+	target, err := newStatePage(ctx, page.Section)
+	if err != nil {
+		return nil, err
+	}
+	if page.Shards != target.Shards || page.Schema != target.Schema {
+		return nil, newError(CodeInvalidArgument, "a dump of a ledger with %d shards at schema v%d cannot be imported into one with %d shards at schema v%d",
+			page.Shards, page.Schema, target.Shards, target.Schema)
+	}
+
+	stub := ctx.GetStub()
+	result := &ImportResult{}
+	for i, record := range page.Records {
+		err = checkSectionKey(page.Section, record.Key)
+		if err != nil {
+			return nil, validate(&validation.Error{Field: fmt.Sprintf("records[%d].key", i), Reason: err.Error()})
+		}
+
+		existing, err := getState(ctx, record.Key)
+		if err != nil {
+			return nil, err
+		}
+		if existing != nil {
+			if !bytes.Equal(existing, record.Value) {
+				return nil, newError(CodeInvalidArgument, "record %q exists with a different value", record.Key)
+			}
+			result.Existing++
+			continue
+		}
+
+		err = stub.PutState(record.Key, record.Value)
+		if err != nil {
+			return nil, &StateError{Op: OpWriteState, Key: record.Key, Err: err}
+		}
+		result.Imported++
+	}
+
+	return result, nil
+}
+
+// newStatePage returns an empty page of section describing the ledger
+func newStatePage(ctx contractapi.TransactionContextInterface, section string) (*StatePage, error) {
+	init, err := ledgerConfig(ctx)
+	if err != nil {
+		return nil, err
+	}
+	shards, err := shardCount(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return &StatePage{Section: section, Shards: shards, Schema: schemaVersion(init), Records: []StateRecord{}}, nil
+}
+
+// checksum returns the hex SHA-256 digest of the page's section, ledger
+// description and length-prefixed records
+func (p *StatePage) checksum() string {
+	h := sha256.New()
+	fmt.Fprintf(h, "%s\n%d\n%d\n", p.Section, p.Shards, p.Schema)
+	for _, record := range p.Records {
+		fmt.Fprintf(h, "%d:%s%d:", len(record.Key), record.Key, len(record.Value))
+		h.Write(record.Value)
+	}
+
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// decodeStatePage strictly decodes and validates an ImportState page
+func decodeStatePage(pageJSON string) (*StatePage, error) {
+	decoder := json.NewDecoder(strings.NewReader(pageJSON))
+	decoder.DisallowUnknownFields()
+
+	var page StatePage
+	if err := decoder.Decode(&page); err != nil {
+		return nil, validate(&validation.Error{Field: "page", Reason: err.Error()})
+	}
+	if _, ok := exportSections[page.Section]; !ok {
+		return nil, validate(&validation.Error{Field: "page.section", Reason: fmt.Sprintf("unknown section %q", page.Section)})
+	}
+
+	return &page, nil
+}
+
+// verifyStatePage checks the page's checksum and its signature against the
+// registered import signer
+func verifyStatePage(ctx contractapi.TransactionContextInterface, page *StatePage, signature string) error {
+	if page.Checksum != page.checksum() {
+		return newError(CodeCorruptRecord, "%s page checksum does not match its records", page.Section)
+	}
+
+	certificate, err := getState(ctx, importSignerKey)
+	if err != nil {
+		return err
+	}
+	if certificate == nil {
+		return newError(CodeUnauthorized, "no import signer, an organization admin must call SetImportSigner")
+	}
+	key, err := parseSigner(certificate)
+	if err != nil {
+		return corrupt(importSignerKey, err)
+	}
+
+	sig, err := base64.StdEncoding.DecodeString(signature)
+	if err != nil {
+		return validate(&validation.Error{Field: "signature", Reason: err.Error()})
+	}
+	digest, _ := hex.DecodeString(page.Checksum)
+	if !ecdsa.VerifyASN1(key, digest, sig) {
+		return newError(CodeUnauthorized, "%s page signature does not verify against the import signer", page.Section)
+	}
+
+	return nil
+}
+
+// parseSigner returns the ECDSA public key of a PEM encoded certificate
+func parseSigner(certificatePEM []byte) (*ecdsa.PublicKey, error) {
+	block, _ := pem.Decode(certificatePEM)
+	if block == nil {
+		return nil, fmt.Errorf("not a PEM encoded certificate")
+	}
+	cert, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+	key, ok := cert.PublicKey.(*ecdsa.PublicKey)
+	if !ok {
+		return nil, fmt.Errorf("certificate key is not ECDSA")
+	}
+
+	return key, nil
+}
+
+// checkSectionKey checks that key belongs to section, so a page cannot
+// write configuration records
+func checkSectionKey(section string, key string) error {
+	objectType := exportSections[section]
+	if objectType == "" {
+		if key == "" || strings.HasPrefix(key, compositeKeyNamespace) {
+			return fmt.Errorf("must be a simple key")
+		}
+		return nil
+	}
+
+	if !strings.HasPrefix(key, compositeKeyNamespace+objectType+compositeKeyNamespace) {
+		return fmt.Errorf("must be a %s key", objectType)
+	}
+
+	return nil
+}
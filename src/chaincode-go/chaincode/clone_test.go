@@ -0,0 +1,232 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package chaincode_test
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/kkiu1756/my_fabric/src/chaincode-go/chaincode"
+	"github.com/kkiu1756/my_fabric/src/chaincode-go/tokentest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// #########
+// HELPERS
+// #########
+
+// exportSigner returns a key and its PEM encoded self-signed certificate
+func exportSigner(t *testing.T) (*ecdsa.PrivateKey, string) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "Admin@org1.example.com"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+
+	return key, string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}))
+}
+
+// sign returns the ImportState arguments for page signed with key
+func sign(t *testing.T, key *ecdsa.PrivateKey, page *chaincode.StatePage) (string, string) {
+	digest, err := hex.DecodeString(page.Checksum)
+	require.NoError(t, err)
+	sig, err := ecdsa.SignASN1(rand.Reader, key, digest)
+	require.NoError(t, err)
+
+	data, err := json.Marshal(page)
+	require.NoError(t, err)
+	return string(data), base64.StdEncoding.EncodeToString(sig)
+}
+
+// checksumOf computes a page's checksum the way the contract does
+func checksumOf(page *chaincode.StatePage) string {
+	h := sha256.New()
+	fmt.Fprintf(h, "%s\n%d\n%d\n", page.Section, page.Shards, page.Schema)
+	for _, record := range page.Records {
+		fmt.Fprintf(h, "%d:%s%d:", len(record.Key), record.Key, len(record.Value))
+		h.Write(record.Value)
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// exportAll exports every section of l a page of pageSize at a time
+func exportAll(t *testing.T, l *tokentest.Ledger, pageSize int) []*chaincode.StatePage {
+	var pages []*chaincode.StatePage
+	for _, section := range chaincode.Sections {
+		bookmark := ""
+		for {
+			page, err := (&chaincode.SmartContract{}).ExportState(l.Context, section, pageSize, bookmark)
+			require.NoError(t, err)
+			pages = append(pages, page)
+			if page.Bookmark == "" {
+				break
+			}
+			bookmark = page.Bookmark
+		}
+	}
+	return pages
+}
+
+// tokenState returns the state of l without its configuration records
+func tokenState(l *tokentest.Ledger) map[string][]byte {
+	state := map[string][]byte{}
+	for key, value := range l.State {
+		if !strings.HasPrefix(key, "\x00config\x00") {
+			state[key] = value
+		}
+	}
+	return state
+}
+
+// #########
+// TESTS
+// #########
+
+func TestCloneLedger(t *testing.T) {
+	source := shardedLedger(t, 2)
+	contract := &chaincode.SmartContract{}
+	for _, id := range []string{"alice", "bob", "treasury"} {
+		_, err := contract.CreateUser(source.Context, id, "user", 100)
+		require.NoError(t, err)
+	}
+	_, err := contract.SetHotAccount(source.Context, "treasury", true)
+	require.NoError(t, err)
+	source.WithTxID("pay")
+	_, err = contract.TransferFrom(source.Context, "alice", "treasury", 30)
+	require.NoError(t, err)
+
+	pages := exportAll(t, source, 2)
+	assert.Len(t, pages, 62, "the transaction records, two pages of accounts, the delta, two pages of audit records, two of their sequences, forty-five empty sections, three pages of index entries and two each of holder ranks, account counts and account types")
+
+	key, cert := exportSigner(t)
+	target := shardedLedger(t, 2)
+	require.NoError(t, contract.SetImportSigner(target.Context, cert))
+	for _, page := range pages {
+		pageJSON, signature := sign(t, key, page)
+		result, err := contract.ImportState(target.Context, pageJSON, signature)
+		require.NoError(t, err)
+		assert.Equal(t, &chaincode.ImportResult{Imported: len(page.Records)}, result)
+	}
+	assert.Equal(t, tokenState(source), tokenState(target))
+	user, err := contract.GetUser(target.Context, "alice")
+	require.NoError(t, err)
+	assert.Equal(t, 70, user.Balance)
+
+	pageJSON, signature := sign(t, key, pages[1])
+	result, err := contract.ImportState(target.Context, pageJSON, signature)
+	require.NoError(t, err)
+	assert.Equal(t, &chaincode.ImportResult{Existing: 2}, result, "a resubmitted page should change nothing")
+}
+
+func TestImportStateRejects(t *testing.T) {
+	key, cert := exportSigner(t)
+	otherKey, _ := exportSigner(t)
+	source := adminLedger(t).WithAccount("alice", "user", 100)
+	paginate(source.Stub, source.State)
+	page := exportAll(t, source, 0)[0]
+
+	tampered := *page
+	tampered.Records = []chaincode.StateRecord{{Key: "alice", Value: []byte(`{"userId":"alice","type":"user","balance":1000}`)}}
+	config := *page
+	config.Records = []chaincode.StateRecord{{Key: chaincode.InitializationKey, Value: []byte(`{}`)}}
+	config.Checksum = checksumOf(&config)
+
+	tests := []struct {
+		name  string
+		setup func(l *tokentest.Ledger) (string, string)
+		err   string
+	}{
+		{
+			"no signer", func(l *tokentest.Ledger) (string, string) {
+				l.State["\x00config\x00importSigner\x00"] = nil
+				return sign(t, key, page)
+			},
+			"[UNAUTHORIZED] no import signer, an organization admin must call SetImportSigner",
+		},
+		{
+			"other signer", func(*tokentest.Ledger) (string, string) { return sign(t, otherKey, page) },
+			"[UNAUTHORIZED] records page signature does not verify against the import signer",
+		},
+		{
+			"tampered", func(*tokentest.Ledger) (string, string) { return sign(t, key, &tampered) },
+			"[CORRUPT_RECORD] records page checksum does not match its records",
+		},
+		{
+			"configuration key", func(*tokentest.Ledger) (string, string) { return sign(t, key, &config) },
+			"[INVALID_ARGUMENT] invalid records[0].key: must be a simple key",
+		},
+		{
+			"different value", func(l *tokentest.Ledger) (string, string) {
+				l.WithAccount("alice", "user", 5)
+				return sign(t, key, page)
+			},
+			`[INVALID_ARGUMENT] record "alice" exists with a different value`,
+		},
+		{
+			"sharded target", func(l *tokentest.Ledger) (string, string) {
+				var init chaincode.Initialization
+				require.NoError(t, json.Unmarshal(l.State[chaincode.InitializationKey], &init))
+				init.Shards = 4
+				data, _ := json.Marshal(init)
+				l.State[chaincode.InitializationKey] = data
+				return sign(t, key, page)
+			},
+			"[INVALID_ARGUMENT] a dump of a ledger with 1 shards at schema v1 cannot be imported into one with 4 shards at schema v1",
+		},
+		{
+			"malformed", func(*tokentest.Ledger) (string, string) { return `{"section":"records","owner":"x"}`, "" },
+			`[INVALID_ARGUMENT] invalid page: json: unknown field "owner"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l := adminLedger(t)
+			require.NoError(t, (&chaincode.SmartContract{}).SetImportSigner(l.Context, cert))
+			writes := l.Stub.PutStateCallCount()
+			pageJSON, signature := tt.setup(l)
+
+			_, err := (&chaincode.SmartContract{}).ImportState(l.Context, pageJSON, signature)
+			assert.EqualError(t, err, tt.err)
+			assert.Equal(t, writes, l.Stub.PutStateCallCount(), "a rejected page should write nothing")
+		})
+	}
+}
+
+func TestCloneRequiresAdmin(t *testing.T) {
+	l := tokentest.NewLedger(t).WithCaller("Org1MSP", "User1@org1.example.com", "client")
+	contract := &chaincode.SmartContract{}
+	_, cert := exportSigner(t)
+
+	_, err := contract.ExportState(l.Context, chaincode.SectionRecords, 0, "")
+	assert.EqualError(t, err, "[UNAUTHORIZED] only an organization admin can export the ledger")
+	err = contract.SetImportSigner(l.Context, cert)
+	assert.EqualError(t, err, "[UNAUTHORIZED] only an organization admin can set the import signer")
+	_, err = contract.ImportState(l.Context, "{}", "")
+	assert.EqualError(t, err, "[UNAUTHORIZED] only an organization admin can import the ledger")
+
+	err = contract.SetImportSigner(adminLedger(t).Context, "not a certificate")
+	assert.EqualError(t, err, "[INVALID_ARGUMENT] invalid certificate: not a PEM encoded certificate")
+}
@@ -0,0 +1,309 @@
+package chaincode
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// Record encodings. Account and transaction records are written in the
+// encoding chosen with SetRecordEncoding, JSON by default. Protobuf records
+// are smaller and cheaper to decode; their schema is records.proto. Every
+// protobuf record starts with protoMagic and a kind byte, which no JSON
+// record can, so records are read in either encoding and a ledger stays
+// readable while MigrateRecords converts it. Other records, such as the
+// initialization record and deltas, are always JSON.
+const (
+	EncodingJSON     = "json"
+	EncodingProtobuf = "protobuf"
+)
+
+const protoMagic = "\x00pb"
+
+// Kind bytes following protoMagic
+const (
+	protoKindUser        = 'u'
+	protoKindTransaction = 't'
+)
+
+// Codec encodes records for the world state
+type Codec interface {
+	// Encoding names the codec, as passed to SetRecordEncoding
+	Encoding() string
+	Marshal(record interface{}) ([]byte, error)
+}
+
+// protoMarshaler is a record with a protobuf encoding
+type protoMarshaler interface {
+	protoKind() byte
+	marshalProto() []byte
+}
+
+// protoRecord is a pointer to a record with a protobuf encoding
+type protoRecord interface {
+	protoMarshaler
+	unmarshalProto(data []byte) error
+}
+
+type jsonCodec struct{}
+
+func (jsonCodec) Encoding() string { return EncodingJSON }
+
+func (jsonCodec) Marshal(record interface{}) ([]byte, error) {
+	return json.Marshal(record)
+}
+
+// protoCodec encodes User and Transaction records as protobuf and any
+// other record as JSON
+type protoCodec struct{}
+
+func (protoCodec) Encoding() string { return EncodingProtobuf }
+
+func (protoCodec) Marshal(record interface{}) ([]byte, error) {
+	r, ok := record.(protoMarshaler)
+	if !ok {
+		return json.Marshal(record)
+	}
+
+	data := append([]byte(protoMagic), r.protoKind())
+	return append(data, r.marshalProto()...), nil
+}
+
+var codecs = map[string]Codec{
+	EncodingJSON:     jsonCodec{},
+	EncodingProtobuf: protoCodec{},
+}
+
+// recordCodec returns the codec records are written with
+func recordCodec(ctx contractapi.TransactionContextInterface) (Codec, error) {
+	init, err := ledgerConfig(ctx)
+	if err != nil || init == nil || init.Encoding == "" {
+		return jsonCodec{}, err
+	}
+
+	codec, ok := codecs[init.Encoding]
+	if !ok {
+		return nil, corrupt(InitializationKey, fmt.Errorf("unknown encoding %q", init.Encoding))
+	}
+	return codec, nil
+}
+
+// isProto reports whether data is a protobuf record
+func isProto(data []byte) bool {
+	return len(data) > len(protoMagic) && string(data[:len(protoMagic)]) == protoMagic
+}
+
+// recordKind returns the kind byte of a protobuf record
+func recordKind(data []byte) byte {
+	return data[len(protoMagic)]
+}
+
+// decodeProto decodes the protobuf record stored under key into v
+func decodeProto(key string, data []byte, v interface{}) error {
+	r, ok := v.(protoRecord)
+	if !ok || recordKind(data) != r.protoKind() {
+		return corrupt(key, fmt.Errorf("unexpected protobuf record kind %q", recordKind(data)))
+	}
+
+	err := r.unmarshalProto(data[len(protoMagic)+1:])
+	if err != nil {
+		return corrupt(key, err)
+	}
+	return nil
+}
+
+// Protobuf wire format. Only the varint and length-delimited wire types
+// are used.
+const (
+	wireVarint = 0
+	wireBytes  = 2
+)
+
+func appendTag(b []byte, field int, wire int) []byte {
+	return append(b, proto.EncodeVarint(uint64(field<<3|wire))...)
+}
+
+func appendString(b []byte, field int, s string) []byte {
+	if s == "" {
+		return b
+	}
+	b = appendTag(b, field, wireBytes)
+	b = append(b, proto.EncodeVarint(uint64(len(s)))...)
+	return append(b, s...)
+}
+
+func appendInt(b []byte, field int, v int) []byte {
+	if v == 0 {
+		return b
+	}
+	b = appendTag(b, field, wireVarint)
+	return append(b, proto.EncodeVarint(uint64(int64(v)))...)
+}
+
+func appendBool(b []byte, field int, v bool) []byte {
+	if !v {
+		return b
+	}
+	b = appendTag(b, field, wireVarint)
+	return append(b, 1)
+}
+
+// protoValue is a decoded field value
+type protoValue struct {
+	wire   int
+	varint uint64
+	bytes  []byte
+}
+
+var errWireType = errors.New("unexpected wire type")
+
+func (v protoValue) string() (string, error) {
+	if v.wire != wireBytes {
+		return "", errWireType
+	}
+	return string(v.bytes), nil
+}
+
+func (v protoValue) int() (int, error) {
+	if v.wire != wireVarint {
+		return 0, errWireType
+	}
+	return int(int64(v.varint)), nil
+}
+
+func (v protoValue) bool() (bool, error) {
+	if v.wire != wireVarint {
+		return false, errWireType
+	}
+	return v.varint != 0, nil
+}
+
+// decodeFields calls field for each field of a protobuf message, failing on
+// truncated data and on fields field does not know
+func decodeFields(data []byte, field func(num int, v protoValue) (bool, error)) error {
+	for len(data) > 0 {
+		tag, n := proto.DecodeVarint(data)
+		if n == 0 {
+			return errors.New("truncated tag")
+		}
+		data = data[n:]
+
+		v := protoValue{wire: int(tag & 7)}
+		switch v.wire {
+		case wireVarint:
+			v.varint, n = proto.DecodeVarint(data)
+			if n == 0 {
+				return errors.New("truncated varint")
+			}
+			data = data[n:]
+		case wireBytes:
+			length, n := proto.DecodeVarint(data)
+			if n == 0 || uint64(len(data)-n) < length {
+				return errors.New("truncated bytes")
+			}
+			v.bytes = data[n : n+int(length)]
+			data = data[n+int(length):]
+		default:
+			return fmt.Errorf("unsupported wire type %d", v.wire)
+		}
+
+		num := int(tag >> 3)
+		known, err := field(num, v)
+		if err != nil {
+			return fmt.Errorf("field %d: %w", num, err)
+		}
+		if !known {
+			return fmt.Errorf("unknown field %d", num)
+		}
+	}
+
+	return nil
+}
+
+func (u User) protoKind() byte { return protoKindUser }
+
+func (u User) marshalProto() []byte {
+	var b []byte
+	b = appendString(b, 1, u.ID)
+	b = appendString(b, 2, u.Type)
+	b = appendInt(b, 3, u.Balance)
+	b = appendBool(b, 4, u.Hot)
+	b = appendInt(b, 5, u.Rebase)
+	return b
+}
+
+func (u *User) unmarshalProto(data []byte) error {
+	*u = User{}
+	return decodeFields(data, func(num int, v protoValue) (bool, error) {
+		var err error
+		switch num {
+		case 1:
+			u.ID, err = v.string()
+		case 2:
+			u.Type, err = v.string()
+		case 3:
+			u.Balance, err = v.int()
+		case 4:
+			u.Hot, err = v.bool()
+		case 5:
+			u.Rebase, err = v.int()
+		default:
+			return false, nil
+		}
+		return true, err
+	})
+}
+
+func (t Transaction) protoKind() byte { return protoKindTransaction }
+
+func (t Transaction) marshalProto() []byte {
+	var b []byte
+	b = appendString(b, 1, t.TXID)
+	b = appendString(b, 2, string(t.Type))
+	b = appendString(b, 3, t.From)
+	b = appendString(b, 4, t.To)
+	b = appendInt(b, 5, t.Value)
+	b = appendString(b, 6, t.Timestamp)
+	b = appendString(b, 7, t.ChannelID)
+	b = appendString(b, 8, t.InitiatorMSPID)
+	b = appendString(b, 9, t.Initiator)
+	b = appendString(b, 10, t.CorrelationID)
+	return b
+}
+
+func (t *Transaction) unmarshalProto(data []byte) error {
+	*t = Transaction{}
+	return decodeFields(data, func(num int, v protoValue) (bool, error) {
+		var err error
+		var txType string
+		switch num {
+		case 1:
+			t.TXID, err = v.string()
+		case 2:
+			txType, err = v.string()
+			t.Type = TransactionType(txType)
+		case 3:
+			t.From, err = v.string()
+		case 4:
+			t.To, err = v.string()
+		case 5:
+			t.Value, err = v.int()
+		case 6:
+			t.Timestamp, err = v.string()
+		case 7:
+			t.ChannelID, err = v.string()
+		case 8:
+			t.InitiatorMSPID, err = v.string()
+		case 9:
+			t.Initiator, err = v.string()
+		case 10:
+			t.CorrelationID, err = v.string()
+		default:
+			return false, nil
+		}
+		return true, err
+	})
+}
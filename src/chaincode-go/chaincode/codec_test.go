@@ -0,0 +1,155 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package chaincode_test
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+	"github.com/hyperledger/fabric-chaincode-go/shimtest"
+	"github.com/kkiu1756/my_fabric/src/chaincode-go/chaincode"
+	"github.com/kkiu1756/my_fabric/src/chaincode-go/tokentest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// #########
+// HELPERS
+// #########
+
+// protoKeys returns the simple keys whose records are protobuf encoded
+func protoKeys(stub *shimtest.MockStub) []string {
+	var keys []string
+	for key, value := range stub.State {
+		if !strings.HasPrefix(key, "\x00") && strings.HasPrefix(string(value), "\x00pb") {
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
+
+// migrateAll runs MigrateRecords a step of limit records at a time
+func migrateAll(t *testing.T, stub *shimtest.MockStub, limit string) int {
+	migrated := 0
+	bookmark := ""
+	for {
+		var page chaincode.MigrationPage
+		require.NoError(t, json.Unmarshal(invokeTx(t, stub, "migrate"+limit+bookmark, "MigrateRecords", limit, bookmark), &page))
+		migrated += page.Migrated
+		if page.Bookmark == "" {
+			return migrated
+		}
+		bookmark = page.Bookmark
+	}
+}
+
+// #########
+// TESTS
+// #########
+
+func TestProtobufRecords(t *testing.T) {
+	stub := newMockStub(t)
+	jsonSize := len(stub.State["alice"])
+
+	invokeTx(t, stub, "encoding", "SetRecordEncoding", "protobuf")
+	invokeTx(t, stub, "transfer", "TransferFrom", "alice", "bob", "30")
+	assert.ElementsMatch(t, []string{"alice", "bob", "transfer"}, protoKeys(stub), "new records should be written as protobuf")
+	assert.Less(t, len(stub.State["alice"]), jsonSize)
+
+	var user chaincode.User
+	require.NoError(t, json.Unmarshal(invokeTx(t, stub, "read", "GetUser", "alice"), &user))
+	assert.Equal(t, chaincode.User{ID: "alice", Type: "user", Balance: 70}, user)
+
+	var tx chaincode.Transaction
+	require.NoError(t, json.Unmarshal(invokeTx(t, stub, "read", "GetTransaction", "transfer"), &tx))
+	assert.Equal(t, chaincode.TransactionTransfer, tx.Type)
+	assert.Equal(t, "transfer", tx.TXID)
+	assert.Equal(t, 30, tx.Value)
+	assert.Equal(t, "Org1MSP", tx.InitiatorMSPID)
+
+	invokeTx(t, stub, "hot", "SetHotAccount", "bob", "true")
+	var hot chaincode.User
+	require.NoError(t, json.Unmarshal(invokeTx(t, stub, "read", "GetUser", "bob"), &hot))
+	assert.True(t, hot.Hot)
+	invokeTx(t, stub, "delete", "DeleteUser", "bob")
+}
+
+func TestMigrateRecords(t *testing.T) {
+	stub := newMockStub(t)
+	invokeTx(t, stub, "transfer", "TransferFrom", "alice", "bob", "30")
+
+	invokeTx(t, stub, "encoding", "SetRecordEncoding", "protobuf")
+	assert.Equal(t, 4, migrateAll(t, stub, "1"), "alice, bob and the transaction records tx and transfer")
+	assert.Len(t, protoKeys(stub), 4)
+	assert.Zero(t, migrateAll(t, stub, "0"), "a rerun should find nothing to migrate")
+
+	invokeTx(t, stub, "json", "SetRecordEncoding", "json")
+	assert.Equal(t, 4, migrateAll(t, stub, "2"))
+	assert.Empty(t, protoKeys(stub))
+	assert.Equal(t, 70, stubBalance(t, stub, "alice"))
+}
+
+func TestMigrateShard(t *testing.T) {
+	l := shardedLedger(t, 2)
+	contract := &chaincode.SmartContract{}
+	_, err := contract.CreateUser(l.Context, "alice", "user", 100)
+	require.NoError(t, err)
+
+	_, err = contract.SetRecordEncoding(l.Context, chaincode.EncodingProtobuf)
+	require.NoError(t, err)
+	l.Stub.GetStateByPartialCompositeKeyStub = func(objectType string, attributes []string) (shim.StateQueryIteratorInterface, error) {
+		it, _, err := l.Stub.GetStateByPartialCompositeKeyWithPagination(objectType, attributes, 1000, "")
+		return it, err
+	}
+
+	migrated := 0
+	for shard := 0; shard < 2; shard++ {
+		page, err := contract.MigrateShard(l.Context, shard, 0, "")
+		require.NoError(t, err)
+		assert.Empty(t, page.Bookmark)
+		migrated += page.Migrated
+	}
+	assert.Equal(t, 1, migrated)
+	user, err := contract.GetUser(l.Context, "alice")
+	require.NoError(t, err)
+	assert.Equal(t, 100, user.Balance)
+
+	_, err = contract.MigrateShard(l.Context, 2, 0, "")
+	assert.EqualError(t, err, "[INVALID_ARGUMENT] invalid shard: must be between 0 and 1")
+	_, err = contract.SetRecordEncoding(l.Context, "xml")
+	assert.EqualError(t, err, "[INVALID_ARGUMENT] invalid encoding: must be json or protobuf")
+
+	l = tokentest.NewLedger(t).WithCaller("Org1MSP", "admin", chaincode.AdminOU)
+	_, err = contract.MigrateShard(l.Context, 0, 0, "")
+	assert.EqualError(t, err, "[INVALID_ARGUMENT] ledger is not sharded, use MigrateRecords")
+}
+
+func TestSetRecordEncodingRequiresAdmin(t *testing.T) {
+	l := tokentest.NewLedger(t).WithCaller("Org1MSP", "User1@org1.example.com", "client")
+	contract := &chaincode.SmartContract{}
+
+	_, err := contract.SetRecordEncoding(l.Context, chaincode.EncodingProtobuf)
+	assert.EqualError(t, err, "[UNAUTHORIZED] only an organization admin can change the record encoding")
+	_, err = contract.MigrateRecords(l.Context, 0, "")
+	assert.EqualError(t, err, "[UNAUTHORIZED] only an organization admin can migrate records")
+}
+
+func TestCorruptProtobufRecord(t *testing.T) {
+	l := tokentest.NewLedger(t)
+	l.State["alice"] = []byte("\x00pbu\x0a\x05ali")
+	l.State["bob"] = []byte("\x00pbu\x50\x01")
+	l.State["carol"] = []byte("\x00pbt\x0a\x05carol")
+
+	for id, reason := range map[string]string{
+		"alice": "truncated bytes",
+		"bob":   "unknown field 10",
+		"carol": `unexpected protobuf record kind 't'`,
+	} {
+		_, err := (&chaincode.SmartContract{}).GetUser(l.Context, id)
+		assert.EqualError(t, err, `[CORRUPT_RECORD] record "`+id+`" is corrupt: `+reason)
+	}
+}
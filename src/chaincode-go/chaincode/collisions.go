@@ -0,0 +1,59 @@
+package chaincode
+
+import (
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+	"github.com/hyperledger/fabric-protos-go/ledger/queryresult"
+	"github.com/hyperledger/fabric-protos-go/peer"
+	"github.com/kkiu1756/my_fabric/src/chaincode-go/validation"
+)
+
+// KeyCollision is an account stored under a key that CreateUser would now
+// reject
+type KeyCollision struct {
+	Key    string `json:"key"`
+	Reason string `json:"reason"`
+}
+
+// CollisionPage is one page of FindKeyCollisions results
+type CollisionPage struct {
+	Collisions []KeyCollision `json:"collisions"`
+	// Bookmark continues the scan, empty after the last page
+	Bookmark string `json:"bookmark"`
+}
+
+// FindKeyCollisions scans the world state for account records created
+// before account ids were restricted: ids starting with "_" or shaped like
+// a transaction id, whose record a transaction record can overwrite. The
+// accounts keep working; operators should move their balances to new ids.
+// It scans pageSize records from bookmark per call, bounded by the query
+// policy, so a full scan takes as many calls as the state is large.
+func (s *SmartContract) FindKeyCollisions(ctx contractapi.TransactionContextInterface, pageSize int, bookmark string) (*CollisionPage, error) {
+	pageSize, err := queryPolicy.pageSize(pageSize)
+	if err != nil {
+		return nil, err
+	}
+
+	// an empty range covers every simple key, composite keys are excluded
+	query := func(pageSize int32, bookmark string) (shim.StateQueryIteratorInterface, *peer.QueryResponseMetadata, error) {
+		return ctx.GetStub().GetStateByRangeWithPagination("", "", pageSize, bookmark)
+	}
+
+	page := &CollisionPage{Collisions: []KeyCollision{}}
+	page.Bookmark, err = queryPolicy.scan(query, "", pageSize, bookmark, func(kv *queryresult.KV) error {
+		if !isAccountRecord(kv.Value) {
+			return nil
+		}
+
+		err := validation.AccountID("id", kv.Key)
+		if verr, ok := err.(*validation.Error); ok {
+			page.Collisions = append(page.Collisions, KeyCollision{Key: kv.Key, Reason: verr.Reason})
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return page, nil
+}
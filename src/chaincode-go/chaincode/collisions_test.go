@@ -0,0 +1,75 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package chaincode_test
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/kkiu1756/my_fabric/src/chaincode-go/chaincode"
+	"github.com/kkiu1756/my_fabric/src/chaincode-go/validation"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const txShapedID = "47552c4081e0cd919ecd4d090d07293376b686ebd11db20e4934c54a661080f5"
+
+func TestCreateUserReservedIDs(t *testing.T) {
+	for _, id := range []string{"_design", txShapedID} {
+		ctx, stub := newContext(ledger(t))
+
+		_, err := (&chaincode.SmartContract{}).CreateUser(ctx, id, "user", 10)
+		assert.True(t, errors.Is(err, validation.ErrInvalidArgument), "id %q: %v", id, err)
+		assert.Zero(t, stub.PutStateCallCount())
+	}
+}
+
+func TestFindKeyCollisions(t *testing.T) {
+	state := ledger(t)
+	for _, id := range []string{"_design", txShapedID} {
+		state[id] = userJSON(t, id, 10)
+	}
+	transaction, err := json.Marshal(chaincode.Transaction{TXID: "f00d" + txShapedID[4:], Type: chaincode.TransactionTransfer, From: "alice", To: "bob", Value: 1})
+	require.NoError(t, err)
+	state["f00d"+txShapedID[4:]] = transaction
+	ctx, stub := newContext(state)
+	paginate(stub, state)
+
+	var collisions []chaincode.KeyCollision
+	bookmark := ""
+	for {
+		page, err := (&chaincode.SmartContract{}).FindKeyCollisions(ctx, 2, bookmark)
+		require.NoError(t, err)
+		collisions = append(collisions, page.Collisions...)
+		if page.Bookmark == "" {
+			break
+		}
+		bookmark = page.Bookmark
+	}
+	assert.ElementsMatch(t, []chaincode.KeyCollision{
+		{Key: "_design", Reason: `must not start with "_", which is reserved`},
+		{Key: txShapedID, Reason: "must not look like a transaction id"},
+	}, collisions, "only account records should be reported")
+}
+
+func TestFindKeyCollisionsClean(t *testing.T) {
+	state := ledger(t)
+	ctx, stub := newContext(state)
+	paginate(stub, state)
+
+	page, err := (&chaincode.SmartContract{}).FindKeyCollisions(ctx, 0, "")
+	require.NoError(t, err)
+	assert.Equal(t, &chaincode.CollisionPage{Collisions: []chaincode.KeyCollision{}}, page)
+}
+
+func TestFindKeyCollisionsQueryFailure(t *testing.T) {
+	ctx, stub := newContext(ledger(t))
+	stub.GetStateByRangeWithPaginationReturns(nil, nil, errInjected)
+
+	_, err := (&chaincode.SmartContract{}).FindKeyCollisions(ctx, 0, "")
+	assert.EqualError(t, err, `[STATE_UNAVAILABLE] failed to query state "": injected failure`)
+	assert.True(t, chaincode.Transient(err))
+}
@@ -0,0 +1,200 @@
+package chaincode
+
+import (
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+	"github.com/hyperledger/fabric-protos-go/ledger/queryresult"
+	"github.com/hyperledger/fabric-protos-go/peer"
+	"github.com/kkiu1756/my_fabric/src/chaincode-go/validation"
+)
+
+// A transfer of more than the ledger's LargeTransferThreshold writes a
+// LargeTransferReview under reviewObjectType~txid for the compliance team
+// and emits EventLargeTransfer instead of its typed event, as a Fabric
+// transaction carries a single event. The event's payload is the
+// Transaction with the threshold, so a listener of Transferred events must
+// also listen to LargeTransfer. A typed event emitted later in the
+// transaction, such as Cashback or CreditDrawn, joins it instead of
+// replacing it: the payload gains the fields of the typed event and lists
+// its name under events. A ledger still emitting the legacy Transfer event,
+// see events.go, keeps emitting it for a large transfer, carrying the
+// threshold and listing LargeTransfer under events.
+const reviewObjectType = "review"
+
+// EventLargeTransfer is emitted by a transfer over the threshold
+const EventLargeTransfer = "LargeTransfer"
+
+// LargeTransferReview records a transfer over the threshold
+type LargeTransferReview struct {
+	TXID  string `json:"txId"`
+	From  string `json:"from"`
+	To    string `json:"to"`
+	Value int    `json:"value"`
+	// Threshold is the threshold the transfer exceeded
+	Threshold int `json:"threshold"`
+	// Timestamp is the client's proposal timestamp in RFC 3339 format
+	Timestamp string `json:"txTimestamp"`
+	// Currency is the symbol of a currency transfer, see currency.go, empty
+	// for the base token
+	Currency string `json:"currency,omitempty" metadata:"currency,optional"`
+}
+
+// ReviewPage is one page of large transfer reviews
+type ReviewPage struct {
+	Reviews []*LargeTransferReview `json:"reviews"`
+	// Bookmark continues the reviews, empty after their last page
+	Bookmark string `json:"bookmark"`
+}
+
+// largeTransferEvent is the payload of EventLargeTransfer
+type largeTransferEvent struct {
+	*Transaction
+	Threshold int `json:"threshold"`
+}
+
+// flagLargeTransfer buffers the review of transaction if it is a transfer
+// over the threshold and returns the event to emit for it, nil otherwise
+func flagLargeTransfer(batch *writeBatch, transaction *Transaction) (*largeTransferEvent, error) {
+	if transaction.Type != TransactionTransfer {
+		return nil, nil
+	}
+	threshold, err := reviewLargeTransfer(batch, &LargeTransferReview{
+		TXID:      transaction.TXID,
+		From:      transaction.From,
+		To:        transaction.To,
+		Value:     transaction.Value,
+		Timestamp: transaction.Timestamp,
+	})
+	if err != nil || threshold == 0 {
+		return nil, err
+	}
+
+	return &largeTransferEvent{transaction, threshold}, nil
+}
+
+// reviewLargeTransfer buffers review, stamped with the threshold, if its
+// value is over the threshold and returns the threshold, 0 otherwise
+func reviewLargeTransfer(batch *writeBatch, review *LargeTransferReview) (int, error) {
+	init, err := ledgerConfig(batch.ctx)
+	if err != nil || init == nil || init.LargeTransferThreshold == 0 || review.Value <= init.LargeTransferThreshold {
+		return 0, err
+	}
+
+	key, err := batch.ctx.GetStub().CreateCompositeKey(reviewObjectType, []string{review.TXID})
+	if err != nil {
+		return 0, err
+	}
+	review.Threshold = init.LargeTransferThreshold
+	err = batch.putState(key, review)
+	if err != nil {
+		return 0, err
+	}
+
+	return init.LargeTransferThreshold, nil
+}
+
+// emitLargeTransfer emits event and holds it for the typed events of the
+// transaction, see emitTypedEvent. A ledger still emitting the legacy
+// Transfer event emits it instead, carrying the threshold and
+// EventLargeTransfer in its events.
+func emitLargeTransfer(batch *writeBatch, event *largeTransferEvent) error {
+	init, err := ledgerConfig(batch.ctx)
+	if err != nil {
+		return err
+	}
+	if init == nil || (!legacyEvents(init) && !inLegacyEventWindow(init, event.Timestamp)) {
+		return holdEvent(batch, EventLargeTransfer, event)
+	}
+
+	err = emitTransactionEvent(batch, event.Transaction)
+	if err != nil {
+		return err
+	}
+	return joinHeldEvent(batch, EventLargeTransfer, struct {
+		Threshold int `json:"threshold"`
+	}{event.Threshold})
+}
+
+// SetLargeTransferThreshold flags every later transfer of more than
+// threshold for review; 0 stops flagging. Only an org admin can call it.
+func (s *SmartContract) SetLargeTransferThreshold(ctx contractapi.TransactionContextInterface, threshold int) (*Initialization, error) {
+	if err := validate(validation.Amount("threshold", threshold)); err != nil {
+		return nil, err
+	}
+	if err := requireInitialized(ctx); err != nil {
+		return nil, err
+	}
+	if _, err := requireAdmin(ctx, "change the large transfer threshold"); err != nil {
+		return nil, err
+	}
+
+	init, err := ledgerConfig(ctx)
+	if err != nil {
+		return nil, err
+	}
+	init.LargeTransferThreshold = threshold
+
+	err = putState(ctx, InitializationKey, init)
+	if err != nil {
+		return nil, err
+	}
+
+	return init, nil
+}
+
+// GetLargeTransferReview returns the review of transfer txid
+func (s *SmartContract) GetLargeTransferReview(ctx contractapi.TransactionContextInterface, txid string) (*LargeTransferReview, error) {
+	if err := validate(validation.ID("txid", txid)); err != nil {
+		return nil, err
+	}
+
+	key, err := ctx.GetStub().CreateCompositeKey(reviewObjectType, []string{txid})
+	if err != nil {
+		return nil, err
+	}
+	data, err := getState(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+	if data == nil {
+		return nil, newError(CodeTransactionNotFound, "transaction %s was not flagged for review", txid)
+	}
+
+	var review LargeTransferReview
+	err = decodeRecord(key, data, &review, "txId", "from", "to", "value", "threshold")
+	if err != nil {
+		return nil, err
+	}
+
+	return &review, nil
+}
+
+// ListLargeTransferReviews returns a page of at most pageSize reviews, in
+// transaction id order, from bookmark, which is empty for the first page
+func (s *SmartContract) ListLargeTransferReviews(ctx contractapi.TransactionContextInterface, pageSize int, bookmark string) (*ReviewPage, error) {
+	pageSize, err := queryPolicy.pageSize(pageSize)
+	if err != nil {
+		return nil, err
+	}
+
+	stub := ctx.GetStub()
+	query := func(pageSize int32, bookmark string) (shim.StateQueryIteratorInterface, *peer.QueryResponseMetadata, error) {
+		return stub.GetStateByPartialCompositeKeyWithPagination(reviewObjectType, []string{}, pageSize, bookmark)
+	}
+
+	page := &ReviewPage{Reviews: []*LargeTransferReview{}}
+	page.Bookmark, err = queryPolicy.scan(query, reviewObjectType, pageSize, bookmark, func(kv *queryresult.KV) error {
+		var review LargeTransferReview
+		err := decodeRecord(kv.Key, kv.Value, &review, "txId", "from", "to", "value", "threshold")
+		if err != nil {
+			return err
+		}
+		page.Reviews = append(page.Reviews, &review)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return page, nil
+}
@@ -0,0 +1,110 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package chaincode_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/kkiu1756/my_fabric/src/chaincode-go/chaincode"
+	"github.com/kkiu1756/my_fabric/src/chaincode-go/tokentest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// #########
+// TESTS
+// #########
+
+func TestLargeTransfer(t *testing.T) {
+	l := adminLedger(t).WithAccount("alice", "user", 1000).WithAccount("bob", "user", 0)
+	paginate(l.Stub, l.State)
+	contract := &chaincode.SmartContract{}
+
+	init, err := contract.SetLargeTransferThreshold(l.Context, 100)
+	require.NoError(t, err)
+	assert.Equal(t, 100, init.LargeTransferThreshold)
+
+	l.WithTxID("small")
+	_, err = contract.TransferFrom(l.Context, "alice", "bob", 100)
+	require.NoError(t, err)
+	l.AssertEvent(chaincode.EventTransfer, map[string]interface{}{"from": "alice", "to": "bob", "value": 100})
+	_, err = contract.GetLargeTransferReview(l.Context, "small")
+	assert.EqualError(t, err, "[TRANSACTION_NOT_FOUND] transaction small was not flagged for review")
+
+	l.WithTxID("large")
+	_, err = contract.TransferFrom(l.Context, "alice", "bob", 101)
+	require.NoError(t, err)
+	transfer := l.Transaction("large")
+	l.AssertEvent(chaincode.EventTransfer, map[string]interface{}{
+		"from": "alice", "to": "bob", "value": 101, "threshold": 100, "events": []string{chaincode.EventLargeTransfer},
+	})
+
+	review, err := contract.GetLargeTransferReview(l.Context, "large")
+	require.NoError(t, err)
+	assert.Equal(t, &chaincode.LargeTransferReview{TXID: "large", From: "alice", To: "bob", Value: 101, Threshold: 100, Timestamp: transfer.Timestamp}, review)
+	page, err := contract.ListLargeTransferReviews(l.Context, 0, "")
+	require.NoError(t, err)
+	assert.Equal(t, []*chaincode.LargeTransferReview{review}, page.Reviews)
+
+	_, err = contract.SetLargeTransferThreshold(l.Context, 0)
+	require.NoError(t, err)
+	l.WithTxID("unflagged")
+	_, err = contract.TransferFrom(l.Context, "alice", "bob", 500)
+	require.NoError(t, err)
+	_, err = contract.GetLargeTransferReview(l.Context, "unflagged")
+	assert.True(t, errors.Is(err, chaincode.ErrTransactionNotFound), "a zero threshold flags nothing")
+}
+
+func TestLargeTransferEvent(t *testing.T) {
+	l := initializedLedger(t).WithAccount("alice", "user", 1000).WithAccount("bob", "user", 0)
+	contract := &chaincode.SmartContract{}
+	_, err := contract.SetLargeTransferThreshold(l.Context, 100)
+	require.NoError(t, err)
+
+	_, err = contract.TransferFrom(l.WithTxID("large").Context, "alice", "bob", 101)
+	require.NoError(t, err)
+	transfer := l.Transaction("large")
+	l.AssertEvent(chaincode.EventLargeTransfer, map[string]interface{}{
+		"txId": "large", "type": "transfer", "from": "alice", "to": "bob", "value": 101,
+		"txTimestamp": transfer.Timestamp, "channelId": "", "initiatorMspId": "Org1MSP", "initiator": transfer.Initiator,
+		"threshold": 100,
+	})
+
+	_, err = contract.SetLegacyEventWindow(l.Context, "2999-01-01T00:00:00Z")
+	require.NoError(t, err)
+	_, err = contract.TransferFrom(l.WithTxID("window").Context, "alice", "bob", 101)
+	require.NoError(t, err)
+	transfer = l.Transaction("window")
+	l.AssertEvent(chaincode.EventTransfer, map[string]interface{}{
+		"txId": "window", "type": "transfer", "from": "alice", "to": "bob", "value": 101,
+		"txTimestamp": transfer.Timestamp, "channelId": "", "initiatorMspId": "Org1MSP", "initiator": transfer.Initiator,
+		"event": chaincode.EventTransferred, "threshold": 100, "events": []string{chaincode.EventLargeTransfer},
+	})
+}
+
+func TestLargeMintNotFlagged(t *testing.T) {
+	l := adminLedger(t)
+	contract := &chaincode.SmartContract{}
+	_, err := contract.SetLargeTransferThreshold(l.Context, 10)
+	require.NoError(t, err)
+
+	_, err = contract.CreateUser(l.Context, "carol", "user", 500)
+	require.NoError(t, err)
+	_, err = contract.GetLargeTransferReview(l.Context, tokentest.DefaultTxID)
+	assert.True(t, errors.Is(err, chaincode.ErrTransactionNotFound), "only transfers are flagged")
+}
+
+func TestSetLargeTransferThreshold(t *testing.T) {
+	l := adminLedger(t)
+	contract := &chaincode.SmartContract{}
+
+	_, err := contract.SetLargeTransferThreshold(l.Context, -1)
+	assert.EqualError(t, err, "[INVALID_ARGUMENT] invalid threshold: must not be negative")
+
+	l.WithCaller("Org1MSP", "User1@org1.example.com", "client")
+	_, err = contract.SetLargeTransferThreshold(l.Context, 10)
+	assert.EqualError(t, err, "[UNAUTHORIZED] only an organization admin can change the large transfer threshold")
+}
@@ -0,0 +1,356 @@
+package chaincode
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/hyperledger/fabric-chaincode-go/pkg/cid"
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+	"github.com/kkiu1756/my_fabric/src/chaincode-go/validation"
+)
+
+// Once an org admin sets a confirmation threshold with
+// SetConfirmationPolicy, TransferFrom rejects larger transfers. They are
+// proposed with ProposeTransfer instead, and tokens only move when the
+// recipient or a compliance officer calls ConfirmTransfer within the
+// confirmation window. Until then the proposal can be cancelled with
+// CancelTransfer; after it anyone can clear it with ExpireTransfer.
+//
+// The recipient is the holder of the receiving account, see
+// isAccountHolder.
+
+// ComplianceOU marks the certificate of a compliance officer, who can
+// confirm or cancel any proposed transfer
+const ComplianceOU = "compliance"
+
+// confirmationObjectType keys the proposed transfers by transaction id
+const confirmationObjectType = "confirmation"
+
+// PendingConfirmation is a proposed transfer awaiting confirmation
+type PendingConfirmation struct {
+	// ID is the transaction id of the ProposeTransfer call
+	ID    string `json:"id"`
+	From  string `json:"from"`
+	To    string `json:"to"`
+	Value int    `json:"value"`
+	// Timestamp is the proposal timestamp of ProposeTransfer, ExpiresAt
+	// the end of the confirmation window, both RFC 3339
+	Timestamp     string `json:"txTimestamp"`
+	ExpiresAt     string `json:"expiresAt"`
+	ProposerMSPID string `json:"proposerMspId"`
+	Proposer      string `json:"proposer"`
+}
+
+// SetConfirmationPolicy makes transfers of more than threshold wait for the
+// recipient's confirmation for at most window, a Go duration such as "72h".
+// A zero threshold turns confirmation off. Only an org admin can call it.
+func (s *SmartContract) SetConfirmationPolicy(ctx contractapi.TransactionContextInterface, threshold int, window string) (*Initialization, error) {
+	errs := []error{validation.Amount("threshold", threshold)}
+	if threshold > 0 {
+		if d, err := time.ParseDuration(window); err != nil || d <= 0 {
+			errs = append(errs, &validation.Error{Field: "window", Reason: "must be a positive duration such as 72h"})
+		}
+	} else if window != "" {
+		errs = append(errs, &validation.Error{Field: "window", Reason: "must be empty without a threshold"})
+	}
+	if err := validate(errs...); err != nil {
+		return nil, err
+	}
+	if err := requireInitialized(ctx); err != nil {
+		return nil, err
+	}
+	if _, err := requireAdmin(ctx, "change the confirmation policy"); err != nil {
+		return nil, err
+	}
+
+	init, err := ledgerConfig(ctx)
+	if err != nil {
+		return nil, err
+	}
+	init.ConfirmationThreshold = threshold
+	init.ConfirmationWindow = window
+
+	err = putState(ctx, InitializationKey, init)
+	if err != nil {
+		return nil, err
+	}
+
+	return init, nil
+}
+
+// requireNoConfirmation fails with LIMIT_EXCEEDED if a transfer of value
+// must be proposed with ProposeTransfer
+func requireNoConfirmation(ctx contractapi.TransactionContextInterface, to string, value int) error {
+	init, err := ledgerConfig(ctx)
+	if err != nil || init == nil || init.ConfirmationThreshold == 0 || value <= init.ConfirmationThreshold {
+		return err
+	}
+
+	return newError(CodeLimitExceeded, "transfer of %d exceeds the confirmation threshold of %d, submit it with ProposeTransfer for %s to confirm",
+		value, init.ConfirmationThreshold, to)
+}
+
+// ProposeTransfer records a transfer of more than the confirmation
+// threshold for its recipient to confirm. It moves no tokens, and the
+// sender's balance is only checked again on confirmation.
+func (s *SmartContract) ProposeTransfer(ctx contractapi.TransactionContextInterface, from string, to string, value int) (*PendingConfirmation, error) {
+	err := validate(
+		validation.ID("from", from),
+		validation.ID("to", to),
+		validation.Amount("value", value),
+	)
+	if err != nil {
+		return nil, err
+	}
+	if err := requireInitialized(ctx); err != nil {
+		return nil, err
+	}
+	from, to, err = resolveAccounts(ctx, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	init, err := ledgerConfig(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if init.ConfirmationThreshold == 0 || value <= init.ConfirmationThreshold {
+		return nil, newError(CodeInvalidArgument, "a transfer of %d needs no confirmation, submit it with TransferFrom", value)
+	}
+	window, err := time.ParseDuration(init.ConfirmationWindow)
+	if err != nil {
+		return nil, corrupt(InitializationKey, err)
+	}
+
+	if from == to {
+		return nil, newError(CodeInvalidArgument, "cannot transfer to and from same client account")
+	}
+	batch := newWriteBatch(ctx)
+	sender, err := batch.getUser(from)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := batch.getUser(to); err != nil {
+		return nil, err
+	}
+	if sender.Balance < value {
+		return nil, newError(CodeInsufficientBalance, "user balance lower than %d", value)
+	}
+
+	stub := ctx.GetStub()
+	timestamp, err := txTimestamp(stub)
+	if err != nil {
+		return nil, err
+	}
+	proposed, err := time.Parse(time.RFC3339Nano, timestamp)
+	if err != nil {
+		return nil, newError(CodeInvalidArgument, "a proposed transfer needs the proposal timestamp")
+	}
+	pending := &PendingConfirmation{
+		ID:            stub.GetTxID(),
+		From:          from,
+		To:            to,
+		Value:         value,
+		Timestamp:     timestamp,
+		ExpiresAt:     proposed.Add(window).Format(time.RFC3339Nano),
+		ProposerMSPID: creatorMSPID(stub),
+		Proposer:      creatorID(stub),
+	}
+	key, err := stub.CreateCompositeKey(confirmationObjectType, []string{pending.ID})
+	if err != nil {
+		return nil, err
+	}
+	err = batch.putState(key, pending)
+	if err != nil {
+		return nil, err
+	}
+
+	err = batch.flush()
+	if err != nil {
+		return nil, err
+	}
+
+	return pending, nil
+}
+
+// GetPendingConfirmation returns the transfer proposed by transaction id
+func (s *SmartContract) GetPendingConfirmation(ctx contractapi.TransactionContextInterface, id string) (*PendingConfirmation, error) {
+	if err := validate(validation.ID("id", id)); err != nil {
+		return nil, err
+	}
+
+	_, pending, err := pendingConfirmation(newWriteBatch(ctx), id)
+	return pending, err
+}
+
+// pendingConfirmation reads the transfer proposed by transaction id and
+// returns its key
+func pendingConfirmation(batch *writeBatch, id string) (string, *PendingConfirmation, error) {
+	key, err := batch.ctx.GetStub().CreateCompositeKey(confirmationObjectType, []string{id})
+	if err != nil {
+		return "", nil, err
+	}
+	data, err := batch.getState(key)
+	if err != nil {
+		return "", nil, err
+	}
+	if data == nil {
+		return "", nil, newError(CodeTransactionNotFound, "no proposed transfer %s is pending", id)
+	}
+
+	var pending PendingConfirmation
+	err = decodeRecord(key, data, &pending, "id", "from", "to", "value", "expiresAt")
+	if err != nil {
+		return "", nil, err
+	}
+
+	return key, &pending, nil
+}
+
+// expired reports whether the confirmation window of pending has ended at
+// the proposal timestamp of the current transaction
+func (p *PendingConfirmation) expired(ctx contractapi.TransactionContextInterface, key string) (bool, error) {
+	timestamp, err := txTimestamp(ctx.GetStub())
+	if err != nil {
+		return false, err
+	}
+	now, err := time.Parse(time.RFC3339Nano, timestamp)
+	if err != nil {
+		return false, newError(CodeInvalidArgument, "a proposed transfer needs the proposal timestamp")
+	}
+	expiresAt, err := time.Parse(time.RFC3339Nano, p.ExpiresAt)
+	if err != nil {
+		return false, corrupt(key, err)
+	}
+
+	return !now.Before(expiresAt), nil
+}
+
+// isAccountHolder reports whether the client holds account: the identity
+// a recovery bound it to, see recovery.go, or else the client of the
+// ledger's MSP whose certificate common name is the account id. Another
+// MSP can issue any common name, so it only holds accounts bound to it.
+func isAccountHolder(ctx contractapi.TransactionContextInterface, account string) bool {
+	caller, err := callerIdentity(ctx)
+	if err != nil {
+		return false
+	}
+	holder, err := accountHolder(newWriteBatch(ctx), account)
+	if err != nil {
+		return false
+	}
+	if holder != nil {
+		return caller == *holder
+	}
+	init, err := ledgerConfig(ctx)
+	if err != nil || init == nil {
+		return false
+	}
+
+	return caller.MSPID == init.MSPID && caller.CommonName == account
+}
+
+// isComplianceOfficer reports whether the client certificate carries
+// ComplianceOU
+func isComplianceOfficer(ctx contractapi.TransactionContextInterface) bool {
+	caller, err := cid.New(ctx.GetStub())
+	if err != nil {
+		return false
+	}
+
+	officer, err := caller.HasOUValue(ComplianceOU)
+	return err == nil && officer
+}
+
+// ConfirmTransfer executes the transfer proposed by transaction id. Only
+// its recipient or a compliance officer can call it, before the
+// confirmation window ends. The sender's limits apply as to any transfer.
+func (s *SmartContract) ConfirmTransfer(ctx contractapi.TransactionContextInterface, id string) (*Transaction, error) {
+	if err := validate(validation.ID("id", id)); err != nil {
+		return nil, err
+	}
+	if err := requireInitialized(ctx); err != nil {
+		return nil, err
+	}
+
+	batch := newWriteBatch(ctx)
+	key, pending, err := pendingConfirmation(batch, id)
+	if err != nil {
+		return nil, err
+	}
+	if !isAccountHolder(ctx, pending.To) && !isComplianceOfficer(ctx) {
+		return nil, newError(CodeUnauthorized, "only %s or a compliance officer can confirm transfer %s", pending.To, id)
+	}
+	expired, err := pending.expired(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+	if expired {
+		return nil, newError(CodeInvalidArgument, "proposed transfer %s expired at %s", id, pending.ExpiresAt)
+	}
+	batch.delState(key)
+
+	transaction, err := transferHelper(batch, pending.From, pending.To, pending.Value, false)
+	if err != nil {
+		return nil, fmt.Errorf("failed to transfer: %w", err)
+	}
+
+	err = batch.flush()
+	if err != nil {
+		return nil, err
+	}
+
+	return transaction, nil
+}
+
+// CancelTransfer drops the transfer proposed by transaction id. Its
+// proposer, its recipient or a compliance officer can call it.
+func (s *SmartContract) CancelTransfer(ctx contractapi.TransactionContextInterface, id string) error {
+	if err := validate(validation.ID("id", id)); err != nil {
+		return err
+	}
+	if err := requireInitialized(ctx); err != nil {
+		return err
+	}
+
+	batch := newWriteBatch(ctx)
+	key, pending, err := pendingConfirmation(batch, id)
+	if err != nil {
+		return err
+	}
+	stub := ctx.GetStub()
+	proposer := creatorMSPID(stub) == pending.ProposerMSPID && creatorID(stub) == pending.Proposer
+	if !proposer && !isAccountHolder(ctx, pending.To) && !isComplianceOfficer(ctx) {
+		return newError(CodeUnauthorized, "only the proposer, %s or a compliance officer can cancel transfer %s", pending.To, id)
+	}
+	batch.delState(key)
+
+	return batch.flush()
+}
+
+// ExpireTransfer drops the transfer proposed by transaction id once its
+// confirmation window has ended. Anyone can call it.
+func (s *SmartContract) ExpireTransfer(ctx contractapi.TransactionContextInterface, id string) error {
+	if err := validate(validation.ID("id", id)); err != nil {
+		return err
+	}
+	if err := requireInitialized(ctx); err != nil {
+		return err
+	}
+
+	batch := newWriteBatch(ctx)
+	key, pending, err := pendingConfirmation(batch, id)
+	if err != nil {
+		return err
+	}
+	expired, err := pending.expired(ctx, key)
+	if err != nil {
+		return err
+	}
+	if !expired {
+		return newError(CodeInvalidArgument, "proposed transfer %s can be confirmed until %s", id, pending.ExpiresAt)
+	}
+	batch.delState(key)
+
+	return batch.flush()
+}
@@ -0,0 +1,163 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package chaincode_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/kkiu1756/my_fabric/src/chaincode-go/chaincode"
+	"github.com/kkiu1756/my_fabric/src/chaincode-go/tokentest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// #########
+// HELPERS
+// #########
+
+var confirmTime = time.Date(2021, 3, 1, 12, 0, 0, 0, time.UTC)
+
+// confirmLedger returns a ledger with alice 1000 and bob 0 where transfers
+// of more than 100 wait a day for confirmation, and the id of a proposed
+// transfer of 500 from alice to bob by a client of Org1MSP
+func confirmLedger(t *testing.T) (*tokentest.Ledger, string) {
+	l := adminLedger(t).
+		WithAccount("alice", "user", 1000).
+		WithAccount("bob", "user", 0).
+		WithTimestamp(confirmTime)
+	contract := &chaincode.SmartContract{}
+	_, err := contract.SetConfirmationPolicy(l.Context, 100, "24h")
+	require.NoError(t, err)
+
+	l.WithCaller("Org1MSP", "alice", "client").WithTxID("propose")
+	pending, err := contract.ProposeTransfer(l.Context, "alice", "bob", 500)
+	require.NoError(t, err)
+	return l, pending.ID
+}
+
+// #########
+// TESTS
+// #########
+
+func TestConfirmTransfer(t *testing.T) {
+	l, id := confirmLedger(t)
+	contract := &chaincode.SmartContract{}
+
+	_, err := contract.TransferFrom(l.Context, "alice", "bob", 101)
+	assert.EqualError(t, err, "failed to transfer: [LIMIT_EXCEEDED] transfer of 101 exceeds the confirmation threshold of 100, submit it with ProposeTransfer for bob to confirm")
+	_, err = contract.TransferFrom(l.Context, "alice", "bob", 100)
+	require.NoError(t, err, "a transfer at the threshold moves at once")
+
+	pending, err := contract.GetPendingConfirmation(l.Context, id)
+	require.NoError(t, err)
+	assert.Equal(t, "propose", pending.ID)
+	assert.Equal(t, "2021-03-02T12:00:00Z", pending.ExpiresAt)
+	assert.Equal(t, "Org1MSP", pending.ProposerMSPID)
+	l.AssertBalance("alice", 900)
+
+	_, err = contract.ConfirmTransfer(l.Context, id)
+	assert.EqualError(t, err, "[UNAUTHORIZED] only bob or a compliance officer can confirm transfer propose")
+	l.WithCaller("Org2MSP", "bob", "client")
+	_, err = contract.ConfirmTransfer(l.Context, id)
+	assert.EqualError(t, err, "[UNAUTHORIZED] only bob or a compliance officer can confirm transfer propose", "another MSP does not hold accounts by common name")
+
+	l.WithCaller("Org1MSP", "bob", "client").WithTxID("confirm")
+	transaction, err := contract.ConfirmTransfer(l.Context, id)
+	require.NoError(t, err)
+	assert.Equal(t, "confirm", transaction.TXID)
+	l.AssertBalance("alice", 400)
+	l.AssertBalance("bob", 600)
+	l.AssertTransaction("confirm", "alice", "bob", 500)
+
+	_, err = contract.GetPendingConfirmation(l.Context, id)
+	assert.True(t, errors.Is(err, chaincode.ErrTransactionNotFound), "a confirmed transfer is no longer pending")
+	_, err = contract.ConfirmTransfer(l.Context, id)
+	assert.EqualError(t, err, "[TRANSACTION_NOT_FOUND] no proposed transfer propose is pending")
+}
+
+func TestConfirmTransferByComplianceOfficer(t *testing.T) {
+	l, id := confirmLedger(t)
+
+	l.WithCaller("Org2MSP", "officer", "client", chaincode.ComplianceOU)
+	_, err := (&chaincode.SmartContract{}).ConfirmTransfer(l.Context, id)
+	require.NoError(t, err)
+	l.AssertBalance("bob", 500)
+}
+
+func TestConfirmTransferRechecksBalance(t *testing.T) {
+	l, id := confirmLedger(t)
+	l.WithAccount("alice", "user", 10)
+
+	l.WithCaller("Org1MSP", "bob", "client")
+	_, err := (&chaincode.SmartContract{}).ConfirmTransfer(l.Context, id)
+	assert.EqualError(t, err, "failed to transfer: [INSUFFICIENT_BALANCE] user balance lower than 500")
+}
+
+func TestCancelTransfer(t *testing.T) {
+	tests := []struct {
+		name   string
+		caller []string
+		err    string
+	}{
+		{"proposer", []string{"Org1MSP", "alice", "client"}, ""},
+		{"recipient", []string{"Org1MSP", "bob", "client"}, ""},
+		{"compliance officer", []string{"Org2MSP", "officer", "client", chaincode.ComplianceOU}, ""},
+		{"other client", []string{"Org2MSP", "alice", "client"}, "[UNAUTHORIZED] only the proposer, bob or a compliance officer can cancel transfer propose"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l, id := confirmLedger(t)
+			l.WithCaller(tt.caller[0], tt.caller[1], tt.caller[2:]...)
+
+			err := (&chaincode.SmartContract{}).CancelTransfer(l.Context, id)
+			if tt.err != "" {
+				assert.EqualError(t, err, tt.err)
+				return
+			}
+			require.NoError(t, err)
+			_, err = (&chaincode.SmartContract{}).GetPendingConfirmation(l.Context, id)
+			assert.True(t, errors.Is(err, chaincode.ErrTransactionNotFound))
+			l.AssertBalance("alice", 1000)
+		})
+	}
+}
+
+func TestExpireTransfer(t *testing.T) {
+	l, id := confirmLedger(t)
+	contract := &chaincode.SmartContract{}
+
+	l.WithTimestamp(confirmTime.Add(23 * time.Hour))
+	err := contract.ExpireTransfer(l.Context, id)
+	assert.EqualError(t, err, "[INVALID_ARGUMENT] proposed transfer propose can be confirmed until 2021-03-02T12:00:00Z")
+
+	l.WithCaller("Org1MSP", "bob", "client").WithTimestamp(confirmTime.Add(24 * time.Hour))
+	_, err = contract.ConfirmTransfer(l.Context, id)
+	assert.EqualError(t, err, "[INVALID_ARGUMENT] proposed transfer propose expired at 2021-03-02T12:00:00Z")
+
+	require.NoError(t, contract.ExpireTransfer(l.Context, id))
+	_, err = contract.GetPendingConfirmation(l.Context, id)
+	assert.True(t, errors.Is(err, chaincode.ErrTransactionNotFound))
+	l.AssertBalance("alice", 1000)
+}
+
+func TestProposeTransferRejects(t *testing.T) {
+	l, _ := confirmLedger(t)
+	contract := &chaincode.SmartContract{}
+
+	_, err := contract.ProposeTransfer(l.Context, "alice", "bob", 100)
+	assert.EqualError(t, err, "[INVALID_ARGUMENT] a transfer of 100 needs no confirmation, submit it with TransferFrom")
+	_, err = contract.ProposeTransfer(l.Context, "alice", "bob", 2000)
+	assert.EqualError(t, err, "[INSUFFICIENT_BALANCE] user balance lower than 2000")
+	_, err = contract.ProposeTransfer(l.Context, "alice", "carol", 500)
+	assert.True(t, errors.Is(err, chaincode.ErrAccountNotFound))
+
+	_, err = contract.SetConfirmationPolicy(adminLedger(t).Context, 100, "soon")
+	assert.EqualError(t, err, "[INVALID_ARGUMENT] invalid window: must be a positive duration such as 72h")
+	_, err = contract.SetConfirmationPolicy(l.Context, 0, "")
+	assert.EqualError(t, err, "[UNAUTHORIZED] only an organization admin can change the confirmation policy")
+}
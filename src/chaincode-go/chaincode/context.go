@@ -0,0 +1,94 @@
+package chaincode
+
+import (
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// TransactionContext is the context the contract API passes to the
+// contract's methods. It caches the world state reads of one transaction,
+// so an account that a method and its helpers read several times is
+// fetched from the peer once.
+//
+// The cache holds committed values only. Like the stub, it does not see the
+// transaction's own writes; writeBatch layers those on top.
+//
+// It also collects the balance changes and the last event of the
+// transaction, which the after transaction hook combines, see changes.go.
+type TransactionContext struct {
+	contractapi.TransactionContext
+	// reads maps each key read so far to its committed value, nil if absent
+	reads map[string][]byte
+	// changes are the balance changes so far, in the order the accounts
+	// were first changed
+	changes []*BalanceChange
+	// event and eventPayload are the name and JSON payload of the last
+	// event set, "" and nil if none
+	event        string
+	eventPayload []byte
+}
+
+// SetStub sets the transaction's stub and empties the read cache and the
+// collected changes
+func (ctx *TransactionContext) SetStub(stub shim.ChaincodeStubInterface) {
+	ctx.TransactionContext.SetStub(stub)
+	ctx.reads = map[string][]byte{}
+	ctx.changes = nil
+	ctx.event, ctx.eventPayload = "", nil
+}
+
+// GetTransactionContextHandler makes the contract API create a
+// TransactionContext for each transaction
+func (s *SmartContract) GetTransactionContextHandler() contractapi.SettableTransactionContextInterface {
+	return new(TransactionContext)
+}
+
+// readCache is implemented by contexts that cache state reads. Contexts
+// without a cache, such as the mocks in the tests, read through the stub on
+// every call.
+type readCache interface {
+	cachedState(key string) ([]byte, bool)
+	cacheState(key string, value []byte)
+}
+
+func (ctx *TransactionContext) cachedState(key string) ([]byte, bool) {
+	value, ok := ctx.reads[key]
+	return value, ok
+}
+
+func (ctx *TransactionContext) cacheState(key string, value []byte) {
+	if ctx.reads == nil {
+		ctx.reads = map[string][]byte{}
+	}
+	ctx.reads[key] = value
+}
+
+// changeRecorder is implemented by contexts that collect the balance
+// changes and the event of a transaction. Contexts without it, such as the
+// mocks in the tests, emit no balance changes.
+type changeRecorder interface {
+	recordChange(change BalanceChange)
+	recordEvent(name string, payloadJSON []byte)
+	recorded() (changes []*BalanceChange, event string, payloadJSON []byte)
+}
+
+// recordChange adds change, merging it into an earlier change of the same
+// account so the account's first Before and last After are kept
+func (ctx *TransactionContext) recordChange(change BalanceChange) {
+	for _, earlier := range ctx.changes {
+		if earlier.Account == change.Account {
+			earlier.After = change.After
+			earlier.Deleted = change.Deleted
+			return
+		}
+	}
+	ctx.changes = append(ctx.changes, &change)
+}
+
+func (ctx *TransactionContext) recordEvent(name string, payloadJSON []byte) {
+	ctx.event, ctx.eventPayload = name, payloadJSON
+}
+
+func (ctx *TransactionContext) recorded() ([]*BalanceChange, string, []byte) {
+	return ctx.changes, ctx.event, ctx.eventPayload
+}
@@ -0,0 +1,46 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package chaincode_test
+
+import (
+	"testing"
+
+	"github.com/kkiu1756/my_fabric/src/chaincode-go/chaincode"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTransactionContextCachesReads(t *testing.T) {
+	state := ledger(t)
+	_, stub := newContext(state)
+	ctx := &chaincode.TransactionContext{}
+	ctx.SetStub(stub)
+	contract := &chaincode.SmartContract{}
+
+	_, err := contract.TransferFrom(ctx, "alice", "bob", 10)
+	require.NoError(t, err)
+	reads := stub.GetStateCallCount()
+
+	_, err = contract.GetUser(ctx, "alice")
+	require.NoError(t, err)
+	_, err = contract.GetUser(ctx, "bob")
+	require.NoError(t, err)
+	assert.Equal(t, reads, stub.GetStateCallCount(), "accounts already read should come from the cache")
+
+	user, err := contract.GetUser(ctx, "alice")
+	require.NoError(t, err)
+	assert.Equal(t, 100, user.Balance, "the cache should hold committed values, not the transaction's writes")
+
+	ctx.SetStub(stub)
+	user, err = contract.GetUser(ctx, "alice")
+	require.NoError(t, err)
+	assert.Equal(t, 90, user.Balance, "a new transaction should start with an empty cache")
+	assert.Equal(t, reads+2, stub.GetStateCallCount(), "the configuration and alice")
+}
+
+func TestContractUsesTransactionContext(t *testing.T) {
+	handler := (&chaincode.SmartContract{}).GetTransactionContextHandler()
+	assert.IsType(t, &chaincode.TransactionContext{}, handler)
+}
@@ -0,0 +1,262 @@
+package chaincode
+
+import (
+	"math/big"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+	"github.com/kkiu1756/my_fabric/src/chaincode-go/validation"
+)
+
+// An oracle, an identity with the OracleOU or an org admin, publishes the
+// rate of each pair of registered currencies with SetRate, and an account
+// holder converts between them with Convert: it burns the amount of one
+// currency and mints the other at the published rate, less the ledger's
+// ConversionSpread. Both supplies change accordingly; the spread is not
+// paid to anyone, it is simply not minted.
+//
+// A rate is the units of the second currency one unit of the first buys,
+// scaled by RateScale. The conversion is recorded as a CurrencyTransaction
+// of type TransactionConvert carrying the rate and spread it used.
+
+// OracleOU is the organizational unit of rate oracles
+const OracleOU = "oracle"
+
+// RateScale is the fixed-point scale of the rates, so a rate of RateScale
+// converts one for one
+const RateScale = 1000000
+
+// MaxConversionSpread is the spread, in basis points, that keeps the whole
+// conversion
+const MaxConversionSpread = 10000
+
+// TransactionConvert is the type of the CurrencyTransaction of a
+// conversion, which records no Transaction
+const TransactionConvert TransactionType = "convert"
+
+// rateObjectType keys the rates by currency pair
+const rateObjectType = "rate"
+
+// Rate is the published rate of a currency pair
+type Rate struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+	// Rate is the units of To one unit of From buys, times RateScale
+	Rate int `json:"rate"`
+	// UpdatedAt is the proposal timestamp of SetRate, RFC 3339
+	UpdatedAt string `json:"updatedAt"`
+}
+
+// SetRate publishes rate, scaled by RateScale, as the rate of
+// fromCurrency in toCurrency. Only an oracle can call it.
+func (s *SmartContract) SetRate(ctx contractapi.TransactionContextInterface, fromCurrency string, toCurrency string, rate int) (*Rate, error) {
+	errs := []error{
+		validation.ID("fromCurrency", fromCurrency),
+		validation.ID("toCurrency", toCurrency),
+		validation.Amount("rate", rate),
+	}
+	if rate == 0 {
+		errs = append(errs, &validation.Error{Field: "rate", Reason: "must be positive"})
+	}
+	if fromCurrency == toCurrency {
+		errs = append(errs, &validation.Error{Field: "toCurrency", Reason: "must differ from fromCurrency"})
+	}
+	if err := validate(errs...); err != nil {
+		return nil, err
+	}
+	if err := requireInitialized(ctx); err != nil {
+		return nil, err
+	}
+	if !isOracle(ctx) {
+		if _, err := requireAdmin(ctx, "publish a rate"); err != nil {
+			return nil, err
+		}
+	}
+
+	batch := newWriteBatch(ctx)
+	for _, symbol := range []string{fromCurrency, toCurrency} {
+		if _, _, err := registeredCurrency(batch, symbol); err != nil {
+			return nil, err
+		}
+	}
+	timestamp, err := txTimestamp(ctx.GetStub())
+	if err != nil {
+		return nil, err
+	}
+	key, err := ctx.GetStub().CreateCompositeKey(rateObjectType, []string{fromCurrency, toCurrency})
+	if err != nil {
+		return nil, err
+	}
+	published := &Rate{From: fromCurrency, To: toCurrency, Rate: rate, UpdatedAt: timestamp}
+	err = batch.putState(key, published)
+	if err != nil {
+		return nil, err
+	}
+
+	err = batch.flush()
+	if err != nil {
+		return nil, err
+	}
+
+	return published, nil
+}
+
+// GetRate returns the published rate of fromCurrency in toCurrency
+func (s *SmartContract) GetRate(ctx contractapi.TransactionContextInterface, fromCurrency string, toCurrency string) (*Rate, error) {
+	err := validate(
+		validation.ID("fromCurrency", fromCurrency),
+		validation.ID("toCurrency", toCurrency),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return currencyRate(newWriteBatch(ctx), fromCurrency, toCurrency)
+}
+
+// SetConversionSpread makes conversions keep spread basis points of what
+// they would mint. Only an org admin can call it.
+func (s *SmartContract) SetConversionSpread(ctx contractapi.TransactionContextInterface, spread int) (*Initialization, error) {
+	if spread < 0 || spread > MaxConversionSpread {
+		return nil, validate(&validation.Error{Field: "spread", Reason: "must be between 0 and 10000 basis points"})
+	}
+	if err := requireInitialized(ctx); err != nil {
+		return nil, err
+	}
+	if _, err := requireAdmin(ctx, "change the conversion spread"); err != nil {
+		return nil, err
+	}
+
+	init, err := ledgerConfig(ctx)
+	if err != nil {
+		return nil, err
+	}
+	init.ConversionSpread = spread
+
+	err = putState(ctx, InitializationKey, init)
+	if err != nil {
+		return nil, err
+	}
+
+	return init, nil
+}
+
+// Convert burns amount of fromCurrency from the caller's account and mints
+// it in toCurrency at the published rate less the conversion spread,
+// rounded down
+func (s *SmartContract) Convert(ctx contractapi.TransactionContextInterface, fromCurrency string, toCurrency string, amount int) (*CurrencyTransaction, error) {
+	err := validateCurrencyOp(fromCurrency, "amount", amount, validation.ID("toCurrency", toCurrency))
+	if err != nil {
+		return nil, err
+	}
+	if err := requireInitialized(ctx); err != nil {
+		return nil, err
+	}
+	caller, err := callerIdentity(ctx)
+	if err != nil {
+		return nil, err
+	}
+	account := caller.CommonName
+	if err := requireAccountHolder(ctx, account, "convert its currencies"); err != nil {
+		return nil, err
+	}
+	init, err := ledgerConfig(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	batch := newWriteBatch(ctx)
+	fromKey, from, err := registeredCurrency(batch, fromCurrency)
+	if err != nil {
+		return nil, err
+	}
+	toKey, to, err := registeredCurrency(batch, toCurrency)
+	if err != nil {
+		return nil, err
+	}
+	rate, err := currencyRate(batch, fromCurrency, toCurrency)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := batch.getUser(account); err != nil {
+		return nil, err
+	}
+	if err := checkSuspended(batch, account); err != nil {
+		return nil, err
+	}
+
+	// amount * rate * (1 - spread) can overflow an int
+	received := new(big.Int).Mul(big.NewInt(int64(amount)), big.NewInt(int64(rate.Rate)))
+	received.Mul(received, big.NewInt(int64(MaxConversionSpread-init.ConversionSpread)))
+	received.Quo(received, big.NewInt(int64(RateScale)*MaxConversionSpread))
+	if !received.IsInt64() || received.Int64() > validation.MaxAmount {
+		return nil, newError(CodeBalanceOverflow, "cannot convert %d %s: amount overflow", amount, fromCurrency)
+	}
+	if received.Sign() == 0 {
+		return nil, newError(CodeInvalidArgument, "%d %s converts to no %s", amount, fromCurrency, toCurrency)
+	}
+	value := int(received.Int64())
+
+	err = debitCurrency(batch, fromCurrency, account, amount)
+	if err != nil {
+		return nil, err
+	}
+	err = creditCurrency(batch, toCurrency, account, value)
+	if err != nil {
+		return nil, err
+	}
+	var ok bool
+	from.Supply -= amount
+	to.Supply, ok = addBalance(to.Supply, value)
+	if !ok {
+		return nil, newError(CodeBalanceOverflow, "cannot mint %d %s: supply overflow", value, toCurrency)
+	}
+	err = batch.putState(fromKey, from)
+	if err != nil {
+		return nil, err
+	}
+	err = batch.putState(toKey, to)
+	if err != nil {
+		return nil, err
+	}
+
+	return recordCurrencyTransaction(batch, &CurrencyTransaction{
+		Currency:   fromCurrency,
+		Type:       TransactionConvert,
+		From:       account,
+		To:         account,
+		Value:      amount,
+		ToCurrency: toCurrency,
+		Received:   value,
+		Rate:       rate.Rate,
+		Spread:     init.ConversionSpread,
+	})
+}
+
+// isOracle reports whether the client has the OracleOU
+func isOracle(ctx contractapi.TransactionContextInterface) bool {
+	return hasRole(ctx, OracleOU)
+}
+
+// currencyRate reads the published rate of fromCurrency in toCurrency,
+// failing if there is none
+func currencyRate(batch *writeBatch, fromCurrency string, toCurrency string) (*Rate, error) {
+	key, err := batch.ctx.GetStub().CreateCompositeKey(rateObjectType, []string{fromCurrency, toCurrency})
+	if err != nil {
+		return nil, err
+	}
+	data, err := batch.getState(key)
+	if err != nil {
+		return nil, err
+	}
+	if data == nil {
+		return nil, newError(CodeInvalidArgument, "no rate of %s in %s is published", fromCurrency, toCurrency)
+	}
+
+	var rate Rate
+	err = decodeRecord(key, data, &rate, "from", "to", "rate")
+	if err != nil {
+		return nil, err
+	}
+
+	return &rate, nil
+}
@@ -0,0 +1,94 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package chaincode_test
+
+import (
+	"testing"
+
+	"github.com/kkiu1756/my_fabric/src/chaincode-go/chaincode"
+	"github.com/kkiu1756/my_fabric/src/chaincode-go/tokentest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// #########
+// HELPERS
+// #########
+
+// currencyLedger returns a ledger with the KRW and POINT currencies and
+// alice holding 1000 KRW
+func currencyLedger(t *testing.T) *tokentest.Ledger {
+	l := adminLedger(t).WithAccount("alice", "user", 0)
+	contract := &chaincode.SmartContract{}
+	for _, symbol := range []string{"KRW", "POINT"} {
+		_, err := contract.RegisterCurrency(l.Context, symbol, symbol+" token")
+		require.NoError(t, err)
+	}
+	_, err := contract.MintCurrency(l.Context, "KRW", "alice", 1000)
+	require.NoError(t, err)
+	return l
+}
+
+// #########
+// TESTS
+// #########
+
+func TestConvert(t *testing.T) {
+	l := currencyLedger(t)
+	contract := &chaincode.SmartContract{}
+
+	l.WithCaller("Org1MSP", "oracle1", "client", chaincode.OracleOU)
+	_, err := contract.SetRate(l.Context, "KRW", "POINT", chaincode.RateScale/10)
+	require.NoError(t, err)
+	l.WithCaller("Org1MSP", "Admin@org1.example.com", chaincode.AdminOU)
+	_, err = contract.SetConversionSpread(l.Context, 200)
+	require.NoError(t, err)
+
+	l.WithCaller("Org1MSP", "alice", "client").WithTxID("convert")
+	transaction, err := contract.Convert(l.Context, "KRW", "POINT", 500)
+	require.NoError(t, err)
+	assert.Equal(t, &chaincode.CurrencyTransaction{
+		TXID: "convert", Currency: "KRW", Type: chaincode.TransactionConvert, From: "alice", To: "alice", Value: 500,
+		Timestamp: transaction.Timestamp, ToCurrency: "POINT", Received: 49, Rate: chaincode.RateScale / 10, Spread: 200,
+	}, transaction)
+
+	for symbol, expected := range map[string]int{"KRW": 500, "POINT": 49} {
+		balance, err := contract.GetCurrencyBalance(l.Context, symbol, "alice")
+		require.NoError(t, err)
+		assert.Equal(t, expected, balance, symbol)
+		currency, err := contract.GetCurrency(l.Context, symbol)
+		require.NoError(t, err)
+		assert.Equal(t, expected, currency.Supply, symbol)
+	}
+
+	_, err = contract.Convert(l.Context, "KRW", "POINT", 5)
+	assert.EqualError(t, err, "[INVALID_ARGUMENT] 5 KRW converts to no POINT")
+	_, err = contract.Convert(l.Context, "POINT", "KRW", 5)
+	assert.EqualError(t, err, "[INVALID_ARGUMENT] no rate of POINT in KRW is published")
+	_, err = contract.Convert(l.Context, "KRW", "POINT", 600)
+	assert.EqualError(t, err, "[INSUFFICIENT_BALANCE] KRW balance of alice lower than 600")
+}
+
+func TestSetRateRejects(t *testing.T) {
+	l := currencyLedger(t)
+	contract := &chaincode.SmartContract{}
+
+	_, err := contract.SetRate(l.Context, "KRW", "KRW", chaincode.RateScale)
+	assert.EqualError(t, err, "[INVALID_ARGUMENT] invalid toCurrency: must differ from fromCurrency")
+	_, err = contract.SetRate(l.Context, "KRW", "USD", chaincode.RateScale)
+	assert.EqualError(t, err, "[INVALID_ARGUMENT] currency USD is not registered")
+	_, err = contract.SetConversionSpread(l.Context, 10001)
+	assert.EqualError(t, err, "[INVALID_ARGUMENT] invalid spread: must be between 0 and 10000 basis points")
+
+	l.WithCaller("Org1MSP", "alice", "client")
+	_, err = contract.SetRate(l.Context, "KRW", "POINT", chaincode.RateScale)
+	assert.EqualError(t, err, "[UNAUTHORIZED] only an organization admin can publish a rate")
+	l.WithCaller("Org2MSP", "oracle1", "client", chaincode.OracleOU)
+	_, err = contract.SetRate(l.Context, "KRW", "POINT", chaincode.RateScale)
+	assert.EqualError(t, err, "[UNAUTHORIZED] only an organization admin can publish a rate")
+	l.WithCaller("Org2MSP", "Admin@org2.example.com", chaincode.AdminOU)
+	_, err = contract.SetRate(l.Context, "KRW", "POINT", chaincode.RateScale)
+	assert.EqualError(t, err, "[UNAUTHORIZED] only an admin of Org1MSP can publish a rate")
+}
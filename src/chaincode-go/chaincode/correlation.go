@@ -0,0 +1,126 @@
+package chaincode
+
+import (
+	"encoding/json"
+
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+	"github.com/kkiu1756/my_fabric/src/chaincode-go/validation"
+)
+
+// A client traces a business flow across transactions by passing the same
+// correlation id, an ID, in the transient field CorrelationTransientKey of
+// each proposal. Any method accepts it: the Transaction record of the call
+// stores it, every event of the call carries it as correlationId, and
+// GetTransactionsByCorrelationID lists the recorded transactions, indexed
+// under correlationObjectType~id~txid.
+const CorrelationTransientKey = "correlationId"
+
+// correlationObjectType keys the transactions by correlation id
+const correlationObjectType = "correlation"
+
+// correlationEntry is the index entry of a transaction
+type correlationEntry struct {
+	TXID string `json:"txId"`
+}
+
+// GetTransactionsByCorrelationID returns the transactions recorded with
+// correlation id, in transaction id order
+func (s *SmartContract) GetTransactionsByCorrelationID(ctx contractapi.TransactionContextInterface, id string) ([]*Transaction, error) {
+	if err := validate(validation.ID("id", id)); err != nil {
+		return nil, err
+	}
+
+	transactions := []*Transaction{}
+	var entry correlationEntry
+	err := forEachRecord(ctx, id, correlationObjectType, []string{id}, &entry, []string{"txId"}, func(string) error {
+		transaction, err := s.GetTransaction(ctx, entry.TXID)
+		if err != nil {
+			return err
+		}
+		transactions = append(transactions, transaction)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return transactions, nil
+}
+
+// checkCorrelationID fails if the proposal carries an invalid correlation id
+func checkCorrelationID(ctx contractapi.TransactionContextInterface) error {
+	transient, err := ctx.GetStub().GetTransient()
+	if err != nil {
+		return nil
+	}
+	id, ok := transient[CorrelationTransientKey]
+	if !ok {
+		return nil
+	}
+
+	return validate(validation.ID("correlationId", string(id)))
+}
+
+// correlationID returns the correlation id of the proposal, "" if none
+func correlationID(stub shim.ChaincodeStubInterface) string {
+	transient, err := stub.GetTransient()
+	if err != nil {
+		return ""
+	}
+
+	return string(transient[CorrelationTransientKey])
+}
+
+// indexCorrelation buffers the index entry of transaction under its
+// correlation id, if it has one
+func indexCorrelation(batch *writeBatch, transaction *Transaction) error {
+	if transaction.CorrelationID == "" {
+		return nil
+	}
+	key, err := batch.ctx.GetStub().CreateCompositeKey(correlationObjectType, []string{transaction.CorrelationID, transaction.TXID})
+	if err != nil {
+		return err
+	}
+
+	return batch.putState(key, correlationEntry{TXID: transaction.TXID})
+}
+
+// withCorrelationID adds the correlation id of the proposal to an event
+// payload that is a JSON object
+func withCorrelationID(stub shim.ChaincodeStubInterface, payloadJSON []byte) []byte {
+	id := correlationID(stub)
+	if id == "" {
+		return payloadJSON
+	}
+
+	data, ok := withField(payloadJSON, "correlationId", id)
+	if !ok {
+		return payloadJSON
+	}
+	return data
+}
+
+// withField sets field to the encoding of value in payloadJSON. It reports
+// false if the payload is not a JSON object.
+func withField(payloadJSON []byte, field string, value interface{}) ([]byte, bool) {
+	if len(payloadJSON) == 0 || payloadJSON[0] != '{' {
+		return nil, false
+	}
+
+	var fields map[string]json.RawMessage
+	if json.Unmarshal(payloadJSON, &fields) != nil {
+		return nil, false
+	}
+	encoded, err := json.Marshal(value)
+	if err != nil {
+		return nil, false
+	}
+	fields[field] = encoded
+	data, err := json.Marshal(fields)
+	if err != nil {
+		return nil, false
+	}
+
+	return data, true
+}
@@ -0,0 +1,66 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package chaincode_test
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"testing"
+
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+	"github.com/kkiu1756/my_fabric/src/chaincode-go/chaincode"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCorrelationID(t *testing.T) {
+	l := adminLedger(t).
+		WithAccount("alice", "user", 100).
+		WithAccount("bob", "user", 0)
+	paginate(l.Stub, l.State)
+	l.Stub.GetStateByPartialCompositeKeyStub = func(objectType string, attributes []string) (shim.StateQueryIteratorInterface, error) {
+		it, _, err := l.Stub.GetStateByPartialCompositeKeyWithPagination(objectType, attributes, 1000, "")
+		return it, err
+	}
+	contract := &chaincode.SmartContract{}
+	before := contract.GetBeforeTransaction().(func(contractapi.TransactionContextInterface) error)
+
+	l.Stub.GetTransientReturns(map[string][]byte{chaincode.CorrelationTransientKey: []byte("order-42")}, nil)
+	require.NoError(t, before(l.Context))
+	l.WithTxID("pay")
+	_, err := contract.TransferFrom(l.Context, "alice", "bob", 30)
+	require.NoError(t, err)
+	l.WithTxID("refund")
+	_, err = contract.TransferFrom(l.Context, "bob", "alice", 10)
+	require.NoError(t, err)
+	name, payload := l.Stub.SetEventArgsForCall(l.Stub.SetEventCallCount() - 1)
+	assert.Equal(t, chaincode.EventTransfer, name, "the legacy event of a ledger without a version")
+	assert.Contains(t, string(payload), `"correlationId":"order-42"`)
+	digest := sha256.Sum256([]byte("certificate"))
+	_, err = contract.RevokeCertificate(l.Context, hex.EncodeToString(digest[:]), "")
+	require.NoError(t, err)
+	name, payload = l.Stub.SetEventArgsForCall(l.Stub.SetEventCallCount() - 1)
+	assert.Equal(t, chaincode.EventCertificateRevoked, name)
+	assert.Contains(t, string(payload), `"correlationId":"order-42"`, "every event carries the correlation id")
+
+	l.Stub.GetTransientReturns(nil, nil)
+	l.WithTxID("other")
+	_, err = contract.TransferFrom(l.Context, "alice", "bob", 5)
+	require.NoError(t, err)
+
+	transactions, err := contract.GetTransactionsByCorrelationID(l.Context, "order-42")
+	require.NoError(t, err)
+	require.Len(t, transactions, 2)
+	assert.Equal(t, "pay", transactions[0].TXID)
+	assert.Equal(t, "refund", transactions[1].TXID)
+	assert.Equal(t, "order-42", transactions[1].CorrelationID)
+	other, err := contract.GetTransaction(l.Context, "other")
+	require.NoError(t, err)
+	assert.Empty(t, other.CorrelationID)
+
+	l.Stub.GetTransientReturns(map[string][]byte{chaincode.CorrelationTransientKey: []byte("")}, nil)
+	assert.EqualError(t, before(l.Context), "[INVALID_ARGUMENT] invalid correlationId: must not be empty")
+}
@@ -0,0 +1,184 @@
+package chaincode
+
+import (
+	"fmt"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+	"github.com/kkiu1756/my_fabric/src/chaincode-go/validation"
+)
+
+// An org admin can extend a credit line to an account with SetCreditLine,
+// letting transfers from it take its balance negative down to minus the
+// limit. Only transfers draw on it; burns, distributions and other debits
+// still need the balance. A transfer that draws credit emits
+// EventCreditDrawn and one that pays back an account's negative balance
+// EventCreditRepaid, replacing its typed event; a transfer doing both
+// emits EventCreditDrawn with both amounts. A legacy Transfer or
+// LargeTransfer event stays, with the amounts added, and the Cashback of
+// the transfer joins the credit event, see events.go. A negative balance
+// earns no interest, pays no demurrage and weighs nothing in a snapshot.
+// Hot accounts and the escrow account cannot have a credit line.
+
+// EventCreditDrawn replaces the typed event of a transfer that drew credit
+const EventCreditDrawn = "CreditDrawn"
+
+// EventCreditRepaid replaces the typed event of a transfer that repaid
+// credit
+const EventCreditRepaid = "CreditRepaid"
+
+// creditLineObjectType keys the credit lines by account
+const creditLineObjectType = "creditline"
+
+// CreditLine is how far an account's balance may go negative
+type CreditLine struct {
+	Account string `json:"account"`
+	Limit   int    `json:"limit"`
+	// UpdatedAt is the proposal timestamp of SetCreditLine, RFC 3339
+	UpdatedAt string `json:"updatedAt"`
+}
+
+// CreditExposure is the credit an account has drawn
+type CreditExposure struct {
+	Account string `json:"account"`
+	Limit   int    `json:"limit"`
+	// Drawn is the negative balance of the account, as a positive amount
+	Drawn int `json:"drawn"`
+	// Available is the credit left to draw
+	Available int `json:"available"`
+}
+
+// creditEvent is the payload of EventCreditDrawn and EventCreditRepaid
+type creditEvent struct {
+	*Transaction
+	// Drawn is the credit the sender drew and Repaid the credit of the
+	// recipient paid back
+	Drawn  int `json:"drawn,omitempty"`
+	Repaid int `json:"repaid,omitempty"`
+}
+
+// SetCreditLine lets account go negative by up to limit; 0 removes its
+// credit line. It cannot be lowered below the credit drawn. Only an org
+// admin can call it.
+func (s *SmartContract) SetCreditLine(ctx contractapi.TransactionContextInterface, account string, limit int) (*CreditLine, error) {
+	err := validate(
+		validation.AccountID("account", account),
+		validation.Amount("limit", limit),
+	)
+	if err != nil {
+		return nil, err
+	}
+	if err := requireInitialized(ctx); err != nil {
+		return nil, err
+	}
+	if _, err := requireAdmin(ctx, "set credit lines"); err != nil {
+		return nil, err
+	}
+
+	batch := newWriteBatch(ctx)
+	user, err := batch.getUser(account)
+	if err != nil {
+		return nil, err
+	}
+	if user.Hot || account == EscrowAccount {
+		return nil, newError(CodeInvalidArgument, "account %s cannot have a credit line", account)
+	}
+	if drawn := creditDrawn(user.Balance); drawn > limit {
+		return nil, newError(CodeInvalidArgument, "account %s has drawn %d of credit", account, drawn)
+	}
+
+	key, err := ctx.GetStub().CreateCompositeKey(creditLineObjectType, []string{account})
+	if err != nil {
+		return nil, err
+	}
+	timestamp, err := txTimestamp(ctx.GetStub())
+	if err != nil {
+		return nil, err
+	}
+	line := &CreditLine{Account: account, Limit: limit, UpdatedAt: timestamp}
+	if limit == 0 {
+		batch.delState(key)
+	} else {
+		err = batch.putState(key, line)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	err = appendAudit(batch, account, AuditSetCreditLine, user.Balance, user.Balance, fmt.Sprintf("credit line of %d", limit))
+	if err != nil {
+		return nil, err
+	}
+
+	err = batch.flush()
+	if err != nil {
+		return nil, err
+	}
+
+	return line, nil
+}
+
+// GetCreditExposure returns the credit line of account and how much of it
+// is drawn
+func (s *SmartContract) GetCreditExposure(ctx contractapi.TransactionContextInterface, account string) (*CreditExposure, error) {
+	if err := validate(validation.ID("account", account)); err != nil {
+		return nil, err
+	}
+
+	user, err := s.GetUser(ctx, account)
+	if err != nil {
+		return nil, err
+	}
+	limit, err := creditLimit(newWriteBatch(ctx), account)
+	if err != nil {
+		return nil, err
+	}
+	drawn := creditDrawn(user.Balance)
+
+	return &CreditExposure{Account: account, Limit: limit, Drawn: drawn, Available: limit - drawn}, nil
+}
+
+// creditLimit returns the credit line of account, 0 if it has none
+func creditLimit(batch *writeBatch, account string) (int, error) {
+	key, err := batch.ctx.GetStub().CreateCompositeKey(creditLineObjectType, []string{account})
+	if err != nil {
+		return 0, err
+	}
+	data, err := batch.getState(key)
+	if err != nil || data == nil {
+		return 0, err
+	}
+
+	var line CreditLine
+	err = decodeRecord(key, data, &line, "account", "limit")
+	if err != nil {
+		return 0, err
+	}
+
+	return line.Limit, nil
+}
+
+// creditDrawn returns the credit drawn by an account holding balance
+func creditDrawn(balance int) int {
+	if balance < 0 {
+		return -balance
+	}
+	return 0
+}
+
+// emitCreditEvent replaces the typed event of transaction if it drew or
+// repaid credit, given the balances of its accounts before it
+func emitCreditEvent(batch *writeBatch, transaction *Transaction, fromBefore int, fromAfter int, toBefore int, toAfter int) error {
+	event := creditEvent{
+		Transaction: transaction,
+		Drawn:       creditDrawn(fromAfter) - creditDrawn(fromBefore),
+		Repaid:      creditDrawn(toBefore) - creditDrawn(toAfter),
+	}
+	switch {
+	case event.Drawn > 0:
+		return emitTypedEvent(batch, EventCreditDrawn, event)
+	case event.Repaid > 0:
+		return emitTypedEvent(batch, EventCreditRepaid, event)
+	}
+
+	return nil
+}
@@ -0,0 +1,139 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package chaincode_test
+
+import (
+	"testing"
+
+	"github.com/kkiu1756/my_fabric/src/chaincode-go/chaincode"
+	"github.com/kkiu1756/my_fabric/src/chaincode-go/tokentest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCreditLine(t *testing.T) {
+	l := initializedLedger(t).
+		WithAccount("alice", "user", 100).
+		WithAccount("bob", "user", 0)
+	contract := &chaincode.SmartContract{}
+
+	_, err := contract.TransferFrom(l.Context, "alice", "bob", 300)
+	assert.EqualError(t, err, "failed to transfer: [INSUFFICIENT_BALANCE] user balance lower than 300")
+
+	_, err = contract.SetCreditLine(l.Context, "alice", 500)
+	require.NoError(t, err)
+
+	l.WithTxID("draw")
+	_, err = contract.TransferFrom(l.Context, "alice", "bob", 300)
+	require.NoError(t, err)
+	l.AssertBalance("alice", -200)
+	l.AssertBalance("bob", 300)
+	transaction, err := contract.GetTransaction(l.Context, "draw")
+	require.NoError(t, err)
+	l.AssertEvent(chaincode.EventCreditDrawn, map[string]interface{}{
+		"txId": "draw", "type": "transfer", "from": "alice", "to": "bob", "value": 300, "txTimestamp": transaction.Timestamp, "channelId": transaction.ChannelID,
+		"initiatorMspId": transaction.InitiatorMSPID, "initiator": transaction.Initiator, "drawn": 200,
+	})
+
+	exposure, err := contract.GetCreditExposure(l.Context, "alice")
+	require.NoError(t, err)
+	assert.Equal(t, &chaincode.CreditExposure{Account: "alice", Limit: 500, Drawn: 200, Available: 300}, exposure)
+
+	_, err = contract.TransferFrom(l.Context, "alice", "bob", 301)
+	assert.EqualError(t, err, "failed to transfer: [INSUFFICIENT_BALANCE] user balance lower than 301")
+	_, err = contract.SetCreditLine(l.Context, "alice", 100)
+	assert.EqualError(t, err, "[INVALID_ARGUMENT] account alice has drawn 200 of credit")
+
+	l.WithTxID("repay")
+	_, err = contract.TransferFrom(l.Context, "bob", "alice", 250)
+	require.NoError(t, err)
+	l.AssertBalance("alice", 50)
+	transaction, err = contract.GetTransaction(l.Context, "repay")
+	require.NoError(t, err)
+	l.AssertEvent(chaincode.EventCreditRepaid, map[string]interface{}{
+		"txId": "repay", "type": "transfer", "from": "bob", "to": "alice", "value": 250, "txTimestamp": transaction.Timestamp, "channelId": transaction.ChannelID,
+		"initiatorMspId": transaction.InitiatorMSPID, "initiator": transaction.Initiator, "repaid": 200,
+	})
+
+	_, err = contract.SetCreditLine(l.Context, "alice", 0)
+	require.NoError(t, err)
+	exposure, err = contract.GetCreditExposure(l.Context, "alice")
+	require.NoError(t, err)
+	assert.Equal(t, &chaincode.CreditExposure{Account: "alice"}, exposure)
+}
+
+func TestCreditKeepsLargeTransfer(t *testing.T) {
+	l := initializedLedger(t).
+		WithAccount("alice", "user", 100).
+		WithAccount("bob", "user", 0)
+	contract := &chaincode.SmartContract{}
+	_, err := contract.SetCreditLine(l.Context, "alice", 500)
+	require.NoError(t, err)
+	_, err = contract.SetLargeTransferThreshold(l.Context, 200)
+	require.NoError(t, err)
+
+	transaction, err := contract.TransferFrom(l.WithTxID("draw").Context, "alice", "bob", 300)
+	require.NoError(t, err)
+	l.AssertEvent(chaincode.EventLargeTransfer, map[string]interface{}{
+		"txId": "draw", "type": "transfer", "from": "alice", "to": "bob", "value": 300, "txTimestamp": transaction.Timestamp, "channelId": transaction.ChannelID,
+		"initiatorMspId": transaction.InitiatorMSPID, "initiator": transaction.Initiator, "threshold": 200, "drawn": 200, "events": []string{chaincode.EventCreditDrawn},
+	})
+}
+
+func TestCreditWithCashback(t *testing.T) {
+	contract := &chaincode.SmartContract{}
+	withCredit := func(l *tokentest.Ledger) *tokentest.Ledger {
+		l.WithAccount("rewards", "pool", 100).WithAccount("alice", "user", 100).WithAccount("shop", chaincode.SellerType, 0)
+		_, err := contract.SetCreditLine(l.Context, "alice", 500)
+		require.NoError(t, err)
+		_, err = contract.SetCashback(l.Context, "rewards", 500)
+		require.NoError(t, err)
+		return l
+	}
+
+	l := withCredit(initializedLedger(t))
+	transaction, err := contract.TransferFrom(l.WithTxID("draw").Context, "alice", "shop", 300)
+	require.NoError(t, err)
+	l.AssertBalance("alice", -185)
+	l.AssertEvent(chaincode.EventCreditDrawn, map[string]interface{}{
+		"txId": "draw", "type": "transfer", "from": "alice", "to": "shop", "value": 300, "txTimestamp": transaction.Timestamp, "channelId": transaction.ChannelID,
+		"initiatorMspId": transaction.InitiatorMSPID, "initiator": transaction.Initiator, "drawn": 200,
+		"cashback": 15, "pool": "rewards", "events": []string{chaincode.EventCashback},
+	})
+
+	l = withCredit(adminLedger(t))
+	transaction, err = contract.TransferFrom(l.WithTxID("draw").Context, "alice", "shop", 300)
+	require.NoError(t, err)
+	l.AssertEvent(chaincode.EventTransfer, map[string]interface{}{
+		"txId": "draw", "type": "transfer", "from": "alice", "to": "shop", "value": 300, "txTimestamp": transaction.Timestamp, "channelId": transaction.ChannelID,
+		"initiatorMspId": transaction.InitiatorMSPID, "initiator": transaction.Initiator, "drawn": 200,
+		"cashback": 15, "pool": "rewards", "events": []string{chaincode.EventCreditDrawn, chaincode.EventCashback},
+	})
+
+	l = withCredit(initializedLedger(t))
+	_, err = contract.SetLegacyEventWindow(l.Context, "2999-01-01T00:00:00Z")
+	require.NoError(t, err)
+	transaction, err = contract.TransferFrom(l.WithTxID("draw").Context, "alice", "shop", 300)
+	require.NoError(t, err)
+	l.AssertEvent(chaincode.EventTransfer, map[string]interface{}{
+		"txId": "draw", "type": "transfer", "from": "alice", "to": "shop", "value": 300, "txTimestamp": transaction.Timestamp, "channelId": transaction.ChannelID,
+		"initiatorMspId": transaction.InitiatorMSPID, "initiator": transaction.Initiator, "event": chaincode.EventTransferred, "drawn": 200,
+		"cashback": 15, "pool": "rewards", "events": []string{chaincode.EventCreditDrawn, chaincode.EventCashback},
+	})
+}
+
+func TestSetCreditLineRejects(t *testing.T) {
+	l := adminLedger(t).WithAccount("alice", "user", 100)
+	contract := &chaincode.SmartContract{}
+
+	_, err := contract.SetCreditLine(l.Context, "alice", -1)
+	assert.EqualError(t, err, "[INVALID_ARGUMENT] invalid limit: must not be negative")
+	_, err = contract.SetCreditLine(l.Context, "carol", 100)
+	assert.EqualError(t, err, "[ACCOUNT_NOT_FOUND] user carol does not exist")
+
+	l.WithCaller("Org1MSP", "alice", "client")
+	_, err = contract.SetCreditLine(l.Context, "alice", 100)
+	assert.EqualError(t, err, "[UNAUTHORIZED] only an organization admin can set credit lines")
+}
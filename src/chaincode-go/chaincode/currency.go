@@ -0,0 +1,459 @@
+package chaincode
+
+import (
+	"encoding/json"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+	"github.com/kkiu1756/my_fabric/src/chaincode-go/validation"
+)
+
+// Besides its base token, the balance of each account, the ledger holds
+// the currencies an org admin registers with RegisterCurrency, such as a
+// point token next to a KRW token. Each currency has its own supply,
+// changed only by MintCurrency and BurnCurrency, and its own balance on
+// every account, moved by TransferCurrency; the methods of the base token
+// keep their signatures and never touch them. Adding a currency parameter
+// to Transfer, Mint, Burn and the methods built on them would break every
+// client and the generated ones, so the base token has no symbol and is
+// not a currency.
+//
+// TransferCurrency resolves aliases and runs the checks of a base token
+// transfer on the sender: suspension, hierarchy, treasury and transfer
+// limits, counting the value against the same limits in the currency's
+// own units. A transfer over the large transfer threshold, see
+// compliance.go, is flagged with the currency in its review, and its
+// CurrencyTransferred event joins the LargeTransfer event.
+//
+// A currency's balance of an account is stored as a JSON integer under
+// currencybalance~symbol~account, and each currency operation records a
+// CurrencyTransaction under currencytx~txid in place of a Transaction, so
+// the base token's statements and supply leave the currencies out.
+
+// EventCurrencyTransferred is the event of a currency operation
+const EventCurrencyTransferred = "CurrencyTransferred"
+
+const (
+	// currencyObjectType keys the currencies by symbol
+	currencyObjectType = "currency"
+	// currencyBalanceObjectType keys the balances by symbol and account
+	currencyBalanceObjectType = "currencybalance"
+	// currencyTxObjectType keys the currency operations by transaction id
+	currencyTxObjectType = "currencytx"
+)
+
+// Currency is a registered currency
+type Currency struct {
+	Symbol string `json:"symbol"`
+	Name   string `json:"name"`
+	// Supply is the total of the currency's balances
+	Supply int `json:"supply"`
+	// RegisteredAt is the proposal timestamp of RegisterCurrency, RFC 3339
+	RegisteredAt string `json:"registeredAt"`
+}
+
+// CurrencyTransaction records a currency operation. A mint comes from "",
+// a burn goes to "".
+type CurrencyTransaction struct {
+	TXID     string          `json:"txId"`
+	Currency string          `json:"currency"`
+	Type     TransactionType `json:"type"`
+	From     string          `json:"from"`
+	To       string          `json:"to"`
+	Value    int             `json:"value"`
+	// Timestamp is the proposal timestamp in RFC 3339 format
+	Timestamp string `json:"txTimestamp"`
+	// ToCurrency, Received, Rate and Spread record a conversion, see
+	// convert.go
+	ToCurrency string `json:"toCurrency,omitempty" metadata:"toCurrency,optional"`
+	Received   int    `json:"received,omitempty" metadata:"received,optional"`
+	Rate       int    `json:"rate,omitempty" metadata:"rate,optional"`
+	Spread     int    `json:"spread,omitempty" metadata:"spread,optional"`
+}
+
+// largeCurrencyTransferEvent is the payload of EventLargeTransfer for a
+// currency transfer
+type largeCurrencyTransferEvent struct {
+	*CurrencyTransaction
+	Threshold int `json:"threshold"`
+}
+
+// RegisterCurrency registers currency symbol named name with no supply.
+// Only an org admin can call it.
+func (s *SmartContract) RegisterCurrency(ctx contractapi.TransactionContextInterface, symbol string, name string) (*Currency, error) {
+	errs := []error{
+		validation.ID("symbol", symbol),
+		validation.Memo("name", name),
+	}
+	if name == "" {
+		errs = append(errs, &validation.Error{Field: "name", Reason: "must not be empty"})
+	}
+	if err := validate(errs...); err != nil {
+		return nil, err
+	}
+	if err := requireInitialized(ctx); err != nil {
+		return nil, err
+	}
+	if _, err := requireAdmin(ctx, "register a currency"); err != nil {
+		return nil, err
+	}
+
+	batch := newWriteBatch(ctx)
+	key, existing, err := currency(batch, symbol)
+	if err != nil {
+		return nil, err
+	}
+	if existing != nil {
+		return nil, newError(CodeInvalidArgument, "currency %s is already registered", symbol)
+	}
+
+	timestamp, err := txTimestamp(ctx.GetStub())
+	if err != nil {
+		return nil, err
+	}
+	registered := &Currency{Symbol: symbol, Name: name, RegisteredAt: timestamp}
+	err = batch.putState(key, registered)
+	if err != nil {
+		return nil, err
+	}
+
+	err = batch.flush()
+	if err != nil {
+		return nil, err
+	}
+
+	return registered, nil
+}
+
+// GetCurrency returns currency symbol with its supply
+func (s *SmartContract) GetCurrency(ctx contractapi.TransactionContextInterface, symbol string) (*Currency, error) {
+	if err := validate(validation.ID("symbol", symbol)); err != nil {
+		return nil, err
+	}
+
+	_, registered, err := registeredCurrency(newWriteBatch(ctx), symbol)
+	if err != nil {
+		return nil, err
+	}
+
+	return registered, nil
+}
+
+// GetCurrencyBalance returns the balance of account in currency symbol
+func (s *SmartContract) GetCurrencyBalance(ctx contractapi.TransactionContextInterface, symbol string, account string) (int, error) {
+	err := validate(
+		validation.ID("symbol", symbol),
+		validation.ID("account", account),
+	)
+	if err != nil {
+		return 0, err
+	}
+
+	batch := newWriteBatch(ctx)
+	if _, _, err := registeredCurrency(batch, symbol); err != nil {
+		return 0, err
+	}
+	if _, err := batch.getUser(account); err != nil {
+		return 0, err
+	}
+	_, balance, err := currencyBalance(batch, symbol, account)
+	return balance, err
+}
+
+// MintCurrency credits account with amount of currency symbol, adding it
+// to the supply. Only an org admin can call it.
+func (s *SmartContract) MintCurrency(ctx contractapi.TransactionContextInterface, symbol string, account string, amount int) (*CurrencyTransaction, error) {
+	if err := validateCurrencyOp(symbol, "amount", amount, validation.AccountID("account", account)); err != nil {
+		return nil, err
+	}
+	if err := requireInitialized(ctx); err != nil {
+		return nil, err
+	}
+	if _, err := requireAdmin(ctx, "mint a currency"); err != nil {
+		return nil, err
+	}
+
+	batch := newWriteBatch(ctx)
+	key, registered, err := registeredCurrency(batch, symbol)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := batch.getUser(account); err != nil {
+		return nil, err
+	}
+	var ok bool
+	registered.Supply, ok = addBalance(registered.Supply, amount)
+	if !ok {
+		return nil, newError(CodeBalanceOverflow, "cannot mint %d %s: supply overflow", amount, symbol)
+	}
+	err = creditCurrency(batch, symbol, account, amount)
+	if err != nil {
+		return nil, err
+	}
+	err = batch.putState(key, registered)
+	if err != nil {
+		return nil, err
+	}
+
+	return recordCurrencyTransaction(batch, &CurrencyTransaction{Currency: symbol, Type: TransactionMint, To: account, Value: amount})
+}
+
+// BurnCurrency debits amount of currency symbol from account, taking it
+// from the supply. Only an org admin can call it.
+func (s *SmartContract) BurnCurrency(ctx contractapi.TransactionContextInterface, symbol string, account string, amount int) (*CurrencyTransaction, error) {
+	if err := validateCurrencyOp(symbol, "amount", amount, validation.ID("account", account)); err != nil {
+		return nil, err
+	}
+	if err := requireInitialized(ctx); err != nil {
+		return nil, err
+	}
+	if _, err := requireAdmin(ctx, "burn a currency"); err != nil {
+		return nil, err
+	}
+
+	batch := newWriteBatch(ctx)
+	key, registered, err := registeredCurrency(batch, symbol)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := batch.getUser(account); err != nil {
+		return nil, err
+	}
+	err = debitCurrency(batch, symbol, account, amount)
+	if err != nil {
+		return nil, err
+	}
+	registered.Supply -= amount
+	err = batch.putState(key, registered)
+	if err != nil {
+		return nil, err
+	}
+
+	return recordCurrencyTransaction(batch, &CurrencyTransaction{Currency: symbol, Type: TransactionBurn, From: account, Value: amount})
+}
+
+// TransferCurrency transfers value of currency symbol from the "from"
+// account to the "to" account
+func (s *SmartContract) TransferCurrency(ctx contractapi.TransactionContextInterface, symbol string, from string, to string, value int) (*CurrencyTransaction, error) {
+	err := validateCurrencyOp(symbol, "value", value,
+		validation.ID("from", from),
+		validation.AccountID("to", to),
+	)
+	if err != nil {
+		return nil, err
+	}
+	if err := requireInitialized(ctx); err != nil {
+		return nil, err
+	}
+	from, to, err = resolveAccounts(ctx, from, to)
+	if err != nil {
+		return nil, err
+	}
+	if from == to {
+		return nil, newError(CodeInvalidArgument, "cannot transfer to and from same client account")
+	}
+
+	batch := newWriteBatch(ctx)
+	if _, _, err := registeredCurrency(batch, symbol); err != nil {
+		return nil, err
+	}
+	for _, account := range []string{from, to} {
+		if _, err := batch.getUser(account); err != nil {
+			return nil, err
+		}
+		if err := checkSuspended(batch, account); err != nil {
+			return nil, err
+		}
+	}
+	err = checkHierarchy(batch, from, to)
+	if err != nil {
+		return nil, err
+	}
+	err = checkTreasury(batch, from)
+	if err != nil {
+		return nil, err
+	}
+	err = debitCurrency(batch, symbol, from, value)
+	if err != nil {
+		return nil, err
+	}
+	err = checkTransferLimit(batch, from, value, false)
+	if err != nil {
+		return nil, err
+	}
+	err = creditCurrency(batch, symbol, to, value)
+	if err != nil {
+		return nil, err
+	}
+
+	return recordCurrencyTransaction(batch, &CurrencyTransaction{Currency: symbol, Type: TransactionTransfer, From: from, To: to, Value: value})
+}
+
+// GetCurrencyTransaction returns the currency operation of transaction txid
+func (s *SmartContract) GetCurrencyTransaction(ctx contractapi.TransactionContextInterface, txid string) (*CurrencyTransaction, error) {
+	if err := validate(validation.ID("txid", txid)); err != nil {
+		return nil, err
+	}
+
+	key, err := ctx.GetStub().CreateCompositeKey(currencyTxObjectType, []string{txid})
+	if err != nil {
+		return nil, err
+	}
+	data, err := getState(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+	if data == nil {
+		return nil, newError(CodeTransactionNotFound, "no currency transaction %s", txid)
+	}
+
+	var transaction CurrencyTransaction
+	err = decodeRecord(key, data, &transaction, "txId", "currency", "type", "value")
+	if err != nil {
+		return nil, err
+	}
+
+	return &transaction, nil
+}
+
+// validateCurrencyOp checks the symbol and the amount, argument field, of a
+// currency operation along with the checks of its accounts
+func validateCurrencyOp(symbol string, field string, amount int, accounts ...error) error {
+	errs := append([]error{validation.ID("symbol", symbol)}, accounts...)
+	errs = append(errs, validation.Amount(field, amount))
+	if amount == 0 {
+		errs = append(errs, &validation.Error{Field: field, Reason: "must be positive"})
+	}
+
+	return validate(errs...)
+}
+
+// recordCurrencyTransaction stamps transaction with the current
+// transaction, buffers its record and event and flushes batch
+func recordCurrencyTransaction(batch *writeBatch, transaction *CurrencyTransaction) (*CurrencyTransaction, error) {
+	stub := batch.ctx.GetStub()
+	timestamp, err := txTimestamp(stub)
+	if err != nil {
+		return nil, err
+	}
+	transaction.TXID = stub.GetTxID()
+	transaction.Timestamp = timestamp
+	key, err := stub.CreateCompositeKey(currencyTxObjectType, []string{transaction.TXID})
+	if err != nil {
+		return nil, err
+	}
+	err = batch.putState(key, transaction)
+	if err != nil {
+		return nil, err
+	}
+	if transaction.Type == TransactionTransfer {
+		threshold, err := reviewLargeTransfer(batch, &LargeTransferReview{
+			TXID:      transaction.TXID,
+			From:      transaction.From,
+			To:        transaction.To,
+			Value:     transaction.Value,
+			Timestamp: transaction.Timestamp,
+			Currency:  transaction.Currency,
+		})
+		if err != nil {
+			return nil, err
+		}
+		if threshold > 0 {
+			err = holdEvent(batch, EventLargeTransfer, largeCurrencyTransferEvent{transaction, threshold})
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+	err = emitTypedEvent(batch, EventCurrencyTransferred, transaction)
+	if err != nil {
+		return nil, err
+	}
+
+	err = batch.flush()
+	if err != nil {
+		return nil, err
+	}
+
+	return transaction, nil
+}
+
+// creditCurrency buffers a credit of amount of currency symbol to account
+func creditCurrency(batch *writeBatch, symbol string, account string, amount int) error {
+	key, balance, err := currencyBalance(batch, symbol, account)
+	if err != nil {
+		return err
+	}
+	balance, ok := addBalance(balance, amount)
+	if !ok {
+		return newError(CodeBalanceOverflow, "cannot credit %s: balance overflow", account)
+	}
+
+	return batch.putState(key, balance)
+}
+
+// debitCurrency buffers a debit of amount of currency symbol from account,
+// failing if its balance is lower
+func debitCurrency(batch *writeBatch, symbol string, account string, amount int) error {
+	key, balance, err := currencyBalance(batch, symbol, account)
+	if err != nil {
+		return err
+	}
+	if balance < amount {
+		return newError(CodeInsufficientBalance, "%s balance of %s lower than %d", symbol, account, amount)
+	}
+
+	return batch.putState(key, balance-amount)
+}
+
+// currencyBalance reads the balance of account in currency symbol and
+// returns its key
+func currencyBalance(batch *writeBatch, symbol string, account string) (string, int, error) {
+	key, err := batch.ctx.GetStub().CreateCompositeKey(currencyBalanceObjectType, []string{symbol, account})
+	if err != nil {
+		return "", 0, err
+	}
+	data, err := batch.getState(key)
+	if err != nil || data == nil {
+		return key, 0, err
+	}
+
+	var balance int
+	if err := json.Unmarshal(data, &balance); err != nil {
+		return "", 0, corrupt(key, err)
+	}
+	return key, balance, nil
+}
+
+// currency reads currency symbol, nil if it is not registered, and
+// returns its key
+func currency(batch *writeBatch, symbol string) (string, *Currency, error) {
+	key, err := batch.ctx.GetStub().CreateCompositeKey(currencyObjectType, []string{symbol})
+	if err != nil {
+		return "", nil, err
+	}
+	data, err := batch.getState(key)
+	if err != nil || data == nil {
+		return key, nil, err
+	}
+
+	var registered Currency
+	err = decodeRecord(key, data, &registered, "symbol", "name", "supply")
+	if err != nil {
+		return "", nil, err
+	}
+
+	return key, &registered, nil
+}
+
+// registeredCurrency is currency failing if symbol is not registered
+func registeredCurrency(batch *writeBatch, symbol string) (string, *Currency, error) {
+	key, registered, err := currency(batch, symbol)
+	if err == nil && registered == nil {
+		err = newError(CodeInvalidArgument, "currency %s is not registered", symbol)
+	}
+	if err != nil {
+		return "", nil, err
+	}
+
+	return key, registered, nil
+}
@@ -0,0 +1,160 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package chaincode_test
+
+import (
+	"testing"
+
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+	"github.com/kkiu1756/my_fabric/src/chaincode-go/chaincode"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCurrencies(t *testing.T) {
+	l := adminLedger(t).
+		WithAccount("alice", "user", 100).
+		WithAccount("bob", "user", 0)
+	contract := &chaincode.SmartContract{}
+	for _, symbol := range []string{"KRW", "POINT"} {
+		_, err := contract.RegisterCurrency(l.Context, symbol, symbol+" token")
+		require.NoError(t, err)
+	}
+
+	_, err := contract.MintCurrency(l.Context, "KRW", "alice", 500)
+	require.NoError(t, err)
+	_, err = contract.MintCurrency(l.Context, "POINT", "alice", 20)
+	require.NoError(t, err)
+
+	l.WithTxID("pay")
+	transaction, err := contract.TransferCurrency(l.Context, "KRW", "alice", "bob", 200)
+	require.NoError(t, err)
+	l.AssertEvent(chaincode.EventCurrencyTransferred, map[string]interface{}{
+		"txId": "pay", "currency": "KRW", "type": "transfer", "from": "alice", "to": "bob", "value": 200,
+		"txTimestamp": transaction.Timestamp,
+	})
+	stored, err := contract.GetCurrencyTransaction(l.Context, "pay")
+	require.NoError(t, err)
+	assert.Equal(t, transaction, stored)
+
+	_, err = contract.BurnCurrency(l.Context, "POINT", "alice", 5)
+	require.NoError(t, err)
+
+	for _, c := range []struct {
+		symbol  string
+		account string
+		balance int
+	}{
+		{"KRW", "alice", 300},
+		{"KRW", "bob", 200},
+		{"POINT", "alice", 15},
+		{"POINT", "bob", 0},
+	} {
+		balance, err := contract.GetCurrencyBalance(l.Context, c.symbol, c.account)
+		require.NoError(t, err)
+		assert.Equal(t, c.balance, balance, "%s of %s", c.symbol, c.account)
+	}
+	krw, err := contract.GetCurrency(l.Context, "KRW")
+	require.NoError(t, err)
+	assert.Equal(t, 500, krw.Supply)
+	point, err := contract.GetCurrency(l.Context, "POINT")
+	require.NoError(t, err)
+	assert.Equal(t, 15, point.Supply)
+
+	l.AssertBalance("alice", 100)
+	l.AssertBalance("bob", 0)
+}
+
+func TestCurrencyTransferChecks(t *testing.T) {
+	l := adminLedger(t).
+		WithAccount("alice", "user", 0).
+		WithAccount("bob", "user", 0).
+		WithAccount("sales", "org", 0).
+		WithAccount("org1-treasury", "org", 0).
+		WithAccount("carol", "user", 0)
+	paginate(l.Stub, l.State)
+	l.Stub.GetStateByPartialCompositeKeyStub = func(objectType string, attributes []string) (shim.StateQueryIteratorInterface, error) {
+		it, _, err := l.Stub.GetStateByPartialCompositeKeyWithPagination(objectType, attributes, 1000, "")
+		return it, err
+	}
+	contract := &chaincode.SmartContract{}
+	_, err := contract.RegisterCurrency(l.Context, "KRW", "Won token")
+	require.NoError(t, err)
+	for _, account := range []string{"alice", "sales", "org1-treasury", "carol"} {
+		_, err = contract.MintCurrency(l.Context, "KRW", account, 500)
+		require.NoError(t, err)
+	}
+
+	l.WithCaller("Org1MSP", "alice", "client")
+	_, err = contract.RegisterAlias(l.Context, "alice.pay", "alice")
+	require.NoError(t, err)
+	l.WithCaller("Org1MSP", "Admin@org1.example.com", chaincode.AdminOU)
+	_, err = contract.TransferCurrency(l.Context, "KRW", "alice.pay", "bob", 10)
+	require.NoError(t, err)
+	balance, err := contract.GetCurrencyBalance(l.Context, "KRW", "bob")
+	require.NoError(t, err)
+	assert.Equal(t, 10, balance)
+
+	_, err = contract.SetParentAccount(l.Context, "sales", "alice", true)
+	require.NoError(t, err)
+	_, err = contract.TransferCurrency(l.Context, "KRW", "sales", "bob", 10)
+	assert.EqualError(t, err, "[INVALID_ARGUMENT] account sales can only send within the tree of alice")
+
+	_, err = contract.SetTreasury(l.Context, "org1-treasury", 100)
+	require.NoError(t, err)
+	_, err = contract.TransferCurrency(l.Context, "KRW", "org1-treasury", "bob", 10)
+	assert.EqualError(t, err, "[INVALID_ARGUMENT] account org1-treasury is a treasury, pay from it with ProposeTreasuryPayment")
+
+	_, err = contract.SetTransferLimit(l.Context, "alice", 50, 0, chaincode.LimitPolicyReject)
+	require.NoError(t, err)
+	_, err = contract.TransferCurrency(l.Context, "KRW", "alice", "bob", 51)
+	assert.EqualError(t, err, "[LIMIT_EXCEEDED] transfer of 51 from alice exceeds its limit of 50 per transfer")
+
+	_, err = contract.SetLargeTransferThreshold(l.Context, 100)
+	require.NoError(t, err)
+	l.WithTxID("large")
+	transaction, err := contract.TransferCurrency(l.Context, "KRW", "carol", "bob", 101)
+	require.NoError(t, err)
+	l.AssertEvent(chaincode.EventLargeTransfer, map[string]interface{}{
+		"txId": "large", "currency": "KRW", "type": "transfer", "from": "carol", "to": "bob", "value": 101,
+		"txTimestamp": transaction.Timestamp, "threshold": 100, "events": []string{chaincode.EventCurrencyTransferred},
+	})
+	review, err := contract.GetLargeTransferReview(l.Context, "large")
+	require.NoError(t, err)
+	assert.Equal(t, &chaincode.LargeTransferReview{
+		TXID: "large", From: "carol", To: "bob", Value: 101, Threshold: 100, Timestamp: transaction.Timestamp, Currency: "KRW",
+	}, review)
+}
+
+func TestCurrencyRejects(t *testing.T) {
+	l := adminLedger(t).
+		WithAccount("alice", "user", 100).
+		WithAccount("bob", "user", 0)
+	contract := &chaincode.SmartContract{}
+
+	_, err := contract.MintCurrency(l.Context, "KRW", "alice", 10)
+	assert.EqualError(t, err, "[INVALID_ARGUMENT] currency KRW is not registered")
+	_, err = contract.RegisterCurrency(l.Context, "KRW", "")
+	assert.EqualError(t, err, "[INVALID_ARGUMENT] invalid name: must not be empty")
+	_, err = contract.RegisterCurrency(l.Context, "KRW", "Won token")
+	require.NoError(t, err)
+	_, err = contract.RegisterCurrency(l.Context, "KRW", "Won token")
+	assert.EqualError(t, err, "[INVALID_ARGUMENT] currency KRW is already registered")
+	_, err = contract.MintCurrency(l.Context, "KRW", "alice", 0)
+	assert.EqualError(t, err, "[INVALID_ARGUMENT] invalid amount: must be positive")
+	_, err = contract.MintCurrency(l.Context, "KRW", "carol", 10)
+	assert.EqualError(t, err, "[ACCOUNT_NOT_FOUND] user carol does not exist")
+
+	_, err = contract.TransferCurrency(l.Context, "KRW", "alice", "bob", 10)
+	assert.EqualError(t, err, "[INSUFFICIENT_BALANCE] KRW balance of alice lower than 10")
+	_, err = contract.BurnCurrency(l.Context, "KRW", "alice", 10)
+	assert.EqualError(t, err, "[INSUFFICIENT_BALANCE] KRW balance of alice lower than 10")
+	_, err = contract.TransferCurrency(l.Context, "KRW", "alice", "alice", 10)
+	assert.EqualError(t, err, "[INVALID_ARGUMENT] cannot transfer to and from same client account")
+
+	l.WithCaller("Org1MSP", "alice", "client")
+	_, err = contract.MintCurrency(l.Context, "KRW", "alice", 10)
+	assert.EqualError(t, err, "[UNAUTHORIZED] only an organization admin can mint a currency")
+}
@@ -0,0 +1,340 @@
+package chaincode
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+	"github.com/kkiu1756/my_fabric/src/chaincode-go/validation"
+)
+
+// The holder of an account lets a secondary certificate, such as a session
+// key, transfer from it with RegisterDelegate. The delegate is held to a
+// scope: at most MaxAmount per transfer, until ExpiresAt, and only to the
+// AllowedRecipients if any are listed. It transfers with DelegatedTransfer,
+// and the holder can RevokeDelegate it at any time. The account's limits
+// apply to delegated transfers as to any other.
+//
+// The scope is advisory, not an access control: TransferFrom moves tokens
+// from any account for any client, so a delegate, like anyone, can bypass
+// its scope by calling it. The scope only bounds what DelegatedTransfer
+// does and records a delegated transfer as such, for wallets that keep
+// their session keys to DelegatedTransfer.
+
+// MaxDelegateRecipients bounds the recipients a delegate can be allowed
+const MaxDelegateRecipients = 100
+
+// EventDelegateRegistered and EventDelegateRevoked are the events of a
+// delegate's registration and revocation, and EventDelegatedTransfer
+// replaces the typed event of a delegated transfer
+const (
+	EventDelegateRegistered = "DelegateRegistered"
+	EventDelegateRevoked    = "DelegateRevoked"
+	EventDelegatedTransfer  = "DelegatedTransfer"
+)
+
+// delegateObjectType keys the delegates by account and identity
+const delegateObjectType = "delegate"
+
+// Delegate is a certificate transferring on behalf of an account holder
+type Delegate struct {
+	Account  string   `json:"account"`
+	Delegate Identity `json:"delegate"`
+	// MaxAmount caps each transfer
+	MaxAmount int `json:"maxAmount"`
+	// ExpiresAt ends the delegation, an RFC 3339 time
+	ExpiresAt string `json:"expiresAt"`
+	// AllowedRecipients are the only accounts the delegate can pay, any
+	// account if empty
+	AllowedRecipients []string `json:"allowedRecipients"`
+}
+
+// delegatedTransferEvent is the payload of EventDelegatedTransfer
+type delegatedTransferEvent struct {
+	*Transaction
+	Delegate Identity `json:"delegate"`
+}
+
+// RegisterDelegate lets the certificate commonName of mspID transfer up to
+// maxAmount at a time from account until expiresAt, an RFC 3339 time, to
+// the accounts in recipientsJSON, a JSON array of account ids, or to any
+// account if it is empty. It replaces an earlier registration of the same
+// delegate. Only the holder of account can call it.
+func (s *SmartContract) RegisterDelegate(ctx contractapi.TransactionContextInterface, account string, mspID string, commonName string, maxAmount int, expiresAt string, recipientsJSON string) (*Delegate, error) {
+	errs := []error{
+		validation.ID("account", account),
+		validation.ID("mspId", mspID),
+		validation.ID("commonName", commonName),
+		validation.Amount("maxAmount", maxAmount),
+	}
+	if maxAmount == 0 {
+		errs = append(errs, &validation.Error{Field: "maxAmount", Reason: "must be positive"})
+	}
+	expires, err := time.Parse(time.RFC3339, expiresAt)
+	if err != nil {
+		errs = append(errs, &validation.Error{Field: "expiresAt", Reason: "must be an RFC 3339 time"})
+	}
+	if err := validate(errs...); err != nil {
+		return nil, err
+	}
+	recipients, err := decodeRecipients(recipientsJSON)
+	if err != nil {
+		return nil, err
+	}
+	if err := requireInitialized(ctx); err != nil {
+		return nil, err
+	}
+
+	batch := newWriteBatch(ctx)
+	if _, err := batch.getUser(account); err != nil {
+		return nil, err
+	}
+	if err := requireAccountHolder(ctx, account, "register its delegates"); err != nil {
+		return nil, err
+	}
+	now, err := delegationTime(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if !expires.After(now) {
+		return nil, validate(&validation.Error{Field: "expiresAt", Reason: "must be in the future"})
+	}
+
+	delegate := &Delegate{
+		Account:           account,
+		Delegate:          Identity{MSPID: mspID, CommonName: commonName},
+		MaxAmount:         maxAmount,
+		ExpiresAt:         expiresAt,
+		AllowedRecipients: recipients,
+	}
+	key, err := delegateKey(ctx, account, delegate.Delegate)
+	if err != nil {
+		return nil, err
+	}
+	err = batch.putState(key, delegate)
+	if err != nil {
+		return nil, err
+	}
+	err = emitEvent(ctx, EventDelegateRegistered, delegate)
+	if err != nil {
+		return nil, err
+	}
+
+	err = batch.flush()
+	if err != nil {
+		return nil, err
+	}
+
+	return delegate, nil
+}
+
+// RevokeDelegate revokes the delegation of account to the certificate
+// commonName of mspID. Only the holder of account can call it.
+func (s *SmartContract) RevokeDelegate(ctx contractapi.TransactionContextInterface, account string, mspID string, commonName string) error {
+	err := validate(
+		validation.ID("account", account),
+		validation.ID("mspId", mspID),
+		validation.ID("commonName", commonName),
+	)
+	if err != nil {
+		return err
+	}
+	if err := requireInitialized(ctx); err != nil {
+		return err
+	}
+	if err := requireAccountHolder(ctx, account, "revoke its delegates"); err != nil {
+		return err
+	}
+
+	batch := newWriteBatch(ctx)
+	identity := Identity{MSPID: mspID, CommonName: commonName}
+	key, delegate, err := accountDelegate(batch, account, identity)
+	if err != nil {
+		return err
+	}
+	batch.delState(key)
+	err = emitEvent(ctx, EventDelegateRevoked, delegate)
+	if err != nil {
+		return err
+	}
+
+	return batch.flush()
+}
+
+// GetDelegate returns the delegation of account to the certificate
+// commonName of mspID
+func (s *SmartContract) GetDelegate(ctx contractapi.TransactionContextInterface, account string, mspID string, commonName string) (*Delegate, error) {
+	err := validate(
+		validation.ID("account", account),
+		validation.ID("mspId", mspID),
+		validation.ID("commonName", commonName),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	_, delegate, err := accountDelegate(newWriteBatch(ctx), account, Identity{MSPID: mspID, CommonName: commonName})
+	return delegate, err
+}
+
+// DelegatedTransfer transfers value from account to to on behalf of its
+// holder, within the scope of the caller's delegation, which is advisory
+// as TransferFrom is open to any client
+func (s *SmartContract) DelegatedTransfer(ctx contractapi.TransactionContextInterface, account string, to string, value int) (*Transaction, error) {
+	err := validate(
+		validation.ID("account", account),
+		validation.ID("to", to),
+		validation.Amount("value", value),
+	)
+	if err != nil {
+		return nil, err
+	}
+	if err := requireInitialized(ctx); err != nil {
+		return nil, err
+	}
+	account, to, err = resolveAccounts(ctx, account, to)
+	if err != nil {
+		return nil, err
+	}
+	err = requireNoConfirmation(ctx, to, value)
+	if err != nil {
+		return nil, fmt.Errorf("failed to transfer: %w", err)
+	}
+	caller, err := callerIdentity(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	batch := newWriteBatch(ctx)
+	key, err := delegateKey(ctx, account, caller)
+	if err != nil {
+		return nil, err
+	}
+	data, err := batch.getState(key)
+	if err != nil {
+		return nil, err
+	}
+	if data == nil {
+		return nil, newError(CodeUnauthorized, "%s is not a delegate of %s", caller.CommonName, account)
+	}
+	var delegate Delegate
+	err = decodeRecord(key, data, &delegate, "account", "delegate", "maxAmount", "expiresAt")
+	if err != nil {
+		return nil, err
+	}
+	err = checkDelegateScope(ctx, &delegate, to, value)
+	if err != nil {
+		return nil, err
+	}
+
+	transaction, err := transferHelper(batch, account, to, value, false)
+	if err == nil {
+		err = emitTypedEvent(batch, EventDelegatedTransfer, delegatedTransferEvent{transaction, caller})
+	}
+	if err == nil {
+		err = batch.flush()
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to transfer: %w", err)
+	}
+
+	return transaction, nil
+}
+
+// checkDelegateScope fails if a transfer of value to to is outside the
+// scope of delegate
+func checkDelegateScope(ctx contractapi.TransactionContextInterface, delegate *Delegate, to string, value int) error {
+	expires, err := time.Parse(time.RFC3339, delegate.ExpiresAt)
+	if err != nil {
+		return corrupt("delegate of "+delegate.Account, err)
+	}
+	now, err := delegationTime(ctx)
+	if err != nil {
+		return err
+	}
+	if !now.Before(expires) {
+		return newError(CodeUnauthorized, "the delegation of %s to %s expired at %s", delegate.Account, delegate.Delegate.CommonName, delegate.ExpiresAt)
+	}
+	if value > delegate.MaxAmount {
+		return newError(CodeLimitExceeded, "transfer of %d exceeds the delegate limit of %d", value, delegate.MaxAmount)
+	}
+	if len(delegate.AllowedRecipients) == 0 {
+		return nil
+	}
+	for _, recipient := range delegate.AllowedRecipients {
+		if recipient == to {
+			return nil
+		}
+	}
+
+	return newError(CodeUnauthorized, "%s cannot pay %s on behalf of %s", delegate.Delegate.CommonName, to, delegate.Account)
+}
+
+// decodeRecipients strictly decodes and validates a RegisterDelegate
+// recipient list, "" for none
+func decodeRecipients(recipientsJSON string) ([]string, error) {
+	recipients := []string{}
+	if recipientsJSON == "" {
+		return recipients, nil
+	}
+	decoder := json.NewDecoder(bytes.NewReader([]byte(recipientsJSON)))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&recipients); err != nil {
+		return nil, validate(&validation.Error{Field: "recipients", Reason: err.Error()})
+	}
+	if len(recipients) > MaxDelegateRecipients {
+		return nil, validate(&validation.Error{Field: "recipients", Reason: fmt.Sprintf("must hold at most %d accounts", MaxDelegateRecipients)})
+	}
+	for i, recipient := range recipients {
+		if err := validate(validation.ID(fmt.Sprintf("recipients[%d]", i), recipient)); err != nil {
+			return nil, err
+		}
+	}
+
+	return recipients, nil
+}
+
+// delegationTime returns the proposal timestamp, which a delegation needs
+func delegationTime(ctx contractapi.TransactionContextInterface) (time.Time, error) {
+	timestamp, err := txTimestamp(ctx.GetStub())
+	if err != nil {
+		return time.Time{}, err
+	}
+	now, err := time.Parse(time.RFC3339Nano, timestamp)
+	if err != nil {
+		return time.Time{}, newError(CodeInvalidArgument, "a delegation needs the proposal timestamp")
+	}
+
+	return now, nil
+}
+
+// accountDelegate reads the delegation of account to identity and returns
+// its key
+func accountDelegate(batch *writeBatch, account string, identity Identity) (string, *Delegate, error) {
+	key, err := delegateKey(batch.ctx, account, identity)
+	if err != nil {
+		return "", nil, err
+	}
+	data, err := batch.getState(key)
+	if err != nil {
+		return "", nil, err
+	}
+	if data == nil {
+		return "", nil, newError(CodeInvalidArgument, "%s of %s is not a delegate of %s", identity.CommonName, identity.MSPID, account)
+	}
+
+	var delegate Delegate
+	err = decodeRecord(key, data, &delegate, "account", "delegate", "maxAmount", "expiresAt")
+	if err != nil {
+		return "", nil, err
+	}
+
+	return key, &delegate, nil
+}
+
+// delegateKey returns the key of the delegation of account to identity
+func delegateKey(ctx contractapi.TransactionContextInterface, account string, identity Identity) (string, error) {
+	return ctx.GetStub().CreateCompositeKey(delegateObjectType, []string{account, identity.MSPID, identity.CommonName})
+}
@@ -0,0 +1,67 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package chaincode_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/kkiu1756/my_fabric/src/chaincode-go/chaincode"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDelegate(t *testing.T) {
+	l := initializedLedger(t).
+		WithAccount("alice", "user", 100).
+		WithAccount("bob", "user", 0).
+		WithAccount("carol", "user", 0).
+		WithTimestamp(demurrageTime)
+	contract := &chaincode.SmartContract{}
+
+	l.WithCaller("Org1MSP", "bob", "client")
+	_, err := contract.RegisterDelegate(l.Context, "alice", "Org1MSP", "alice-phone", 50, "2021-02-01T00:00:00Z", "")
+	assert.EqualError(t, err, "[UNAUTHORIZED] only the holder of alice can register its delegates")
+
+	l.WithCaller("Org1MSP", "alice", "client")
+	_, err = contract.RegisterDelegate(l.Context, "alice", "Org1MSP", "alice-phone", 50, "2020-12-01T00:00:00Z", "")
+	assert.EqualError(t, err, "[INVALID_ARGUMENT] invalid expiresAt: must be in the future")
+	_, err = contract.RegisterDelegate(l.Context, "alice", "Org1MSP", "alice-phone", 50, "2021-02-01T00:00:00Z", `{"bob":1}`)
+	assert.Error(t, err)
+	delegate, err := contract.RegisterDelegate(l.Context, "alice", "Org1MSP", "alice-phone", 50, "2021-02-01T00:00:00Z", `["bob"]`)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"bob"}, delegate.AllowedRecipients)
+
+	l.WithCaller("Org1MSP", "alice-phone", "client").WithTxID("delegated")
+	transaction, err := contract.DelegatedTransfer(l.Context, "alice", "bob", 40)
+	require.NoError(t, err)
+	l.AssertBalance("alice", 60)
+	l.AssertBalance("bob", 40)
+	tx, err := contract.GetTransaction(l.Context, "delegated")
+	require.NoError(t, err)
+	l.AssertEvent(chaincode.EventDelegatedTransfer, map[string]interface{}{
+		"txId": "delegated", "type": "transfer", "from": "alice", "to": "bob", "value": 40,
+		"channelId": tx.ChannelID, "initiatorMspId": tx.InitiatorMSPID, "initiator": tx.Initiator,
+		"txTimestamp": transaction.Timestamp, "delegate": map[string]interface{}{"mspId": "Org1MSP", "commonName": "alice-phone"},
+	})
+
+	_, err = contract.DelegatedTransfer(l.Context, "alice", "bob", 51)
+	assert.EqualError(t, err, "[LIMIT_EXCEEDED] transfer of 51 exceeds the delegate limit of 50")
+	_, err = contract.DelegatedTransfer(l.Context, "alice", "carol", 10)
+	assert.EqualError(t, err, "[UNAUTHORIZED] alice-phone cannot pay carol on behalf of alice")
+
+	l.WithTimestamp(demurrageTime.Add(31 * 24 * time.Hour))
+	_, err = contract.DelegatedTransfer(l.Context, "alice", "bob", 10)
+	assert.EqualError(t, err, "[UNAUTHORIZED] the delegation of alice to alice-phone expired at 2021-02-01T00:00:00Z")
+
+	l.WithCaller("Org1MSP", "alice", "client")
+	require.NoError(t, contract.RevokeDelegate(l.Context, "alice", "Org1MSP", "alice-phone"))
+	_, err = contract.GetDelegate(l.Context, "alice", "Org1MSP", "alice-phone")
+	assert.EqualError(t, err, "[INVALID_ARGUMENT] alice-phone of Org1MSP is not a delegate of alice")
+
+	l.WithCaller("Org1MSP", "alice-phone", "client")
+	_, err = contract.DelegatedTransfer(l.Context, "alice", "bob", 10)
+	assert.EqualError(t, err, "[UNAUTHORIZED] alice-phone is not a delegate of alice")
+}
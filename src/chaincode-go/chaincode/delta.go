@@ -0,0 +1,218 @@
+package chaincode
+
+import (
+	"encoding/json"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+	"github.com/kkiu1756/my_fabric/src/chaincode-go/validation"
+)
+
+// Hot accounts, such as a treasury or fee collector, receive credits from
+// many concurrent transactions. A credit to an ordinary account rewrites its
+// record, so any two transactions crediting it in the same block conflict.
+// A credit to a hot account is instead written under its own delta key,
+// deltaObjectType~account~txid, and leaves the account record untouched,
+// so concurrent credits never conflict. PruneDeltas folds the deltas into
+// the balance.
+//
+// Debits from a hot account still update its record: a debit must check
+// the balance, and reading the deltas would conflict with every concurrent
+// credit. Credits therefore become spendable once they are pruned.
+const deltaObjectType = "delta"
+
+type delta struct {
+	Value int `json:"value"`
+}
+
+// creditDelta buffers a credit of value to a hot account under the current
+// transaction's delta key, adding to a credit the transaction buffered
+// before, such as a transfer's and its referral bonus
+func creditDelta(batch *writeBatch, account string, value int) error {
+	stub := batch.ctx.GetStub()
+	key, err := stub.CreateCompositeKey(deltaObjectType, []string{account, stub.GetTxID()})
+	if err != nil {
+		return err
+	}
+	if data, ok := batch.writes[key]; ok {
+		var buffered delta
+		if err := json.Unmarshal(data, &buffered); err != nil {
+			return corrupt(key, err)
+		}
+		value += buffered.Value
+	}
+
+	return batch.putState(key, delta{value})
+}
+
+// pendingDeltas returns the sum of account's unpruned deltas and their keys.
+// It reads committed state, not buffered writes.
+func pendingDeltas(ctx contractapi.TransactionContextInterface, account string) (int, []string, error) {
+	sum := 0
+	var keys []string
+	var d delta
+	err := forEachRecord(ctx, account, deltaObjectType, []string{account}, &d, []string{"value"}, func(key string) error {
+		var ok bool
+		sum, ok = addBalance(sum, d.Value)
+		if !ok {
+			return newError(CodeBalanceOverflow, "pending credits to %s overflow", account)
+		}
+		keys = append(keys, key)
+		return nil
+	})
+	if err != nil {
+		return 0, nil, err
+	}
+
+	return sum, keys, nil
+}
+
+// pruneDeltas folds account's deltas into user's balance and deletes them
+func pruneDeltas(batch *writeBatch, user *User) error {
+	sum, keys, err := pendingDeltas(batch.ctx, user.ID)
+	if err != nil {
+		return err
+	}
+	if len(keys) == 0 {
+		return nil
+	}
+
+	balance, ok := addBalance(user.Balance, sum)
+	if !ok {
+		return newError(CodeBalanceOverflow, "cannot prune %s: balance overflow", user.ID)
+	}
+	user.Balance = balance
+
+	err = batch.putUser(user)
+	if err != nil {
+		return err
+	}
+	for _, key := range keys {
+		batch.delState(key)
+	}
+
+	return nil
+}
+
+// PruneDeltas folds the pending credits of a hot account into its balance.
+// It changes no effective balance, so any client may call it; it conflicts
+// with credits endorsed concurrently, so run it periodically rather than
+// after every credit.
+func (s *SmartContract) PruneDeltas(ctx contractapi.TransactionContextInterface, account string) (*User, error) {
+	if err := validate(validation.ID("account", account)); err != nil {
+		return nil, err
+	}
+	if err := requireInitialized(ctx); err != nil {
+		return nil, err
+	}
+
+	batch := newWriteBatch(ctx)
+	user, err := batch.getUser(account)
+	if err != nil {
+		return nil, err
+	}
+
+	err = pruneDeltas(batch, user)
+	if err != nil {
+		return nil, err
+	}
+
+	err = batch.flush()
+	if err != nil {
+		return nil, err
+	}
+
+	return user, nil
+}
+
+// PendingDeltas returns the sum of the credits to account not yet folded
+// into its balance by PruneDeltas
+func (s *SmartContract) PendingDeltas(ctx contractapi.TransactionContextInterface, account string) (int, error) {
+	if err := validate(validation.ID("account", account)); err != nil {
+		return 0, err
+	}
+
+	sum, _, err := pendingDeltas(ctx, account)
+	return sum, err
+}
+
+// SetHotAccount marks or unmarks an account as hot. Unmarking prunes its
+// pending deltas first. Only an org admin can call it.
+func (s *SmartContract) SetHotAccount(ctx contractapi.TransactionContextInterface, id string, hot bool) (*User, error) {
+	if err := validate(validation.ID("id", id)); err != nil {
+		return nil, err
+	}
+	if err := requireInitialized(ctx); err != nil {
+		return nil, err
+	}
+	if _, err := requireAdmin(ctx, "mark hot accounts"); err != nil {
+		return nil, err
+	}
+
+	batch := newWriteBatch(ctx)
+	user, err := batch.getUser(id)
+	if err != nil {
+		return nil, err
+	}
+	if user.Hot == hot {
+		return user, nil
+	}
+
+	before := user.Balance
+	if !hot {
+		err = pruneDeltas(batch, user)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	user.Hot = hot
+	err = batch.putUser(user)
+	if err != nil {
+		return nil, err
+	}
+
+	detail := "hot"
+	if !hot {
+		detail = "not hot"
+	}
+	err = appendAudit(batch, id, AuditSetHot, before, user.Balance, detail)
+	if err != nil {
+		return nil, err
+	}
+
+	err = batch.flush()
+	if err != nil {
+		return nil, err
+	}
+
+	return user, nil
+}
+
+// deleteDeltas buffers the deletion of the pending deltas of an account
+// being deleted and returns their sum
+func deleteDeltas(batch *writeBatch, account string) (int, error) {
+	sum, keys, err := pendingDeltas(batch.ctx, account)
+	if err != nil {
+		return 0, err
+	}
+
+	for _, key := range keys {
+		batch.delState(key)
+	}
+
+	return sum, nil
+}
+
+// isHot reports whether the raw account record is marked hot. It decodes
+// leniently, so a corrupt record can still be deleted.
+func isHot(record []byte) bool {
+	if isProto(record) {
+		var user User
+		return decodeProto("", record, &user) == nil && user.Hot
+	}
+
+	var user struct {
+		Hot bool `json:"hot"`
+	}
+	return json.Unmarshal(record, &user) == nil && user.Hot
+}
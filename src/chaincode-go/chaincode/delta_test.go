@@ -0,0 +1,133 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package chaincode_test
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+	"github.com/hyperledger/fabric-chaincode-go/shimtest"
+	"github.com/kkiu1756/my_fabric/src/chaincode-go/chaincode"
+	"github.com/kkiu1756/my_fabric/src/chaincode-go/tokentest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// #########
+// HELPERS
+// #########
+
+func hotUserJSON(t testing.TB, id string, balance int) []byte {
+	data, err := json.Marshal(chaincode.User{ID: id, Type: "user", Balance: balance, Hot: true})
+	require.NoError(t, err)
+	return data
+}
+
+// invokeTx invokes fn as transaction txID and fails the test on an error
+// response
+func invokeTx(t *testing.T, stub *shimtest.MockStub, txID string, fn string, args ...string) []byte {
+	invocation := [][]byte{[]byte(fn)}
+	for _, arg := range args {
+		invocation = append(invocation, []byte(arg))
+	}
+
+	resp := stub.MockInvoke(txID, invocation)
+	require.Equal(t, int32(shim.OK), resp.Status, "%s: %s", fn, resp.Message)
+	return resp.Payload
+}
+
+func deltaKeys(t *testing.T, stub *shimtest.MockStub, account string) []string {
+	prefix, err := stub.CreateCompositeKey("delta", []string{account})
+	require.NoError(t, err)
+
+	var keys []string
+	for key := range stub.State {
+		if strings.HasPrefix(key, prefix) {
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
+
+// #########
+// TESTS
+// #########
+
+func TestHotAccountDeltas(t *testing.T) {
+	stub := newMockStub(t)
+	invokeTx(t, stub, "hot", "SetHotAccount", "bob", "true")
+
+	invokeTx(t, stub, "credit1", "TransferFrom", "alice", "bob", "5")
+	invokeTx(t, stub, "credit2", "TransferFrom", "alice", "bob", "7")
+	assert.Equal(t, 88, stubBalance(t, stub, "alice"))
+	assert.Equal(t, 20, stubBalance(t, stub, "bob"), "credits should not touch the hot account record")
+	assert.Len(t, deltaKeys(t, stub, "bob"), 2)
+	assert.Equal(t, "12", string(invokeTx(t, stub, "pending", "PendingDeltas", "bob")))
+
+	resp := stub.MockInvoke("debit", [][]byte{[]byte("TransferFrom"), []byte("bob"), []byte("alice"), []byte("25")})
+	assert.Contains(t, resp.Message, "[INSUFFICIENT_BALANCE]", "unpruned credits should not be spendable")
+	invokeTx(t, stub, "debit", "TransferFrom", "bob", "alice", "15")
+	assert.Equal(t, 5, stubBalance(t, stub, "bob"), "debits should update the record")
+
+	var pruned chaincode.User
+	require.NoError(t, json.Unmarshal(invokeTx(t, stub, "prune", "PruneDeltas", "bob"), &pruned))
+	assert.Equal(t, chaincode.User{ID: "bob", Type: "user", Balance: 17, Hot: true}, pruned)
+	assert.Equal(t, 17, stubBalance(t, stub, "bob"))
+	assert.Empty(t, deltaKeys(t, stub, "bob"))
+	assert.Equal(t, "0", string(invokeTx(t, stub, "pending", "PendingDeltas", "bob")))
+
+	invokeTx(t, stub, "prune-again", "PruneDeltas", "bob")
+	assert.Equal(t, 17, stubBalance(t, stub, "bob"), "pruning without deltas should change nothing")
+}
+
+func TestUnmarkHotAccountPrunes(t *testing.T) {
+	stub := newMockStub(t)
+	invokeTx(t, stub, "hot", "SetHotAccount", "bob", "true")
+	invokeTx(t, stub, "credit", "TransferFrom", "alice", "bob", "5")
+
+	invokeTx(t, stub, "cold", "SetHotAccount", "bob", "false")
+	assert.Equal(t, 25, stubBalance(t, stub, "bob"))
+	assert.Empty(t, deltaKeys(t, stub, "bob"))
+
+	invokeTx(t, stub, "credit2", "TransferFrom", "alice", "bob", "5")
+	assert.Equal(t, 30, stubBalance(t, stub, "bob"), "an ordinary account should be credited directly")
+}
+
+func TestDeleteHotAccount(t *testing.T) {
+	stub := newMockStub(t)
+	invokeTx(t, stub, "hot", "SetHotAccount", "bob", "true")
+	invokeTx(t, stub, "credit", "TransferFrom", "alice", "bob", "5")
+
+	invokeTx(t, stub, "delete", "DeleteUser", "bob")
+	assert.NotContains(t, stub.State, "bob")
+	assert.Empty(t, deltaKeys(t, stub, "bob"), "deltas should go with the account")
+}
+
+func TestPruneDeltasOverflow(t *testing.T) {
+	stub := newMockStub(t)
+	stub.MockTransactionStart("max")
+	require.NoError(t, stub.PutState("bob", hotUserJSON(t, "bob", int(^uint(0)>>1)-10)))
+	stub.MockTransactionEnd("max")
+	invokeTx(t, stub, "credit1", "TransferFrom", "alice", "bob", "6")
+	invokeTx(t, stub, "credit2", "TransferFrom", "alice", "bob", "6")
+
+	resp := stub.MockInvoke("prune", [][]byte{[]byte("PruneDeltas"), []byte("bob")})
+	assert.Equal(t, "[BALANCE_OVERFLOW] cannot prune bob: balance overflow", resp.Message)
+	assert.Len(t, deltaKeys(t, stub, "bob"), 2, "a failed prune should keep the deltas")
+}
+
+func TestSetHotAccountRequiresAdmin(t *testing.T) {
+	l := tokentest.NewLedger(t).
+		WithAccount("bob", "user", 20).
+		WithCaller("Org1MSP", "User1@org1.example.com", "client")
+
+	_, err := (&chaincode.SmartContract{}).SetHotAccount(l.Context, "bob", true)
+	assert.EqualError(t, err, "[UNAUTHORIZED] only an organization admin can mark hot accounts")
+	assert.True(t, errors.Is(err, chaincode.ErrUnauthorized))
+	assert.False(t, l.Account("bob").Hot)
+}
@@ -0,0 +1,176 @@
+package chaincode
+
+import (
+	"encoding/json"
+	"math/big"
+	"time"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+	"github.com/kkiu1756/my_fabric/src/chaincode-go/validation"
+)
+
+// With demurrage enabled by SetDemurrage, balances decay at a yearly rate
+// in basis points, as simple interest from their last change. The fee is
+// settled lazily: a transfer first burns the fees due on both accounts,
+// and GetUser and GetAccount report balances net of the fees due. A
+// settled fee is a statement line of type LineDemurrage rather than a
+// Transaction, as a Fabric transaction records one. Hot accounts and the
+// escrow account do not decay.
+
+// LineDemurrage is the type of the statement line of a settled fee
+const LineDemurrage = "demurrage"
+
+// MaxDemurrageRate is 100% a year, in basis points
+const MaxDemurrageRate = 10000
+
+// demurrageObjectType keys the time each account's balance last changed
+// while demurrage was enabled
+const demurrageObjectType = "demurrage"
+
+// secondsPerYear is the year of the yearly rate
+const secondsPerYear = 365 * 24 * 60 * 60
+
+// DemurragePolicy is the holding fee on balances
+type DemurragePolicy struct {
+	// Rate is the yearly fee in basis points
+	Rate int `json:"rate"`
+	// Since is when demurrage was enabled, an RFC 3339 time; balances that
+	// have not changed since decay from then
+	Since string `json:"since"`
+}
+
+// SetDemurrage charges a holding fee of rate basis points a year on every
+// balance from now; 0 disables it. Changing the rate applies it to the
+// fees due since each balance last changed. Only an org admin can call it.
+func (s *SmartContract) SetDemurrage(ctx contractapi.TransactionContextInterface, rate int) (*Initialization, error) {
+	if rate < 0 || rate > MaxDemurrageRate {
+		return nil, validate(&validation.Error{Field: "rate", Reason: "must be between 0 and 10000 basis points"})
+	}
+	if err := requireInitialized(ctx); err != nil {
+		return nil, err
+	}
+	if _, err := requireAdmin(ctx, "change demurrage"); err != nil {
+		return nil, err
+	}
+
+	init, err := ledgerConfig(ctx)
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case rate == 0:
+		init.Demurrage = nil
+	case init.Demurrage != nil:
+		init.Demurrage.Rate = rate
+	default:
+		timestamp, err := txTimestamp(ctx.GetStub())
+		if err != nil {
+			return nil, err
+		}
+		if _, err := time.Parse(time.RFC3339Nano, timestamp); err != nil {
+			return nil, newError(CodeInvalidArgument, "demurrage needs the proposal timestamp")
+		}
+		init.Demurrage = &DemurragePolicy{Rate: rate, Since: timestamp}
+	}
+
+	err = putState(ctx, InitializationKey, init)
+	if err != nil {
+		return nil, err
+	}
+
+	return init, nil
+}
+
+// demurrageFee returns the fee due on user at the proposal timestamp, 0 if
+// demurrage is disabled
+func demurrageFee(batch *writeBatch, user *User) (int, error) {
+	init, err := ledgerConfig(batch.ctx)
+	if err != nil || init == nil || init.Demurrage == nil || user.Hot || user.ID == EscrowAccount || user.Balance <= 0 {
+		return 0, err
+	}
+
+	since, err := time.Parse(time.RFC3339Nano, init.Demurrage.Since)
+	if err != nil {
+		return 0, corrupt(InitializationKey, err)
+	}
+	key, err := batch.ctx.GetStub().CreateCompositeKey(demurrageObjectType, []string{user.ID})
+	if err != nil {
+		return 0, err
+	}
+	data, err := batch.getState(key)
+	if err != nil {
+		return 0, err
+	}
+	if data != nil {
+		var stamp string
+		if err := json.Unmarshal(data, &stamp); err != nil {
+			return 0, corrupt(key, err)
+		}
+		changed, err := time.Parse(time.RFC3339Nano, stamp)
+		if err != nil {
+			return 0, corrupt(key, err)
+		}
+		if changed.After(since) {
+			since = changed
+		}
+	}
+
+	timestamp, err := txTimestamp(batch.ctx.GetStub())
+	if err != nil {
+		return 0, err
+	}
+	now, err := time.Parse(time.RFC3339Nano, timestamp)
+	if err != nil || !now.After(since) {
+		return 0, nil
+	}
+
+	// balance * rate * seconds / (10000 * secondsPerYear), rounded down
+	fee := big.NewInt(int64(user.Balance))
+	fee.Mul(fee, big.NewInt(int64(init.Demurrage.Rate)))
+	fee.Mul(fee, big.NewInt(int64(now.Sub(since)/time.Second)))
+	fee.Quo(fee, big.NewInt(MaxDemurrageRate*secondsPerYear))
+	if !fee.IsInt64() || fee.Int64() > int64(user.Balance) {
+		return user.Balance, nil
+	}
+
+	return int(fee.Int64()), nil
+}
+
+// settleDemurrage burns the fee due on user from its balance and indexes
+// it. The caller stores the user.
+func settleDemurrage(batch *writeBatch, user *User) error {
+	fee, err := demurrageFee(batch, user)
+	if err != nil || fee == 0 {
+		return err
+	}
+	user.Balance -= fee
+
+	return indexBalanceChange(batch, user.ID, LineDemurrage, "", -fee)
+}
+
+// recordBalanceTime buffers the current proposal timestamp as the time
+// account's balance last changed, while demurrage is enabled
+func recordBalanceTime(batch *writeBatch, account string, timestamp string) error {
+	init, err := ledgerConfig(batch.ctx)
+	if err != nil || init == nil || init.Demurrage == nil || timestamp == "" {
+		return err
+	}
+
+	key, err := batch.ctx.GetStub().CreateCompositeKey(demurrageObjectType, []string{account})
+	if err != nil {
+		return err
+	}
+	return batch.putState(key, timestamp)
+}
+
+// netOfDemurrage reduces the balance of user, read for a client, by the fee
+// due
+func netOfDemurrage(ctx contractapi.TransactionContextInterface, user *User) error {
+	fee, err := demurrageFee(newWriteBatch(ctx), user)
+	if err != nil {
+		return err
+	}
+	user.Balance -= fee
+
+	return nil
+}
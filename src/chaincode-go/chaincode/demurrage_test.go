@@ -0,0 +1,111 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package chaincode_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+	"github.com/kkiu1756/my_fabric/src/chaincode-go/chaincode"
+	"github.com/kkiu1756/my_fabric/src/chaincode-go/tokentest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// #########
+// HELPERS
+// #########
+
+var demurrageTime = time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// demurrageLedger returns an admin's ledger with alice 10000 and bob 0,
+// where balances decay by 10% a year from demurrageTime
+func demurrageLedger(t *testing.T) *tokentest.Ledger {
+	l := adminLedger(t).
+		WithAccount("alice", "user", 10000).
+		WithAccount("bob", "user", 0).
+		WithTimestamp(demurrageTime)
+	paginate(l.Stub, l.State)
+	l.Stub.GetStateByPartialCompositeKeyStub = func(objectType string, attributes []string) (shim.StateQueryIteratorInterface, error) {
+		it, _, err := l.Stub.GetStateByPartialCompositeKeyWithPagination(objectType, attributes, 1000, "")
+		return it, err
+	}
+
+	_, err := (&chaincode.SmartContract{}).SetDemurrage(l.Context, 1000)
+	require.NoError(t, err)
+	return l
+}
+
+// #########
+// TESTS
+// #########
+
+func TestDemurrage(t *testing.T) {
+	l := demurrageLedger(t)
+	contract := &chaincode.SmartContract{}
+	year := 365 * 24 * time.Hour
+
+	l.WithTimestamp(demurrageTime.Add(year))
+	writes := l.Stub.PutStateCallCount()
+	user, err := contract.GetUser(l.Context, "alice")
+	require.NoError(t, err)
+	assert.Equal(t, 9000, user.Balance, "a year at 10% should take a tenth")
+	l.AssertBalance("alice", 10000)
+	assert.Equal(t, writes, l.Stub.PutStateCallCount(), "a read should settle nothing")
+
+	l.WithTxID("pay")
+	_, err = contract.TransferFrom(l.Context, "alice", "bob", 1000)
+	require.NoError(t, err)
+	l.AssertTransaction("pay", "alice", "bob", 1000)
+	l.AssertBalance("alice", 8000)
+	l.AssertBalance("bob", 1000)
+
+	statement, err := contract.GenerateStatement(l.Context, "alice", "2021-01-01", "2022-12-31")
+	require.NoError(t, err)
+	assert.Equal(t, 10000, statement.OpeningBalance)
+	require.Len(t, statement.Lines, 2)
+	assert.Equal(t, chaincode.LineDemurrage, statement.Lines[1].Type)
+	assert.Equal(t, -1000, statement.Lines[1].Amount)
+	assert.Equal(t, 8000, statement.ClosingBalance)
+
+	l.WithTimestamp(demurrageTime.Add(year + year/2))
+	account, err := contract.GetAccount(l.Context, "bob")
+	require.NoError(t, err)
+	assert.Equal(t, 950, account.Balance, "bob's balance should decay from when it was credited")
+}
+
+func TestDemurrageExemptions(t *testing.T) {
+	l := demurrageLedger(t)
+	contract := &chaincode.SmartContract{}
+	_, err := contract.SetHotAccount(l.Context, "alice", true)
+	require.NoError(t, err)
+
+	l.WithTimestamp(demurrageTime.Add(20 * 365 * 24 * time.Hour))
+	user, err := contract.GetUser(l.Context, "alice")
+	require.NoError(t, err)
+	assert.Equal(t, 10000, user.Balance, "a hot account should not decay")
+
+	_, err = contract.SetHotAccount(l.Context, "alice", false)
+	require.NoError(t, err)
+	user, err = contract.GetUser(l.Context, "alice")
+	require.NoError(t, err)
+	assert.Equal(t, 0, user.Balance, "the fee should be capped at the balance")
+
+	_, err = contract.SetDemurrage(l.Context, 0)
+	require.NoError(t, err)
+	user, err = contract.GetUser(l.Context, "alice")
+	require.NoError(t, err)
+	assert.Equal(t, 10000, user.Balance, "disabled demurrage should charge nothing")
+}
+
+func TestSetDemurrageRejects(t *testing.T) {
+	_, err := (&chaincode.SmartContract{}).SetDemurrage(adminLedger(t).Context, 10001)
+	assert.EqualError(t, err, "[INVALID_ARGUMENT] invalid rate: must be between 0 and 10000 basis points")
+
+	l := adminLedger(t).WithCaller("Org1MSP", "User1@org1.example.com", "client")
+	_, err = (&chaincode.SmartContract{}).SetDemurrage(l.Context, 100)
+	assert.EqualError(t, err, "[UNAUTHORIZED] only an organization admin can change demurrage")
+}
@@ -0,0 +1,175 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package chaincode_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/golang/protobuf/ptypes/timestamp"
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+	"github.com/kkiu1756/my_fabric/src/chaincode-go/chaincode"
+	"github.com/stretchr/testify/assert"
+)
+
+// executions is how often each transaction is simulated. Map iteration
+// order is randomised per range statement, so more runs make a
+// nondeterministic write more likely to show up.
+const executions = 10
+
+// #########
+// HELPERS
+// #########
+
+// execution is what an endorser would sign: the write set as the peer
+// builds it (last write per key wins, deletes are writes of nil), the
+// chaincode event and the response
+type execution struct {
+	writes   map[string][]byte
+	event    string
+	response string
+}
+
+type transactionFunc func(ctx contractapi.TransactionContextInterface) (interface{}, error)
+
+// simulate runs tx against a copy of initial, as one endorser would
+func simulate(initial map[string][]byte, tx transactionFunc) execution {
+	state := make(map[string][]byte, len(initial))
+	for k, v := range initial {
+		state[k] = v
+	}
+
+	e := execution{writes: map[string][]byte{}}
+	ctx, stub := newContext(state)
+	stub.GetTxTimestampReturns(&timestamp.Timestamp{Seconds: 1600000000}, nil)
+	stub.PutStateStub = func(key string, value []byte) error {
+		state[key] = value
+		e.writes[key] = append([]byte(nil), value...)
+		return nil
+	}
+	stub.DelStateStub = func(key string) error {
+		delete(state, key)
+		e.writes[key] = nil
+		return nil
+	}
+	stub.SetEventStub = func(name string, payload []byte) error {
+		e.event = name + " " + string(payload)
+		return nil
+	}
+
+	result, err := tx(ctx)
+	if err != nil {
+		e.response = "error: " + err.Error()
+	} else {
+		response, _ := json.Marshal(result)
+		e.response = string(response)
+	}
+
+	return e
+}
+
+// diffExecutions describes how b differs from a, or returns ""
+func diffExecutions(a, b execution) string {
+	var diffs []string
+
+	keys := map[string]bool{}
+	for k := range a.writes {
+		keys[k] = true
+	}
+	for k := range b.writes {
+		keys[k] = true
+	}
+	sorted := make([]string, 0, len(keys))
+	for k := range keys {
+		sorted = append(sorted, k)
+	}
+	sort.Strings(sorted)
+
+	for _, k := range sorted {
+		va, inA := a.writes[k]
+		vb, inB := b.writes[k]
+		switch {
+		case !inA:
+			diffs = append(diffs, fmt.Sprintf("write %q only in second: %q", k, vb))
+		case !inB:
+			diffs = append(diffs, fmt.Sprintf("write %q only in first: %q", k, va))
+		case !bytes.Equal(va, vb):
+			diffs = append(diffs, fmt.Sprintf("write %q differs:\n  first:  %q\n  second: %q", k, va, vb))
+		}
+	}
+	if a.event != b.event {
+		diffs = append(diffs, fmt.Sprintf("event differs:\n  first:  %s\n  second: %s", a.event, b.event))
+	}
+	if a.response != b.response {
+		diffs = append(diffs, fmt.Sprintf("response differs:\n  first:  %s\n  second: %s", a.response, b.response))
+	}
+
+	return strings.Join(diffs, "\n")
+}
+
+// assertDeterministic simulates tx repeatedly against the same state and
+// fails if any endorsement would differ from the first
+func assertDeterministic(t *testing.T, initial map[string][]byte, tx transactionFunc) {
+	t.Helper()
+
+	first := simulate(initial, tx)
+	for i := 1; i < executions; i++ {
+		if diff := diffExecutions(first, simulate(initial, tx)); diff != "" {
+			t.Errorf("execution %d differs from the first:\n%s", i+1, diff)
+			return
+		}
+	}
+}
+
+// #########
+// TESTS
+// #########
+
+func TestDeterminism(t *testing.T) {
+	contract := &chaincode.SmartContract{}
+	tests := []struct {
+		name string
+		tx   transactionFunc
+	}{
+		{"TransferFrom", func(ctx contractapi.TransactionContextInterface) (interface{}, error) {
+			return contract.TransferFrom(ctx, "alice", "bob", 10)
+		}},
+		{"TransferFrom rejected", func(ctx contractapi.TransactionContextInterface) (interface{}, error) {
+			return contract.TransferFrom(ctx, "alice", "bob", 1000)
+		}},
+		{"CreateUser", func(ctx contractapi.TransactionContextInterface) (interface{}, error) {
+			return contract.CreateUser(ctx, "carol", "merchant", 10)
+		}},
+		{"DeleteUser", func(ctx contractapi.TransactionContextInterface) (interface{}, error) {
+			return nil, contract.DeleteUser(ctx, "bob")
+		}},
+		{"SetBalance", func(ctx contractapi.TransactionContextInterface) (interface{}, error) {
+			return contract.SetBalance(ctx, "bob", 5)
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assertDeterministic(t, ledger(t), tt.tx)
+		})
+	}
+}
+
+func TestDiffExecutions(t *testing.T) {
+	a := execution{writes: map[string][]byte{"alice": []byte("1"), "bob": nil}, event: "Transfer {}", response: "{}"}
+	assert.Empty(t, diffExecutions(a, a), "identical executions should not differ")
+
+	b := execution{writes: map[string][]byte{"alice": []byte("2"), "carol": []byte("3")}, event: "Transfer {\"value\":1}", response: "{}"}
+	diff := diffExecutions(a, b)
+	assert.Contains(t, diff, `write "alice" differs`)
+	assert.Contains(t, diff, `write "bob" only in first`)
+	assert.Contains(t, diff, `write "carol" only in second`)
+	assert.Contains(t, diff, "event differs")
+	assert.NotContains(t, diff, "response differs")
+}
@@ -0,0 +1,304 @@
+package chaincode
+
+import (
+	"fmt"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+	"github.com/kkiu1756/my_fabric/src/chaincode-go/validation"
+)
+
+// The sender of a reversible transfer can dispute it within its dispute
+// window with OpenDispute, and the recipient answer with RespondDispute.
+// A disputed transfer can no longer be reversed or claimed; its tokens
+// stay in escrow until an arbiter rules with ResolveDispute:
+//
+//   - RulingRefund returns them to the sender
+//   - RulingUphold pays them to the recipient
+//   - RulingSplit returns the refund to the sender and leaves the rest for
+//     the recipient to claim at once with ClaimTransfer
+//
+// A Fabric transaction carries one transfer record, so a split pays its two
+// shares in two transactions. The dispute, with the ruling, stays on the
+// ledger.
+
+// disputeObjectType keys the disputes by the transaction id of the
+// disputed transfer
+const disputeObjectType = "dispute"
+
+// Dispute statuses
+const (
+	DisputeOpen     = "open"
+	DisputeResolved = "resolved"
+)
+
+// Rulings
+const (
+	RulingRefund = "refund"
+	RulingSplit  = "split"
+	RulingUphold = "uphold"
+)
+
+// Dispute is a disputed reversible transfer
+type Dispute struct {
+	// TransferID is the transaction id of the TransferReversible call
+	TransferID string `json:"transferId"`
+	From       string `json:"from"`
+	To         string `json:"to"`
+	Value      int    `json:"value"`
+	Status     string `json:"status"`
+	// Reason is the sender's statement, Response the recipient's
+	Reason   string `json:"reason"`
+	Response string `json:"response,omitempty" metadata:"response,optional"`
+	// OpenedAt and ResolvedAt are proposal timestamps
+	OpenedAt string `json:"openedAt"`
+	// Ruling, Refund, the tokens returned to the sender, and the arbiter
+	// are set when the dispute is resolved
+	Ruling       string `json:"ruling,omitempty" metadata:"ruling,optional"`
+	Refund       int    `json:"refund,omitempty" metadata:"refund,optional"`
+	ResolvedAt   string `json:"resolvedAt,omitempty" metadata:"resolvedAt,optional"`
+	ArbiterMSPID string `json:"arbiterMspId,omitempty" metadata:"arbiterMspId,optional"`
+	Arbiter      string `json:"arbiter,omitempty" metadata:"arbiter,optional"`
+}
+
+// OpenDispute disputes the reversible transfer sent by transaction id,
+// stating reason. Only the holder of the sending account can call it,
+// within the dispute window.
+func (s *SmartContract) OpenDispute(ctx contractapi.TransactionContextInterface, id string, reason string) (*Dispute, error) {
+	err := validate(
+		validation.ID("id", id),
+		validation.Memo("reason", reason),
+	)
+	if err != nil {
+		return nil, err
+	}
+	if err := requireInitialized(ctx); err != nil {
+		return nil, err
+	}
+
+	batch := newWriteBatch(ctx)
+	key, reversible, err := reversibleTransfer(batch, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := requireAccountHolder(ctx, reversible.From, "dispute transfer "+id); err != nil {
+		return nil, err
+	}
+	final, err := reversible.final(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+	if final {
+		return nil, newError(CodeInvalidArgument, "transfer %s became final at %s", id, reversible.FinalAt)
+	}
+	disputeKey, dispute, err := transferDispute(batch, id)
+	if err != nil {
+		return nil, err
+	}
+	if dispute != nil {
+		return nil, newError(CodeInvalidArgument, "transfer %s is already disputed", id)
+	}
+
+	timestamp, err := txTimestamp(ctx.GetStub())
+	if err != nil {
+		return nil, err
+	}
+	dispute = &Dispute{
+		TransferID: id,
+		From:       reversible.From,
+		To:         reversible.To,
+		Value:      reversible.Value,
+		Status:     DisputeOpen,
+		Reason:     reason,
+		OpenedAt:   timestamp,
+	}
+	err = batch.putState(disputeKey, dispute)
+	if err != nil {
+		return nil, err
+	}
+
+	err = batch.flush()
+	if err != nil {
+		return nil, err
+	}
+
+	return dispute, nil
+}
+
+// RespondDispute records the recipient's response to the dispute of
+// transfer id. Only the holder of the receiving account can call it, while
+// the dispute is open.
+func (s *SmartContract) RespondDispute(ctx contractapi.TransactionContextInterface, id string, response string) (*Dispute, error) {
+	err := validate(
+		validation.ID("id", id),
+		validation.Memo("response", response),
+	)
+	if err != nil {
+		return nil, err
+	}
+	if err := requireInitialized(ctx); err != nil {
+		return nil, err
+	}
+
+	batch := newWriteBatch(ctx)
+	key, dispute, err := openDispute(batch, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := requireAccountHolder(ctx, dispute.To, "respond to the dispute of transfer "+id); err != nil {
+		return nil, err
+	}
+	dispute.Response = response
+	err = batch.putState(key, dispute)
+	if err != nil {
+		return nil, err
+	}
+
+	err = batch.flush()
+	if err != nil {
+		return nil, err
+	}
+
+	return dispute, nil
+}
+
+// ResolveDispute rules on the dispute of transfer id with one of the
+// rulings. refund is the share returned to the sender under RulingSplit,
+// between 0 and the value exclusive, and must be 0 under the others. Only
+// an arbiter can call it. It returns the transfer record of the tokens it
+// moved.
+func (s *SmartContract) ResolveDispute(ctx contractapi.TransactionContextInterface, id string, ruling string, refund int) (*Transaction, error) {
+	errs := []error{
+		validation.ID("id", id),
+		validation.Amount("refund", refund),
+	}
+	if ruling != RulingRefund && ruling != RulingSplit && ruling != RulingUphold {
+		errs = append(errs, &validation.Error{Field: "ruling", Reason: fmt.Sprintf("must be %s, %s or %s", RulingRefund, RulingSplit, RulingUphold)})
+	}
+	if err := validate(errs...); err != nil {
+		return nil, err
+	}
+	if err := requireInitialized(ctx); err != nil {
+		return nil, err
+	}
+	if !isArbiter(ctx) {
+		return nil, newError(CodeUnauthorized, "only an arbiter can resolve disputes")
+	}
+
+	batch := newWriteBatch(ctx)
+	key, dispute, err := openDispute(batch, id)
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case ruling == RulingSplit && (refund == 0 || refund >= dispute.Value):
+		return nil, validate(&validation.Error{Field: "refund", Reason: fmt.Sprintf("must be between 0 and %d exclusive for a split", dispute.Value)})
+	case ruling != RulingSplit && refund != 0:
+		return nil, validate(&validation.Error{Field: "refund", Reason: fmt.Sprintf("must be 0 for %s", ruling)})
+	case ruling == RulingRefund:
+		refund = dispute.Value
+	}
+
+	stub := ctx.GetStub()
+	timestamp, err := txTimestamp(stub)
+	if err != nil {
+		return nil, err
+	}
+	dispute.Status = DisputeResolved
+	dispute.Ruling = ruling
+	dispute.Refund = refund
+	dispute.ResolvedAt = timestamp
+	dispute.ArbiterMSPID = creatorMSPID(stub)
+	dispute.Arbiter = creatorID(stub)
+	err = batch.putState(key, dispute)
+	if err != nil {
+		return nil, err
+	}
+
+	reversibleKey, reversible, err := reversibleTransfer(batch, id)
+	if err != nil {
+		return nil, err
+	}
+	if ruling == RulingUphold {
+		return releaseEscrow(batch, reversibleKey, reversible.To, reversible.Value)
+	}
+	if ruling == RulingSplit {
+		// the recipient's share stays in escrow, final at once
+		reversible.Value -= refund
+		reversible.FinalAt = timestamp
+		err = batch.putState(reversibleKey, reversible)
+		if err != nil {
+			return nil, err
+		}
+		transaction, err := moveTokens(batch, EscrowAccount, reversible.From, refund, true)
+		if err != nil {
+			return nil, fmt.Errorf("failed to transfer: %w", err)
+		}
+		return transaction, batch.flush()
+	}
+
+	return releaseEscrow(batch, reversibleKey, reversible.From, refund)
+}
+
+// GetDispute returns the dispute of transfer id
+func (s *SmartContract) GetDispute(ctx contractapi.TransactionContextInterface, id string) (*Dispute, error) {
+	if err := validate(validation.ID("id", id)); err != nil {
+		return nil, err
+	}
+
+	_, dispute, err := transferDispute(newWriteBatch(ctx), id)
+	if err != nil {
+		return nil, err
+	}
+	if dispute == nil {
+		return nil, newError(CodeTransactionNotFound, "transfer %s is not disputed", id)
+	}
+
+	return dispute, nil
+}
+
+// transferDispute reads the dispute of transfer id, nil if there is none,
+// and returns its key
+func transferDispute(batch *writeBatch, id string) (string, *Dispute, error) {
+	key, err := batch.ctx.GetStub().CreateCompositeKey(disputeObjectType, []string{id})
+	if err != nil {
+		return "", nil, err
+	}
+	data, err := batch.getState(key)
+	if err != nil || data == nil {
+		return key, nil, err
+	}
+
+	var dispute Dispute
+	err = decodeRecord(key, data, &dispute, "transferId", "from", "to", "value", "status")
+	if err != nil {
+		return "", nil, err
+	}
+
+	return key, &dispute, nil
+}
+
+// openDispute reads the dispute of transfer id, failing unless it is open
+func openDispute(batch *writeBatch, id string) (string, *Dispute, error) {
+	key, dispute, err := transferDispute(batch, id)
+	if err != nil {
+		return "", nil, err
+	}
+	if dispute == nil || dispute.Status != DisputeOpen {
+		return "", nil, newError(CodeInvalidArgument, "transfer %s has no open dispute", id)
+	}
+
+	return key, dispute, nil
+}
+
+// requireUndisputed fails while the dispute of transfer id is open
+func requireUndisputed(batch *writeBatch, id string) error {
+	_, dispute, err := transferDispute(batch, id)
+	if err != nil {
+		return err
+	}
+	if dispute != nil && dispute.Status == DisputeOpen {
+		return newError(CodeInvalidArgument, "transfer %s is disputed, an arbiter must resolve it", id)
+	}
+
+	return nil
+}
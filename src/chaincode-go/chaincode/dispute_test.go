@@ -0,0 +1,128 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package chaincode_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/kkiu1756/my_fabric/src/chaincode-go/chaincode"
+	"github.com/kkiu1756/my_fabric/src/chaincode-go/tokentest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// #########
+// HELPERS
+// #########
+
+// disputedLedger returns reversibleLedger with alice disputing "send"
+func disputedLedger(t *testing.T) *tokentest.Ledger {
+	l := reversibleLedger(t).WithCaller("Org1MSP", "alice", "client")
+
+	_, err := (&chaincode.SmartContract{}).OpenDispute(l.Context, "send", "never delivered")
+	require.NoError(t, err)
+	return l
+}
+
+// asArbiter switches l to an arbiter's client
+func asArbiter(l *tokentest.Ledger) *tokentest.Ledger {
+	return l.WithCaller("Org1MSP", "arbiter1", "client", chaincode.ArbiterOU).WithTxID("ruling")
+}
+
+// #########
+// TESTS
+// #########
+
+func TestDispute(t *testing.T) {
+	l := disputedLedger(t)
+	contract := &chaincode.SmartContract{}
+
+	_, err := contract.ReverseTransfer(l.Context, "send")
+	assert.EqualError(t, err, "[INVALID_ARGUMENT] transfer send is disputed, an arbiter must resolve it")
+	l.WithTimestamp(reversibleTime.Add(48 * time.Hour))
+	_, err = contract.ClaimTransfer(l.Context, "send")
+	assert.EqualError(t, err, "[INVALID_ARGUMENT] transfer send is disputed, an arbiter must resolve it", "a dispute outlasts the window")
+
+	_, err = contract.RespondDispute(l.Context, "send", "it was")
+	assert.EqualError(t, err, "[UNAUTHORIZED] only the holder of bob can respond to the dispute of transfer send")
+	l.WithCaller("Org1MSP", "bob", "client")
+	dispute, err := contract.RespondDispute(l.Context, "send", "tracking number 42")
+	require.NoError(t, err)
+	assert.Equal(t, "tracking number 42", dispute.Response)
+
+	_, err = contract.ResolveDispute(l.Context, "send", chaincode.RulingUphold, 0)
+	assert.EqualError(t, err, "[UNAUTHORIZED] only an arbiter can resolve disputes")
+
+	asArbiter(l)
+	_, err = contract.ResolveDispute(l.Context, "send", chaincode.RulingUphold, 0)
+	require.NoError(t, err)
+	l.AssertTransaction("ruling", chaincode.EscrowAccount, "bob", 30)
+	l.AssertBalance("bob", 30)
+
+	dispute, err = contract.GetDispute(l.Context, "send")
+	require.NoError(t, err)
+	assert.Equal(t, chaincode.DisputeResolved, dispute.Status)
+	assert.Equal(t, chaincode.RulingUphold, dispute.Ruling)
+	assert.Equal(t, "Org1MSP", dispute.ArbiterMSPID)
+	assert.Equal(t, "never delivered", dispute.Reason)
+
+	_, err = contract.ResolveDispute(l.Context, "send", chaincode.RulingRefund, 0)
+	assert.EqualError(t, err, "[INVALID_ARGUMENT] transfer send has no open dispute")
+}
+
+func TestResolveDisputeRefund(t *testing.T) {
+	l := asArbiter(disputedLedger(t))
+
+	_, err := (&chaincode.SmartContract{}).ResolveDispute(l.Context, "send", chaincode.RulingRefund, 0)
+	require.NoError(t, err)
+	l.AssertTransaction("ruling", chaincode.EscrowAccount, "alice", 30)
+	l.AssertBalance("alice", 100)
+	l.AssertBalance(chaincode.EscrowAccount, 0)
+}
+
+func TestResolveDisputeSplit(t *testing.T) {
+	l := asArbiter(disputedLedger(t))
+	contract := &chaincode.SmartContract{}
+
+	_, err := contract.ResolveDispute(l.Context, "send", chaincode.RulingSplit, 30)
+	assert.EqualError(t, err, "[INVALID_ARGUMENT] invalid refund: must be between 0 and 30 exclusive for a split")
+	_, err = contract.ResolveDispute(l.Context, "send", chaincode.RulingSplit, 10)
+	require.NoError(t, err)
+	l.AssertTransaction("ruling", chaincode.EscrowAccount, "alice", 10)
+	l.AssertBalance("alice", 80)
+
+	reversible, err := contract.GetReversibleTransfer(l.Context, "send")
+	require.NoError(t, err)
+	assert.Equal(t, 20, reversible.Value)
+
+	l.WithCaller("Org1MSP", "bob", "client").WithTxID("claim")
+	_, err = contract.ClaimTransfer(l.Context, "send")
+	require.NoError(t, err, "the recipient's share is final at once")
+	l.AssertBalance("bob", 20)
+	l.AssertBalance(chaincode.EscrowAccount, 0)
+	l.AssertTotal(100)
+}
+
+func TestOpenDisputeRejects(t *testing.T) {
+	l := disputedLedger(t)
+	contract := &chaincode.SmartContract{}
+
+	_, err := contract.OpenDispute(l.Context, "send", "again")
+	assert.EqualError(t, err, "[INVALID_ARGUMENT] transfer send is already disputed")
+	_, err = contract.GetDispute(l.Context, "other")
+	assert.True(t, errors.Is(err, chaincode.ErrTransactionNotFound))
+
+	l = reversibleLedger(t).WithCaller("Org1MSP", "bob", "client")
+	_, err = contract.OpenDispute(l.Context, "send", "")
+	assert.EqualError(t, err, "[UNAUTHORIZED] only the holder of alice can dispute transfer send")
+	l.WithCaller("Org1MSP", "alice", "client").WithTimestamp(reversibleTime.Add(24 * time.Hour))
+	_, err = contract.OpenDispute(l.Context, "send", "")
+	assert.EqualError(t, err, "[INVALID_ARGUMENT] transfer send became final at 2021-03-02T12:00:00Z")
+
+	_, err = contract.ResolveDispute(l.Context, "send", "coinflip", 0)
+	assert.EqualError(t, err, "[INVALID_ARGUMENT] invalid ruling: must be refund, split or uphold")
+}
@@ -0,0 +1,423 @@
+package chaincode
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math/big"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+	"github.com/kkiu1756/my_fabric/src/chaincode-go/validation"
+)
+
+// An org admin takes a snapshot of the balances of listed accounts with
+// TakeSnapshot. DistributeProRata then pays an amount from a source
+// account to the snapshot's accounts in proportion to their snapshot
+// balances, DistributionChunk accounts per transaction to stay within
+// write-set limits: each call pays the next chunk and advances the
+// distribution's cursor until every account is paid. Shares are rounded
+// down and the rounding stays with the source, as does the source's own
+// share if it is in the snapshot.
+//
+// A Fabric transaction records a single Transaction, so the payments are
+// statement lines of type LineDistribution and the chunk is reported by
+// the Distributed event.
+
+// MaxSnapshotAccounts is the most accounts a snapshot can hold
+const MaxSnapshotAccounts = 5000
+
+// DistributionChunk is the most accounts one DistributeProRata call pays
+const DistributionChunk = 100
+
+// LineDistribution is the type of the statement lines of a distribution
+const LineDistribution = "distribution"
+
+// EventDistributed reports a chunk paid by DistributeProRata
+const EventDistributed = "Distributed"
+
+const (
+	snapshotObjectType     = "snapshot"
+	distributionObjectType = "distribution"
+)
+
+// SnapshotWeight is the balance of an account in a snapshot
+type SnapshotWeight struct {
+	Account string `json:"account"`
+	Weight  int    `json:"weight"`
+}
+
+// Snapshot records the balances of a list of accounts
+type Snapshot struct {
+	ID string `json:"id"`
+	// Timestamp is the proposal timestamp of the snapshot, RFC 3339
+	Timestamp   string           `json:"timestamp"`
+	TotalWeight int              `json:"totalWeight"`
+	Weights     []SnapshotWeight `json:"weights"`
+}
+
+// Distribution is the progress of paying Total from Source over a snapshot
+type Distribution struct {
+	SnapshotID string `json:"snapshotId"`
+	Source     string `json:"source"`
+	Total      int    `json:"total"`
+	// Cursor is the index in the snapshot's weights of the next account
+	// to pay
+	Cursor int `json:"cursor"`
+	// Paid is the total paid so far
+	Paid int  `json:"paid"`
+	Done bool `json:"done"`
+}
+
+// DistributedChunk is the chunk of a distribution paid by one call, the
+// payload of EventDistributed
+type DistributedChunk struct {
+	*Distribution
+	TXID string `json:"txId"`
+	// Payments holds the amount paid to each account of the chunk
+	Payments []SnapshotWeight `json:"payments"`
+}
+
+// TakeSnapshot records the current balances of the accounts in
+// accountsJSON, a JSON array of account ids, pending credits and fees due
+// included, as snapshot id. Only an org admin can call it.
+func (s *SmartContract) TakeSnapshot(ctx contractapi.TransactionContextInterface, id string, accountsJSON string) (*Snapshot, error) {
+	if err := validate(validation.ID("id", id)); err != nil {
+		return nil, err
+	}
+	accounts, err := decodeSnapshotAccounts(accountsJSON)
+	if err != nil {
+		return nil, err
+	}
+	if err := requireInitialized(ctx); err != nil {
+		return nil, err
+	}
+	if _, err := requireAdmin(ctx, "take a snapshot"); err != nil {
+		return nil, err
+	}
+
+	batch := newWriteBatch(ctx)
+	key, existing, err := snapshot(batch, id)
+	if err != nil {
+		return nil, err
+	}
+	if existing != nil {
+		return nil, newError(CodeInvalidArgument, "snapshot %s already exists", id)
+	}
+
+	timestamp, err := txTimestamp(ctx.GetStub())
+	if err != nil {
+		return nil, err
+	}
+	snap := &Snapshot{ID: id, Timestamp: timestamp, Weights: make([]SnapshotWeight, 0, len(accounts))}
+	for _, account := range accounts {
+		user, err := batch.getUser(account)
+		if err != nil {
+			return nil, err
+		}
+		err = netOfDemurrage(ctx, user)
+		if err != nil {
+			return nil, err
+		}
+		weight := user.Balance
+		if weight < 0 {
+			// drawn credit weighs nothing, see credit.go
+			weight = 0
+		}
+		if user.Hot {
+			pending, _, err := pendingDeltas(ctx, account)
+			if err != nil {
+				return nil, err
+			}
+			weight += pending
+		}
+
+		var ok bool
+		snap.TotalWeight, ok = addBalance(snap.TotalWeight, weight)
+		if !ok {
+			return nil, newError(CodeBalanceOverflow, "the balances of snapshot %s overflow", id)
+		}
+		snap.Weights = append(snap.Weights, SnapshotWeight{Account: account, Weight: weight})
+	}
+
+	err = batch.putState(key, snap)
+	if err != nil {
+		return nil, err
+	}
+
+	err = batch.flush()
+	if err != nil {
+		return nil, err
+	}
+
+	return snap, nil
+}
+
+// GetSnapshot returns snapshot id
+func (s *SmartContract) GetSnapshot(ctx contractapi.TransactionContextInterface, id string) (*Snapshot, error) {
+	if err := validate(validation.ID("id", id)); err != nil {
+		return nil, err
+	}
+
+	_, snap, err := snapshot(newWriteBatch(ctx), id)
+	if err == nil && snap == nil {
+		err = newError(CodeInvalidArgument, "no snapshot %s", id)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return snap, nil
+}
+
+// DistributeProRata pays the next chunk of the distribution of totalAmount
+// from sourceAccount to the accounts of snapshot snapshotID, starting the
+// distribution on the first call. Calls are repeated with the same
+// arguments until the returned chunk is done. Only the holder of the
+// source or an org admin can call it.
+func (s *SmartContract) DistributeProRata(ctx contractapi.TransactionContextInterface, sourceAccount string, totalAmount int, snapshotID string) (*DistributedChunk, error) {
+	errs := []error{
+		validation.ID("sourceAccount", sourceAccount),
+		validation.Amount("totalAmount", totalAmount),
+		validation.ID("snapshotId", snapshotID),
+	}
+	if totalAmount == 0 {
+		errs = append(errs, &validation.Error{Field: "totalAmount", Reason: "must be positive"})
+	}
+	if err := validate(errs...); err != nil {
+		return nil, err
+	}
+	if err := requireInitialized(ctx); err != nil {
+		return nil, err
+	}
+	if !isAccountHolder(ctx, sourceAccount) {
+		if _, err := requireAdmin(ctx, "distribute from another account"); err != nil {
+			return nil, err
+		}
+	}
+
+	batch := newWriteBatch(ctx)
+	_, snap, err := snapshot(batch, snapshotID)
+	if err == nil && snap == nil {
+		err = newError(CodeInvalidArgument, "no snapshot %s", snapshotID)
+	}
+	if err != nil {
+		return nil, err
+	}
+	key, distribution, err := distributionProgress(batch, snapshotID, sourceAccount)
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case distribution == nil:
+		distribution = &Distribution{SnapshotID: snapshotID, Source: sourceAccount, Total: totalAmount}
+	case distribution.Total != totalAmount:
+		return nil, newError(CodeInvalidArgument, "the distribution of snapshot %s from %s is of %d", snapshotID, sourceAccount, distribution.Total)
+	case distribution.Done:
+		return nil, newError(CodeInvalidArgument, "the distribution of snapshot %s from %s is done", snapshotID, sourceAccount)
+	}
+
+	source, err := batch.getUser(sourceAccount)
+	if err != nil {
+		return nil, err
+	}
+	if source.Hot {
+		return nil, newError(CodeInvalidArgument, "cannot distribute from hot account %s", sourceAccount)
+	}
+	err = checkSuspended(batch, sourceAccount)
+	if err != nil {
+		return nil, err
+	}
+	err = settleDemurrage(batch, source)
+	if err != nil {
+		return nil, err
+	}
+
+	end := distribution.Cursor + DistributionChunk
+	if end > len(snap.Weights) {
+		end = len(snap.Weights)
+	}
+	chunk := &DistributedChunk{Distribution: distribution, TXID: ctx.GetStub().GetTxID(), Payments: []SnapshotWeight{}}
+	paid := 0
+	for _, weight := range snap.Weights[distribution.Cursor:end] {
+		share := proRataShare(totalAmount, weight.Weight, snap.TotalWeight)
+		if share == 0 || weight.Account == sourceAccount {
+			continue
+		}
+		err = creditShare(batch, weight.Account, LineDistribution, sourceAccount, share)
+		if err != nil {
+			return nil, err
+		}
+		paid += share
+		chunk.Payments = append(chunk.Payments, SnapshotWeight{Account: weight.Account, Weight: share})
+	}
+
+	if source.Balance < paid {
+		return nil, newError(CodeInsufficientBalance, "user balance lower than %d", paid)
+	}
+	source.Balance -= paid
+	err = batch.putUser(source)
+	if err != nil {
+		return nil, err
+	}
+	if paid > 0 {
+		err = indexBalanceChange(batch, sourceAccount, LineDistribution, "", -paid)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	distribution.Cursor = end
+	distribution.Paid += paid
+	distribution.Done = end == len(snap.Weights)
+	err = batch.putState(key, distribution)
+	if err != nil {
+		return nil, err
+	}
+	err = emitEvent(ctx, EventDistributed, chunk)
+	if err != nil {
+		return nil, err
+	}
+
+	err = batch.flush()
+	if err != nil {
+		return nil, err
+	}
+
+	return chunk, nil
+}
+
+// GetDistribution returns the progress of the distribution of snapshot
+// snapshotID from sourceAccount
+func (s *SmartContract) GetDistribution(ctx contractapi.TransactionContextInterface, snapshotID string, sourceAccount string) (*Distribution, error) {
+	err := validate(
+		validation.ID("snapshotId", snapshotID),
+		validation.ID("sourceAccount", sourceAccount),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	_, distribution, err := distributionProgress(newWriteBatch(ctx), snapshotID, sourceAccount)
+	if err == nil && distribution == nil {
+		err = newError(CodeInvalidArgument, "no distribution of snapshot %s from %s", snapshotID, sourceAccount)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return distribution, nil
+}
+
+// proRataShare returns total * weight / totalWeight, rounded down
+func proRataShare(total int, weight int, totalWeight int) int {
+	if totalWeight == 0 {
+		return 0
+	}
+	share := big.NewInt(int64(total))
+	share.Mul(share, big.NewInt(int64(weight)))
+	share.Quo(share, big.NewInt(int64(totalWeight)))
+	return int(share.Int64())
+}
+
+// creditShare buffers the credit of share to account from source, indexed
+// as a line of lineType
+func creditShare(batch *writeBatch, account string, lineType string, source string, share int) error {
+	user, err := batch.getUser(account)
+	if err != nil {
+		return err
+	}
+
+	// a hot account's record is only read, see delta.go
+	if user.Hot {
+		err = creditDelta(batch, account, share)
+	} else {
+		err = settleDemurrage(batch, user)
+		if err != nil {
+			return err
+		}
+		var ok bool
+		user.Balance, ok = addBalance(user.Balance, share)
+		if !ok {
+			return newError(CodeBalanceOverflow, "cannot credit %s: balance overflow", account)
+		}
+		err = batch.putUser(user)
+	}
+	if err != nil {
+		return err
+	}
+
+	return indexBalanceChange(batch, account, lineType, source, share)
+}
+
+// decodeSnapshotAccounts strictly decodes and validates the accounts of a
+// snapshot
+func decodeSnapshotAccounts(accountsJSON string) ([]string, error) {
+	decoder := json.NewDecoder(bytes.NewReader([]byte(accountsJSON)))
+	decoder.DisallowUnknownFields()
+
+	var accounts []string
+	if err := decoder.Decode(&accounts); err != nil {
+		return nil, validate(&validation.Error{Field: "accounts", Reason: err.Error()})
+	}
+	if len(accounts) == 0 {
+		return nil, validate(&validation.Error{Field: "accounts", Reason: "must not be empty"})
+	}
+	if len(accounts) > MaxSnapshotAccounts {
+		return nil, validate(&validation.Error{Field: "accounts", Reason: fmt.Sprintf("must hold at most %d accounts", MaxSnapshotAccounts)})
+	}
+
+	seen := make(map[string]bool, len(accounts))
+	for i, account := range accounts {
+		field := fmt.Sprintf("accounts[%d]", i)
+		if err := validate(validation.ID(field, account)); err != nil {
+			return nil, err
+		}
+		if seen[account] {
+			return nil, validate(&validation.Error{Field: field, Reason: fmt.Sprintf("duplicate account %s", account)})
+		}
+		seen[account] = true
+	}
+
+	return accounts, nil
+}
+
+// snapshot reads snapshot id, nil if there is none, and returns its key
+func snapshot(batch *writeBatch, id string) (string, *Snapshot, error) {
+	key, err := batch.ctx.GetStub().CreateCompositeKey(snapshotObjectType, []string{id})
+	if err != nil {
+		return "", nil, err
+	}
+	data, err := batch.getState(key)
+	if err != nil || data == nil {
+		return key, nil, err
+	}
+
+	var snap Snapshot
+	err = decodeRecord(key, data, &snap, "id", "totalWeight", "weights")
+	if err != nil {
+		return "", nil, err
+	}
+
+	return key, &snap, nil
+}
+
+// distributionProgress reads the distribution of snapshot snapshotID from
+// source, nil if it has not started, and returns its key
+func distributionProgress(batch *writeBatch, snapshotID string, source string) (string, *Distribution, error) {
+	key, err := batch.ctx.GetStub().CreateCompositeKey(distributionObjectType, []string{snapshotID, source})
+	if err != nil {
+		return "", nil, err
+	}
+	data, err := batch.getState(key)
+	if err != nil || data == nil {
+		return key, nil, err
+	}
+
+	var distribution Distribution
+	err = decodeRecord(key, data, &distribution, "snapshotId", "source", "total", "cursor")
+	if err != nil {
+		return "", nil, err
+	}
+
+	return key, &distribution, nil
+}
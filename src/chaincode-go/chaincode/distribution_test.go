@@ -0,0 +1,117 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package chaincode_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/kkiu1756/my_fabric/src/chaincode-go/chaincode"
+	"github.com/kkiu1756/my_fabric/src/chaincode-go/tokentest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// #########
+// HELPERS
+// #########
+
+// holderLedger returns an admin's ledger with a treasury of 1000 and
+// holders h000 to h(n-1) with balances 1, 2, ... n, snapshotted as
+// "snap"
+func holderLedger(t *testing.T, n int) *tokentest.Ledger {
+	l := adminLedger(t).WithAccount("treasury", "treasury", 1000)
+	accounts := make([]string, n)
+	for i := range accounts {
+		accounts[i] = fmt.Sprintf("h%03d", i)
+		l.WithAccount(accounts[i], "user", i+1)
+	}
+	data, err := json.Marshal(accounts)
+	require.NoError(t, err)
+
+	_, err = (&chaincode.SmartContract{}).TakeSnapshot(l.Context, "snap", string(data))
+	require.NoError(t, err)
+	return l
+}
+
+// #########
+// TESTS
+// #########
+
+func TestDistributeProRata(t *testing.T) {
+	l := holderLedger(t, 3)
+	contract := &chaincode.SmartContract{}
+
+	snap, err := contract.GetSnapshot(l.Context, "snap")
+	require.NoError(t, err)
+	assert.Equal(t, 6, snap.TotalWeight)
+	assert.Equal(t, []chaincode.SnapshotWeight{{Account: "h000", Weight: 1}, {Account: "h001", Weight: 2}, {Account: "h002", Weight: 3}}, snap.Weights)
+
+	l.WithTxID("dist")
+	chunk, err := contract.DistributeProRata(l.Context, "treasury", 100, "snap")
+	require.NoError(t, err)
+	assert.Equal(t, &chaincode.DistributedChunk{
+		Distribution: &chaincode.Distribution{SnapshotID: "snap", Source: "treasury", Total: 100, Cursor: 3, Paid: 99, Done: true},
+		TXID:         "dist",
+		Payments:     []chaincode.SnapshotWeight{{Account: "h000", Weight: 16}, {Account: "h001", Weight: 33}, {Account: "h002", Weight: 50}},
+	}, chunk)
+	l.AssertEvent(chaincode.EventDistributed, chunk)
+	l.AssertBalance("treasury", 901)
+	l.AssertBalance("h000", 17)
+	l.AssertBalance("h002", 53)
+	l.AssertTotal(1006)
+
+	_, err = contract.DistributeProRata(l.Context, "treasury", 100, "snap")
+	assert.EqualError(t, err, "[INVALID_ARGUMENT] the distribution of snapshot snap from treasury is done")
+}
+
+func TestDistributeProRataChunks(t *testing.T) {
+	n := chaincode.DistributionChunk + 20
+	l := holderLedger(t, n)
+	contract := &chaincode.SmartContract{}
+	total := n * (n + 1) / 2
+
+	chunk, err := contract.DistributeProRata(l.Context, "treasury", total/10, "snap")
+	require.NoError(t, err)
+	assert.Equal(t, chaincode.DistributionChunk, chunk.Cursor)
+	assert.Len(t, chunk.Payments, 91, "the holders whose share rounds down to 0 should not be paid")
+	assert.False(t, chunk.Done)
+	l.AssertBalance("h119", 120)
+
+	_, err = contract.DistributeProRata(l.Context, "treasury", 10, "snap")
+	assert.EqualError(t, err, "[INVALID_ARGUMENT] the distribution of snapshot snap from treasury is of 726")
+
+	chunk, err = contract.DistributeProRata(l.Context, "treasury", total/10, "snap")
+	require.NoError(t, err)
+	assert.True(t, chunk.Done)
+	assert.Equal(t, n, chunk.Cursor)
+	l.AssertBalance("h119", 132)
+
+	distribution, err := contract.GetDistribution(l.Context, "snap", "treasury")
+	require.NoError(t, err)
+	assert.Equal(t, chunk.Distribution, distribution)
+	l.AssertBalance("treasury", 1000-distribution.Paid)
+}
+
+func TestDistributeProRataRejects(t *testing.T) {
+	l := holderLedger(t, 2)
+	contract := &chaincode.SmartContract{}
+
+	_, err := contract.TakeSnapshot(l.Context, "snap", `["h000"]`)
+	assert.EqualError(t, err, "[INVALID_ARGUMENT] snapshot snap already exists")
+	_, err = contract.TakeSnapshot(l.Context, "other", `["h000","h000"]`)
+	assert.EqualError(t, err, "[INVALID_ARGUMENT] invalid accounts[1]: duplicate account h000")
+	_, err = contract.DistributeProRata(l.Context, "treasury", 100, "none")
+	assert.EqualError(t, err, "[INVALID_ARGUMENT] no snapshot none")
+	_, err = contract.DistributeProRata(l.Context, "treasury", 5000, "snap")
+	assert.EqualError(t, err, "[INSUFFICIENT_BALANCE] user balance lower than 4999")
+
+	l.WithCaller("Org1MSP", "h000", "client")
+	_, err = contract.TakeSnapshot(l.Context, "other", `["h000"]`)
+	assert.EqualError(t, err, "[UNAUTHORIZED] only an organization admin can take a snapshot")
+	_, err = contract.DistributeProRata(l.Context, "treasury", 100, "snap")
+	assert.EqualError(t, err, "[UNAUTHORIZED] only an organization admin can distribute from another account")
+}
@@ -0,0 +1,186 @@
+package chaincode
+
+import (
+	"time"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+	"github.com/kkiu1756/my_fabric/src/chaincode-go/validation"
+)
+
+// A ledger initialized with InitializeWithEmission accrues a fixed amount
+// to a pool account every epoch from its initialization. MintEmission,
+// which anyone can call, mints what accrued over the whole epochs since
+// the last call. Its progress is kept apart from the Initialization
+// record, which every transaction reads.
+
+// emissionKey stores the EmissionState
+const emissionKey = "\x00config\x00emission\x00"
+
+// EmissionSchedule mints Amount to Pool every Epoch
+type EmissionSchedule struct {
+	Pool   string `json:"pool"`
+	Amount int    `json:"amount"`
+	// Epoch is a Go duration
+	Epoch string `json:"epoch"`
+	// Start is when the first epoch began, an RFC 3339 time
+	Start string `json:"start"`
+}
+
+// EmissionState is what MintEmission has minted
+type EmissionState struct {
+	// Epochs is the number of epochs minted
+	Epochs int `json:"epochs"`
+	// Minted is the total minted
+	Minted int `json:"minted"`
+	// MintedUntil is the end of the last epoch minted, an RFC 3339 time
+	MintedUntil string `json:"mintedUntil"`
+}
+
+// InitializeWithEmission enables the contract like Initialize, with an
+// emission of amount to pool every epoch, a Go duration such as "24h",
+// from now. The schedule cannot be changed later.
+func (s *SmartContract) InitializeWithEmission(ctx contractapi.TransactionContextInterface, pool string, amount int, epoch string) (*Initialization, error) {
+	errs := []error{validation.AccountID("pool", pool), validation.Amount("amount", amount)}
+	if amount == 0 {
+		errs = append(errs, &validation.Error{Field: "amount", Reason: "must be positive"})
+	}
+	if d, err := time.ParseDuration(epoch); err != nil || d < time.Second {
+		errs = append(errs, &validation.Error{Field: "epoch", Reason: "must be a duration of at least 1s"})
+	}
+	if err := validate(errs...); err != nil {
+		return nil, err
+	}
+
+	timestamp, err := txTimestamp(ctx.GetStub())
+	if err != nil {
+		return nil, err
+	}
+	if _, err := time.Parse(time.RFC3339Nano, timestamp); err != nil {
+		return nil, newError(CodeInvalidArgument, "an emission needs the proposal timestamp")
+	}
+
+	init, err := initialize(ctx, 0)
+	if err != nil {
+		return nil, err
+	}
+	init.Emission = &EmissionSchedule{Pool: pool, Amount: amount, Epoch: epoch, Start: timestamp}
+	err = putState(ctx, InitializationKey, init)
+	if err != nil {
+		return nil, err
+	}
+	err = putState(ctx, emissionKey, EmissionState{MintedUntil: timestamp})
+	if err != nil {
+		return nil, err
+	}
+
+	return init, nil
+}
+
+// GetEmission returns what has been minted of the emission schedule
+func (s *SmartContract) GetEmission(ctx contractapi.TransactionContextInterface) (*EmissionState, error) {
+	_, state, err := emission(ctx)
+	return state, err
+}
+
+// MintEmission mints to the pool the emission of every whole epoch since
+// the last call, and fails if none has ended. Anyone can call it.
+func (s *SmartContract) MintEmission(ctx contractapi.TransactionContextInterface) (*Transaction, error) {
+	schedule, state, err := emission(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	epoch, err := time.ParseDuration(schedule.Epoch)
+	if err != nil {
+		return nil, corrupt(InitializationKey, err)
+	}
+	until, err := time.Parse(time.RFC3339Nano, state.MintedUntil)
+	if err != nil {
+		return nil, corrupt(emissionKey, err)
+	}
+	timestamp, err := txTimestamp(ctx.GetStub())
+	if err != nil {
+		return nil, err
+	}
+	now, err := time.Parse(time.RFC3339Nano, timestamp)
+	if err != nil {
+		return nil, newError(CodeInvalidArgument, "an emission needs the proposal timestamp")
+	}
+
+	epochs := int(now.Sub(until) / epoch)
+	if epochs < 1 {
+		return nil, newError(CodeInvalidArgument, "the next emission is due at %s", until.Add(epoch).Format(time.RFC3339Nano))
+	}
+	if epochs > validation.MaxAmount/schedule.Amount {
+		return nil, newError(CodeBalanceOverflow, "cannot mint %d epochs of %d", epochs, schedule.Amount)
+	}
+	value := epochs * schedule.Amount
+
+	batch := newWriteBatch(ctx)
+	pool, err := batch.getUser(schedule.Pool)
+	if err != nil {
+		return nil, err
+	}
+	if pool.Hot {
+		err = creditDelta(batch, pool.ID, value)
+	} else {
+		err = settleDemurrage(batch, pool)
+		if err == nil {
+			var ok bool
+			if pool.Balance, ok = addBalance(pool.Balance, value); !ok {
+				return nil, newError(CodeBalanceOverflow, "cannot credit %s: balance overflow", pool.ID)
+			}
+			err = batch.putUser(pool)
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	transaction, err := recordTransaction(batch, TransactionMint, "", pool.ID, value)
+	if err != nil {
+		return nil, err
+	}
+	err = batch.putState(emissionKey, EmissionState{
+		Epochs:      state.Epochs + epochs,
+		Minted:      state.Minted + value,
+		MintedUntil: until.Add(time.Duration(epochs) * epoch).Format(time.RFC3339Nano),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	err = batch.flush()
+	if err != nil {
+		return nil, err
+	}
+
+	return transaction, nil
+}
+
+// emission reads the emission schedule and its state, failing if the
+// ledger has none
+func emission(ctx contractapi.TransactionContextInterface) (*EmissionSchedule, *EmissionState, error) {
+	if err := requireInitialized(ctx); err != nil {
+		return nil, nil, err
+	}
+	init, err := ledgerConfig(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	if init.Emission == nil {
+		return nil, nil, newError(CodeInvalidArgument, "the ledger has no emission schedule")
+	}
+
+	data, err := getState(ctx, emissionKey)
+	if err != nil {
+		return nil, nil, err
+	}
+	var state EmissionState
+	err = decodeRecord(emissionKey, data, &state, "epochs", "minted", "mintedUntil")
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return init.Emission, &state, nil
+}
@@ -0,0 +1,80 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package chaincode_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/kkiu1756/my_fabric/src/chaincode-go/chaincode"
+	"github.com/kkiu1756/my_fabric/src/chaincode-go/tokentest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// #########
+// HELPERS
+// #########
+
+var emissionTime = time.Date(2021, 6, 1, 0, 0, 0, 0, time.UTC)
+
+// emissionLedger returns an admin's ledger initialized at emissionTime to
+// mint 50 a day to the rewards account
+func emissionLedger(t *testing.T) *tokentest.Ledger {
+	l := tokentest.NewLedger(t).Uninitialized().
+		WithCaller("Org1MSP", "Admin@org1.example.com", chaincode.AdminOU).
+		WithTimestamp(emissionTime)
+
+	init, err := (&chaincode.SmartContract{}).InitializeWithEmission(l.Context, "rewards", 50, "24h")
+	require.NoError(t, err)
+	assert.Equal(t, &chaincode.EmissionSchedule{Pool: "rewards", Amount: 50, Epoch: "24h", Start: "2021-06-01T00:00:00Z"}, init.Emission)
+	return l.WithAccount("rewards", "pool", 0)
+}
+
+// #########
+// TESTS
+// #########
+
+func TestMintEmission(t *testing.T) {
+	l := emissionLedger(t)
+	contract := &chaincode.SmartContract{}
+
+	l.WithTimestamp(emissionTime.Add(23 * time.Hour))
+	_, err := contract.MintEmission(l.Context)
+	assert.EqualError(t, err, "[INVALID_ARGUMENT] the next emission is due at 2021-06-02T00:00:00Z")
+
+	l.WithTimestamp(emissionTime.Add(3*24*time.Hour+12*time.Hour)).WithTxID("emit").
+		WithCaller("Org2MSP", "User1@org2.example.com", "client")
+	_, err = contract.MintEmission(l.Context)
+	require.NoError(t, err)
+	l.AssertTransaction("emit", "", "rewards", 150)
+	l.AssertBalance("rewards", 150)
+	l.AssertEvent(chaincode.EventMinted, l.Transaction("emit"))
+
+	state, err := contract.GetEmission(l.Context)
+	require.NoError(t, err)
+	assert.Equal(t, &chaincode.EmissionState{Epochs: 3, Minted: 150, MintedUntil: "2021-06-04T00:00:00Z"}, state, "the part epoch should accrue to the next call")
+
+	l.WithTimestamp(emissionTime.Add(4 * 24 * time.Hour)).WithTxID("emit2")
+	_, err = contract.MintEmission(l.Context)
+	require.NoError(t, err)
+	l.AssertBalance("rewards", 200)
+}
+
+func TestMintEmissionRejects(t *testing.T) {
+	_, err := (&chaincode.SmartContract{}).MintEmission(adminLedger(t).Context)
+	assert.EqualError(t, err, "[INVALID_ARGUMENT] the ledger has no emission schedule")
+
+	l := tokentest.NewLedger(t).Uninitialized().WithCaller("Org1MSP", "Admin@org1.example.com", chaincode.AdminOU)
+	_, err = (&chaincode.SmartContract{}).InitializeWithEmission(l.Context, "rewards", 0, "24h")
+	assert.EqualError(t, err, "[INVALID_ARGUMENT] invalid amount: must be positive")
+	_, err = (&chaincode.SmartContract{}).InitializeWithEmission(l.Context, "rewards", 50, "1ms")
+	assert.EqualError(t, err, "[INVALID_ARGUMENT] invalid epoch: must be a duration of at least 1s")
+
+	l = emissionLedger(t).WithTimestamp(emissionTime.Add(48 * time.Hour))
+	delete(l.State, "rewards")
+	_, err = (&chaincode.SmartContract{}).MintEmission(l.Context)
+	assert.EqualError(t, err, "[ACCOUNT_NOT_FOUND] user rewards does not exist")
+}
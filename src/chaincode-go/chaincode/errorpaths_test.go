@@ -0,0 +1,160 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package chaincode_test
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+	"github.com/kkiu1756/my_fabric/src/chaincode-go/chaincode"
+	"github.com/kkiu1756/my_fabric/src/chaincode-go/chaincode/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var errInjected = errors.New("injected failure")
+
+// #########
+// HELPERS
+// #########
+
+// fault fails the call-th (zero based) stub access of kind op and records
+// every access made after it
+type fault struct {
+	op    string
+	call  int
+	calls map[string]int
+	fired bool
+	after []string
+}
+
+func (f *fault) access(op string, key string) error {
+	if f.fired {
+		f.after = append(f.after, fmt.Sprintf("%s %q", op, key))
+	}
+
+	n := f.calls[op]
+	f.calls[op]++
+	if op == f.op && n == f.call {
+		f.fired = true
+		return errInjected
+	}
+
+	return nil
+}
+
+// inject routes the stub's state and event access through f
+func inject(stub *mocks.ChaincodeStub, state map[string][]byte, f *fault) {
+	f.calls = map[string]int{}
+	stub.GetStateStub = func(key string) ([]byte, error) {
+		if err := f.access(chaincode.OpReadState, key); err != nil {
+			return nil, err
+		}
+		return state[key], nil
+	}
+	stub.PutStateStub = func(key string, value []byte) error {
+		if err := f.access(chaincode.OpWriteState, key); err != nil {
+			return err
+		}
+		state[key] = value
+		return nil
+	}
+	stub.DelStateStub = func(key string) error {
+		if err := f.access(chaincode.OpDeleteState, key); err != nil {
+			return err
+		}
+		delete(state, key)
+		return nil
+	}
+	stub.SetEventStub = func(name string, payload []byte) error {
+		return f.access(chaincode.OpSetEvent, name)
+	}
+}
+
+// run executes tx against a fresh ledger with f injected
+func run(t *testing.T, tx transactionFunc, f *fault) error {
+	state := ledger(t)
+	state["tx1"] = []byte(`{"txId":"tx1","from":"alice","to":"bob","value":1}`)
+
+	ctx, stub := newContext(state)
+	inject(stub, state, f)
+
+	_, err := tx(ctx)
+	return err
+}
+
+// #########
+// TESTS
+// #########
+
+// TestStateFailures fails every state and event access each transaction
+// makes, one at a time, and checks that the transaction stops there and
+// reports the failure as a *StateError wrapping the stub's error
+func TestStateFailures(t *testing.T) {
+	contract := &chaincode.SmartContract{}
+	tests := []struct {
+		name string
+		tx   transactionFunc
+	}{
+		{"TransferFrom", transfer("alice", "bob", 10)},
+		{"GetUser", func(ctx contractapi.TransactionContextInterface) (interface{}, error) {
+			return contract.GetUser(ctx, "alice")
+		}},
+		{"CreateUser", func(ctx contractapi.TransactionContextInterface) (interface{}, error) {
+			return contract.CreateUser(ctx, "carol", "user", 10)
+		}},
+		{"DeleteUser", func(ctx contractapi.TransactionContextInterface) (interface{}, error) {
+			return nil, contract.DeleteUser(ctx, "bob")
+		}},
+		{"UserExist", func(ctx contractapi.TransactionContextInterface) (interface{}, error) {
+			return contract.UserExist(ctx, "alice")
+		}},
+		{"GetTransaction", func(ctx contractapi.TransactionContextInterface) (interface{}, error) {
+			return contract.GetTransaction(ctx, "tx1")
+		}},
+		{"SetBalance", func(ctx contractapi.TransactionContextInterface) (interface{}, error) {
+			return contract.SetBalance(ctx, "bob", 5)
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clean := &fault{call: -1}
+			require.NoError(t, run(t, tt.tx, clean))
+			require.NotEmpty(t, clean.calls, "transaction should access state")
+
+			for op, calls := range clean.calls {
+				for call := 0; call < calls; call++ {
+					t.Run(fmt.Sprintf("%s %d", op, call), func(t *testing.T) {
+						f := &fault{op: op, call: call}
+						err := run(t, tt.tx, f)
+						require.Error(t, err)
+
+						var stateErr *chaincode.StateError
+						require.True(t, errors.As(err, &stateErr), "error should be a *StateError: %v", err)
+						assert.Equal(t, op, stateErr.Op)
+						assert.NotEmpty(t, stateErr.Key)
+						assert.True(t, errors.Is(err, errInjected), "error should wrap the stub error: %v", err)
+						assert.True(t, chaincode.Transient(err), "stub failures should be transient")
+						assert.Empty(t, f.after, "transaction should stop at the failure")
+					})
+				}
+			}
+		})
+	}
+}
+
+func TestStateError(t *testing.T) {
+	err := &chaincode.StateError{Op: chaincode.OpWriteState, Key: "alice", Err: errInjected}
+	assert.EqualError(t, err, `[STATE_UNAVAILABLE] failed to write state "alice": injected failure`)
+	assert.Equal(t, errInjected, errors.Unwrap(err))
+
+	wrapped := fmt.Errorf("failed to transfer: %w", err)
+	var stateErr *chaincode.StateError
+	require.True(t, errors.As(wrapped, &stateErr))
+	assert.Equal(t, "alice", stateErr.Key)
+}
@@ -0,0 +1,103 @@
+package chaincode
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/kkiu1756/my_fabric/src/chaincode-go/validation"
+)
+
+// Code identifies a class of contract error. Codes are part of the
+// contract's interface: clients see only the message text, so every coded
+// error message contains its code in brackets, for example
+// "[ACCOUNT_NOT_FOUND] user carol does not exist", and the REST gateway
+// maps the code to an HTTP status. Do not rename existing codes.
+type Code string
+
+// Error codes
+const (
+	CodeInvalidArgument     Code = "INVALID_ARGUMENT"
+	CodeAccountNotFound     Code = "ACCOUNT_NOT_FOUND"
+	CodeAccountExists       Code = "ACCOUNT_EXISTS"
+	CodeAccountFrozen       Code = "ACCOUNT_FROZEN"
+	CodeTransactionNotFound Code = "TRANSACTION_NOT_FOUND"
+	CodeInsufficientBalance Code = "INSUFFICIENT_BALANCE"
+	CodeBalanceOverflow     Code = "BALANCE_OVERFLOW"
+	CodeLimitExceeded       Code = "LIMIT_EXCEEDED"
+	CodeUnauthorized        Code = "UNAUTHORIZED"
+	CodeStateUnavailable    Code = "STATE_UNAVAILABLE"
+	CodeNotInitialized      Code = "NOT_INITIALIZED"
+	CodeAlreadyInitialized  Code = "ALREADY_INITIALIZED"
+	CodeCorruptRecord       Code = "CORRUPT_RECORD"
+	CodeInternal            Code = "INTERNAL"
+)
+
+// Sentinel errors, matched with errors.Is against any error of the same code
+var (
+	ErrInvalidArgument     = &Error{Code: CodeInvalidArgument, Message: "invalid argument"}
+	ErrAccountNotFound     = &Error{Code: CodeAccountNotFound, Message: "account not found"}
+	ErrAccountExists       = &Error{Code: CodeAccountExists, Message: "account already exists"}
+	ErrAccountFrozen       = &Error{Code: CodeAccountFrozen, Message: "account is frozen"}
+	ErrTransactionNotFound = &Error{Code: CodeTransactionNotFound, Message: "transaction not found"}
+	ErrInsufficientBalance = &Error{Code: CodeInsufficientBalance, Message: "insufficient balance"}
+	ErrBalanceOverflow     = &Error{Code: CodeBalanceOverflow, Message: "balance overflow"}
+	ErrLimitExceeded       = &Error{Code: CodeLimitExceeded, Message: "transfer limit exceeded"}
+	ErrUnauthorized        = &Error{Code: CodeUnauthorized, Message: "unauthorized"}
+	ErrStateUnavailable    = &Error{Code: CodeStateUnavailable, Message: "world state unavailable"}
+	ErrNotInitialized      = &Error{Code: CodeNotInitialized, Message: "contract not initialized"}
+	ErrAlreadyInitialized  = &Error{Code: CodeAlreadyInitialized, Message: "contract already initialized"}
+	ErrCorruptRecord       = &Error{Code: CodeCorruptRecord, Message: "corrupt state record"}
+	ErrInternal            = &Error{Code: CodeInternal, Message: "internal error"}
+)
+
+// Error is a contract error with a stable code
+type Error struct {
+	Code    Code
+	Message string
+	// Err is the underlying error, if any
+	Err error
+}
+
+func newError(code Code, format string, args ...interface{}) *Error {
+	return &Error{Code: code, Message: fmt.Sprintf(format, args...)}
+}
+
+// wrapError returns a coded error whose message ends with err's and which
+// unwraps to err
+func wrapError(code Code, err error, format string, args ...interface{}) *Error {
+	return &Error{Code: code, Message: fmt.Sprintf(format, args...) + ": " + err.Error(), Err: err}
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("[%s] %s", e.Code, e.Message)
+}
+
+// Unwrap returns the underlying error
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// Is reports whether target is an *Error with the same code, so every
+// error matches the sentinel of its code
+func (e *Error) Is(target error) bool {
+	t, ok := target.(*Error)
+	return ok && t.Code == e.Code
+}
+
+// Transient reports whether err is a failed world state access rather than
+// a rejection of the request. Only transient failures are worth retrying
+// unchanged; clients see them as STATE_UNAVAILABLE.
+func Transient(err error) bool {
+	return errors.Is(err, ErrStateUnavailable)
+}
+
+// validate returns the first failed argument check as an
+// INVALID_ARGUMENT error that still matches validation.ErrInvalidArgument
+func validate(errs ...error) error {
+	err := validation.First(errs...)
+	if err == nil {
+		return nil
+	}
+
+	return &Error{Code: CodeInvalidArgument, Message: err.Error(), Err: err}
+}
@@ -0,0 +1,88 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package chaincode_test
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+	"github.com/kkiu1756/my_fabric/src/chaincode-go/chaincode"
+	"github.com/kkiu1756/my_fabric/src/chaincode-go/validation"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestErrorCodes checks each failure matches its sentinel and carries the
+// code in its message, which is all the REST gateway sees
+func TestErrorCodes(t *testing.T) {
+	contract := &chaincode.SmartContract{}
+	tests := []struct {
+		name     string
+		tx       transactionFunc
+		sentinel *chaincode.Error
+	}{
+		{"invalid argument", transfer("alice", "", 1), chaincode.ErrInvalidArgument},
+		{"same account", transfer("alice", "alice", 1), chaincode.ErrInvalidArgument},
+		{"unknown account", transfer("alice", "carol", 1), chaincode.ErrAccountNotFound},
+		{"insufficient balance", transfer("bob", "alice", 21), chaincode.ErrInsufficientBalance},
+		{"existing account", func(ctx contractapi.TransactionContextInterface) (interface{}, error) {
+			return contract.CreateUser(ctx, "alice", "user", 1)
+		}, chaincode.ErrAccountExists},
+		{"unknown transaction", func(ctx contractapi.TransactionContextInterface) (interface{}, error) {
+			return contract.GetTransaction(ctx, "tx2")
+		}, chaincode.ErrTransactionNotFound},
+		{"overflow", func(ctx contractapi.TransactionContextInterface) (interface{}, error) {
+			require.NoError(t, ctx.GetStub().PutState("bob", userJSON(t, "bob", int(^uint(0)>>1))))
+			return contract.TransferFrom(ctx, "alice", "bob", 1)
+		}, chaincode.ErrBalanceOverflow},
+		{"corrupt record", func(ctx contractapi.TransactionContextInterface) (interface{}, error) {
+			require.NoError(t, ctx.GetStub().PutState("bob", []byte(`{"userId":"bob","balance":20}`)))
+			return contract.TransferFrom(ctx, "alice", "bob", 1)
+		}, chaincode.ErrCorruptRecord},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, _ := newContext(ledger(t))
+
+			_, err := tt.tx(ctx)
+			require.Error(t, err)
+			assert.True(t, errors.Is(err, tt.sentinel), "error should match %s: %v", tt.sentinel.Code, err)
+			assert.Contains(t, err.Error(), fmt.Sprintf("[%s] ", tt.sentinel.Code))
+			assert.Equal(t, 1, strings.Count(err.Error(), "["), "message should carry exactly one code: %v", err)
+			assert.False(t, chaincode.Transient(err), "rejections should not be retried")
+		})
+	}
+}
+
+func TestErrorMatching(t *testing.T) {
+	frozen := &chaincode.Error{Code: chaincode.CodeAccountFrozen, Message: "account alice is frozen"}
+	assert.EqualError(t, frozen, "[ACCOUNT_FROZEN] account alice is frozen")
+	assert.True(t, errors.Is(fmt.Errorf("failed to transfer: %w", frozen), chaincode.ErrAccountFrozen))
+	assert.False(t, errors.Is(frozen, chaincode.ErrUnauthorized))
+
+	var coded *chaincode.Error
+	require.True(t, errors.As(fmt.Errorf("failed to transfer: %w", frozen), &coded))
+	assert.Equal(t, chaincode.CodeAccountFrozen, coded.Code)
+
+	ctx, stub := newContext(ledger(t))
+	_, err := (&chaincode.SmartContract{}).GetUser(ctx, "")
+	assert.True(t, errors.Is(err, validation.ErrInvalidArgument), "validation errors should stay matchable")
+
+	stub.GetStateReturns(nil, errInjected)
+	_, err = (&chaincode.SmartContract{}).GetUser(ctx, "alice")
+	assert.True(t, errors.Is(err, chaincode.ErrStateUnavailable))
+	assert.True(t, errors.Is(err, errInjected))
+
+	parse := errors.New("asn1: syntax error")
+	unauthorized := fmt.Errorf("failed to initialize: %w", &chaincode.Error{Code: chaincode.CodeUnauthorized, Message: "cannot read client identity", Err: parse})
+	assert.True(t, errors.Is(unauthorized, chaincode.ErrUnauthorized))
+	assert.True(t, errors.Is(unauthorized, parse), "coded errors should unwrap to their cause")
+	assert.False(t, chaincode.Transient(unauthorized))
+	assert.False(t, chaincode.Transient(nil))
+}
@@ -0,0 +1,409 @@
+package chaincode
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+	"github.com/kkiu1756/my_fabric/src/chaincode-go/validation"
+)
+
+// Once an org admin sets an escheatment policy, an account whose balance
+// has not changed for the dormancy period can be flagged by anyone with
+// FlagDormantAccount. If it stays inactive through the grace period, an
+// org admin sweeps its balance to the escheatment account with
+// EscheatAccount. Activity is read from the statement index, so an
+// account with no index entries counts as inactive since before the
+// index. The account holder or an admin can clear a flag with
+// ClearDormancyFlag, and any balance change after flagging blocks the
+// sweep.
+
+// Escheatment events
+const (
+	EventDormancyFlagged = "DormancyFlagged"
+	EventDormancyCleared = "DormancyCleared"
+	// EventEscheated replaces the typed event of the sweep's transfer
+	EventEscheated = "Escheated"
+)
+
+// dormancyObjectType keys the dormancy flags by account
+const dormancyObjectType = "dormancy"
+
+// EscheatmentPolicy configures the sweeping of dormant accounts
+type EscheatmentPolicy struct {
+	// Dormancy is how long, a Go duration, an account must be inactive to
+	// be flagged, Grace how long after flagging it can be swept
+	Dormancy string `json:"dormancy"`
+	Grace    string `json:"grace"`
+	// Account receives the swept balances
+	Account string `json:"account"`
+}
+
+// DormancyFlag marks an account flagged as dormant
+type DormancyFlag struct {
+	Account string `json:"account"`
+	// LastActivity is the timestamp of the account's last balance change,
+	// empty if it has none in the statement index
+	LastActivity string `json:"lastActivity,omitempty" metadata:"lastActivity,optional"`
+	// FlaggedAt is the proposal timestamp of the flagging, SweepableAt the
+	// end of the grace period
+	FlaggedAt   string `json:"flaggedAt"`
+	SweepableAt string `json:"sweepableAt"`
+}
+
+// escheatedEvent is the payload of EventEscheated
+type escheatedEvent struct {
+	*Transaction
+	LastActivity string `json:"lastActivity,omitempty"`
+	FlaggedAt    string `json:"flaggedAt"`
+}
+
+// SetEscheatmentPolicy lets accounts inactive for dormancy be swept to
+// account grace after they are flagged, both Go durations such as "8760h".
+// An empty dormancy disables escheatment. Only an org admin can call it.
+func (s *SmartContract) SetEscheatmentPolicy(ctx contractapi.TransactionContextInterface, dormancy string, grace string, account string) (*Initialization, error) {
+	var policy *EscheatmentPolicy
+	if dormancy != "" {
+		policy = &EscheatmentPolicy{Dormancy: dormancy, Grace: grace, Account: account}
+		errs := []error{validation.ID("account", account)}
+		for field, value := range map[string]string{"dormancy": dormancy, "grace": grace} {
+			if d, err := time.ParseDuration(value); err != nil || d <= 0 {
+				errs = append(errs, &validation.Error{Field: field, Reason: "must be a positive duration such as 720h"})
+			}
+		}
+		if err := validate(errs...); err != nil {
+			return nil, err
+		}
+	} else if grace != "" || account != "" {
+		return nil, validate(&validation.Error{Field: "dormancy", Reason: "must be set with grace and account"})
+	}
+	if err := requireInitialized(ctx); err != nil {
+		return nil, err
+	}
+	if _, err := requireAdmin(ctx, "change the escheatment policy"); err != nil {
+		return nil, err
+	}
+	if policy != nil {
+		if _, err := GetUser(ctx, account); err != nil {
+			return nil, err
+		}
+	}
+
+	init, err := ledgerConfig(ctx)
+	if err != nil {
+		return nil, err
+	}
+	init.Escheatment = policy
+
+	err = putState(ctx, InitializationKey, init)
+	if err != nil {
+		return nil, err
+	}
+
+	return init, nil
+}
+
+// escheatmentPolicy returns the ledger's policy, failing if it has none
+func escheatmentPolicy(ctx contractapi.TransactionContextInterface) (*EscheatmentPolicy, time.Duration, time.Duration, error) {
+	init, err := ledgerConfig(ctx)
+	if err != nil {
+		return nil, 0, 0, err
+	}
+	if init.Escheatment == nil {
+		return nil, 0, 0, newError(CodeInvalidArgument, "escheatment is disabled, an organization admin must call SetEscheatmentPolicy")
+	}
+	dormancy, err := time.ParseDuration(init.Escheatment.Dormancy)
+	if err != nil {
+		return nil, 0, 0, corrupt(InitializationKey, err)
+	}
+	grace, err := time.ParseDuration(init.Escheatment.Grace)
+	if err != nil {
+		return nil, 0, 0, corrupt(InitializationKey, err)
+	}
+
+	return init.Escheatment, dormancy, grace, nil
+}
+
+// lastActivity returns the time of the last statement index entry of
+// account, the zero time if it has none
+func lastActivity(batch *writeBatch, account string) (time.Time, error) {
+	last := time.Time{}
+	var entry indexEntry
+	err := forEachRecord(batch.ctx, account, txIndexObjectType, []string{account}, &entry, []string{"txId", "txTimestamp"}, func(key string) error {
+		if entry.Timestamp == "" {
+			return nil
+		}
+		at, err := time.Parse(time.RFC3339Nano, entry.Timestamp)
+		if err != nil {
+			return corrupt(key, err)
+		}
+		if at.After(last) {
+			last = at
+		}
+		return nil
+	})
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	return last, nil
+}
+
+// escheatTime returns the proposal timestamp, which escheatment needs
+func escheatTime(ctx contractapi.TransactionContextInterface) (time.Time, error) {
+	timestamp, err := txTimestamp(ctx.GetStub())
+	if err != nil {
+		return time.Time{}, err
+	}
+	now, err := time.Parse(time.RFC3339Nano, timestamp)
+	if err != nil {
+		return time.Time{}, newError(CodeInvalidArgument, "escheatment needs the proposal timestamp")
+	}
+
+	return now, nil
+}
+
+// FlagDormantAccount flags account as dormant if its balance has not
+// changed for the policy's dormancy period, starting its grace period.
+// Anyone can call it.
+func (s *SmartContract) FlagDormantAccount(ctx contractapi.TransactionContextInterface, account string) (*DormancyFlag, error) {
+	if err := validate(validation.ID("account", account)); err != nil {
+		return nil, err
+	}
+	if err := requireInitialized(ctx); err != nil {
+		return nil, err
+	}
+	policy, dormancy, grace, err := escheatmentPolicy(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if account == policy.Account || account == EscrowAccount {
+		return nil, newError(CodeInvalidArgument, "account %s cannot be escheated", account)
+	}
+
+	batch := newWriteBatch(ctx)
+	user, err := batch.getUser(account)
+	if err != nil {
+		return nil, err
+	}
+	if user.Balance <= 0 {
+		return nil, newError(CodeInvalidArgument, "account %s holds nothing to escheat", account)
+	}
+	key, flag, err := dormancyFlag(batch, account)
+	if err != nil {
+		return nil, err
+	}
+	if flag != nil {
+		return nil, newError(CodeInvalidArgument, "account %s was flagged dormant at %s", account, flag.FlaggedAt)
+	}
+
+	now, err := escheatTime(ctx)
+	if err != nil {
+		return nil, err
+	}
+	last, err := lastActivity(batch, account)
+	if err != nil {
+		return nil, err
+	}
+	if !last.IsZero() && now.Before(last.Add(dormancy)) {
+		return nil, newError(CodeInvalidArgument, "account %s was active at %s, it is dormant from %s",
+			account, last.Format(time.RFC3339Nano), last.Add(dormancy).Format(time.RFC3339Nano))
+	}
+
+	flag = &DormancyFlag{
+		Account:     account,
+		FlaggedAt:   now.Format(time.RFC3339Nano),
+		SweepableAt: now.Add(grace).Format(time.RFC3339Nano),
+	}
+	if !last.IsZero() {
+		flag.LastActivity = last.Format(time.RFC3339Nano)
+	}
+	err = batch.putState(key, flag)
+	if err != nil {
+		return nil, err
+	}
+	err = appendAudit(batch, account, AuditFlagDormant, user.Balance, user.Balance, "sweepable at "+flag.SweepableAt)
+	if err != nil {
+		return nil, err
+	}
+
+	err = batch.flush()
+	if err != nil {
+		return nil, err
+	}
+	err = emitEvent(ctx, EventDormancyFlagged, flag)
+	if err != nil {
+		return nil, err
+	}
+
+	return flag, nil
+}
+
+// ClearDormancyFlag removes the dormancy flag of account. The account
+// holder or an org admin can call it.
+func (s *SmartContract) ClearDormancyFlag(ctx contractapi.TransactionContextInterface, account string) error {
+	if err := validate(validation.ID("account", account)); err != nil {
+		return err
+	}
+	if err := requireInitialized(ctx); err != nil {
+		return err
+	}
+	if !isAccountHolder(ctx, account) {
+		if _, err := requireAdmin(ctx, "clear the dormancy flag of another account"); err != nil {
+			return err
+		}
+	}
+
+	batch := newWriteBatch(ctx)
+	key, flag, err := dormancyFlag(batch, account)
+	if err != nil {
+		return err
+	}
+	if flag == nil {
+		return newError(CodeInvalidArgument, "account %s is not flagged dormant", account)
+	}
+	user, err := batch.getUser(account)
+	if err != nil {
+		return err
+	}
+	batch.delState(key)
+	err = appendAudit(batch, account, AuditClearDormant, user.Balance, user.Balance, "")
+	if err != nil {
+		return err
+	}
+
+	err = batch.flush()
+	if err != nil {
+		return err
+	}
+
+	return emitEvent(ctx, EventDormancyCleared, flag)
+}
+
+// EscheatAccount sweeps the balance of account, flagged dormant and
+// inactive since, to the escheatment account once its grace period has
+// ended. Demurrage due is settled first; the sweep pays no referral bonus
+// or cashback. Only an org admin can call it.
+func (s *SmartContract) EscheatAccount(ctx contractapi.TransactionContextInterface, account string) (*Transaction, error) {
+	if err := validate(validation.ID("account", account)); err != nil {
+		return nil, err
+	}
+	if err := requireInitialized(ctx); err != nil {
+		return nil, err
+	}
+	if _, err := requireAdmin(ctx, "escheat accounts"); err != nil {
+		return nil, err
+	}
+	policy, _, _, err := escheatmentPolicy(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if policy.Account == EscrowAccount {
+		return nil, newError(CodeInvalidArgument, "account %s only takes reversible transfers", EscrowAccount)
+	}
+
+	batch := newWriteBatch(ctx)
+	key, flag, err := dormancyFlag(batch, account)
+	if err != nil {
+		return nil, err
+	}
+	if flag == nil {
+		return nil, newError(CodeInvalidArgument, "account %s is not flagged dormant", account)
+	}
+	now, err := escheatTime(ctx)
+	if err != nil {
+		return nil, err
+	}
+	sweepableAt, err := time.Parse(time.RFC3339Nano, flag.SweepableAt)
+	if err != nil {
+		return nil, corrupt(key, err)
+	}
+	if now.Before(sweepableAt) {
+		return nil, newError(CodeInvalidArgument, "account %s can be escheated from %s", account, flag.SweepableAt)
+	}
+	flaggedAt, err := time.Parse(time.RFC3339Nano, flag.FlaggedAt)
+	if err != nil {
+		return nil, corrupt(key, err)
+	}
+	last, err := lastActivity(batch, account)
+	if err != nil {
+		return nil, err
+	}
+	if last.After(flaggedAt) {
+		return nil, newError(CodeInvalidArgument, "account %s was active at %s, after it was flagged", account, last.Format(time.RFC3339Nano))
+	}
+
+	user, err := batch.getUser(account)
+	if err != nil {
+		return nil, err
+	}
+	err = settleDemurrage(batch, user)
+	if err != nil {
+		return nil, err
+	}
+	if user.Balance <= 0 {
+		return nil, newError(CodeInvalidArgument, "account %s holds nothing to escheat", account)
+	}
+	err = batch.putUser(user)
+	if err != nil {
+		return nil, err
+	}
+	batch.delState(key)
+	transaction, err := moveTokens(batch, account, policy.Account, user.Balance, true)
+	if err != nil {
+		return nil, fmt.Errorf("failed to transfer: %w", err)
+	}
+	err = appendAudit(batch, account, AuditEscheat, user.Balance, 0, "to "+policy.Account)
+	if err != nil {
+		return nil, err
+	}
+
+	err = batch.flush()
+	if err != nil {
+		return nil, err
+	}
+	err = emitTypedEvent(batch, EventEscheated, escheatedEvent{transaction, flag.LastActivity, flag.FlaggedAt})
+	if err != nil {
+		return nil, err
+	}
+
+	return transaction, nil
+}
+
+// GetDormancyFlag returns the dormancy flag of account
+func (s *SmartContract) GetDormancyFlag(ctx contractapi.TransactionContextInterface, account string) (*DormancyFlag, error) {
+	if err := validate(validation.ID("account", account)); err != nil {
+		return nil, err
+	}
+
+	_, flag, err := dormancyFlag(newWriteBatch(ctx), account)
+	if err != nil {
+		return nil, err
+	}
+	if flag == nil {
+		return nil, newError(CodeInvalidArgument, "account %s is not flagged dormant", account)
+	}
+
+	return flag, nil
+}
+
+// dormancyFlag reads the dormancy flag of account, nil if it has none, and
+// returns its key
+func dormancyFlag(batch *writeBatch, account string) (string, *DormancyFlag, error) {
+	key, err := batch.ctx.GetStub().CreateCompositeKey(dormancyObjectType, []string{account})
+	if err != nil {
+		return "", nil, err
+	}
+	data, err := batch.getState(key)
+	if err != nil || data == nil {
+		return key, nil, err
+	}
+
+	var flag DormancyFlag
+	err = decodeRecord(key, data, &flag, "account", "flaggedAt", "sweepableAt")
+	if err != nil {
+		return "", nil, err
+	}
+
+	return key, &flag, nil
+}
@@ -0,0 +1,161 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package chaincode_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+	"github.com/kkiu1756/my_fabric/src/chaincode-go/chaincode"
+	"github.com/kkiu1756/my_fabric/src/chaincode-go/tokentest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// #########
+// HELPERS
+// #########
+
+var escheatTime = time.Date(2021, 3, 1, 12, 0, 0, 0, time.UTC)
+
+// escheatLedger returns an admin's ledger with alice 100, bob 0 and the
+// state account, where alice last paid bob at escheatTime and accounts
+// idle for 30 days are swept to state a week after they are flagged
+func escheatLedger(t *testing.T) *tokentest.Ledger {
+	l := initializedLedger(t).
+		WithAccount("alice", "user", 130).
+		WithAccount("bob", "user", 0).
+		WithAccount("state", "treasury", 0).
+		WithTimestamp(escheatTime)
+	paginate(l.Stub, l.State)
+	l.Stub.GetStateByPartialCompositeKeyStub = func(objectType string, attributes []string) (shim.StateQueryIteratorInterface, error) {
+		it, _, err := l.Stub.GetStateByPartialCompositeKeyWithPagination(objectType, attributes, 1000, "")
+		return it, err
+	}
+
+	contract := &chaincode.SmartContract{}
+	_, err := contract.SetEscheatmentPolicy(l.Context, "720h", "168h", "state")
+	require.NoError(t, err)
+	_, err = contract.TransferFrom(l.Context, "alice", "bob", 30)
+	require.NoError(t, err)
+	return l
+}
+
+// #########
+// TESTS
+// #########
+
+func TestEscheatAccount(t *testing.T) {
+	l := escheatLedger(t)
+	contract := &chaincode.SmartContract{}
+
+	l.WithTimestamp(escheatTime.Add(29 * 24 * time.Hour))
+	_, err := contract.FlagDormantAccount(l.Context, "alice")
+	assert.EqualError(t, err, "[INVALID_ARGUMENT] account alice was active at 2021-03-01T12:00:00Z, it is dormant from 2021-03-31T12:00:00Z")
+
+	l.WithTimestamp(escheatTime.Add(30 * 24 * time.Hour))
+	flag, err := contract.FlagDormantAccount(l.Context, "alice")
+	require.NoError(t, err)
+	assert.Equal(t, &chaincode.DormancyFlag{
+		Account:      "alice",
+		LastActivity: "2021-03-01T12:00:00Z",
+		FlaggedAt:    "2021-03-31T12:00:00Z",
+		SweepableAt:  "2021-04-07T12:00:00Z",
+	}, flag)
+	l.AssertEvent(chaincode.EventDormancyFlagged, flag)
+
+	_, err = contract.EscheatAccount(l.Context, "alice")
+	assert.EqualError(t, err, "[INVALID_ARGUMENT] account alice can be escheated from 2021-04-07T12:00:00Z")
+
+	l.WithTimestamp(escheatTime.Add(37 * 24 * time.Hour)).WithTxID("sweep")
+	transaction, err := contract.EscheatAccount(l.Context, "alice")
+	require.NoError(t, err)
+	l.AssertTransaction("sweep", "alice", "state", 100)
+	l.AssertBalance("alice", 0)
+	l.AssertBalance("state", 100)
+	l.AssertEvent(chaincode.EventEscheated, map[string]interface{}{
+		"txId": "sweep", "type": "transfer", "from": "alice", "to": "state", "value": 100,
+		"txTimestamp": transaction.Timestamp, "channelId": "", "initiatorMspId": "Org1MSP", "initiator": transaction.Initiator,
+		"lastActivity": "2021-03-01T12:00:00Z", "flaggedAt": "2021-03-31T12:00:00Z",
+	})
+
+	trail, err := contract.GetAuditTrail(l.Context, "alice", 0, "")
+	require.NoError(t, err)
+	assert.Equal(t, chaincode.AuditEscheat, trail.Records[len(trail.Records)-1].Action)
+	_, err = contract.GetDormancyFlag(l.Context, "alice")
+	assert.EqualError(t, err, "[INVALID_ARGUMENT] account alice is not flagged dormant")
+}
+
+func TestEscheatAccountSettlesDemurrage(t *testing.T) {
+	l := escheatLedger(t)
+	contract := &chaincode.SmartContract{}
+	_, err := contract.SetDemurrage(l.Context, 1000)
+	require.NoError(t, err)
+	l.WithTimestamp(escheatTime.Add(30 * 24 * time.Hour))
+	_, err = contract.FlagDormantAccount(l.Context, "alice")
+	require.NoError(t, err)
+
+	l.WithTimestamp(escheatTime.Add(37 * 24 * time.Hour)).WithTxID("sweep")
+	_, err = contract.EscheatAccount(l.Context, "alice")
+	require.NoError(t, err)
+	l.AssertTransaction("sweep", "alice", "state", 99)
+	l.AssertBalance("alice", 0)
+	l.AssertBalance("state", 99)
+}
+
+func TestEscheatAccountAfterActivity(t *testing.T) {
+	l := escheatLedger(t)
+	contract := &chaincode.SmartContract{}
+	l.WithTimestamp(escheatTime.Add(30 * 24 * time.Hour))
+	_, err := contract.FlagDormantAccount(l.Context, "alice")
+	require.NoError(t, err)
+
+	l.WithTimestamp(escheatTime.Add(31 * 24 * time.Hour))
+	_, err = contract.TransferFrom(l.Context, "bob", "alice", 5)
+	require.NoError(t, err)
+
+	l.WithTimestamp(escheatTime.Add(40 * 24 * time.Hour))
+	_, err = contract.EscheatAccount(l.Context, "alice")
+	assert.EqualError(t, err, "[INVALID_ARGUMENT] account alice was active at 2021-04-01T12:00:00Z, after it was flagged")
+	l.AssertBalance("alice", 105)
+}
+
+func TestClearDormancyFlag(t *testing.T) {
+	l := escheatLedger(t)
+	contract := &chaincode.SmartContract{}
+	l.WithTimestamp(escheatTime.Add(30 * 24 * time.Hour))
+	flag, err := contract.FlagDormantAccount(l.Context, "alice")
+	require.NoError(t, err)
+
+	l.WithCaller("Org1MSP", "bob", "client")
+	err = contract.ClearDormancyFlag(l.Context, "alice")
+	assert.EqualError(t, err, "[UNAUTHORIZED] only an organization admin can clear the dormancy flag of another account")
+
+	l.WithCaller("Org1MSP", "alice", "client")
+	require.NoError(t, contract.ClearDormancyFlag(l.Context, "alice"))
+	l.AssertEvent(chaincode.EventDormancyCleared, flag)
+	_, err = contract.GetDormancyFlag(l.Context, "alice")
+	assert.EqualError(t, err, "[INVALID_ARGUMENT] account alice is not flagged dormant")
+}
+
+func TestFlagDormantAccountRejects(t *testing.T) {
+	l := escheatLedger(t).WithTimestamp(escheatTime.Add(60 * 24 * time.Hour))
+	contract := &chaincode.SmartContract{}
+
+	_, err := contract.FlagDormantAccount(l.Context, "state")
+	assert.EqualError(t, err, "[INVALID_ARGUMENT] account state cannot be escheated")
+	_, err = contract.FlagDormantAccount(l.Context, "bob")
+	require.NoError(t, err, "bob was credited at escheatTime and is dormant too")
+	_, err = contract.FlagDormantAccount(l.Context, "bob")
+	assert.EqualError(t, err, "[INVALID_ARGUMENT] account bob was flagged dormant at 2021-04-30T12:00:00Z")
+
+	_, err = contract.SetEscheatmentPolicy(l.Context, "", "", "")
+	require.NoError(t, err)
+	_, err = contract.FlagDormantAccount(l.Context, "alice")
+	assert.EqualError(t, err, "[INVALID_ARGUMENT] escheatment is disabled, an organization admin must call SetEscheatmentPolicy")
+	_, err = contract.SetEscheatmentPolicy(l.Context, "720h", "soon", "state")
+	assert.EqualError(t, err, "[INVALID_ARGUMENT] invalid grace: must be a positive duration such as 720h")
+}
@@ -0,0 +1,222 @@
+package chaincode
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+	"github.com/kkiu1756/my_fabric/src/chaincode-go/validation"
+)
+
+// Every recorded Transaction emits a typed event named after its type, with
+// the Transaction as payload. Contracts before typed events only emitted
+// the legacy Transfer event, {from, to, value}, from TransferFrom.
+//
+// A ledger initialized before typed events keeps emitting the legacy event
+// until PostUpgrade opens a transition window. Inside the window both
+// kinds are emitted; as a Fabric transaction carries a single event, a
+// transfer's legacy event then carries the typed payload too, so existing
+// listeners keep working while new ones move to the typed events. After
+// the window only typed events are emitted.
+//
+// Some methods replace the typed event of their transaction with one of
+// their own, such as CreditDrawn. The legacy Transfer event, the
+// LargeTransfer event of compliance.go and the first such event are held
+// instead: a later one, such as the Cashback of the same transfer, joins
+// them, adding the fields they lack and its name to their events.
+const (
+	EventTransferred  = "Transferred"
+	EventMinted       = "Minted"
+	EventBurned       = "Burned"
+	EventBootstrapped = "Bootstrapped"
+	// EventTransfer is the legacy event
+	EventTransfer = "Transfer"
+)
+
+// DefaultLegacyEventWindow is how long after PostUpgrade the legacy event
+// is still emitted
+const DefaultLegacyEventWindow = 30 * 24 * time.Hour
+
+var typedEvents = map[TransactionType]string{
+	TransactionTransfer:  EventTransferred,
+	TransactionMint:      EventMinted,
+	TransactionBurn:      EventBurned,
+	TransactionBootstrap: EventBootstrapped,
+}
+
+// transitionEvent is the legacy Transfer event emitted inside the window:
+// the typed payload, which has the legacy from, to and value fields, and
+// the name of the typed event it stands for
+type transitionEvent struct {
+	*Transaction
+	Event string `json:"event"`
+}
+
+// heldEvent is an event typed events join: its name, its payload's fields
+// and the names of the typed events that joined it
+type heldEvent struct {
+	name   string
+	fields map[string]json.RawMessage
+	events []string
+}
+
+// emitTransactionEvent emits the events of a transaction recorded in batch
+// for the ledger's transition state, holding a legacy Transfer event
+func emitTransactionEvent(batch *writeBatch, transaction *Transaction) error {
+	batch.held = nil
+	init, err := ledgerConfig(batch.ctx)
+	if err != nil || init == nil {
+		return err
+	}
+
+	name := typedEvents[transaction.Type]
+	legacy := legacyEvents(init)
+	window := inLegacyEventWindow(init, transaction.Timestamp)
+
+	switch {
+	case transaction.Type != TransactionTransfer && legacy:
+		return nil
+	case transaction.Type != TransactionTransfer:
+		return emitEvent(batch.ctx, name, transaction)
+	case legacy:
+		return holdEvent(batch, EventTransfer, event{transaction.From, transaction.To, transaction.Value})
+	case window:
+		return holdEvent(batch, EventTransfer, transitionEvent{transaction, name})
+	default:
+		return emitEvent(batch.ctx, name, transaction)
+	}
+}
+
+// legacyEvents reports whether the ledger init describes emits legacy
+// events only, as it was initialized before typed events and PostUpgrade
+// has not opened the transition window
+func legacyEvents(init *Initialization) bool {
+	return init.Version == "" && init.LegacyEventsUntil == ""
+}
+
+// holdEvent emits name with payload and holds it in batch, so the typed
+// events emitted later for the transaction join it
+func holdEvent(batch *writeBatch, name string, payload interface{}) error {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to obtain JSON encoding of %s event: %w", name, err)
+	}
+	held := &heldEvent{name: name}
+	err = json.Unmarshal(data, &held.fields)
+	if err != nil {
+		return err
+	}
+	batch.held = held
+
+	return emitEvent(batch.ctx, name, payload)
+}
+
+// emitTypedEvent emits and holds the typed event name of a transaction
+// recorded in batch. If batch holds an event it stays the event, gaining
+// the fields of payload it lacks and name in its events; at
+// VerbosityMinimal it is left as it is.
+func emitTypedEvent(batch *writeBatch, name string, payload interface{}) error {
+	if batch.held == nil {
+		return holdEvent(batch, name, payload)
+	}
+	verbosity, err := eventVerbosity(batch.ctx)
+	if err != nil || verbosity == VerbosityMinimal {
+		return err
+	}
+
+	return joinHeldEvent(batch, name, payload)
+}
+
+// joinHeldEvent adds the fields of payload the event held in batch lacks
+// to it, and name to its events, and emits it again
+func joinHeldEvent(batch *writeBatch, name string, payload interface{}) error {
+	held := batch.held
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to obtain JSON encoding of %s event: %w", name, err)
+	}
+	var fields map[string]json.RawMessage
+	err = json.Unmarshal(data, &fields)
+	if err != nil {
+		return err
+	}
+	for field, value := range fields {
+		if _, ok := held.fields[field]; !ok {
+			held.fields[field] = value
+		}
+	}
+	held.events = append(held.events, name)
+	held.fields["events"], err = json.Marshal(held.events)
+	if err != nil {
+		return err
+	}
+
+	return emitEvent(batch.ctx, held.name, held.fields)
+}
+
+// inLegacyEventWindow reports whether timestamp, an RFC 3339 time, is
+// inside the ledger's transition window. A transaction without a timestamp
+// is inside any window.
+func inLegacyEventWindow(init *Initialization, timestamp string) bool {
+	if init.LegacyEventsUntil == "" {
+		return false
+	}
+	now, err := time.Parse(time.RFC3339Nano, timestamp)
+	if err != nil {
+		return true
+	}
+	until, err := time.Parse(time.RFC3339Nano, init.LegacyEventsUntil)
+	return err != nil || now.Before(until)
+}
+
+// SetLegacyEventWindow moves the end of the window in which the legacy
+// Transfer event is still emitted to until, an RFC 3339 time; empty ends
+// it now. Only an org admin can call it.
+func (s *SmartContract) SetLegacyEventWindow(ctx contractapi.TransactionContextInterface, until string) (*Initialization, error) {
+	if until != "" {
+		t, err := time.Parse(time.RFC3339Nano, until)
+		if err != nil {
+			return nil, validate(&validation.Error{Field: "until", Reason: "must be an RFC 3339 time"})
+		}
+		until = t.UTC().Format(time.RFC3339Nano)
+	}
+	if err := requireInitialized(ctx); err != nil {
+		return nil, err
+	}
+	if _, err := requireAdmin(ctx, "change the legacy event window"); err != nil {
+		return nil, err
+	}
+
+	init, err := ledgerConfig(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if init.Version == "" {
+		return nil, newError(CodeInvalidArgument, "ledger emits legacy events until PostUpgrade")
+	}
+	init.LegacyEventsUntil = until
+
+	err = putState(ctx, InitializationKey, init)
+	if err != nil {
+		return nil, err
+	}
+
+	return init, nil
+}
+
+// openLegacyEventWindow starts the transition window of a ledger upgraded
+// from a contract without typed events
+func openLegacyEventWindow(ctx contractapi.TransactionContextInterface, init *Initialization) error {
+	now, err := txTimestamp(ctx.GetStub())
+	if err != nil {
+		return err
+	}
+	t, err := time.Parse(time.RFC3339Nano, now)
+	if err != nil {
+		return fmt.Errorf("no transaction timestamp to start the legacy event window at")
+	}
+
+	init.LegacyEventsUntil = t.Add(DefaultLegacyEventWindow).Format(time.RFC3339Nano)
+	return nil
+}
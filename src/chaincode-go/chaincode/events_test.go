@@ -0,0 +1,111 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package chaincode_test
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/kkiu1756/my_fabric/src/chaincode-go/chaincode"
+	"github.com/kkiu1756/my_fabric/src/chaincode-go/tokentest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// #########
+// HELPERS
+// #########
+
+// upgradedLedger returns a ledger upgraded from before typed events at
+// upgradeTime, inside the legacy event window
+func upgradedLedger(t *testing.T) *tokentest.Ledger {
+	l := upgradableLedger(t)
+	_, err := (&chaincode.SmartContract{}).PostUpgrade(l.Context)
+	require.NoError(t, err)
+	return l
+}
+
+// #########
+// TESTS
+// #########
+
+func TestLegacyEvents(t *testing.T) {
+	l := adminLedger(t).WithAccount("alice", "user", 100).WithAccount("bob", "user", 20)
+	contract := &chaincode.SmartContract{}
+
+	_, err := contract.TransferFrom(l.Context, "alice", "bob", 30)
+	require.NoError(t, err)
+	l.AssertEvent(chaincode.EventTransfer, map[string]interface{}{"from": "alice", "to": "bob", "value": 30})
+
+	l = adminLedger(t)
+	_, err = contract.CreateUser(l.Context, "carol", "user", 5)
+	require.NoError(t, err)
+	l.AssertNoEvent()
+}
+
+func TestTransitionEvents(t *testing.T) {
+	l := upgradedLedger(t).WithTimestamp(upgradeTime.Add(time.Hour)).WithTxID("pay")
+	contract := &chaincode.SmartContract{}
+
+	_, err := contract.TransferFrom(l.Context, "alice", "bob", 30)
+	require.NoError(t, err)
+	transfer := l.Transaction("pay")
+	l.AssertEvent(chaincode.EventTransfer, map[string]interface{}{
+		"txId": "pay", "type": "transfer", "from": "alice", "to": "bob", "value": 30,
+		"txTimestamp": transfer.Timestamp, "channelId": "", "initiatorMspId": "Org1MSP", "initiator": transfer.Initiator,
+		"event": chaincode.EventTransferred,
+	})
+
+	l.WithTxID("mint")
+	_, err = contract.CreateUser(l.Context, "carol", "user", 5)
+	require.NoError(t, err)
+	l.AssertEvent(chaincode.EventMinted, l.Transaction("mint"))
+
+	l.WithTimestamp(upgradeTime.Add(chaincode.DefaultLegacyEventWindow)).WithTxID("late")
+	_, err = contract.TransferFrom(l.Context, "alice", "bob", 1)
+	require.NoError(t, err)
+	l.AssertEvent(chaincode.EventTransferred, l.Transaction("late"))
+}
+
+func TestTypedEvents(t *testing.T) {
+	l := adminLedger(t).Uninitialized()
+	contract := &chaincode.SmartContract{}
+	_, err := contract.Initialize(l.Context)
+	require.NoError(t, err)
+
+	_, err = contract.CreateUser(l.Context, "alice", "user", 100)
+	require.NoError(t, err)
+	l.AssertEvent(chaincode.EventMinted, l.Transaction(tokentest.DefaultTxID))
+
+	_, err = contract.SetBalance(l.Context, "alice", 40)
+	require.NoError(t, err)
+	l.AssertEvent(chaincode.EventBurned, l.Transaction(tokentest.DefaultTxID))
+}
+
+func TestSetLegacyEventWindow(t *testing.T) {
+	contract := &chaincode.SmartContract{}
+
+	_, err := contract.SetLegacyEventWindow(adminLedger(t).Context, "")
+	assert.EqualError(t, err, "[INVALID_ARGUMENT] ledger emits legacy events until PostUpgrade")
+	_, err = contract.SetLegacyEventWindow(adminLedger(t).Context, "next week")
+	assert.EqualError(t, err, "[INVALID_ARGUMENT] invalid until: must be an RFC 3339 time")
+	_, err = contract.SetLegacyEventWindow(tokentest.NewLedger(t).WithCaller("Org1MSP", "User1@org1.example.com", "client").Context, "")
+	assert.EqualError(t, err, "[UNAUTHORIZED] only an organization admin can change the legacy event window")
+
+	l := upgradedLedger(t)
+	var init chaincode.Initialization
+	require.NoError(t, json.Unmarshal(l.State[chaincode.InitializationKey], &init))
+	assert.Equal(t, "2021-03-31T12:00:00Z", init.LegacyEventsUntil)
+
+	updated, err := contract.SetLegacyEventWindow(l.Context, "2021-03-01T13:00:00+01:00")
+	require.NoError(t, err)
+	assert.Equal(t, "2021-03-01T12:00:00Z", updated.LegacyEventsUntil)
+
+	l.WithTxID("pay")
+	_, err = contract.TransferFrom(l.Context, "alice", "bob", 1)
+	require.NoError(t, err)
+	l.AssertEvent(chaincode.EventTransferred, l.Transaction("pay"))
+}
@@ -0,0 +1,189 @@
+//go:build go1.18
+// +build go1.18
+
+/*
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package chaincode_test
+
+import (
+	"encoding/json"
+	"strconv"
+	"testing"
+
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+	"github.com/hyperledger/fabric-chaincode-go/shimtest"
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+	"github.com/kkiu1756/my_fabric/src/chaincode-go/chaincode"
+	"github.com/kkiu1756/my_fabric/src/chaincode-go/tokentest"
+	"github.com/kkiu1756/my_fabric/src/chaincode-go/validation"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// #########
+// HELPERS
+// #########
+
+// newMockStub runs the contract behind the contractapi router, so arguments
+// go through the same string parsing as on a peer
+func newMockStub(t *testing.T) *shimtest.MockStub {
+	cc, err := contractapi.NewChaincode(&chaincode.SmartContract{})
+	require.NoError(t, err)
+
+	stub := shimtest.NewMockStub("token", cc)
+	stub.Creator = tokentest.Identity(t, "Org1MSP", "admin", chaincode.AdminOU)
+	require.True(t, invoke(stub, "Initialize"))
+	invoke(stub, "CreateUser", "alice", "user", "100")
+	invoke(stub, "CreateUser", "bob", "user", "20")
+
+	return stub
+}
+
+func invoke(stub *shimtest.MockStub, fn string, args ...string) bool {
+	invocation := [][]byte{[]byte(fn)}
+	for _, arg := range args {
+		invocation = append(invocation, []byte(arg))
+	}
+
+	return stub.MockInvoke("tx", invocation).Status == shim.OK
+}
+
+func stubBalance(t *testing.T, stub *shimtest.MockStub, id string) int {
+	var user chaincode.User
+	require.NoError(t, json.Unmarshal(stub.State[id], &user))
+	return user.Balance
+}
+
+// #########
+// TESTS
+// #########
+
+func FuzzTransferFrom(f *testing.F) {
+	f.Add("alice", "bob", "30")
+	f.Add("alice", "bob", "100")
+	f.Add("alice", "bob", "101")
+	f.Add("alice", "bob", "-1")
+	f.Add("alice", "bob", "+5")
+	f.Add("alice", "bob", "1.5")
+	f.Add("alice", "bob", "9223372036854775808")
+	f.Add("alice", "alice", "1")
+	f.Add("carol", "bob", "1")
+	f.Add("", "bob", "0")
+
+	f.Fuzz(func(t *testing.T, from string, to string, amount string) {
+		stub := newMockStub(t)
+		balances := map[string]int{"alice": 100, "bob": 20}
+
+		value, parseErr := strconv.ParseInt(amount, 10, 64)
+		_, fromExists := balances[from]
+		_, toExists := balances[to]
+		accept := parseErr == nil && from != to && fromExists && toExists && value >= 0 && int(value) <= balances[from]
+
+		ok := invoke(stub, "TransferFrom", from, to, amount)
+		require.Equal(t, accept, ok, "accepted %q -> %q amount %q", from, to, amount)
+
+		if accept {
+			balances[from] -= int(value)
+			balances[to] += int(value)
+		}
+		assert.Equal(t, balances["alice"], stubBalance(t, stub, "alice"))
+		assert.Equal(t, balances["bob"], stubBalance(t, stub, "bob"))
+		assert.Equal(t, 120, stubBalance(t, stub, "alice")+stubBalance(t, stub, "bob"), "transfers must conserve the total")
+	})
+}
+
+func FuzzCreateUser(f *testing.F) {
+	f.Add("carol", "user", 10)
+	f.Add("", "", 0)
+	f.Add("alice", "user", 10)
+	f.Add("\x00composite\x00key\x00", "user", -5)
+	f.Add("\xff\xfe", "merchant", 1<<62)
+
+	f.Fuzz(func(t *testing.T, id string, userType string, balance int) {
+		state := ledger(t)
+		_, existed := state[id]
+		ctx, _ := newContext(state)
+		contract := &chaincode.SmartContract{}
+
+		created, err := contract.CreateUser(ctx, id, userType, balance)
+		invalid := validation.First(
+			validation.ID("id", id),
+			validation.ID("type", userType),
+			validation.Amount("balance", balance),
+		)
+		if invalid != nil {
+			require.EqualError(t, err, "[INVALID_ARGUMENT] "+invalid.Error())
+			return
+		}
+		if existed {
+			require.EqualError(t, err, "[ACCOUNT_EXISTS] user "+id+" exist")
+			return
+		}
+		require.NoError(t, err)
+
+		stored, err := contract.GetUser(ctx, id)
+		require.NoError(t, err, "created user %q must be readable", id)
+		assert.Equal(t, created, stored)
+
+		_, err = contract.CreateUser(ctx, id, userType, balance)
+		assert.Error(t, err, "second create of %q must be rejected", id)
+	})
+}
+
+func FuzzUserRecord(f *testing.F) {
+	f.Add([]byte(`{"userId":"alice","type":"user","balance":100}`))
+	f.Add([]byte(`{"userId":"alice","balance":1e3}`))
+	f.Add([]byte(`{"balance":"100"}`))
+	f.Add([]byte(`{"balance":9223372036854775808}`))
+	f.Add([]byte(`null`))
+	f.Add([]byte(`[]`))
+	f.Add([]byte(`{`))
+	f.Add([]byte{})
+
+	f.Fuzz(func(t *testing.T, record []byte) {
+		state := map[string][]byte{"alice": record}
+		ctx, _ := newContext(state)
+
+		fromMethod, methodErr := (&chaincode.SmartContract{}).GetUser(ctx, "alice")
+		fromHelper, helperErr := chaincode.GetUser(ctx, "alice")
+		require.Equal(t, methodErr == nil, helperErr == nil, "GetUser method and helper disagree on %q", record)
+		if methodErr != nil {
+			return
+		}
+		assert.Equal(t, fromMethod, fromHelper)
+
+		roundTrip, err := json.Marshal(fromMethod)
+		require.NoError(t, err)
+		state["alice"] = roundTrip
+		again, err := chaincode.GetUser(ctx, "alice")
+		require.NoError(t, err)
+		assert.Equal(t, fromMethod, again, "re-encoded record must decode to the same user")
+	})
+}
+
+func FuzzTransactionRecord(f *testing.F) {
+	f.Add([]byte(`{"txId":"tx1","from":"alice","to":"bob","value":5}`))
+	f.Add([]byte(`{"value":-1}`))
+	f.Add([]byte(`"tx1"`))
+	f.Add([]byte(`{`))
+
+	f.Fuzz(func(t *testing.T, record []byte) {
+		state := map[string][]byte{"tx1": record}
+		ctx, _ := newContext(state)
+		contract := &chaincode.SmartContract{}
+
+		tx, err := contract.GetTransaction(ctx, "tx1")
+		if err != nil {
+			return
+		}
+
+		roundTrip, err := json.Marshal(tx)
+		require.NoError(t, err)
+		state["tx1"] = roundTrip
+		again, err := contract.GetTransaction(ctx, "tx1")
+		require.NoError(t, err)
+		assert.Equal(t, tx, again)
+	})
+}
@@ -0,0 +1,315 @@
+package chaincode
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"time"
+
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+	"github.com/hyperledger/fabric-protos-go/ledger/queryresult"
+	"github.com/hyperledger/fabric-protos-go/peer"
+	"github.com/kkiu1756/my_fabric/src/chaincode-go/validation"
+)
+
+// The holder of an issuer account sells prepaid gift cards with
+// IssueGiftCard, which moves the card's value into EscrowAccount and
+// stores only the SHA-256 of its code. Whoever holds the code redeems it
+// into their own account with RedeemGiftCard before the card expires; a
+// card is redeemed once. The value of expired cards stays in escrow and is
+// reported as breakage by GetGiftCardBreakage.
+
+// EventGiftCardIssued and EventGiftCardRedeemed replace the typed event of
+// the transfers issuing and redeeming a gift card
+const (
+	EventGiftCardIssued   = "GiftCardIssued"
+	EventGiftCardRedeemed = "GiftCardRedeemed"
+)
+
+// giftCardObjectType keys the gift cards by code hash
+const giftCardObjectType = "giftcard"
+
+// GiftCard is a prepaid gift card
+type GiftCard struct {
+	// CodeHash is the hex encoded SHA-256 of the card's code
+	CodeHash string `json:"codeHash"`
+	Issuer   string `json:"issuer"`
+	Value    int    `json:"value"`
+	// IssuedAt is the proposal timestamp of IssueGiftCard and ExpiresAt
+	// ends the card, both RFC 3339
+	IssuedAt  string `json:"issuedAt"`
+	ExpiresAt string `json:"expiresAt"`
+	// RedeemedBy is the account the card was redeemed into at RedeemedAt,
+	// empty until it is
+	RedeemedBy string `json:"redeemedBy,omitempty" metadata:"redeemedBy,optional"`
+	RedeemedAt string `json:"redeemedAt,omitempty" metadata:"redeemedAt,optional"`
+}
+
+// giftCardEvent is the payload of EventGiftCardIssued and
+// EventGiftCardRedeemed
+type giftCardEvent struct {
+	*Transaction
+	GiftCard *GiftCard `json:"giftCard"`
+}
+
+// GiftCardBreakage sums the expired, unredeemed gift cards of one page
+type GiftCardBreakage struct {
+	Cards int `json:"cards"`
+	Value int `json:"value"`
+	// Bookmark continues the listing, empty after the last page
+	Bookmark string `json:"bookmark"`
+}
+
+// IssueGiftCard issues a gift card of value, paid by issuer, redeemable
+// until expiresAt, an RFC 3339 time, with the code whose hex encoded
+// SHA-256 is codeHash. Only the holder of issuer can call it.
+func (s *SmartContract) IssueGiftCard(ctx contractapi.TransactionContextInterface, issuer string, codeHash string, value int, expiresAt string) (*GiftCard, error) {
+	errs := []error{
+		validation.ID("issuer", issuer),
+		digestError("codeHash", codeHash),
+		validation.Amount("value", value),
+	}
+	if value == 0 {
+		errs = append(errs, &validation.Error{Field: "value", Reason: "must be positive"})
+	}
+	expires, err := time.Parse(time.RFC3339, expiresAt)
+	if err != nil {
+		errs = append(errs, &validation.Error{Field: "expiresAt", Reason: "must be an RFC 3339 time"})
+	}
+	if err := validate(errs...); err != nil {
+		return nil, err
+	}
+	if err := requireInitialized(ctx); err != nil {
+		return nil, err
+	}
+	if err := requireAccountHolder(ctx, issuer, "issue its gift cards"); err != nil {
+		return nil, err
+	}
+
+	stub := ctx.GetStub()
+	timestamp, err := txTimestamp(stub)
+	if err != nil {
+		return nil, err
+	}
+	now, err := time.Parse(time.RFC3339Nano, timestamp)
+	if err != nil {
+		return nil, newError(CodeInvalidArgument, "a gift card needs the proposal timestamp")
+	}
+	if !expires.After(now) {
+		return nil, validate(&validation.Error{Field: "expiresAt", Reason: "must be in the future"})
+	}
+
+	batch := newWriteBatch(ctx)
+	key, existing, err := giftCard(batch, codeHash)
+	if err != nil {
+		return nil, err
+	}
+	if existing != nil {
+		return nil, newError(CodeInvalidArgument, "a gift card with code hash %s exists", codeHash)
+	}
+	err = openEscrow(batch)
+	if err != nil {
+		return nil, err
+	}
+	transaction, err := moveTokens(batch, issuer, EscrowAccount, value, false)
+	if err != nil {
+		return nil, fmt.Errorf("failed to transfer: %w", err)
+	}
+
+	card := &GiftCard{CodeHash: codeHash, Issuer: issuer, Value: value, IssuedAt: timestamp, ExpiresAt: expiresAt}
+	err = batch.putState(key, card)
+	if err != nil {
+		return nil, err
+	}
+	err = emitTypedEvent(batch, EventGiftCardIssued, giftCardEvent{transaction, card})
+	if err != nil {
+		return nil, err
+	}
+
+	err = batch.flush()
+	if err != nil {
+		return nil, err
+	}
+
+	return card, nil
+}
+
+// RedeemGiftCard credits the value of the gift card with code to the
+// caller's account
+func (s *SmartContract) RedeemGiftCard(ctx contractapi.TransactionContextInterface, code string) (*GiftCard, error) {
+	errs := []error{validation.Memo("code", code)}
+	if code == "" {
+		errs = append(errs, &validation.Error{Field: "code", Reason: "must not be empty"})
+	}
+	if err := validate(errs...); err != nil {
+		return nil, err
+	}
+	if err := requireInitialized(ctx); err != nil {
+		return nil, err
+	}
+	caller, err := callerIdentity(ctx)
+	if err != nil {
+		return nil, err
+	}
+	account := caller.CommonName
+	if err := requireAccountHolder(ctx, account, "redeem gift cards"); err != nil {
+		return nil, err
+	}
+
+	batch := newWriteBatch(ctx)
+	key, card, err := giftCard(batch, GiftCardCodeHash(code))
+	if err != nil {
+		return nil, err
+	}
+	if card == nil {
+		return nil, newError(CodeInvalidArgument, "no gift card has this code")
+	}
+	if card.RedeemedBy != "" {
+		return nil, newError(CodeInvalidArgument, "gift card %s was redeemed at %s", card.CodeHash, card.RedeemedAt)
+	}
+	timestamp, err := txTimestamp(ctx.GetStub())
+	if err != nil {
+		return nil, err
+	}
+	if expired, err := giftCardExpired(card, timestamp); err != nil || expired {
+		if err == nil {
+			err = newError(CodeInvalidArgument, "gift card %s expired at %s", card.CodeHash, card.ExpiresAt)
+		}
+		return nil, err
+	}
+
+	transaction, err := moveTokens(batch, EscrowAccount, account, card.Value, true)
+	if err != nil {
+		return nil, fmt.Errorf("failed to transfer: %w", err)
+	}
+	card.RedeemedBy = account
+	card.RedeemedAt = timestamp
+	err = batch.putState(key, card)
+	if err != nil {
+		return nil, err
+	}
+	err = emitTypedEvent(batch, EventGiftCardRedeemed, giftCardEvent{transaction, card})
+	if err != nil {
+		return nil, err
+	}
+
+	err = batch.flush()
+	if err != nil {
+		return nil, err
+	}
+
+	return card, nil
+}
+
+// GetGiftCard returns the gift card whose code hashes to codeHash
+func (s *SmartContract) GetGiftCard(ctx contractapi.TransactionContextInterface, codeHash string) (*GiftCard, error) {
+	if err := validate(digestError("codeHash", codeHash)); err != nil {
+		return nil, err
+	}
+
+	_, card, err := giftCard(newWriteBatch(ctx), codeHash)
+	if err == nil && card == nil {
+		err = newError(CodeInvalidArgument, "no gift card has code hash %s", codeHash)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return card, nil
+}
+
+// GetGiftCardBreakage sums the gift cards of issuer, or of every issuer if
+// it is "", that expired unredeemed, over one page of gift cards. Sum the
+// pages for the total.
+func (s *SmartContract) GetGiftCardBreakage(ctx contractapi.TransactionContextInterface, issuer string, pageSize int, bookmark string) (*GiftCardBreakage, error) {
+	if issuer != "" {
+		if err := validate(validation.ID("issuer", issuer)); err != nil {
+			return nil, err
+		}
+	}
+	pageSize, err := queryPolicy.pageSize(pageSize)
+	if err != nil {
+		return nil, err
+	}
+
+	stub := ctx.GetStub()
+	timestamp, err := txTimestamp(stub)
+	if err != nil {
+		return nil, err
+	}
+	query := func(pageSize int32, bookmark string) (shim.StateQueryIteratorInterface, *peer.QueryResponseMetadata, error) {
+		return stub.GetStateByPartialCompositeKeyWithPagination(giftCardObjectType, []string{}, pageSize, bookmark)
+	}
+
+	breakage := &GiftCardBreakage{}
+	breakage.Bookmark, err = queryPolicy.scan(query, giftCardObjectType, pageSize, bookmark, func(kv *queryresult.KV) error {
+		var card GiftCard
+		if err := decodeRecord(kv.Key, kv.Value, &card, "codeHash", "issuer", "value", "expiresAt"); err != nil {
+			return err
+		}
+		if card.RedeemedBy != "" || (issuer != "" && card.Issuer != issuer) {
+			return nil
+		}
+		expired, err := giftCardExpired(&card, timestamp)
+		if err != nil || !expired {
+			return err
+		}
+
+		var ok bool
+		breakage.Cards++
+		breakage.Value, ok = addBalance(breakage.Value, card.Value)
+		if !ok {
+			return newError(CodeBalanceOverflow, "gift card breakage overflows")
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return breakage, nil
+}
+
+// GiftCardCodeHash returns the hex encoded SHA-256 of a gift card code, the
+// codeHash IssueGiftCard takes
+func GiftCardCodeHash(code string) string {
+	digest := sha256.Sum256([]byte(code))
+	return hex.EncodeToString(digest[:])
+}
+
+// giftCard reads the gift card of codeHash, nil if there is none, and
+// returns its key
+func giftCard(batch *writeBatch, codeHash string) (string, *GiftCard, error) {
+	key, err := batch.ctx.GetStub().CreateCompositeKey(giftCardObjectType, []string{codeHash})
+	if err != nil {
+		return "", nil, err
+	}
+	data, err := batch.getState(key)
+	if err != nil || data == nil {
+		return key, nil, err
+	}
+
+	var card GiftCard
+	err = decodeRecord(key, data, &card, "codeHash", "issuer", "value", "expiresAt")
+	if err != nil {
+		return "", nil, err
+	}
+
+	return key, &card, nil
+}
+
+// giftCardExpired reports whether card has expired at timestamp, the
+// proposal timestamp
+func giftCardExpired(card *GiftCard, timestamp string) (bool, error) {
+	expires, err := time.Parse(time.RFC3339, card.ExpiresAt)
+	if err != nil {
+		return false, corrupt("gift card "+card.CodeHash, err)
+	}
+	now, err := time.Parse(time.RFC3339Nano, timestamp)
+	if err != nil {
+		return false, newError(CodeInvalidArgument, "a gift card needs the proposal timestamp")
+	}
+
+	return !now.Before(expires), nil
+}
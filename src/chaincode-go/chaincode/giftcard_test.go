@@ -0,0 +1,89 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package chaincode_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/kkiu1756/my_fabric/src/chaincode-go/chaincode"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGiftCard(t *testing.T) {
+	l := initializedLedger(t).
+		WithAccount("shop", "user", 1000).
+		WithAccount("alice", "user", 0).
+		WithTimestamp(demurrageTime)
+	paginate(l.Stub, l.State)
+	contract := &chaincode.SmartContract{}
+	expiresAt := demurrageTime.Add(24 * time.Hour).Format(time.RFC3339)
+
+	l.WithCaller("Org1MSP", "shop", "client")
+	for code, value := range map[string]int{"GIFT-1": 300, "GIFT-2": 200} {
+		_, err := contract.IssueGiftCard(l.Context, "shop", chaincode.GiftCardCodeHash(code), value, expiresAt)
+		require.NoError(t, err)
+	}
+	l.AssertBalance("shop", 500)
+	l.AssertBalance(chaincode.EscrowAccount, 500)
+	_, err := contract.IssueGiftCard(l.Context, "shop", chaincode.GiftCardCodeHash("GIFT-1"), 100, expiresAt)
+	assert.EqualError(t, err, "[INVALID_ARGUMENT] a gift card with code hash "+chaincode.GiftCardCodeHash("GIFT-1")+" exists")
+
+	l.WithCaller("Org1MSP", "alice", "client").WithTxID("redeem")
+	card, err := contract.RedeemGiftCard(l.Context, "GIFT-1")
+	require.NoError(t, err)
+	assert.Equal(t, "alice", card.RedeemedBy)
+	l.AssertBalance("alice", 300)
+	l.AssertBalance(chaincode.EscrowAccount, 200)
+	transaction, err := contract.GetTransaction(l.Context, "redeem")
+	require.NoError(t, err)
+	l.AssertEvent(chaincode.EventGiftCardRedeemed, map[string]interface{}{
+		"txId": "redeem", "type": "transfer", "from": chaincode.EscrowAccount, "to": "alice", "value": 300,
+		"txTimestamp": transaction.Timestamp, "channelId": transaction.ChannelID,
+		"initiatorMspId": transaction.InitiatorMSPID, "initiator": transaction.Initiator,
+		"giftCard": map[string]interface{}{
+			"codeHash": chaincode.GiftCardCodeHash("GIFT-1"), "issuer": "shop", "value": 300,
+			"issuedAt": card.IssuedAt, "expiresAt": expiresAt, "redeemedBy": "alice", "redeemedAt": card.RedeemedAt,
+		},
+	})
+
+	_, err = contract.RedeemGiftCard(l.Context, "GIFT-1")
+	assert.EqualError(t, err, "[INVALID_ARGUMENT] gift card "+chaincode.GiftCardCodeHash("GIFT-1")+" was redeemed at "+card.RedeemedAt)
+	_, err = contract.RedeemGiftCard(l.Context, "GIFT-3")
+	assert.EqualError(t, err, "[INVALID_ARGUMENT] no gift card has this code")
+
+	breakage, err := contract.GetGiftCardBreakage(l.Context, "shop", 0, "")
+	require.NoError(t, err)
+	assert.Equal(t, &chaincode.GiftCardBreakage{}, breakage, "no card has expired yet")
+
+	l.WithTimestamp(demurrageTime.Add(48 * time.Hour))
+	_, err = contract.RedeemGiftCard(l.Context, "GIFT-2")
+	assert.EqualError(t, err, "[INVALID_ARGUMENT] gift card "+chaincode.GiftCardCodeHash("GIFT-2")+" expired at "+expiresAt)
+	breakage, err = contract.GetGiftCardBreakage(l.Context, "shop", 0, "")
+	require.NoError(t, err)
+	assert.Equal(t, &chaincode.GiftCardBreakage{Cards: 1, Value: 200}, breakage)
+}
+
+func TestIssueGiftCardRejects(t *testing.T) {
+	l := adminLedger(t).
+		WithAccount("shop", "user", 100).
+		WithTimestamp(demurrageTime)
+	contract := &chaincode.SmartContract{}
+	codeHash := chaincode.GiftCardCodeHash("GIFT-1")
+	expiresAt := demurrageTime.Add(time.Hour).Format(time.RFC3339)
+
+	l.WithCaller("Org1MSP", "shop", "client")
+	_, err := contract.IssueGiftCard(l.Context, "shop", "GIFT-1", 50, expiresAt)
+	assert.EqualError(t, err, "[INVALID_ARGUMENT] invalid codeHash: must be a hex encoded SHA-256 digest")
+	_, err = contract.IssueGiftCard(l.Context, "shop", codeHash, 50, demurrageTime.Format(time.RFC3339))
+	assert.EqualError(t, err, "[INVALID_ARGUMENT] invalid expiresAt: must be in the future")
+	_, err = contract.IssueGiftCard(l.Context, "shop", codeHash, 150, expiresAt)
+	assert.EqualError(t, err, "failed to transfer: [INSUFFICIENT_BALANCE] user balance lower than 150")
+
+	l.WithCaller("Org1MSP", "alice", "client")
+	_, err = contract.IssueGiftCard(l.Context, "shop", codeHash, 50, expiresAt)
+	assert.EqualError(t, err, "[UNAUTHORIZED] only the holder of shop can issue its gift cards")
+}
@@ -0,0 +1,76 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package chaincode_test
+
+import (
+	"flag"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/golang/protobuf/ptypes/timestamp"
+	"github.com/kkiu1756/my_fabric/src/chaincode-go/chaincode"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// The golden files pin the exact bytes other systems consume: state records
+// read by the explorer indexer and CouchDB queries, and event payloads read
+// by the gateway and webhook listeners. A failure here means the wire
+// format changed; if that is intended, regenerate with
+//
+//	go test ./chaincode -run Golden -update
+//
+// and call out the change for downstream consumers in review.
+var update = flag.Bool("update", false, "rewrite golden files")
+
+// #########
+// HELPERS
+// #########
+
+func assertGolden(t *testing.T, name string, actual []byte) {
+	t.Helper()
+
+	path := filepath.Join("testdata", "golden", name)
+	if *update {
+		require.NoError(t, ioutil.WriteFile(path, actual, 0644))
+	}
+
+	expected, err := ioutil.ReadFile(path)
+	require.NoError(t, err, "missing golden file, run with -update to create it")
+	assert.Equal(t, string(expected), string(actual), "encoding of %s changed", name)
+}
+
+// #########
+// TESTS
+// #########
+
+func TestGoldenUserRecord(t *testing.T) {
+	state := initialized(t)
+	ctx, _ := newContext(state)
+
+	_, err := (&chaincode.SmartContract{}).CreateUser(ctx, "alice", "user", 100)
+	require.NoError(t, err)
+	assertGolden(t, "user.json", state["alice"])
+}
+
+func TestGoldenTransfer(t *testing.T) {
+	state := ledger(t)
+	ctx, stub := newContext(state)
+	stub.GetTxTimestampReturns(&timestamp.Timestamp{Seconds: 1614834367, Nanos: 8}, nil)
+	stub.GetChannelIDReturns("mychannel")
+
+	_, err := (&chaincode.SmartContract{}).TransferFrom(ctx, "alice", "bob", 30)
+	require.NoError(t, err)
+
+	assertGolden(t, "transfer_sender.json", state["alice"])
+	assertGolden(t, "transfer_recipient.json", state["bob"])
+	assertGolden(t, "transaction.json", state["tx1"])
+
+	require.Equal(t, 1, stub.SetEventCallCount())
+	name, payload := stub.SetEventArgsForCall(0)
+	assert.Equal(t, "Transfer", name)
+	assertGolden(t, "transfer_event.json", payload)
+}
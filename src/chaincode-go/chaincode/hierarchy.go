@@ -0,0 +1,280 @@
+package chaincode
+
+import (
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+	"github.com/kkiu1756/my_fabric/src/chaincode-go/validation"
+)
+
+// An org admin arranges accounts in trees, such as department accounts
+// under an org treasury, with SetParentAccount. GetRollupBalance sums the
+// balances of an account and everything under it. A sub-account linked as
+// restricted can only send to accounts in its own tree, the accounts with
+// the same root; the check covers every debit of a transfer, escrow legs
+// included. Trees are at most MaxHierarchyDepth deep and a roll-up covers
+// at most MaxRollupAccounts accounts.
+
+// MaxHierarchyDepth bounds the number of ancestors of an account
+const MaxHierarchyDepth = 16
+
+// MaxRollupAccounts bounds the accounts GetRollupBalance sums
+const MaxRollupAccounts = 1000
+
+// hierarchyObjectType keys the link of each account to its parent,
+// hierarchy~parent~account, and its index by parent,
+// hierarchy~child~parent~account
+const hierarchyObjectType = "hierarchy"
+
+// AccountLink links an account to its parent
+type AccountLink struct {
+	Account string `json:"account"`
+	Parent  string `json:"parent"`
+	// Restricted keeps the account's transfers within its tree
+	Restricted bool `json:"restricted"`
+	// UpdatedAt is the proposal timestamp of SetParentAccount, RFC 3339
+	UpdatedAt string `json:"updatedAt"`
+}
+
+// RollupBalance is the balance of an account and its subtree
+type RollupBalance struct {
+	Account string `json:"account"`
+	// Balance is the account's own balance and Total that of its subtree,
+	// pending credits included, see GetAccount
+	Balance int `json:"balance"`
+	Total   int `json:"total"`
+	// Accounts counts the accounts in the subtree, the account included
+	Accounts int `json:"accounts"`
+}
+
+// SetParentAccount places account under parent, restricting its transfers
+// to the tree if restricted; an empty parent detaches it. Only an org admin
+// can call it.
+func (s *SmartContract) SetParentAccount(ctx contractapi.TransactionContextInterface, account string, parent string, restricted bool) (*AccountLink, error) {
+	errs := []error{validation.ID("account", account)}
+	if parent != "" {
+		errs = append(errs, validation.ID("parent", parent))
+	}
+	if parent == account {
+		errs = append(errs, &validation.Error{Field: "parent", Reason: "must differ from account"})
+	}
+	if err := validate(errs...); err != nil {
+		return nil, err
+	}
+	if err := requireInitialized(ctx); err != nil {
+		return nil, err
+	}
+	if _, err := requireAdmin(ctx, "arrange accounts"); err != nil {
+		return nil, err
+	}
+
+	batch := newWriteBatch(ctx)
+	if _, err := batch.getUser(account); err != nil {
+		return nil, err
+	}
+	key, existing, err := accountLink(batch, account)
+	if err != nil {
+		return nil, err
+	}
+	if existing != nil {
+		childKey, err := childLinkKey(batch, existing.Parent, account)
+		if err != nil {
+			return nil, err
+		}
+		batch.delState(key)
+		batch.delState(childKey)
+	}
+
+	timestamp, err := txTimestamp(ctx.GetStub())
+	if err != nil {
+		return nil, err
+	}
+	link := &AccountLink{Account: account, Parent: parent, Restricted: restricted, UpdatedAt: timestamp}
+	if parent != "" {
+		if _, err := batch.getUser(parent); err != nil {
+			return nil, err
+		}
+		ancestors, err := accountAncestors(batch, parent)
+		if err != nil {
+			return nil, err
+		}
+		for _, ancestor := range ancestors {
+			if ancestor == account {
+				return nil, newError(CodeInvalidArgument, "account %s is above %s", account, parent)
+			}
+		}
+		height, err := subtreeHeight(batch, account)
+		if err != nil {
+			return nil, err
+		}
+		if len(ancestors)+1+height > MaxHierarchyDepth {
+			return nil, newError(CodeInvalidArgument, "the tree would be deeper than %d", MaxHierarchyDepth)
+		}
+
+		childKey, err := childLinkKey(batch, parent, account)
+		if err != nil {
+			return nil, err
+		}
+		for _, k := range []string{key, childKey} {
+			if err := batch.putState(k, link); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	err = batch.flush()
+	if err != nil {
+		return nil, err
+	}
+
+	return link, nil
+}
+
+// GetRollupBalance returns the balance of account and of the accounts
+// under it
+func (s *SmartContract) GetRollupBalance(ctx contractapi.TransactionContextInterface, account string) (*RollupBalance, error) {
+	if err := validate(validation.ID("account", account)); err != nil {
+		return nil, err
+	}
+
+	batch := newWriteBatch(ctx)
+	rollup := &RollupBalance{Account: account}
+	queue := []string{account}
+	for len(queue) > 0 {
+		id := queue[0]
+		queue = queue[1:]
+		rollup.Accounts++
+		if rollup.Accounts > MaxRollupAccounts {
+			return nil, newError(CodeInvalidArgument, "the tree of %s has more than %d accounts", account, MaxRollupAccounts)
+		}
+
+		held, err := s.GetAccount(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		balance, ok := addBalance(held.Balance, held.Pending)
+		if id == account {
+			rollup.Balance = balance
+		}
+		if ok {
+			rollup.Total, ok = addBalance(rollup.Total, balance)
+		}
+		if !ok {
+			return nil, newError(CodeBalanceOverflow, "the balance of the tree of %s overflows", account)
+		}
+
+		children, err := childAccounts(batch, id)
+		if err != nil {
+			return nil, err
+		}
+		queue = append(queue, children...)
+	}
+
+	return rollup, nil
+}
+
+// checkHierarchy fails if from is restricted to its tree and to is outside
+// it
+func checkHierarchy(batch *writeBatch, from string, to string) error {
+	_, link, err := accountLink(batch, from)
+	if err != nil || link == nil || !link.Restricted {
+		return err
+	}
+
+	roots := make([]string, 2)
+	for i, account := range []string{from, to} {
+		ancestors, err := accountAncestors(batch, account)
+		if err != nil {
+			return err
+		}
+		roots[i] = account
+		if len(ancestors) > 0 {
+			roots[i] = ancestors[len(ancestors)-1]
+		}
+	}
+	if roots[0] != roots[1] {
+		return newError(CodeInvalidArgument, "account %s can only send within the tree of %s", from, roots[0])
+	}
+
+	return nil
+}
+
+// accountAncestors returns the parent of account, its parent and so on up
+// to the root
+func accountAncestors(batch *writeBatch, account string) ([]string, error) {
+	var ancestors []string
+	for {
+		_, link, err := accountLink(batch, account)
+		if err != nil || link == nil {
+			return ancestors, err
+		}
+		if len(ancestors) == MaxHierarchyDepth {
+			return nil, newError(CodeInvalidArgument, "the tree above %s is deeper than %d", account, MaxHierarchyDepth)
+		}
+		ancestors = append(ancestors, link.Parent)
+		account = link.Parent
+	}
+}
+
+// subtreeHeight returns the number of levels below account, stopping at
+// MaxHierarchyDepth
+func subtreeHeight(batch *writeBatch, account string) (int, error) {
+	children, err := childAccounts(batch, account)
+	if err != nil {
+		return 0, err
+	}
+	height := 0
+	for _, child := range children {
+		h, err := subtreeHeight(batch, child)
+		if err != nil {
+			return 0, err
+		}
+		if h+1 > height {
+			height = h + 1
+		}
+		if height >= MaxHierarchyDepth {
+			break
+		}
+	}
+
+	return height, nil
+}
+
+// childAccounts lists the accounts directly under parent
+func childAccounts(batch *writeBatch, parent string) ([]string, error) {
+	var children []string
+	var link AccountLink
+	err := forEachRecord(batch.ctx, parent, hierarchyObjectType, []string{"child", parent}, &link, []string{"account", "parent"}, func(string) error {
+		children = append(children, link.Account)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return children, nil
+}
+
+// accountLink reads the link of account to its parent, nil if it has none,
+// and returns its key
+func accountLink(batch *writeBatch, account string) (string, *AccountLink, error) {
+	key, err := batch.ctx.GetStub().CreateCompositeKey(hierarchyObjectType, []string{"parent", account})
+	if err != nil {
+		return "", nil, err
+	}
+	data, err := batch.getState(key)
+	if err != nil || data == nil {
+		return key, nil, err
+	}
+
+	var link AccountLink
+	err = decodeRecord(key, data, &link, "account", "parent")
+	if err != nil {
+		return "", nil, err
+	}
+
+	return key, &link, nil
+}
+
+// childLinkKey returns the key indexing account under parent
+func childLinkKey(batch *writeBatch, parent string, account string) (string, error) {
+	return batch.ctx.GetStub().CreateCompositeKey(hierarchyObjectType, []string{"child", parent, account})
+}
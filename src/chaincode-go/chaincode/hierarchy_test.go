@@ -0,0 +1,57 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package chaincode_test
+
+import (
+	"testing"
+
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+	"github.com/kkiu1756/my_fabric/src/chaincode-go/chaincode"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHierarchy(t *testing.T) {
+	l := adminLedger(t).
+		WithAccount("treasury", "org", 1000).
+		WithAccount("sales", "org", 200).
+		WithAccount("sales-east", "org", 50).
+		WithAccount("bob", "user", 0)
+	paginate(l.Stub, l.State)
+	l.Stub.GetStateByPartialCompositeKeyStub = func(objectType string, attributes []string) (shim.StateQueryIteratorInterface, error) {
+		it, _, err := l.Stub.GetStateByPartialCompositeKeyWithPagination(objectType, attributes, 1000, "")
+		return it, err
+	}
+	contract := &chaincode.SmartContract{}
+
+	_, err := contract.SetParentAccount(l.Context, "sales", "treasury", false)
+	require.NoError(t, err)
+	_, err = contract.SetParentAccount(l.Context, "sales-east", "sales", true)
+	require.NoError(t, err)
+	_, err = contract.SetParentAccount(l.Context, "treasury", "sales-east", false)
+	assert.EqualError(t, err, "[INVALID_ARGUMENT] account treasury is above sales-east")
+
+	rollup, err := contract.GetRollupBalance(l.Context, "treasury")
+	require.NoError(t, err)
+	assert.Equal(t, &chaincode.RollupBalance{Account: "treasury", Balance: 1000, Total: 1250, Accounts: 3}, rollup)
+	rollup, err = contract.GetRollupBalance(l.Context, "sales")
+	require.NoError(t, err)
+	assert.Equal(t, &chaincode.RollupBalance{Account: "sales", Balance: 200, Total: 250, Accounts: 2}, rollup)
+
+	_, err = contract.TransferFrom(l.Context, "sales-east", "bob", 10)
+	assert.EqualError(t, err, "failed to transfer: [INVALID_ARGUMENT] account sales-east can only send within the tree of treasury")
+	_, err = contract.TransferFrom(l.Context, "sales-east", "treasury", 10)
+	require.NoError(t, err)
+	_, err = contract.TransferFrom(l.Context, "sales", "bob", 10)
+	require.NoError(t, err, "an unrestricted account sends anywhere")
+
+	_, err = contract.SetParentAccount(l.Context, "sales-east", "", false)
+	require.NoError(t, err)
+	_, err = contract.TransferFrom(l.Context, "sales-east", "bob", 10)
+	require.NoError(t, err)
+	rollup, err = contract.GetRollupBalance(l.Context, "treasury")
+	require.NoError(t, err)
+	assert.Equal(t, 2, rollup.Accounts)
+}
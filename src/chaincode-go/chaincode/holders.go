@@ -0,0 +1,226 @@
+package chaincode
+
+import (
+	"fmt"
+
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+	"github.com/hyperledger/fabric-protos-go/ledger/queryresult"
+	"github.com/kkiu1756/my_fabric/src/chaincode-go/iterate"
+	"github.com/kkiu1756/my_fabric/src/chaincode-go/validation"
+)
+
+// Every account record written is ranked under
+// holderRankObjectType~rank~account, the rank being the stored balance
+// subtracted from validation.MaxAmount in a fixed-width format, so the
+// largest balances sort first and GetTopHolders reads them off the front
+// of the index. Balances beyond that format, see holderRankOf, keep the
+// order. The index ranks the balance as last written: credits
+// pending on a hot account and interest not yet settled do not move an
+// account until it is written again, and a rebase scales every balance
+// alike. Accounts written before the index was introduced join it, the
+// account counts and the type index, see lookup.go, with IndexHolders.
+
+// holderRankObjectType keys the ranked balances
+const holderRankObjectType = "holderrank"
+
+// Holder is an account in GetTopHolders
+type Holder struct {
+	Account string `json:"account"`
+	Type    string `json:"type"`
+	Balance int    `json:"balance"`
+}
+
+// holderRank is the index entry of an account's stored balance
+type holderRank struct {
+	Account string `json:"account"`
+	Balance int    `json:"balance"`
+}
+
+// GetTopHolders returns the n accounts with the largest balances, largest
+// first, n being at most the maximum page size
+func (s *SmartContract) GetTopHolders(ctx contractapi.TransactionContextInterface, n int) ([]*Holder, error) {
+	if n < 1 || n > queryPolicy.MaxPageSize {
+		return nil, validate(&validation.Error{Field: "n", Reason: fmt.Sprintf("must be between 1 and %d", queryPolicy.MaxPageSize)})
+	}
+
+	batch := newWriteBatch(ctx)
+	holders := []*Holder{}
+	_, err := iterate.PartialCompositeKeyPage(ctx.GetStub(), holderRankObjectType, []string{}, int32(n), "", func(kv *queryresult.KV) error {
+		var rank holderRank
+		err := decodeRecord(kv.Key, kv.Value, &rank, "account", "balance")
+		if err != nil {
+			return err
+		}
+		user, err := batch.getUser(rank.Account)
+		if err != nil {
+			return err
+		}
+		holders = append(holders, &Holder{Account: user.ID, Type: user.Type, Balance: user.Balance})
+		return nil
+	})
+	if err != nil {
+		return nil, queryError(holderRankObjectType, err)
+	}
+
+	return holders, nil
+}
+
+// IndexHolders ranks and types the accounts of shard, 0 on an unsharded
+// ledger, visiting at most pageSize records from bookmark, which is empty
+// for the first step. Accounts already indexed are left alone, so it can
+// be rerun.
+// Only an org admin can call it.
+func (s *SmartContract) IndexHolders(ctx contractapi.TransactionContextInterface, shard int, pageSize int, bookmark string) (*MigrationPage, error) {
+	if err := requireInitialized(ctx); err != nil {
+		return nil, err
+	}
+	if _, err := requireAdmin(ctx, "index holders"); err != nil {
+		return nil, err
+	}
+	shards, err := shardCount(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if shard < 0 || shard >= shards {
+		return nil, validate(&validation.Error{Field: "shard", Reason: fmt.Sprintf("must be between 0 and %d", shards-1)})
+	}
+	pageSize, err = queryPolicy.pageSize(pageSize)
+	if err != nil {
+		return nil, err
+	}
+
+	// the step writes, so it cannot read with a paginated query, see
+	// MigrateRecords
+	var iterator shim.StateQueryIteratorInterface
+	if shards == 1 {
+		iterator, err = ctx.GetStub().GetStateByRange(bookmark, maxUnicodeRune)
+	} else {
+		iterator, err = ctx.GetStub().GetStateByPartialCompositeKey(accountObjectType, []string{shardName(shard)})
+	}
+	if err != nil {
+		return nil, &StateError{Op: OpQueryState, Key: shardName(shard), Err: err}
+	}
+
+	batch := newWriteBatch(ctx)
+	page := &MigrationPage{}
+	page.Bookmark, err = unpaginatedPage(iterator, pageSize, bookmark, shards == 1, func(kv *queryresult.KV) error {
+		if shards == 1 && !isAccountRecord(kv.Value) {
+			return nil
+		}
+		var user User
+		err := decodeRecord(kv.Key, kv.Value, &user, "userId", "type", "balance")
+		if err != nil {
+			return err
+		}
+		key, err := holderRankKey(ctx, user.ID, user.Balance)
+		if err != nil {
+			return err
+		}
+		ranked, err := batch.getState(key)
+		if err != nil {
+			return err
+		}
+		key, err = accountTypeKey(ctx, user.Type, user.ID)
+		if err != nil {
+			return err
+		}
+		typed, err := batch.getState(key)
+		if err != nil {
+			return err
+		}
+
+		switch {
+		case ranked == nil:
+			page.Migrated++
+			return rankHolder(batch, &user, nil)
+		case typed == nil:
+			page.Migrated++
+			return typeAccount(batch, &user, nil)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	err = batch.flush()
+	if err != nil {
+		return nil, err
+	}
+
+	return page, nil
+}
+
+// rankHolder buffers moving user in the index from its stored record
+// previous, nil for a new account, counts it, see census.go, and types it,
+// see lookup.go
+func rankHolder(batch *writeBatch, user *User, previous []byte) error {
+	var stored *User
+	if previous != nil {
+		if s, err := userFromRecord(user.ID, previous); err == nil {
+			stored = s
+		}
+	}
+	err := typeAccount(batch, user, stored)
+	if err != nil {
+		return err
+	}
+	if stored != nil {
+		if stored.Balance == user.Balance && stored.Type == user.Type {
+			return nil
+		}
+		err = unrankHolder(batch, stored)
+		if err != nil {
+			return err
+		}
+	}
+
+	key, err := holderRankKey(batch.ctx, user.ID, user.Balance)
+	if err != nil {
+		return err
+	}
+	err = batch.putState(key, holderRank{Account: user.ID, Balance: user.Balance})
+	if err != nil {
+		return err
+	}
+
+	return countAccount(batch, user.Type, user.Balance, 1)
+}
+
+// unrankHolder buffers the removal of stored, an account as last written,
+// from the index and its counts if it was ranked
+func unrankHolder(batch *writeBatch, stored *User) error {
+	key, err := holderRankKey(batch.ctx, stored.ID, stored.Balance)
+	if err != nil {
+		return err
+	}
+	data, err := batch.getState(key)
+	if err != nil || data == nil {
+		return err
+	}
+	batch.delState(key)
+
+	return countAccount(batch, stored.Type, stored.Balance, -1)
+}
+
+// holderRankKey returns the index key of account stored with balance
+func holderRankKey(ctx contractapi.TransactionContextInterface, account string, balance int) (string, error) {
+	return ctx.GetStub().CreateCompositeKey(holderRankObjectType, []string{holderRankOf(balance), account})
+}
+
+// holderRankOf returns the rank key attribute of balance. A balance above
+// validation.MaxAmount, which a rebase can reach, ranks under "-", which
+// sorts before the digits, and a rank too wide for 17 digits under "~",
+// which sorts after them.
+func holderRankOf(balance int) string {
+	if balance > validation.MaxAmount {
+		return fmt.Sprintf("-%019d", maxInt-balance)
+	}
+	// uint64 holds MaxAmount less any int
+	rank := uint64(validation.MaxAmount) - uint64(balance)
+	if rank >= 1e17 {
+		return fmt.Sprintf("~%020d", rank)
+	}
+	return fmt.Sprintf("%017d", rank)
+}
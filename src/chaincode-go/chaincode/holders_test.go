@@ -0,0 +1,107 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package chaincode_test
+
+import (
+	"testing"
+
+	"github.com/kkiu1756/my_fabric/src/chaincode-go/chaincode"
+	"github.com/kkiu1756/my_fabric/src/chaincode-go/validation"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetTopHolders(t *testing.T) {
+	l := adminLedger(t).
+		WithAccount("alice", "user", 100).
+		WithAccount("bob", "user", 50).
+		RejectWritesAfterPagination()
+	paginate(l.Stub, l.State)
+	contract := &chaincode.SmartContract{}
+
+	holders, err := contract.GetTopHolders(l.Context, 10)
+	require.NoError(t, err)
+	assert.Empty(t, holders, "accounts written before the index are not ranked")
+
+	l.WithTxID("index")
+	page, err := contract.IndexHolders(l.Context, 0, 0, "")
+	require.NoError(t, err)
+	assert.Equal(t, &chaincode.MigrationPage{Migrated: 2}, page)
+	page, err = contract.IndexHolders(l.Context, 0, 0, "")
+	require.NoError(t, err)
+	assert.Zero(t, page.Migrated, "a rerun leaves ranked accounts alone")
+
+	l.WithTxID("create")
+	_, err = contract.CreateUser(l.Context, "carol", "user", 80)
+	require.NoError(t, err)
+	_, err = contract.TransferFrom(l.Context, "alice", "bob", 60)
+	require.NoError(t, err)
+
+	holders, err = contract.GetTopHolders(l.Context, 2)
+	require.NoError(t, err)
+	assert.Equal(t, []*chaincode.Holder{
+		{Account: "bob", Type: "user", Balance: 110},
+		{Account: "carol", Type: "user", Balance: 80},
+	}, holders)
+
+	require.NoError(t, contract.DeleteUser(l.WithTxID("delete").Context, "bob"))
+	holders, err = contract.GetTopHolders(l.Context, 10)
+	require.NoError(t, err)
+	assert.Equal(t, []*chaincode.Holder{
+		{Account: "carol", Type: "user", Balance: 80},
+		{Account: "alice", Type: "user", Balance: 40},
+	}, holders)
+
+	_, err = contract.GetTopHolders(l.Context, 0)
+	assert.EqualError(t, err, "[INVALID_ARGUMENT] invalid n: must be between 1 and 1000")
+}
+
+func TestGetTopHoldersAboveMaxAmount(t *testing.T) {
+	l := adminLedger(t).
+		WithAccount("alice", "user", validation.MaxAmount).
+		WithAccount("bob", "user", validation.MaxAmount-4).
+		WithAccount("carol", "user", 100).
+		WithAccount("dave", "user", 0)
+	paginate(l.Stub, l.State)
+	contract := &chaincode.SmartContract{}
+	_, err := contract.SetCreditLine(l.Context, "dave", validation.MaxAmount)
+	require.NoError(t, err)
+	_, err = contract.Rebase(l.Context, 16, 1)
+	require.NoError(t, err)
+	_, err = contract.TransferFrom(l.Context, "dave", "carol", validation.MaxAmount)
+	require.NoError(t, err)
+	_, err = contract.TransferFrom(l.Context, "alice", "bob", 16)
+	require.NoError(t, err)
+
+	holders, err := contract.GetTopHolders(l.Context, 10)
+	require.NoError(t, err)
+	assert.Equal(t, []*chaincode.Holder{
+		{Account: "alice", Type: "user", Balance: 16*validation.MaxAmount - 16},
+		{Account: "bob", Type: "user", Balance: 16*validation.MaxAmount - 48},
+		{Account: "carol", Type: "user", Balance: validation.MaxAmount + 1600},
+		{Account: "dave", Type: "user", Balance: -validation.MaxAmount},
+	}, holders)
+}
+
+func TestIndexHoldersInSteps(t *testing.T) {
+	l := adminLedger(t).
+		WithAccount("alice", "user", 100).
+		WithAccount("bob", "user", 50).
+		WithAccount("carol", "user", 20).
+		RejectWritesAfterPagination()
+	paginate(l.Stub, l.State)
+	contract := &chaincode.SmartContract{}
+
+	page, err := contract.IndexHolders(l.Context, 0, 2, "")
+	require.NoError(t, err)
+	assert.Equal(t, &chaincode.MigrationPage{Migrated: 2, Bookmark: "carol"}, page)
+	page, err = contract.IndexHolders(l.WithTxID("step2").Context, 0, 2, page.Bookmark)
+	require.NoError(t, err)
+	assert.Equal(t, &chaincode.MigrationPage{Migrated: 1}, page)
+
+	holders, err := contract.GetTopHolders(l.Context, 10)
+	require.NoError(t, err)
+	assert.Len(t, holders, 3)
+}
@@ -0,0 +1,252 @@
+package chaincode
+
+import (
+	"encoding/json"
+	"math/big"
+	"time"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+	"github.com/kkiu1756/my_fabric/src/chaincode-go/validation"
+)
+
+// With interest set by SetInterest, the accounts of a type earn a yearly
+// rate in basis points, as simple interest from when it last accrued. Like
+// a demurrage fee, see demurrage.go, interest is settled lazily: reading an
+// account for a transaction adds the interest due to its balance, writing
+// it records the accrual as a statement line of type LineInterest, and
+// GetUser and GetAccount report balances with the interest due.
+// AccrueInterest writes an account only to settle it. Interest is minted;
+// hot accounts and the escrow account earn none.
+
+// LineInterest is the type of the statement line of accrued interest
+const LineInterest = "interest"
+
+// EventInterestAccrued is the event of AccrueInterest
+const EventInterestAccrued = "InterestAccrued"
+
+// MaxInterestRate is 100% a year, in basis points
+const MaxInterestRate = 10000
+
+// interestObjectType keys the time interest last accrued on each account
+const interestObjectType = "interest"
+
+// InterestPolicy is the interest earned by the accounts of a type
+type InterestPolicy struct {
+	AccountType string `json:"accountType"`
+	// Rate is the yearly interest in basis points
+	Rate int `json:"rate"`
+	// Since is when the type started earning interest, an RFC 3339 time;
+	// accounts on which it has not accrued since earn from then
+	Since string `json:"since"`
+}
+
+// interestAccrued is the payload of EventInterestAccrued
+type interestAccrued struct {
+	Account string `json:"account"`
+	Amount  int    `json:"amount"`
+	Balance int    `json:"balance"`
+}
+
+// SetInterest makes the accounts of accountType earn rate basis points a
+// year from now; 0 stops them earning. Changing the rate applies it to the
+// interest due since each account last accrued. Only an org admin can call
+// it.
+func (s *SmartContract) SetInterest(ctx contractapi.TransactionContextInterface, accountType string, rate int) (*Initialization, error) {
+	errs := []error{validation.ID("accountType", accountType)}
+	if rate < 0 || rate > MaxInterestRate {
+		errs = append(errs, &validation.Error{Field: "rate", Reason: "must be between 0 and 10000 basis points"})
+	}
+	if err := validate(errs...); err != nil {
+		return nil, err
+	}
+	if err := requireInitialized(ctx); err != nil {
+		return nil, err
+	}
+	if _, err := requireAdmin(ctx, "change interest"); err != nil {
+		return nil, err
+	}
+
+	init, err := ledgerConfig(ctx)
+	if err != nil {
+		return nil, err
+	}
+	policies := make([]*InterestPolicy, 0, len(init.Interest)+1)
+	var existing *InterestPolicy
+	for _, policy := range init.Interest {
+		if policy.AccountType == accountType {
+			existing = policy
+			continue
+		}
+		policies = append(policies, policy)
+	}
+	switch {
+	case rate == 0:
+	case existing != nil:
+		existing.Rate = rate
+		policies = append(policies, existing)
+	default:
+		timestamp, err := txTimestamp(ctx.GetStub())
+		if err != nil {
+			return nil, err
+		}
+		if _, err := time.Parse(time.RFC3339Nano, timestamp); err != nil {
+			return nil, newError(CodeInvalidArgument, "interest needs the proposal timestamp")
+		}
+		policies = append(policies, &InterestPolicy{AccountType: accountType, Rate: rate, Since: timestamp})
+	}
+	init.Interest = policies
+	if len(policies) == 0 {
+		init.Interest = nil
+	}
+
+	err = putState(ctx, InitializationKey, init)
+	if err != nil {
+		return nil, err
+	}
+
+	return init, nil
+}
+
+// AccrueInterest settles the interest due on account and returns it with
+// the new balance
+func (s *SmartContract) AccrueInterest(ctx contractapi.TransactionContextInterface, account string) (*User, error) {
+	if err := validate(validation.AccountID("account", account)); err != nil {
+		return nil, err
+	}
+	if err := requireInitialized(ctx); err != nil {
+		return nil, err
+	}
+
+	batch := newWriteBatch(ctx)
+	user, err := batch.getUser(account)
+	if err != nil {
+		return nil, err
+	}
+	policy, err := accountInterest(ctx, user)
+	if err != nil {
+		return nil, err
+	}
+	if policy == nil {
+		return nil, newError(CodeInvalidArgument, "accounts of type %s earn no interest", user.Type)
+	}
+	accrued := interestAccrued{Account: account, Amount: user.accrued, Balance: user.Balance}
+	err = batch.putUser(user)
+	if err != nil {
+		return nil, err
+	}
+	err = emitEvent(ctx, EventInterestAccrued, accrued)
+	if err != nil {
+		return nil, err
+	}
+
+	err = batch.flush()
+	if err != nil {
+		return nil, err
+	}
+
+	return user, nil
+}
+
+// accountInterest returns the interest policy of user, nil if it earns none
+func accountInterest(ctx contractapi.TransactionContextInterface, user *User) (*InterestPolicy, error) {
+	init, err := ledgerConfig(ctx)
+	if err != nil || init == nil || user.Hot || user.ID == EscrowAccount {
+		return nil, err
+	}
+	for _, policy := range init.Interest {
+		if policy.AccountType == user.Type {
+			return policy, nil
+		}
+	}
+
+	return nil, nil
+}
+
+// accrueInterest adds the interest due on user, read from its record, at
+// the proposal timestamp and notes it for putUser to index
+func accrueInterest(batch *writeBatch, user *User) error {
+	policy, err := accountInterest(batch.ctx, user)
+	if err != nil || policy == nil || user.Balance <= 0 {
+		return err
+	}
+
+	since, err := time.Parse(time.RFC3339Nano, policy.Since)
+	if err != nil {
+		return corrupt(InitializationKey, err)
+	}
+	key, err := batch.ctx.GetStub().CreateCompositeKey(interestObjectType, []string{user.ID})
+	if err != nil {
+		return err
+	}
+	data, err := batch.getState(key)
+	if err != nil {
+		return err
+	}
+	if data != nil {
+		var stamp string
+		if err := json.Unmarshal(data, &stamp); err != nil {
+			return corrupt(key, err)
+		}
+		accrued, err := time.Parse(time.RFC3339Nano, stamp)
+		if err != nil {
+			return corrupt(key, err)
+		}
+		if accrued.After(since) {
+			since = accrued
+		}
+	}
+
+	timestamp, err := txTimestamp(batch.ctx.GetStub())
+	if err != nil {
+		return err
+	}
+	now, err := time.Parse(time.RFC3339Nano, timestamp)
+	if err != nil || !now.After(since) {
+		return nil
+	}
+
+	// balance * rate * seconds / (10000 * secondsPerYear), rounded down
+	interest := big.NewInt(int64(user.Balance))
+	interest.Mul(interest, big.NewInt(int64(policy.Rate)))
+	interest.Mul(interest, big.NewInt(int64(now.Sub(since)/time.Second)))
+	interest.Quo(interest, big.NewInt(MaxInterestRate*secondsPerYear))
+	if !interest.IsInt64() {
+		return newError(CodeBalanceOverflow, "interest on %s overflows", user.ID)
+	}
+	balance, ok := addBalance(user.Balance, int(interest.Int64()))
+	if !ok {
+		return newError(CodeBalanceOverflow, "interest on %s overflows", user.ID)
+	}
+	user.accrued += balance - user.Balance
+	user.Balance = balance
+
+	return nil
+}
+
+// recordAccrualTime buffers the current proposal timestamp as the time
+// interest last accrued on user, if it earns any
+func recordAccrualTime(batch *writeBatch, user *User) error {
+	policy, err := accountInterest(batch.ctx, user)
+	if err != nil || policy == nil {
+		return err
+	}
+	timestamp, err := txTimestamp(batch.ctx.GetStub())
+	if err != nil || timestamp == "" {
+		return err
+	}
+
+	key, err := batch.ctx.GetStub().CreateCompositeKey(interestObjectType, []string{user.ID})
+	if err != nil {
+		return err
+	}
+	return batch.putState(key, timestamp)
+}
+
+// withInterest adds the interest due to the balance of user, read for a
+// client
+func withInterest(ctx contractapi.TransactionContextInterface, user *User) error {
+	err := accrueInterest(newWriteBatch(ctx), user)
+	user.accrued = 0
+
+	return err
+}
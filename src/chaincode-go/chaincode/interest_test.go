@@ -0,0 +1,76 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package chaincode_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+	"github.com/kkiu1756/my_fabric/src/chaincode-go/chaincode"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInterest(t *testing.T) {
+	l := adminLedger(t).
+		WithAccount("alice", "saver", 10000).
+		WithAccount("bob", "user", 1000).
+		WithTimestamp(demurrageTime)
+	paginate(l.Stub, l.State)
+	l.Stub.GetStateByPartialCompositeKeyStub = func(objectType string, attributes []string) (shim.StateQueryIteratorInterface, error) {
+		it, _, err := l.Stub.GetStateByPartialCompositeKeyWithPagination(objectType, attributes, 1000, "")
+		return it, err
+	}
+	contract := &chaincode.SmartContract{}
+	year := 365 * 24 * time.Hour
+
+	_, err := contract.SetInterest(l.Context, "saver", 1000)
+	require.NoError(t, err)
+
+	l.WithTimestamp(demurrageTime.Add(year))
+	writes := l.Stub.PutStateCallCount()
+	user, err := contract.GetUser(l.Context, "alice")
+	require.NoError(t, err)
+	assert.Equal(t, 11000, user.Balance, "a year at 10% should add a tenth")
+	l.AssertBalance("alice", 10000)
+	assert.Equal(t, writes, l.Stub.PutStateCallCount(), "a read should settle nothing")
+
+	l.WithTxID("accrue")
+	user, err = contract.AccrueInterest(l.Context, "alice")
+	require.NoError(t, err)
+	assert.Equal(t, 11000, user.Balance)
+	l.AssertBalance("alice", 11000)
+	l.AssertEvent(chaincode.EventInterestAccrued, map[string]interface{}{"account": "alice", "amount": 1000, "balance": 11000})
+
+	l.WithTimestamp(demurrageTime.Add(year + year/2)).WithTxID("pay")
+	_, err = contract.TransferFrom(l.Context, "alice", "bob", 1550)
+	require.NoError(t, err)
+	l.AssertBalance("alice", 10000)
+	l.AssertBalance("bob", 2550)
+
+	statement, err := contract.GenerateStatement(l.Context, "alice", "2021-01-01", "2022-12-31")
+	require.NoError(t, err)
+	require.Len(t, statement.Lines, 3)
+	assert.Equal(t, chaincode.LineInterest, statement.Lines[0].Type)
+	assert.Equal(t, 1000, statement.Lines[0].Amount)
+	assert.Equal(t, chaincode.LineInterest, statement.Lines[2].Type)
+	assert.Equal(t, 550, statement.Lines[2].Amount)
+
+	_, err = contract.AccrueInterest(l.Context, "bob")
+	assert.EqualError(t, err, "[INVALID_ARGUMENT] accounts of type user earn no interest")
+}
+
+func TestSetInterestRejects(t *testing.T) {
+	l := adminLedger(t)
+	contract := &chaincode.SmartContract{}
+
+	_, err := contract.SetInterest(l.Context, "saver", 10001)
+	assert.EqualError(t, err, "[INVALID_ARGUMENT] invalid rate: must be between 0 and 10000 basis points")
+
+	l.WithCaller("Org1MSP", "alice", "client")
+	_, err = contract.SetInterest(l.Context, "saver", 1000)
+	assert.EqualError(t, err, "[UNAUTHORIZED] only an organization admin can change interest")
+}
@@ -0,0 +1,250 @@
+package chaincode
+
+import (
+	"fmt"
+
+	"github.com/hyperledger/fabric-chaincode-go/pkg/cid"
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+	"github.com/kkiu1756/my_fabric/src/chaincode-go/validation"
+)
+
+// InitializationKey holds the Initialization record. It uses composite key
+// syntax, which account ids cannot contain, so no account can shadow it.
+const InitializationKey = "\x00config\x00initialized\x00"
+
+// AdminOU is the organizational unit of org admin certificates issued with
+// NodeOUs enabled, as in the test network
+const AdminOU = "admin"
+
+// Initialization records who initialized the contract
+type Initialization struct {
+	MSPID   string `json:"mspId"`
+	AdminID string `json:"adminId"`
+	TXID    string `json:"txId"`
+	// Shards is the number of shards accounts are stored in, 0 if they are
+	// stored under their ids, see shard.go
+	Shards int `json:"shards,omitempty" metadata:"shards,optional"`
+	// Encoding is the codec account and transaction records are written
+	// with, empty for JSON, see codec.go
+	Encoding string `json:"encoding,omitempty" metadata:"encoding,optional"`
+	// Schema is the layout version of the stored records, 0 on ledgers
+	// initialized before it was recorded, see schema.go
+	Schema int `json:"schema,omitempty" metadata:"schema,optional"`
+	// Version is the ContractVersion that initialized the ledger or last
+	// ran PostUpgrade on it, empty if neither happened since it was recorded
+	Version string `json:"version,omitempty" metadata:"version,optional"`
+	// LegacyEventsUntil ends the window in which the legacy Transfer event
+	// is still emitted, an RFC 3339 time, see events.go
+	LegacyEventsUntil string `json:"legacyEventsUntil,omitempty" metadata:"legacyEventsUntil,optional"`
+	// EventVerbosity is the detail of transaction events, empty for
+	// verbose, see verbosity.go
+	EventVerbosity string `json:"eventVerbosity,omitempty" metadata:"eventVerbosity,optional"`
+	// LargeTransferThreshold flags larger transfers for review, 0 if none
+	// are, see compliance.go
+	LargeTransferThreshold int `json:"largeTransferThreshold,omitempty" metadata:"largeTransferThreshold,optional"`
+	// TravelRuleThreshold requires travel rule information on larger
+	// transfers, 0 if none need it, see travel.go
+	TravelRuleThreshold int `json:"travelRuleThreshold,omitempty" metadata:"travelRuleThreshold,optional"`
+	// ConfirmationThreshold makes larger transfers wait for confirmation
+	// for at most ConfirmationWindow, a Go duration, 0 if none wait, see
+	// confirm.go
+	ConfirmationThreshold int    `json:"confirmationThreshold,omitempty" metadata:"confirmationThreshold,optional"`
+	ConfirmationWindow    string `json:"confirmationWindow,omitempty" metadata:"confirmationWindow,optional"`
+	// DisputeWindow is how long a reversible transfer can be reversed, a Go
+	// duration, empty if reversible transfers are disabled, see
+	// reversible.go
+	DisputeWindow string `json:"disputeWindow,omitempty" metadata:"disputeWindow,optional"`
+	// Escheatment sweeps dormant accounts, nil if it is disabled, see
+	// escheat.go
+	Escheatment *EscheatmentPolicy `json:"escheatment,omitempty" metadata:"escheatment,optional"`
+	// Demurrage is the holding fee on balances, nil if there is none, see
+	// demurrage.go
+	Demurrage *DemurragePolicy `json:"demurrage,omitempty" metadata:"demurrage,optional"`
+	// Emission mints to a pool every epoch, nil if there is no emission,
+	// see emission.go
+	Emission *EmissionSchedule `json:"emission,omitempty" metadata:"emission,optional"`
+	// Airdrop is the published airdrop, nil if none is, see airdrop.go
+	Airdrop *Airdrop `json:"airdrop,omitempty" metadata:"airdrop,optional"`
+	// Referral is the referral program, nil if none runs, see referral.go
+	Referral *ReferralProgram `json:"referral,omitempty" metadata:"referral,optional"`
+	// Cashback pays buyers a share of purchases, nil if it is off, see
+	// cashback.go
+	Cashback *CashbackPolicy `json:"cashback,omitempty" metadata:"cashback,optional"`
+	// ConversionSpread is the share of a currency conversion the ledger
+	// keeps, in basis points, see convert.go
+	ConversionSpread int `json:"conversionSpread,omitempty" metadata:"conversionSpread,optional"`
+	// Rebase is the latest rebase, nil before the first, see rebase.go
+	Rebase *RebaseFactor `json:"rebase,omitempty" metadata:"rebase,optional"`
+	// Interest lists the account types earning interest, see interest.go
+	Interest []*InterestPolicy `json:"interest,omitempty" metadata:"interest,optional"`
+}
+
+// Initialize enables the contract. Until an org admin has called it, every
+// mutating method fails with NOT_INITIALIZED. It can only be called once.
+func (s *SmartContract) Initialize(ctx contractapi.TransactionContextInterface) (*Initialization, error) {
+	return initialize(ctx, 0)
+}
+
+// InitializeSharded enables the contract like Initialize, storing accounts
+// in the given number of hash-bucketed shards so they can be enumerated in
+// parallel with ListAccounts. The shard count cannot be changed later.
+func (s *SmartContract) InitializeSharded(ctx contractapi.TransactionContextInterface, shards int) (*Initialization, error) {
+	if shards < 2 || shards > MaxShards {
+		return nil, validate(&validation.Error{Field: "shards", Reason: fmt.Sprintf("must be between 2 and %d", MaxShards)})
+	}
+
+	return initialize(ctx, shards)
+}
+
+func initialize(ctx contractapi.TransactionContextInterface, shards int) (*Initialization, error) {
+	caller, err := requireAdmin(ctx, "initialize the contract")
+	if err != nil {
+		return nil, err
+	}
+
+	existing, err := getState(ctx, InitializationKey)
+	if err != nil {
+		return nil, err
+	}
+	if existing != nil {
+		return nil, newError(CodeAlreadyInitialized, "contract already initialized")
+	}
+
+	mspID, err := caller.GetMSPID()
+	if err != nil {
+		return nil, wrapError(CodeUnauthorized, err, "cannot read client MSP ID")
+	}
+	adminID, err := caller.GetID()
+	if err != nil {
+		return nil, wrapError(CodeUnauthorized, err, "cannot read client id")
+	}
+
+	init := Initialization{MSPID: mspID, AdminID: adminID, TXID: ctx.GetStub().GetTxID(), Shards: shards, Schema: CurrentSchema, Version: ContractVersion}
+	err = putState(ctx, InitializationKey, init)
+	if err != nil {
+		return nil, err
+	}
+
+	return &init, nil
+}
+
+// Initialized reports whether Initialize has been called
+func (s *SmartContract) Initialized(ctx contractapi.TransactionContextInterface) (bool, error) {
+	data, err := getState(ctx, InitializationKey)
+	if err != nil {
+		return false, err
+	}
+
+	return data != nil, nil
+}
+
+// SetRecordEncoding selects the encoding account and transaction records
+// are written with from now on, json or protobuf. Records already stored
+// stay readable; MigrateRecords and MigrateShard convert them. Only an org
+// admin can call it.
+func (s *SmartContract) SetRecordEncoding(ctx contractapi.TransactionContextInterface, encoding string) (*Initialization, error) {
+	if _, ok := codecs[encoding]; !ok {
+		return nil, validate(&validation.Error{Field: "encoding", Reason: fmt.Sprintf("must be %s or %s", EncodingJSON, EncodingProtobuf)})
+	}
+	if err := requireInitialized(ctx); err != nil {
+		return nil, err
+	}
+	if _, err := requireAdmin(ctx, "change the record encoding"); err != nil {
+		return nil, err
+	}
+
+	init, err := ledgerConfig(ctx)
+	if err != nil {
+		return nil, err
+	}
+	init.Encoding = encoding
+	if encoding == EncodingJSON {
+		init.Encoding = ""
+	}
+
+	err = putState(ctx, InitializationKey, init)
+	if err != nil {
+		return nil, err
+	}
+
+	return init, nil
+}
+
+// ledgerConfig reads the Initialization record, nil before Initialize
+func ledgerConfig(ctx contractapi.TransactionContextInterface) (*Initialization, error) {
+	data, err := getState(ctx, InitializationKey)
+	if err != nil || data == nil {
+		return nil, err
+	}
+
+	var init Initialization
+	err = decodeRecord(InitializationKey, data, &init, "mspId", "adminId", "txId")
+	if err != nil {
+		return nil, err
+	}
+
+	return &init, nil
+}
+
+// requireAdmin fails with UNAUTHORIZED unless the client is an admin of
+// the org that initialized the ledger. action completes the error message
+// "only an organization admin can ...".
+func requireAdmin(ctx contractapi.TransactionContextInterface, action string) (cid.ClientIdentity, error) {
+	// read the identity from the stub, the context's client identity
+	// panics on a creator it could not parse
+	caller, err := cid.New(ctx.GetStub())
+	if err != nil {
+		return nil, wrapError(CodeUnauthorized, err, "cannot read client identity")
+	}
+	admin, err := caller.HasOUValue(AdminOU)
+	if err != nil || !admin {
+		return nil, newError(CodeUnauthorized, "only an organization admin can %s", action)
+	}
+	// before Initialize any org's admin can initialize the ledger, and
+	// its MSP becomes the one that administers it
+	init, err := ledgerConfig(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if init != nil && !inLedgerMSP(init, caller) {
+		return nil, newError(CodeUnauthorized, "only an admin of %s can %s", init.MSPID, action)
+	}
+
+	return caller, nil
+}
+
+// hasRole reports whether the client certificate carries ou and the client
+// belongs to the MSP that initialized the ledger, as any org on the channel
+// can issue certificates with any OU
+func hasRole(ctx contractapi.TransactionContextInterface, ou string) bool {
+	caller, err := cid.New(ctx.GetStub())
+	if err != nil {
+		return false
+	}
+	role, err := caller.HasOUValue(ou)
+	if err != nil || !role {
+		return false
+	}
+	init, err := ledgerConfig(ctx)
+
+	return err == nil && init != nil && inLedgerMSP(init, caller)
+}
+
+// inLedgerMSP reports whether caller belongs to the MSP of init
+func inLedgerMSP(init *Initialization, caller cid.ClientIdentity) bool {
+	mspID, err := caller.GetMSPID()
+	return err == nil && mspID == init.MSPID
+}
+
+// requireInitialized fails with NOT_INITIALIZED before Initialize
+func requireInitialized(ctx contractapi.TransactionContextInterface) error {
+	data, err := getState(ctx, InitializationKey)
+	if err != nil {
+		return err
+	}
+	if data == nil {
+		return newError(CodeNotInitialized, "contract not initialized, an organization admin must call Initialize")
+	}
+
+	return nil
+}
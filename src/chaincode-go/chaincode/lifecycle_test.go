@@ -0,0 +1,126 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package chaincode_test
+
+import (
+	"encoding/base64"
+	"errors"
+	"testing"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+	"github.com/kkiu1756/my_fabric/src/chaincode-go/chaincode"
+	"github.com/kkiu1756/my_fabric/src/chaincode-go/tokentest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInitialize(t *testing.T) {
+	contract := &chaincode.SmartContract{}
+	l := tokentest.NewLedger(t).Uninitialized().WithTxID("init-tx").WithCaller("Org1MSP", "Admin@org1.example.com", chaincode.AdminOU)
+
+	initialized, err := contract.Initialized(l.Context)
+	require.NoError(t, err)
+	assert.False(t, initialized)
+
+	init, err := contract.Initialize(l.Context)
+	require.NoError(t, err)
+	assert.Equal(t, "Org1MSP", init.MSPID)
+	adminID, err := base64.StdEncoding.DecodeString(init.AdminID)
+	require.NoError(t, err)
+	assert.Contains(t, string(adminID), "CN=Admin@org1.example.com")
+	assert.Equal(t, "init-tx", init.TXID)
+
+	initialized, err = contract.Initialized(l.Context)
+	require.NoError(t, err)
+	assert.True(t, initialized)
+
+	_, err = contract.Initialize(l.Context)
+	assert.EqualError(t, err, "[ALREADY_INITIALIZED] contract already initialized")
+	assert.True(t, errors.Is(err, chaincode.ErrAlreadyInitialized))
+}
+
+func TestInitializeRequiresAdmin(t *testing.T) {
+	tests := []struct {
+		name  string
+		setup func(l *tokentest.Ledger)
+		err   string
+	}{
+		{
+			name:  "client identity",
+			setup: func(l *tokentest.Ledger) { l.WithCaller("Org1MSP", "User1@org1.example.com", "client") },
+			err:   "[UNAUTHORIZED] only an organization admin can initialize the contract",
+		},
+		{
+			name:  "no creator",
+			setup: func(l *tokentest.Ledger) {},
+			err:   "[UNAUTHORIZED] cannot read client identity",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l := tokentest.NewLedger(t).Uninitialized()
+			tt.setup(l)
+
+			_, err := (&chaincode.SmartContract{}).Initialize(l.Context)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.err)
+			assert.True(t, errors.Is(err, chaincode.ErrUnauthorized))
+			assert.NotContains(t, l.State, chaincode.InitializationKey)
+		})
+	}
+}
+
+func TestAdminOfLedgerMSP(t *testing.T) {
+	contract := &chaincode.SmartContract{}
+	l := tokentest.NewLedger(t).Uninitialized().WithCaller("Org2MSP", "Admin@org2.example.com", chaincode.AdminOU)
+
+	init, err := contract.Initialize(l.Context)
+	require.NoError(t, err)
+	assert.Equal(t, "Org2MSP", init.MSPID)
+
+	l.WithCaller("Org1MSP", "Admin@org1.example.com", chaincode.AdminOU)
+	_, err = contract.SetLargeTransferThreshold(l.Context, 100)
+	assert.EqualError(t, err, "[UNAUTHORIZED] only an admin of Org2MSP can change the large transfer threshold")
+
+	l.WithCaller("Org2MSP", "Admin@org2.example.com", chaincode.AdminOU)
+	_, err = contract.SetLargeTransferThreshold(l.Context, 100)
+	require.NoError(t, err)
+}
+
+func TestNotInitialized(t *testing.T) {
+	contract := &chaincode.SmartContract{}
+	tests := []struct {
+		name string
+		tx   transactionFunc
+	}{
+		{"TransferFrom", transfer("alice", "bob", 1)},
+		{"CreateUser", func(ctx contractapi.TransactionContextInterface) (interface{}, error) {
+			return contract.CreateUser(ctx, "carol", "user", 1)
+		}},
+		{"DeleteUser", func(ctx contractapi.TransactionContextInterface) (interface{}, error) {
+			return nil, contract.DeleteUser(ctx, "bob")
+		}},
+		{"SetBalance", func(ctx contractapi.TransactionContextInterface) (interface{}, error) {
+			return contract.SetBalance(ctx, "bob", 1)
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l := tokentest.NewLedger(t).Uninitialized().WithAccount("alice", "user", 100).WithAccount("bob", "user", 20)
+
+			_, err := tt.tx(l.Context)
+			assert.EqualError(t, err, "[NOT_INITIALIZED] contract not initialized, an organization admin must call Initialize")
+			assert.True(t, errors.Is(err, chaincode.ErrNotInitialized))
+			assert.Zero(t, l.Stub.PutStateCallCount())
+			assert.Zero(t, l.Stub.DelStateCallCount())
+		})
+	}
+
+	l := tokentest.NewLedger(t).Uninitialized().WithAccount("alice", "user", 100)
+	_, err := contract.GetUser(l.Context, "alice")
+	assert.NoError(t, err, "queries should work before initialization")
+}
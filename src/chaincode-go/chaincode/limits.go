@@ -0,0 +1,399 @@
+package chaincode
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+	"github.com/kkiu1756/my_fabric/src/chaincode-go/validation"
+)
+
+// An org admin can cap what an account sends with SetTransferLimit: a
+// maximum per transfer and a maximum over any 24 hours. The daily total is
+// the sum of the account's spend records, spendObjectType~account~txid,
+// whose proposal timestamps fall in the 24 hours up to the transfer's own
+// proposal timestamp, so every endorser computes the same total. A debit
+// from a limited account prunes its spend records older than that.
+//
+// Under LimitPolicyReject a transfer over a limit fails with
+// LIMIT_EXCEEDED. Under LimitPolicyApprove it fails the same way, and the
+// client can submit it with RequestTransfer instead, for an org admin to
+// execute with ApproveTransfer or drop with RejectTransfer.
+const (
+	limitObjectType           = "limit"
+	spendObjectType           = "spend"
+	pendingTransferObjectType = "pendingtransfer"
+)
+
+// Limit policies
+const (
+	LimitPolicyReject  = "reject"
+	LimitPolicyApprove = "approve"
+)
+
+// limitWindow is the period the daily limit applies to
+const limitWindow = 24 * time.Hour
+
+// TransferLimit caps the transfers from an account
+type TransferLimit struct {
+	Account string `json:"account"`
+	// MaxTransfer caps a single transfer, 0 for no cap
+	MaxTransfer int `json:"maxTransfer"`
+	// DailyLimit caps the total sent in any 24 hours, 0 for no cap
+	DailyLimit int `json:"dailyLimit"`
+	// Policy is LimitPolicyReject or LimitPolicyApprove, empty for an
+	// account without limits
+	Policy string `json:"policy"`
+}
+
+// spend records a debit counted towards the daily limit
+type spend struct {
+	Value int `json:"value"`
+	// Timestamp is the proposal timestamp of the debit
+	Timestamp string `json:"txTimestamp"`
+}
+
+// PendingTransfer is a transfer over a limit awaiting an admin's approval
+type PendingTransfer struct {
+	// ID is the transaction id of the RequestTransfer call
+	ID    string `json:"id"`
+	From  string `json:"from"`
+	To    string `json:"to"`
+	Value int    `json:"value"`
+	// Timestamp is the proposal timestamp of the request
+	Timestamp      string `json:"txTimestamp"`
+	RequesterMSPID string `json:"requesterMspId"`
+	Requester      string `json:"requester"`
+}
+
+// SetTransferLimit sets the limits on transfers from account. Zero for
+// both limits removes them. Only an org admin can call it.
+func (s *SmartContract) SetTransferLimit(ctx contractapi.TransactionContextInterface, account string, maxTransfer int, dailyLimit int, policy string) (*TransferLimit, error) {
+	errs := []error{
+		validation.ID("account", account),
+		validation.Amount("maxTransfer", maxTransfer),
+		validation.Amount("dailyLimit", dailyLimit),
+	}
+	if policy != LimitPolicyReject && policy != LimitPolicyApprove {
+		errs = append(errs, &validation.Error{Field: "policy", Reason: fmt.Sprintf("must be %s or %s", LimitPolicyReject, LimitPolicyApprove)})
+	}
+	if err := validate(errs...); err != nil {
+		return nil, err
+	}
+	if err := requireInitialized(ctx); err != nil {
+		return nil, err
+	}
+	if _, err := requireAdmin(ctx, "set transfer limits"); err != nil {
+		return nil, err
+	}
+
+	batch := newWriteBatch(ctx)
+	user, err := batch.getUser(account)
+	if err != nil {
+		return nil, err
+	}
+
+	key, err := ctx.GetStub().CreateCompositeKey(limitObjectType, []string{account})
+	if err != nil {
+		return nil, err
+	}
+	limit := &TransferLimit{Account: account, MaxTransfer: maxTransfer, DailyLimit: dailyLimit, Policy: policy}
+	detail := fmt.Sprintf("max %d per transfer, %d daily, %s", maxTransfer, dailyLimit, policy)
+	if maxTransfer == 0 && dailyLimit == 0 {
+		batch.delState(key)
+		limit = &TransferLimit{Account: account}
+		detail = "no limits"
+	} else {
+		err = batch.putState(key, limit)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	err = appendAudit(batch, account, AuditSetLimit, user.Balance, user.Balance, detail)
+	if err != nil {
+		return nil, err
+	}
+
+	err = batch.flush()
+	if err != nil {
+		return nil, err
+	}
+
+	return limit, nil
+}
+
+// GetTransferLimit returns the limits on transfers from account, with an
+// empty policy if it has none
+func (s *SmartContract) GetTransferLimit(ctx contractapi.TransactionContextInterface, account string) (*TransferLimit, error) {
+	if err := validate(validation.ID("account", account)); err != nil {
+		return nil, err
+	}
+
+	limit, err := transferLimit(newWriteBatch(ctx), account)
+	if err != nil || limit != nil {
+		return limit, err
+	}
+
+	return &TransferLimit{Account: account}, nil
+}
+
+// transferLimit reads the limits of account, nil if it has none
+func transferLimit(batch *writeBatch, account string) (*TransferLimit, error) {
+	key, err := batch.ctx.GetStub().CreateCompositeKey(limitObjectType, []string{account})
+	if err != nil {
+		return nil, err
+	}
+	data, err := batch.getState(key)
+	if err != nil || data == nil {
+		return nil, err
+	}
+
+	var limit TransferLimit
+	err = decodeRecord(key, data, &limit, "account", "maxTransfer", "dailyLimit", "policy")
+	if err != nil {
+		return nil, err
+	}
+
+	return &limit, nil
+}
+
+// checkTransferLimit fails with LIMIT_EXCEEDED if sending value from
+// account breaks its limits, unless the transfer is approved. It buffers
+// the spend record of the debit and the pruning of expired ones.
+func checkTransferLimit(batch *writeBatch, account string, value int, approved bool) error {
+	limit, err := transferLimit(batch, account)
+	if err != nil || limit == nil {
+		return err
+	}
+
+	exceeded := func(format string, args ...interface{}) error {
+		err := newError(CodeLimitExceeded, "transfer of %d from %s exceeds its "+format, append([]interface{}{value, account}, args...)...)
+		if limit.Policy == LimitPolicyApprove {
+			err.Message += ", submit it with RequestTransfer for approval"
+		}
+		return err
+	}
+
+	if !approved && limit.MaxTransfer > 0 && value > limit.MaxTransfer {
+		return exceeded("limit of %d per transfer", limit.MaxTransfer)
+	}
+	if limit.DailyLimit == 0 {
+		return nil
+	}
+
+	stub := batch.ctx.GetStub()
+	timestamp, err := txTimestamp(stub)
+	if err != nil {
+		return err
+	}
+	now, err := time.Parse(time.RFC3339Nano, timestamp)
+	if err != nil {
+		return newError(CodeInvalidArgument, "the daily limit of %s needs the proposal timestamp", account)
+	}
+
+	spent, err := dailySpend(batch, account, now)
+	if err != nil {
+		return err
+	}
+	total, ok := addBalance(spent, value)
+	if !approved && (!ok || total > limit.DailyLimit) {
+		return exceeded("daily limit of %d, %d already sent", limit.DailyLimit, spent)
+	}
+
+	key, err := stub.CreateCompositeKey(spendObjectType, []string{account, stub.GetTxID()})
+	if err != nil {
+		return err
+	}
+
+	return batch.putState(key, spend{Value: value, Timestamp: timestamp})
+}
+
+// dailySpend sums account's spend records in the window ending at now and
+// buffers the deletion of older ones
+func dailySpend(batch *writeBatch, account string, now time.Time) (int, error) {
+	spent := 0
+	var s spend
+	err := forEachRecord(batch.ctx, account, spendObjectType, []string{account}, &s, []string{"value", "txTimestamp"}, func(key string) error {
+		at, err := time.Parse(time.RFC3339Nano, s.Timestamp)
+		if err != nil {
+			return corrupt(key, err)
+		}
+
+		if !at.After(now.Add(-limitWindow)) {
+			batch.delState(key)
+			return nil
+		}
+		var ok bool
+		spent, ok = addBalance(spent, s.Value)
+		if !ok {
+			return newError(CodeBalanceOverflow, "daily spend of %s overflows", account)
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return spent, nil
+}
+
+// RequestTransfer records a transfer from an account under
+// LimitPolicyApprove for an org admin to approve. It moves no tokens.
+func (s *SmartContract) RequestTransfer(ctx contractapi.TransactionContextInterface, from string, to string, value int) (*PendingTransfer, error) {
+	err := validate(
+		validation.ID("from", from),
+		validation.ID("to", to),
+		validation.Amount("value", value),
+	)
+	if err != nil {
+		return nil, err
+	}
+	if err := requireInitialized(ctx); err != nil {
+		return nil, err
+	}
+	from, to, err = resolveAccounts(ctx, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	batch := newWriteBatch(ctx)
+	limit, err := transferLimit(batch, from)
+	if err != nil {
+		return nil, err
+	}
+	if limit == nil || limit.Policy != LimitPolicyApprove {
+		return nil, newError(CodeInvalidArgument, "transfers from %s do not take approval", from)
+	}
+
+	stub := ctx.GetStub()
+	timestamp, err := txTimestamp(stub)
+	if err != nil {
+		return nil, err
+	}
+	pending := &PendingTransfer{
+		ID:             stub.GetTxID(),
+		From:           from,
+		To:             to,
+		Value:          value,
+		Timestamp:      timestamp,
+		RequesterMSPID: creatorMSPID(stub),
+		Requester:      creatorID(stub),
+	}
+	key, err := stub.CreateCompositeKey(pendingTransferObjectType, []string{pending.ID})
+	if err != nil {
+		return nil, err
+	}
+	err = batch.putState(key, pending)
+	if err != nil {
+		return nil, err
+	}
+
+	err = batch.flush()
+	if err != nil {
+		return nil, err
+	}
+
+	return pending, nil
+}
+
+// GetPendingTransfer returns the transfer requested by transaction id
+func (s *SmartContract) GetPendingTransfer(ctx contractapi.TransactionContextInterface, id string) (*PendingTransfer, error) {
+	if err := validate(validation.ID("id", id)); err != nil {
+		return nil, err
+	}
+
+	_, pending, err := pendingTransfer(newWriteBatch(ctx), id)
+	return pending, err
+}
+
+// pendingTransfer reads the transfer requested by transaction id and
+// returns its key
+func pendingTransfer(batch *writeBatch, id string) (string, *PendingTransfer, error) {
+	key, err := batch.ctx.GetStub().CreateCompositeKey(pendingTransferObjectType, []string{id})
+	if err != nil {
+		return "", nil, err
+	}
+	data, err := batch.getState(key)
+	if err != nil {
+		return "", nil, err
+	}
+	if data == nil {
+		return "", nil, newError(CodeTransactionNotFound, "no transfer request %s is pending", id)
+	}
+
+	var pending PendingTransfer
+	err = decodeRecord(key, data, &pending, "id", "from", "to", "value")
+	if err != nil {
+		return "", nil, err
+	}
+
+	return key, &pending, nil
+}
+
+// ApproveTransfer executes the transfer requested by transaction id,
+// whatever the limits of its sender; it still counts towards the daily
+// total. Only an org admin can call it.
+func (s *SmartContract) ApproveTransfer(ctx contractapi.TransactionContextInterface, id string) (*Transaction, error) {
+	if err := validate(validation.ID("id", id)); err != nil {
+		return nil, err
+	}
+	if err := requireInitialized(ctx); err != nil {
+		return nil, err
+	}
+	if _, err := requireAdmin(ctx, "approve transfers"); err != nil {
+		return nil, err
+	}
+
+	batch := newWriteBatch(ctx)
+	key, pending, err := pendingTransfer(batch, id)
+	if err != nil {
+		return nil, err
+	}
+	batch.delState(key)
+
+	from, err := batch.getUser(pending.From)
+	if err != nil {
+		return nil, err
+	}
+	before := from.Balance
+
+	transaction, err := transferHelper(batch, pending.From, pending.To, pending.Value, true)
+	if err != nil {
+		return nil, fmt.Errorf("failed to transfer: %w", err)
+	}
+	err = appendAudit(batch, pending.From, AuditApproveTransfer, before, before-pending.Value, fmt.Sprintf("request %s to %s", id, pending.To))
+	if err != nil {
+		return nil, err
+	}
+
+	err = batch.flush()
+	if err != nil {
+		return nil, err
+	}
+
+	return transaction, nil
+}
+
+// RejectTransfer drops the transfer requested by transaction id. Only an
+// org admin can call it.
+func (s *SmartContract) RejectTransfer(ctx contractapi.TransactionContextInterface, id string) error {
+	if err := validate(validation.ID("id", id)); err != nil {
+		return err
+	}
+	if err := requireInitialized(ctx); err != nil {
+		return err
+	}
+	if _, err := requireAdmin(ctx, "reject transfers"); err != nil {
+		return err
+	}
+
+	batch := newWriteBatch(ctx)
+	key, _, err := pendingTransfer(batch, id)
+	if err != nil {
+		return err
+	}
+	batch.delState(key)
+
+	return batch.flush()
+}
@@ -0,0 +1,157 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package chaincode_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+	"github.com/kkiu1756/my_fabric/src/chaincode-go/chaincode"
+	"github.com/kkiu1756/my_fabric/src/chaincode-go/tokentest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// #########
+// HELPERS
+// #########
+
+var limitTime = time.Date(2021, 3, 1, 12, 0, 0, 0, time.UTC)
+
+// limitedLedger returns an admin's ledger with alice 1000 and bob 0, where
+// alice's transfers are limited
+func limitedLedger(t *testing.T, maxTransfer int, dailyLimit int, policy string) *tokentest.Ledger {
+	l := adminLedger(t).
+		WithAccount("alice", "user", 1000).
+		WithAccount("bob", "user", 0).
+		WithTimestamp(limitTime)
+	paginate(l.Stub, l.State)
+	l.Stub.GetStateByPartialCompositeKeyStub = func(objectType string, attributes []string) (shim.StateQueryIteratorInterface, error) {
+		it, _, err := l.Stub.GetStateByPartialCompositeKeyWithPagination(objectType, attributes, 1000, "")
+		return it, err
+	}
+
+	_, err := (&chaincode.SmartContract{}).SetTransferLimit(l.Context, "alice", maxTransfer, dailyLimit, policy)
+	require.NoError(t, err)
+	return l
+}
+
+// #########
+// TESTS
+// #########
+
+func TestMaxTransfer(t *testing.T) {
+	l := limitedLedger(t, 50, 0, chaincode.LimitPolicyReject)
+	contract := &chaincode.SmartContract{}
+
+	_, err := contract.TransferFrom(l.Context, "alice", "bob", 51)
+	assert.EqualError(t, err, "failed to transfer: [LIMIT_EXCEEDED] transfer of 51 from alice exceeds its limit of 50 per transfer")
+	_, err = contract.TransferFrom(l.Context, "alice", "bob", 50)
+	require.NoError(t, err)
+	_, err = contract.TransferFrom(l.Context, "bob", "alice", 50)
+	require.NoError(t, err, "bob has no limits")
+	l.AssertBalance("alice", 1000)
+}
+
+func TestDailyLimit(t *testing.T) {
+	l := limitedLedger(t, 0, 100, chaincode.LimitPolicyReject)
+	contract := &chaincode.SmartContract{}
+
+	l.WithTxID("t1")
+	_, err := contract.TransferFrom(l.Context, "alice", "bob", 60)
+	require.NoError(t, err)
+	l.WithTxID("t2").WithTimestamp(limitTime.Add(23 * time.Hour))
+	_, err = contract.TransferFrom(l.Context, "alice", "bob", 50)
+	assert.EqualError(t, err, "failed to transfer: [LIMIT_EXCEEDED] transfer of 50 from alice exceeds its daily limit of 100, 60 already sent")
+	_, err = contract.TransferFrom(l.Context, "alice", "bob", 40)
+	require.NoError(t, err)
+
+	l.WithTxID("t3").WithTimestamp(limitTime.Add(24 * time.Hour))
+	_, err = contract.TransferFrom(l.Context, "alice", "bob", 60)
+	require.NoError(t, err, "the first transfer left the window")
+	assert.NotContains(t, l.State, "\x00spend\x00alice\x00t1\x00", "expired spends are pruned")
+	assert.Contains(t, l.State, "\x00spend\x00alice\x00t2\x00")
+	l.AssertBalance("bob", 160)
+}
+
+func TestApproveTransfer(t *testing.T) {
+	l := limitedLedger(t, 100, 0, chaincode.LimitPolicyApprove)
+	contract := &chaincode.SmartContract{}
+
+	_, err := contract.TransferFrom(l.Context, "alice", "bob", 500)
+	assert.EqualError(t, err, "failed to transfer: [LIMIT_EXCEEDED] transfer of 500 from alice exceeds its limit of 100 per transfer, submit it with RequestTransfer for approval")
+
+	l.WithTxID("request")
+	pending, err := contract.RequestTransfer(l.Context, "alice", "bob", 500)
+	require.NoError(t, err)
+	assert.Equal(t, "request", pending.ID)
+	l.AssertBalance("alice", 1000)
+
+	stored, err := contract.GetPendingTransfer(l.Context, "request")
+	require.NoError(t, err)
+	assert.Equal(t, pending, stored)
+
+	l.WithTxID("approval")
+	tx, err := contract.ApproveTransfer(l.Context, "request")
+	require.NoError(t, err)
+	assert.Equal(t, chaincode.TransactionTransfer, tx.Type)
+	l.AssertBalance("alice", 500)
+	l.AssertBalance("bob", 500)
+
+	_, err = contract.ApproveTransfer(l.Context, "request")
+	assert.EqualError(t, err, "[TRANSACTION_NOT_FOUND] no transfer request request is pending")
+
+	trail := auditTrail(t, l, "alice", 0)
+	last := trail[len(trail)-1]
+	assert.Equal(t, chaincode.AuditApproveTransfer, last.Action)
+	assert.Equal(t, 1000, last.Before)
+	assert.Equal(t, 500, last.After)
+}
+
+func TestRejectTransfer(t *testing.T) {
+	l := limitedLedger(t, 100, 0, chaincode.LimitPolicyApprove)
+	contract := &chaincode.SmartContract{}
+
+	l.WithTxID("request")
+	_, err := contract.RequestTransfer(l.Context, "alice", "bob", 500)
+	require.NoError(t, err)
+	require.NoError(t, contract.RejectTransfer(l.Context, "request"))
+	_, err = contract.GetPendingTransfer(l.Context, "request")
+	assert.EqualError(t, err, "[TRANSACTION_NOT_FOUND] no transfer request request is pending")
+	l.AssertBalance("alice", 1000)
+
+	l.WithCaller("Org1MSP", "User1@org1.example.com", "client")
+	_, err = contract.RequestTransfer(l.Context, "alice", "bob", 500)
+	require.NoError(t, err)
+	_, err = contract.ApproveTransfer(l.Context, "request")
+	assert.EqualError(t, err, "[UNAUTHORIZED] only an organization admin can approve transfers")
+}
+
+func TestSetTransferLimit(t *testing.T) {
+	l := limitedLedger(t, 100, 0, chaincode.LimitPolicyReject)
+	contract := &chaincode.SmartContract{}
+
+	_, err := contract.RequestTransfer(l.Context, "alice", "bob", 500)
+	assert.EqualError(t, err, "[INVALID_ARGUMENT] transfers from alice do not take approval")
+
+	_, err = contract.SetTransferLimit(l.Context, "alice", 100, 0, "ask")
+	assert.EqualError(t, err, "[INVALID_ARGUMENT] invalid policy: must be reject or approve")
+	_, err = contract.SetTransferLimit(l.Context, "carol", 100, 0, chaincode.LimitPolicyReject)
+	assert.EqualError(t, err, "[ACCOUNT_NOT_FOUND] user carol does not exist")
+
+	limit, err := contract.SetTransferLimit(l.Context, "alice", 0, 0, chaincode.LimitPolicyReject)
+	require.NoError(t, err)
+	assert.Equal(t, &chaincode.TransferLimit{Account: "alice"}, limit)
+	_, err = contract.TransferFrom(l.Context, "alice", "bob", 500)
+	require.NoError(t, err, "the limits are removed")
+
+	l.WithCaller("Org1MSP", "User1@org1.example.com", "client")
+	_, err = contract.SetTransferLimit(l.Context, "alice", 100, 0, chaincode.LimitPolicyReject)
+	assert.EqualError(t, err, "[UNAUTHORIZED] only an organization admin can set transfer limits")
+	limit, err = contract.GetTransferLimit(l.Context, "alice")
+	require.NoError(t, err)
+	assert.Equal(t, &chaincode.TransferLimit{Account: "alice"}, limit)
+}
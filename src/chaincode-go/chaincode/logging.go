@@ -0,0 +1,123 @@
+package chaincode
+
+import (
+	"hash/fnv"
+	"log/slog"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+	"github.com/hyperledger/fabric-protos-go/msp"
+)
+
+// LogLevelEnv selects the minimum level logged: debug, info, warn or error.
+// If unset, the peer's CORE_CHAINCODE_LOGGING_LEVEL is used, then info.
+const LogLevelEnv = "CHAINCODE_LOG_LEVEL"
+
+// TransferLogSampleEnv sets how many transfers produce one set of
+// per-transfer log records: 1 (the default) logs every transfer, 100 one in
+// a hundred and 0 none. Failed transactions are reported to the client
+// either way.
+const TransferLogSampleEnv = "CHAINCODE_TRANSFER_LOG_SAMPLE"
+
+var (
+	logger            = newLogger()
+	transferLogSample = sampleFromEnv()
+)
+
+// newLogger logs JSON lines to stderr, which the peer collects as the
+// chaincode container log
+func newLogger() *slog.Logger {
+	return slog.New(slog.NewJSONHandler(os.Stderr, &slog.HandlerOptions{Level: levelFromEnv()}))
+}
+
+func levelFromEnv() slog.Level {
+	name := os.Getenv(LogLevelEnv)
+	if name == "" {
+		name = os.Getenv("CORE_CHAINCODE_LOGGING_LEVEL")
+	}
+
+	switch strings.ToLower(name) {
+	case "debug":
+		return slog.LevelDebug
+	case "warn", "warning":
+		return slog.LevelWarn
+	case "error", "critical", "panic", "fatal":
+		return slog.LevelError
+	default:
+		return slog.LevelInfo
+	}
+}
+
+func sampleFromEnv() int {
+	n, err := strconv.Atoi(os.Getenv(TransferLogSampleEnv))
+	if err != nil || n < 0 {
+		return 1
+	}
+	return n
+}
+
+// SetTransferLogSample overrides CHAINCODE_TRANSFER_LOG_SAMPLE
+func SetTransferLogSample(n int) {
+	transferLogSample = n
+}
+
+// SetLogger replaces the contract's logger, for tests or a custom handler
+func SetLogger(l *slog.Logger) {
+	logger = l
+}
+
+// txLogger returns the logger with the fields identifying the current
+// transaction: txid, channel, method and the submitting client's MSP
+func txLogger(ctx contractapi.TransactionContextInterface) *slog.Logger {
+	return stubLogger(ctx.GetStub())
+}
+
+func stubLogger(stub shim.ChaincodeStubInterface) *slog.Logger {
+	method, _ := stub.GetFunctionAndParameters()
+
+	return logger.With(
+		slog.String("txid", stub.GetTxID()),
+		slog.String("channel", stub.GetChannelID()),
+		slog.String("method", method),
+		slog.String("mspid", creatorMSPID(stub)),
+	)
+}
+
+// transferLogger returns txLogger(ctx) if the transaction is sampled for
+// per-transfer logging, or nil. Sampling hashes the txid, so every
+// endorsing peer logs the same transactions.
+func transferLogger(ctx contractapi.TransactionContextInterface) *slog.Logger {
+	if transferLogSample == 0 {
+		return nil
+	}
+	if transferLogSample > 1 {
+		h := fnv.New32a()
+		h.Write([]byte(ctx.GetStub().GetTxID()))
+		if h.Sum32()%uint32(transferLogSample) != 0 {
+			return nil
+		}
+	}
+
+	return txLogger(ctx)
+}
+
+// creatorMSPID reads the MSP ID from the signed proposal's creator. It does
+// not use the client identity, which panics when the creator certificate
+// cannot be parsed.
+func creatorMSPID(stub shim.ChaincodeStubInterface) string {
+	creator, err := stub.GetCreator()
+	if err != nil {
+		return ""
+	}
+
+	var identity msp.SerializedIdentity
+	if err := proto.Unmarshal(creator, &identity); err != nil {
+		return ""
+	}
+
+	return identity.Mspid
+}
@@ -0,0 +1,136 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package chaincode_test
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"testing"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/hyperledger/fabric-protos-go/msp"
+	"github.com/kkiu1756/my_fabric/src/chaincode-go/chaincode"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// #########
+// HELPERS
+// #########
+
+// captureLogs sends the contract's log to the returned buffer at level
+// until the test ends
+func captureLogs(t *testing.T, level slog.Level) *bytes.Buffer {
+	var buf bytes.Buffer
+	chaincode.SetLogger(slog.New(slog.NewJSONHandler(&buf, &slog.HandlerOptions{Level: level})))
+	t.Cleanup(func() { chaincode.SetLogger(slog.Default()) })
+	return &buf
+}
+
+func logLines(t *testing.T, buf *bytes.Buffer) []map[string]interface{} {
+	var lines []map[string]interface{}
+	scanner := bufio.NewScanner(buf)
+	for scanner.Scan() {
+		var line map[string]interface{}
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &line))
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+// #########
+// TESTS
+// #########
+
+func TestTransferLogFields(t *testing.T) {
+	buf := captureLogs(t, slog.LevelInfo)
+
+	creator, err := proto.Marshal(&msp.SerializedIdentity{Mspid: "Org1MSP"})
+	require.NoError(t, err)
+	ctx, stub := newContext(ledger(t))
+	stub.GetChannelIDReturns("mychannel")
+	stub.GetFunctionAndParametersReturns("TransferFrom", []string{"alice", "bob", "30"})
+	stub.GetCreatorReturns(creator, nil)
+
+	_, err = (&chaincode.SmartContract{}).TransferFrom(ctx, "alice", "bob", 30)
+	require.NoError(t, err)
+
+	lines := logLines(t, buf)
+	require.Len(t, lines, 3)
+	for _, line := range lines {
+		assert.Equal(t, "INFO", line["level"])
+		assert.Equal(t, "tx1", line["txid"])
+		assert.Equal(t, "mychannel", line["channel"])
+		assert.Equal(t, "TransferFrom", line["method"])
+		assert.Equal(t, "Org1MSP", line["mspid"])
+	}
+
+	assert.Equal(t, "balance updated", lines[0]["msg"])
+	assert.Equal(t, "alice", lines[0]["account"])
+	assert.Equal(t, 100.0, lines[0]["before"])
+	assert.Equal(t, 70.0, lines[0]["after"])
+	assert.Equal(t, "bob", lines[1]["account"])
+	assert.Equal(t, "transfer", lines[2]["msg"])
+	assert.Equal(t, 30.0, lines[2]["value"])
+}
+
+func TestLogLevel(t *testing.T) {
+	buf := captureLogs(t, slog.LevelWarn)
+	ctx, _ := newContext(ledger(t))
+
+	_, err := (&chaincode.SmartContract{}).TransferFrom(ctx, "alice", "bob", 30)
+	require.NoError(t, err)
+	assert.Empty(t, buf.String(), "info lines should be dropped at warn level")
+}
+
+func TestLogWithoutCreator(t *testing.T) {
+	buf := captureLogs(t, slog.LevelInfo)
+	ctx, stub := newContext(ledger(t))
+	stub.GetCreatorReturns([]byte("not a serialized identity"), nil)
+
+	_, err := (&chaincode.SmartContract{}).TransferFrom(ctx, "alice", "bob", 30)
+	require.NoError(t, err)
+	assert.Equal(t, "", logLines(t, buf)[0]["mspid"])
+}
+
+func TestTransferLogSampling(t *testing.T) {
+	tests := []struct {
+		sample int
+		logged int
+	}{
+		{sample: 1, logged: 100},
+		{sample: 0, logged: 0},
+		{sample: 10, logged: 10},
+	}
+
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("sample %d", tt.sample), func(t *testing.T) {
+			buf := captureLogs(t, slog.LevelInfo)
+			chaincode.SetTransferLogSample(tt.sample)
+			defer chaincode.SetTransferLogSample(1)
+
+			state := accounts(t, map[string]int{"alice": 1000, "bob": 0})
+			for i := 0; i < 100; i++ {
+				ctx, stub := newContext(state)
+				stub.GetTxIDReturns(fmt.Sprintf("tx%d", i))
+				_, err := (&chaincode.SmartContract{}).TransferFrom(ctx, "alice", "bob", 1)
+				require.NoError(t, err)
+			}
+
+			lines := logLines(t, buf)
+			transfers := 0
+			for _, line := range lines {
+				if line["msg"] == "transfer" {
+					transfers++
+				}
+			}
+			assert.InDelta(t, tt.logged, transfers, float64(tt.logged)/2, "transfers logged")
+			assert.Len(t, lines, 3*transfers, "sampled transfers should log all three records")
+		})
+	}
+}
@@ -0,0 +1,272 @@
+package chaincode
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+	"github.com/hyperledger/fabric-protos-go/ledger/queryresult"
+	"github.com/hyperledger/fabric-protos-go/peer"
+	"github.com/kkiu1756/my_fabric/src/chaincode-go/iterate"
+	"github.com/kkiu1756/my_fabric/src/chaincode-go/validation"
+)
+
+// The lookups by account type, balance range and time range read
+// composite-key indexes the contract maintains itself instead of CouchDB
+// selectors, so they work on LevelDB peers too. Accounts are indexed by
+// type under accountTypeObjectType~type~account and transactions by time
+// under txTimeObjectType~timestamp~txid, the timestamp in the fixed-width
+// format of the statement index. Balance ranges are read from the holder
+// index, see holders.go, so they match balances as last written. A range
+// is listed by starting the query at the key of its first entry, as range
+// query bookmarks are start keys, and ending it at the first entry past
+// the range. Accounts written before the type index was introduced join
+// it with IndexHolders; transactions recorded before the time index was
+// introduced are not listed.
+
+const (
+	// accountTypeObjectType keys the accounts by type
+	accountTypeObjectType = "accounttype"
+	// txTimeObjectType keys the transactions by proposal timestamp
+	txTimeObjectType = "txtime"
+)
+
+// TransactionPage is one page of ListTransactionsByTime
+type TransactionPage struct {
+	Transactions []*Transaction `json:"transactions"`
+	// Bookmark continues the listing, empty after the last page
+	Bookmark string `json:"bookmark"`
+}
+
+// accountTypeEntry is the index entry of an account under its type
+type accountTypeEntry struct {
+	Account string `json:"account"`
+}
+
+// transactionTime is the index entry of a transaction under its timestamp
+type transactionTime struct {
+	TXID string `json:"txId"`
+	// Timestamp is the proposal timestamp in RFC 3339 format
+	Timestamp string `json:"txTimestamp"`
+}
+
+// ListAccountsByType returns a page of the accounts of accountType in id
+// order, starting at bookmark, which is empty for the first page
+func (s *SmartContract) ListAccountsByType(ctx contractapi.TransactionContextInterface, accountType string, pageSize int, bookmark string) (*AccountPage, error) {
+	if err := validate(validation.ID("accountType", accountType)); err != nil {
+		return nil, err
+	}
+	pageSize, err := queryPolicy.pageSize(pageSize)
+	if err != nil {
+		return nil, err
+	}
+
+	stub := ctx.GetStub()
+	query := func(pageSize int32, bookmark string) (shim.StateQueryIteratorInterface, *peer.QueryResponseMetadata, error) {
+		return stub.GetStateByPartialCompositeKeyWithPagination(accountTypeObjectType, []string{accountType}, pageSize, bookmark)
+	}
+
+	batch := newWriteBatch(ctx)
+	page := &AccountPage{Accounts: []*User{}}
+	page.Bookmark, err = queryPolicy.scan(query, accountType, pageSize, bookmark, func(kv *queryresult.KV) error {
+		var entry accountTypeEntry
+		err := decodeRecord(kv.Key, kv.Value, &entry, "account")
+		if err != nil {
+			return err
+		}
+		user, err := batch.getUser(entry.Account)
+		if err != nil {
+			return err
+		}
+		page.Accounts = append(page.Accounts, user)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return page, nil
+}
+
+// ListAccountsByBalance returns a page of the accounts whose balance as
+// last written is between minBalance and maxBalance, both included,
+// largest first, starting at bookmark, which is empty for the first page
+func (s *SmartContract) ListAccountsByBalance(ctx contractapi.TransactionContextInterface, minBalance int, maxBalance int, pageSize int, bookmark string) (*AccountPage, error) {
+	errs := []error{balanceBound("minBalance", minBalance), balanceBound("maxBalance", maxBalance)}
+	if maxBalance < minBalance {
+		errs = append(errs, &validation.Error{Field: "maxBalance", Reason: "must not be less than minBalance"})
+	}
+	if err := validate(errs...); err != nil {
+		return nil, err
+	}
+	pageSize, err := queryPolicy.pageSize(pageSize)
+	if err != nil {
+		return nil, err
+	}
+
+	stub := ctx.GetStub()
+	start, err := stub.CreateCompositeKey(holderRankObjectType, []string{holderRankOf(maxBalance)})
+	if err != nil {
+		return nil, err
+	}
+	query := func(pageSize int32, bookmark string) (shim.StateQueryIteratorInterface, *peer.QueryResponseMetadata, error) {
+		return stub.GetStateByPartialCompositeKeyWithPagination(holderRankObjectType, []string{}, pageSize, bookmark)
+	}
+
+	batch := newWriteBatch(ctx)
+	page := &AccountPage{Accounts: []*User{}}
+	page.Bookmark, err = queryPolicy.scan(query, holderRankObjectType, pageSize, rangeStart(start, bookmark), func(kv *queryresult.KV) error {
+		var rank holderRank
+		err := decodeRecord(kv.Key, kv.Value, &rank, "account", "balance")
+		if err != nil {
+			return err
+		}
+		if rank.Balance < minBalance {
+			return iterate.Stop
+		}
+		user, err := batch.getUser(rank.Account)
+		if err != nil {
+			return err
+		}
+		page.Accounts = append(page.Accounts, user)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return page, nil
+}
+
+// ListTransactionsByTime returns a page of the transactions proposed at or
+// after fromTime and before toTime, both RFC 3339, oldest first, starting
+// at bookmark, which is empty for the first page
+func (s *SmartContract) ListTransactionsByTime(ctx contractapi.TransactionContextInterface, fromTime string, toTime string, pageSize int, bookmark string) (*TransactionPage, error) {
+	var errs []error
+	from, err := time.Parse(time.RFC3339Nano, fromTime)
+	if err != nil {
+		errs = append(errs, &validation.Error{Field: "fromTime", Reason: "must be an RFC 3339 timestamp"})
+	}
+	to, err := time.Parse(time.RFC3339Nano, toTime)
+	if err != nil {
+		errs = append(errs, &validation.Error{Field: "toTime", Reason: "must be an RFC 3339 timestamp"})
+	} else if !to.After(from) {
+		errs = append(errs, &validation.Error{Field: "toTime", Reason: "must be after fromTime"})
+	}
+	if err := validate(errs...); err != nil {
+		return nil, err
+	}
+	pageSize, err = queryPolicy.pageSize(pageSize)
+	if err != nil {
+		return nil, err
+	}
+
+	stub := ctx.GetStub()
+	start, err := stub.CreateCompositeKey(txTimeObjectType, []string{from.UTC().Format(indexStamp)})
+	if err != nil {
+		return nil, err
+	}
+	query := func(pageSize int32, bookmark string) (shim.StateQueryIteratorInterface, *peer.QueryResponseMetadata, error) {
+		return stub.GetStateByPartialCompositeKeyWithPagination(txTimeObjectType, []string{}, pageSize, bookmark)
+	}
+
+	page := &TransactionPage{Transactions: []*Transaction{}}
+	page.Bookmark, err = queryPolicy.scan(query, txTimeObjectType, pageSize, rangeStart(start, bookmark), func(kv *queryresult.KV) error {
+		var entry transactionTime
+		err := decodeRecord(kv.Key, kv.Value, &entry, "txId", "txTimestamp")
+		if err != nil {
+			return err
+		}
+		at, err := time.Parse(time.RFC3339Nano, entry.Timestamp)
+		if err != nil {
+			return corrupt(kv.Key, err)
+		}
+		if !at.Before(to) {
+			return iterate.Stop
+		}
+		transaction, err := s.GetTransaction(ctx, entry.TXID)
+		if err != nil {
+			return err
+		}
+		page.Transactions = append(page.Transactions, transaction)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return page, nil
+}
+
+// typeAccount buffers moving user in the type index from stored, its
+// record as last written, nil for a new account
+func typeAccount(batch *writeBatch, user *User, stored *User) error {
+	if stored != nil {
+		if stored.Type == user.Type {
+			return nil
+		}
+		err := untypeAccount(batch, stored)
+		if err != nil {
+			return err
+		}
+	}
+
+	key, err := accountTypeKey(batch.ctx, user.Type, user.ID)
+	if err != nil {
+		return err
+	}
+
+	return batch.putState(key, accountTypeEntry{Account: user.ID})
+}
+
+// untypeAccount buffers the removal of stored from the type index
+func untypeAccount(batch *writeBatch, stored *User) error {
+	key, err := accountTypeKey(batch.ctx, stored.Type, stored.ID)
+	if err != nil {
+		return err
+	}
+	batch.delState(key)
+
+	return nil
+}
+
+// indexTransactionTime buffers the index entry of a recorded transaction
+// under its timestamp, if it has a valid one
+func indexTransactionTime(batch *writeBatch, transaction *Transaction) error {
+	at, err := time.Parse(time.RFC3339Nano, transaction.Timestamp)
+	if err != nil {
+		return nil
+	}
+	key, err := batch.ctx.GetStub().CreateCompositeKey(txTimeObjectType, []string{at.UTC().Format(indexStamp), transaction.TXID})
+	if err != nil {
+		return err
+	}
+
+	return batch.putState(key, transactionTime{TXID: transaction.TXID, Timestamp: transaction.Timestamp})
+}
+
+// accountTypeKey returns the type index key of account of accountType
+func accountTypeKey(ctx contractapi.TransactionContextInterface, accountType string, account string) (string, error) {
+	return ctx.GetStub().CreateCompositeKey(accountTypeObjectType, []string{accountType, account})
+}
+
+// balanceBound checks a bound of a balance range, which may be negative
+func balanceBound(field string, balance int) error {
+	if balance < -validation.MaxAmount || balance > validation.MaxAmount {
+		return &validation.Error{Field: field, Reason: fmt.Sprintf("must be between %d and %d", -validation.MaxAmount, validation.MaxAmount)}
+	}
+
+	return nil
+}
+
+// rangeStart returns the bookmark a range query starts at: the key of the
+// range's first entry for the first page, bookmark otherwise, moved up to
+// that key if it is before it
+func rangeStart(start string, bookmark string) string {
+	if bookmark < start {
+		return start
+	}
+
+	return bookmark
+}
@@ -0,0 +1,173 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package chaincode_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+	"github.com/kkiu1756/my_fabric/src/chaincode-go/chaincode"
+	"github.com/kkiu1756/my_fabric/src/chaincode-go/validation"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// accountIDs returns the ids of the accounts of page
+func accountIDs(page *chaincode.AccountPage) []string {
+	ids := []string{}
+	for _, user := range page.Accounts {
+		ids = append(ids, user.ID)
+	}
+	return ids
+}
+
+func TestListAccountsByType(t *testing.T) {
+	l := adminLedger(t).
+		WithAccount("alice", "user", 100).
+		WithAccount("shop", "merchant", 50)
+	paginate(l.Stub, l.State)
+	contract := &chaincode.SmartContract{}
+
+	page, err := contract.ListAccountsByType(l.Context, "merchant", 0, "")
+	require.NoError(t, err)
+	assert.Empty(t, page.Accounts, "accounts written before the index are not typed")
+	migrated, err := contract.IndexHolders(l.Context, 0, 0, "")
+	require.NoError(t, err)
+	assert.Equal(t, 2, migrated.Migrated)
+
+	_, err = contract.CreateUser(l.Context, "cafe", "merchant", 10)
+	require.NoError(t, err)
+	_, err = contract.CreateUser(l.Context, "deli", "merchant", 0)
+	require.NoError(t, err)
+
+	page, err = contract.ListAccountsByType(l.Context, "merchant", 2, "")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"cafe", "deli"}, accountIDs(page))
+	assert.NotEmpty(t, page.Bookmark)
+	page, err = contract.ListAccountsByType(l.Context, "merchant", 2, page.Bookmark)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"shop"}, accountIDs(page))
+	assert.Empty(t, page.Bookmark)
+
+	require.NoError(t, contract.DeleteUser(l.Context, "deli"))
+	page, err = contract.ListAccountsByType(l.Context, "merchant", 0, "")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"cafe", "shop"}, accountIDs(page))
+	page, err = contract.ListAccountsByType(l.Context, "user", 0, "")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"alice"}, accountIDs(page))
+
+	_, err = contract.ListAccountsByType(l.Context, "", 0, "")
+	assert.EqualError(t, err, "[INVALID_ARGUMENT] invalid accountType: must not be empty")
+}
+
+func TestIndexHoldersTypesRankedAccounts(t *testing.T) {
+	l := adminLedger(t).WithAccount("alice", "user", 100)
+	paginate(l.Stub, l.State)
+	l.Stub.GetStateByPartialCompositeKeyStub = func(objectType string, attributes []string) (shim.StateQueryIteratorInterface, error) {
+		it, _, err := l.Stub.GetStateByPartialCompositeKeyWithPagination(objectType, attributes, 1000, "")
+		return it, err
+	}
+	contract := &chaincode.SmartContract{}
+
+	_, err := contract.IndexHolders(l.Context, 0, 0, "")
+	require.NoError(t, err)
+	// an account ranked before the type index was introduced
+	delete(l.State, "\x00accounttype\x00user\x00alice\x00")
+
+	page, err := contract.IndexHolders(l.Context, 0, 0, "")
+	require.NoError(t, err)
+	assert.Equal(t, 1, page.Migrated)
+	accounts, err := contract.ListAccountsByType(l.Context, "user", 0, "")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"alice"}, accountIDs(accounts))
+	distribution, err := contract.GetAccountDistribution(l.Context)
+	require.NoError(t, err)
+	assert.Equal(t, 1, distribution.Accounts, "typing an account does not count it again")
+}
+
+func TestListAccountsByBalance(t *testing.T) {
+	l := adminLedger(t)
+	paginate(l.Stub, l.State)
+	contract := &chaincode.SmartContract{}
+	for id, balance := range map[string]int{"alice": 100, "bob": 50, "carol": 20, "dave": 20, "erin": 0} {
+		_, err := contract.CreateUser(l.Context, id, "user", balance)
+		require.NoError(t, err)
+	}
+
+	page, err := contract.ListAccountsByBalance(l.Context, 20, 50, 0, "")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"bob", "carol", "dave"}, accountIDs(page))
+	assert.Empty(t, page.Bookmark, "the listing ends at the first balance below the range")
+
+	page, err = contract.ListAccountsByBalance(l.Context, 0, validation.MaxAmount, 2, "")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"alice", "bob"}, accountIDs(page))
+	page, err = contract.ListAccountsByBalance(l.Context, 0, validation.MaxAmount, 2, page.Bookmark)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"carol", "dave"}, accountIDs(page))
+	page, err = contract.ListAccountsByBalance(l.Context, 0, validation.MaxAmount, 2, page.Bookmark)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"erin"}, accountIDs(page))
+	assert.Empty(t, page.Bookmark)
+
+	page, err = contract.ListAccountsByBalance(l.Context, 20, 50, 0, "\x00holderrank\x00")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"bob", "carol", "dave"}, accountIDs(page), "a bookmark before the range starts at the range")
+
+	_, err = contract.TransferFrom(l.Context, "alice", "erin", 30)
+	require.NoError(t, err)
+	page, err = contract.ListAccountsByBalance(l.Context, 30, 70, 0, "")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"alice", "bob", "erin"}, accountIDs(page))
+
+	_, err = contract.ListAccountsByBalance(l.Context, 50, 20, 0, "")
+	assert.EqualError(t, err, "[INVALID_ARGUMENT] invalid maxBalance: must not be less than minBalance")
+	_, err = contract.ListAccountsByBalance(l.Context, -1<<53, 20, 0, "")
+	assert.EqualError(t, err, "[INVALID_ARGUMENT] invalid minBalance: must be between -9007199254740991 and 9007199254740991")
+}
+
+func TestListTransactionsByTime(t *testing.T) {
+	l := adminLedger(t).
+		WithAccount("alice", "user", 100).
+		WithAccount("bob", "user", 100)
+	paginate(l.Stub, l.State)
+	contract := &chaincode.SmartContract{}
+	for i, txID := range []string{"tx1", "tx2", "tx3", "tx4"} {
+		l.WithTxID(txID).WithTimestamp(demurrageTime.Add(time.Duration(i) * time.Hour))
+		_, err := contract.TransferFrom(l.Context, "alice", "bob", 1)
+		require.NoError(t, err)
+	}
+
+	txIDs := func(page *chaincode.TransactionPage) []string {
+		ids := []string{}
+		for _, transaction := range page.Transactions {
+			ids = append(ids, transaction.TXID)
+		}
+		return ids
+	}
+	from := demurrageTime.Add(time.Hour).Format(time.RFC3339)
+	to := demurrageTime.Add(3 * time.Hour).Format(time.RFC3339)
+
+	page, err := contract.ListTransactionsByTime(l.Context, from, to, 0, "")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"tx2", "tx3"}, txIDs(page), "from is included and to is not")
+	assert.Empty(t, page.Bookmark)
+
+	page, err = contract.ListTransactionsByTime(l.Context, demurrageTime.Format(time.RFC3339), "2030-01-01T00:00:00Z", 3, "")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"tx1", "tx2", "tx3"}, txIDs(page))
+	page, err = contract.ListTransactionsByTime(l.Context, demurrageTime.Format(time.RFC3339), "2030-01-01T00:00:00Z", 3, page.Bookmark)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"tx4"}, txIDs(page))
+	assert.Empty(t, page.Bookmark)
+	assert.Equal(t, "alice", page.Transactions[0].From)
+
+	_, err = contract.ListTransactionsByTime(l.Context, "2021-01-01", to, 0, "")
+	assert.EqualError(t, err, "[INVALID_ARGUMENT] invalid fromTime: must be an RFC 3339 timestamp")
+	_, err = contract.ListTransactionsByTime(l.Context, to, from, 0, "")
+	assert.EqualError(t, err, "[INVALID_ARGUMENT] invalid toTime: must be after fromTime")
+}
@@ -0,0 +1,74 @@
+package chaincode
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+
+	"github.com/kkiu1756/my_fabric/src/chaincode-go/validation"
+)
+
+// Airdrops and whitelists are published as the root of a merkle tree over
+// their entries, and callers supply the proof of their own entry. A leaf
+// hash starts with 0x00 and an inner node is SHA-256(0x01 || lower child
+// || higher child), the children ordered bytewise, so a proof is the list
+// of sibling hashes from the leaf up and needs no left or right flags.
+
+// MaxMerkleProof is the most hashes a proof can hold, enough for a tree of
+// 2^32 entries
+const MaxMerkleProof = 32
+
+// MerkleNode returns the inner node of two children
+func MerkleNode(a []byte, b []byte) []byte {
+	if bytes.Compare(a, b) > 0 {
+		a, b = b, a
+	}
+	h := sha256.New()
+	h.Write([]byte{1})
+	h.Write(a)
+	h.Write(b)
+	return h.Sum(nil)
+}
+
+// merkleRoot returns the hex encoded root leaf and its proof lead to
+func merkleRoot(leaf []byte, siblings [][]byte) string {
+	node := leaf
+	for _, sibling := range siblings {
+		node = MerkleNode(node, sibling)
+	}
+	return hex.EncodeToString(node)
+}
+
+// digestError checks root, such as a merkle root, is a hex encoded SHA-256
+// digest
+func digestError(field string, root string) error {
+	if digest, err := hex.DecodeString(root); err != nil || len(digest) != sha256.Size {
+		return &validation.Error{Field: field, Reason: "must be a hex encoded SHA-256 digest"}
+	}
+	return nil
+}
+
+// decodeMerkleProof strictly decodes and validates a proof, a JSON array of
+// hex encoded hashes
+func decodeMerkleProof(proof string) ([][]byte, error) {
+	var hashes []string
+	if err := json.Unmarshal([]byte(proof), &hashes); err != nil {
+		return nil, validate(&validation.Error{Field: "proof", Reason: err.Error()})
+	}
+	if len(hashes) > MaxMerkleProof {
+		return nil, validate(&validation.Error{Field: "proof", Reason: fmt.Sprintf("must hold at most %d hashes", MaxMerkleProof)})
+	}
+
+	siblings := make([][]byte, len(hashes))
+	for i, hash := range hashes {
+		field := fmt.Sprintf("proof[%d]", i)
+		if err := digestError(field, hash); err != nil {
+			return nil, validate(err)
+		}
+		siblings[i], _ = hex.DecodeString(hash)
+	}
+
+	return siblings, nil
+}
@@ -0,0 +1,198 @@
+package chaincode
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+	"github.com/hyperledger/fabric-protos-go/ledger/queryresult"
+	"github.com/kkiu1756/my_fabric/src/chaincode-go/iterate"
+	"github.com/kkiu1756/my_fabric/src/chaincode-go/validation"
+)
+
+const (
+	// compositeKeyNamespace starts every composite key
+	compositeKeyNamespace = "\x00"
+	// maxUnicodeRune ends an open range, as in the shim's composite key
+	// ranges
+	maxUnicodeRune = "\U0010FFFF"
+)
+
+// MigrationPage reports one step of a record migration
+type MigrationPage struct {
+	// Migrated counts the records rewritten
+	Migrated int `json:"migrated"`
+	// Bookmark continues the migration, empty after the last step
+	Bookmark string `json:"bookmark"`
+}
+
+// MigrateRecords rewrites the account and transaction records stored under
+// simple keys in the encoding selected with SetRecordEncoding, visiting at
+// most limit records from bookmark, which is empty for the first step.
+// Records already in that encoding are left alone, so an interrupted
+// migration can be rerun. On a sharded ledger the accounts are migrated
+// with MigrateShard. Only an org admin can call it.
+//
+// A step conflicts with transactions writing the keys it visits and is
+// then resubmitted, so run migrations when the ledger is quiet.
+func (s *SmartContract) MigrateRecords(ctx contractapi.TransactionContextInterface, limit int, bookmark string) (*MigrationPage, error) {
+	if err := requireMigration(ctx); err != nil {
+		return nil, err
+	}
+	limit, err := queryPolicy.pageSize(limit)
+	if err != nil {
+		return nil, err
+	}
+
+	// range queries are evaluated again at validation, paginated ones
+	// cannot be submitted
+	iterator, err := ctx.GetStub().GetStateByRange(bookmark, maxUnicodeRune)
+	if err != nil {
+		return nil, &StateError{Op: OpQueryState, Key: bookmark, Err: err}
+	}
+
+	rewrite, err := reencode(ctx)
+	if err != nil {
+		iterator.Close()
+		return nil, err
+	}
+
+	return migrate(ctx, iterator, limit, "", true, rewrite)
+}
+
+// MigrateShard rewrites the account records of one shard of a sharded
+// ledger like MigrateRecords. A shard's range cannot start at a bookmark,
+// so each step iterates the shard from its first account and skips those
+// before bookmark.
+func (s *SmartContract) MigrateShard(ctx contractapi.TransactionContextInterface, shard int, limit int, bookmark string) (*MigrationPage, error) {
+	if err := requireMigration(ctx); err != nil {
+		return nil, err
+	}
+	shards, err := shardCount(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if shards == 1 {
+		return nil, newError(CodeInvalidArgument, "ledger is not sharded, use MigrateRecords")
+	}
+	if shard < 0 || shard >= shards {
+		return nil, validate(&validation.Error{Field: "shard", Reason: fmt.Sprintf("must be between 0 and %d", shards-1)})
+	}
+	limit, err = queryPolicy.pageSize(limit)
+	if err != nil {
+		return nil, err
+	}
+
+	iterator, err := ctx.GetStub().GetStateByPartialCompositeKey(accountObjectType, []string{shardName(shard)})
+	if err != nil {
+		return nil, &StateError{Op: OpQueryState, Key: shardName(shard), Err: err}
+	}
+
+	rewrite, err := reencode(ctx)
+	if err != nil {
+		iterator.Close()
+		return nil, err
+	}
+
+	return migrate(ctx, iterator, limit, bookmark, false, rewrite)
+}
+
+func requireMigration(ctx contractapi.TransactionContextInterface) error {
+	if err := requireInitialized(ctx); err != nil {
+		return err
+	}
+	_, err := requireAdmin(ctx, "migrate records")
+	return err
+}
+
+// rewriteFunc returns the record to store under key in place of data, or
+// nil to leave it alone
+type rewriteFunc func(key string, data []byte) (interface{}, error)
+
+// reencode returns the rewriteFunc converting account and transaction
+// records to the ledger's encoding
+func reencode(ctx contractapi.TransactionContextInterface) (rewriteFunc, error) {
+	codec, err := recordCodec(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return func(key string, data []byte) (interface{}, error) {
+		if isProto(data) == (codec.Encoding() == EncodingProtobuf) {
+			return nil, nil
+		}
+
+		var record interface{} = &Transaction{}
+		required := []string{"txId", "from", "to", "value"}
+		if isAccountRecord(data) {
+			record = &User{}
+			required = []string{"userId", "type", "balance"}
+		}
+		err := decodeRecord(key, data, record, required...)
+		if err != nil {
+			return nil, err
+		}
+
+		return record, nil
+	}, nil
+}
+
+// migrate stores what rewrite returns for up to limit of the records
+// iterator returns from bookmark on, see unpaginatedPage
+func migrate(ctx contractapi.TransactionContextInterface, iterator shim.StateQueryIteratorInterface, limit int, bookmark string, simpleKeys bool, rewrite rewriteFunc) (*MigrationPage, error) {
+	batch := newWriteBatch(ctx)
+	page := &MigrationPage{}
+	var err error
+	page.Bookmark, err = unpaginatedPage(iterator, limit, bookmark, simpleKeys, func(kv *queryresult.KV) error {
+		record, err := rewrite(kv.Key, kv.Value)
+		if err != nil || record == nil {
+			return err
+		}
+
+		err = batch.putState(kv.Key, record)
+		if err != nil {
+			return err
+		}
+		page.Migrated++
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	err = batch.flush()
+	if err != nil {
+		return nil, err
+	}
+
+	return page, nil
+}
+
+// unpaginatedPage calls visit for up to limit of the records iterator
+// returns from bookmark on and returns the key the next page starts at,
+// empty after the last. A transaction that writes cannot run paginated
+// queries, so it reads with unpaginated ones cut off here. With simpleKeys
+// it skips composite keys, which the peer leaves out of simple key ranges
+// anyway.
+func unpaginatedPage(iterator shim.StateQueryIteratorInterface, limit int, bookmark string, simpleKeys bool, visit iterate.Visit) (string, error) {
+	next := ""
+	visited := 0
+	err := iterate.All(iterator, func(kv *queryresult.KV) error {
+		if kv.Key < bookmark || (simpleKeys && strings.HasPrefix(kv.Key, compositeKeyNamespace)) {
+			return nil
+		}
+		if visited == limit {
+			next = kv.Key
+			return iterate.Stop
+		}
+		visited++
+
+		return visit(kv)
+	})
+	if err != nil {
+		return "", queryError(bookmark, err)
+	}
+
+	return next, nil
+}
@@ -0,0 +1,252 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package chaincode_test
+
+// These tests simulate the peer's validation of concurrently endorsed
+// transactions. Every transfer reads and writes both account keys, so two
+// transactions touching a common account in the same block conflict: the
+// first in block order commits and the later ones are marked
+// MVCC_READ_CONFLICT without changing state. Clients should re-endorse and
+// resubmit such transactions, as the REST gateway does for up to three
+// attempts; the retry sees the committed balance, so a conflict can never
+// overdraw an account.
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+	"github.com/kkiu1756/my_fabric/src/chaincode-go/chaincode"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	valid         = "VALID"
+	mvccConflict  = "MVCC_READ_CONFLICT"
+	endorseFailed = "ENDORSEMENT_FAILURE"
+)
+
+// #########
+// HELPERS
+// #########
+
+// versionedLedger is committed world state with the version (block and
+// transaction number) each key was last written at
+type versionedLedger struct {
+	state    map[string][]byte
+	versions map[string]uint64
+	block    uint64
+}
+
+// endorsement is a simulated transaction's read set with versions and its
+// write set
+type endorsement struct {
+	reads  map[string]uint64
+	writes map[string][]byte
+	err    error
+}
+
+func newVersionedLedger(state map[string][]byte) *versionedLedger {
+	l := &versionedLedger{state: state, versions: map[string]uint64{}}
+	for k := range state {
+		l.versions[k] = 1
+	}
+	return l
+}
+
+// endorse simulates tx against the committed state without changing it.
+// Like on a peer, reads do not see the transaction's own writes.
+func (l *versionedLedger) endorse(txID string, tx transactionFunc) endorsement {
+	e := endorsement{reads: map[string]uint64{}, writes: map[string][]byte{}}
+
+	ctx, stub := newContext(l.state)
+	stub.GetTxIDReturns(txID)
+	stub.GetStateStub = func(key string) ([]byte, error) {
+		e.reads[key] = l.versions[key]
+		return l.state[key], nil
+	}
+	stub.PutStateStub = func(key string, value []byte) error {
+		e.writes[key] = value
+		return nil
+	}
+	stub.DelStateStub = func(key string) error {
+		e.writes[key] = nil
+		return nil
+	}
+
+	_, e.err = tx(ctx)
+	return e
+}
+
+// commit validates the transactions of one block in order and applies the
+// valid ones, returning each transaction's validation code
+func (l *versionedLedger) commit(block ...endorsement) []string {
+	l.block++
+	codes := make([]string, len(block))
+
+	for i, tx := range block {
+		if tx.err != nil {
+			codes[i] = endorseFailed
+			continue
+		}
+
+		codes[i] = valid
+		for key, version := range tx.reads {
+			if l.versions[key] != version {
+				codes[i] = mvccConflict
+				break
+			}
+		}
+		if codes[i] != valid {
+			continue
+		}
+
+		for key, value := range tx.writes {
+			if value == nil {
+				delete(l.state, key)
+				delete(l.versions, key)
+				continue
+			}
+			l.state[key] = value
+			l.versions[key] = l.block<<16 | uint64(i+1)
+		}
+	}
+
+	return codes
+}
+
+func (l *versionedLedger) balance(t *testing.T, id string) int {
+	return balanceOf(t, l.state, id)
+}
+
+func transfer(from string, to string, value int) transactionFunc {
+	return func(ctx contractapi.TransactionContextInterface) (interface{}, error) {
+		return (&chaincode.SmartContract{}).TransferFrom(ctx, from, to, value)
+	}
+}
+
+func accounts(t testing.TB, balances map[string]int) map[string][]byte {
+	state := initialized(t)
+	for id, balance := range balances {
+		state[id] = userJSON(t, id, balance)
+	}
+	return state
+}
+
+// #########
+// TESTS
+// #########
+
+func TestMVCCSameSender(t *testing.T) {
+	l := newVersionedLedger(accounts(t, map[string]int{"alice": 100, "bob": 0, "carol": 0}))
+
+	toBob := l.endorse("tx1", transfer("alice", "bob", 10))
+	toCarol := l.endorse("tx2", transfer("alice", "carol", 10))
+	assert.Equal(t, []string{valid, mvccConflict}, l.commit(toBob, toCarol))
+
+	assert.Equal(t, 90, l.balance(t, "alice"))
+	assert.Equal(t, 10, l.balance(t, "bob"))
+	assert.Equal(t, 0, l.balance(t, "carol"), "conflicting transaction should not change state")
+	assert.NotContains(t, l.state, "tx2", "conflicting transaction should not be recorded")
+
+	retry := l.endorse("tx3", transfer("alice", "carol", 10))
+	assert.Equal(t, []string{valid}, l.commit(retry), "re-endorsed transfer should commit")
+	assert.Equal(t, 80, l.balance(t, "alice"))
+	assert.Equal(t, 10, l.balance(t, "carol"))
+}
+
+func TestMVCCSameRecipient(t *testing.T) {
+	l := newVersionedLedger(accounts(t, map[string]int{"alice": 100, "bob": 100, "merchant": 0}))
+
+	fromAlice := l.endorse("tx1", transfer("alice", "merchant", 10))
+	fromBob := l.endorse("tx2", transfer("bob", "merchant", 10))
+	assert.Equal(t, []string{valid, mvccConflict}, l.commit(fromAlice, fromBob), "a shared recipient is a hot key too")
+	assert.Equal(t, 10, l.balance(t, "merchant"))
+}
+
+func TestMVCCHotRecipientDeltas(t *testing.T) {
+	state := accounts(t, map[string]int{"alice": 100, "bob": 100})
+	state["merchant"] = hotUserJSON(t, "merchant", 0)
+	l := newVersionedLedger(state)
+
+	fromAlice := l.endorse("tx1", transfer("alice", "merchant", 10))
+	fromBob := l.endorse("tx2", transfer("bob", "merchant", 10))
+	assert.Equal(t, []string{valid, valid}, l.commit(fromAlice, fromBob), "credits to a hot account should not conflict")
+
+	assert.Equal(t, 0, l.balance(t, "merchant"), "credits should wait for PruneDeltas")
+	assert.Contains(t, l.state, "\x00delta\x00merchant\x00tx1\x00")
+	assert.Contains(t, l.state, "\x00delta\x00merchant\x00tx2\x00")
+}
+
+func TestMVCCBlockOrderDecides(t *testing.T) {
+	l := newVersionedLedger(accounts(t, map[string]int{"alice": 100, "bob": 0, "carol": 0}))
+
+	toBob := l.endorse("tx1", transfer("alice", "bob", 10))
+	toCarol := l.endorse("tx2", transfer("alice", "carol", 10))
+	assert.Equal(t, []string{valid, mvccConflict}, l.commit(toCarol, toBob), "the orderer's order, not endorsement order, wins")
+	assert.Equal(t, 10, l.balance(t, "carol"))
+	assert.Equal(t, 0, l.balance(t, "bob"))
+}
+
+func TestMVCCDisjointAccounts(t *testing.T) {
+	l := newVersionedLedger(accounts(t, map[string]int{"alice": 100, "bob": 0, "carol": 100, "dave": 0}))
+
+	first := l.endorse("tx1", transfer("alice", "bob", 10))
+	second := l.endorse("tx2", transfer("carol", "dave", 10))
+	assert.Equal(t, []string{valid, valid}, l.commit(first, second))
+}
+
+func TestMVCCConflictCannotOverdraw(t *testing.T) {
+	l := newVersionedLedger(accounts(t, map[string]int{"alice": 100, "bob": 0, "carol": 0}))
+
+	// both endorsements see a balance of 100
+	toBob := l.endorse("tx1", transfer("alice", "bob", 60))
+	toCarol := l.endorse("tx2", transfer("alice", "carol", 60))
+	require.NoError(t, toBob.err)
+	require.NoError(t, toCarol.err)
+	assert.Equal(t, []string{valid, mvccConflict}, l.commit(toBob, toCarol))
+
+	retry := l.endorse("tx3", transfer("alice", "carol", 60))
+	assert.EqualError(t, retry.err, "failed to transfer: [INSUFFICIENT_BALANCE] user balance lower than 60", "the retry should see the committed balance")
+	assert.Equal(t, 40, l.balance(t, "alice"))
+}
+
+func TestMVCCRetryUntilCommitted(t *testing.T) {
+	const senders = 8
+	balances := map[string]int{"merchant": 0}
+	for i := 0; i < senders; i++ {
+		balances[fmt.Sprintf("user%d", i)] = 10
+	}
+	l := newVersionedLedger(accounts(t, balances))
+
+	// every round all pending transfers are endorsed concurrently and
+	// ordered into one block; only the first can commit
+	pending := make([]string, 0, senders)
+	for i := 0; i < senders; i++ {
+		pending = append(pending, fmt.Sprintf("user%d", i))
+	}
+
+	rounds := 0
+	for len(pending) > 0 {
+		rounds++
+		block := make([]endorsement, len(pending))
+		for i, sender := range pending {
+			block[i] = l.endorse(fmt.Sprintf("tx-%s-%d", sender, rounds), transfer(sender, "merchant", 10))
+		}
+
+		var retry []string
+		for i, code := range l.commit(block...) {
+			if code == mvccConflict {
+				retry = append(retry, pending[i])
+			}
+		}
+		assert.Len(t, retry, len(pending)-1, "exactly one transfer into the hot account should commit per block")
+		pending = retry
+	}
+
+	assert.Equal(t, senders, rounds)
+	assert.Equal(t, senders*10, l.balance(t, "merchant"))
+}
@@ -0,0 +1,195 @@
+package chaincode
+
+import (
+	"fmt"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+	"github.com/kkiu1756/my_fabric/src/chaincode-go/validation"
+)
+
+// A seller bills a customer with RequestPayment, and the customer pays
+// with AcceptPayment or refuses with DeclinePayment, so no one holds a
+// standing right to debit another account. The seller is the account
+// named by the caller's certificate common name, and must be held by the
+// caller, see isAccountHolder.
+
+// paymentObjectType keys the payment requests by transaction id
+const paymentObjectType = "payment"
+
+// PaymentRequest is a bill awaiting its payer's answer
+type PaymentRequest struct {
+	// ID is the transaction id of the RequestPayment call
+	ID string `json:"id"`
+	// From is the payer, To the seller who requested the payment
+	From      string `json:"from"`
+	To        string `json:"to"`
+	Value     int    `json:"value"`
+	Reference string `json:"reference"`
+	// Timestamp is the proposal timestamp of the request
+	Timestamp string `json:"txTimestamp"`
+}
+
+// RequestPayment bills from for value, to be paid to the caller's account.
+// reference is free text for the payer, such as an invoice number. It
+// moves no tokens.
+func (s *SmartContract) RequestPayment(ctx contractapi.TransactionContextInterface, from string, value int, reference string) (*PaymentRequest, error) {
+	err := validate(
+		validation.ID("from", from),
+		validation.Amount("value", value),
+		validation.Memo("reference", reference),
+	)
+	if err != nil {
+		return nil, err
+	}
+	if err := requireInitialized(ctx); err != nil {
+		return nil, err
+	}
+
+	caller, err := callerIdentity(ctx)
+	if err != nil {
+		return nil, err
+	}
+	to := caller.CommonName
+	if !isAccountHolder(ctx, to) {
+		return nil, newError(CodeUnauthorized, "only the holder of %s can request payment to it", to)
+	}
+	if from == to {
+		return nil, newError(CodeInvalidArgument, "cannot request payment from the same account")
+	}
+
+	batch := newWriteBatch(ctx)
+	for _, account := range []string{from, to} {
+		if _, err := batch.getUser(account); err != nil {
+			return nil, err
+		}
+	}
+
+	stub := ctx.GetStub()
+	timestamp, err := txTimestamp(stub)
+	if err != nil {
+		return nil, err
+	}
+	request := &PaymentRequest{
+		ID:        stub.GetTxID(),
+		From:      from,
+		To:        to,
+		Value:     value,
+		Reference: reference,
+		Timestamp: timestamp,
+	}
+	key, err := stub.CreateCompositeKey(paymentObjectType, []string{request.ID})
+	if err != nil {
+		return nil, err
+	}
+	err = batch.putState(key, request)
+	if err != nil {
+		return nil, err
+	}
+
+	err = batch.flush()
+	if err != nil {
+		return nil, err
+	}
+
+	return request, nil
+}
+
+// GetPaymentRequest returns the payment requested by transaction id
+func (s *SmartContract) GetPaymentRequest(ctx contractapi.TransactionContextInterface, id string) (*PaymentRequest, error) {
+	if err := validate(validation.ID("id", id)); err != nil {
+		return nil, err
+	}
+
+	_, request, err := paymentRequest(newWriteBatch(ctx), id)
+	return request, err
+}
+
+// paymentRequest reads the payment requested by transaction id and returns
+// its key
+func paymentRequest(batch *writeBatch, id string) (string, *PaymentRequest, error) {
+	key, err := batch.ctx.GetStub().CreateCompositeKey(paymentObjectType, []string{id})
+	if err != nil {
+		return "", nil, err
+	}
+	data, err := batch.getState(key)
+	if err != nil {
+		return "", nil, err
+	}
+	if data == nil {
+		return "", nil, newError(CodeTransactionNotFound, "no payment request %s is pending", id)
+	}
+
+	var request PaymentRequest
+	err = decodeRecord(key, data, &request, "id", "from", "to", "value")
+	if err != nil {
+		return "", nil, err
+	}
+
+	return key, &request, nil
+}
+
+// payerRequest reads the payment requested by transaction id for its payer
+// to answer. action completes the error message "only the holder of ...
+// can ...".
+func payerRequest(ctx contractapi.TransactionContextInterface, batch *writeBatch, id string, action string) (string, *PaymentRequest, error) {
+	key, request, err := paymentRequest(batch, id)
+	if err != nil {
+		return "", nil, err
+	}
+	err = requireAccountHolder(ctx, request.From, fmt.Sprintf("%s payment request %s", action, id))
+	if err != nil {
+		return "", nil, err
+	}
+
+	return key, request, nil
+}
+
+// AcceptPayment pays the payment requested by transaction id. Only the
+// holder of the payer's account can call it.
+func (s *SmartContract) AcceptPayment(ctx contractapi.TransactionContextInterface, id string) (*Transaction, error) {
+	if err := validate(validation.ID("id", id)); err != nil {
+		return nil, err
+	}
+	if err := requireInitialized(ctx); err != nil {
+		return nil, err
+	}
+
+	batch := newWriteBatch(ctx)
+	key, request, err := payerRequest(ctx, batch, id, "accept")
+	if err != nil {
+		return nil, err
+	}
+	batch.delState(key)
+
+	transaction, err := transferHelper(batch, request.From, request.To, request.Value, false)
+	if err != nil {
+		return nil, fmt.Errorf("failed to transfer: %w", err)
+	}
+
+	err = batch.flush()
+	if err != nil {
+		return nil, err
+	}
+
+	return transaction, nil
+}
+
+// DeclinePayment drops the payment requested by transaction id. Only the
+// holder of the payer's account can call it.
+func (s *SmartContract) DeclinePayment(ctx contractapi.TransactionContextInterface, id string) error {
+	if err := validate(validation.ID("id", id)); err != nil {
+		return err
+	}
+	if err := requireInitialized(ctx); err != nil {
+		return err
+	}
+
+	batch := newWriteBatch(ctx)
+	key, _, err := payerRequest(ctx, batch, id, "decline")
+	if err != nil {
+		return err
+	}
+	batch.delState(key)
+
+	return batch.flush()
+}
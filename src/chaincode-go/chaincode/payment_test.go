@@ -0,0 +1,97 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package chaincode_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/kkiu1756/my_fabric/src/chaincode-go/chaincode"
+	"github.com/kkiu1756/my_fabric/src/chaincode-go/tokentest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// #########
+// HELPERS
+// #########
+
+// paymentLedger returns a ledger with alice 100 and shop 0, where shop
+// billed alice 30 in transaction "bill"
+func paymentLedger(t *testing.T) *tokentest.Ledger {
+	l := adminLedger(t).
+		WithAccount("alice", "user", 100).
+		WithAccount("shop", "merchant", 0).
+		WithCaller("Org1MSP", "shop", "client").
+		WithTxID("bill")
+
+	_, err := (&chaincode.SmartContract{}).RequestPayment(l.Context, "alice", 30, "invoice 17")
+	require.NoError(t, err)
+	return l
+}
+
+// #########
+// TESTS
+// #########
+
+func TestAcceptPayment(t *testing.T) {
+	l := paymentLedger(t)
+	contract := &chaincode.SmartContract{}
+
+	request, err := contract.GetPaymentRequest(l.Context, "bill")
+	require.NoError(t, err)
+	assert.Equal(t, &chaincode.PaymentRequest{ID: "bill", From: "alice", To: "shop", Value: 30, Reference: "invoice 17"}, request)
+	l.AssertBalance("alice", 100)
+
+	_, err = contract.AcceptPayment(l.Context, "bill")
+	assert.EqualError(t, err, "[UNAUTHORIZED] only the holder of alice can accept payment request bill", "the seller cannot pay its own bill")
+
+	l.WithCaller("Org1MSP", "alice", "client").WithTxID("pay")
+	transaction, err := contract.AcceptPayment(l.Context, "bill")
+	require.NoError(t, err)
+	assert.Equal(t, "pay", transaction.TXID)
+	l.AssertTransaction("pay", "alice", "shop", 30)
+	l.AssertBalance("alice", 70)
+	l.AssertBalance("shop", 30)
+
+	_, err = contract.AcceptPayment(l.Context, "bill")
+	assert.EqualError(t, err, "[TRANSACTION_NOT_FOUND] no payment request bill is pending")
+}
+
+func TestDeclinePayment(t *testing.T) {
+	l := paymentLedger(t)
+	contract := &chaincode.SmartContract{}
+
+	err := contract.DeclinePayment(l.Context, "bill")
+	assert.EqualError(t, err, "[UNAUTHORIZED] only the holder of alice can decline payment request bill")
+
+	l.WithCaller("Org1MSP", "alice", "client")
+	require.NoError(t, contract.DeclinePayment(l.Context, "bill"))
+	_, err = contract.GetPaymentRequest(l.Context, "bill")
+	assert.True(t, errors.Is(err, chaincode.ErrTransactionNotFound))
+	l.AssertBalance("alice", 100)
+}
+
+func TestAcceptPaymentInsufficientBalance(t *testing.T) {
+	l := paymentLedger(t)
+	l.WithAccount("alice", "user", 10).WithCaller("Org1MSP", "alice", "client")
+
+	_, err := (&chaincode.SmartContract{}).AcceptPayment(l.Context, "bill")
+	assert.EqualError(t, err, "failed to transfer: [INSUFFICIENT_BALANCE] user balance lower than 30")
+}
+
+func TestRequestPaymentRejects(t *testing.T) {
+	l := paymentLedger(t)
+	contract := &chaincode.SmartContract{}
+
+	_, err := contract.RequestPayment(l.Context, "shop", 30, "")
+	assert.EqualError(t, err, "[INVALID_ARGUMENT] cannot request payment from the same account")
+	_, err = contract.RequestPayment(l.Context, "bob", 30, "")
+	assert.True(t, errors.Is(err, chaincode.ErrAccountNotFound))
+
+	l.WithCaller("Org1MSP", "stranger", "client")
+	_, err = contract.RequestPayment(l.Context, "alice", 30, "")
+	assert.True(t, errors.Is(err, chaincode.ErrAccountNotFound), "the caller needs an account to be paid to")
+}
@@ -0,0 +1,183 @@
+package chaincode
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"strconv"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+	"github.com/kkiu1756/my_fabric/src/chaincode-go/validation"
+)
+
+// A buyer pays for an item of a seller's catalog, see catalog.go, from
+// their account with Purchase, which checks the price the buyer agreed to
+// against the listing, so a price change between browsing and paying fails
+// the purchase rather than charging the new price. The purchase takes one
+// item from stock and its transfer is recorded as usual, with a receipt
+// linking it to the buyer, seller and item. The Purchase event replaces the
+// transfer's typed event and embeds the receipt.
+//
+// A receipt carries the hash of its details, see ReceiptHash, so a buyer
+// can hand the details to a third party, who checks them against the
+// ledger with VerifyReceipt without reading the receipt itself.
+
+// EventPurchase replaces the typed event of a purchase's transfer
+const EventPurchase = "Purchase"
+
+// receiptObjectType keys the purchase receipts by transaction id
+const receiptObjectType = "receipt"
+
+// Receipt records a purchase
+type Receipt struct {
+	// TXID is the purchase's transaction and Transaction record
+	TXID   string `json:"txId"`
+	Buyer  string `json:"buyer"`
+	Seller string `json:"seller"`
+	ItemID string `json:"itemId"`
+	Price  int    `json:"price"`
+	// Timestamp is the proposal timestamp of the purchase, RFC 3339
+	Timestamp string `json:"timestamp"`
+	// Hash is the hex encoded ReceiptHash of the receipt
+	Hash string `json:"hash"`
+}
+
+// Purchase pays price for one of itemID of seller from the caller's
+// account, failing unless price is the item's listed price and it is in
+// stock, and returns the purchase's receipt
+func (s *SmartContract) Purchase(ctx contractapi.TransactionContextInterface, sellerID string, itemID string, price int) (*Receipt, error) {
+	err := validate(
+		validation.ID("sellerId", sellerID),
+		validation.ID("itemId", itemID),
+		validation.Amount("price", price),
+	)
+	if err != nil {
+		return nil, err
+	}
+	if err := requireInitialized(ctx); err != nil {
+		return nil, err
+	}
+	if err := requireNoConfirmation(ctx, sellerID, price); err != nil {
+		return nil, err
+	}
+
+	caller, err := callerIdentity(ctx)
+	if err != nil {
+		return nil, err
+	}
+	buyer := caller.CommonName
+	if err := requireAccountHolder(ctx, buyer, "pay from it"); err != nil {
+		return nil, err
+	}
+
+	batch := newWriteBatch(ctx)
+	itemKey, item, err := listedItem(batch, sellerID, itemID)
+	if err != nil {
+		return nil, err
+	}
+	if item.Price != price {
+		return nil, newError(CodeInvalidArgument, "item %s of %s is listed at %d, not %d", itemID, sellerID, item.Price, price)
+	}
+	if item.Stock == 0 {
+		return nil, newError(CodeInvalidArgument, "item %s of %s is out of stock", itemID, sellerID)
+	}
+	item.Stock--
+	err = batch.putState(itemKey, item)
+	if err != nil {
+		return nil, err
+	}
+
+	transaction, err := transferHelper(batch, buyer, sellerID, price, false)
+	if err != nil {
+		return nil, err
+	}
+	receipt := &Receipt{
+		TXID:      transaction.TXID,
+		Buyer:     buyer,
+		Seller:    sellerID,
+		ItemID:    itemID,
+		Price:     price,
+		Timestamp: transaction.Timestamp,
+	}
+	receipt.Hash = ReceiptHash(receipt)
+	key, err := ctx.GetStub().CreateCompositeKey(receiptObjectType, []string{receipt.TXID})
+	if err != nil {
+		return nil, err
+	}
+	err = batch.putState(key, receipt)
+	if err != nil {
+		return nil, err
+	}
+	err = emitTypedEvent(batch, EventPurchase, receipt)
+	if err != nil {
+		return nil, err
+	}
+
+	err = batch.flush()
+	if err != nil {
+		return nil, err
+	}
+
+	return receipt, nil
+}
+
+// GetReceipt returns the receipt of the purchase made by transaction id
+func (s *SmartContract) GetReceipt(ctx contractapi.TransactionContextInterface, id string) (*Receipt, error) {
+	if err := validate(validation.ID("id", id)); err != nil {
+		return nil, err
+	}
+
+	key, err := ctx.GetStub().CreateCompositeKey(receiptObjectType, []string{id})
+	if err != nil {
+		return nil, err
+	}
+	data, err := getState(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+	if data == nil {
+		return nil, newError(CodeTransactionNotFound, "no purchase %s", id)
+	}
+
+	var receipt Receipt
+	err = decodeRecord(key, data, &receipt, "txId", "buyer", "seller", "itemId", "price", "hash")
+	if err != nil {
+		return nil, err
+	}
+
+	return &receipt, nil
+}
+
+// VerifyReceipt reports whether details, the JSON encoding of a receipt,
+// are the details of the receipt of the purchase made by transaction id.
+// A hash in details is ignored.
+func (s *SmartContract) VerifyReceipt(ctx contractapi.TransactionContextInterface, id string, details string) (bool, error) {
+	if err := validate(validation.ID("id", id)); err != nil {
+		return false, err
+	}
+	decoder := json.NewDecoder(bytes.NewReader([]byte(details)))
+	decoder.DisallowUnknownFields()
+	var claimed Receipt
+	if err := decoder.Decode(&claimed); err != nil {
+		return false, validate(&validation.Error{Field: "details", Reason: err.Error()})
+	}
+
+	receipt, err := s.GetReceipt(ctx, id)
+	if err != nil {
+		return false, err
+	}
+
+	return claimed.TXID == id && ReceiptHash(&claimed) == receipt.Hash, nil
+}
+
+// ReceiptHash returns the hex encoded SHA-256 of the details of receipt,
+// its fields but Hash, each followed by 0x00 and the price in decimal
+func ReceiptHash(receipt *Receipt) string {
+	h := sha256.New()
+	for _, field := range []string{receipt.TXID, receipt.Buyer, receipt.Seller, receipt.ItemID, strconv.Itoa(receipt.Price), receipt.Timestamp} {
+		h.Write([]byte(field))
+		h.Write([]byte{0})
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
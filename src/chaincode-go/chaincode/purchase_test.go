@@ -0,0 +1,114 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package chaincode_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/kkiu1756/my_fabric/src/chaincode-go/chaincode"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPurchase(t *testing.T) {
+	l := initializedLedger(t).
+		WithAccount("alice", "user", 100).
+		WithAccount("shop", chaincode.SellerType, 0)
+	contract := &chaincode.SmartContract{}
+
+	l.WithCaller("Org1MSP", "shop", "client")
+	_, err := contract.AddItem(l.Context, "shop", "lamp", "Desk lamp", 30, 2)
+	require.NoError(t, err)
+
+	l.WithCaller("Org1MSP", "alice", "client").WithTxID("buy")
+	receipt, err := contract.Purchase(l.Context, "shop", "lamp", 30)
+	require.NoError(t, err)
+	expected := &chaincode.Receipt{TXID: "buy", Buyer: "alice", Seller: "shop", ItemID: "lamp", Price: 30, Timestamp: receipt.Timestamp}
+	expected.Hash = chaincode.ReceiptHash(expected)
+	assert.Equal(t, expected, receipt)
+	l.AssertBalance("alice", 70)
+	l.AssertBalance("shop", 30)
+	item, err := contract.GetItem(l.Context, "shop", "lamp")
+	require.NoError(t, err)
+	assert.Equal(t, 1, item.Stock, "a purchase takes one from stock")
+	l.AssertEvent(chaincode.EventPurchase, map[string]interface{}{
+		"txId": "buy", "buyer": "alice", "seller": "shop", "itemId": "lamp", "price": 30,
+		"timestamp": receipt.Timestamp, "hash": receipt.Hash,
+	})
+
+	stored, err := contract.GetReceipt(l.Context, "buy")
+	require.NoError(t, err)
+	assert.Equal(t, receipt, stored)
+	transaction, err := contract.GetTransaction(l.Context, "buy")
+	require.NoError(t, err)
+	assert.Equal(t, "alice", transaction.From)
+	assert.Equal(t, "shop", transaction.To)
+
+	_, err = contract.GetReceipt(l.Context, "gift")
+	assert.EqualError(t, err, "[TRANSACTION_NOT_FOUND] no purchase gift")
+}
+
+func TestVerifyReceipt(t *testing.T) {
+	l := adminLedger(t).
+		WithAccount("alice", "user", 100).
+		WithAccount("shop", chaincode.SellerType, 0)
+	contract := &chaincode.SmartContract{}
+	l.WithCaller("Org1MSP", "shop", "client")
+	_, err := contract.AddItem(l.Context, "shop", "lamp", "Desk lamp", 30, 1)
+	require.NoError(t, err)
+	l.WithCaller("Org1MSP", "alice", "client").WithTxID("buy")
+	receipt, err := contract.Purchase(l.Context, "shop", "lamp", 30)
+	require.NoError(t, err)
+
+	details := func(price int, hash string) string {
+		data, err := json.Marshal(&chaincode.Receipt{TXID: "buy", Buyer: "alice", Seller: "shop", ItemID: "lamp", Price: price, Timestamp: receipt.Timestamp, Hash: hash})
+		require.NoError(t, err)
+		return string(data)
+	}
+	ok, err := contract.VerifyReceipt(l.Context, "buy", details(30, ""))
+	require.NoError(t, err)
+	assert.True(t, ok)
+	ok, err = contract.VerifyReceipt(l.Context, "buy", details(3, receipt.Hash))
+	require.NoError(t, err)
+	assert.False(t, ok, "the hash in the details is not trusted")
+
+	_, err = contract.VerifyReceipt(l.Context, "buy", `{"txId":"buy","tip":1}`)
+	assert.EqualError(t, err, `[INVALID_ARGUMENT] invalid details: json: unknown field "tip"`)
+	_, err = contract.VerifyReceipt(l.Context, "sell", details(30, ""))
+	assert.EqualError(t, err, "[TRANSACTION_NOT_FOUND] no purchase sell")
+}
+
+func TestPurchaseRejects(t *testing.T) {
+	l := adminLedger(t).
+		WithAccount("alice", "user", 100).
+		WithAccount("bob", "user", 0).
+		WithAccount("shop", chaincode.SellerType, 0)
+	contract := &chaincode.SmartContract{}
+
+	l.WithCaller("Org1MSP", "shop", "client")
+	_, err := contract.AddItem(l.Context, "shop", "lamp", "Desk lamp", 30, 1)
+	require.NoError(t, err)
+	_, err = contract.UpdateItem(l.Context, "shop", "lamp", "Desk lamp", 35, 1)
+	require.NoError(t, err)
+
+	l.WithCaller("Org1MSP", "alice", "client")
+	_, err = contract.Purchase(l.Context, "shop", "lamp", 30)
+	assert.EqualError(t, err, "[INVALID_ARGUMENT] item lamp of shop is listed at 35, not 30")
+	_, err = contract.Purchase(l.Context, "shop", "chair", 30)
+	assert.EqualError(t, err, "[INVALID_ARGUMENT] shop lists no item chair")
+
+	l.WithCaller("Org1MSP", "bob", "client")
+	_, err = contract.Purchase(l.Context, "shop", "lamp", 35)
+	assert.EqualError(t, err, "[INSUFFICIENT_BALANCE] user balance lower than 35")
+	l.AssertBalance("shop", 0)
+
+	l.WithCaller("Org1MSP", "alice", "client")
+	_, err = contract.Purchase(l.Context, "shop", "lamp", 35)
+	require.NoError(t, err)
+	_, err = contract.Purchase(l.Context, "shop", "lamp", 35)
+	assert.EqualError(t, err, "[INVALID_ARGUMENT] item lamp of shop is out of stock")
+	l.AssertBalance("alice", 65)
+}
@@ -0,0 +1,124 @@
+package chaincode
+
+import (
+	"fmt"
+	"reflect"
+	"time"
+
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+	"github.com/hyperledger/fabric-protos-go/ledger/queryresult"
+	"github.com/hyperledger/fabric-protos-go/peer"
+	"github.com/kkiu1756/my_fabric/src/chaincode-go/iterate"
+	"github.com/kkiu1756/my_fabric/src/chaincode-go/validation"
+)
+
+// QueryPolicy bounds the range queries of the list methods, so no single
+// call walks the whole world state
+type QueryPolicy struct {
+	// DefaultPageSize is used when a caller passes a page size of 0
+	DefaultPageSize int
+	// MaxPageSize is the largest page a caller can ask for
+	MaxPageSize int
+	// Budget is how long a call may iterate before it returns a short page
+	// and a bookmark to continue from; 0 means no limit. The list methods
+	// are only evaluated, so the cut-off need not agree across peers.
+	Budget time.Duration
+}
+
+// DefaultQueryPolicy is the policy the contract starts with
+var DefaultQueryPolicy = QueryPolicy{DefaultPageSize: 100, MaxPageSize: 1000, Budget: 2 * time.Second}
+
+var queryPolicy = DefaultQueryPolicy
+
+// SetQueryPolicy replaces the policy applied to the list methods
+func SetQueryPolicy(p QueryPolicy) {
+	queryPolicy = p
+}
+
+// pageSize applies the policy to a requested page size
+func (p QueryPolicy) pageSize(requested int) (int, error) {
+	if requested == 0 {
+		return p.DefaultPageSize, nil
+	}
+	if requested < 0 || requested > p.MaxPageSize {
+		return 0, validate(&validation.Error{
+			Field:  "pageSize",
+			Reason: fmt.Sprintf("must be between 1 and %d, or 0 for %d", p.MaxPageSize, p.DefaultPageSize),
+		})
+	}
+
+	return requested, nil
+}
+
+// rangeQuery runs one paginated range query
+type rangeQuery func(pageSize int32, bookmark string) (shim.StateQueryIteratorInterface, *peer.QueryResponseMetadata, error)
+
+// scan calls visit for each result of one page of query, starting at
+// bookmark, and returns the bookmark of the next page, empty after the
+// last. If the budget runs out it stops early and returns a bookmark just
+// past the last key visited: range query bookmarks are start keys on both
+// LevelDB and CouchDB. At least one result is visited per call, so a
+// listing always progresses. visit may return iterate.Stop to end the
+// listing. name identifies the range in errors.
+func (p QueryPolicy) scan(query rangeQuery, name string, pageSize int, bookmark string, visit func(*queryresult.KV) error) (string, error) {
+	start := time.Now()
+	last := ""
+	stopped, ended := false, false
+	metadata, err := iterate.Page(func() (shim.StateQueryIteratorInterface, *peer.QueryResponseMetadata, error) {
+		return query(int32(pageSize), bookmark)
+	}, func(kv *queryresult.KV) error {
+		if last != "" && p.Budget > 0 && time.Since(start) > p.Budget {
+			stopped = true
+			return iterate.Stop
+		}
+		err := visit(kv)
+		if err == iterate.Stop {
+			ended = true
+		}
+		if err != nil {
+			return err
+		}
+		last = kv.Key
+		return nil
+	})
+	if err != nil {
+		return "", queryError(name, err)
+	}
+
+	if stopped {
+		return last + "\x00", nil
+	}
+	if ended || int(metadata.GetFetchedRecordsCount()) < pageSize {
+		return "", nil
+	}
+	return metadata.GetBookmark(), nil
+}
+
+// queryError turns a failure of the peer to run the query of the range
+// name into a StateError and returns other errors as they are
+func queryError(name string, err error) error {
+	if queryErr, ok := err.(*iterate.Error); ok {
+		return &StateError{Op: OpQueryState, Key: name, Err: queryErr.Err}
+	}
+
+	return err
+}
+
+// forEachRecord decodes each state whose composite key starts with
+// objectType and attributes into record, zeroed first, requiring fields
+// like decodeRecord, and calls visit with its key. visit may return
+// iterate.Stop. name identifies the range in errors.
+func forEachRecord(ctx contractapi.TransactionContextInterface, name string, objectType string, attributes []string, record interface{}, fields []string, visit func(key string) error) error {
+	value := reflect.ValueOf(record).Elem()
+	err := iterate.PartialCompositeKey(ctx.GetStub(), objectType, attributes, func(kv *queryresult.KV) error {
+		value.Set(reflect.Zero(value.Type()))
+		err := decodeRecord(kv.Key, kv.Value, record, fields...)
+		if err != nil {
+			return err
+		}
+		return visit(kv.Key)
+	})
+
+	return queryError(name, err)
+}
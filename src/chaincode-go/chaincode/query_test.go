@@ -0,0 +1,75 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package chaincode_test
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/kkiu1756/my_fabric/src/chaincode-go/chaincode"
+	"github.com/kkiu1756/my_fabric/src/chaincode-go/tokentest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func setQueryPolicy(t *testing.T, p chaincode.QueryPolicy) {
+	chaincode.SetQueryPolicy(p)
+	t.Cleanup(func() { chaincode.SetQueryPolicy(chaincode.DefaultQueryPolicy) })
+}
+
+func TestQueryPolicyPageSize(t *testing.T) {
+	setQueryPolicy(t, chaincode.QueryPolicy{DefaultPageSize: 2, MaxPageSize: 3})
+	l := tokentest.NewLedger(t)
+	for i := 0; i < 5; i++ {
+		l.WithAccount(fmt.Sprintf("user%d", i), "user", i)
+	}
+	paginate(l.Stub, l.State)
+	contract := &chaincode.SmartContract{}
+
+	page, err := contract.ListAccounts(l.Context, 0, 0, "")
+	require.NoError(t, err)
+	assert.Len(t, page.Accounts, 2, "0 should use the default page size")
+	assert.Equal(t, "user2", page.Bookmark)
+
+	page, err = contract.ListAccounts(l.Context, 0, 3, page.Bookmark)
+	require.NoError(t, err)
+	assert.Len(t, page.Accounts, 3)
+	assert.Empty(t, page.Bookmark, "a full last page should end the listing")
+
+	for _, size := range []int{-1, 4} {
+		_, err = contract.ListAccounts(l.Context, 0, size, "")
+		assert.EqualError(t, err, "[INVALID_ARGUMENT] invalid pageSize: must be between 1 and 3, or 0 for 2")
+		_, err = contract.FindKeyCollisions(l.Context, size, "")
+		assert.EqualError(t, err, "[INVALID_ARGUMENT] invalid pageSize: must be between 1 and 3, or 0 for 2")
+	}
+}
+
+func TestQueryPolicyBudget(t *testing.T) {
+	setQueryPolicy(t, chaincode.QueryPolicy{DefaultPageSize: 10, MaxPageSize: 10, Budget: time.Nanosecond})
+	l := tokentest.NewLedger(t)
+	for i := 0; i < 3; i++ {
+		l.WithAccount(fmt.Sprintf("user%d", i), "user", i)
+	}
+	paginate(l.Stub, l.State)
+	contract := &chaincode.SmartContract{}
+
+	var ids []string
+	bookmark := ""
+	for calls := 1; ; calls++ {
+		require.LessOrEqual(t, calls, 3, "every call should visit at least one record")
+		page, err := contract.ListAccounts(l.Context, 0, 0, bookmark)
+		require.NoError(t, err)
+		for _, user := range page.Accounts {
+			ids = append(ids, user.ID)
+		}
+		if page.Bookmark == "" {
+			break
+		}
+		assert.Len(t, page.Accounts, 1, "a spent budget should cut the page short")
+		bookmark = page.Bookmark
+	}
+	assert.Equal(t, []string{"user0", "user1", "user2"}, ids, "short pages should resume after the last account")
+}
@@ -0,0 +1,232 @@
+package chaincode
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+	"github.com/hyperledger/fabric-protos-go/ledger/queryresult"
+	"github.com/hyperledger/fabric-protos-go/peer"
+	"github.com/kkiu1756/my_fabric/src/chaincode-go/validation"
+)
+
+// Rebase scales every balance by a factor without rewriting the accounts.
+// Each rebase starts a generation and records the product of all factors
+// so far, and an account record stores the generation its balance is in.
+// Reading an account scales its balance by the factors since, rounded
+// down, and writing it stores the scaled balance in the current
+// generation. Like a demurrage fee, see demurrage.go, the scaling is
+// settled lazily: GetUser, GetAccount, ListAccounts and TotalSupply report
+// scaled balances, and the change becomes a statement line of type
+// LineRebase when the account is next written. Credits pending on a hot
+// account are added unscaled when they are pruned. The escrow account is
+// not rebased, as the reversible transfers, disputes and gift cards it
+// holds pay out their nominal value.
+
+// EventRebased is the event of a rebase
+const EventRebased = "Rebased"
+
+// LineRebase is the type of the statement line of a settled rebase
+const LineRebase = "rebase"
+
+// rebaseObjectType keys the rebase history by generation
+const rebaseObjectType = "rebase"
+
+// RebaseFactor is a rebase
+type RebaseFactor struct {
+	// Generation counts the rebases up to this one
+	Generation  int `json:"generation"`
+	Numerator   int `json:"numerator"`
+	Denominator int `json:"denominator"`
+	// Cumulative is the product of the factors of every rebase up to this
+	// one, as a fraction "n/d" or an integer "n"
+	Cumulative string `json:"cumulative"`
+	TXID       string `json:"txId"`
+	// Timestamp is the proposal timestamp of the rebase, RFC 3339
+	Timestamp string `json:"timestamp"`
+}
+
+// RebasePage is one page of the rebase history
+type RebasePage struct {
+	Rebases []*RebaseFactor `json:"rebases"`
+	// Bookmark continues the listing, empty after the last page
+	Bookmark string `json:"bookmark"`
+}
+
+// Rebase scales every balance by factorNumerator/factorDenominator. Only
+// an org admin can call it.
+func (s *SmartContract) Rebase(ctx contractapi.TransactionContextInterface, factorNumerator int, factorDenominator int) (*RebaseFactor, error) {
+	errs := []error{
+		validation.Amount("factorNumerator", factorNumerator),
+		validation.Amount("factorDenominator", factorDenominator),
+	}
+	if factorNumerator == 0 {
+		errs = append(errs, &validation.Error{Field: "factorNumerator", Reason: "must be positive"})
+	}
+	if factorDenominator == 0 {
+		errs = append(errs, &validation.Error{Field: "factorDenominator", Reason: "must be positive"})
+	}
+	if err := validate(errs...); err != nil {
+		return nil, err
+	}
+	if err := requireInitialized(ctx); err != nil {
+		return nil, err
+	}
+	if _, err := requireAdmin(ctx, "rebase"); err != nil {
+		return nil, err
+	}
+
+	init, err := ledgerConfig(ctx)
+	if err != nil {
+		return nil, err
+	}
+	cumulative := big.NewRat(1, 1)
+	generation := 0
+	if init.Rebase != nil {
+		cumulative, err = cumulativeFactor(init.Rebase)
+		if err != nil {
+			return nil, err
+		}
+		generation = init.Rebase.Generation
+	}
+	cumulative.Mul(cumulative, big.NewRat(int64(factorNumerator), int64(factorDenominator)))
+
+	stub := ctx.GetStub()
+	timestamp, err := txTimestamp(stub)
+	if err != nil {
+		return nil, err
+	}
+	rebase := &RebaseFactor{
+		Generation:  generation + 1,
+		Numerator:   factorNumerator,
+		Denominator: factorDenominator,
+		Cumulative:  cumulative.RatString(),
+		TXID:        stub.GetTxID(),
+		Timestamp:   timestamp,
+	}
+	key, err := rebaseKey(ctx, rebase.Generation)
+	if err != nil {
+		return nil, err
+	}
+	err = putState(ctx, key, rebase)
+	if err != nil {
+		return nil, err
+	}
+	init.Rebase = rebase
+	err = putState(ctx, InitializationKey, init)
+	if err != nil {
+		return nil, err
+	}
+	err = emitEvent(ctx, EventRebased, rebase)
+	if err != nil {
+		return nil, err
+	}
+
+	return rebase, nil
+}
+
+// GetRebaseHistory returns a page of the rebases, oldest first
+func (s *SmartContract) GetRebaseHistory(ctx contractapi.TransactionContextInterface, pageSize int, bookmark string) (*RebasePage, error) {
+	pageSize, err := queryPolicy.pageSize(pageSize)
+	if err != nil {
+		return nil, err
+	}
+
+	stub := ctx.GetStub()
+	query := func(pageSize int32, bookmark string) (shim.StateQueryIteratorInterface, *peer.QueryResponseMetadata, error) {
+		return stub.GetStateByPartialCompositeKeyWithPagination(rebaseObjectType, []string{}, pageSize, bookmark)
+	}
+
+	page := &RebasePage{Rebases: []*RebaseFactor{}}
+	page.Bookmark, err = queryPolicy.scan(query, rebaseObjectType, pageSize, bookmark, func(kv *queryresult.KV) error {
+		var rebase RebaseFactor
+		if err := decodeRecord(kv.Key, kv.Value, &rebase, "generation", "numerator", "denominator", "cumulative"); err != nil {
+			return err
+		}
+		page.Rebases = append(page.Rebases, &rebase)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return page, nil
+}
+
+// rebaseUser scales the balance of user, read from its record, to the
+// current generation and notes the change for putUser to index
+func rebaseUser(ctx contractapi.TransactionContextInterface, user *User) error {
+	init, err := ledgerConfig(ctx)
+	if err != nil || init == nil || init.Rebase == nil || user.Rebase == init.Rebase.Generation {
+		return err
+	}
+	if user.ID == EscrowAccount {
+		user.Rebase = init.Rebase.Generation
+		return nil
+	}
+
+	current, err := cumulativeFactor(init.Rebase)
+	if err != nil {
+		return err
+	}
+	factor := new(big.Rat).Set(current)
+	if user.Rebase > 0 {
+		key, err := rebaseKey(ctx, user.Rebase)
+		if err != nil {
+			return err
+		}
+		data, err := getState(ctx, key)
+		if err != nil {
+			return err
+		}
+		var since RebaseFactor
+		err = decodeRecord(key, data, &since, "generation", "cumulative")
+		if err != nil {
+			return err
+		}
+		from, err := cumulativeFactor(&since)
+		if err != nil {
+			return err
+		}
+		factor.Quo(factor, from)
+	}
+
+	scaled := new(big.Int).Mul(big.NewInt(int64(user.Balance)), factor.Num())
+	scaled.Quo(scaled, factor.Denom())
+	if !scaled.IsInt64() || scaled.Int64() > int64(maxInt) || scaled.Int64() < int64(minInt) {
+		return newError(CodeBalanceOverflow, "cannot rebase %s: balance overflow", user.ID)
+	}
+	balance := int(scaled.Int64())
+	user.rebased += balance - user.Balance
+	user.Balance = balance
+	user.Rebase = init.Rebase.Generation
+
+	return nil
+}
+
+// currentRebase returns the current generation, 0 before the first rebase
+func currentRebase(ctx contractapi.TransactionContextInterface) (int, error) {
+	init, err := ledgerConfig(ctx)
+	if err != nil || init == nil || init.Rebase == nil {
+		return 0, err
+	}
+
+	return init.Rebase.Generation, nil
+}
+
+// cumulativeFactor parses the cumulative factor of rebase
+func cumulativeFactor(rebase *RebaseFactor) (*big.Rat, error) {
+	factor, ok := new(big.Rat).SetString(rebase.Cumulative)
+	if !ok || factor.Sign() <= 0 {
+		return nil, corrupt(fmt.Sprintf("rebase %d", rebase.Generation), fmt.Errorf("invalid cumulative factor %q", rebase.Cumulative))
+	}
+
+	return factor, nil
+}
+
+// rebaseKey returns the key of the rebase of generation, zero padded so
+// the history lists in order
+func rebaseKey(ctx contractapi.TransactionContextInterface, generation int) (string, error) {
+	return ctx.GetStub().CreateCompositeKey(rebaseObjectType, []string{fmt.Sprintf("%010d", generation)})
+}
@@ -0,0 +1,117 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package chaincode_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+	"github.com/kkiu1756/my_fabric/src/chaincode-go/chaincode"
+	"github.com/kkiu1756/my_fabric/src/chaincode-go/validation"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRebase(t *testing.T) {
+	l := adminLedger(t).
+		WithAccount("alice", "user", 1001).
+		WithAccount("bob", "user", 0).
+		WithTimestamp(demurrageTime)
+	paginate(l.Stub, l.State)
+	l.Stub.GetStateByPartialCompositeKeyStub = func(objectType string, attributes []string) (shim.StateQueryIteratorInterface, error) {
+		it, _, err := l.Stub.GetStateByPartialCompositeKeyWithPagination(objectType, attributes, 1000, "")
+		return it, err
+	}
+	contract := &chaincode.SmartContract{}
+
+	l.WithTxID("rebase1")
+	rebase, err := contract.Rebase(l.Context, 3, 2)
+	require.NoError(t, err)
+	assert.Equal(t, 1, rebase.Generation)
+	assert.Equal(t, "3/2", rebase.Cumulative)
+	l.AssertEvent(chaincode.EventRebased, map[string]interface{}{
+		"generation": 1, "numerator": 3, "denominator": 2, "cumulative": "3/2", "txId": "rebase1", "timestamp": rebase.Timestamp,
+	})
+
+	writes := l.Stub.PutStateCallCount()
+	user, err := contract.GetUser(l.Context, "alice")
+	require.NoError(t, err)
+	assert.Equal(t, 1501, user.Balance, "a rebase should round down")
+	l.AssertBalance("alice", 1001)
+	assert.Equal(t, writes, l.Stub.PutStateCallCount(), "a read should settle nothing")
+
+	l.WithTxID("pay")
+	_, err = contract.TransferFrom(l.Context, "alice", "bob", 501)
+	require.NoError(t, err)
+	l.AssertBalance("alice", 1000)
+	l.AssertBalance("bob", 501)
+
+	statement, err := contract.GenerateStatement(l.Context, "alice", "2021-01-01", "2030-12-31")
+	require.NoError(t, err)
+	require.Len(t, statement.Lines, 2)
+	assert.Equal(t, chaincode.LineRebase, statement.Lines[1].Type)
+	assert.Equal(t, 500, statement.Lines[1].Amount)
+
+	l.WithTxID("rebase2")
+	_, err = contract.Rebase(l.Context, 1, 3)
+	require.NoError(t, err)
+	for id, expected := range map[string]int{"alice": 333, "bob": 167} {
+		user, err := contract.GetUser(l.Context, id)
+		require.NoError(t, err)
+		assert.Equal(t, expected, user.Balance, id)
+	}
+
+	history, err := contract.GetRebaseHistory(l.Context, 0, "")
+	require.NoError(t, err)
+	require.Len(t, history.Rebases, 2)
+	assert.Equal(t, "rebase1", history.Rebases[0].TXID)
+	assert.Equal(t, "1/2", history.Rebases[1].Cumulative)
+}
+
+func TestRebaseSkipsEscrow(t *testing.T) {
+	for _, factor := range [][2]int{{1, 2}, {2, 1}} {
+		l := reversibleLedger(t)
+		contract := &chaincode.SmartContract{}
+		_, err := contract.Rebase(l.Context, factor[0], factor[1])
+		require.NoError(t, err)
+
+		l.WithTimestamp(reversibleTime.Add(24 * time.Hour)).WithTxID("claim")
+		_, err = contract.ClaimTransfer(l.Context, "send")
+		require.NoError(t, err, factor)
+		l.AssertTransaction("claim", chaincode.EscrowAccount, "bob", 30)
+		escrow, err := contract.GetUser(l.Context, chaincode.EscrowAccount)
+		require.NoError(t, err)
+		assert.Equal(t, 0, escrow.Balance, factor)
+		bob, err := contract.GetUser(l.Context, "bob")
+		require.NoError(t, err)
+		assert.Equal(t, 30, bob.Balance, factor)
+	}
+}
+
+func TestRebaseAboveMaxAmount(t *testing.T) {
+	l := adminLedger(t).WithAccount("alice", "user", validation.MaxAmount)
+	contract := &chaincode.SmartContract{}
+	_, err := contract.Rebase(l.Context, 2, 1)
+	require.NoError(t, err)
+
+	user, err := contract.GetUser(l.Context, "alice")
+	require.NoError(t, err)
+	assert.Equal(t, 2*validation.MaxAmount, user.Balance)
+}
+
+func TestRebaseRejects(t *testing.T) {
+	l := adminLedger(t)
+	contract := &chaincode.SmartContract{}
+
+	_, err := contract.Rebase(l.Context, 0, 2)
+	assert.EqualError(t, err, "[INVALID_ARGUMENT] invalid factorNumerator: must be positive")
+	_, err = contract.Rebase(l.Context, 3, -2)
+	assert.EqualError(t, err, "[INVALID_ARGUMENT] invalid factorDenominator: must not be negative")
+
+	l.WithCaller("Org1MSP", "alice", "client")
+	_, err = contract.Rebase(l.Context, 3, 2)
+	assert.EqualError(t, err, "[UNAUTHORIZED] only an organization admin can rebase")
+}
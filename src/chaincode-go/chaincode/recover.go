@@ -0,0 +1,41 @@
+package chaincode
+
+import (
+	"fmt"
+	"runtime/debug"
+
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+	"github.com/hyperledger/fabric-protos-go/peer"
+)
+
+// Recover wraps cc so a panic in any transaction, for example on a record
+// the contract does not expect, fails that transaction with INTERNAL and
+// logs the stack instead of crashing the chaincode process. The peer
+// discards the failed transaction's writes.
+func Recover(cc shim.Chaincode) shim.Chaincode {
+	return recovering{cc}
+}
+
+type recovering struct {
+	cc shim.Chaincode
+}
+
+func (r recovering) Init(stub shim.ChaincodeStubInterface) (resp peer.Response) {
+	defer recoverTransaction(stub, &resp)
+	return r.cc.Init(stub)
+}
+
+func (r recovering) Invoke(stub shim.ChaincodeStubInterface) (resp peer.Response) {
+	defer recoverTransaction(stub, &resp)
+	return r.cc.Invoke(stub)
+}
+
+func recoverTransaction(stub shim.ChaincodeStubInterface, resp *peer.Response) {
+	p := recover()
+	if p == nil {
+		return
+	}
+
+	stubLogger(stub).Error("transaction panicked", "panic", fmt.Sprint(p), "stack", string(debug.Stack()))
+	*resp = shim.Error(newError(CodeInternal, "transaction %s failed: internal error", stub.GetTxID()).Error())
+}
@@ -0,0 +1,50 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package chaincode_test
+
+import (
+	"log/slog"
+	"testing"
+
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+	"github.com/kkiu1756/my_fabric/src/chaincode-go/chaincode"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRecover(t *testing.T) {
+	buf := captureLogs(t, slog.LevelInfo)
+
+	cc, err := contractapi.NewChaincode(&chaincode.SmartContract{})
+	require.NoError(t, err)
+
+	state := ledger(t)
+	_, stub := newContext(state)
+	stub.GetFunctionAndParametersReturns("GetUser", []string{"alice"})
+	stub.GetStateStub = func(key string) ([]byte, error) {
+		panic("unexpected record")
+	}
+
+	resp := chaincode.Recover(cc).Invoke(stub)
+	assert.Equal(t, int32(shim.ERROR), resp.Status)
+	assert.Equal(t, "[INTERNAL] transaction tx1 failed: internal error", resp.Message)
+
+	lines := logLines(t, buf)
+	require.Len(t, lines, 1)
+	assert.Equal(t, "ERROR", lines[0]["level"])
+	assert.Equal(t, "transaction panicked", lines[0]["msg"])
+	assert.Equal(t, "unexpected record", lines[0]["panic"])
+	assert.Equal(t, "tx1", lines[0]["txid"])
+	assert.Equal(t, "GetUser", lines[0]["method"])
+	assert.Contains(t, lines[0]["stack"], "chaincode_test.TestRecover")
+
+	stub.GetStateStub = func(key string) ([]byte, error) {
+		return state[key], nil
+	}
+	resp = chaincode.Recover(cc).Invoke(stub)
+	assert.Equal(t, int32(shim.OK), resp.Status, "transactions that do not panic should pass through: %s", resp.Message)
+	assert.JSONEq(t, `{"userId":"alice","type":"user","balance":100}`, string(resp.Payload))
+}
@@ -0,0 +1,477 @@
+package chaincode
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/hyperledger/fabric-chaincode-go/pkg/cid"
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+	"github.com/kkiu1756/my_fabric/src/chaincode-go/validation"
+)
+
+// An account is held by the client whose certificate common name is its
+// id, until a recovery binds it to another identity. The holder names
+// guardians with SetGuardians. Guardians who lose touch with the holder
+// approve the binding of the account to a new identity with
+// ApproveRecovery; once the threshold of them agree the recovery can be
+// completed with CompleteRecovery after the guardian set's delay, during
+// which the holder can still stop it with CancelRecovery. Each step emits
+// a recovery event with the Recovery as payload.
+
+// Recovery events
+const (
+	EventGuardiansSet      = "GuardiansSet"
+	EventRecoveryApproved  = "RecoveryApproved"
+	EventRecoveryCompleted = "RecoveryCompleted"
+	EventRecoveryCancelled = "RecoveryCancelled"
+)
+
+// MaxGuardians is the most guardians an account can name
+const MaxGuardians = 10
+
+// Object types of the recovery records, keyed by account
+const (
+	holderObjectType   = "holder"
+	guardianObjectType = "guardians"
+	recoveryObjectType = "recovery"
+)
+
+// Identity names a client by MSP and certificate common name
+type Identity struct {
+	MSPID      string `json:"mspId"`
+	CommonName string `json:"commonName"`
+}
+
+// GuardianSet lists the guardians of an account
+type GuardianSet struct {
+	Account   string     `json:"account"`
+	Guardians []Identity `json:"guardians"`
+	// Threshold is how many guardians must approve a recovery
+	Threshold int `json:"threshold"`
+	// Delay is how long, a Go duration, a recovery waits after reaching the
+	// threshold before it can be completed
+	Delay string `json:"delay"`
+}
+
+// Recovery is the binding of an account to a new identity, underway
+type Recovery struct {
+	Account   string     `json:"account"`
+	NewHolder Identity   `json:"newHolder"`
+	Approvals []Identity `json:"approvals"`
+	// ReadyAt is when the recovery can be completed, an RFC 3339 time,
+	// empty until the threshold of guardians approved it
+	ReadyAt string `json:"readyAt,omitempty" metadata:"readyAt,optional"`
+}
+
+// callerIdentity returns the client's Identity
+func callerIdentity(ctx contractapi.TransactionContextInterface) (Identity, error) {
+	caller, err := cid.New(ctx.GetStub())
+	if err != nil {
+		return Identity{}, wrapError(CodeUnauthorized, err, "cannot read client identity")
+	}
+	mspID, err := caller.GetMSPID()
+	if err != nil {
+		return Identity{}, wrapError(CodeUnauthorized, err, "cannot read client identity")
+	}
+	cert, err := caller.GetX509Certificate()
+	if err != nil || cert == nil {
+		return Identity{}, newError(CodeUnauthorized, "cannot read client certificate")
+	}
+
+	return Identity{MSPID: mspID, CommonName: cert.Subject.CommonName}, nil
+}
+
+// accountHolder returns the identity account is bound to by a recovery,
+// nil if it was never recovered
+func accountHolder(batch *writeBatch, account string) (*Identity, error) {
+	key, err := batch.ctx.GetStub().CreateCompositeKey(holderObjectType, []string{account})
+	if err != nil {
+		return nil, err
+	}
+	data, err := batch.getState(key)
+	if err != nil || data == nil {
+		return nil, err
+	}
+
+	var holder Identity
+	err = decodeRecord(key, data, &holder, "mspId", "commonName")
+	if err != nil {
+		return nil, err
+	}
+
+	return &holder, nil
+}
+
+// requireAccountHolder fails with UNAUTHORIZED unless the client holds
+// account. action completes the error message "only the holder of ... can
+// ...".
+func requireAccountHolder(ctx contractapi.TransactionContextInterface, account string, action string) error {
+	if !isAccountHolder(ctx, account) {
+		return newError(CodeUnauthorized, "only the holder of %s can %s", account, action)
+	}
+
+	return nil
+}
+
+// SetGuardians names the guardians of account, a JSON array of identities,
+// threshold of whom must approve a recovery, which then waits delay, a Go
+// duration such as "72h". It replaces the guardians of a recovery underway,
+// and drops the recovery. Only the account holder can call it.
+func (s *SmartContract) SetGuardians(ctx contractapi.TransactionContextInterface, account string, guardiansJSON string, threshold int, delay string) (*GuardianSet, error) {
+	if err := validate(validation.ID("account", account)); err != nil {
+		return nil, err
+	}
+	guardians, err := decodeGuardians(guardiansJSON)
+	if err != nil {
+		return nil, err
+	}
+	errs := []error{}
+	if threshold < 1 || threshold > len(guardians) {
+		errs = append(errs, &validation.Error{Field: "threshold", Reason: fmt.Sprintf("must be between 1 and %d", len(guardians))})
+	}
+	if d, err := time.ParseDuration(delay); err != nil || d <= 0 {
+		errs = append(errs, &validation.Error{Field: "delay", Reason: "must be a positive duration such as 72h"})
+	}
+	if err := validate(errs...); err != nil {
+		return nil, err
+	}
+	if err := requireInitialized(ctx); err != nil {
+		return nil, err
+	}
+
+	batch := newWriteBatch(ctx)
+	if _, err := batch.getUser(account); err != nil {
+		return nil, err
+	}
+	if err := requireAccountHolder(ctx, account, "set its guardians"); err != nil {
+		return nil, err
+	}
+
+	stub := ctx.GetStub()
+	set := &GuardianSet{Account: account, Guardians: guardians, Threshold: threshold, Delay: delay}
+	key, err := stub.CreateCompositeKey(guardianObjectType, []string{account})
+	if err != nil {
+		return nil, err
+	}
+	err = batch.putState(key, set)
+	if err != nil {
+		return nil, err
+	}
+	recoveryKey, err := stub.CreateCompositeKey(recoveryObjectType, []string{account})
+	if err != nil {
+		return nil, err
+	}
+	batch.delState(recoveryKey)
+
+	err = batch.flush()
+	if err != nil {
+		return nil, err
+	}
+	err = emitEvent(ctx, EventGuardiansSet, set)
+	if err != nil {
+		return nil, err
+	}
+
+	return set, nil
+}
+
+// decodeGuardians strictly decodes and validates a SetGuardians list
+func decodeGuardians(guardiansJSON string) ([]Identity, error) {
+	decoder := json.NewDecoder(bytes.NewReader([]byte(guardiansJSON)))
+	decoder.DisallowUnknownFields()
+
+	var guardians []Identity
+	if err := decoder.Decode(&guardians); err != nil {
+		return nil, validate(&validation.Error{Field: "guardians", Reason: err.Error()})
+	}
+	if len(guardians) == 0 || len(guardians) > MaxGuardians {
+		return nil, validate(&validation.Error{Field: "guardians", Reason: fmt.Sprintf("must hold between 1 and %d guardians", MaxGuardians)})
+	}
+
+	seen := make(map[Identity]bool, len(guardians))
+	for i, guardian := range guardians {
+		field := fmt.Sprintf("guardians[%d]", i)
+		err := validate(
+			validation.ID(field+".mspId", guardian.MSPID),
+			validation.ID(field+".commonName", guardian.CommonName),
+		)
+		if err != nil {
+			return nil, err
+		}
+		if seen[guardian] {
+			return nil, validate(&validation.Error{Field: field, Reason: "duplicate guardian"})
+		}
+		seen[guardian] = true
+	}
+
+	return guardians, nil
+}
+
+// GetGuardians returns the guardians of account, none if it named none
+func (s *SmartContract) GetGuardians(ctx contractapi.TransactionContextInterface, account string) (*GuardianSet, error) {
+	if err := validate(validation.ID("account", account)); err != nil {
+		return nil, err
+	}
+
+	set, err := guardianSet(newWriteBatch(ctx), account)
+	if err != nil || set != nil {
+		return set, err
+	}
+
+	return &GuardianSet{Account: account, Guardians: []Identity{}}, nil
+}
+
+// guardianSet reads the guardians of account, nil if it named none
+func guardianSet(batch *writeBatch, account string) (*GuardianSet, error) {
+	key, err := batch.ctx.GetStub().CreateCompositeKey(guardianObjectType, []string{account})
+	if err != nil {
+		return nil, err
+	}
+	data, err := batch.getState(key)
+	if err != nil || data == nil {
+		return nil, err
+	}
+
+	var set GuardianSet
+	err = decodeRecord(key, data, &set, "account", "guardians", "threshold", "delay")
+	if err != nil {
+		return nil, err
+	}
+
+	return &set, nil
+}
+
+// GetRecovery returns the recovery of account underway, with no approvals
+// if there is none
+func (s *SmartContract) GetRecovery(ctx contractapi.TransactionContextInterface, account string) (*Recovery, error) {
+	if err := validate(validation.ID("account", account)); err != nil {
+		return nil, err
+	}
+
+	_, recovery, err := accountRecovery(newWriteBatch(ctx), account)
+	if err != nil || recovery != nil {
+		return recovery, err
+	}
+
+	return &Recovery{Account: account, Approvals: []Identity{}}, nil
+}
+
+// accountRecovery reads the recovery of account underway, nil if there is
+// none, and returns its key
+func accountRecovery(batch *writeBatch, account string) (string, *Recovery, error) {
+	key, err := batch.ctx.GetStub().CreateCompositeKey(recoveryObjectType, []string{account})
+	if err != nil {
+		return "", nil, err
+	}
+	data, err := batch.getState(key)
+	if err != nil || data == nil {
+		return key, nil, err
+	}
+
+	var recovery Recovery
+	err = decodeRecord(key, data, &recovery, "account", "newHolder", "approvals")
+	if err != nil {
+		return "", nil, err
+	}
+
+	return key, &recovery, nil
+}
+
+// ApproveRecovery approves binding account to the client of newMSPID whose
+// certificate common name is newCommonName. The first approval starts the
+// recovery; the one reaching the threshold starts its delay. Only a
+// guardian of the account can call it.
+func (s *SmartContract) ApproveRecovery(ctx contractapi.TransactionContextInterface, account string, newMSPID string, newCommonName string) (*Recovery, error) {
+	err := validate(
+		validation.ID("account", account),
+		validation.ID("newMspId", newMSPID),
+		validation.ID("newCommonName", newCommonName),
+	)
+	if err != nil {
+		return nil, err
+	}
+	if err := requireInitialized(ctx); err != nil {
+		return nil, err
+	}
+
+	batch := newWriteBatch(ctx)
+	set, err := guardianSet(batch, account)
+	if err != nil {
+		return nil, err
+	}
+	caller, err := callerIdentity(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if set == nil || !containsIdentity(set.Guardians, caller) {
+		return nil, newError(CodeUnauthorized, "only a guardian of %s can approve its recovery", account)
+	}
+
+	key, recovery, err := accountRecovery(batch, account)
+	if err != nil {
+		return nil, err
+	}
+	newHolder := Identity{MSPID: newMSPID, CommonName: newCommonName}
+	if recovery == nil {
+		recovery = &Recovery{Account: account, NewHolder: newHolder, Approvals: []Identity{}}
+	}
+	if recovery.NewHolder != newHolder {
+		return nil, newError(CodeInvalidArgument, "a recovery of %s to %s/%s is underway, its holder must cancel it first",
+			account, recovery.NewHolder.MSPID, recovery.NewHolder.CommonName)
+	}
+	if containsIdentity(recovery.Approvals, caller) {
+		return nil, newError(CodeInvalidArgument, "%s/%s already approved the recovery of %s", caller.MSPID, caller.CommonName, account)
+	}
+	recovery.Approvals = append(recovery.Approvals, caller)
+
+	if len(recovery.Approvals) == set.Threshold {
+		readyAt, err := recoveryReadyAt(ctx, set)
+		if err != nil {
+			return nil, err
+		}
+		recovery.ReadyAt = readyAt
+	}
+	err = batch.putState(key, recovery)
+	if err != nil {
+		return nil, err
+	}
+
+	err = batch.flush()
+	if err != nil {
+		return nil, err
+	}
+	err = emitEvent(ctx, EventRecoveryApproved, recovery)
+	if err != nil {
+		return nil, err
+	}
+
+	return recovery, nil
+}
+
+// recoveryReadyAt returns the proposal timestamp plus the set's delay
+func recoveryReadyAt(ctx contractapi.TransactionContextInterface, set *GuardianSet) (string, error) {
+	now, err := recoveryTime(ctx)
+	if err != nil {
+		return "", err
+	}
+	delay, err := time.ParseDuration(set.Delay)
+	if err != nil {
+		return "", corrupt(set.Account, err)
+	}
+
+	return now.Add(delay).Format(time.RFC3339Nano), nil
+}
+
+// recoveryTime returns the proposal timestamp, which a recovery needs
+func recoveryTime(ctx contractapi.TransactionContextInterface) (time.Time, error) {
+	timestamp, err := txTimestamp(ctx.GetStub())
+	if err != nil {
+		return time.Time{}, err
+	}
+	now, err := time.Parse(time.RFC3339Nano, timestamp)
+	if err != nil {
+		return time.Time{}, newError(CodeInvalidArgument, "a recovery needs the proposal timestamp")
+	}
+
+	return now, nil
+}
+
+// CompleteRecovery binds account to the new holder of its recovery, once
+// the threshold of guardians approved it and its delay has passed. Anyone
+// can call it.
+func (s *SmartContract) CompleteRecovery(ctx contractapi.TransactionContextInterface, account string) (*Recovery, error) {
+	if err := validate(validation.ID("account", account)); err != nil {
+		return nil, err
+	}
+	if err := requireInitialized(ctx); err != nil {
+		return nil, err
+	}
+
+	batch := newWriteBatch(ctx)
+	key, recovery, err := accountRecovery(batch, account)
+	if err != nil {
+		return nil, err
+	}
+	if recovery == nil {
+		return nil, newError(CodeInvalidArgument, "no recovery of %s is underway", account)
+	}
+	if recovery.ReadyAt == "" {
+		return nil, newError(CodeUnauthorized, "the recovery of %s has %d approvals, fewer than its guardians' threshold", account, len(recovery.Approvals))
+	}
+	now, err := recoveryTime(ctx)
+	if err != nil {
+		return nil, err
+	}
+	readyAt, err := time.Parse(time.RFC3339Nano, recovery.ReadyAt)
+	if err != nil {
+		return nil, corrupt(key, err)
+	}
+	if now.Before(readyAt) {
+		return nil, newError(CodeUnauthorized, "the recovery of %s can be completed from %s", account, recovery.ReadyAt)
+	}
+
+	holderKey, err := ctx.GetStub().CreateCompositeKey(holderObjectType, []string{account})
+	if err != nil {
+		return nil, err
+	}
+	err = batch.putState(holderKey, recovery.NewHolder)
+	if err != nil {
+		return nil, err
+	}
+	batch.delState(key)
+
+	err = batch.flush()
+	if err != nil {
+		return nil, err
+	}
+	err = emitEvent(ctx, EventRecoveryCompleted, recovery)
+	if err != nil {
+		return nil, err
+	}
+
+	return recovery, nil
+}
+
+// CancelRecovery stops the recovery of account underway. Only the account
+// holder can call it.
+func (s *SmartContract) CancelRecovery(ctx contractapi.TransactionContextInterface, account string) error {
+	if err := validate(validation.ID("account", account)); err != nil {
+		return err
+	}
+	if err := requireInitialized(ctx); err != nil {
+		return err
+	}
+	if err := requireAccountHolder(ctx, account, "cancel its recovery"); err != nil {
+		return err
+	}
+
+	batch := newWriteBatch(ctx)
+	key, recovery, err := accountRecovery(batch, account)
+	if err != nil {
+		return err
+	}
+	if recovery == nil {
+		return newError(CodeInvalidArgument, "no recovery of %s is underway", account)
+	}
+	batch.delState(key)
+
+	err = batch.flush()
+	if err != nil {
+		return err
+	}
+
+	return emitEvent(ctx, EventRecoveryCancelled, recovery)
+}
+
+// containsIdentity reports whether identities holds identity
+func containsIdentity(identities []Identity, identity Identity) bool {
+	for _, i := range identities {
+		if i == identity {
+			return true
+		}
+	}
+
+	return false
+}
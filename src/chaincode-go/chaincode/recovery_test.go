@@ -0,0 +1,141 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package chaincode_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/kkiu1756/my_fabric/src/chaincode-go/chaincode"
+	"github.com/kkiu1756/my_fabric/src/chaincode-go/tokentest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// #########
+// HELPERS
+// #########
+
+var recoveryTime = time.Date(2021, 3, 1, 12, 0, 0, 0, time.UTC)
+
+const guardiansJSON = `[{"mspId":"Org1MSP","commonName":"bob"},{"mspId":"Org1MSP","commonName":"carol"},{"mspId":"Org2MSP","commonName":"dave"}]`
+
+// recoveryLedger returns a ledger where bob, carol and dave are the
+// guardians of alice, two of whom recover the account after a day
+func recoveryLedger(t *testing.T) *tokentest.Ledger {
+	l := adminLedger(t).
+		WithAccount("alice", "user", 100).
+		WithTimestamp(recoveryTime).
+		WithCaller("Org1MSP", "alice", "client")
+
+	_, err := (&chaincode.SmartContract{}).SetGuardians(l.Context, "alice", guardiansJSON, 2, "24h")
+	require.NoError(t, err)
+	return l
+}
+
+// #########
+// TESTS
+// #########
+
+func TestRecovery(t *testing.T) {
+	l := recoveryLedger(t)
+	contract := &chaincode.SmartContract{}
+	newHolder := chaincode.Identity{MSPID: "Org2MSP", CommonName: "alice-new"}
+
+	l.WithCaller("Org1MSP", "bob", "client")
+	recovery, err := contract.ApproveRecovery(l.Context, "alice", "Org2MSP", "alice-new")
+	require.NoError(t, err)
+	assert.Equal(t, "", recovery.ReadyAt)
+	l.AssertEvent(chaincode.EventRecoveryApproved, recovery)
+
+	_, err = contract.ApproveRecovery(l.Context, "alice", "Org2MSP", "alice-new")
+	assert.EqualError(t, err, "[INVALID_ARGUMENT] Org1MSP/bob already approved the recovery of alice")
+	_, err = contract.CompleteRecovery(l.Context, "alice")
+	assert.EqualError(t, err, "[UNAUTHORIZED] the recovery of alice has 1 approvals, fewer than its guardians' threshold")
+
+	l.WithCaller("Org2MSP", "dave", "client")
+	_, err = contract.ApproveRecovery(l.Context, "alice", "Org2MSP", "mallory")
+	assert.EqualError(t, err, "[INVALID_ARGUMENT] a recovery of alice to Org2MSP/alice-new is underway, its holder must cancel it first")
+	recovery, err = contract.ApproveRecovery(l.Context, "alice", "Org2MSP", "alice-new")
+	require.NoError(t, err)
+	assert.Equal(t, "2021-03-02T12:00:00Z", recovery.ReadyAt)
+	assert.Equal(t, []chaincode.Identity{{MSPID: "Org1MSP", CommonName: "bob"}, {MSPID: "Org2MSP", CommonName: "dave"}}, recovery.Approvals)
+
+	_, err = contract.CompleteRecovery(l.Context, "alice")
+	assert.EqualError(t, err, "[UNAUTHORIZED] the recovery of alice can be completed from 2021-03-02T12:00:00Z")
+
+	l.WithTimestamp(recoveryTime.Add(24 * time.Hour))
+	recovery, err = contract.CompleteRecovery(l.Context, "alice")
+	require.NoError(t, err)
+	assert.Equal(t, newHolder, recovery.NewHolder)
+	l.AssertEvent(chaincode.EventRecoveryCompleted, recovery)
+
+	pending, err := contract.GetRecovery(l.Context, "alice")
+	require.NoError(t, err)
+	assert.Empty(t, pending.Approvals)
+
+	l.WithCaller("Org1MSP", "alice", "client")
+	_, err = contract.SetGuardians(l.Context, "alice", guardiansJSON, 1, "1h")
+	assert.EqualError(t, err, "[UNAUTHORIZED] only the holder of alice can set its guardians", "the old certificate no longer holds the account")
+	l.WithCaller("Org2MSP", "alice-new", "client")
+	_, err = contract.SetGuardians(l.Context, "alice", guardiansJSON, 1, "1h")
+	require.NoError(t, err)
+}
+
+func TestCancelRecovery(t *testing.T) {
+	l := recoveryLedger(t)
+	contract := &chaincode.SmartContract{}
+
+	l.WithCaller("Org1MSP", "bob", "client")
+	_, err := contract.ApproveRecovery(l.Context, "alice", "Org1MSP", "mallory")
+	require.NoError(t, err)
+	err = contract.CancelRecovery(l.Context, "alice")
+	assert.EqualError(t, err, "[UNAUTHORIZED] only the holder of alice can cancel its recovery")
+
+	l.WithCaller("Org1MSP", "alice", "client")
+	require.NoError(t, contract.CancelRecovery(l.Context, "alice"))
+	l.AssertEvent(chaincode.EventRecoveryCancelled, &chaincode.Recovery{
+		Account:   "alice",
+		NewHolder: chaincode.Identity{MSPID: "Org1MSP", CommonName: "mallory"},
+		Approvals: []chaincode.Identity{{MSPID: "Org1MSP", CommonName: "bob"}},
+	})
+	_, err = contract.CompleteRecovery(l.Context, "alice")
+	assert.EqualError(t, err, "[INVALID_ARGUMENT] no recovery of alice is underway")
+}
+
+func TestApproveRecoveryRequiresGuardian(t *testing.T) {
+	l := recoveryLedger(t)
+
+	l.WithCaller("Org2MSP", "bob", "client")
+	_, err := (&chaincode.SmartContract{}).ApproveRecovery(l.Context, "alice", "Org2MSP", "bob")
+	assert.EqualError(t, err, "[UNAUTHORIZED] only a guardian of alice can approve its recovery", "a guardian is named by MSP too")
+}
+
+func TestSetGuardiansRejects(t *testing.T) {
+	l := recoveryLedger(t)
+	contract := &chaincode.SmartContract{}
+
+	tests := []struct {
+		guardians string
+		threshold int
+		delay     string
+		err       string
+	}{
+		{`[]`, 1, "24h", "[INVALID_ARGUMENT] invalid guardians: must hold between 1 and 10 guardians"},
+		{`[{"mspId":"Org1MSP","commonName":"bob"},{"mspId":"Org1MSP","commonName":"bob"}]`, 1, "24h", "[INVALID_ARGUMENT] invalid guardians[1]: duplicate guardian"},
+		{`[{"mspId":"Org1MSP","name":"bob"}]`, 1, "24h", `[INVALID_ARGUMENT] invalid guardians: json: unknown field "name"`},
+		{guardiansJSON, 4, "24h", "[INVALID_ARGUMENT] invalid threshold: must be between 1 and 3"},
+		{guardiansJSON, 2, "0s", "[INVALID_ARGUMENT] invalid delay: must be a positive duration such as 72h"},
+	}
+	for _, tt := range tests {
+		_, err := contract.SetGuardians(l.Context, "alice", tt.guardians, tt.threshold, tt.delay)
+		assert.EqualError(t, err, tt.err)
+	}
+
+	set, err := contract.GetGuardians(l.Context, "alice")
+	require.NoError(t, err)
+	assert.Len(t, set.Guardians, 3)
+	assert.Equal(t, 2, set.Threshold)
+}
@@ -0,0 +1,301 @@
+package chaincode
+
+import (
+	"encoding/json"
+	"errors"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+	"github.com/kkiu1756/my_fabric/src/chaincode-go/validation"
+)
+
+// While an org admin runs a referral program, an account holder can name
+// the account that referred them once with SetReferrer. The first transfer
+// of at least the program's minimum the referred account then sends pays
+// its referrer the bonus from the program's pool. A referral rewarded past
+// the program's total cap or the referrer's cap, or while the pool is short
+// or the referrer suspended, qualifies without a bonus.
+//
+// The bonus is paid inside the qualifying transfer, which records the
+// transaction's Transaction and event, so it shows as statement lines of
+// type LineReferral and on the Referral.
+
+// LineReferral is the type of the statement lines of a referral bonus
+const LineReferral = "referral"
+
+// referralPaidKey stores the total bonus the program has paid
+const referralPaidKey = "\x00config\x00referralPaid\x00"
+
+const (
+	// referralObjectType keys the referrals by referred account
+	referralObjectType = "referral"
+	// referrerObjectType keys the count of rewarded referrals by referrer
+	referrerObjectType = "referrer"
+)
+
+// ReferralProgram pays Bonus from Pool to the referrer of an account on its
+// first transfer of at least MinTransfer
+type ReferralProgram struct {
+	Pool        string `json:"pool"`
+	Bonus       int    `json:"bonus"`
+	MinTransfer int    `json:"minTransfer"`
+	// Cap is the most the program pays in total, MaxPerReferrer the most
+	// rewarded referrals of one referrer, 0 if unlimited
+	Cap            int `json:"cap,omitempty" metadata:"cap,optional"`
+	MaxPerReferrer int `json:"maxPerReferrer,omitempty" metadata:"maxPerReferrer,optional"`
+}
+
+// Referral names the referrer of an account
+type Referral struct {
+	Account  string `json:"account"`
+	Referrer string `json:"referrer"`
+	// RegisteredAt is the proposal timestamp of SetReferrer, RFC 3339
+	RegisteredAt string `json:"registeredAt"`
+	// QualifiedTxID is the qualifying transfer, empty until it happens,
+	// and Bonus what it paid the referrer
+	QualifiedTxID string `json:"qualifiedTxId,omitempty" metadata:"qualifiedTxId,optional"`
+	Bonus         int    `json:"bonus,omitempty" metadata:"bonus,optional"`
+}
+
+// SetReferralProgram pays bonus from pool to the referrer of each account
+// on its first transfer of at least minTransfer, up to cap in total and
+// maxPerReferrer referrals per referrer, 0 for no limit. A 0 bonus ends the
+// program. Only an org admin can call it.
+func (s *SmartContract) SetReferralProgram(ctx contractapi.TransactionContextInterface, pool string, bonus int, minTransfer int, cap int, maxPerReferrer int) (*Initialization, error) {
+	errs := []error{
+		validation.Amount("bonus", bonus),
+		validation.Amount("minTransfer", minTransfer),
+		validation.Amount("cap", cap),
+	}
+	if bonus > 0 {
+		errs = append(errs, validation.AccountID("pool", pool))
+	}
+	if maxPerReferrer < 0 {
+		errs = append(errs, &validation.Error{Field: "maxPerReferrer", Reason: "must not be negative"})
+	}
+	if err := validate(errs...); err != nil {
+		return nil, err
+	}
+	if err := requireInitialized(ctx); err != nil {
+		return nil, err
+	}
+	if _, err := requireAdmin(ctx, "change the referral program"); err != nil {
+		return nil, err
+	}
+
+	init, err := ledgerConfig(ctx)
+	if err != nil {
+		return nil, err
+	}
+	init.Referral = nil
+	if bonus > 0 {
+		if _, err := newWriteBatch(ctx).getUser(pool); err != nil {
+			return nil, err
+		}
+		init.Referral = &ReferralProgram{Pool: pool, Bonus: bonus, MinTransfer: minTransfer, Cap: cap, MaxPerReferrer: maxPerReferrer}
+	}
+
+	err = putState(ctx, InitializationKey, init)
+	if err != nil {
+		return nil, err
+	}
+
+	return init, nil
+}
+
+// SetReferrer names account as the referrer of the caller's account. It
+// can be set once, before the caller's qualifying transfer; an account
+// cannot refer itself, the account it was referred by, or be referred by
+// the program's pool.
+func (s *SmartContract) SetReferrer(ctx contractapi.TransactionContextInterface, account string) (*Referral, error) {
+	if err := validate(validation.ID("account", account)); err != nil {
+		return nil, err
+	}
+	if err := requireInitialized(ctx); err != nil {
+		return nil, err
+	}
+
+	init, err := ledgerConfig(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if init.Referral == nil {
+		return nil, newError(CodeInvalidArgument, "no referral program is running")
+	}
+	caller, err := callerIdentity(ctx)
+	if err != nil {
+		return nil, err
+	}
+	referred := caller.CommonName
+	if !isAccountHolder(ctx, referred) {
+		return nil, newError(CodeUnauthorized, "only the holder of %s can set its referrer", referred)
+	}
+	if account == referred || isAccountHolder(ctx, account) {
+		return nil, newError(CodeInvalidArgument, "an account cannot refer itself")
+	}
+	if account == init.Referral.Pool {
+		return nil, newError(CodeInvalidArgument, "the referral pool cannot refer accounts")
+	}
+
+	batch := newWriteBatch(ctx)
+	for _, id := range []string{referred, account} {
+		if _, err := batch.getUser(id); err != nil {
+			return nil, err
+		}
+	}
+	key, existing, err := referral(batch, referred)
+	if err != nil {
+		return nil, err
+	}
+	if existing != nil {
+		return nil, newError(CodeInvalidArgument, "account %s was referred by %s", referred, existing.Referrer)
+	}
+	_, upstream, err := referral(batch, account)
+	if err != nil {
+		return nil, err
+	}
+	if upstream != nil && upstream.Referrer == referred {
+		return nil, newError(CodeInvalidArgument, "account %s was referred by %s", account, referred)
+	}
+
+	timestamp, err := txTimestamp(ctx.GetStub())
+	if err != nil {
+		return nil, err
+	}
+	ref := &Referral{Account: referred, Referrer: account, RegisteredAt: timestamp}
+	err = batch.putState(key, ref)
+	if err != nil {
+		return nil, err
+	}
+
+	err = batch.flush()
+	if err != nil {
+		return nil, err
+	}
+
+	return ref, nil
+}
+
+// GetReferral returns the referral of account
+func (s *SmartContract) GetReferral(ctx contractapi.TransactionContextInterface, account string) (*Referral, error) {
+	if err := validate(validation.ID("account", account)); err != nil {
+		return nil, err
+	}
+
+	_, ref, err := referral(newWriteBatch(ctx), account)
+	if err == nil && ref == nil {
+		err = newError(CodeInvalidArgument, "account %s has no referrer", account)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return ref, nil
+}
+
+// payReferralBonus buffers the referral bonus a transfer qualifies for, if
+// any
+func payReferralBonus(batch *writeBatch, transaction *Transaction) error {
+	init, err := ledgerConfig(batch.ctx)
+	if err != nil || init == nil || init.Referral == nil || transaction.Value < init.Referral.MinTransfer {
+		return err
+	}
+	program := init.Referral
+
+	key, ref, err := referral(batch, transaction.From)
+	if err != nil || ref == nil || ref.QualifiedTxID != "" {
+		return err
+	}
+	ref.QualifiedTxID = transaction.TXID
+
+	countKey, err := batch.ctx.GetStub().CreateCompositeKey(referrerObjectType, []string{ref.Referrer})
+	if err != nil {
+		return err
+	}
+	count, err := readCount(batch, countKey)
+	if err != nil {
+		return err
+	}
+	paid, err := readCount(batch, referralPaidKey)
+	if err != nil {
+		return err
+	}
+	frozen := checkSuspended(batch, ref.Referrer)
+	if frozen != nil && !errors.Is(frozen, ErrAccountFrozen) {
+		return frozen
+	}
+	capped := (program.Cap > 0 && paid+program.Bonus > program.Cap) ||
+		(program.MaxPerReferrer > 0 && count >= program.MaxPerReferrer)
+	if capped || frozen != nil {
+		return batch.putState(key, ref)
+	}
+
+	pool, err := batch.getUser(program.Pool)
+	if err != nil {
+		return err
+	}
+	err = settleDemurrage(batch, pool)
+	if err != nil {
+		return err
+	}
+	if pool.Balance >= program.Bonus {
+		ref.Bonus = program.Bonus
+		pool.Balance -= ref.Bonus
+		err = indexBalanceChange(batch, program.Pool, LineReferral, ref.Referrer, -ref.Bonus)
+		if err != nil {
+			return err
+		}
+		err = creditShare(batch, ref.Referrer, LineReferral, program.Pool, ref.Bonus)
+		if err != nil {
+			return err
+		}
+		err = batch.putState(countKey, count+1)
+		if err != nil {
+			return err
+		}
+		err = batch.putState(referralPaidKey, paid+ref.Bonus)
+		if err != nil {
+			return err
+		}
+	}
+	err = batch.putUser(pool)
+	if err != nil {
+		return err
+	}
+
+	return batch.putState(key, ref)
+}
+
+// readCount reads the count stored under key, 0 if there is none
+func readCount(batch *writeBatch, key string) (int, error) {
+	data, err := batch.getState(key)
+	if err != nil || data == nil {
+		return 0, err
+	}
+
+	var count int
+	if err := json.Unmarshal(data, &count); err != nil {
+		return 0, corrupt(key, err)
+	}
+	return count, nil
+}
+
+// referral reads the referral of account, nil if it has none, and returns
+// its key
+func referral(batch *writeBatch, account string) (string, *Referral, error) {
+	key, err := batch.ctx.GetStub().CreateCompositeKey(referralObjectType, []string{account})
+	if err != nil {
+		return "", nil, err
+	}
+	data, err := batch.getState(key)
+	if err != nil || data == nil {
+		return key, nil, err
+	}
+
+	var ref Referral
+	err = decodeRecord(key, data, &ref, "account", "referrer", "registeredAt")
+	if err != nil {
+		return "", nil, err
+	}
+
+	return key, &ref, nil
+}
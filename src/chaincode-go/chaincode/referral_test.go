@@ -0,0 +1,125 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package chaincode_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+	"github.com/kkiu1756/my_fabric/src/chaincode-go/chaincode"
+	"github.com/kkiu1756/my_fabric/src/chaincode-go/tokentest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// #########
+// HELPERS
+// #########
+
+// referralLedger returns a ledger with a rewards pool of 100, alice and
+// carol with 100 and bob with 200, running a program of 10 per referral on a
+// first transfer of 50 or more, capped at 15 in total
+func referralLedger(t *testing.T) *tokentest.Ledger {
+	l := adminLedger(t).
+		WithAccount("rewards", "pool", 100).
+		WithAccount("alice", "user", 100).
+		WithAccount("bob", "user", 200).
+		WithAccount("carol", "user", 100).
+		WithTimestamp(time.Date(2021, 9, 1, 12, 0, 0, 0, time.UTC))
+	paginate(l.Stub, l.State)
+	l.Stub.GetStateByPartialCompositeKeyStub = func(objectType string, attributes []string) (shim.StateQueryIteratorInterface, error) {
+		it, _, err := l.Stub.GetStateByPartialCompositeKeyWithPagination(objectType, attributes, 1000, "")
+		return it, err
+	}
+
+	_, err := (&chaincode.SmartContract{}).SetReferralProgram(l.Context, "rewards", 10, 50, 15, 0)
+	require.NoError(t, err)
+	return l
+}
+
+// #########
+// TESTS
+// #########
+
+func TestReferralBonus(t *testing.T) {
+	l := referralLedger(t)
+	contract := &chaincode.SmartContract{}
+
+	l.WithCaller("Org1MSP", "bob", "client")
+	ref, err := contract.SetReferrer(l.Context, "alice")
+	require.NoError(t, err)
+	assert.Equal(t, "alice", ref.Referrer)
+
+	l.WithTxID("small")
+	_, err = contract.TransferFrom(l.Context, "bob", "carol", 49)
+	require.NoError(t, err)
+	l.AssertBalance("alice", 100)
+
+	l.WithTxID("first")
+	_, err = contract.TransferFrom(l.Context, "bob", "alice", 50)
+	require.NoError(t, err)
+	l.AssertTransaction("first", "bob", "alice", 50)
+	l.AssertBalance("alice", 160)
+	l.AssertBalance("rewards", 90)
+	l.AssertTotal(500)
+
+	ref, err = contract.GetReferral(l.Context, "bob")
+	require.NoError(t, err)
+	assert.Equal(t, "first", ref.QualifiedTxID)
+	assert.Equal(t, 10, ref.Bonus)
+
+	statement, err := contract.GenerateStatement(l.Context, "alice", "2021-09-01", "2021-09-01")
+	require.NoError(t, err)
+	require.Len(t, statement.Lines, 2)
+	assert.Equal(t, chaincode.LineReferral, statement.Lines[1].Type)
+	assert.Equal(t, 10, statement.Lines[1].Amount)
+
+	l.WithTxID("second")
+	_, err = contract.TransferFrom(l.Context, "bob", "carol", 50)
+	require.NoError(t, err)
+	l.AssertBalance("alice", 160)
+
+	l.WithCaller("Org1MSP", "carol", "client").WithTxID("capped")
+	_, err = contract.SetReferrer(l.Context, "alice")
+	require.NoError(t, err)
+	_, err = contract.TransferFrom(l.Context, "carol", "bob", 50)
+	require.NoError(t, err)
+	l.AssertBalance("rewards", 90)
+	ref, err = contract.GetReferral(l.Context, "carol")
+	require.NoError(t, err)
+	assert.Equal(t, &chaincode.Referral{Account: "carol", Referrer: "alice", RegisteredAt: ref.RegisteredAt, QualifiedTxID: "capped"}, ref, "a referral past the cap should qualify without a bonus")
+}
+
+func TestSetReferrerRejects(t *testing.T) {
+	l := referralLedger(t)
+	contract := &chaincode.SmartContract{}
+
+	l.WithCaller("Org1MSP", "bob", "client")
+	_, err := contract.SetReferrer(l.Context, "bob")
+	assert.EqualError(t, err, "[INVALID_ARGUMENT] an account cannot refer itself")
+	_, err = contract.SetReferrer(l.Context, "rewards")
+	assert.EqualError(t, err, "[INVALID_ARGUMENT] the referral pool cannot refer accounts")
+	_, err = contract.SetReferrer(l.Context, "dave")
+	assert.EqualError(t, err, "[ACCOUNT_NOT_FOUND] user dave does not exist")
+
+	_, err = contract.SetReferrer(l.Context, "alice")
+	require.NoError(t, err)
+	_, err = contract.SetReferrer(l.Context, "carol")
+	assert.EqualError(t, err, "[INVALID_ARGUMENT] account bob was referred by alice")
+
+	l.WithCaller("Org1MSP", "alice", "client")
+	_, err = contract.SetReferrer(l.Context, "bob")
+	assert.EqualError(t, err, "[INVALID_ARGUMENT] account bob was referred by alice")
+
+	_, err = contract.SetReferralProgram(l.Context, "", 0, 0, 0, 0)
+	assert.EqualError(t, err, "[UNAUTHORIZED] only an organization admin can change the referral program")
+	l.WithCaller("Org1MSP", "Admin@org1.example.com", chaincode.AdminOU)
+	_, err = contract.SetReferralProgram(l.Context, "", 0, 0, 0, 0)
+	require.NoError(t, err)
+	l.WithCaller("Org1MSP", "carol", "client")
+	_, err = contract.SetReferrer(l.Context, "alice")
+	assert.EqualError(t, err, "[INVALID_ARGUMENT] no referral program is running")
+}
@@ -0,0 +1,287 @@
+package chaincode
+
+import (
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+	"github.com/kkiu1756/my_fabric/src/chaincode-go/validation"
+)
+
+// The recipient of a transfer, or an arbiter, returns part or all of it to
+// its sender with Refund. Each refund is a transfer of its own, recorded as
+// the refund transaction's Transaction, and the refunds of a transfer are
+// listed on its Refunds record, which keeps their total within the value
+// of the transfer. GetRefundStatus reports how much of a transfer is left
+// to refund. A refund earns no cashback or referral bonus and cannot itself
+// be refunded. If the transfer paid cashback, each refund returns the same
+// share of it from the sender to the cashback pool. The Refunded event
+// replaces the refund's typed event.
+
+// EventRefunded replaces the typed event of a refund
+const EventRefunded = "Refunded"
+
+// refundObjectType keys the refunds of a transfer by its transaction id
+const refundObjectType = "refund"
+
+// refundTxObjectType keys the transfer each refund returns by the refund's
+// transaction id
+const refundTxObjectType = "refundtx"
+
+// refundOf records the transfer a refund returns
+type refundOf struct {
+	OriginalTxID string `json:"originalTxId"`
+}
+
+// RefundRecord is one refund of a transfer
+type RefundRecord struct {
+	TXID   string `json:"txId"`
+	Amount int    `json:"amount"`
+	// Timestamp is the proposal timestamp of the refund, RFC 3339
+	Timestamp string `json:"timestamp"`
+}
+
+// Refunds lists the refunds of the transfer OriginalTxID
+type Refunds struct {
+	OriginalTxID string `json:"originalTxId"`
+	// Value is the value of the original transfer and Refunded the total
+	// of its refunds, at most Value
+	Value    int             `json:"value"`
+	Refunded int             `json:"refunded"`
+	Refunds  []*RefundRecord `json:"refunds"`
+}
+
+// RefundStatus is how much of the transfer OriginalTxID has been refunded
+type RefundStatus struct {
+	OriginalTxID string `json:"originalTxId"`
+	Value        int    `json:"value"`
+	Refunded     int    `json:"refunded"`
+	// Remaining is Value less Refunded, the most a further refund returns
+	Remaining int `json:"remaining"`
+}
+
+// refundedEvent is the payload of EventRefunded
+type refundedEvent struct {
+	*Transaction
+	OriginalTxID string `json:"originalTxId"`
+	// CashbackReturned is the cashback the refund took back to the pool
+	CashbackReturned int `json:"cashbackReturned,omitempty"`
+}
+
+// Refund returns amount of the transfer originalTxID from its recipient to
+// its sender. The refunds of a transfer total at most its value. Only the
+// holder of the recipient account or an arbiter can call it.
+func (s *SmartContract) Refund(ctx contractapi.TransactionContextInterface, originalTxID string, amount int) (*Refunds, error) {
+	errs := []error{
+		validation.ID("originalTxId", originalTxID),
+		validation.Amount("amount", amount),
+	}
+	if amount == 0 {
+		errs = append(errs, &validation.Error{Field: "amount", Reason: "must be positive"})
+	}
+	if err := validate(errs...); err != nil {
+		return nil, err
+	}
+	if err := requireInitialized(ctx); err != nil {
+		return nil, err
+	}
+
+	original, err := refundableTransfer(ctx, originalTxID)
+	if err != nil {
+		return nil, err
+	}
+	if !isArbiter(ctx) {
+		if err := requireAccountHolder(ctx, original.To, "refund its transfers"); err != nil {
+			return nil, err
+		}
+	}
+
+	batch := newWriteBatch(ctx)
+	key, refunds, err := transferRefunds(batch, originalTxID)
+	if err != nil {
+		return nil, err
+	}
+	if refunds == nil {
+		refunds = &Refunds{OriginalTxID: originalTxID, Value: original.Value, Refunds: []*RefundRecord{}}
+	}
+	if refunds.Refunded+amount > refunds.Value {
+		return nil, newError(CodeInvalidArgument, "only %d of transaction %s is left to refund", refunds.Value-refunds.Refunded, originalTxID)
+	}
+
+	if original.From == EscrowAccount || original.To == EscrowAccount {
+		return nil, newError(CodeInvalidArgument, "account %s only takes reversible transfers", EscrowAccount)
+	}
+
+	// a refund is not held to the recipient's limits
+	transaction, err := moveTokens(batch, original.To, original.From, amount, true)
+	if err != nil {
+		return nil, err
+	}
+	returned, err := clawBackCashback(batch, original, refunds.Refunded, amount)
+	if err != nil {
+		return nil, err
+	}
+	refunds.Refunded += amount
+	refunds.Refunds = append(refunds.Refunds, &RefundRecord{TXID: transaction.TXID, Amount: amount, Timestamp: transaction.Timestamp})
+	err = batch.putState(key, refunds)
+	if err != nil {
+		return nil, err
+	}
+	refundKey, err := ctx.GetStub().CreateCompositeKey(refundTxObjectType, []string{transaction.TXID})
+	if err != nil {
+		return nil, err
+	}
+	err = batch.putState(refundKey, refundOf{OriginalTxID: originalTxID})
+	if err != nil {
+		return nil, err
+	}
+	err = emitTypedEvent(batch, EventRefunded, refundedEvent{transaction, originalTxID, returned})
+	if err != nil {
+		return nil, err
+	}
+
+	err = batch.flush()
+	if err != nil {
+		return nil, err
+	}
+
+	return refunds, nil
+}
+
+// GetRefunds returns the refunds of the transfer originalTxID
+func (s *SmartContract) GetRefunds(ctx contractapi.TransactionContextInterface, originalTxID string) (*Refunds, error) {
+	if err := validate(validation.ID("originalTxId", originalTxID)); err != nil {
+		return nil, err
+	}
+
+	_, refunds, err := transferRefunds(newWriteBatch(ctx), originalTxID)
+	if err == nil && refunds == nil {
+		err = newError(CodeTransactionNotFound, "transaction %s has no refunds", originalTxID)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return refunds, nil
+}
+
+// GetRefundStatus returns how much of the transfer txid has been refunded
+// and how much is left to refund
+func (s *SmartContract) GetRefundStatus(ctx contractapi.TransactionContextInterface, txid string) (*RefundStatus, error) {
+	if err := validate(validation.ID("txid", txid)); err != nil {
+		return nil, err
+	}
+
+	original, err := refundableTransfer(ctx, txid)
+	if err != nil {
+		return nil, err
+	}
+	_, refunds, err := transferRefunds(newWriteBatch(ctx), txid)
+	if err != nil {
+		return nil, err
+	}
+
+	status := &RefundStatus{OriginalTxID: txid, Value: original.Value}
+	if refunds != nil {
+		status.Refunded = refunds.Refunded
+	}
+	status.Remaining = status.Value - status.Refunded
+
+	return status, nil
+}
+
+// refundableTransfer reads the Transaction record of txid, failing unless
+// it is a transfer other than a refund
+func refundableTransfer(ctx contractapi.TransactionContextInterface, txid string) (*Transaction, error) {
+	original, err := (&SmartContract{}).GetTransaction(ctx, txid)
+	if err != nil {
+		return nil, err
+	}
+	// records written before the type was added are transfers if they
+	// have both parties
+	if (original.Type != TransactionTransfer && original.Type != "") || original.From == "" || original.To == "" {
+		return nil, newError(CodeInvalidArgument, "transaction %s is not a transfer", txid)
+	}
+	key, err := ctx.GetStub().CreateCompositeKey(refundTxObjectType, []string{txid})
+	if err != nil {
+		return nil, err
+	}
+	data, err := getState(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+	if data != nil {
+		return nil, newError(CodeInvalidArgument, "transaction %s is a refund", txid)
+	}
+
+	return original, nil
+}
+
+// clawBackCashback buffers the return to the pool of the share of the
+// cashback paid on original that refunding amount of it, after refunded,
+// takes back from its sender, and returns that share. The shares of the
+// refunds of a transfer add up to its cashback.
+func clawBackCashback(batch *writeBatch, original *Transaction, refunded int, amount int) (int, error) {
+	key, err := batch.ctx.GetStub().CreateCompositeKey(cashbackObjectType, []string{original.TXID})
+	if err != nil {
+		return 0, err
+	}
+	data, err := batch.getState(key)
+	if err != nil || data == nil {
+		return 0, err
+	}
+	var payment cashbackPayment
+	err = decodeRecord(key, data, &payment, "pool", "cashback")
+	if err != nil {
+		return 0, err
+	}
+	share := proRataShare(payment.Cashback, refunded+amount, original.Value) - proRataShare(payment.Cashback, refunded, original.Value)
+	if share == 0 {
+		return 0, nil
+	}
+
+	buyer, err := batch.getUser(original.From)
+	if err != nil {
+		return 0, err
+	}
+	err = settleDemurrage(batch, buyer)
+	if err != nil {
+		return 0, err
+	}
+	if buyer.Balance < share {
+		return 0, newError(CodeInsufficientBalance, "account %s cannot return %d of cashback", original.From, share)
+	}
+	buyer.Balance -= share
+	err = batch.putUser(buyer)
+	if err != nil {
+		return 0, err
+	}
+	err = indexBalanceChange(batch, original.From, LineCashback, payment.Pool, -share)
+	if err != nil {
+		return 0, err
+	}
+	err = creditShare(batch, payment.Pool, LineCashback, original.From, share)
+	if err != nil {
+		return 0, err
+	}
+
+	return share, nil
+}
+
+// transferRefunds reads the refunds of the transfer originalTxID, nil if it
+// has none, and returns their key
+func transferRefunds(batch *writeBatch, originalTxID string) (string, *Refunds, error) {
+	key, err := batch.ctx.GetStub().CreateCompositeKey(refundObjectType, []string{originalTxID})
+	if err != nil {
+		return "", nil, err
+	}
+	data, err := batch.getState(key)
+	if err != nil || data == nil {
+		return key, nil, err
+	}
+
+	var refunds Refunds
+	err = decodeRecord(key, data, &refunds, "originalTxId", "value", "refunded", "refunds")
+	if err != nil {
+		return "", nil, err
+	}
+
+	return key, &refunds, nil
+}
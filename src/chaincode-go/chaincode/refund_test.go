@@ -0,0 +1,146 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package chaincode_test
+
+import (
+	"testing"
+
+	"github.com/kkiu1756/my_fabric/src/chaincode-go/chaincode"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRefund(t *testing.T) {
+	l := initializedLedger(t).
+		WithAccount("alice", "user", 100).
+		WithAccount("shop", "user", 0)
+	contract := &chaincode.SmartContract{}
+	l.WithTxID("buy")
+	_, err := contract.TransferFrom(l.Context, "alice", "shop", 50)
+	require.NoError(t, err)
+
+	l.WithCaller("Org1MSP", "shop", "client").WithTxID("partial")
+	refunds, err := contract.Refund(l.Context, "buy", 20)
+	require.NoError(t, err)
+	assert.Equal(t, 20, refunds.Refunded)
+	l.AssertBalance("alice", 70)
+	l.AssertBalance("shop", 30)
+	transaction, err := contract.GetTransaction(l.Context, "partial")
+	require.NoError(t, err)
+	l.AssertEvent(chaincode.EventRefunded, map[string]interface{}{
+		"txId": "partial", "type": "transfer", "from": "shop", "to": "alice", "value": 20,
+		"txTimestamp": transaction.Timestamp, "channelId": transaction.ChannelID,
+		"initiatorMspId": transaction.InitiatorMSPID, "initiator": transaction.Initiator,
+		"originalTxId": "buy",
+	})
+
+	status, err := contract.GetRefundStatus(l.Context, "buy")
+	require.NoError(t, err)
+	assert.Equal(t, &chaincode.RefundStatus{OriginalTxID: "buy", Value: 50, Refunded: 20, Remaining: 30}, status)
+
+	l.WithCaller("Org1MSP", "arbiter1", "client", chaincode.ArbiterOU).WithTxID("ruling")
+	_, err = contract.Refund(l.Context, "buy", 31)
+	assert.EqualError(t, err, "[INVALID_ARGUMENT] only 30 of transaction buy is left to refund")
+	_, err = contract.Refund(l.Context, "buy", 30)
+	require.NoError(t, err)
+	l.AssertBalance("alice", 100)
+
+	refunds, err = contract.GetRefunds(l.Context, "buy")
+	require.NoError(t, err)
+	assert.Equal(t, 50, refunds.Value)
+	assert.Equal(t, 50, refunds.Refunded)
+	require.Len(t, refunds.Refunds, 2)
+	assert.Equal(t, "partial", refunds.Refunds[0].TXID)
+	assert.Equal(t, "ruling", refunds.Refunds[1].TXID)
+}
+
+func TestRefundClawsBackCashback(t *testing.T) {
+	l := initializedLedger(t).
+		WithAccount("rewards", "pool", 10).
+		WithAccount("alice", "user", 100).
+		WithAccount("shop", chaincode.SellerType, 0)
+	contract := &chaincode.SmartContract{}
+	_, err := contract.SetCashback(l.Context, "rewards", 500)
+	require.NoError(t, err)
+	_, err = contract.TransferFrom(l.WithTxID("buy").Context, "alice", "shop", 60)
+	require.NoError(t, err)
+	l.AssertBalance("alice", 43)
+	l.AssertBalance("rewards", 7)
+
+	l.WithCaller("Org1MSP", "shop", "client")
+	_, err = contract.Refund(l.WithTxID("first").Context, "buy", 20)
+	require.NoError(t, err)
+	l.AssertBalance("alice", 62)
+	l.AssertBalance("rewards", 8)
+	transaction, err := contract.GetTransaction(l.Context, "first")
+	require.NoError(t, err)
+	l.AssertEvent(chaincode.EventRefunded, map[string]interface{}{
+		"txId": "first", "type": "transfer", "from": "shop", "to": "alice", "value": 20,
+		"txTimestamp": transaction.Timestamp, "channelId": transaction.ChannelID,
+		"initiatorMspId": transaction.InitiatorMSPID, "initiator": transaction.Initiator,
+		"originalTxId": "buy", "cashbackReturned": 1,
+	})
+
+	_, err = contract.Refund(l.WithTxID("rest").Context, "buy", 40)
+	require.NoError(t, err)
+	l.AssertBalance("alice", 100)
+	l.AssertBalance("shop", 0)
+	l.AssertBalance("rewards", 10)
+
+	_, err = contract.Refund(l.Context, "first", 5)
+	assert.EqualError(t, err, "[INVALID_ARGUMENT] transaction first is a refund")
+	_, err = contract.GetRefundStatus(l.Context, "rest")
+	assert.EqualError(t, err, "[INVALID_ARGUMENT] transaction rest is a refund")
+}
+
+func TestRefundKeepsLargeTransfer(t *testing.T) {
+	l := initializedLedger(t).
+		WithAccount("alice", "user", 100).
+		WithAccount("shop", "user", 0)
+	contract := &chaincode.SmartContract{}
+	_, err := contract.TransferFrom(l.WithTxID("buy").Context, "alice", "shop", 50)
+	require.NoError(t, err)
+	_, err = contract.SetLargeTransferThreshold(l.Context, 30)
+	require.NoError(t, err)
+
+	l.WithCaller("Org1MSP", "shop", "client")
+	_, err = contract.Refund(l.WithTxID("back").Context, "buy", 40)
+	require.NoError(t, err)
+	transaction, err := contract.GetTransaction(l.Context, "back")
+	require.NoError(t, err)
+	l.AssertEvent(chaincode.EventLargeTransfer, map[string]interface{}{
+		"txId": "back", "type": "transfer", "from": "shop", "to": "alice", "value": 40,
+		"txTimestamp": transaction.Timestamp, "channelId": transaction.ChannelID,
+		"initiatorMspId": transaction.InitiatorMSPID, "initiator": transaction.Initiator,
+		"threshold": 30, "originalTxId": "buy", "events": []string{chaincode.EventRefunded},
+	})
+}
+
+func TestRefundRejects(t *testing.T) {
+	l := adminLedger(t).
+		WithAccount("alice", "user", 100).
+		WithAccount("shop", "user", 0).
+		WithTransaction("buy", "alice", "shop", 50)
+	contract := &chaincode.SmartContract{}
+
+	_, err := contract.Refund(l.Context, "buy", 0)
+	assert.EqualError(t, err, "[INVALID_ARGUMENT] invalid amount: must be positive")
+	_, err = contract.Refund(l.Context, "sell", 10)
+	assert.EqualError(t, err, "[TRANSACTION_NOT_FOUND] the transaction sell does not exist")
+
+	l.WithCaller("Org1MSP", "alice", "client")
+	_, err = contract.Refund(l.Context, "buy", 10)
+	assert.EqualError(t, err, "[UNAUTHORIZED] only the holder of shop can refund its transfers")
+
+	l.WithCaller("Org1MSP", "shop", "client")
+	_, err = contract.Refund(l.Context, "buy", 10)
+	assert.EqualError(t, err, "[INSUFFICIENT_BALANCE] user balance lower than 10")
+	_, err = contract.GetRefunds(l.Context, "buy")
+	assert.EqualError(t, err, "[TRANSACTION_NOT_FOUND] transaction buy has no refunds")
+
+	status, err := contract.GetRefundStatus(l.Context, "buy")
+	require.NoError(t, err)
+	assert.Equal(t, &chaincode.RefundStatus{OriginalTxID: "buy", Value: 50, Remaining: 50}, status)
+}
@@ -0,0 +1,205 @@
+package chaincode
+
+import (
+	"github.com/hyperledger/fabric-chaincode-go/pkg/cid"
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+	"github.com/kkiu1756/my_fabric/src/chaincode-go/validation"
+)
+
+// The issuer of a registered currency, an identity with the IssuerOU or an
+// org admin, publishes the reserves backing it once per period with
+// AttestReserves: the amount an auditor attested and the hash of the
+// auditor's report. The attestation is signed by the issuer's transaction,
+// whose identity it records, and cannot be replaced. CompareReserves sets
+// an attestation against the currency's supply, see currency.go. The base
+// token has no running supply; sum it with TotalSupply instead.
+
+// IssuerOU is the organizational unit of currency issuers
+const IssuerOU = "issuer"
+
+// EventReserveAttested is the event of a reserve attestation
+const EventReserveAttested = "ReserveAttested"
+
+// attestationObjectType keys the attestations by currency and period
+const attestationObjectType = "attestation"
+
+// Attestation attests the reserves backing a currency in a period
+type Attestation struct {
+	Currency string `json:"currency"`
+	Period   string `json:"period"`
+	Amount   int    `json:"amount"`
+	// Auditor names the auditor and ReportHash is the hex encoded SHA-256
+	// of its report
+	Auditor    string `json:"auditor"`
+	ReportHash string `json:"reportHash"`
+	// TXID is the attesting transaction, submitted by Issuer of
+	// IssuerMSPID
+	TXID        string `json:"txId"`
+	IssuerMSPID string `json:"issuerMspId"`
+	Issuer      string `json:"issuer"`
+	// Timestamp is the proposal timestamp of the attestation, RFC 3339
+	Timestamp string `json:"timestamp"`
+}
+
+// ReserveComparison sets an attestation against the current supply
+type ReserveComparison struct {
+	*Attestation
+	Supply int `json:"supply"`
+	// Surplus is the attested amount less the supply, negative if the
+	// supply is not fully backed
+	Surplus int `json:"surplus"`
+}
+
+// AttestReserves publishes amount as the reserves backing currency symbol
+// in period, attested by auditor in the report of hex encoded SHA-256
+// reportHash. Only an issuer can call it.
+func (s *SmartContract) AttestReserves(ctx contractapi.TransactionContextInterface, symbol string, period string, amount int, auditor string, reportHash string) (*Attestation, error) {
+	errs := []error{
+		validation.ID("symbol", symbol),
+		validation.ID("period", period),
+		validation.Amount("amount", amount),
+		validation.Memo("auditor", auditor),
+		digestError("reportHash", reportHash),
+	}
+	if auditor == "" {
+		errs = append(errs, &validation.Error{Field: "auditor", Reason: "must not be empty"})
+	}
+	if err := validate(errs...); err != nil {
+		return nil, err
+	}
+	if err := requireInitialized(ctx); err != nil {
+		return nil, err
+	}
+	if !isIssuer(ctx) {
+		if _, err := requireAdmin(ctx, "attest reserves"); err != nil {
+			return nil, err
+		}
+	}
+
+	batch := newWriteBatch(ctx)
+	if _, _, err := registeredCurrency(batch, symbol); err != nil {
+		return nil, err
+	}
+	key, existing, err := attestation(batch, symbol, period)
+	if err != nil {
+		return nil, err
+	}
+	if existing != nil {
+		return nil, newError(CodeInvalidArgument, "the reserves of %s in period %s are attested", symbol, period)
+	}
+
+	stub := ctx.GetStub()
+	timestamp, err := txTimestamp(stub)
+	if err != nil {
+		return nil, err
+	}
+	attested := &Attestation{
+		Currency:    symbol,
+		Period:      period,
+		Amount:      amount,
+		Auditor:     auditor,
+		ReportHash:  reportHash,
+		TXID:        stub.GetTxID(),
+		IssuerMSPID: creatorMSPID(stub),
+		Issuer:      creatorID(stub),
+		Timestamp:   timestamp,
+	}
+	err = batch.putState(key, attested)
+	if err != nil {
+		return nil, err
+	}
+	err = emitEvent(ctx, EventReserveAttested, attested)
+	if err != nil {
+		return nil, err
+	}
+
+	err = batch.flush()
+	if err != nil {
+		return nil, err
+	}
+
+	return attested, nil
+}
+
+// GetAttestation returns the attestation of the reserves of currency
+// symbol in period
+func (s *SmartContract) GetAttestation(ctx contractapi.TransactionContextInterface, symbol string, period string) (*Attestation, error) {
+	err := validate(
+		validation.ID("symbol", symbol),
+		validation.ID("period", period),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return periodAttestation(newWriteBatch(ctx), symbol, period)
+}
+
+// CompareReserves compares the reserves attested for currency symbol in
+// period with its current supply
+func (s *SmartContract) CompareReserves(ctx contractapi.TransactionContextInterface, symbol string, period string) (*ReserveComparison, error) {
+	err := validate(
+		validation.ID("symbol", symbol),
+		validation.ID("period", period),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	batch := newWriteBatch(ctx)
+	_, registered, err := registeredCurrency(batch, symbol)
+	if err != nil {
+		return nil, err
+	}
+	attested, err := periodAttestation(batch, symbol, period)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ReserveComparison{Attestation: attested, Supply: registered.Supply, Surplus: attested.Amount - registered.Supply}, nil
+}
+
+// isIssuer reports whether the client has the IssuerOU
+func isIssuer(ctx contractapi.TransactionContextInterface) bool {
+	caller, err := cid.New(ctx.GetStub())
+	if err != nil {
+		return false
+	}
+
+	issuer, err := caller.HasOUValue(IssuerOU)
+	return err == nil && issuer
+}
+
+// attestation reads the attestation of symbol in period, nil if there is
+// none, and returns its key
+func attestation(batch *writeBatch, symbol string, period string) (string, *Attestation, error) {
+	key, err := batch.ctx.GetStub().CreateCompositeKey(attestationObjectType, []string{symbol, period})
+	if err != nil {
+		return "", nil, err
+	}
+	data, err := batch.getState(key)
+	if err != nil || data == nil {
+		return key, nil, err
+	}
+
+	var attested Attestation
+	err = decodeRecord(key, data, &attested, "currency", "period", "amount", "auditor", "reportHash")
+	if err != nil {
+		return "", nil, err
+	}
+
+	return key, &attested, nil
+}
+
+// periodAttestation is attestation failing if there is none
+func periodAttestation(batch *writeBatch, symbol string, period string) (*Attestation, error) {
+	_, attested, err := attestation(batch, symbol, period)
+	if err == nil && attested == nil {
+		err = newError(CodeInvalidArgument, "the reserves of %s in period %s are not attested", symbol, period)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return attested, nil
+}
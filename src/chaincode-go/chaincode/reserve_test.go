@@ -0,0 +1,61 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package chaincode_test
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"testing"
+
+	"github.com/kkiu1756/my_fabric/src/chaincode-go/chaincode"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAttestReserves(t *testing.T) {
+	l := currencyLedger(t)
+	contract := &chaincode.SmartContract{}
+	digest := sha256.Sum256([]byte("report"))
+	report := hex.EncodeToString(digest[:])
+
+	l.WithCaller("Org1MSP", "issuer1", "client", chaincode.IssuerOU).WithTxID("attest")
+	attested, err := contract.AttestReserves(l.Context, "KRW", "2021-09", 900, "Audit & Co", report)
+	require.NoError(t, err)
+	assert.Equal(t, "Org1MSP", attested.IssuerMSPID)
+	assert.NotEmpty(t, attested.Issuer)
+	l.AssertEvent(chaincode.EventReserveAttested, map[string]interface{}{
+		"currency": "KRW", "period": "2021-09", "amount": 900, "auditor": "Audit & Co", "reportHash": report,
+		"txId": "attest", "issuerMspId": "Org1MSP", "issuer": attested.Issuer, "timestamp": attested.Timestamp,
+	})
+
+	comparison, err := contract.CompareReserves(l.Context, "KRW", "2021-09")
+	require.NoError(t, err)
+	assert.Equal(t, attested, comparison.Attestation)
+	assert.Equal(t, 1000, comparison.Supply)
+	assert.Equal(t, -100, comparison.Surplus, "the supply is not fully backed")
+
+	_, err = contract.AttestReserves(l.Context, "KRW", "2021-09", 1000, "Audit & Co", report)
+	assert.EqualError(t, err, "[INVALID_ARGUMENT] the reserves of KRW in period 2021-09 are attested")
+	_, err = contract.CompareReserves(l.Context, "KRW", "2021-10")
+	assert.EqualError(t, err, "[INVALID_ARGUMENT] the reserves of KRW in period 2021-10 are not attested")
+}
+
+func TestAttestReservesRejects(t *testing.T) {
+	l := currencyLedger(t)
+	contract := &chaincode.SmartContract{}
+	digest := sha256.Sum256([]byte("report"))
+	report := hex.EncodeToString(digest[:])
+
+	_, err := contract.AttestReserves(l.Context, "KRW", "2021-09", 900, "Audit & Co", "report")
+	assert.EqualError(t, err, "[INVALID_ARGUMENT] invalid reportHash: must be a hex encoded SHA-256 digest")
+	_, err = contract.AttestReserves(l.Context, "KRW", "2021-09", 900, "", report)
+	assert.EqualError(t, err, "[INVALID_ARGUMENT] invalid auditor: must not be empty")
+	_, err = contract.AttestReserves(l.Context, "USD", "2021-09", 900, "Audit & Co", report)
+	assert.EqualError(t, err, "[INVALID_ARGUMENT] currency USD is not registered")
+
+	l.WithCaller("Org1MSP", "alice", "client")
+	_, err = contract.AttestReserves(l.Context, "KRW", "2021-09", 900, "Audit & Co", report)
+	assert.EqualError(t, err, "[UNAUTHORIZED] only an organization admin can attest reserves")
+}
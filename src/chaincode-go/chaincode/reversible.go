@@ -0,0 +1,306 @@
+package chaincode
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+	"github.com/kkiu1756/my_fabric/src/chaincode-go/validation"
+)
+
+// Once an org admin sets a dispute window with SetDisputeWindow,
+// TransferReversible moves tokens from the sender into EscrowAccount.
+// Within the window the sender's holder or an arbiter can send them back
+// with ReverseTransfer; after it the transfer is final and
+// ClaimTransfer, which anyone can call, pays the recipient. Both legs are
+// ordinary transfer records, so balances, statements and the total supply
+// account for the escrowed tokens.
+
+// EscrowAccount holds the tokens of reversible transfers. Account ids
+// starting with "_" are reserved, so no user can create it, and
+// transferHelper refuses it.
+const EscrowAccount = "_escrow"
+
+// ArbiterOU marks the certificate of an arbiter, who can reverse any
+// reversible transfer within its window
+const ArbiterOU = "arbiter"
+
+// reversibleObjectType keys the reversible transfers by transaction id
+const reversibleObjectType = "reversible"
+
+// ReversibleTransfer is a transfer held in escrow
+type ReversibleTransfer struct {
+	// ID is the transaction id of the TransferReversible call
+	ID    string `json:"id"`
+	From  string `json:"from"`
+	To    string `json:"to"`
+	Value int    `json:"value"`
+	// Timestamp is the proposal timestamp of TransferReversible, FinalAt
+	// the end of its dispute window, both RFC 3339
+	Timestamp string `json:"txTimestamp"`
+	FinalAt   string `json:"finalAt"`
+}
+
+// SetDisputeWindow sets how long, a Go duration such as "72h", reversible
+// transfers can be reversed; "" disables TransferReversible. Transfers
+// already in escrow keep their window. Only an org admin can call it.
+func (s *SmartContract) SetDisputeWindow(ctx contractapi.TransactionContextInterface, window string) (*Initialization, error) {
+	if window != "" {
+		if d, err := time.ParseDuration(window); err != nil || d <= 0 {
+			return nil, validate(&validation.Error{Field: "window", Reason: "must be a positive duration such as 72h"})
+		}
+	}
+	if err := requireInitialized(ctx); err != nil {
+		return nil, err
+	}
+	if _, err := requireAdmin(ctx, "change the dispute window"); err != nil {
+		return nil, err
+	}
+
+	init, err := ledgerConfig(ctx)
+	if err != nil {
+		return nil, err
+	}
+	init.DisputeWindow = window
+
+	err = putState(ctx, InitializationKey, init)
+	if err != nil {
+		return nil, err
+	}
+
+	return init, nil
+}
+
+// TransferReversible moves value from from into escrow for to, who can
+// claim it once the dispute window has passed
+func (s *SmartContract) TransferReversible(ctx contractapi.TransactionContextInterface, from string, to string, value int) (*ReversibleTransfer, error) {
+	err := validate(
+		validation.ID("from", from),
+		validation.ID("to", to),
+		validation.Amount("value", value),
+	)
+	if err != nil {
+		return nil, err
+	}
+	if err := requireInitialized(ctx); err != nil {
+		return nil, err
+	}
+	from, to, err = resolveAccounts(ctx, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	init, err := ledgerConfig(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if init.DisputeWindow == "" {
+		return nil, newError(CodeInvalidArgument, "reversible transfers are disabled, an organization admin must call SetDisputeWindow")
+	}
+	window, err := time.ParseDuration(init.DisputeWindow)
+	if err != nil {
+		return nil, corrupt(InitializationKey, err)
+	}
+	if from == EscrowAccount || to == EscrowAccount {
+		return nil, newError(CodeInvalidArgument, "account %s only takes reversible transfers", EscrowAccount)
+	}
+	if from == to {
+		return nil, newError(CodeInvalidArgument, "cannot transfer to and from same client account")
+	}
+
+	batch := newWriteBatch(ctx)
+	if _, err := batch.getUser(to); err != nil {
+		return nil, err
+	}
+	err = openEscrow(batch)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := moveTokens(batch, from, EscrowAccount, value, false); err != nil {
+		return nil, fmt.Errorf("failed to transfer: %w", err)
+	}
+
+	stub := ctx.GetStub()
+	timestamp, err := txTimestamp(stub)
+	if err != nil {
+		return nil, err
+	}
+	sent, err := time.Parse(time.RFC3339Nano, timestamp)
+	if err != nil {
+		return nil, newError(CodeInvalidArgument, "a reversible transfer needs the proposal timestamp")
+	}
+	reversible := &ReversibleTransfer{
+		ID:        stub.GetTxID(),
+		From:      from,
+		To:        to,
+		Value:     value,
+		Timestamp: timestamp,
+		FinalAt:   sent.Add(window).Format(time.RFC3339Nano),
+	}
+	key, err := stub.CreateCompositeKey(reversibleObjectType, []string{reversible.ID})
+	if err != nil {
+		return nil, err
+	}
+	err = batch.putState(key, reversible)
+	if err != nil {
+		return nil, err
+	}
+
+	err = batch.flush()
+	if err != nil {
+		return nil, err
+	}
+
+	return reversible, nil
+}
+
+// openEscrow creates EscrowAccount on the first reversible transfer
+func openEscrow(batch *writeBatch) error {
+	_, err := batch.getUser(EscrowAccount)
+	if !errors.Is(err, ErrAccountNotFound) {
+		return err
+	}
+
+	return batch.putUser(&User{ID: EscrowAccount, Type: "escrow"})
+}
+
+// GetReversibleTransfer returns the reversible transfer sent by transaction
+// id while it is in escrow
+func (s *SmartContract) GetReversibleTransfer(ctx contractapi.TransactionContextInterface, id string) (*ReversibleTransfer, error) {
+	if err := validate(validation.ID("id", id)); err != nil {
+		return nil, err
+	}
+
+	_, reversible, err := reversibleTransfer(newWriteBatch(ctx), id)
+	return reversible, err
+}
+
+// reversibleTransfer reads the reversible transfer sent by transaction id
+// and returns its key
+func reversibleTransfer(batch *writeBatch, id string) (string, *ReversibleTransfer, error) {
+	key, err := batch.ctx.GetStub().CreateCompositeKey(reversibleObjectType, []string{id})
+	if err != nil {
+		return "", nil, err
+	}
+	data, err := batch.getState(key)
+	if err != nil {
+		return "", nil, err
+	}
+	if data == nil {
+		return "", nil, newError(CodeTransactionNotFound, "no reversible transfer %s is in escrow", id)
+	}
+
+	var reversible ReversibleTransfer
+	err = decodeRecord(key, data, &reversible, "id", "from", "to", "value", "finalAt")
+	if err != nil {
+		return "", nil, err
+	}
+
+	return key, &reversible, nil
+}
+
+// final reports whether the dispute window of r has ended at the proposal
+// timestamp
+func (r *ReversibleTransfer) final(ctx contractapi.TransactionContextInterface, key string) (bool, error) {
+	timestamp, err := txTimestamp(ctx.GetStub())
+	if err != nil {
+		return false, err
+	}
+	now, err := time.Parse(time.RFC3339Nano, timestamp)
+	if err != nil {
+		return false, newError(CodeInvalidArgument, "a reversible transfer needs the proposal timestamp")
+	}
+	finalAt, err := time.Parse(time.RFC3339Nano, r.FinalAt)
+	if err != nil {
+		return false, corrupt(key, err)
+	}
+
+	return !now.Before(finalAt), nil
+}
+
+// isArbiter reports whether the client certificate carries ArbiterOU
+func isArbiter(ctx contractapi.TransactionContextInterface) bool {
+	return hasRole(ctx, ArbiterOU)
+}
+
+// ReverseTransfer returns the tokens of the reversible transfer sent by
+// transaction id to its sender. Only the holder of the sending account or
+// an arbiter can call it, within the dispute window.
+func (s *SmartContract) ReverseTransfer(ctx contractapi.TransactionContextInterface, id string) (*Transaction, error) {
+	if err := validate(validation.ID("id", id)); err != nil {
+		return nil, err
+	}
+	if err := requireInitialized(ctx); err != nil {
+		return nil, err
+	}
+
+	batch := newWriteBatch(ctx)
+	key, reversible, err := reversibleTransfer(batch, id)
+	if err != nil {
+		return nil, err
+	}
+	if !isAccountHolder(ctx, reversible.From) && !isArbiter(ctx) {
+		return nil, newError(CodeUnauthorized, "only the holder of %s or an arbiter can reverse transfer %s", reversible.From, id)
+	}
+	if err := requireUndisputed(batch, id); err != nil {
+		return nil, err
+	}
+	final, err := reversible.final(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+	if final {
+		return nil, newError(CodeInvalidArgument, "transfer %s became final at %s", id, reversible.FinalAt)
+	}
+
+	return releaseEscrow(batch, key, reversible.From, reversible.Value)
+}
+
+// ClaimTransfer pays the reversible transfer sent by transaction id to its
+// recipient once its dispute window has ended, unless it is disputed, see
+// dispute.go. Anyone can call it.
+func (s *SmartContract) ClaimTransfer(ctx contractapi.TransactionContextInterface, id string) (*Transaction, error) {
+	if err := validate(validation.ID("id", id)); err != nil {
+		return nil, err
+	}
+	if err := requireInitialized(ctx); err != nil {
+		return nil, err
+	}
+
+	batch := newWriteBatch(ctx)
+	key, reversible, err := reversibleTransfer(batch, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := requireUndisputed(batch, id); err != nil {
+		return nil, err
+	}
+	final, err := reversible.final(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+	if !final {
+		return nil, newError(CodeInvalidArgument, "transfer %s can be reversed until %s", id, reversible.FinalAt)
+	}
+
+	return releaseEscrow(batch, key, reversible.To, reversible.Value)
+}
+
+// releaseEscrow pays value from escrow to account and drops the reversible
+// transfer under key
+func releaseEscrow(batch *writeBatch, key string, account string, value int) (*Transaction, error) {
+	batch.delState(key)
+
+	transaction, err := moveTokens(batch, EscrowAccount, account, value, true)
+	if err != nil {
+		return nil, fmt.Errorf("failed to transfer: %w", err)
+	}
+
+	err = batch.flush()
+	if err != nil {
+		return nil, err
+	}
+
+	return transaction, nil
+}
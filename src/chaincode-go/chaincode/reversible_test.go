@@ -0,0 +1,122 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package chaincode_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/kkiu1756/my_fabric/src/chaincode-go/chaincode"
+	"github.com/kkiu1756/my_fabric/src/chaincode-go/tokentest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// #########
+// HELPERS
+// #########
+
+var reversibleTime = time.Date(2021, 3, 1, 12, 0, 0, 0, time.UTC)
+
+// reversibleLedger returns a ledger with alice 100 and bob 0 and a one day
+// dispute window, where alice sent bob 30 reversibly in transaction "send"
+func reversibleLedger(t *testing.T) *tokentest.Ledger {
+	l := adminLedger(t).
+		WithAccount("alice", "user", 100).
+		WithAccount("bob", "user", 0).
+		WithTimestamp(reversibleTime)
+	contract := &chaincode.SmartContract{}
+	_, err := contract.SetDisputeWindow(l.Context, "24h")
+	require.NoError(t, err)
+
+	l.WithTxID("send")
+	_, err = contract.TransferReversible(l.Context, "alice", "bob", 30)
+	require.NoError(t, err)
+	return l
+}
+
+// #########
+// TESTS
+// #########
+
+func TestClaimTransfer(t *testing.T) {
+	l := reversibleLedger(t)
+	contract := &chaincode.SmartContract{}
+
+	reversible, err := contract.GetReversibleTransfer(l.Context, "send")
+	require.NoError(t, err)
+	assert.Equal(t, "2021-03-02T12:00:00Z", reversible.FinalAt)
+	l.AssertTransaction("send", "alice", chaincode.EscrowAccount, 30)
+	l.AssertBalance("alice", 70)
+	l.AssertBalance(chaincode.EscrowAccount, 30)
+	l.AssertTotal(100)
+
+	_, err = contract.ClaimTransfer(l.Context, "send")
+	assert.EqualError(t, err, "[INVALID_ARGUMENT] transfer send can be reversed until 2021-03-02T12:00:00Z")
+
+	l.WithTimestamp(reversibleTime.Add(24 * time.Hour)).WithTxID("claim")
+	_, err = contract.ClaimTransfer(l.Context, "send")
+	require.NoError(t, err)
+	l.AssertTransaction("claim", chaincode.EscrowAccount, "bob", 30)
+	l.AssertBalance("bob", 30)
+	l.AssertBalance(chaincode.EscrowAccount, 0)
+
+	_, err = contract.ClaimTransfer(l.Context, "send")
+	assert.True(t, errors.Is(err, chaincode.ErrTransactionNotFound), "a transfer is claimed once")
+}
+
+func TestReverseTransfer(t *testing.T) {
+	tests := []struct {
+		name   string
+		caller []string
+		err    string
+	}{
+		{"sender", []string{"Org1MSP", "alice", "client"}, ""},
+		{"arbiter", []string{"Org1MSP", "arbiter1", "client", chaincode.ArbiterOU}, ""},
+		{"other org's arbiter", []string{"Org2MSP", "arbiter1", "client", chaincode.ArbiterOU}, "[UNAUTHORIZED] only the holder of alice or an arbiter can reverse transfer send"},
+		{"recipient", []string{"Org1MSP", "bob", "client"}, "[UNAUTHORIZED] only the holder of alice or an arbiter can reverse transfer send"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l := reversibleLedger(t)
+			l.WithCaller(tt.caller[0], tt.caller[1], tt.caller[2:]...).WithTxID("reverse")
+
+			_, err := (&chaincode.SmartContract{}).ReverseTransfer(l.Context, "send")
+			if tt.err != "" {
+				assert.EqualError(t, err, tt.err)
+				return
+			}
+			require.NoError(t, err)
+			l.AssertTransaction("reverse", chaincode.EscrowAccount, "alice", 30)
+			l.AssertBalance("alice", 100)
+			l.AssertBalance("bob", 0)
+		})
+	}
+}
+
+func TestReverseTransferAfterWindow(t *testing.T) {
+	l := reversibleLedger(t)
+	l.WithCaller("Org1MSP", "alice", "client").WithTimestamp(reversibleTime.Add(24 * time.Hour))
+
+	_, err := (&chaincode.SmartContract{}).ReverseTransfer(l.Context, "send")
+	assert.EqualError(t, err, "[INVALID_ARGUMENT] transfer send became final at 2021-03-02T12:00:00Z")
+}
+
+func TestEscrowAccountIsReserved(t *testing.T) {
+	l := reversibleLedger(t)
+	contract := &chaincode.SmartContract{}
+
+	_, err := contract.TransferFrom(l.Context, chaincode.EscrowAccount, "bob", 30)
+	assert.EqualError(t, err, "failed to transfer: [INVALID_ARGUMENT] account _escrow only takes reversible transfers")
+	_, err = contract.TransferReversible(l.Context, "alice", chaincode.EscrowAccount, 30)
+	assert.EqualError(t, err, "[INVALID_ARGUMENT] account _escrow only takes reversible transfers")
+
+	_, err = contract.SetDisputeWindow(l.Context, "")
+	require.NoError(t, err)
+	_, err = contract.TransferReversible(l.Context, "alice", "bob", 30)
+	assert.EqualError(t, err, "[INVALID_ARGUMENT] reversible transfers are disabled, an organization admin must call SetDisputeWindow")
+}
@@ -0,0 +1,191 @@
+package chaincode
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+
+	"github.com/hyperledger/fabric-chaincode-go/pkg/cid"
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+	"github.com/kkiu1756/my_fabric/src/chaincode-go/validation"
+)
+
+// An org admin revokes a compromised client certificate with
+// RevokeCertificate, naming it by its fingerprint, the hex encoded SHA-256
+// digest of its DER encoding. The contract's before transaction hook, see
+// auditor.go, rejects every call signed by a revoked certificate, so the
+// revocation takes effect on the next block rather than when the updated
+// CRL reaches every peer's MSP. ReinstateCertificate undoes a revocation
+// made in error.
+
+// EventCertificateRevoked and EventCertificateReinstated are the events of
+// a revocation and of its reversal
+const (
+	EventCertificateRevoked    = "CertificateRevoked"
+	EventCertificateReinstated = "CertificateReinstated"
+)
+
+// revokedCertObjectType keys the revoked certificates by fingerprint
+const revokedCertObjectType = "revokedcert"
+
+// RevokedCertificate is the revocation of a client certificate
+type RevokedCertificate struct {
+	// Fingerprint is the hex encoded SHA-256 digest of the certificate
+	Fingerprint string `json:"fingerprint"`
+	Reason      string `json:"reason,omitempty" metadata:"reason,optional"`
+	TXID        string `json:"txId"`
+	// Timestamp is the proposal timestamp of the revocation
+	Timestamp    string `json:"txTimestamp"`
+	RevokerMSPID string `json:"revokerMspId"`
+	Revoker      string `json:"revoker"`
+}
+
+// RevokeCertificate rejects every later call signed by the certificate
+// with fingerprint, for reason. Only an org admin can call it.
+func (s *SmartContract) RevokeCertificate(ctx contractapi.TransactionContextInterface, fingerprint string, reason string) (*RevokedCertificate, error) {
+	err := validate(
+		digestError("fingerprint", fingerprint),
+		validation.Memo("reason", reason),
+	)
+	if err != nil {
+		return nil, err
+	}
+	if err := requireInitialized(ctx); err != nil {
+		return nil, err
+	}
+	if _, err := requireAdmin(ctx, "revoke certificates"); err != nil {
+		return nil, err
+	}
+
+	fingerprint = strings.ToLower(fingerprint)
+	key, err := revokedCertKey(ctx, fingerprint)
+	if err != nil {
+		return nil, err
+	}
+	data, err := getState(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+	if data != nil {
+		return nil, newError(CodeInvalidArgument, "certificate %s is revoked", fingerprint)
+	}
+
+	stub := ctx.GetStub()
+	timestamp, err := txTimestamp(stub)
+	if err != nil {
+		return nil, err
+	}
+	revoked := &RevokedCertificate{
+		Fingerprint:  fingerprint,
+		Reason:       reason,
+		TXID:         stub.GetTxID(),
+		Timestamp:    timestamp,
+		RevokerMSPID: creatorMSPID(stub),
+		Revoker:      creatorID(stub),
+	}
+	err = putState(ctx, key, revoked)
+	if err != nil {
+		return nil, err
+	}
+	err = emitEvent(ctx, EventCertificateRevoked, revoked)
+	if err != nil {
+		return nil, err
+	}
+
+	return revoked, nil
+}
+
+// ReinstateCertificate lifts the revocation of the certificate with
+// fingerprint. Only an org admin can call it.
+func (s *SmartContract) ReinstateCertificate(ctx contractapi.TransactionContextInterface, fingerprint string) error {
+	if err := validate(digestError("fingerprint", fingerprint)); err != nil {
+		return err
+	}
+	if err := requireInitialized(ctx); err != nil {
+		return err
+	}
+	if _, err := requireAdmin(ctx, "reinstate certificates"); err != nil {
+		return err
+	}
+
+	fingerprint = strings.ToLower(fingerprint)
+	revoked, err := s.GetRevokedCertificate(ctx, fingerprint)
+	if err != nil {
+		return err
+	}
+	key, err := revokedCertKey(ctx, fingerprint)
+	if err != nil {
+		return err
+	}
+	batch := newWriteBatch(ctx)
+	batch.delState(key)
+	err = emitEvent(ctx, EventCertificateReinstated, revoked)
+	if err != nil {
+		return err
+	}
+
+	return batch.flush()
+}
+
+// GetRevokedCertificate returns the revocation of the certificate with
+// fingerprint
+func (s *SmartContract) GetRevokedCertificate(ctx contractapi.TransactionContextInterface, fingerprint string) (*RevokedCertificate, error) {
+	if err := validate(digestError("fingerprint", fingerprint)); err != nil {
+		return nil, err
+	}
+
+	key, err := revokedCertKey(ctx, strings.ToLower(fingerprint))
+	if err != nil {
+		return nil, err
+	}
+	data, err := getState(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+	if data == nil {
+		return nil, newError(CodeInvalidArgument, "certificate %s is not revoked", fingerprint)
+	}
+
+	var revoked RevokedCertificate
+	err = decodeRecord(key, data, &revoked, "fingerprint", "txId")
+	if err != nil {
+		return nil, err
+	}
+
+	return &revoked, nil
+}
+
+// checkRevoked fails with UNAUTHORIZED if the client certificate is
+// revoked. A creator without a certificate is left to the MSP.
+func checkRevoked(ctx contractapi.TransactionContextInterface) error {
+	caller, err := cid.New(ctx.GetStub())
+	if err != nil {
+		return nil
+	}
+	cert, err := caller.GetX509Certificate()
+	if err != nil || cert == nil {
+		return nil
+	}
+
+	digest := sha256.Sum256(cert.Raw)
+	fingerprint := hex.EncodeToString(digest[:])
+	key, err := revokedCertKey(ctx, fingerprint)
+	if err != nil {
+		return err
+	}
+	data, err := getState(ctx, key)
+	if err != nil {
+		return err
+	}
+	if data != nil {
+		return newError(CodeUnauthorized, "certificate %s is revoked", fingerprint)
+	}
+
+	return nil
+}
+
+// revokedCertKey returns the key of the revocation of the certificate with
+// fingerprint
+func revokedCertKey(ctx contractapi.TransactionContextInterface, fingerprint string) (string, error) {
+	return ctx.GetStub().CreateCompositeKey(revokedCertObjectType, []string{fingerprint})
+}
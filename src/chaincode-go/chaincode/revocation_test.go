@@ -0,0 +1,59 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package chaincode_test
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+	"testing"
+
+	"github.com/hyperledger/fabric-chaincode-go/pkg/cid"
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+	"github.com/kkiu1756/my_fabric/src/chaincode-go/chaincode"
+	"github.com/kkiu1756/my_fabric/src/chaincode-go/tokentest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRevokeCertificate(t *testing.T) {
+	l := adminLedger(t)
+	contract := &chaincode.SmartContract{}
+	before := contract.GetBeforeTransaction().(func(contractapi.TransactionContextInterface) error)
+
+	compromised := tokentest.Identity(t, "Org1MSP", "alice", "client")
+	l.Stub.GetCreatorReturns(compromised, nil)
+	caller, err := cid.New(l.Stub)
+	require.NoError(t, err)
+	cert, err := caller.GetX509Certificate()
+	require.NoError(t, err)
+	digest := sha256.Sum256(cert.Raw)
+	fingerprint := hex.EncodeToString(digest[:])
+	require.NoError(t, before(l.Context))
+
+	_, err = contract.RevokeCertificate(l.Context, fingerprint, "key leaked")
+	assert.EqualError(t, err, "[UNAUTHORIZED] only an organization admin can revoke certificates")
+
+	l.WithCaller("Org1MSP", "Admin@org1.example.com", chaincode.AdminOU).WithTxID("revoke")
+	revoked, err := contract.RevokeCertificate(l.Context, strings.ToUpper(fingerprint), "key leaked")
+	require.NoError(t, err)
+	assert.Equal(t, fingerprint, revoked.Fingerprint)
+	assert.Equal(t, "Org1MSP", revoked.RevokerMSPID)
+	_, err = contract.RevokeCertificate(l.Context, fingerprint, "")
+	assert.EqualError(t, err, "[INVALID_ARGUMENT] certificate "+fingerprint+" is revoked")
+	require.NoError(t, before(l.Context), "other certificates are unaffected")
+
+	l.Stub.GetCreatorReturns(compromised, nil)
+	assert.EqualError(t, before(l.Context), "[UNAUTHORIZED] certificate "+fingerprint+" is revoked")
+	l.WithCaller("Org1MSP", "alice", "client")
+	require.NoError(t, before(l.Context), "a reissued certificate is not revoked")
+
+	l.WithCaller("Org1MSP", "Admin@org1.example.com", chaincode.AdminOU)
+	require.NoError(t, contract.ReinstateCertificate(l.Context, fingerprint))
+	_, err = contract.GetRevokedCertificate(l.Context, fingerprint)
+	assert.EqualError(t, err, "[INVALID_ARGUMENT] certificate "+fingerprint+" is not revoked")
+	l.Stub.GetCreatorReturns(compromised, nil)
+	require.NoError(t, before(l.Context))
+}
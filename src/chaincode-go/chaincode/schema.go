@@ -0,0 +1,132 @@
+package chaincode
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// Schema versions of the account and transaction records. A chaincode
+// upgrade that changes their layout adds a version and the upgrade from the
+// previous one; MigrateState applies it to the stored records. Amounts are
+// ints in every version: string amounts need a chaincode that reads and
+// writes them before a version can rewrite the records to them.
+const (
+	// SchemaV1 transaction records have no type, timestamp, channel or
+	// initiator
+	SchemaV1 = 1
+	// SchemaV2 transaction records carry their TransactionType
+	SchemaV2 = 2
+	// CurrentSchema is the version this chaincode writes
+	CurrentSchema = SchemaV2
+)
+
+// schemaUpgrades maps a version to the rewrite of its records into the next
+// one, given the key and data of every record stored under a simple key
+var schemaUpgrades = map[int]rewriteFunc{
+	SchemaV1: upgradeTransactionType,
+}
+
+// schemaVersion returns the version of the records of the ledger init
+// describes
+func schemaVersion(init *Initialization) int {
+	if init.Schema == 0 {
+		return SchemaV1
+	}
+	return init.Schema
+}
+
+// MigrateState upgrades the records stored under simple keys from schema
+// fromVersion to toVersion, the next version, visiting at most pageSize
+// records from bookmark, which is empty for the first step. Records already
+// in the new layout are left alone, so an interrupted migration can be
+// rerun. The step returning an empty bookmark records toVersion as the
+// ledger's schema. Only an org admin can call it.
+//
+// Like MigrateRecords, run it when the ledger is quiet.
+func (s *SmartContract) MigrateState(ctx contractapi.TransactionContextInterface, fromVersion int, toVersion int, pageSize int, bookmark string) (*MigrationPage, error) {
+	if err := requireMigration(ctx); err != nil {
+		return nil, err
+	}
+	_, ok := schemaUpgrades[fromVersion]
+	if !ok || toVersion != fromVersion+1 {
+		return nil, newError(CodeInvalidArgument, "no migration from schema v%d to v%d", fromVersion, toVersion)
+	}
+	init, err := ledgerConfig(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if version := schemaVersion(init); version != fromVersion {
+		return nil, newError(CodeInvalidArgument, "ledger is at schema v%d, not v%d", version, fromVersion)
+	}
+
+	return migrateSchema(ctx, init, pageSize, bookmark)
+}
+
+// migrateSchema runs one MigrateState step upgrading the ledger described
+// by init to the next schema version, updating init after the last step
+func migrateSchema(ctx contractapi.TransactionContextInterface, init *Initialization, pageSize int, bookmark string) (*MigrationPage, error) {
+	pageSize, err := queryPolicy.pageSize(pageSize)
+	if err != nil {
+		return nil, err
+	}
+
+	iterator, err := ctx.GetStub().GetStateByRange(bookmark, maxUnicodeRune)
+	if err != nil {
+		return nil, &StateError{Op: OpQueryState, Key: bookmark, Err: err}
+	}
+
+	version := schemaVersion(init)
+	page, err := migrate(ctx, iterator, pageSize, "", true, schemaUpgrades[version])
+	if err != nil {
+		return nil, err
+	}
+
+	if page.Bookmark == "" {
+		init.Schema = version + 1
+		err = putState(ctx, InitializationKey, init)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return page, nil
+}
+
+// upgradeTransactionType sets the type of a SchemaV1 transaction record from
+// its accounts: a record from "" is a mint, one to "" a burn, any other a
+// transfer. The proposal fields of the original transaction are lost and
+// stay empty.
+func upgradeTransactionType(key string, data []byte) (interface{}, error) {
+	if isProto(data) || isAccountRecord(data) {
+		return nil, nil
+	}
+
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, corrupt(key, err)
+	}
+	if _, ok := fields["type"]; ok {
+		return nil, nil
+	}
+
+	var transaction Transaction
+	err := decodeRecord(key, data, &transaction, "txId", "from", "to", "value")
+	if err != nil {
+		return nil, err
+	}
+
+	switch {
+	case transaction.From == "" && transaction.To == "":
+		return nil, corrupt(key, fmt.Errorf("transaction has neither from nor to"))
+	case transaction.From == "":
+		transaction.Type = TransactionMint
+	case transaction.To == "":
+		transaction.Type = TransactionBurn
+	default:
+		transaction.Type = TransactionTransfer
+	}
+
+	return &transaction, nil
+}
@@ -0,0 +1,141 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package chaincode_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/hyperledger/fabric-chaincode-go/shimtest"
+	"github.com/kkiu1756/my_fabric/src/chaincode-go/chaincode"
+	"github.com/kkiu1756/my_fabric/src/chaincode-go/tokentest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// #########
+// HELPERS
+// #########
+
+// legacyLedger returns a mock stub whose ledger predates schema versions,
+// holding SchemaV1 records of a mint, a transfer and a burn
+func legacyLedger(t *testing.T) *shimtest.MockStub {
+	stub := newMockStub(t)
+
+	var init chaincode.Initialization
+	require.NoError(t, json.Unmarshal(stub.State[chaincode.InitializationKey], &init))
+	init.Schema = 0
+	records := map[string]interface{}{chaincode.InitializationKey: init}
+	for _, tx := range []string{
+		`{"txId":"mint","from":"","to":"alice","value":100}`,
+		`{"txId":"pay","from":"alice","to":"bob","value":30}`,
+		`{"txId":"burn","from":"bob","to":"","value":5}`,
+	} {
+		var record map[string]interface{}
+		require.NoError(t, json.Unmarshal([]byte(tx), &record))
+		records[record["txId"].(string)] = record
+	}
+
+	stub.MockTransactionStart("legacy")
+	for key, record := range records {
+		data, err := json.Marshal(record)
+		require.NoError(t, err)
+		require.NoError(t, stub.PutState(key, data))
+	}
+	stub.MockTransactionEnd("legacy")
+
+	return stub
+}
+
+// migrateState runs MigrateState from v1 to v2 a step of pageSize records
+// at a time
+func migrateState(t *testing.T, stub *shimtest.MockStub, pageSize string) int {
+	migrated := 0
+	bookmark := ""
+	for {
+		var page chaincode.MigrationPage
+		require.NoError(t, json.Unmarshal(invokeTx(t, stub, "schema"+pageSize+bookmark, "MigrateState", "1", "2", pageSize, bookmark), &page))
+		migrated += page.Migrated
+		if page.Bookmark == "" {
+			return migrated
+		}
+		bookmark = page.Bookmark
+	}
+}
+
+// #########
+// TESTS
+// #########
+
+func TestMigrateState(t *testing.T) {
+	stub := legacyLedger(t)
+
+	assert.Equal(t, 3, migrateState(t, stub, "1"), "mint, pay and burn, the other records are current")
+
+	types := map[string]chaincode.TransactionType{
+		"mint": chaincode.TransactionMint,
+		"pay":  chaincode.TransactionTransfer,
+		"burn": chaincode.TransactionBurn,
+	}
+	for txid, txType := range types {
+		var tx chaincode.Transaction
+		require.NoError(t, json.Unmarshal(invokeTx(t, stub, "read", "GetTransaction", txid), &tx))
+		assert.Equal(t, txType, tx.Type, txid)
+	}
+	assert.Equal(t, 100, stubBalance(t, stub, "alice"))
+
+	var init chaincode.Initialization
+	require.NoError(t, json.Unmarshal(stub.State[chaincode.InitializationKey], &init))
+	assert.Equal(t, chaincode.SchemaV2, init.Schema)
+
+	resp := stub.MockInvoke("again", [][]byte{[]byte("MigrateState"), []byte("1"), []byte("2"), []byte("0"), []byte("")})
+	assert.Equal(t, "[INVALID_ARGUMENT] ledger is at schema v2, not v1", resp.Message)
+}
+
+func TestMigrateStateResumes(t *testing.T) {
+	stub := legacyLedger(t)
+
+	var page chaincode.MigrationPage
+	require.NoError(t, json.Unmarshal(invokeTx(t, stub, "step", "MigrateState", "1", "2", "2", ""), &page))
+	require.NotEmpty(t, page.Bookmark)
+
+	var init chaincode.Initialization
+	require.NoError(t, json.Unmarshal(stub.State[chaincode.InitializationKey], &init))
+	assert.Zero(t, init.Schema, "the schema should change with the last step only")
+
+	assert.Equal(t, 3, page.Migrated+migrateState(t, stub, "2"))
+}
+
+func TestMigrateStateRejects(t *testing.T) {
+	tests := []struct {
+		name string
+		args []string
+		err  string
+	}{
+		{"unknown version", []string{"2", "3", "0", ""}, "[INVALID_ARGUMENT] no migration from schema v2 to v3"},
+		{"skipped version", []string{"1", "3", "0", ""}, "[INVALID_ARGUMENT] no migration from schema v1 to v3"},
+		{"page size", []string{"1", "2", "1001", ""}, "[INVALID_ARGUMENT] invalid pageSize: must be between 1 and 1000, or 0 for 100"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stub := legacyLedger(t)
+
+			invocation := [][]byte{[]byte("MigrateState")}
+			for _, arg := range tt.args {
+				invocation = append(invocation, []byte(arg))
+			}
+			assert.Equal(t, tt.err, stub.MockInvoke("tx", invocation).Message)
+		})
+	}
+}
+
+func TestMigrateStateRequiresAdmin(t *testing.T) {
+	stub := legacyLedger(t)
+	stub.Creator = tokentest.Identity(t, "Org1MSP", "User1@org1.example.com", "client")
+
+	resp := stub.MockInvoke("tx", [][]byte{[]byte("MigrateState"), []byte("1"), []byte("2"), []byte("0"), []byte("")})
+	assert.Equal(t, "[UNAUTHORIZED] only an organization admin can migrate records", resp.Message)
+}
@@ -0,0 +1,382 @@
+package chaincode
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+	"github.com/hyperledger/fabric-protos-go/ledger/queryresult"
+	"github.com/hyperledger/fabric-protos-go/peer"
+	"github.com/kkiu1756/my_fabric/src/chaincode-go/validation"
+)
+
+// A seller account accumulates its receivables as its balance, net of the
+// refunds it pays. Its holder or an org admin names the settlement account
+// its payouts go to, and a settlement operator, an identity with the
+// SettlementOU or an org admin, pays out the sellers of a period with
+// SettleSellers, MaxSettlementBatch sellers per call. A seller is paid once
+// per period, so a batch can be resubmitted. A hot seller's pending
+// credits are paid out in the period after they are pruned, and a
+// suspended seller is skipped.
+//
+// The payouts of a batch are statement lines of type LineSettlement, one
+// per seller and one per settlement account for the sum it received, and
+// GetSettlementReport returns a period's totals and payouts.
+
+// SettlementOU is the organizational unit of settlement operators
+const SettlementOU = "settlement"
+
+// MaxSettlementBatch is the most sellers one SettleSellers call pays out
+const MaxSettlementBatch = 50
+
+// LineSettlement is the type of the statement lines of a payout
+const LineSettlement = "settlement"
+
+const (
+	// settlementAccountObjectType keys the settlement accounts by seller
+	settlementAccountObjectType = "settlementaccount"
+	// settlementPeriodObjectType keys the period totals by period
+	settlementPeriodObjectType = "settlementperiod"
+	// payoutObjectType keys the payouts by period and seller
+	payoutObjectType = "payout"
+)
+
+// SettlementAccount is where a seller's payouts go
+type SettlementAccount struct {
+	Seller  string `json:"seller"`
+	Account string `json:"account"`
+}
+
+// Payout is a seller's payout in a period
+type Payout struct {
+	Period  string `json:"period"`
+	Seller  string `json:"seller"`
+	Account string `json:"account"`
+	Amount  int    `json:"amount"`
+	TXID    string `json:"txId"`
+	// Timestamp is the proposal timestamp of the payout, RFC 3339
+	Timestamp string `json:"timestamp"`
+}
+
+// SettlementPeriod is the totals of a period
+type SettlementPeriod struct {
+	Period  string `json:"period"`
+	Sellers int    `json:"sellers"`
+	Total   int    `json:"total"`
+}
+
+// SettlementBatch is the result of a SettleSellers call
+type SettlementBatch struct {
+	Payouts []*Payout `json:"payouts"`
+	// Skipped lists the suspended sellers of the batch
+	Skipped []string `json:"skipped"`
+	// Settled lists the sellers of the batch already paid in the period
+	Settled []string `json:"settled"`
+}
+
+// SettlementReport is a page of a period's payouts with its totals
+type SettlementReport struct {
+	*SettlementPeriod
+	Payouts []*Payout `json:"payouts"`
+	// Bookmark continues the listing, empty after the last page
+	Bookmark string `json:"bookmark"`
+}
+
+// SetSettlementAccount names account as where the payouts of seller go.
+// Only the holder of seller or an org admin can call it.
+func (s *SmartContract) SetSettlementAccount(ctx contractapi.TransactionContextInterface, seller string, account string) (*SettlementAccount, error) {
+	err := validate(
+		validation.ID("seller", seller),
+		validation.AccountID("account", account),
+	)
+	if err != nil {
+		return nil, err
+	}
+	if err := requireInitialized(ctx); err != nil {
+		return nil, err
+	}
+	if !isAccountHolder(ctx, seller) {
+		if _, err := requireAdmin(ctx, "set the settlement account of another seller"); err != nil {
+			return nil, err
+		}
+	}
+
+	batch := newWriteBatch(ctx)
+	user, err := batch.getUser(seller)
+	if err != nil {
+		return nil, err
+	}
+	if user.Type != SellerType {
+		return nil, newError(CodeInvalidArgument, "account %s is not a seller", seller)
+	}
+	target, err := batch.getUser(account)
+	if err != nil {
+		return nil, err
+	}
+	if target.Type == SellerType {
+		return nil, newError(CodeInvalidArgument, "seller %s cannot be a settlement account", account)
+	}
+
+	key, err := ctx.GetStub().CreateCompositeKey(settlementAccountObjectType, []string{seller})
+	if err != nil {
+		return nil, err
+	}
+	settlement := &SettlementAccount{Seller: seller, Account: account}
+	err = batch.putState(key, settlement)
+	if err != nil {
+		return nil, err
+	}
+
+	err = batch.flush()
+	if err != nil {
+		return nil, err
+	}
+
+	return settlement, nil
+}
+
+// SettleSellers pays out the balances of the sellers in sellersJSON, a
+// JSON array of seller accounts, to their settlement accounts for period.
+// Only a settlement operator can call it.
+func (s *SmartContract) SettleSellers(ctx contractapi.TransactionContextInterface, period string, sellersJSON string) (*SettlementBatch, error) {
+	if err := validate(validation.ID("period", period)); err != nil {
+		return nil, err
+	}
+	sellers, err := decodeSellers(sellersJSON)
+	if err != nil {
+		return nil, err
+	}
+	if err := requireInitialized(ctx); err != nil {
+		return nil, err
+	}
+	if !isSettlementOperator(ctx) {
+		if _, err := requireAdmin(ctx, "settle sellers"); err != nil {
+			return nil, err
+		}
+	}
+
+	batch := newWriteBatch(ctx)
+	stub := ctx.GetStub()
+	timestamp, err := txTimestamp(stub)
+	if err != nil {
+		return nil, err
+	}
+	periodKey, totals, err := settlementPeriod(batch, period)
+	if err != nil {
+		return nil, err
+	}
+	if totals == nil {
+		totals = &SettlementPeriod{Period: period}
+	}
+
+	result := &SettlementBatch{Payouts: []*Payout{}, Skipped: []string{}, Settled: []string{}}
+	credits := map[string]int{}
+	for _, seller := range sellers {
+		payoutKey, err := stub.CreateCompositeKey(payoutObjectType, []string{period, seller})
+		if err != nil {
+			return nil, err
+		}
+		paid, err := batch.getState(payoutKey)
+		if err != nil {
+			return nil, err
+		}
+		if paid != nil {
+			result.Settled = append(result.Settled, seller)
+			continue
+		}
+
+		account, err := settlementAccount(batch, seller)
+		if err != nil {
+			return nil, err
+		}
+		frozen := checkSuspended(batch, seller)
+		if errors.Is(frozen, ErrAccountFrozen) {
+			result.Skipped = append(result.Skipped, seller)
+			continue
+		}
+		if frozen != nil {
+			return nil, frozen
+		}
+
+		user, err := batch.getUser(seller)
+		if err != nil {
+			return nil, err
+		}
+		err = settleDemurrage(batch, user)
+		if err != nil {
+			return nil, err
+		}
+		payout := &Payout{Period: period, Seller: seller, Account: account, Amount: user.Balance, TXID: stub.GetTxID(), Timestamp: timestamp}
+		if payout.Amount > 0 {
+			user.Balance = 0
+			err = indexBalanceChange(batch, seller, LineSettlement, account, -payout.Amount)
+			if err != nil {
+				return nil, err
+			}
+			credits[account] += payout.Amount
+		}
+		err = batch.putUser(user)
+		if err != nil {
+			return nil, err
+		}
+		err = batch.putState(payoutKey, payout)
+		if err != nil {
+			return nil, err
+		}
+
+		totals.Sellers++
+		totals.Total += payout.Amount
+		result.Payouts = append(result.Payouts, payout)
+	}
+
+	// credit each settlement account once, in order, for the sum it
+	// received
+	accounts := make([]string, 0, len(credits))
+	for account := range credits {
+		accounts = append(accounts, account)
+	}
+	sort.Strings(accounts)
+	for _, account := range accounts {
+		err = creditShare(batch, account, LineSettlement, "", credits[account])
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	err = batch.putState(periodKey, totals)
+	if err != nil {
+		return nil, err
+	}
+
+	err = batch.flush()
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// GetSettlementReport returns the totals of period and a page of its
+// payouts
+func (s *SmartContract) GetSettlementReport(ctx contractapi.TransactionContextInterface, period string, pageSize int, bookmark string) (*SettlementReport, error) {
+	if err := validate(validation.ID("period", period)); err != nil {
+		return nil, err
+	}
+	pageSize, err := queryPolicy.pageSize(pageSize)
+	if err != nil {
+		return nil, err
+	}
+
+	_, totals, err := settlementPeriod(newWriteBatch(ctx), period)
+	if err == nil && totals == nil {
+		err = newError(CodeInvalidArgument, "no sellers were settled in period %s", period)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	stub := ctx.GetStub()
+	query := func(pageSize int32, bookmark string) (shim.StateQueryIteratorInterface, *peer.QueryResponseMetadata, error) {
+		return stub.GetStateByPartialCompositeKeyWithPagination(payoutObjectType, []string{period}, pageSize, bookmark)
+	}
+
+	report := &SettlementReport{SettlementPeriod: totals, Payouts: []*Payout{}}
+	report.Bookmark, err = queryPolicy.scan(query, payoutObjectType, pageSize, bookmark, func(kv *queryresult.KV) error {
+		var payout Payout
+		if err := decodeRecord(kv.Key, kv.Value, &payout, "period", "seller", "account", "amount"); err != nil {
+			return err
+		}
+		report.Payouts = append(report.Payouts, &payout)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return report, nil
+}
+
+// isSettlementOperator reports whether the client has the SettlementOU
+func isSettlementOperator(ctx contractapi.TransactionContextInterface) bool {
+	return hasRole(ctx, SettlementOU)
+}
+
+// settlementAccount reads the settlement account of seller, failing if it
+// has none
+func settlementAccount(batch *writeBatch, seller string) (string, error) {
+	key, err := batch.ctx.GetStub().CreateCompositeKey(settlementAccountObjectType, []string{seller})
+	if err != nil {
+		return "", err
+	}
+	data, err := batch.getState(key)
+	if err != nil {
+		return "", err
+	}
+	if data == nil {
+		return "", newError(CodeInvalidArgument, "seller %s has no settlement account", seller)
+	}
+
+	var settlement SettlementAccount
+	err = decodeRecord(key, data, &settlement, "seller", "account")
+	if err != nil {
+		return "", err
+	}
+
+	return settlement.Account, nil
+}
+
+// settlementPeriod reads the totals of period, nil if no seller was
+// settled in it, and returns their key
+func settlementPeriod(batch *writeBatch, period string) (string, *SettlementPeriod, error) {
+	key, err := batch.ctx.GetStub().CreateCompositeKey(settlementPeriodObjectType, []string{period})
+	if err != nil {
+		return "", nil, err
+	}
+	data, err := batch.getState(key)
+	if err != nil || data == nil {
+		return key, nil, err
+	}
+
+	var totals SettlementPeriod
+	err = decodeRecord(key, data, &totals, "period", "sellers", "total")
+	if err != nil {
+		return "", nil, err
+	}
+
+	return key, &totals, nil
+}
+
+// decodeSellers strictly decodes and validates the sellers of a batch
+func decodeSellers(sellersJSON string) ([]string, error) {
+	decoder := json.NewDecoder(bytes.NewReader([]byte(sellersJSON)))
+	decoder.DisallowUnknownFields()
+
+	var sellers []string
+	if err := decoder.Decode(&sellers); err != nil {
+		return nil, validate(&validation.Error{Field: "sellers", Reason: err.Error()})
+	}
+	if len(sellers) == 0 {
+		return nil, validate(&validation.Error{Field: "sellers", Reason: "must not be empty"})
+	}
+	if len(sellers) > MaxSettlementBatch {
+		return nil, validate(&validation.Error{Field: "sellers", Reason: fmt.Sprintf("must hold at most %d sellers", MaxSettlementBatch)})
+	}
+
+	seen := make(map[string]bool, len(sellers))
+	for i, seller := range sellers {
+		field := fmt.Sprintf("sellers[%d]", i)
+		if err := validate(validation.ID(field, seller)); err != nil {
+			return nil, err
+		}
+		if seen[seller] {
+			return nil, validate(&validation.Error{Field: field, Reason: fmt.Sprintf("duplicate seller %s", seller)})
+		}
+		seen[seller] = true
+	}
+
+	return sellers, nil
+}
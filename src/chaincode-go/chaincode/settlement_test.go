@@ -0,0 +1,109 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package chaincode_test
+
+import (
+	"testing"
+
+	"github.com/kkiu1756/my_fabric/src/chaincode-go/chaincode"
+	"github.com/kkiu1756/my_fabric/src/chaincode-go/tokentest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// #########
+// HELPERS
+// #########
+
+// settlementLedger returns a settlement operator's ledger with the sellers
+// shop and cafe, holding 70 and 30, both settled to the bank account
+func settlementLedger(t *testing.T) *tokentest.Ledger {
+	l := adminLedger(t).
+		WithAccount("shop", chaincode.SellerType, 70).
+		WithAccount("cafe", chaincode.SellerType, 30).
+		WithAccount("bank", "settlement", 0)
+	paginate(l.Stub, l.State)
+
+	contract := &chaincode.SmartContract{}
+	for _, seller := range []string{"shop", "cafe"} {
+		_, err := contract.SetSettlementAccount(l.Context, seller, "bank")
+		require.NoError(t, err)
+	}
+	return l.WithCaller("Org1MSP", "Settler@org1.example.com", chaincode.SettlementOU)
+}
+
+// #########
+// TESTS
+// #########
+
+func TestSettleSellers(t *testing.T) {
+	l := settlementLedger(t)
+	contract := &chaincode.SmartContract{}
+
+	l.WithTxID("settle")
+	result, err := contract.SettleSellers(l.Context, "2021-09", `["shop","cafe"]`)
+	require.NoError(t, err)
+	require.Len(t, result.Payouts, 2)
+	assert.Equal(t, &chaincode.Payout{Period: "2021-09", Seller: "shop", Account: "bank", Amount: 70, TXID: "settle"}, result.Payouts[0])
+	l.AssertBalance("shop", 0)
+	l.AssertBalance("cafe", 0)
+	l.AssertBalance("bank", 100)
+
+	l.WithTxID("again")
+	result, err = contract.SettleSellers(l.Context, "2021-09", `["shop"]`)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"shop"}, result.Settled, "a seller should be paid once per period")
+
+	report, err := contract.GetSettlementReport(l.Context, "2021-09", 1, "")
+	require.NoError(t, err)
+	assert.Equal(t, &chaincode.SettlementPeriod{Period: "2021-09", Sellers: 2, Total: 100}, report.SettlementPeriod)
+	require.Len(t, report.Payouts, 1)
+	assert.Equal(t, "cafe", report.Payouts[0].Seller)
+	report, err = contract.GetSettlementReport(l.Context, "2021-09", 1, report.Bookmark)
+	require.NoError(t, err)
+	assert.Equal(t, "shop", report.Payouts[0].Seller)
+}
+
+func TestSettleSellersSkipsSuspended(t *testing.T) {
+	l := settlementLedger(t)
+	contract := &chaincode.SmartContract{}
+
+	l.WithCaller("Org1MSP", "Admin@org1.example.com", chaincode.AdminOU)
+	_, err := contract.SuspendAccount(l.Context, "cafe", "fraud", "", "")
+	require.NoError(t, err)
+
+	result, err := contract.SettleSellers(l.Context, "2021-09", `["shop","cafe"]`)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"cafe"}, result.Skipped)
+	l.AssertBalance("cafe", 30)
+	l.AssertBalance("bank", 70)
+}
+
+func TestSettlementRejects(t *testing.T) {
+	l := settlementLedger(t).WithAccount("alice", "user", 0)
+	contract := &chaincode.SmartContract{}
+
+	_, err := contract.SettleSellers(l.Context, "2021-09", `["alice"]`)
+	assert.EqualError(t, err, "[INVALID_ARGUMENT] seller alice has no settlement account")
+	_, err = contract.SettleSellers(l.Context, "2021-09", `["shop","shop"]`)
+	assert.EqualError(t, err, "[INVALID_ARGUMENT] invalid sellers[1]: duplicate seller shop")
+	_, err = contract.GetSettlementReport(l.Context, "2021-10", 0, "")
+	assert.EqualError(t, err, "[INVALID_ARGUMENT] no sellers were settled in period 2021-10")
+
+	l.WithCaller("Org1MSP", "shop", "client")
+	_, err = contract.SetSettlementAccount(l.Context, "shop", "cafe")
+	assert.EqualError(t, err, "[INVALID_ARGUMENT] seller cafe cannot be a settlement account")
+	_, err = contract.SetSettlementAccount(l.Context, "cafe", "alice")
+	assert.EqualError(t, err, "[UNAUTHORIZED] only an organization admin can set the settlement account of another seller")
+	_, err = contract.SettleSellers(l.Context, "2021-09", `["shop"]`)
+	assert.EqualError(t, err, "[UNAUTHORIZED] only an organization admin can settle sellers")
+	l.WithCaller("Org2MSP", "Settler@org2.example.com", chaincode.SettlementOU)
+	_, err = contract.SettleSellers(l.Context, "2021-09", `["shop"]`)
+	assert.EqualError(t, err, "[UNAUTHORIZED] only an organization admin can settle sellers")
+
+	l.WithCaller("Org1MSP", "alice", "client")
+	_, err = contract.SetSettlementAccount(l.Context, "alice", "shop")
+	assert.EqualError(t, err, "[INVALID_ARGUMENT] account alice is not a seller")
+}
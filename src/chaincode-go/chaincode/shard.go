@@ -0,0 +1,144 @@
+package chaincode
+
+import (
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+	"github.com/hyperledger/fabric-protos-go/ledger/queryresult"
+	"github.com/hyperledger/fabric-protos-go/peer"
+	"github.com/kkiu1756/my_fabric/src/chaincode-go/validation"
+)
+
+// By default an account is stored under its id. A ledger initialized with
+// InitializeSharded stores each account under the composite key
+// accountObjectType~shard~id instead, where shard is a hash bucket of the
+// id, so the accounts of every shard can be listed by its own range query
+// and a full enumeration or backup can run one worker per shard. The shard
+// count is fixed at initialization, since changing it would move every
+// account.
+const accountObjectType = "account"
+
+// MaxShards is the most shards a ledger can be initialized with
+const MaxShards = 256
+
+// AccountPage is one page of a shard's accounts
+type AccountPage struct {
+	Accounts []*User `json:"accounts"`
+	// Bookmark continues the listing, empty after the shard's last page
+	Bookmark string `json:"bookmark"`
+}
+
+// shardOf returns the shard of account id, an FNV-1a hash bucket
+func shardOf(id string, shards int) int {
+	h := fnv.New32a()
+	h.Write([]byte(id))
+	return int(h.Sum32() % uint32(shards))
+}
+
+// shardName formats a shard for composite keys, so the shards sort in order
+func shardName(shard int) string {
+	return fmt.Sprintf("%02x", shard)
+}
+
+// shardCount returns the ledger's shard count, 1 if it is not sharded or
+// not initialized
+func shardCount(ctx contractapi.TransactionContextInterface) (int, error) {
+	init, err := ledgerConfig(ctx)
+	if err != nil {
+		return 0, err
+	}
+	if init == nil || init.Shards < 2 {
+		return 1, nil
+	}
+
+	return init.Shards, nil
+}
+
+// accountKey returns the key account id is stored under
+func accountKey(ctx contractapi.TransactionContextInterface, id string) (string, error) {
+	shards, err := shardCount(ctx)
+	if err != nil {
+		return "", err
+	}
+	if shards == 1 {
+		return id, nil
+	}
+
+	return ctx.GetStub().CreateCompositeKey(accountObjectType, []string{shardName(shardOf(id, shards)), id})
+}
+
+// ShardCount returns the number of shards ListAccounts splits the accounts
+// into, 1 for a ledger that is not sharded
+func (s *SmartContract) ShardCount(ctx contractapi.TransactionContextInterface) (int, error) {
+	return shardCount(ctx)
+}
+
+// ListAccounts returns a page of at most pageSize accounts of shard, in key
+// order, starting at bookmark, which is empty for the first page. The page
+// size and iteration time are bounded by the query policy. The shards
+// are independent, so clients can list them in parallel. On a ledger that
+// is not sharded the only shard is 0 and its pages may hold fewer accounts
+// than pageSize, as the range also holds transaction records, which are
+// skipped.
+func (s *SmartContract) ListAccounts(ctx contractapi.TransactionContextInterface, shard int, pageSize int, bookmark string) (*AccountPage, error) {
+	shards, err := shardCount(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if shard < 0 || shard >= shards {
+		return nil, validate(&validation.Error{Field: "shard", Reason: fmt.Sprintf("must be between 0 and %d", shards-1)})
+	}
+	pageSize, err = queryPolicy.pageSize(pageSize)
+	if err != nil {
+		return nil, err
+	}
+
+	stub := ctx.GetStub()
+	query := func(pageSize int32, bookmark string) (shim.StateQueryIteratorInterface, *peer.QueryResponseMetadata, error) {
+		if shards == 1 {
+			return stub.GetStateByRangeWithPagination("", "", pageSize, bookmark)
+		}
+		return stub.GetStateByPartialCompositeKeyWithPagination(accountObjectType, []string{shardName(shard)}, pageSize, bookmark)
+	}
+
+	page := &AccountPage{Accounts: []*User{}}
+	page.Bookmark, err = queryPolicy.scan(query, shardName(shard), pageSize, bookmark, func(kv *queryresult.KV) error {
+		if shards == 1 && !isAccountRecord(kv.Value) {
+			return nil
+		}
+		var user User
+		err := decodeRecord(kv.Key, kv.Value, &user, "userId", "type", "balance")
+		if err != nil {
+			return err
+		}
+		err = rebaseUser(ctx, &user)
+		if err != nil {
+			return err
+		}
+		page.Accounts = append(page.Accounts, &user)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return page, nil
+}
+
+// isAccountRecord reports whether a simple key's record is an account
+// rather than a transaction
+func isAccountRecord(record []byte) bool {
+	if isProto(record) {
+		return recordKind(record) == protoKindUser
+	}
+
+	var fields map[string]json.RawMessage
+	if json.Unmarshal(record, &fields) != nil {
+		return false
+	}
+	_, ok := fields["userId"]
+	return ok
+}
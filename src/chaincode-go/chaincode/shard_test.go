@@ -0,0 +1,211 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package chaincode_test
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+	"github.com/hyperledger/fabric-protos-go/ledger/queryresult"
+	"github.com/hyperledger/fabric-protos-go/peer"
+	"github.com/kkiu1756/my_fabric/src/chaincode-go/chaincode"
+	"github.com/kkiu1756/my_fabric/src/chaincode-go/chaincode/mocks"
+	"github.com/kkiu1756/my_fabric/src/chaincode-go/tokentest"
+	"github.com/kkiu1756/my_fabric/src/chaincode-go/validation"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// #########
+// HELPERS
+// #########
+
+// kvIterator iterates over a fixed page of query results
+type kvIterator struct {
+	kvs []*queryresult.KV
+}
+
+func (it *kvIterator) HasNext() bool { return len(it.kvs) > 0 }
+func (it *kvIterator) Close() error  { return nil }
+
+func (it *kvIterator) Next() (*queryresult.KV, error) {
+	kv := it.kvs[0]
+	it.kvs = it.kvs[1:]
+	return kv, nil
+}
+
+// paginate serves the stub's range queries, paginated or not, from state.
+// The bookmark is the first key of the next page.
+func paginate(stub *mocks.ChaincodeStub, state map[string][]byte) {
+	page := func(start string, end string, pageSize int32, bookmark string) (shim.StateQueryIteratorInterface, *peer.QueryResponseMetadata, error) {
+		if bookmark != "" {
+			start = bookmark
+		}
+		var keys []string
+		for key := range state {
+			if key >= start && (end == "" || key < end) && (end != "" || !strings.HasPrefix(key, "\x00")) {
+				keys = append(keys, key)
+			}
+		}
+		sort.Strings(keys)
+
+		next := ""
+		if len(keys) > int(pageSize) {
+			next = keys[pageSize]
+			keys = keys[:pageSize]
+		}
+		it := &kvIterator{}
+		for _, key := range keys {
+			it.kvs = append(it.kvs, &queryresult.KV{Key: key, Value: state[key]})
+		}
+		return it, &peer.QueryResponseMetadata{FetchedRecordsCount: int32(len(keys)), Bookmark: next}, nil
+	}
+
+	stub.GetStateByRangeWithPaginationStub = page
+	stub.GetStateByPartialCompositeKeyWithPaginationStub = func(objectType string, attributes []string, pageSize int32, bookmark string) (shim.StateQueryIteratorInterface, *peer.QueryResponseMetadata, error) {
+		prefix, err := shim.CreateCompositeKey(objectType, attributes)
+		if err != nil {
+			return nil, nil, err
+		}
+		return page(prefix, prefix+"\U0010FFFF", pageSize, bookmark)
+	}
+	stub.GetStateByRangeStub = func(start string, end string) (shim.StateQueryIteratorInterface, error) {
+		it, _, err := page(start, end, int32(len(state)+1), "")
+		return it, err
+	}
+	stub.GetStateByPartialCompositeKeyStub = func(objectType string, attributes []string) (shim.StateQueryIteratorInterface, error) {
+		prefix, err := shim.CreateCompositeKey(objectType, attributes)
+		if err != nil {
+			return nil, err
+		}
+		it, _, err := page(prefix, prefix+"\U0010FFFF", int32(len(state)+1), "")
+		return it, err
+	}
+}
+
+// shardedLedger returns a ledger initialized with shards shards by an admin
+func shardedLedger(t *testing.T, shards int) *tokentest.Ledger {
+	l := adminLedger(t).Uninitialized()
+	paginate(l.Stub, l.State)
+
+	init, err := (&chaincode.SmartContract{}).InitializeSharded(l.Context, shards)
+	require.NoError(t, err)
+	assert.Equal(t, shards, init.Shards)
+	return l
+}
+
+// listAll lists every account of every shard, a page of pageSize at a time
+func listAll(t *testing.T, ctx *mocks.TransactionContext, pageSize int) map[int][]string {
+	contract := &chaincode.SmartContract{}
+	shards, err := contract.ShardCount(ctx)
+	require.NoError(t, err)
+
+	ids := map[int][]string{}
+	for shard := 0; shard < shards; shard++ {
+		bookmark := ""
+		for {
+			page, err := contract.ListAccounts(ctx, shard, pageSize, bookmark)
+			require.NoError(t, err)
+			for _, user := range page.Accounts {
+				ids[shard] = append(ids[shard], user.ID)
+			}
+			if page.Bookmark == "" {
+				break
+			}
+			bookmark = page.Bookmark
+		}
+	}
+	return ids
+}
+
+// #########
+// TESTS
+// #########
+
+func TestShardedAccounts(t *testing.T) {
+	l := shardedLedger(t, 4)
+	contract := &chaincode.SmartContract{}
+
+	for i := 0; i < 20; i++ {
+		_, err := contract.CreateUser(l.Context, fmt.Sprintf("user%d", i), "user", 10)
+		require.NoError(t, err)
+	}
+	_, err := contract.TransferFrom(l.Context, "user0", "user1", 4)
+	require.NoError(t, err)
+
+	for key := range l.State {
+		assert.False(t, strings.HasPrefix(key, "user"), "account stored under its plain id %q", key)
+	}
+	user, err := contract.GetUser(l.Context, "user1")
+	require.NoError(t, err)
+	assert.Equal(t, 14, user.Balance)
+
+	ids := listAll(t, l.Context, 3)
+	all := []string{}
+	for shard, shardIDs := range ids {
+		assert.True(t, sort.StringsAreSorted(shardIDs), "shard %d should list in key order", shard)
+		for _, id := range shardIDs {
+			key, err := shim.CreateCompositeKey("account", []string{fmt.Sprintf("%02x", shard), id})
+			require.NoError(t, err)
+			assert.Contains(t, l.State, key, "%s listed in the wrong shard", id)
+		}
+		all = append(all, shardIDs...)
+	}
+	assert.Len(t, all, 20)
+	assert.Greater(t, len(ids), 1, "accounts should spread over the shards")
+
+	require.NoError(t, contract.DeleteUser(l.Context, "user0"))
+	_, err = contract.GetUser(l.Context, "user0")
+	assert.True(t, errors.Is(err, chaincode.ErrAccountNotFound))
+}
+
+func TestListAccountsUnsharded(t *testing.T) {
+	l := tokentest.NewLedger(t).
+		WithAccount("alice", "user", 100).
+		WithAccount("bob", "user", 20).
+		WithAccount("carol", "user", 0).
+		WithTransaction("tx0", "alice", "bob", 1)
+	paginate(l.Stub, l.State)
+
+	count, err := (&chaincode.SmartContract{}).ShardCount(l.Context)
+	require.NoError(t, err)
+	assert.Equal(t, 1, count)
+	assert.Equal(t, map[int][]string{0: {"alice", "bob", "carol"}}, listAll(t, l.Context, 2), "transaction records should be skipped")
+}
+
+func TestListAccountsRejects(t *testing.T) {
+	l := shardedLedger(t, 4)
+	contract := &chaincode.SmartContract{}
+
+	_, err := contract.ListAccounts(l.Context, 4, 10, "")
+	assert.EqualError(t, err, "[INVALID_ARGUMENT] invalid shard: must be between 0 and 3")
+	_, err = contract.ListAccounts(l.Context, 0, 1001, "")
+	assert.EqualError(t, err, "[INVALID_ARGUMENT] invalid pageSize: must be between 1 and 1000, or 0 for 100")
+
+	l.Stub.GetStateByPartialCompositeKeyWithPaginationReturns(nil, nil, errInjected)
+	_, err = contract.ListAccounts(l.Context, 1, 10, "")
+	assert.EqualError(t, err, `[STATE_UNAVAILABLE] failed to query state "01": injected failure`)
+}
+
+func TestInitializeSharded(t *testing.T) {
+	for _, shards := range []int{1, chaincode.MaxShards + 1} {
+		l := adminLedger(t).Uninitialized()
+		_, err := (&chaincode.SmartContract{}).InitializeSharded(l.Context, shards)
+		assert.True(t, errors.Is(err, validation.ErrInvalidArgument), "%d shards: %v", shards, err)
+		assert.NotContains(t, l.State, chaincode.InitializationKey)
+	}
+
+	l := tokentest.NewLedger(t).Uninitialized().WithCaller("Org1MSP", "User1@org1.example.com", "client")
+	_, err := (&chaincode.SmartContract{}).InitializeSharded(l.Context, 4)
+	assert.EqualError(t, err, "[UNAUTHORIZED] only an organization admin can initialize the contract")
+
+	l = shardedLedger(t, 4)
+	_, err = (&chaincode.SmartContract{}).InitializeSharded(l.Context, 8)
+	assert.EqualError(t, err, "[ALREADY_INITIALIZED] contract already initialized")
+}
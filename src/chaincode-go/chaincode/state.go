@@ -0,0 +1,114 @@
+package chaincode
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// StateError reports a failed world state access. Transactions return it
+// as soon as a read or write fails, so the peer discards the whole write
+// set instead of committing part of an update.
+type StateError struct {
+	Op  string
+	Key string
+	Err error
+}
+
+func (e *StateError) Error() string {
+	return fmt.Sprintf("[%s] failed to %s %q: %v", CodeStateUnavailable, e.Op, e.Key, e.Err)
+}
+
+// Is reports whether target is ErrStateUnavailable
+func (e *StateError) Is(target error) bool {
+	t, ok := target.(*Error)
+	return ok && t.Code == CodeStateUnavailable
+}
+
+// Unwrap returns the stub error
+func (e *StateError) Unwrap() error {
+	return e.Err
+}
+
+// Operations reported in StateError.Op
+const (
+	OpReadState   = "read state"
+	OpWriteState  = "write state"
+	OpDeleteState = "delete state"
+	OpSetEvent    = "set event"
+	OpQueryState  = "query state"
+	// private data operations, see travel.go
+	OpReadPrivateData  = "read private data"
+	OpWritePrivateData = "write private data"
+)
+
+// getState reads the committed value of key, through the context's read
+// cache if it has one
+func getState(ctx contractapi.TransactionContextInterface, key string) ([]byte, error) {
+	cache, cached := ctx.(readCache)
+	if cached {
+		if data, ok := cache.cachedState(key); ok {
+			return data, nil
+		}
+	}
+
+	data, err := ctx.GetStub().GetState(key)
+	if err != nil {
+		return nil, &StateError{Op: OpReadState, Key: key, Err: err}
+	}
+	if cached {
+		cache.cacheState(key, data)
+	}
+
+	return data, nil
+}
+
+func putState(ctx contractapi.TransactionContextInterface, key string, value interface{}) error {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Errorf("failed to obtain JSON encoding of %s: %w", key, err)
+	}
+
+	err = ctx.GetStub().PutState(key, data)
+	if err != nil {
+		return &StateError{Op: OpWriteState, Key: key, Err: err}
+	}
+
+	return nil
+}
+
+// decodeRecord strictly decodes the record stored under key into v:
+// malformed JSON, unknown fields and missing required fields fail with
+// CORRUPT_RECORD instead of leaving zero values behind. Protobuf records
+// are decoded by their codec, where absent fields are zero values.
+func decodeRecord(key string, data []byte, v interface{}, required ...string) error {
+	if isProto(data) {
+		return decodeProto(key, data, v)
+	}
+
+	var fields map[string]json.RawMessage
+	err := json.Unmarshal(data, &fields)
+	if err != nil {
+		return corrupt(key, err)
+	}
+	for _, field := range required {
+		if _, ok := fields[field]; !ok {
+			return corrupt(key, fmt.Errorf("missing field %q", field))
+		}
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	err = decoder.Decode(v)
+	if err != nil {
+		return corrupt(key, err)
+	}
+
+	return nil
+}
+
+func corrupt(key string, err error) *Error {
+	return wrapError(CodeCorruptRecord, err, "record %q is corrupt", key)
+}
@@ -0,0 +1,198 @@
+package chaincode
+
+import (
+	"errors"
+	"time"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+	"github.com/kkiu1756/my_fabric/src/chaincode-go/validation"
+)
+
+// Every balance change of an account is indexed under
+// txIndexObjectType~account~timestamp~txid, the timestamp in a fixed-width
+// UTC format so an account's entries sort by time. GenerateStatement reads
+// an account's statement from its index, so statements are only complete
+// for periods after the index was introduced.
+const txIndexObjectType = "txindex"
+
+// LineDelete is the type of the statement line of a deleted account's
+// remaining balance, which records no Transaction
+const LineDelete = "delete"
+
+const (
+	// statementDate is the layout of GenerateStatement's dates
+	statementDate = "2006-01-02"
+	// indexStamp is the layout of the index timestamps
+	indexStamp = "2006-01-02T15:04:05.000000000Z"
+)
+
+// indexEntry is a balance change of one account
+type indexEntry struct {
+	TXID string `json:"txId"`
+	// Timestamp is the proposal timestamp in RFC 3339 format
+	Timestamp    string `json:"txTimestamp"`
+	Type         string `json:"type"`
+	Counterparty string `json:"counterparty"`
+	Amount       int    `json:"amount"`
+}
+
+// StatementLine is a balance change listed in a Statement
+type StatementLine struct {
+	TXID      string `json:"txId"`
+	Timestamp string `json:"txTimestamp"`
+	// Type is a TransactionType, LineDelete, LineDemurrage,
+	// LineDistribution, LineReferral, LineCashback, LineSettlement,
+	// LineRebase or LineInterest
+	Type string `json:"type"`
+	// Counterparty is the other account of a transfer, "" otherwise
+	Counterparty string `json:"counterparty"`
+	// Amount is positive for a credit and negative for a debit
+	Amount int `json:"amount"`
+	// Balance is the balance after the line
+	Balance int `json:"balance"`
+}
+
+// Statement lists the balance changes of an account between two dates
+type Statement struct {
+	Account string `json:"account"`
+	// From and To are the first and last day covered, as YYYY-MM-DD in UTC
+	From           string           `json:"from"`
+	To             string           `json:"to"`
+	OpeningBalance int              `json:"openingBalance"`
+	Lines          []*StatementLine `json:"lines"`
+	ClosingBalance int              `json:"closingBalance"`
+}
+
+// secondaryLines are the line types written in the transaction of another
+// change to the same account, see demurrage.go, referral.go, cashback.go,
+// rebase.go and interest.go, and keyed apart from it
+var secondaryLines = map[string]bool{LineDemurrage: true, LineReferral: true, LineCashback: true, LineRebase: true, LineInterest: true}
+
+// indexTransaction buffers the index entries of a recorded transaction.
+// A bootstrap indexes each created account itself.
+func indexTransaction(batch *writeBatch, transaction *Transaction) error {
+	txType := string(transaction.Type)
+	switch transaction.Type {
+	case TransactionTransfer:
+		err := indexBalanceChange(batch, transaction.From, txType, transaction.To, -transaction.Value)
+		if err != nil {
+			return err
+		}
+		return indexBalanceChange(batch, transaction.To, txType, transaction.From, transaction.Value)
+	case TransactionMint:
+		return indexBalanceChange(batch, transaction.To, txType, "", transaction.Value)
+	case TransactionBurn:
+		return indexBalanceChange(batch, transaction.From, txType, "", -transaction.Value)
+	}
+
+	return nil
+}
+
+// indexBalanceChange buffers the index entry of a change of amount to
+// account by the current transaction
+func indexBalanceChange(batch *writeBatch, account string, lineType string, counterparty string, amount int) error {
+	stub := batch.ctx.GetStub()
+	timestamp, err := txTimestamp(stub)
+	if err != nil {
+		return err
+	}
+	stamp := ""
+	if t, err := time.Parse(time.RFC3339Nano, timestamp); err == nil {
+		stamp = t.UTC().Format(indexStamp)
+	}
+
+	attributes := []string{account, stamp, stub.GetTxID()}
+	if secondaryLines[lineType] {
+		attributes = append(attributes, lineType)
+	}
+	key, err := stub.CreateCompositeKey(txIndexObjectType, attributes)
+	if err != nil {
+		return err
+	}
+	err = recordBalanceTime(batch, account, timestamp)
+	if err != nil {
+		return err
+	}
+
+	return batch.putState(key, indexEntry{
+		TXID:         stub.GetTxID(),
+		Timestamp:    timestamp,
+		Type:         lineType,
+		Counterparty: counterparty,
+		Amount:       amount,
+	})
+}
+
+// GenerateStatement returns the statement of account from the start of
+// fromDate to the end of toDate, both YYYY-MM-DD in UTC. The opening
+// balance is worked back from the current balance, pending credits
+// included, through the later index entries. A deleted account has a
+// current balance of 0.
+func (s *SmartContract) GenerateStatement(ctx contractapi.TransactionContextInterface, account string, fromDate string, toDate string) (*Statement, error) {
+	errs := []error{validation.ID("account", account)}
+	from, err := time.Parse(statementDate, fromDate)
+	if err != nil {
+		errs = append(errs, &validation.Error{Field: "fromDate", Reason: "must be a date as YYYY-MM-DD"})
+	}
+	to, err := time.Parse(statementDate, toDate)
+	if err != nil {
+		errs = append(errs, &validation.Error{Field: "toDate", Reason: "must be a date as YYYY-MM-DD"})
+	} else if to.Before(from) {
+		errs = append(errs, &validation.Error{Field: "toDate", Reason: "must not be before fromDate"})
+	}
+	if err := validate(errs...); err != nil {
+		return nil, err
+	}
+	end := to.AddDate(0, 0, 1)
+
+	current := 0
+	user, err := GetUser(ctx, account)
+	switch {
+	case err == nil:
+		current = user.Balance
+		if user.Hot {
+			pending, _, err := pendingDeltas(ctx, account)
+			if err != nil {
+				return nil, err
+			}
+			current += pending
+		}
+	case !errors.Is(err, ErrAccountNotFound):
+		return nil, err
+	}
+
+	statement := &Statement{Account: account, From: fromDate, To: toDate, Lines: []*StatementLine{}}
+	later := 0
+	var entry indexEntry
+	err = forEachRecord(ctx, account, txIndexObjectType, []string{account}, &entry, []string{"txId", "type", "amount"}, func(string) error {
+		at, err := time.Parse(time.RFC3339Nano, entry.Timestamp)
+		if err != nil || at.Before(from) {
+			return nil
+		}
+
+		later += entry.Amount
+		if at.Before(end) {
+			statement.Lines = append(statement.Lines, &StatementLine{
+				TXID:         entry.TXID,
+				Timestamp:    entry.Timestamp,
+				Type:         entry.Type,
+				Counterparty: entry.Counterparty,
+				Amount:       entry.Amount,
+			})
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	statement.OpeningBalance = current - later
+	balance := statement.OpeningBalance
+	for _, line := range statement.Lines {
+		balance += line.Amount
+		line.Balance = balance
+	}
+	statement.ClosingBalance = balance
+
+	return statement, nil
+}
@@ -0,0 +1,142 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package chaincode_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+	"github.com/kkiu1756/my_fabric/src/chaincode-go/chaincode"
+	"github.com/kkiu1756/my_fabric/src/chaincode-go/tokentest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// #########
+// HELPERS
+// #########
+
+// statementLedger returns an admin's ledger that serves partial composite
+// key queries from its state
+func statementLedger(t *testing.T) *tokentest.Ledger {
+	l := adminLedger(t)
+	paginate(l.Stub, l.State)
+	l.Stub.GetStateByPartialCompositeKeyStub = func(objectType string, attributes []string) (shim.StateQueryIteratorInterface, error) {
+		it, _, err := l.Stub.GetStateByPartialCompositeKeyWithPagination(objectType, attributes, 1000, "")
+		return it, err
+	}
+	return l
+}
+
+// on runs tx as transaction txid at day 2021-03-<day> noon UTC
+func on(t *testing.T, l *tokentest.Ledger, day int, txid string, tx func() error) {
+	l.WithTxID(txid).WithTimestamp(time.Date(2021, 3, day, 12, 0, 0, 0, time.UTC))
+	require.NoError(t, tx())
+}
+
+// #########
+// TESTS
+// #########
+
+func TestGenerateStatement(t *testing.T) {
+	l := statementLedger(t)
+	contract := &chaincode.SmartContract{}
+	on(t, l, 1, "open", func() error {
+		_, err := contract.CreateUser(l.Context, "alice", "user", 100)
+		return err
+	})
+	on(t, l, 1, "openbob", func() error {
+		_, err := contract.CreateUser(l.Context, "bob", "user", 0)
+		return err
+	})
+	on(t, l, 3, "pay", func() error {
+		_, err := contract.TransferFrom(l.Context, "alice", "bob", 30)
+		return err
+	})
+	on(t, l, 5, "topup", func() error {
+		_, err := contract.SetBalance(l.Context, "alice", 200)
+		return err
+	})
+	on(t, l, 9, "refund", func() error {
+		_, err := contract.TransferFrom(l.Context, "bob", "alice", 10)
+		return err
+	})
+
+	statement, err := contract.GenerateStatement(l.Context, "alice", "2021-03-02", "2021-03-05")
+	require.NoError(t, err)
+	assert.Equal(t, 100, statement.OpeningBalance)
+	assert.Equal(t, 200, statement.ClosingBalance)
+	assert.Equal(t, []*chaincode.StatementLine{
+		{TXID: "pay", Timestamp: "2021-03-03T12:00:00Z", Type: "transfer", Counterparty: "bob", Amount: -30, Balance: 70},
+		{TXID: "topup", Timestamp: "2021-03-05T12:00:00Z", Type: "mint", Amount: 130, Balance: 200},
+	}, statement.Lines)
+
+	statement, err = contract.GenerateStatement(l.Context, "alice", "2021-03-06", "2021-03-08")
+	require.NoError(t, err)
+	assert.Empty(t, statement.Lines)
+	assert.Equal(t, 200, statement.OpeningBalance, "a quiet period")
+	assert.Equal(t, 200, statement.ClosingBalance)
+
+	statement, err = contract.GenerateStatement(l.Context, "bob", "2021-03-01", "2021-03-31")
+	require.NoError(t, err)
+	assert.Equal(t, 0, statement.OpeningBalance)
+	assert.Equal(t, 20, statement.ClosingBalance)
+	assert.Len(t, statement.Lines, 2, "an empty account records no opening line")
+}
+
+func TestStatementOfDeletedAccount(t *testing.T) {
+	l := statementLedger(t)
+	contract := &chaincode.SmartContract{}
+	on(t, l, 1, "open", func() error {
+		_, err := contract.CreateUser(l.Context, "alice", "user", 100)
+		return err
+	})
+	on(t, l, 2, "close", func() error { return contract.DeleteUser(l.Context, "alice") })
+
+	statement, err := contract.GenerateStatement(l.Context, "alice", "2021-03-01", "2021-03-02")
+	require.NoError(t, err)
+	assert.Equal(t, 0, statement.OpeningBalance)
+	assert.Equal(t, 0, statement.ClosingBalance)
+	require.Len(t, statement.Lines, 2)
+	assert.Equal(t, chaincode.LineDelete, statement.Lines[1].Type)
+	assert.Equal(t, -100, statement.Lines[1].Amount)
+}
+
+func TestStatementOfHotAccount(t *testing.T) {
+	l := statementLedger(t)
+	contract := &chaincode.SmartContract{}
+	on(t, l, 1, "open", func() error {
+		_, err := contract.CreateUser(l.Context, "alice", "user", 100)
+		return err
+	})
+	on(t, l, 1, "treasury", func() error {
+		_, err := contract.CreateUser(l.Context, "treasury", "user", 0)
+		return err
+	})
+	on(t, l, 1, "hot", func() error {
+		_, err := contract.SetHotAccount(l.Context, "treasury", true)
+		return err
+	})
+	on(t, l, 2, "fee", func() error {
+		_, err := contract.TransferFrom(l.Context, "alice", "treasury", 5)
+		return err
+	})
+
+	statement, err := contract.GenerateStatement(l.Context, "treasury", "2021-03-02", "2021-03-02")
+	require.NoError(t, err)
+	assert.Equal(t, 0, statement.OpeningBalance)
+	assert.Equal(t, 5, statement.ClosingBalance, "the pending credit counts")
+}
+
+func TestGenerateStatementInvalid(t *testing.T) {
+	l := statementLedger(t)
+	contract := &chaincode.SmartContract{}
+
+	_, err := contract.GenerateStatement(l.Context, "alice", "March 1", "2021-03-02")
+	assert.EqualError(t, err, "[INVALID_ARGUMENT] invalid fromDate: must be a date as YYYY-MM-DD")
+	_, err = contract.GenerateStatement(l.Context, "alice", "2021-03-02", "2021-03-01")
+	assert.EqualError(t, err, "[INVALID_ARGUMENT] invalid toDate: must not be before fromDate")
+}
@@ -0,0 +1,126 @@
+package chaincode
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+	"github.com/hyperledger/fabric-protos-go/ledger/queryresult"
+	"github.com/hyperledger/fabric-protos-go/peer"
+	"github.com/kkiu1756/my_fabric/src/chaincode-go/iterate"
+	"github.com/kkiu1756/my_fabric/src/chaincode-go/validation"
+)
+
+// Backup agents read the state with StreamState rather than ExportState:
+// its chunks are bounded by their encoded size instead of their record
+// count, so a section of large records cannot push a response past the
+// peer's size limit. A chunk is a StatePage whose Bookmark is the cursor
+// of the next chunk, and ImportState accepts it like an ExportState page.
+
+// Chunk size bounds of StreamState, in bytes of JSON encoded records
+const (
+	DefaultStreamBytes = 1 << 20
+	MaxStreamBytes     = 4 << 20
+)
+
+// StreamState returns the records of namespace, one of Sections, from
+// cursor, which is empty for the first chunk, up to maxBytes of JSON
+// encoded records, or DefaultStreamBytes if it is 0. The chunk's Bookmark
+// is the cursor of the next one, empty after the last. A record larger
+// than maxBytes fails the call. Only an org admin can call it.
+func (s *SmartContract) StreamState(ctx contractapi.TransactionContextInterface, namespace string, cursor string, maxBytes int) (*StatePage, error) {
+	objectType, ok := exportSections[namespace]
+	errs := []error{}
+	if !ok {
+		errs = append(errs, &validation.Error{Field: "namespace", Reason: "must be one of " + strings.Join(Sections, ", ")})
+	}
+	if maxBytes < 0 || maxBytes > MaxStreamBytes {
+		errs = append(errs, &validation.Error{Field: "maxBytes", Reason: fmt.Sprintf("must be between 1 and %d, or 0 for %d", MaxStreamBytes, DefaultStreamBytes)})
+	}
+	if err := validate(errs...); err != nil {
+		return nil, err
+	}
+	if maxBytes == 0 {
+		maxBytes = DefaultStreamBytes
+	}
+	if err := requireInitialized(ctx); err != nil {
+		return nil, err
+	}
+	if _, err := requireAdmin(ctx, "export the ledger"); err != nil {
+		return nil, err
+	}
+
+	chunk, err := newStatePage(ctx, namespace)
+	if err != nil {
+		return nil, err
+	}
+
+	stub := ctx.GetStub()
+	query := func(bookmark string) (shim.StateQueryIteratorInterface, *peer.QueryResponseMetadata, error) {
+		pageSize := int32(queryPolicy.MaxPageSize)
+		if objectType == "" {
+			return stub.GetStateByRangeWithPagination("", "", pageSize, bookmark)
+		}
+		return stub.GetStateByPartialCompositeKeyWithPagination(objectType, []string{}, pageSize, bookmark)
+	}
+
+	size := 0
+	for {
+		next, more, err := fillChunk(query, chunk, cursor, &size, maxBytes)
+		if err != nil {
+			return nil, err
+		}
+		if !more {
+			chunk.Bookmark = next
+			break
+		}
+		cursor = next
+	}
+	chunk.Checksum = chunk.checksum()
+
+	return chunk, nil
+}
+
+// fillChunk adds the records of one query page from cursor to chunk while
+// size stays within maxBytes. It returns the cursor of the first record
+// left out, "" at the end of the namespace, and whether the page was used
+// up with room to spare, calling for the next page.
+func fillChunk(query func(string) (shim.StateQueryIteratorInterface, *peer.QueryResponseMetadata, error), chunk *StatePage, cursor string, size *int, maxBytes int) (string, bool, error) {
+	next := ""
+	metadata, err := iterate.Page(func() (shim.StateQueryIteratorInterface, *peer.QueryResponseMetadata, error) {
+		return query(cursor)
+	}, func(kv *queryresult.KV) error {
+		record := StateRecord{Key: kv.Key, Value: kv.Value}
+		encoded, err := json.Marshal(record)
+		if err != nil {
+			return fmt.Errorf("failed to obtain JSON encoding of %s: %w", kv.Key, err)
+		}
+		// a comma separates the record from the previous one
+		if len(chunk.Records) > 0 {
+			encoded = append(encoded, ',')
+		}
+		if *size+len(encoded) > maxBytes {
+			if len(chunk.Records) == 0 {
+				return newError(CodeLimitExceeded, "record %q needs a maxBytes of at least %d", kv.Key, len(encoded))
+			}
+			next = kv.Key
+			return iterate.Stop
+		}
+		chunk.Records = append(chunk.Records, record)
+		*size += len(encoded)
+		return nil
+	})
+	if err != nil {
+		return "", false, queryError(chunk.Section, err)
+	}
+
+	if next != "" {
+		return next, false, nil
+	}
+	if int(metadata.GetFetchedRecordsCount()) < queryPolicy.MaxPageSize || metadata.GetBookmark() == "" {
+		return "", false, nil
+	}
+	return metadata.GetBookmark(), true, nil
+}
@@ -0,0 +1,60 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package chaincode_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/kkiu1756/my_fabric/src/chaincode-go/chaincode"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStreamState(t *testing.T) {
+	source := shardedLedger(t, 2)
+	contract := &chaincode.SmartContract{}
+	for _, id := range []string{"alice", "bob", "treasury"} {
+		_, err := contract.CreateUser(source.Context, id, "user", 100)
+		require.NoError(t, err)
+	}
+	source.WithTxID("pay")
+	_, err := contract.TransferFrom(source.Context, "alice", "bob", 30)
+	require.NoError(t, err)
+
+	key, cert := exportSigner(t)
+	target := shardedLedger(t, 2)
+	require.NoError(t, contract.SetImportSigner(target.Context, cert))
+	const maxBytes = 1000
+	chunks := 0
+	for _, namespace := range chaincode.Sections {
+		cursor := ""
+		for {
+			chunk, err := contract.StreamState(source.Context, namespace, cursor, maxBytes)
+			require.NoError(t, err)
+			records, err := json.Marshal(chunk.Records)
+			require.NoError(t, err)
+			assert.LessOrEqual(t, len(records)-2, maxBytes, "the records fit in maxBytes")
+
+			pageJSON, signature := sign(t, key, chunk)
+			_, err = contract.ImportState(target.Context, pageJSON, signature)
+			require.NoError(t, err)
+			chunks++
+			if chunk.Bookmark == "" {
+				break
+			}
+			cursor = chunk.Bookmark
+		}
+	}
+	assert.Greater(t, chunks, len(chaincode.Sections), "some namespaces take several chunks")
+	assert.Equal(t, tokenState(source), tokenState(target))
+
+	_, err = contract.StreamState(source.Context, "audit", "", 10)
+	assert.Regexp(t, `^\[LIMIT_EXCEEDED\] record ".*" needs a maxBytes of at least \d+$`, err.Error())
+	_, err = contract.StreamState(source.Context, "audit", "", chaincode.MaxStreamBytes+1)
+	assert.EqualError(t, err, "[INVALID_ARGUMENT] invalid maxBytes: must be between 1 and 4194304, or 0 for 1048576")
+	_, err = contract.StreamState(source.Context, "keys", "", 0)
+	assert.Error(t, err)
+}
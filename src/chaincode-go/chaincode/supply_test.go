@@ -0,0 +1,240 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package chaincode_test
+
+import (
+	"fmt"
+	"math/rand"
+	"strings"
+	"testing"
+	"testing/quick"
+
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+	"github.com/kkiu1756/my_fabric/src/chaincode-go/chaincode"
+	"github.com/kkiu1756/my_fabric/src/chaincode-go/tokentest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// #########
+// HELPERS
+// #########
+
+var accountPool = []string{"alice", "bob", "carol", "dave", "erin"}
+
+// supplyModel is the expected ledger: balances of existing accounts and the
+// total issued through CreateUser and SetBalance less what DeleteUser burnt
+type supplyModel struct {
+	balances map[string]int
+	supply   int
+}
+
+type operation struct {
+	name  string
+	run   func(contract *chaincode.SmartContract, state map[string][]byte) error
+	apply func(m *supplyModel) bool
+}
+
+func randomOperation(r *rand.Rand) operation {
+	a := accountPool[r.Intn(len(accountPool))]
+	b := accountPool[r.Intn(len(accountPool))]
+	amount := r.Intn(600) - 50
+
+	switch r.Intn(4) {
+	case 0:
+		return operation{
+			name: fmt.Sprintf("CreateUser(%s, %d)", a, amount),
+			run: func(c *chaincode.SmartContract, state map[string][]byte) error {
+				ctx, _ := newContext(state)
+				_, err := c.CreateUser(ctx, a, "user", amount)
+				return err
+			},
+			apply: func(m *supplyModel) bool {
+				if _, ok := m.balances[a]; ok || amount < 0 {
+					return false
+				}
+				m.balances[a] = amount
+				m.supply += amount
+				return true
+			},
+		}
+	case 1:
+		return operation{
+			name: fmt.Sprintf("SetBalance(%s, %d)", a, amount),
+			run: func(c *chaincode.SmartContract, state map[string][]byte) error {
+				ctx, _ := newContext(state)
+				_, err := c.SetBalance(ctx, a, amount)
+				return err
+			},
+			apply: func(m *supplyModel) bool {
+				old, ok := m.balances[a]
+				if !ok || amount < 0 {
+					return false
+				}
+				m.balances[a] = amount
+				m.supply += amount - old
+				return true
+			},
+		}
+	case 2:
+		return operation{
+			name: fmt.Sprintf("DeleteUser(%s)", a),
+			run: func(c *chaincode.SmartContract, state map[string][]byte) error {
+				ctx, _ := newContext(state)
+				return c.DeleteUser(ctx, a)
+			},
+			apply: func(m *supplyModel) bool {
+				old, ok := m.balances[a]
+				if !ok {
+					return false
+				}
+				delete(m.balances, a)
+				m.supply -= old
+				return true
+			},
+		}
+	default:
+		return operation{
+			name: fmt.Sprintf("TransferFrom(%s, %s, %d)", a, b, amount),
+			run: func(c *chaincode.SmartContract, state map[string][]byte) error {
+				ctx, _ := newContext(state)
+				_, err := c.TransferFrom(ctx, a, b, amount)
+				return err
+			},
+			apply: func(m *supplyModel) bool {
+				from, fromOK := m.balances[a]
+				_, toOK := m.balances[b]
+				if !fromOK || !toOK || a == b || amount < 0 || amount > from {
+					return false
+				}
+				m.balances[a] -= amount
+				m.balances[b] += amount
+				return true
+			},
+		}
+	}
+}
+
+// checkLedger compares the world state with the model
+func checkLedger(state map[string][]byte, m *supplyModel) error {
+	total := 0
+	for _, id := range accountPool {
+		expected, exists := m.balances[id]
+		if _, stored := state[id]; stored != exists {
+			return fmt.Errorf("account %s stored: %v, expected: %v", id, stored, exists)
+		}
+		if !exists {
+			continue
+		}
+
+		ctx, _ := newContext(state)
+		user, err := chaincode.GetUser(ctx, id)
+		if err != nil {
+			return err
+		}
+		if user.Balance < 0 {
+			return fmt.Errorf("account %s has negative balance %d", id, user.Balance)
+		}
+		if user.Balance != expected {
+			return fmt.Errorf("account %s has balance %d, expected %d", id, user.Balance, expected)
+		}
+		total += user.Balance
+	}
+
+	if total != m.supply {
+		return fmt.Errorf("sum of balances %d does not match supply %d", total, m.supply)
+	}
+
+	return nil
+}
+
+// totalSupply sums TotalSupply over every page of every shard
+func totalSupply(t *testing.T, l *tokentest.Ledger, pageSize int) int {
+	contract := &chaincode.SmartContract{}
+	shards, err := contract.ShardCount(l.Context)
+	require.NoError(t, err)
+
+	total := 0
+	for shard := 0; shard < shards; shard++ {
+		bookmark := ""
+		for {
+			page, err := contract.TotalSupply(l.Context, shard, pageSize, bookmark)
+			require.NoError(t, err)
+			total += page.Supply
+			if page.Bookmark == "" {
+				break
+			}
+			bookmark = page.Bookmark
+		}
+	}
+	return total
+}
+
+// #########
+// TESTS
+// #########
+
+// TestSupplyConservation runs random operation sequences and checks after
+// every step that balances never go negative, the sum of balances equals
+// the supply and only supply-changing operations change it
+func TestSupplyConservation(t *testing.T) {
+	property := func(seed int64) bool {
+		r := rand.New(rand.NewSource(seed))
+		state := initialized(t)
+		m := &supplyModel{balances: map[string]int{}}
+		contract := &chaincode.SmartContract{}
+
+		var history []string
+		for i := 0; i < 100; i++ {
+			op := randomOperation(r)
+			err := op.run(contract, state)
+			accepted := op.apply(m)
+			history = append(history, fmt.Sprintf("%s: %v", op.name, err))
+
+			if accepted != (err == nil) {
+				t.Logf("seed %d: %s accepted %v, expected %v\n%s", seed, op.name, err == nil, accepted, strings.Join(history, "\n"))
+				return false
+			}
+			if err := checkLedger(state, m); err != nil {
+				t.Logf("seed %d: %v\n%s", seed, err, strings.Join(history, "\n"))
+				return false
+			}
+		}
+
+		return true
+	}
+
+	if err := quick.Check(property, &quick.Config{MaxCount: 200}); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestTotalSupplyAcrossMigration(t *testing.T) {
+	l := shardedLedger(t, 2)
+	l.Stub.GetStateByPartialCompositeKeyStub = func(objectType string, attributes []string) (shim.StateQueryIteratorInterface, error) {
+		it, _, err := l.Stub.GetStateByPartialCompositeKeyWithPagination(objectType, attributes, 1000, "")
+		return it, err
+	}
+	contract := &chaincode.SmartContract{}
+	for _, id := range []string{"alice", "bob", "treasury"} {
+		_, err := contract.CreateUser(l.Context, id, "user", 40)
+		require.NoError(t, err)
+	}
+	_, err := contract.SetHotAccount(l.Context, "treasury", true)
+	require.NoError(t, err)
+	_, err = contract.TransferFrom(l.Context, "alice", "treasury", 30)
+	require.NoError(t, err)
+
+	before := totalSupply(t, l, 1)
+	assert.Equal(t, 120, before, "the credit pending on treasury counts")
+
+	_, err = contract.SetRecordEncoding(l.Context, chaincode.EncodingProtobuf)
+	require.NoError(t, err)
+	for shard := 0; shard < 2; shard++ {
+		_, err := contract.MigrateShard(l.Context, shard, 0, "")
+		require.NoError(t, err)
+	}
+	assert.Equal(t, before, totalSupply(t, l, 2))
+}
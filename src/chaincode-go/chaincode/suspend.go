@@ -0,0 +1,359 @@
+package chaincode
+
+import (
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+	"github.com/hyperledger/fabric-protos-go/ledger/queryresult"
+	"github.com/hyperledger/fabric-protos-go/peer"
+	"github.com/kkiu1756/my_fabric/src/chaincode-go/validation"
+)
+
+// An org admin freezes an account with SuspendAccount, giving one of the
+// suspension reason codes and optionally the time the suspension ends.
+// Until then, or until LiftSuspension, transfers from or to the account
+// fail with ACCOUNT_FROZEN. The account holder or an admin can attach an
+// appeal note, and ListSuspendedAccounts lists the suspensions in force
+// for operations review.
+
+// suspensionObjectType keys the suspensions by account
+const suspensionObjectType = "suspension"
+
+// Suspension reason codes
+const (
+	SuspensionFraud      = "fraud"
+	SuspensionSanctions  = "sanctions"
+	SuspensionCourtOrder = "courtOrder"
+	SuspensionKYC        = "kyc"
+	SuspensionDormant    = "dormant"
+	SuspensionOther      = "other"
+)
+
+// suspensionReasons is the reason code taxonomy
+var suspensionReasons = map[string]bool{
+	SuspensionFraud:      true,
+	SuspensionSanctions:  true,
+	SuspensionCourtOrder: true,
+	SuspensionKYC:        true,
+	SuspensionDormant:    true,
+	SuspensionOther:      true,
+}
+
+// Suspension is the freeze of an account
+type Suspension struct {
+	Account string `json:"account"`
+	// Reason is a suspension reason code, empty if the account was never
+	// suspended or the suspension was lifted
+	Reason string `json:"reason"`
+	// Note details the reason
+	Note string `json:"note,omitempty" metadata:"note,optional"`
+	// ExpiresAt ends the suspension, an RFC 3339 time, empty if it lasts
+	// until lifted
+	ExpiresAt string `json:"expiresAt,omitempty" metadata:"expiresAt,optional"`
+	// AppealNote is the account holder's or an admin's note on an appeal
+	AppealNote string `json:"appealNote,omitempty" metadata:"appealNote,optional"`
+	// Timestamp is the proposal timestamp of the SuspendAccount call
+	Timestamp      string `json:"txTimestamp,omitempty" metadata:"txTimestamp,optional"`
+	SuspenderMSPID string `json:"suspenderMspId,omitempty" metadata:"suspenderMspId,optional"`
+	Suspender      string `json:"suspender,omitempty" metadata:"suspender,optional"`
+}
+
+// SuspensionPage is a page of ListSuspendedAccounts
+type SuspensionPage struct {
+	Suspensions []*Suspension `json:"suspensions"`
+	// Bookmark continues the listing, empty after the last page
+	Bookmark string `json:"bookmark"`
+}
+
+// SuspendAccount freezes account for reason, one of the suspension reason
+// codes, detailed by note, until expiresAt, an RFC 3339 time, or until
+// lifted if it is empty. Suspending a suspended account replaces its
+// suspension and clears its appeal note. Only an org admin can call it.
+func (s *SmartContract) SuspendAccount(ctx contractapi.TransactionContextInterface, account string, reason string, note string, expiresAt string) (*Suspension, error) {
+	errs := []error{
+		validation.ID("account", account),
+		validation.Memo("note", note),
+	}
+	if !suspensionReasons[reason] {
+		errs = append(errs, &validation.Error{Field: "reason", Reason: "must be one of " + strings.Join(suspensionReasonList(), ", ")})
+	}
+	if expiresAt != "" {
+		if _, err := time.Parse(time.RFC3339, expiresAt); err != nil {
+			errs = append(errs, &validation.Error{Field: "expiresAt", Reason: "must be an RFC 3339 time"})
+		}
+	}
+	if err := validate(errs...); err != nil {
+		return nil, err
+	}
+	if err := requireInitialized(ctx); err != nil {
+		return nil, err
+	}
+	if _, err := requireAdmin(ctx, "suspend accounts"); err != nil {
+		return nil, err
+	}
+
+	batch := newWriteBatch(ctx)
+	user, err := batch.getUser(account)
+	if err != nil {
+		return nil, err
+	}
+
+	stub := ctx.GetStub()
+	timestamp, err := txTimestamp(stub)
+	if err != nil {
+		return nil, err
+	}
+	suspension := &Suspension{
+		Account:        account,
+		Reason:         reason,
+		Note:           note,
+		ExpiresAt:      expiresAt,
+		Timestamp:      timestamp,
+		SuspenderMSPID: creatorMSPID(stub),
+		Suspender:      creatorID(stub),
+	}
+	key, err := stub.CreateCompositeKey(suspensionObjectType, []string{account})
+	if err != nil {
+		return nil, err
+	}
+	err = batch.putState(key, suspension)
+	if err != nil {
+		return nil, err
+	}
+
+	detail := reason
+	if expiresAt != "" {
+		detail += " until " + expiresAt
+	}
+	err = appendAudit(batch, account, AuditSuspend, user.Balance, user.Balance, detail)
+	if err != nil {
+		return nil, err
+	}
+
+	err = batch.flush()
+	if err != nil {
+		return nil, err
+	}
+
+	return suspension, nil
+}
+
+// LiftSuspension unfreezes account. Only an org admin can call it.
+func (s *SmartContract) LiftSuspension(ctx contractapi.TransactionContextInterface, account string) error {
+	if err := validate(validation.ID("account", account)); err != nil {
+		return err
+	}
+	if err := requireInitialized(ctx); err != nil {
+		return err
+	}
+	if _, err := requireAdmin(ctx, "lift suspensions"); err != nil {
+		return err
+	}
+
+	batch := newWriteBatch(ctx)
+	key, suspension, err := accountSuspension(batch, account)
+	if err != nil {
+		return err
+	}
+	if suspension == nil {
+		return newError(CodeInvalidArgument, "account %s is not suspended", account)
+	}
+	user, err := batch.getUser(account)
+	if err != nil {
+		return err
+	}
+	batch.delState(key)
+
+	err = appendAudit(batch, account, AuditLiftSuspension, user.Balance, user.Balance, suspension.Reason)
+	if err != nil {
+		return err
+	}
+
+	return batch.flush()
+}
+
+// SetAppealNote records note as the appeal of account's suspension. The
+// account holder or an org admin can call it.
+func (s *SmartContract) SetAppealNote(ctx contractapi.TransactionContextInterface, account string, note string) (*Suspension, error) {
+	err := validate(
+		validation.ID("account", account),
+		validation.Memo("note", note),
+	)
+	if err != nil {
+		return nil, err
+	}
+	if err := requireInitialized(ctx); err != nil {
+		return nil, err
+	}
+	if !isAccountHolder(ctx, account) {
+		if _, err := requireAdmin(ctx, "appeal the suspension of another account"); err != nil {
+			return nil, err
+		}
+	}
+
+	batch := newWriteBatch(ctx)
+	key, suspension, err := accountSuspension(batch, account)
+	if err != nil {
+		return nil, err
+	}
+	if suspension == nil {
+		return nil, newError(CodeInvalidArgument, "account %s is not suspended", account)
+	}
+	suspension.AppealNote = note
+	err = batch.putState(key, suspension)
+	if err != nil {
+		return nil, err
+	}
+
+	err = batch.flush()
+	if err != nil {
+		return nil, err
+	}
+
+	return suspension, nil
+}
+
+// GetSuspension returns the suspension of account, which may have expired,
+// with an empty reason if it has none
+func (s *SmartContract) GetSuspension(ctx contractapi.TransactionContextInterface, account string) (*Suspension, error) {
+	if err := validate(validation.ID("account", account)); err != nil {
+		return nil, err
+	}
+
+	_, suspension, err := accountSuspension(newWriteBatch(ctx), account)
+	if err != nil || suspension != nil {
+		return suspension, err
+	}
+
+	return &Suspension{Account: account}, nil
+}
+
+// ListSuspendedAccounts returns a page of the suspensions in force, in
+// account order, from bookmark, which is empty for the first page. Expired
+// suspensions are skipped, so a page may hold fewer than pageSize.
+func (s *SmartContract) ListSuspendedAccounts(ctx contractapi.TransactionContextInterface, pageSize int, bookmark string) (*SuspensionPage, error) {
+	pageSize, err := queryPolicy.pageSize(pageSize)
+	if err != nil {
+		return nil, err
+	}
+	now, err := suspensionTime(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	stub := ctx.GetStub()
+	query := func(pageSize int32, bookmark string) (shim.StateQueryIteratorInterface, *peer.QueryResponseMetadata, error) {
+		return stub.GetStateByPartialCompositeKeyWithPagination(suspensionObjectType, []string{}, pageSize, bookmark)
+	}
+
+	page := &SuspensionPage{Suspensions: []*Suspension{}}
+	page.Bookmark, err = queryPolicy.scan(query, suspensionObjectType, pageSize, bookmark, func(kv *queryresult.KV) error {
+		suspension, err := decodeSuspension(kv.Key, kv.Value)
+		if err != nil {
+			return err
+		}
+		active, err := suspension.activeAt(kv.Key, now)
+		if err != nil || !active {
+			return err
+		}
+		page.Suspensions = append(page.Suspensions, suspension)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return page, nil
+}
+
+// accountSuspension reads the suspension of account, nil if it has none,
+// and returns its key
+func accountSuspension(batch *writeBatch, account string) (string, *Suspension, error) {
+	key, err := batch.ctx.GetStub().CreateCompositeKey(suspensionObjectType, []string{account})
+	if err != nil {
+		return "", nil, err
+	}
+	data, err := batch.getState(key)
+	if err != nil || data == nil {
+		return key, nil, err
+	}
+
+	suspension, err := decodeSuspension(key, data)
+	if err != nil {
+		return "", nil, err
+	}
+
+	return key, suspension, nil
+}
+
+// decodeSuspension decodes the suspension stored under key
+func decodeSuspension(key string, data []byte) (*Suspension, error) {
+	var suspension Suspension
+	err := decodeRecord(key, data, &suspension, "account", "reason")
+	if err != nil {
+		return nil, err
+	}
+
+	return &suspension, nil
+}
+
+// activeAt reports whether the suspension is in force at now, the zero
+// time if the transaction has no timestamp, where every suspension is
+func (s *Suspension) activeAt(key string, now time.Time) (bool, error) {
+	if s.ExpiresAt == "" || now.IsZero() {
+		return true, nil
+	}
+	expiresAt, err := time.Parse(time.RFC3339, s.ExpiresAt)
+	if err != nil {
+		return false, corrupt(key, err)
+	}
+
+	return now.Before(expiresAt), nil
+}
+
+// suspensionTime returns the proposal timestamp suspensions expire
+// against, the zero time if it has none
+func suspensionTime(ctx contractapi.TransactionContextInterface) (time.Time, error) {
+	timestamp, err := txTimestamp(ctx.GetStub())
+	if err != nil || timestamp == "" {
+		return time.Time{}, err
+	}
+
+	return time.Parse(time.RFC3339Nano, timestamp)
+}
+
+// checkSuspended fails with ACCOUNT_FROZEN if a suspension of account is
+// in force
+func checkSuspended(batch *writeBatch, account string) error {
+	key, suspension, err := accountSuspension(batch, account)
+	if err != nil || suspension == nil {
+		return err
+	}
+	now, err := suspensionTime(batch.ctx)
+	if err != nil {
+		return err
+	}
+	active, err := suspension.activeAt(key, now)
+	if err != nil || !active {
+		return err
+	}
+
+	until := "until lifted"
+	if suspension.ExpiresAt != "" {
+		until = "until " + suspension.ExpiresAt
+	}
+	return newError(CodeAccountFrozen, "account %s is suspended (%s) %s", account, suspension.Reason, until)
+}
+
+// suspensionReasonList returns the reason codes in order
+func suspensionReasonList() []string {
+	reasons := make([]string, 0, len(suspensionReasons))
+	for reason := range suspensionReasons {
+		reasons = append(reasons, reason)
+	}
+	sort.Strings(reasons)
+	return reasons
+}
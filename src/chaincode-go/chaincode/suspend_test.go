@@ -0,0 +1,135 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package chaincode_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/kkiu1756/my_fabric/src/chaincode-go/chaincode"
+	"github.com/kkiu1756/my_fabric/src/chaincode-go/tokentest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// #########
+// HELPERS
+// #########
+
+var suspendTime = time.Date(2021, 3, 1, 12, 0, 0, 0, time.UTC)
+
+// suspendLedger returns an admin's ledger with alice 100, bob 20 and carol
+// 0, where alice is suspended for fraud until lifted
+func suspendLedger(t *testing.T) *tokentest.Ledger {
+	l := adminLedger(t).
+		WithAccount("alice", "user", 100).
+		WithAccount("bob", "user", 20).
+		WithAccount("carol", "user", 0).
+		WithTimestamp(suspendTime)
+	paginate(l.Stub, l.State)
+
+	_, err := (&chaincode.SmartContract{}).SuspendAccount(l.Context, "alice", chaincode.SuspensionFraud, "chargebacks", "")
+	require.NoError(t, err)
+	return l
+}
+
+// #########
+// TESTS
+// #########
+
+func TestSuspendAccount(t *testing.T) {
+	l := suspendLedger(t)
+	contract := &chaincode.SmartContract{}
+
+	_, err := contract.TransferFrom(l.Context, "alice", "bob", 10)
+	assert.EqualError(t, err, "failed to transfer: [ACCOUNT_FROZEN] account alice is suspended (fraud) until lifted")
+	assert.True(t, errors.Is(err, chaincode.ErrAccountFrozen))
+	_, err = contract.TransferFrom(l.Context, "bob", "alice", 10)
+	assert.True(t, errors.Is(err, chaincode.ErrAccountFrozen), "a suspended account cannot receive either")
+	_, err = contract.TransferFrom(l.Context, "bob", "carol", 10)
+	require.NoError(t, err)
+
+	suspension, err := contract.GetSuspension(l.Context, "alice")
+	require.NoError(t, err)
+	assert.Equal(t, chaincode.SuspensionFraud, suspension.Reason)
+	assert.Equal(t, "chargebacks", suspension.Note)
+	assert.Equal(t, "2021-03-01T12:00:00Z", suspension.Timestamp)
+	trail, err := contract.GetAuditTrail(l.Context, "alice", 0, "")
+	require.NoError(t, err)
+	assert.Equal(t, chaincode.AuditSuspend, trail.Records[len(trail.Records)-1].Action)
+
+	require.NoError(t, contract.LiftSuspension(l.Context, "alice"))
+	_, err = contract.TransferFrom(l.Context, "alice", "bob", 10)
+	require.NoError(t, err)
+	suspension, err = contract.GetSuspension(l.Context, "alice")
+	require.NoError(t, err)
+	assert.Equal(t, &chaincode.Suspension{Account: "alice"}, suspension)
+
+	err = contract.LiftSuspension(l.Context, "alice")
+	assert.EqualError(t, err, "[INVALID_ARGUMENT] account alice is not suspended")
+}
+
+func TestSuspensionExpiry(t *testing.T) {
+	l := suspendLedger(t)
+	contract := &chaincode.SmartContract{}
+
+	_, err := contract.SuspendAccount(l.Context, "bob", chaincode.SuspensionKYC, "", "2021-03-02T12:00:00Z")
+	require.NoError(t, err)
+	_, err = contract.TransferFrom(l.Context, "bob", "carol", 10)
+	assert.EqualError(t, err, "failed to transfer: [ACCOUNT_FROZEN] account bob is suspended (kyc) until 2021-03-02T12:00:00Z")
+
+	page, err := contract.ListSuspendedAccounts(l.Context, 0, "")
+	require.NoError(t, err)
+	require.Len(t, page.Suspensions, 2)
+	assert.Equal(t, "alice", page.Suspensions[0].Account)
+	assert.Equal(t, "bob", page.Suspensions[1].Account)
+
+	l.WithTimestamp(suspendTime.Add(24 * time.Hour))
+	_, err = contract.TransferFrom(l.Context, "bob", "carol", 10)
+	require.NoError(t, err, "an expired suspension no longer freezes the account")
+	page, err = contract.ListSuspendedAccounts(l.Context, 0, "")
+	require.NoError(t, err)
+	require.Len(t, page.Suspensions, 1)
+	assert.Equal(t, "alice", page.Suspensions[0].Account)
+}
+
+func TestSetAppealNote(t *testing.T) {
+	l := suspendLedger(t)
+	contract := &chaincode.SmartContract{}
+
+	l.WithCaller("Org1MSP", "bob", "client")
+	_, err := contract.SetAppealNote(l.Context, "alice", "not me")
+	assert.EqualError(t, err, "[UNAUTHORIZED] only an organization admin can appeal the suspension of another account")
+
+	l.WithCaller("Org1MSP", "alice", "client")
+	suspension, err := contract.SetAppealNote(l.Context, "alice", "the chargebacks were refunded")
+	require.NoError(t, err)
+	assert.Equal(t, "the chargebacks were refunded", suspension.AppealNote)
+	suspension, err = contract.GetSuspension(l.Context, "alice")
+	require.NoError(t, err)
+	assert.Equal(t, "the chargebacks were refunded", suspension.AppealNote)
+
+	_, err = contract.SetAppealNote(l.Context, "bob", "x")
+	assert.EqualError(t, err, "[UNAUTHORIZED] only an organization admin can appeal the suspension of another account")
+}
+
+func TestSuspendAccountRejects(t *testing.T) {
+	l := suspendLedger(t)
+	contract := &chaincode.SmartContract{}
+
+	_, err := contract.SuspendAccount(l.Context, "bob", "bored", "", "")
+	assert.EqualError(t, err, "[INVALID_ARGUMENT] invalid reason: must be one of courtOrder, dormant, fraud, kyc, other, sanctions")
+	_, err = contract.SuspendAccount(l.Context, "bob", chaincode.SuspensionOther, "", "tomorrow")
+	assert.EqualError(t, err, "[INVALID_ARGUMENT] invalid expiresAt: must be an RFC 3339 time")
+	_, err = contract.SuspendAccount(l.Context, "dave", chaincode.SuspensionOther, "", "")
+	assert.True(t, errors.Is(err, chaincode.ErrAccountNotFound))
+
+	l.WithCaller("Org1MSP", "User1@org1.example.com", "client")
+	_, err = contract.SuspendAccount(l.Context, "bob", chaincode.SuspensionOther, "", "")
+	assert.EqualError(t, err, "[UNAUTHORIZED] only an organization admin can suspend accounts")
+	err = contract.LiftSuspension(l.Context, "alice")
+	assert.EqualError(t, err, "[UNAUTHORIZED] only an organization admin can lift suspensions")
+}
@@ -3,9 +3,13 @@ package chaincode
 import (
 	"encoding/json"
 	"fmt"
-	"log"
+	"time"
 
+	"github.com/golang/protobuf/ptypes"
+	"github.com/hyperledger/fabric-chaincode-go/pkg/cid"
+	"github.com/hyperledger/fabric-chaincode-go/shim"
 	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+	"github.com/kkiu1756/my_fabric/src/chaincode-go/validation"
 )
 
 // SmartContract provides functions for transferring tokens between accounts
@@ -24,38 +28,96 @@ type User struct {
 	ID      string `json:"userId"`
 	Type    string `json:"type"`
 	Balance int    `json:"balance"`
+	// Hot accounts take credits as deltas, see delta.go
+	Hot bool `json:"hot,omitempty" metadata:"hot,optional"`
+	// Rebase is the rebase generation Balance is in, see rebase.go
+	Rebase int `json:"rebase,omitempty" metadata:"rebase,optional"`
+
+	// rebased is the change rebaseUser made to Balance, not yet indexed
+	rebased int
+	// accrued is the interest accrueInterest added to Balance, not yet
+	// indexed
+	accrued int
 }
 
+// TransactionType is the operation a Transaction record describes
+type TransactionType string
+
+// Transaction types
+const (
+	TransactionTransfer TransactionType = "transfer"
+	// TransactionMint credits an account from outside the ledger, From is ""
+	TransactionMint TransactionType = "mint"
+	// TransactionBurn debits an account to outside the ledger, To is ""
+	TransactionBurn TransactionType = "burn"
+	// TransactionBootstrap creates a chunk of initial accounts, From and To
+	// are "" and Value is their total balance
+	TransactionBootstrap TransactionType = "bootstrap"
+)
+
+// Transaction records a balance change. Every field is taken from the
+// proposal, so all endorsers write identical records.
 type Transaction struct {
-	TXID  string `json:"txId"`
-	From  string `json:"from"`
-	To    string `json:"to"`
-	Value int    `json:"value"`
+	TXID  string          `json:"txId"`
+	Type  TransactionType `json:"type"`
+	From  string          `json:"from"`
+	To    string          `json:"to"`
+	Value int             `json:"value"`
+	// Timestamp is the client's proposal timestamp in RFC 3339 format
+	Timestamp string `json:"txTimestamp"`
+	ChannelID string `json:"channelId"`
+	// InitiatorMSPID and Initiator identify the submitting client, Initiator
+	// is the client identity id, empty if the creator cannot be parsed
+	InitiatorMSPID string `json:"initiatorMspId"`
+	Initiator      string `json:"initiator"`
+	// CorrelationID is the client's correlation id, see correlation.go
+	CorrelationID string `json:"correlationId,omitempty" metadata:"correlationId,optional"`
+}
+
+// GetEvaluateTransactions returns the read-only functions so the contract
+// metadata tags them as evaluate instead of submit
+func (s *SmartContract) GetEvaluateTransactions() []string {
+	return []string{"GetUser", "UserExist", "GetTransaction", "Initialized", "FindKeyCollisions", "PendingDeltas", "ShardCount", "ListAccounts", "GetAccount", "Version", "ExportState", "TotalSupply", "GetAuditTrail", "GetAuditorReads", "GetTransferLimit", "GetPendingTransfer", "GetLargeTransferReview", "ListLargeTransferReviews", "GenerateStatement", "GetTravelRuleRecord", "GetPendingConfirmation", "GetSuspension", "ListSuspendedAccounts", "GetGuardians", "GetRecovery", "GetPaymentRequest", "GetReversibleTransfer", "GetDispute", "GetDormancyFlag", "GetEmission", "GetSnapshot", "GetDistribution", "AirdropClaimed", "GetWhitelist", "VerifyWhitelist", "GetReferral", "GetSettlementReport", "GetItem", "GetReceipt", "ListItems", "VerifyReceipt", "GetRefunds", "GetRefundStatus", "GetCurrency", "GetCurrencyBalance", "GetCurrencyTransaction", "GetRate", "GetAttestation", "CompareReserves", "GetRebaseHistory", "GetCreditExposure", "GetGiftCard", "GetGiftCardBreakage", "GetAlias", "GetWallet", "ListWallets", "GetRollupBalance", "GetTreasury", "GetTreasuryPayment", "GetDelegate", "GetRevokedCertificate", "GetTopHolders", "GetAccountDistribution", "StreamState", "GetTransactionsByCorrelationID", "ListAccountsByType", "ListAccountsByBalance", "ListTransactionsByTime"}
 }
 
 // TransferFrom transfers the value amount from the "from" address to the "to" address
-// This function triggers a Transfer event
+// This function triggers a Transferred event, see events.go
 func (s *SmartContract) TransferFrom(ctx contractapi.TransactionContextInterface, from string, to string, value int) (*Transaction, error) {
-
-	// Initiate the transfer
-	err := transferHelper(ctx, from, to, value)
+	err := validate(
+		validation.ID("from", from),
+		validation.ID("to", to),
+		validation.Amount("value", value),
+	)
 	if err != nil {
-		return nil, fmt.Errorf("failed to transfer: %v", err)
+		return nil, err
 	}
 
-	// Set transaction
-	transaction, err := SetTransaction(ctx, from, to, value)
+	err = requireInitialized(ctx)
 	if err != nil {
-		return nil, fmt.Errorf("failed to set transaction: %v", err)
+		return nil, err
 	}
-
-	// Emit the Transfer event
-	err = SetEvent(ctx, "Transfer", event{from, to, value})
+	from, to, err = resolveAccounts(ctx, from, to)
 	if err != nil {
 		return nil, err
 	}
+	err = requireNoConfirmation(ctx, to, value)
+	if err != nil {
+		return nil, fmt.Errorf("failed to transfer: %w", err)
+	}
 
-	log.Printf("%s transfer %d balance to %s", from, value, to)
+	// Initiate the transfer
+	batch := newWriteBatch(ctx)
+	transaction, err := transferHelper(batch, from, to, value, false)
+	if err == nil {
+		err = batch.flush()
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to transfer: %w", err)
+	}
+
+	if log := transferLogger(ctx); log != nil {
+		log.Info("transfer", "from", from, "to", to, "value", value)
+	}
 
 	return transaction, nil
 }
@@ -63,89 +125,190 @@ func (s *SmartContract) TransferFrom(ctx contractapi.TransactionContextInterface
 // Helper Functions
 
 // transferHelper is a helper function that transfers tokens from the "from" address to the "to" address
-// Dependant functions include Transfer and TransferFrom
-func transferHelper(ctx contractapi.TransactionContextInterface, from string, to string, value int) error {
+// and records the transfer as the transaction's Transaction entry. The changes are buffered in batch
+// An approved transfer is not held to the sender's limits, see limits.go
+// Dependant functions include Transfer, TransferFrom and ApproveTransfer
+func transferHelper(batch *writeBatch, from string, to string, value int, approved bool) (*Transaction, error) {
+	if from == EscrowAccount || to == EscrowAccount {
+		return nil, newError(CodeInvalidArgument, "account %s only takes reversible transfers", EscrowAccount)
+	}
+
+	transaction, err := moveTokens(batch, from, to, value, approved)
+	if err != nil {
+		return nil, err
+	}
+	err = payReferralBonus(batch, transaction)
+	if err != nil {
+		return nil, err
+	}
+
+	return transaction, payCashback(batch, transaction)
+}
+
+// moveTokens is transferHelper without the reserved account check, for the
+// escrow legs of reversible transfers, see reversible.go
+func moveTokens(batch *writeBatch, from string, to string, value int, approved bool) (*Transaction, error) {
+	ctx := batch.ctx
 
 	if from == to {
-		return fmt.Errorf("cannot transfer to and from same client account")
+		return nil, newError(CodeInvalidArgument, "cannot transfer to and from same client account")
 	}
 
-	if value < 0 { // transfer of 0 is allowed in ERC-20, so just validate against negative amounts
-		return fmt.Errorf("transfer amount cannot be negative")
+	fromUser, err := batch.getUser(from)
+	if err != nil {
+		return nil, err
 	}
 
-	fromUser, err := GetUser(ctx, from)
+	toUser, err := batch.getUser(to)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	toUser, err := GetUser(ctx, to)
+	for _, account := range []string{from, to} {
+		err = checkSuspended(batch, account)
+		if err != nil {
+			return nil, err
+		}
+	}
+	err = checkHierarchy(batch, from, to)
 	if err != nil {
-		return err
+		return nil, err
+	}
+	err = checkTreasury(batch, from)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, user := range []*User{fromUser, toUser} {
+		err = settleDemurrage(batch, user)
+		if err != nil {
+			return nil, err
+		}
 	}
 
 	if fromUser.Balance < value {
-		return fmt.Errorf("user balance lower than %d", value)
+		limit, err := creditLimit(batch, from)
+		if err != nil {
+			return nil, err
+		}
+		if fromUser.Balance+limit < value {
+			return nil, newError(CodeInsufficientBalance, "user balance lower than %d", value)
+		}
+	}
+
+	err = checkTransferLimit(batch, from, value, approved)
+	if err != nil {
+		return nil, err
 	}
 
 	beforeFromUserBalance := fromUser.Balance
 	beforeToUserBalance := toUser.Balance
-	fromUser.Balance -= value
-	toUser.Balance += value
+	var ok bool
+	fromUser.Balance, ok = subBalance(fromUser.Balance, value)
+	if !ok {
+		return nil, newError(CodeBalanceOverflow, "cannot debit %s: balance overflow", from)
+	}
+	toUser.Balance, ok = addBalance(toUser.Balance, value)
+	if !ok {
+		return nil, newError(CodeBalanceOverflow, "cannot credit %s: balance overflow", to)
+	}
 
 	// update
-	fromUserJSON, err := json.Marshal(fromUser)
+	err = batch.putUser(fromUser)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	toUserJSON, err := json.Marshal(toUser)
+	// a hot account's record is only read, see delta.go
+	if toUser.Hot {
+		err = creditDelta(batch, to, value)
+	} else {
+		err = batch.putUser(toUser)
+	}
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	err = ctx.GetStub().PutState(from, fromUserJSON)
-	if err != nil {
-		return err
+	if log := transferLogger(ctx); log != nil {
+		log.Info("balance updated", "account", from, "role", "sender", "before", beforeFromUserBalance, "after", fromUser.Balance)
+		if toUser.Hot {
+			log.Info("balance delta", "account", to, "role", "recipient", "value", value)
+		} else {
+			log.Info("balance updated", "account", to, "role", "recipient", "before", beforeToUserBalance, "after", toUser.Balance)
+		}
 	}
 
-	err = ctx.GetStub().PutState(to, toUserJSON)
+	transaction, err := recordTransaction(batch, TransactionTransfer, from, to, value)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	log.Printf("client %s balance updated from %d to %d", from, beforeFromUserBalance, fromUser.Balance)
-	log.Printf("recipient %s balance updated from %d to %d", to, beforeToUserBalance, toUser.Balance)
+	err = requireTravelRule(ctx, transaction)
+	if err != nil {
+		return nil, err
+	}
+	err = emitCreditEvent(batch, transaction, beforeFromUserBalance, fromUser.Balance, beforeToUserBalance, toUser.Balance)
+	if err != nil {
+		return nil, err
+	}
 
-	return nil
+	return transaction, nil
 }
 
 func SetEvent(ctx contractapi.TransactionContextInterface, eventName string, e event) error {
-	// Emit the Transfer event
-	transferEvent := e
-	transferEventJSON, err := json.Marshal(transferEvent)
+	return emitEvent(ctx, eventName, e)
+}
+
+// emitEvent sets the JSON encoding of payload as the transaction's event
+func emitEvent(ctx contractapi.TransactionContextInterface, eventName string, payload interface{}) error {
+	payload, err := eventPayload(ctx, payload)
 	if err != nil {
-		return fmt.Errorf("failed to obtain JSON encoding: %v", err)
+		return err
 	}
-	err = ctx.GetStub().SetEvent("Transfer", transferEventJSON)
+	payloadJSON, err := json.Marshal(payload)
 	if err != nil {
-		return fmt.Errorf("failed to set event: %v", err)
+		return fmt.Errorf("failed to obtain JSON encoding of %s event: %w", eventName, err)
+	}
+	err = ctx.GetStub().SetEvent(eventName, withCorrelationID(ctx.GetStub(), payloadJSON))
+	if err != nil {
+		return &StateError{Op: OpSetEvent, Key: eventName, Err: err}
+	}
+	if recorder, ok := ctx.(changeRecorder); ok {
+		recorder.recordEvent(eventName, payloadJSON)
 	}
 
 	return nil
 }
 
+// GetUser reads the account stored under id. It returns an error matching
+// ErrAccountNotFound if there is none.
 func GetUser(ctx contractapi.TransactionContextInterface, id string) (*User, error) {
-	userJSON, err := ctx.GetStub().GetState(id)
+	userJSON, err := readAccount(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	return userFromRecord(id, userJSON)
+}
+
+// readAccount reads the raw record of account id, nil if there is none
+func readAccount(ctx contractapi.TransactionContextInterface, id string) ([]byte, error) {
+	key, err := accountKey(ctx, id)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read from world state: %v", err)
+		return nil, err
 	}
+
+	return getState(ctx, key)
+}
+
+// userFromRecord decodes the account record stored under id, nil if none
+func userFromRecord(id string, userJSON []byte) (*User, error) {
 	if userJSON == nil {
-		return nil, fmt.Errorf("user %s does not exist", id)
+		return nil, newError(CodeAccountNotFound, "user %s does not exist", id)
 	}
 
 	var user User
-	err = json.Unmarshal(userJSON, &user)
+	err := decodeRecord(id, userJSON, &user, "userId", "type", "balance")
 	if err != nil {
 		return nil, err
 	}
@@ -153,113 +316,339 @@ func GetUser(ctx contractapi.TransactionContextInterface, id string) (*User, err
 }
 
 func (s *SmartContract) GetUser(ctx contractapi.TransactionContextInterface, id string) (*User, error) {
-	userJSON, err := ctx.GetStub().GetState(id)
+	if err := validate(validation.ID("id", id)); err != nil {
+		return nil, err
+	}
+
+	user, err := GetUser(ctx, id)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read from world state: %v", err)
+		return nil, err
 	}
-	if userJSON == nil {
-		return nil, fmt.Errorf("user %s does not exist", id)
+	err = rebaseUser(ctx, user)
+	if err != nil {
+		return nil, err
 	}
-
-	var user User
-	err = json.Unmarshal(userJSON, &user)
+	err = withInterest(ctx, user)
 	if err != nil {
 		return nil, err
 	}
-	return &user, nil
+
+	return user, netOfDemurrage(ctx, user)
 }
 
 func (s *SmartContract) CreateUser(ctx contractapi.TransactionContextInterface, _id string, _type string, _balance int) (*User, error) {
-	exist, _ := s.UserExist(ctx, _id)
-	if exist {
-		return nil, fmt.Errorf("user %s exist", _id)
+	err := validate(
+		validation.AccountID("id", _id),
+		validation.ID("type", _type),
+		validation.Amount("balance", _balance),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	err = requireInitialized(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	key, err := accountKey(ctx, _id)
+	if err != nil {
+		return nil, err
+	}
+	batch := newWriteBatch(ctx)
+	existing, err := batch.getState(key)
+	if err != nil {
+		return nil, err
+	}
+	if existing != nil {
+		return nil, newError(CodeAccountExists, "user %s exist", _id)
+	}
+	err = requireNoAlias(batch, _id)
+	if err != nil {
+		return nil, err
 	}
 
 	user := User{ID: _id, Type: _type, Balance: _balance}
-	userJSON, err := json.Marshal(user)
+	err = batch.putUser(&user)
+	if err != nil {
+		return nil, err
+	}
+
+	err = recordBalanceChange(batch, _id, 0, _balance)
+	if err != nil {
+		return nil, err
+	}
+
+	err = appendAudit(batch, _id, AuditCreate, 0, _balance, "")
 	if err != nil {
-		return nil, fmt.Errorf("cannot create user: %v", err)
+		return nil, err
 	}
 
-	err = ctx.GetStub().PutState(_id, userJSON)
+	err = batch.flush()
 	if err != nil {
-		return nil, fmt.Errorf("failed to put to world state. %v", err)
+		return nil, err
 	}
 
 	return &user, nil
 }
 
 func (s *SmartContract) DeleteUser(ctx contractapi.TransactionContextInterface, _id string) error {
-	exist, err := s.UserExist(ctx, _id)
-	if !exist {
-		return fmt.Errorf("user %s does not exist: %v", _id, err)
+	if err := validate(validation.ID("id", _id)); err != nil {
+		return err
+	}
+	if err := requireInitialized(ctx); err != nil {
+		return err
 	}
 
-	err = ctx.GetStub().DelState(_id)
+	key, err := accountKey(ctx, _id)
+	if err != nil {
+		return err
+	}
+	batch := newWriteBatch(ctx)
+	existing, err := batch.getState(key)
+	if err != nil {
+		return err
+	}
+	if existing == nil {
+		return newError(CodeAccountNotFound, "user %s does not exist", _id)
+	}
+
+	pending := 0
+	if isHot(existing) {
+		pending, err = deleteDeltas(batch, _id)
+		if err != nil {
+			return err
+		}
+	}
+	batch.delState(key)
+
+	// a corrupt record can still be deleted, its balance is then unknown
+	before, detail := 0, ""
+	if user, err := userFromRecord(_id, existing); err == nil {
+		before = user.Balance
+		err = unrankHolder(batch, user)
+		if err != nil {
+			return err
+		}
+		err = untypeAccount(batch, user)
+		if err != nil {
+			return err
+		}
+		noteDeletion(ctx, _id, before)
+	} else {
+		detail = "corrupt record"
+	}
+	err = appendAudit(batch, _id, AuditDelete, before, 0, detail)
 	if err != nil {
-		return fmt.Errorf("failed to put to world state. %v", err)
+		return err
+	}
+	if before+pending != 0 {
+		err = indexBalanceChange(batch, _id, LineDelete, "", -(before + pending))
+		if err != nil {
+			return err
+		}
 	}
 
-	return nil
+	return batch.flush()
 }
 
 func (s *SmartContract) UserExist(ctx contractapi.TransactionContextInterface, id string) (bool, error) {
-	userJSON, err := ctx.GetStub().GetState(id)
+	if err := validate(validation.ID("id", id)); err != nil {
+		return false, err
+	}
+
+	userJSON, err := readAccount(ctx, id)
 	if err != nil {
-		return false, fmt.Errorf("failed to read from world state: %v", err)
+		return false, err
 	}
 	if userJSON == nil {
-		return false, fmt.Errorf("user %s does not exist", id)
+		return false, newError(CodeAccountNotFound, "user %s does not exist", id)
 	}
 
 	return userJSON != nil, nil
 }
 
 func (s *SmartContract) GetTransaction(ctx contractapi.TransactionContextInterface, txid string) (*Transaction, error) {
-	transactionJSON, err := ctx.GetStub().GetState(txid)
+	if err := validate(validation.ID("txid", txid)); err != nil {
+		return nil, err
+	}
+
+	transactionJSON, err := getState(ctx, txid)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read from world state: %v", err)
+		return nil, err
 	}
 	if transactionJSON == nil {
-		return nil, fmt.Errorf("the transaction %s does not exist", txid)
+		return nil, newError(CodeTransactionNotFound, "the transaction %s does not exist", txid)
 	}
 
+	// records written before the type and proposal fields were added
+	// are still valid
 	var transaction Transaction
-	err = json.Unmarshal(transactionJSON, &transaction)
+	err = decodeRecord(txid, transactionJSON, &transaction, "txId", "from", "to", "value")
 	if err != nil {
 		return nil, err
 	}
 	return &transaction, nil
 }
 
-func SetTransaction(ctx contractapi.TransactionContextInterface, from string, to string, balance int) (*Transaction, error) {
-	txid := ctx.GetStub().GetTxID()
-	transaction := Transaction{TXID: txid, From: from, To: to, Value: balance}
-	transactionJSON, err := json.Marshal(transaction)
+// SetTransaction stores the Transaction record of the current transaction
+func SetTransaction(ctx contractapi.TransactionContextInterface, txType TransactionType, from string, to string, balance int) (*Transaction, error) {
+	batch := newWriteBatch(ctx)
+	transaction, err := recordTransaction(batch, txType, from, to, balance)
+	if err != nil {
+		return nil, err
+	}
+
+	err = batch.flush()
+	if err != nil {
+		return nil, err
+	}
+
+	return transaction, nil
+}
+
+// recordTransaction buffers the Transaction record of the current
+// transaction in batch
+func recordTransaction(batch *writeBatch, txType TransactionType, from string, to string, balance int) (*Transaction, error) {
+	stub := batch.ctx.GetStub()
+	txid := stub.GetTxID()
+
+	timestamp, err := txTimestamp(stub)
+	if err != nil {
+		return nil, err
+	}
+
+	transaction := Transaction{
+		TXID:           txid,
+		Type:           txType,
+		From:           from,
+		To:             to,
+		Value:          balance,
+		Timestamp:      timestamp,
+		ChannelID:      stub.GetChannelID(),
+		InitiatorMSPID: creatorMSPID(stub),
+		Initiator:      creatorID(stub),
+		CorrelationID:  correlationID(stub),
+	}
+	err = batch.putState(txid, transaction)
+	if err != nil {
+		return nil, err
+	}
+	err = indexTransaction(batch, &transaction)
+	if err != nil {
+		return nil, err
+	}
+	err = indexCorrelation(batch, &transaction)
+	if err != nil {
+		return nil, err
+	}
+	err = indexTransactionTime(batch, &transaction)
+	if err != nil {
+		return nil, err
+	}
+
+	// a large transfer's event replaces its typed event, see compliance.go
+	large, err := flagLargeTransfer(batch, &transaction)
+	if err == nil && large != nil {
+		err = emitLargeTransfer(batch, large)
+	} else if err == nil {
+		err = emitTransactionEvent(batch, &transaction)
+	}
 	if err != nil {
 		return nil, err
 	}
 
-	err = ctx.GetStub().PutState(txid, transactionJSON)
+	return &transaction, nil
+}
+
+// recordBalanceChange records a balance set outside a transfer as a
+// Transaction: a credit comes from "" and a debit goes to "". An unchanged
+// balance records nothing.
+func recordBalanceChange(batch *writeBatch, id string, before int, after int) error {
+	var err error
+	switch {
+	case after > before:
+		_, err = recordTransaction(batch, TransactionMint, "", id, after-before)
+	case after < before:
+		_, err = recordTransaction(batch, TransactionBurn, id, "", before-after)
+	}
+
+	return err
+}
+
+// txTimestamp formats the proposal timestamp, or returns "" if the stub has
+// none
+func txTimestamp(stub shim.ChaincodeStubInterface) (string, error) {
+	ts, err := stub.GetTxTimestamp()
+	if err != nil {
+		return "", fmt.Errorf("failed to read transaction timestamp: %w", err)
+	}
+	if ts == nil {
+		return "", nil
+	}
+
+	t, err := ptypes.Timestamp(ts)
+	if err != nil {
+		return "", fmt.Errorf("invalid transaction timestamp: %w", err)
+	}
+	return t.UTC().Format(time.RFC3339Nano), nil
+}
+
+// creatorID returns the client identity id of the creator, or "" if the
+// creator cannot be parsed
+func creatorID(stub shim.ChaincodeStubInterface) string {
+	caller, err := cid.New(stub)
+	if err != nil {
+		return ""
+	}
 
-	return &transaction, err
+	id, err := caller.GetID()
+	if err != nil {
+		return ""
+	}
+	return id
 }
 
 func (s *SmartContract) SetBalance(ctx contractapi.TransactionContextInterface, id string, balance int) (*User, error) {
-	user, err := GetUser(ctx, id)
+	err := validate(
+		validation.ID("id", id),
+		validation.Amount("balance", balance),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	err = requireInitialized(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	batch := newWriteBatch(ctx)
+	user, err := batch.getUser(id)
 	if err != nil {
-		return nil, fmt.Errorf("user id %s does not exist", id)
+		return nil, err
 	}
 
+	before := user.Balance
 	user.Balance = balance
-	userJSON, err := json.Marshal(user)
+	err = batch.putUser(user)
 	if err != nil {
 		return nil, err
 	}
 
-	err = ctx.GetStub().PutState(user.ID, userJSON)
+	err = recordBalanceChange(batch, id, before, balance)
 	if err != nil {
-		return nil, fmt.Errorf("failed to put to world state. %v", err)
+		return nil, err
+	}
+
+	err = appendAudit(batch, id, AuditSetBalance, before, balance, "")
+	if err != nil {
+		return nil, err
+	}
+
+	err = batch.flush()
+	if err != nil {
+		return nil, err
 	}
 
 	return user, nil
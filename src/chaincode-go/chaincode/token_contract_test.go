@@ -0,0 +1,526 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package chaincode_test
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+	"github.com/kkiu1756/my_fabric/src/chaincode-go/chaincode"
+	"github.com/kkiu1756/my_fabric/src/chaincode-go/chaincode/mocks"
+	"github.com/kkiu1756/my_fabric/src/chaincode-go/tokentest"
+	"github.com/kkiu1756/my_fabric/src/chaincode-go/validation"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+//go:generate counterfeiter -o mocks/transaction.go -fake-name TransactionContext . transactionContext
+type transactionContext interface {
+	contractapi.TransactionContextInterface
+}
+
+//go:generate counterfeiter -o mocks/chaincodestub.go -fake-name ChaincodeStub . chaincodeStub
+type chaincodeStub interface {
+	shim.ChaincodeStubInterface
+}
+
+// #########
+// HELPERS
+// #########
+
+// newContext returns a transaction context whose stub reads and writes the
+// given world state
+func newContext(state map[string][]byte) (*mocks.TransactionContext, *mocks.ChaincodeStub) {
+	stub := &mocks.ChaincodeStub{}
+	stub.GetStateStub = func(key string) ([]byte, error) {
+		return state[key], nil
+	}
+	stub.PutStateStub = func(key string, value []byte) error {
+		state[key] = value
+		return nil
+	}
+	stub.DelStateStub = func(key string) error {
+		delete(state, key)
+		return nil
+	}
+	stub.GetTxIDReturns("tx1")
+	stub.CreateCompositeKeyStub = shim.CreateCompositeKey
+
+	ctx := &mocks.TransactionContext{}
+	ctx.GetStubReturns(stub)
+
+	return ctx, stub
+}
+
+// failingRead fails reads of key with "unavailable", other keys are absent
+func failingRead(key string) func(string) ([]byte, error) {
+	return func(k string) ([]byte, error) {
+		if k == key {
+			return nil, fmt.Errorf("unavailable")
+		}
+		return nil, nil
+	}
+}
+
+func userJSON(t testing.TB, id string, balance int) []byte {
+	data, err := json.Marshal(chaincode.User{ID: id, Type: "user", Balance: balance})
+	require.NoError(t, err)
+	return data
+}
+
+// initialized returns world state holding only the record Initialize
+// writes, which every mutating method requires
+func initialized(t testing.TB) map[string][]byte {
+	data, err := json.Marshal(chaincode.Initialization{MSPID: "Org1MSP", AdminID: "admin", TXID: "init"})
+	require.NoError(t, err)
+	return map[string][]byte{chaincode.InitializationKey: data}
+}
+
+func ledger(t *testing.T) map[string][]byte {
+	state := initialized(t)
+	state["alice"] = userJSON(t, "alice", 100)
+	state["bob"] = userJSON(t, "bob", 20)
+	return state
+}
+
+func balanceOf(t *testing.T, state map[string][]byte, id string) int {
+	var user chaincode.User
+	require.NoError(t, json.Unmarshal(state[id], &user))
+	return user.Balance
+}
+
+func transactionOf(t *testing.T, state map[string][]byte, txid string) chaincode.Transaction {
+	var transaction chaincode.Transaction
+	require.NoError(t, json.Unmarshal(state[txid], &transaction))
+	return transaction
+}
+
+// #########
+// TESTS
+// #########
+
+func TestTransferFrom(t *testing.T) {
+	tests := []struct {
+		name  string
+		from  string
+		to    string
+		value int
+		setup func(stub *mocks.ChaincodeStub)
+		err   string
+	}{
+		{name: "transfers", from: "alice", to: "bob", value: 30},
+		{name: "zero value", from: "alice", to: "bob", value: 0},
+		{name: "whole balance", from: "alice", to: "bob", value: 100},
+		{name: "same account", from: "alice", to: "alice", value: 1, err: "failed to transfer: [INVALID_ARGUMENT] cannot transfer to and from same client account"},
+		{name: "negative value", from: "alice", to: "bob", value: -1, err: "[INVALID_ARGUMENT] invalid value: must not be negative"},
+		{name: "unknown sender", from: "carol", to: "bob", value: 1, err: "failed to transfer: [ACCOUNT_NOT_FOUND] user carol does not exist"},
+		{name: "unknown recipient", from: "alice", to: "carol", value: 1, err: "failed to transfer: [ACCOUNT_NOT_FOUND] user carol does not exist"},
+		{name: "insufficient balance", from: "bob", to: "alice", value: 21, err: "failed to transfer: [INSUFFICIENT_BALANCE] user balance lower than 21"},
+		{
+			name: "read failure", from: "alice", to: "bob", value: 1,
+			setup: func(stub *mocks.ChaincodeStub) { stub.GetStateReturns(nil, fmt.Errorf("unavailable")) },
+			err:   `[STATE_UNAVAILABLE] failed to read state "\x00config\x00initialized\x00": unavailable`,
+		},
+		{
+			name: "write failure", from: "alice", to: "bob", value: 1,
+			setup: func(stub *mocks.ChaincodeStub) { stub.PutStateReturns(fmt.Errorf("unavailable")) },
+			err:   `failed to transfer: [STATE_UNAVAILABLE] failed to write state "\x00accountcount\x00user\x0003\x00tx1\x00": unavailable`,
+		},
+		{
+			name: "transaction record failure", from: "alice", to: "bob", value: 1,
+			setup: func(stub *mocks.ChaincodeStub) { stub.PutStateReturnsOnCall(7, fmt.Errorf("unavailable")) },
+			err:   `failed to transfer: [STATE_UNAVAILABLE] failed to write state "tx1": unavailable`,
+		},
+		{
+			name: "event failure", from: "alice", to: "bob", value: 1,
+			setup: func(stub *mocks.ChaincodeStub) { stub.SetEventReturns(fmt.Errorf("unavailable")) },
+			err:   `failed to transfer: [STATE_UNAVAILABLE] failed to set event "Transfer": unavailable`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			state := ledger(t)
+			ctx, stub := newContext(state)
+			if tt.setup != nil {
+				tt.setup(stub)
+			}
+
+			tx, err := (&chaincode.SmartContract{}).TransferFrom(ctx, tt.from, tt.to, tt.value)
+			if tt.err != "" {
+				assert.EqualError(t, err, tt.err)
+				assert.Nil(t, tx)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, &chaincode.Transaction{TXID: "tx1", Type: chaincode.TransactionTransfer, From: tt.from, To: tt.to, Value: tt.value}, tx)
+			assert.Equal(t, 100-tt.value, balanceOf(t, state, "alice"))
+			assert.Equal(t, 20+tt.value, balanceOf(t, state, "bob"))
+			assert.NotNil(t, state["tx1"], "should record the transaction")
+
+			require.Equal(t, 1, stub.SetEventCallCount())
+			name, payload := stub.SetEventArgsForCall(0)
+			assert.Equal(t, "Transfer", name)
+			assert.JSONEq(t, fmt.Sprintf(`{"from":%q,"to":%q,"value":%d}`, tt.from, tt.to, tt.value), string(payload))
+		})
+	}
+}
+
+func TestInvalidArguments(t *testing.T) {
+	contract := &chaincode.SmartContract{}
+	tests := []struct {
+		name string
+		tx   transactionFunc
+		err  string
+	}{
+		{"TransferFrom empty recipient", transfer("alice", "", 1), "[INVALID_ARGUMENT] invalid to: must not be empty"},
+		{"TransferFrom amount too large", transfer("alice", "bob", validation.MaxAmount+1), "[INVALID_ARGUMENT] invalid value: must be at most 9007199254740991"},
+		{"GetUser", func(ctx contractapi.TransactionContextInterface) (interface{}, error) {
+			return contract.GetUser(ctx, "")
+		}, "[INVALID_ARGUMENT] invalid id: must not be empty"},
+		{"CreateUser type", func(ctx contractapi.TransactionContextInterface) (interface{}, error) {
+			return contract.CreateUser(ctx, "carol", "", 1)
+		}, "[INVALID_ARGUMENT] invalid type: must not be empty"},
+		{"DeleteUser", func(ctx contractapi.TransactionContextInterface) (interface{}, error) {
+			return nil, contract.DeleteUser(ctx, "a/b")
+		}, `[INVALID_ARGUMENT] invalid id: character "/" not allowed, use letters, digits and -_.@`},
+		{"UserExist", func(ctx contractapi.TransactionContextInterface) (interface{}, error) {
+			return contract.UserExist(ctx, "")
+		}, "[INVALID_ARGUMENT] invalid id: must not be empty"},
+		{"GetTransaction", func(ctx contractapi.TransactionContextInterface) (interface{}, error) {
+			return contract.GetTransaction(ctx, strings.Repeat("f", 65))
+		}, "[INVALID_ARGUMENT] invalid txid: must be at most 64 characters"},
+		{"SetBalance", func(ctx contractapi.TransactionContextInterface) (interface{}, error) {
+			return contract.SetBalance(ctx, "", 1)
+		}, "[INVALID_ARGUMENT] invalid id: must not be empty"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, stub := newContext(ledger(t))
+
+			_, err := tt.tx(ctx)
+			assert.EqualError(t, err, tt.err)
+			assert.True(t, errors.Is(err, validation.ErrInvalidArgument))
+			assert.Zero(t, stub.GetStateCallCount(), "arguments should be checked before reading state")
+		})
+	}
+}
+
+func TestTransferOverflow(t *testing.T) {
+	const maxInt = int(^uint(0) >> 1)
+	state := accounts(t, map[string]int{"alice": 100, "bob": maxInt - 1})
+	ctx, stub := newContext(state)
+	contract := &chaincode.SmartContract{}
+
+	_, err := contract.TransferFrom(ctx, "alice", "bob", 1)
+	require.NoError(t, err, "crediting up to the maximum should succeed")
+	assert.Equal(t, maxInt, balanceOf(t, state, "bob"))
+
+	_, err = contract.TransferFrom(ctx, "alice", "bob", 1)
+	assert.EqualError(t, err, "failed to transfer: [BALANCE_OVERFLOW] cannot credit bob: balance overflow")
+	assert.True(t, errors.Is(err, chaincode.ErrBalanceOverflow))
+	assert.Equal(t, 99, balanceOf(t, state, "alice"), "sender should not be debited")
+	assert.Equal(t, maxInt, balanceOf(t, state, "bob"), "recipient should not wrap around")
+	assert.Equal(t, 9, stub.PutStateCallCount(), "only the first transfer should write")
+
+	_, err = contract.TransferFrom(ctx, "alice", "bob", 0)
+	assert.NoError(t, err, "a zero transfer into a full account should succeed")
+}
+
+func TestGetUser(t *testing.T) {
+	tests := []struct {
+		name  string
+		id    string
+		state map[string][]byte
+		setup func(stub *mocks.ChaincodeStub)
+		want  *chaincode.User
+		err   string
+	}{
+		{name: "existing user", id: "alice", want: &chaincode.User{ID: "alice", Type: "user", Balance: 100}},
+		{name: "unknown user", id: "carol", err: "[ACCOUNT_NOT_FOUND] user carol does not exist"},
+		{name: "corrupt record", id: "alice", state: map[string][]byte{"alice": []byte("{")}, err: `[CORRUPT_RECORD] record "alice" is corrupt: unexpected end of JSON input`},
+		{
+			name: "unknown field", id: "alice",
+			state: map[string][]byte{"alice": []byte(`{"userId":"alice","type":"user","balance":100,"frozen":true}`)},
+			err:   `[CORRUPT_RECORD] record "alice" is corrupt: json: unknown field "frozen"`,
+		},
+		{
+			name: "missing field", id: "alice",
+			state: map[string][]byte{"alice": []byte(`{"userId":"alice","type":"user"}`)},
+			err:   `[CORRUPT_RECORD] record "alice" is corrupt: missing field "balance"`,
+		},
+		{
+			name: "trailing data", id: "alice",
+			state: map[string][]byte{"alice": []byte(`{"userId":"alice","type":"user","balance":100}{}`)},
+			err:   `[CORRUPT_RECORD] record "alice" is corrupt: invalid character '{' after top-level value`,
+		},
+		{
+			name: "wrong type", id: "alice",
+			state: map[string][]byte{"alice": []byte(`{"userId":"alice","type":"user","balance":"100"}`)},
+			err:   `[CORRUPT_RECORD] record "alice" is corrupt: json: cannot unmarshal string into Go struct field User.balance of type int`,
+		},
+		{
+			name: "read failure", id: "alice",
+			setup: func(stub *mocks.ChaincodeStub) { stub.GetStateStub = failingRead("alice") },
+			err:   `[STATE_UNAVAILABLE] failed to read state "alice": unavailable`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			state := tt.state
+			if state == nil {
+				state = ledger(t)
+			}
+			ctx, stub := newContext(state)
+			if tt.setup != nil {
+				tt.setup(stub)
+			}
+
+			user, err := (&chaincode.SmartContract{}).GetUser(ctx, tt.id)
+			if tt.err != "" {
+				assert.EqualError(t, err, tt.err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, user)
+
+			user, err = chaincode.GetUser(ctx, tt.id)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, user, "helper should match the contract method")
+		})
+	}
+}
+
+func TestAccountNotFound(t *testing.T) {
+	contract := &chaincode.SmartContract{}
+	tests := []struct {
+		name string
+		tx   transactionFunc
+	}{
+		{"GetUser", func(ctx contractapi.TransactionContextInterface) (interface{}, error) {
+			return contract.GetUser(ctx, "carol")
+		}},
+		{"UserExist", func(ctx contractapi.TransactionContextInterface) (interface{}, error) {
+			return contract.UserExist(ctx, "carol")
+		}},
+		{"DeleteUser", func(ctx contractapi.TransactionContextInterface) (interface{}, error) {
+			return nil, contract.DeleteUser(ctx, "carol")
+		}},
+		{"SetBalance", func(ctx contractapi.TransactionContextInterface) (interface{}, error) {
+			return contract.SetBalance(ctx, "carol", 1)
+		}},
+		{"TransferFrom sender", transfer("carol", "bob", 1)},
+		{"TransferFrom recipient", transfer("alice", "carol", 1)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, stub := newContext(ledger(t))
+
+			_, err := tt.tx(ctx)
+			assert.True(t, errors.Is(err, chaincode.ErrAccountNotFound), "error should match ErrAccountNotFound: %v", err)
+			assert.Contains(t, err.Error(), "[ACCOUNT_NOT_FOUND] user carol does not exist")
+			assert.Zero(t, stub.PutStateCallCount(), "nothing should be written")
+		})
+	}
+
+	ctx, _ := newContext(ledger(t))
+	_, err := contract.GetTransaction(ctx, "tx2")
+	assert.False(t, errors.Is(err, chaincode.ErrAccountNotFound), "a missing transaction is not a missing account")
+}
+
+func TestCreateUser(t *testing.T) {
+	tests := []struct {
+		name    string
+		id      string
+		balance int
+		setup   func(stub *mocks.ChaincodeStub)
+		err     string
+	}{
+		{name: "new user", id: "carol", balance: 50},
+		{name: "zero balance", id: "carol"},
+		{name: "existing user", id: "alice", balance: 50, err: "[ACCOUNT_EXISTS] user alice exist"},
+		{name: "invalid UTF-8", id: "\xd0", err: `[INVALID_ARGUMENT] invalid id: character "\xd0" not allowed, use letters, digits and -_.@`},
+		{name: "negative balance", id: "carol", balance: -1, err: "[INVALID_ARGUMENT] invalid balance: must not be negative"},
+		{
+			name: "write failure", id: "carol", balance: 50,
+			setup: func(stub *mocks.ChaincodeStub) { stub.PutStateReturns(fmt.Errorf("unavailable")) },
+			err:   `[STATE_UNAVAILABLE] failed to write state "\x00accountcount\x00merchant\x0003\x00tx1\x00": unavailable`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			state := ledger(t)
+			ctx, stub := newContext(state)
+			if tt.setup != nil {
+				tt.setup(stub)
+			}
+
+			user, err := (&chaincode.SmartContract{}).CreateUser(ctx, tt.id, "merchant", tt.balance)
+			if tt.err != "" {
+				assert.EqualError(t, err, tt.err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, &chaincode.User{ID: tt.id, Type: "merchant", Balance: tt.balance}, user)
+			assert.Equal(t, tt.balance, balanceOf(t, state, tt.id))
+			if tt.balance == 0 {
+				assert.NotContains(t, state, "tx1", "an empty account records no transaction")
+			} else {
+				expected := chaincode.Transaction{TXID: "tx1", Type: chaincode.TransactionMint, To: "carol", Value: tt.balance}
+				assert.Equal(t, expected, transactionOf(t, state, "tx1"))
+			}
+		})
+	}
+}
+
+func TestDeleteUser(t *testing.T) {
+	tests := []struct {
+		name  string
+		id    string
+		setup func(stub *mocks.ChaincodeStub)
+		err   string
+	}{
+		{name: "existing user", id: "alice"},
+		{name: "unknown user", id: "carol", err: "[ACCOUNT_NOT_FOUND] user carol does not exist"},
+		{
+			name: "delete failure", id: "alice",
+			setup: func(stub *mocks.ChaincodeStub) { stub.DelStateReturns(fmt.Errorf("unavailable")) },
+			err:   `[STATE_UNAVAILABLE] failed to delete state "\x00accounttype\x00user\x00alice\x00": unavailable`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			state := ledger(t)
+			ctx, stub := newContext(state)
+			if tt.setup != nil {
+				tt.setup(stub)
+			}
+
+			err := (&chaincode.SmartContract{}).DeleteUser(ctx, tt.id)
+			if tt.err != "" {
+				assert.EqualError(t, err, tt.err)
+				return
+			}
+			require.NoError(t, err)
+			assert.NotContains(t, state, tt.id)
+		})
+	}
+}
+
+func TestUserExist(t *testing.T) {
+	ctx, stub := newContext(ledger(t))
+	contract := &chaincode.SmartContract{}
+
+	exists, err := contract.UserExist(ctx, "alice")
+	require.NoError(t, err)
+	assert.True(t, exists)
+
+	exists, err = contract.UserExist(ctx, "carol")
+	assert.EqualError(t, err, "[ACCOUNT_NOT_FOUND] user carol does not exist")
+	assert.False(t, exists)
+
+	stub.GetStateStub = failingRead("alice")
+	_, err = contract.UserExist(ctx, "alice")
+	assert.EqualError(t, err, `[STATE_UNAVAILABLE] failed to read state "alice": unavailable`)
+}
+
+func TestGetTransaction(t *testing.T) {
+	state := ledger(t)
+	state["tx1"] = []byte(`{"txId":"tx1","from":"alice","to":"bob","value":5}`)
+	state["tx2"] = []byte("{")
+	ctx, stub := newContext(state)
+	contract := &chaincode.SmartContract{}
+
+	tx, err := contract.GetTransaction(ctx, "tx1")
+	require.NoError(t, err)
+	assert.Equal(t, &chaincode.Transaction{TXID: "tx1", From: "alice", To: "bob", Value: 5}, tx)
+
+	_, err = contract.GetTransaction(ctx, "tx3")
+	assert.EqualError(t, err, "[TRANSACTION_NOT_FOUND] the transaction tx3 does not exist")
+
+	_, err = contract.GetTransaction(ctx, "tx2")
+	assert.EqualError(t, err, `[CORRUPT_RECORD] record "tx2" is corrupt: unexpected end of JSON input`)
+	assert.True(t, errors.Is(err, chaincode.ErrCorruptRecord))
+
+	state["tx2"] = []byte(`{"txId":"tx2","from":"alice","value":5}`)
+	_, err = contract.GetTransaction(ctx, "tx2")
+	assert.EqualError(t, err, `[CORRUPT_RECORD] record "tx2" is corrupt: missing field "to"`)
+
+	stub.GetStateReturns(nil, fmt.Errorf("unavailable"))
+	_, err = contract.GetTransaction(ctx, "tx1")
+	assert.EqualError(t, err, `[STATE_UNAVAILABLE] failed to read state "tx1": unavailable`)
+}
+
+func TestSetBalance(t *testing.T) {
+	state := ledger(t)
+	ctx, stub := newContext(state)
+	contract := &chaincode.SmartContract{}
+
+	user, err := contract.SetBalance(ctx, "bob", 75)
+	require.NoError(t, err)
+	assert.Equal(t, &chaincode.User{ID: "bob", Type: "user", Balance: 75}, user)
+	assert.Equal(t, 75, balanceOf(t, state, "bob"))
+	mint := chaincode.Transaction{TXID: "tx1", Type: chaincode.TransactionMint, To: "bob", Value: 55}
+	assert.Equal(t, mint, transactionOf(t, state, "tx1"))
+
+	_, err = contract.SetBalance(ctx, "bob", 60)
+	require.NoError(t, err)
+	burn := chaincode.Transaction{TXID: "tx1", Type: chaincode.TransactionBurn, From: "bob", Value: 15}
+	assert.Equal(t, burn, transactionOf(t, state, "tx1"))
+
+	_, err = contract.SetBalance(ctx, "carol", 75)
+	assert.EqualError(t, err, "[ACCOUNT_NOT_FOUND] user carol does not exist")
+
+	_, err = contract.SetBalance(ctx, "bob", -1)
+	assert.EqualError(t, err, "[INVALID_ARGUMENT] invalid balance: must not be negative")
+
+	stub.PutStateReturns(fmt.Errorf("unavailable"))
+	_, err = contract.SetBalance(ctx, "bob", 80)
+	assert.EqualError(t, err, `[STATE_UNAVAILABLE] failed to write state "\x00accountcount\x00user\x0003\x00tx1\x00": unavailable`)
+}
+
+func TestSetTransaction(t *testing.T) {
+	l := tokentest.NewLedger(t).
+		WithTimestamp(time.Date(2021, 3, 4, 5, 6, 7, 8, time.FixedZone("CET", 3600))).
+		WithChannel("mychannel").
+		WithCaller("Org1MSP", "User1@org1.example.com", "client")
+
+	tx, err := chaincode.SetTransaction(l.Context, chaincode.TransactionTransfer, "alice", "bob", 5)
+	require.NoError(t, err)
+	assert.Equal(t, "2021-03-04T04:06:07.000000008Z", tx.Timestamp, "should be UTC")
+	assert.Equal(t, "mychannel", tx.ChannelID)
+	assert.Equal(t, "Org1MSP", tx.InitiatorMSPID)
+	initiator, err := base64.StdEncoding.DecodeString(tx.Initiator)
+	require.NoError(t, err)
+	assert.Contains(t, string(initiator), "CN=User1@org1.example.com")
+
+	expected := &chaincode.Transaction{
+		TXID: "tx1", Type: chaincode.TransactionTransfer, From: "alice", To: "bob", Value: 5,
+		Timestamp: tx.Timestamp, ChannelID: "mychannel", InitiatorMSPID: "Org1MSP", Initiator: tx.Initiator,
+	}
+	assert.Equal(t, expected, tx)
+	assert.Equal(t, *expected, l.Transaction("tx1"))
+
+	l.Stub.GetTxTimestampReturns(nil, fmt.Errorf("no header"))
+	_, err = chaincode.SetTransaction(l.Context, chaincode.TransactionTransfer, "alice", "bob", 5)
+	assert.EqualError(t, err, "failed to read transaction timestamp: no header")
+}
+
+func TestGetEvaluateTransactions(t *testing.T) {
+	assert.ElementsMatch(t, []string{"GetUser", "UserExist", "GetTransaction", "Initialized", "FindKeyCollisions", "PendingDeltas", "ShardCount", "ListAccounts", "GetAccount", "Version", "ExportState", "TotalSupply", "GetAuditTrail", "GetAuditorReads", "GetTransferLimit", "GetPendingTransfer", "GetLargeTransferReview", "ListLargeTransferReviews", "GenerateStatement", "GetTravelRuleRecord", "GetPendingConfirmation", "GetSuspension", "ListSuspendedAccounts", "GetGuardians", "GetRecovery", "GetPaymentRequest", "GetReversibleTransfer", "GetDispute", "GetDormancyFlag", "GetEmission", "GetSnapshot", "GetDistribution", "AirdropClaimed", "GetWhitelist", "VerifyWhitelist", "GetReferral", "GetSettlementReport", "GetItem", "GetReceipt", "ListItems", "VerifyReceipt", "GetRefunds", "GetRefundStatus", "GetCurrency", "GetCurrencyBalance", "GetCurrencyTransaction", "GetRate", "GetAttestation", "CompareReserves", "GetRebaseHistory", "GetCreditExposure", "GetGiftCard", "GetGiftCardBreakage", "GetAlias", "GetWallet", "ListWallets", "GetRollupBalance", "GetTreasury", "GetTreasuryPayment", "GetDelegate", "GetRevokedCertificate", "GetTopHolders", "GetAccountDistribution", "StreamState", "GetTransactionsByCorrelationID", "ListAccountsByType", "ListAccountsByBalance", "ListTransactionsByTime"}, (&chaincode.SmartContract{}).GetEvaluateTransactions())
+}
@@ -0,0 +1,170 @@
+package chaincode
+
+import (
+	"bytes"
+	"encoding/json"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+	"github.com/kkiu1756/my_fabric/src/chaincode-go/validation"
+)
+
+// A transfer of more than the ledger's TravelRuleThreshold must carry the
+// identities of its originator and beneficiary, as a TravelRuleInfo in the
+// proposal's transient field TravelRuleTransientKey. The contract stores
+// them with the transfer in ComplianceCollection, see
+// collections_config.json, so they never reach the public ledger, and
+// rejects the transfer if they are absent.
+const (
+	ComplianceCollection   = "complianceCollection"
+	TravelRuleTransientKey = "travelRule"
+)
+
+// TravelRuleParty identifies the originator or beneficiary of a transfer
+type TravelRuleParty struct {
+	Name string `json:"name"`
+	// Address and ID, such as a national id or LEI, are optional
+	Address string `json:"address,omitempty" metadata:"address,optional"`
+	ID      string `json:"id,omitempty" metadata:"id,optional"`
+}
+
+// TravelRuleInfo is the transient input of a transfer over the threshold
+type TravelRuleInfo struct {
+	Originator  TravelRuleParty `json:"originator"`
+	Beneficiary TravelRuleParty `json:"beneficiary"`
+}
+
+// TravelRuleRecord is stored in ComplianceCollection under the transaction
+// id of the transfer
+type TravelRuleRecord struct {
+	TXID        string          `json:"txId"`
+	From        string          `json:"from"`
+	To          string          `json:"to"`
+	Value       int             `json:"value"`
+	Originator  TravelRuleParty `json:"originator"`
+	Beneficiary TravelRuleParty `json:"beneficiary"`
+}
+
+// requireTravelRule stores the travel rule record of transaction if it is
+// over the threshold, and fails if the proposal lacks its TravelRuleInfo
+func requireTravelRule(ctx contractapi.TransactionContextInterface, transaction *Transaction) error {
+	init, err := ledgerConfig(ctx)
+	if err != nil || init == nil || init.TravelRuleThreshold == 0 || transaction.Value <= init.TravelRuleThreshold {
+		return err
+	}
+
+	stub := ctx.GetStub()
+	transient, err := stub.GetTransient()
+	if err != nil {
+		return wrapError(CodeInvalidArgument, err, "cannot read the transient field")
+	}
+	data, ok := transient[TravelRuleTransientKey]
+	if !ok {
+		return newError(CodeInvalidArgument, "a transfer of more than %d needs originator and beneficiary in transient field %s", init.TravelRuleThreshold, TravelRuleTransientKey)
+	}
+	info, err := decodeTravelRule(data)
+	if err != nil {
+		return err
+	}
+
+	record, err := json.Marshal(TravelRuleRecord{
+		TXID:        transaction.TXID,
+		From:        transaction.From,
+		To:          transaction.To,
+		Value:       transaction.Value,
+		Originator:  info.Originator,
+		Beneficiary: info.Beneficiary,
+	})
+	if err != nil {
+		return err
+	}
+	err = stub.PutPrivateData(ComplianceCollection, transaction.TXID, record)
+	if err != nil {
+		return &StateError{Op: OpWritePrivateData, Key: transaction.TXID, Err: err}
+	}
+
+	return nil
+}
+
+// decodeTravelRule strictly decodes and validates a TravelRuleInfo
+func decodeTravelRule(data []byte) (*TravelRuleInfo, error) {
+	var info TravelRuleInfo
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	err := decoder.Decode(&info)
+	if err != nil {
+		return nil, validate(&validation.Error{Field: TravelRuleTransientKey, Reason: err.Error()})
+	}
+
+	parties := []struct {
+		field string
+		party TravelRuleParty
+	}{{"originator", info.Originator}, {"beneficiary", info.Beneficiary}}
+	for _, p := range parties {
+		field := TravelRuleTransientKey + "." + p.field
+		errs := []error{
+			validation.Memo(field+".name", p.party.Name),
+			validation.Memo(field+".address", p.party.Address),
+			validation.Memo(field+".id", p.party.ID),
+		}
+		if p.party.Name == "" {
+			errs = append(errs, &validation.Error{Field: field + ".name", Reason: "must not be empty"})
+		}
+		if err := validate(errs...); err != nil {
+			return nil, err
+		}
+	}
+
+	return &info, nil
+}
+
+// SetTravelRuleThreshold requires travel rule information on every later
+// transfer of more than threshold; 0 requires none. Only an org admin can
+// call it.
+func (s *SmartContract) SetTravelRuleThreshold(ctx contractapi.TransactionContextInterface, threshold int) (*Initialization, error) {
+	if err := validate(validation.Amount("threshold", threshold)); err != nil {
+		return nil, err
+	}
+	if err := requireInitialized(ctx); err != nil {
+		return nil, err
+	}
+	if _, err := requireAdmin(ctx, "change the travel rule threshold"); err != nil {
+		return nil, err
+	}
+
+	init, err := ledgerConfig(ctx)
+	if err != nil {
+		return nil, err
+	}
+	init.TravelRuleThreshold = threshold
+
+	err = putState(ctx, InitializationKey, init)
+	if err != nil {
+		return nil, err
+	}
+
+	return init, nil
+}
+
+// GetTravelRuleRecord returns the travel rule record of transfer txid. Only
+// peers of the collection's member orgs hold it.
+func (s *SmartContract) GetTravelRuleRecord(ctx contractapi.TransactionContextInterface, txid string) (*TravelRuleRecord, error) {
+	if err := validate(validation.ID("txid", txid)); err != nil {
+		return nil, err
+	}
+
+	data, err := ctx.GetStub().GetPrivateData(ComplianceCollection, txid)
+	if err != nil {
+		return nil, &StateError{Op: OpReadPrivateData, Key: txid, Err: err}
+	}
+	if data == nil {
+		return nil, newError(CodeTransactionNotFound, "no travel rule record for transaction %s", txid)
+	}
+
+	var record TravelRuleRecord
+	err = decodeRecord(txid, data, &record, "txId", "from", "to", "value", "originator", "beneficiary")
+	if err != nil {
+		return nil, err
+	}
+
+	return &record, nil
+}
@@ -0,0 +1,116 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package chaincode_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/kkiu1756/my_fabric/src/chaincode-go/chaincode"
+	"github.com/kkiu1756/my_fabric/src/chaincode-go/tokentest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// #########
+// HELPERS
+// #########
+
+const travelRuleJSON = `{"originator":{"name":"Alice Kim","id":"KR-1"},"beneficiary":{"name":"Bob Lee"}}`
+
+// travelLedger returns a ledger requiring travel rule information on
+// transfers of more than 100, with its compliance collection in collection
+func travelLedger(t *testing.T) (*tokentest.Ledger, map[string][]byte) {
+	l := adminLedger(t).WithAccount("alice", "user", 1000).WithAccount("bob", "user", 0)
+	_, err := (&chaincode.SmartContract{}).SetTravelRuleThreshold(l.Context, 100)
+	require.NoError(t, err)
+
+	collection := map[string][]byte{}
+	l.Stub.PutPrivateDataStub = func(name string, key string, value []byte) error {
+		if name != chaincode.ComplianceCollection {
+			return fmt.Errorf("unexpected collection %s", name)
+		}
+		collection[key] = value
+		return nil
+	}
+	l.Stub.GetPrivateDataStub = func(name string, key string) ([]byte, error) {
+		return collection[key], nil
+	}
+	return l, collection
+}
+
+// #########
+// TESTS
+// #########
+
+func TestTravelRule(t *testing.T) {
+	l, collection := travelLedger(t)
+	contract := &chaincode.SmartContract{}
+
+	l.WithTxID("small")
+	_, err := contract.TransferFrom(l.Context, "alice", "bob", 100)
+	require.NoError(t, err, "a transfer at the threshold needs no travel rule information")
+	assert.Empty(t, collection)
+
+	l.WithTxID("large")
+	l.Stub.GetTransientReturns(map[string][]byte{chaincode.TravelRuleTransientKey: []byte(travelRuleJSON)}, nil)
+	_, err = contract.TransferFrom(l.Context, "alice", "bob", 101)
+	require.NoError(t, err)
+	l.AssertBalance("bob", 201)
+
+	record, err := contract.GetTravelRuleRecord(l.Context, "large")
+	require.NoError(t, err)
+	assert.Equal(t, &chaincode.TravelRuleRecord{
+		TXID: "large", From: "alice", To: "bob", Value: 101,
+		Originator:  chaincode.TravelRuleParty{Name: "Alice Kim", ID: "KR-1"},
+		Beneficiary: chaincode.TravelRuleParty{Name: "Bob Lee"},
+	}, record)
+	assert.NotContains(t, string(l.State["large"]), "Alice Kim", "travel rule information should stay off the public ledger")
+
+	_, err = contract.GetTravelRuleRecord(l.Context, "small")
+	assert.EqualError(t, err, "[TRANSACTION_NOT_FOUND] no travel rule record for transaction small")
+}
+
+func TestTravelRuleRejects(t *testing.T) {
+	tests := []struct {
+		name      string
+		transient map[string][]byte
+		err       string
+	}{
+		{
+			"absent", map[string][]byte{},
+			"failed to transfer: [INVALID_ARGUMENT] a transfer of more than 100 needs originator and beneficiary in transient field travelRule",
+		},
+		{
+			"malformed", map[string][]byte{chaincode.TravelRuleTransientKey: []byte(`{"originator":{"name":"Alice"},"note":"x"}`)},
+			`failed to transfer: [INVALID_ARGUMENT] invalid travelRule: json: unknown field "note"`,
+		},
+		{
+			"no beneficiary", map[string][]byte{chaincode.TravelRuleTransientKey: []byte(`{"originator":{"name":"Alice"}}`)},
+			"failed to transfer: [INVALID_ARGUMENT] invalid travelRule.beneficiary.name: must not be empty",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l, collection := travelLedger(t)
+			l.Stub.GetTransientReturns(tt.transient, nil)
+			writes := l.Stub.PutStateCallCount()
+
+			_, err := (&chaincode.SmartContract{}).TransferFrom(l.Context, "alice", "bob", 500)
+			assert.EqualError(t, err, tt.err)
+			assert.Equal(t, writes, l.Stub.PutStateCallCount(), "a rejected transfer should write nothing")
+			assert.Empty(t, collection)
+			l.AssertBalance("alice", 1000)
+		})
+	}
+}
+
+func TestSetTravelRuleThresholdRequiresAdmin(t *testing.T) {
+	l := tokentest.NewLedger(t).WithCaller("Org1MSP", "User1@org1.example.com", "client")
+
+	_, err := (&chaincode.SmartContract{}).SetTravelRuleThreshold(l.Context, 100)
+	assert.EqualError(t, err, "[UNAUTHORIZED] only an organization admin can change the travel rule threshold")
+}
@@ -0,0 +1,353 @@
+package chaincode
+
+import (
+	"fmt"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+	"github.com/kkiu1756/my_fabric/src/chaincode-go/validation"
+)
+
+// An org admin makes an account the treasury of their organization with
+// SetTreasury. Transfers can then only debit it through
+// ProposeTreasuryPayment, which any member of the organization can call: a
+// payment up to the treasury's threshold is executed at once, a larger one
+// waits for an admin of the organization to ApproveTreasuryPayment or
+// RejectTreasuryPayment it. Every payment keeps its record, with who
+// proposed and who decided it.
+
+// Treasury payment statuses
+const (
+	TreasuryPaymentPending  = "pending"
+	TreasuryPaymentExecuted = "executed"
+	TreasuryPaymentRejected = "rejected"
+)
+
+// EventTreasuryPaymentProposed is the event of a payment waiting for
+// approval
+const EventTreasuryPaymentProposed = "TreasuryPaymentProposed"
+
+const (
+	treasuryObjectType        = "treasury"
+	treasuryPaymentObjectType = "treasurypayment"
+)
+
+// Treasury is an account owned by an organization
+type Treasury struct {
+	Account string `json:"account"`
+	MSPID   string `json:"mspId"`
+	// Threshold is the largest payment executed without approval
+	Threshold int `json:"threshold"`
+}
+
+// TreasuryPayment is a payment from a treasury
+type TreasuryPayment struct {
+	// ID is the transaction id of the ProposeTreasuryPayment call
+	ID       string `json:"id"`
+	Treasury string `json:"treasury"`
+	To       string `json:"to"`
+	Value    int    `json:"value"`
+	Memo     string `json:"memo,omitempty" metadata:"memo,optional"`
+	// Status is TreasuryPaymentPending, TreasuryPaymentExecuted or
+	// TreasuryPaymentRejected
+	Status        string `json:"status"`
+	ProposerMSPID string `json:"proposerMspId"`
+	Proposer      string `json:"proposer"`
+	// ProposedAt is the proposal timestamp of the proposal, RFC 3339
+	ProposedAt string `json:"proposedAt"`
+	// Approver decided the payment at DecidedAt in transaction DecisionTxID,
+	// empty for a payment executed without approval or still pending
+	Approver     string `json:"approver,omitempty" metadata:"approver,optional"`
+	DecidedAt    string `json:"decidedAt,omitempty" metadata:"decidedAt,optional"`
+	DecisionTxID string `json:"decisionTxId,omitempty" metadata:"decisionTxId,optional"`
+}
+
+// SetTreasury makes account the treasury of the caller's organization,
+// paying up to threshold without approval. Only an org admin can call it,
+// and only an admin of the same organization can change it later.
+func (s *SmartContract) SetTreasury(ctx contractapi.TransactionContextInterface, account string, threshold int) (*Treasury, error) {
+	err := validate(
+		validation.ID("account", account),
+		validation.Amount("threshold", threshold),
+	)
+	if err != nil {
+		return nil, err
+	}
+	if err := requireInitialized(ctx); err != nil {
+		return nil, err
+	}
+	if _, err := requireAdmin(ctx, "set treasuries"); err != nil {
+		return nil, err
+	}
+	mspID := creatorMSPID(ctx.GetStub())
+
+	batch := newWriteBatch(ctx)
+	if _, err := batch.getUser(account); err != nil {
+		return nil, err
+	}
+	key, existing, err := treasury(batch, account)
+	if err != nil {
+		return nil, err
+	}
+	if existing != nil && existing.MSPID != mspID {
+		return nil, newError(CodeUnauthorized, "account %s is the treasury of %s", account, existing.MSPID)
+	}
+
+	updated := &Treasury{Account: account, MSPID: mspID, Threshold: threshold}
+	err = batch.putState(key, updated)
+	if err != nil {
+		return nil, err
+	}
+
+	err = batch.flush()
+	if err != nil {
+		return nil, err
+	}
+
+	return updated, nil
+}
+
+// GetTreasury returns the treasury account
+func (s *SmartContract) GetTreasury(ctx contractapi.TransactionContextInterface, account string) (*Treasury, error) {
+	if err := validate(validation.ID("account", account)); err != nil {
+		return nil, err
+	}
+
+	return orgTreasury(newWriteBatch(ctx), account)
+}
+
+// ProposeTreasuryPayment proposes paying value from treasury account to to,
+// executing it if value is within the treasury's threshold. Only a member
+// of the treasury's organization can call it.
+func (s *SmartContract) ProposeTreasuryPayment(ctx contractapi.TransactionContextInterface, account string, to string, value int, memo string) (*TreasuryPayment, error) {
+	err := validate(
+		validation.ID("account", account),
+		validation.ID("to", to),
+		validation.Amount("value", value),
+		validation.Memo("memo", memo),
+	)
+	if err != nil {
+		return nil, err
+	}
+	if err := requireInitialized(ctx); err != nil {
+		return nil, err
+	}
+
+	batch := newWriteBatch(ctx)
+	owner, err := orgTreasury(batch, account)
+	if err != nil {
+		return nil, err
+	}
+	stub := ctx.GetStub()
+	if creatorMSPID(stub) != owner.MSPID {
+		return nil, newError(CodeUnauthorized, "only a member of %s can propose payments from %s", owner.MSPID, account)
+	}
+	if _, err := batch.getUser(to); err != nil {
+		return nil, err
+	}
+
+	timestamp, err := txTimestamp(stub)
+	if err != nil {
+		return nil, err
+	}
+	payment := &TreasuryPayment{
+		ID:            stub.GetTxID(),
+		Treasury:      account,
+		To:            to,
+		Value:         value,
+		Memo:          memo,
+		Status:        TreasuryPaymentPending,
+		ProposerMSPID: creatorMSPID(stub),
+		Proposer:      creatorID(stub),
+		ProposedAt:    timestamp,
+	}
+	if value <= owner.Threshold {
+		err = payFromTreasury(batch, payment)
+	} else {
+		err = emitEvent(ctx, EventTreasuryPaymentProposed, payment)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return putTreasuryPayment(batch, payment)
+}
+
+// ApproveTreasuryPayment executes the pending treasury payment id. Only an
+// admin of the treasury's organization can call it.
+func (s *SmartContract) ApproveTreasuryPayment(ctx contractapi.TransactionContextInterface, id string) (*TreasuryPayment, error) {
+	batch, payment, err := decideTreasuryPayment(ctx, id, "approve")
+	if err != nil {
+		return nil, err
+	}
+	err = payFromTreasury(batch, payment)
+	if err != nil {
+		return nil, err
+	}
+
+	return putTreasuryPayment(batch, payment)
+}
+
+// RejectTreasuryPayment drops the pending treasury payment id. Only an
+// admin of the treasury's organization can call it.
+func (s *SmartContract) RejectTreasuryPayment(ctx contractapi.TransactionContextInterface, id string) (*TreasuryPayment, error) {
+	batch, payment, err := decideTreasuryPayment(ctx, id, "reject")
+	if err != nil {
+		return nil, err
+	}
+	payment.Status = TreasuryPaymentRejected
+
+	return putTreasuryPayment(batch, payment)
+}
+
+// GetTreasuryPayment returns the treasury payment proposed by transaction
+// id
+func (s *SmartContract) GetTreasuryPayment(ctx contractapi.TransactionContextInterface, id string) (*TreasuryPayment, error) {
+	if err := validate(validation.ID("id", id)); err != nil {
+		return nil, err
+	}
+
+	return treasuryPayment(newWriteBatch(ctx), id)
+}
+
+// decideTreasuryPayment checks the caller can action the pending treasury
+// payment id and stamps it with the decision
+func decideTreasuryPayment(ctx contractapi.TransactionContextInterface, id string, action string) (*writeBatch, *TreasuryPayment, error) {
+	if err := validate(validation.ID("id", id)); err != nil {
+		return nil, nil, err
+	}
+	if err := requireInitialized(ctx); err != nil {
+		return nil, nil, err
+	}
+	if _, err := requireAdmin(ctx, action+" treasury payments"); err != nil {
+		return nil, nil, err
+	}
+
+	batch := newWriteBatch(ctx)
+	payment, err := treasuryPayment(batch, id)
+	if err != nil {
+		return nil, nil, err
+	}
+	if payment.Status != TreasuryPaymentPending {
+		return nil, nil, newError(CodeInvalidArgument, "treasury payment %s is %s", id, payment.Status)
+	}
+	owner, err := orgTreasury(batch, payment.Treasury)
+	if err != nil {
+		return nil, nil, err
+	}
+	stub := ctx.GetStub()
+	if creatorMSPID(stub) != owner.MSPID {
+		return nil, nil, newError(CodeUnauthorized, "only an admin of %s can %s payments from %s", owner.MSPID, action, payment.Treasury)
+	}
+
+	timestamp, err := txTimestamp(stub)
+	if err != nil {
+		return nil, nil, err
+	}
+	payment.Approver = creatorID(stub)
+	payment.DecidedAt = timestamp
+	payment.DecisionTxID = stub.GetTxID()
+
+	return batch, payment, nil
+}
+
+// payFromTreasury executes payment
+func payFromTreasury(batch *writeBatch, payment *TreasuryPayment) error {
+	batch.treasury = payment.Treasury
+	defer func() { batch.treasury = "" }()
+
+	if _, err := transferHelper(batch, payment.Treasury, payment.To, payment.Value, false); err != nil {
+		return fmt.Errorf("failed to transfer: %w", err)
+	}
+	payment.Status = TreasuryPaymentExecuted
+
+	return nil
+}
+
+// putTreasuryPayment stores payment and flushes the batch
+func putTreasuryPayment(batch *writeBatch, payment *TreasuryPayment) (*TreasuryPayment, error) {
+	key, err := batch.ctx.GetStub().CreateCompositeKey(treasuryPaymentObjectType, []string{payment.ID})
+	if err != nil {
+		return nil, err
+	}
+	err = batch.putState(key, payment)
+	if err != nil {
+		return nil, err
+	}
+
+	err = batch.flush()
+	if err != nil {
+		return nil, err
+	}
+
+	return payment, nil
+}
+
+// checkTreasury fails if from is a treasury the batch does not pay from
+func checkTreasury(batch *writeBatch, from string) error {
+	if from == batch.treasury {
+		return nil
+	}
+	_, found, err := treasury(batch, from)
+	if err == nil && found != nil {
+		err = newError(CodeInvalidArgument, "account %s is a treasury, pay from it with ProposeTreasuryPayment", from)
+	}
+
+	return err
+}
+
+// treasury reads the treasury record of account, nil if it is not one,
+// and returns its key
+func treasury(batch *writeBatch, account string) (string, *Treasury, error) {
+	key, err := batch.ctx.GetStub().CreateCompositeKey(treasuryObjectType, []string{account})
+	if err != nil {
+		return "", nil, err
+	}
+	data, err := batch.getState(key)
+	if err != nil || data == nil {
+		return key, nil, err
+	}
+
+	var found Treasury
+	err = decodeRecord(key, data, &found, "account", "mspId", "threshold")
+	if err != nil {
+		return "", nil, err
+	}
+
+	return key, &found, nil
+}
+
+// orgTreasury is treasury failing if account is not one
+func orgTreasury(batch *writeBatch, account string) (*Treasury, error) {
+	_, found, err := treasury(batch, account)
+	if err == nil && found == nil {
+		err = newError(CodeInvalidArgument, "account %s is not a treasury", account)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return found, nil
+}
+
+// treasuryPayment reads the treasury payment proposed by transaction id
+func treasuryPayment(batch *writeBatch, id string) (*TreasuryPayment, error) {
+	key, err := batch.ctx.GetStub().CreateCompositeKey(treasuryPaymentObjectType, []string{id})
+	if err != nil {
+		return nil, err
+	}
+	data, err := batch.getState(key)
+	if err != nil {
+		return nil, err
+	}
+	if data == nil {
+		return nil, newError(CodeTransactionNotFound, "no treasury payment %s was proposed", id)
+	}
+
+	var payment TreasuryPayment
+	err = decodeRecord(key, data, &payment, "id", "treasury", "to", "value", "status")
+	if err != nil {
+		return nil, err
+	}
+
+	return &payment, nil
+}
@@ -0,0 +1,60 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package chaincode_test
+
+import (
+	"testing"
+
+	"github.com/kkiu1756/my_fabric/src/chaincode-go/chaincode"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTreasury(t *testing.T) {
+	l := adminLedger(t).
+		WithAccount("org1-treasury", "org", 1000).
+		WithAccount("vendor", "user", 0)
+	contract := &chaincode.SmartContract{}
+
+	_, err := contract.SetTreasury(l.Context, "org1-treasury", 100)
+	require.NoError(t, err)
+	_, err = contract.TransferFrom(l.Context, "org1-treasury", "vendor", 10)
+	assert.EqualError(t, err, "failed to transfer: [INVALID_ARGUMENT] account org1-treasury is a treasury, pay from it with ProposeTreasuryPayment")
+
+	l.WithCaller("Org1MSP", "clerk", "client").WithTxID("small")
+	payment, err := contract.ProposeTreasuryPayment(l.Context, "org1-treasury", "vendor", 100, "stationery")
+	require.NoError(t, err)
+	assert.Equal(t, chaincode.TreasuryPaymentExecuted, payment.Status)
+	l.AssertBalance("vendor", 100)
+
+	l.WithTxID("large")
+	payment, err = contract.ProposeTreasuryPayment(l.Context, "org1-treasury", "vendor", 500, "servers")
+	require.NoError(t, err)
+	assert.Equal(t, chaincode.TreasuryPaymentPending, payment.Status)
+	l.AssertBalance("vendor", 100)
+	_, err = contract.ApproveTreasuryPayment(l.Context, "large")
+	assert.EqualError(t, err, "[UNAUTHORIZED] only an organization admin can approve treasury payments")
+
+	l.WithCaller("Org2MSP", "Admin@org2.example.com", chaincode.AdminOU)
+	_, err = contract.ApproveTreasuryPayment(l.Context, "large")
+	assert.EqualError(t, err, "[UNAUTHORIZED] only an admin of Org1MSP can approve treasury payments")
+	_, err = contract.ProposeTreasuryPayment(l.Context, "org1-treasury", "vendor", 10, "")
+	assert.EqualError(t, err, "[UNAUTHORIZED] only a member of Org1MSP can propose payments from org1-treasury")
+
+	l.WithCaller("Org1MSP", "Admin@org1.example.com", chaincode.AdminOU).WithTxID("approve")
+	payment, err = contract.ApproveTreasuryPayment(l.Context, "large")
+	require.NoError(t, err)
+	assert.Equal(t, chaincode.TreasuryPaymentExecuted, payment.Status)
+	assert.Equal(t, "approve", payment.DecisionTxID)
+	assert.NotEmpty(t, payment.Approver)
+	l.AssertBalance("org1-treasury", 400)
+	l.AssertBalance("vendor", 600)
+	_, err = contract.RejectTreasuryPayment(l.Context, "large")
+	assert.EqualError(t, err, "[INVALID_ARGUMENT] treasury payment large is executed")
+
+	recorded, err := contract.GetTreasuryPayment(l.Context, "large")
+	require.NoError(t, err)
+	assert.Equal(t, payment, recorded)
+}
@@ -0,0 +1,97 @@
+package chaincode
+
+import (
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+	"github.com/kkiu1756/my_fabric/src/chaincode-go/validation"
+)
+
+// The event verbosity of a ledger trades the size of the events of
+// recorded transactions for throughput. Minimal events carry the
+// transaction's ids and amount only: txId, type, from, to, value and the
+// correlation id. Standard events carry the whole transaction and the
+// details of its event, such as the cashback paid or the credit drawn.
+// Verbose events add the balance changes of the transaction, see
+// changes.go. Ledgers are verbose until an admin changes it. Events that
+// do not carry a transaction, such as those of recoveries or delegates,
+// are emitted in full at every level.
+
+const (
+	VerbosityMinimal  = "minimal"
+	VerbosityStandard = "standard"
+	VerbosityVerbose  = "verbose"
+)
+
+// transactionPayload is implemented by *Transaction and by the events
+// embedding it
+type transactionPayload interface {
+	minimalEvent() *minimalEvent
+}
+
+// minimalEvent is the payload of a transaction event at VerbosityMinimal
+type minimalEvent struct {
+	TXID  string          `json:"txId"`
+	Type  TransactionType `json:"type"`
+	From  string          `json:"from"`
+	To    string          `json:"to"`
+	Value int             `json:"value"`
+}
+
+func (t *Transaction) minimalEvent() *minimalEvent {
+	return &minimalEvent{TXID: t.TXID, Type: t.Type, From: t.From, To: t.To, Value: t.Value}
+}
+
+// SetEventVerbosity sets the detail of the events of recorded transactions
+// to minimal, standard or verbose. Only an org admin can call it.
+func (s *SmartContract) SetEventVerbosity(ctx contractapi.TransactionContextInterface, verbosity string) (*Initialization, error) {
+	switch verbosity {
+	case VerbosityMinimal, VerbosityStandard, VerbosityVerbose:
+	default:
+		return nil, validate(&validation.Error{Field: "verbosity", Reason: "must be minimal, standard or verbose"})
+	}
+	if err := requireInitialized(ctx); err != nil {
+		return nil, err
+	}
+	if _, err := requireAdmin(ctx, "change the event verbosity"); err != nil {
+		return nil, err
+	}
+
+	init, err := ledgerConfig(ctx)
+	if err != nil {
+		return nil, err
+	}
+	init.EventVerbosity = verbosity
+
+	err = putState(ctx, InitializationKey, init)
+	if err != nil {
+		return nil, err
+	}
+
+	return init, nil
+}
+
+// eventVerbosity returns the ledger's event verbosity
+func eventVerbosity(ctx contractapi.TransactionContextInterface) (string, error) {
+	init, err := ledgerConfig(ctx)
+	if err != nil {
+		return "", err
+	}
+	if init == nil || init.EventVerbosity == "" {
+		return VerbosityVerbose, nil
+	}
+
+	return init.EventVerbosity, nil
+}
+
+// eventPayload returns payload trimmed to the ledger's event verbosity
+func eventPayload(ctx contractapi.TransactionContextInterface, payload interface{}) (interface{}, error) {
+	transaction, ok := payload.(transactionPayload)
+	if !ok {
+		return payload, nil
+	}
+	verbosity, err := eventVerbosity(ctx)
+	if err != nil || verbosity != VerbosityMinimal {
+		return payload, err
+	}
+
+	return transaction.minimalEvent(), nil
+}
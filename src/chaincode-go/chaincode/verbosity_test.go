@@ -0,0 +1,104 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package chaincode_test
+
+import (
+	"testing"
+
+	"github.com/kkiu1756/my_fabric/src/chaincode-go/chaincode"
+	"github.com/kkiu1756/my_fabric/src/chaincode-go/tokentest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// initializedLedger returns a ledger an admin initialized, which emits
+// typed events
+func initializedLedger(t *testing.T) *tokentest.Ledger {
+	l := adminLedger(t).Uninitialized()
+	_, err := (&chaincode.SmartContract{}).Initialize(l.Context)
+	require.NoError(t, err)
+	return l
+}
+
+// typedLedger returns a ledger emitting typed events with accounts alice
+// and bob
+func typedLedger(t *testing.T) *tokentest.Ledger {
+	return initializedLedger(t).WithAccount("alice", "user", 100).WithAccount("bob", "user", 20)
+}
+
+func TestEventVerbosity(t *testing.T) {
+	contract := &chaincode.SmartContract{}
+
+	l := typedLedger(t)
+	init, err := contract.SetEventVerbosity(l.Context, chaincode.VerbosityMinimal)
+	require.NoError(t, err)
+	assert.Equal(t, chaincode.VerbosityMinimal, init.EventVerbosity)
+	ctx, after := changeContext(l.WithTxID("pay"))
+	_, err = contract.TransferFrom(ctx, "alice", "bob", 30)
+	require.NoError(t, err)
+	require.NoError(t, after(ctx))
+	l.AssertEvent(chaincode.EventTransferred, map[string]interface{}{"txId": "pay", "type": "transfer", "from": "alice", "to": "bob", "value": 30})
+
+	l = typedLedger(t)
+	_, err = contract.SetEventVerbosity(l.Context, chaincode.VerbosityStandard)
+	require.NoError(t, err)
+	ctx, after = changeContext(l.WithTxID("pay"))
+	_, err = contract.TransferFrom(ctx, "alice", "bob", 30)
+	require.NoError(t, err)
+	require.NoError(t, after(ctx))
+	l.AssertEvent(chaincode.EventTransferred, l.Transaction("pay"))
+
+	events := l.Stub.SetEventCallCount()
+	ctx, after = changeContext(l.WithTxID("delete"))
+	require.NoError(t, contract.DeleteUser(ctx, "bob"))
+	require.NoError(t, after(ctx))
+	assert.Equal(t, events, l.Stub.SetEventCallCount(), "should not emit BalanceChanged below verbose")
+
+	l = typedLedger(t)
+	_, err = contract.SetEventVerbosity(l.Context, chaincode.VerbosityVerbose)
+	require.NoError(t, err)
+	ctx, after = changeContext(l.WithTxID("pay"))
+	_, err = contract.TransferFrom(ctx, "alice", "bob", 30)
+	require.NoError(t, err)
+	require.NoError(t, after(ctx))
+	transfer := l.Transaction("pay")
+	l.AssertEvent(chaincode.EventTransferred, map[string]interface{}{
+		"txId": "pay", "type": "transfer", "from": "alice", "to": "bob", "value": 30,
+		"txTimestamp": transfer.Timestamp, "channelId": "", "initiatorMspId": "Org1MSP", "initiator": transfer.Initiator,
+		"balanceChanges": []map[string]interface{}{
+			{"account": "alice", "before": 100, "after": 70},
+			{"account": "bob", "before": 20, "after": 50},
+		},
+	})
+}
+
+func TestEventVerbosityKeepsOtherEvents(t *testing.T) {
+	l := typedLedger(t)
+	contract := &chaincode.SmartContract{}
+	_, err := contract.SetEventVerbosity(l.Context, chaincode.VerbosityMinimal)
+	require.NoError(t, err)
+
+	_, err = contract.SetLargeTransferThreshold(l.Context, 10)
+	require.NoError(t, err)
+	_, err = contract.TransferFrom(l.WithTxID("large").Context, "alice", "bob", 30)
+	require.NoError(t, err)
+	l.AssertEvent(chaincode.EventLargeTransfer, map[string]interface{}{"txId": "large", "type": "transfer", "from": "alice", "to": "bob", "value": 30})
+
+	l = tokentest.NewLedger(t).WithAccount("alice", "user", 100).WithAccount("bob", "user", 20)
+	_, err = contract.TransferFrom(l.Context, "alice", "bob", 30)
+	require.NoError(t, err)
+	l.AssertEvent(chaincode.EventTransfer, map[string]interface{}{"from": "alice", "to": "bob", "value": 30})
+}
+
+func TestSetEventVerbosity(t *testing.T) {
+	contract := &chaincode.SmartContract{}
+
+	_, err := contract.SetEventVerbosity(adminLedger(t).Context, "loud")
+	assert.EqualError(t, err, "[INVALID_ARGUMENT] invalid verbosity: must be minimal, standard or verbose")
+	_, err = contract.SetEventVerbosity(tokentest.NewLedger(t).WithCaller("Org1MSP", "User1@org1.example.com", "client").Context, chaincode.VerbosityMinimal)
+	assert.EqualError(t, err, "[UNAUTHORIZED] only an organization admin can change the event verbosity")
+	_, err = contract.SetEventVerbosity(adminLedger(t).Uninitialized().Context, chaincode.VerbosityMinimal)
+	assert.EqualError(t, err, "[NOT_INITIALIZED] contract not initialized, an organization admin must call Initialize")
+}
@@ -0,0 +1,112 @@
+package chaincode
+
+import (
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// ContractVersion is the semantic version of this chaincode. Bump it with
+// every release, so PostUpgrade runs once per upgrade.
+const ContractVersion = "1.0.0"
+
+// EventContractUpgraded is emitted by PostUpgrade with an UpgradeResult
+const EventContractUpgraded = "ContractUpgraded"
+
+// VersionInfo identifies the deployed contract and the ledger it runs on
+type VersionInfo struct {
+	// Version is the ContractVersion of the deployed chaincode
+	Version string `json:"version"`
+	// Schema is the record schema version the chaincode writes
+	Schema int `json:"schema"`
+	// LedgerVersion is the Initialization version, see there
+	LedgerVersion string `json:"ledgerVersion"`
+	// LedgerSchema is the schema version of the stored records, 0 before
+	// Initialize
+	LedgerSchema int `json:"ledgerSchema"`
+}
+
+// UpgradeResult reports what PostUpgrade did
+type UpgradeResult struct {
+	// Version is the ContractVersion now recorded
+	Version string `json:"version"`
+	// Previous is the version recorded before, empty if there was none
+	Previous string `json:"previous"`
+	// Schema is the schema version of the stored records after the upgrade
+	Schema int `json:"schema"`
+	// Migrated counts the records rewritten by schema migrations
+	Migrated int `json:"migrated"`
+	// Bookmark is not empty if the schema migration did not fit in the
+	// transaction. It continues with MigrateState from Schema.
+	Bookmark string `json:"bookmark"`
+}
+
+// Version returns the deployed contract and ledger versions
+func (s *SmartContract) Version(ctx contractapi.TransactionContextInterface) (*VersionInfo, error) {
+	info := &VersionInfo{Version: ContractVersion, Schema: CurrentSchema}
+
+	init, err := ledgerConfig(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if init != nil {
+		info.LedgerVersion = init.Version
+		info.LedgerSchema = schemaVersion(init)
+	}
+
+	return info, nil
+}
+
+// PostUpgrade completes a chaincode upgrade. An org admin submits it once
+// after `peer lifecycle chaincode commit` of a new version: it runs the
+// schema migrations up to CurrentSchema, a page of the default page size
+// each, records ContractVersion and emits a ContractUpgraded event. On a
+// ledger upgraded from before typed events it opens the legacy event
+// window, see events.go. A
+// migration that does not fit in one page is left for MigrateState to
+// finish from the returned bookmark. It fails with ALREADY_INITIALIZED if
+// the ledger is already at this version.
+func (s *SmartContract) PostUpgrade(ctx contractapi.TransactionContextInterface) (*UpgradeResult, error) {
+	if err := requireInitialized(ctx); err != nil {
+		return nil, err
+	}
+	if _, err := requireAdmin(ctx, "upgrade the contract"); err != nil {
+		return nil, err
+	}
+
+	init, err := ledgerConfig(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if init.Version == ContractVersion {
+		return nil, newError(CodeAlreadyInitialized, "contract already upgraded to version %s", ContractVersion)
+	}
+
+	result := &UpgradeResult{Version: ContractVersion, Previous: init.Version}
+	for result.Bookmark == "" && schemaVersion(init) < CurrentSchema {
+		page, err := migrateSchema(ctx, init, 0, "")
+		if err != nil {
+			return nil, err
+		}
+		result.Migrated += page.Migrated
+		result.Bookmark = page.Bookmark
+	}
+	result.Schema = schemaVersion(init)
+
+	if init.Version == "" {
+		err = openLegacyEventWindow(ctx, init)
+		if err != nil {
+			return nil, err
+		}
+	}
+	init.Version = ContractVersion
+	err = putState(ctx, InitializationKey, init)
+	if err != nil {
+		return nil, err
+	}
+
+	err = emitEvent(ctx, EventContractUpgraded, result)
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
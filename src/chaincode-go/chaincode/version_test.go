@@ -0,0 +1,110 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package chaincode_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+	"github.com/kkiu1756/my_fabric/src/chaincode-go/chaincode"
+	"github.com/kkiu1756/my_fabric/src/chaincode-go/tokentest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// #########
+// HELPERS
+// #########
+
+var upgradeTime = time.Date(2021, 3, 1, 12, 0, 0, 0, time.UTC)
+
+// upgradableLedger returns an admin ledger initialized before versions were
+// recorded, holding a SchemaV1 transfer record
+func upgradableLedger(t *testing.T) *tokentest.Ledger {
+	l := adminLedger(t).WithAccount("alice", "user", 100).WithAccount("bob", "user", 20).WithTimestamp(upgradeTime)
+	l.State["pay"] = []byte(`{"txId":"pay","from":"alice","to":"bob","value":30}`)
+
+	paginate(l.Stub, l.State)
+	l.Stub.GetStateByRangeStub = func(start string, end string) (shim.StateQueryIteratorInterface, error) {
+		it, _, err := l.Stub.GetStateByRangeWithPaginationStub(start, "", 1000, "")
+		return it, err
+	}
+
+	return l
+}
+
+// #########
+// TESTS
+// #########
+
+func TestVersion(t *testing.T) {
+	contract := &chaincode.SmartContract{}
+
+	info, err := contract.Version(tokentest.NewLedger(t).Uninitialized().Context)
+	require.NoError(t, err)
+	assert.Equal(t, &chaincode.VersionInfo{Version: chaincode.ContractVersion, Schema: chaincode.CurrentSchema}, info)
+
+	info, err = contract.Version(tokentest.NewLedger(t).Context)
+	require.NoError(t, err)
+	assert.Equal(t, &chaincode.VersionInfo{Version: chaincode.ContractVersion, Schema: chaincode.CurrentSchema, LedgerSchema: chaincode.SchemaV1}, info)
+
+	l := adminLedger(t).Uninitialized()
+	_, err = contract.Initialize(l.Context)
+	require.NoError(t, err)
+	info, err = contract.Version(l.Context)
+	require.NoError(t, err)
+	assert.Equal(t, chaincode.ContractVersion, info.LedgerVersion)
+	assert.Equal(t, chaincode.CurrentSchema, info.LedgerSchema)
+}
+
+func TestPostUpgrade(t *testing.T) {
+	l := upgradableLedger(t)
+	contract := &chaincode.SmartContract{}
+
+	result, err := contract.PostUpgrade(l.Context)
+	require.NoError(t, err)
+	expected := &chaincode.UpgradeResult{Version: chaincode.ContractVersion, Schema: chaincode.CurrentSchema, Migrated: 1}
+	assert.Equal(t, expected, result)
+	l.AssertEvent(chaincode.EventContractUpgraded, expected)
+	assert.Equal(t, chaincode.TransactionTransfer, l.Transaction("pay").Type)
+	l.AssertBalance("alice", 100)
+
+	info, err := contract.Version(l.Context)
+	require.NoError(t, err)
+	assert.Equal(t, &chaincode.VersionInfo{
+		Version:       chaincode.ContractVersion,
+		Schema:        chaincode.CurrentSchema,
+		LedgerVersion: chaincode.ContractVersion,
+		LedgerSchema:  chaincode.CurrentSchema,
+	}, info)
+
+	_, err = contract.PostUpgrade(l.Context)
+	assert.EqualError(t, err, "[ALREADY_INITIALIZED] contract already upgraded to version "+chaincode.ContractVersion)
+}
+
+func TestPostUpgradeLeavesLongMigration(t *testing.T) {
+	l := upgradableLedger(t)
+	l.State["pay2"] = []byte(`{"txId":"pay2","from":"bob","to":"alice","value":5}`)
+	setQueryPolicy(t, chaincode.QueryPolicy{DefaultPageSize: 2, MaxPageSize: 2, Budget: chaincode.DefaultQueryPolicy.Budget})
+
+	result, err := (&chaincode.SmartContract{}).PostUpgrade(l.Context)
+	require.NoError(t, err)
+	assert.Equal(t, chaincode.SchemaV1, result.Schema, "the schema should change with the last step only")
+	assert.Equal(t, "pay", result.Bookmark)
+	assert.Zero(t, result.Migrated, "alice and bob are current")
+
+	page, err := (&chaincode.SmartContract{}).MigrateState(l.Context, chaincode.SchemaV1, chaincode.SchemaV2, 0, result.Bookmark)
+	require.NoError(t, err)
+	assert.Equal(t, &chaincode.MigrationPage{Migrated: 2}, page)
+}
+
+func TestPostUpgradeRequiresAdmin(t *testing.T) {
+	l := tokentest.NewLedger(t).WithCaller("Org1MSP", "User1@org1.example.com", "client")
+
+	_, err := (&chaincode.SmartContract{}).PostUpgrade(l.Context)
+	assert.EqualError(t, err, "[UNAUTHORIZED] only an organization admin can upgrade the contract")
+	l.AssertNoEvent()
+}
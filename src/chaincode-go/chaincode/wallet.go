@@ -0,0 +1,265 @@
+package chaincode
+
+import (
+	"fmt"
+
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+	"github.com/hyperledger/fabric-protos-go/ledger/queryresult"
+	"github.com/hyperledger/fabric-protos-go/peer"
+	"github.com/kkiu1756/my_fabric/src/chaincode-go/validation"
+)
+
+// A client identity opens named wallets, such as "operating" and
+// "savings", with CreateWallet. Each wallet is an account of its own, with
+// the id "<common name>.<wallet>" and the identity as its holder, so it has
+// its own balance, limits, statement and audit trail, and every account
+// method takes it. TransferBetweenWallets moves tokens between the
+// caller's wallets, and GetWallet and ListWallets read them by name.
+
+// walletObjectType keys the wallets by owner and name
+const walletObjectType = "wallet"
+
+// Wallet is a named account of a client identity
+type Wallet struct {
+	Owner Identity `json:"owner"`
+	Name  string   `json:"name"`
+	// Account is the id of the wallet's account
+	Account string `json:"account"`
+	// CreatedAt is the proposal timestamp of CreateWallet, RFC 3339
+	CreatedAt string `json:"createdAt"`
+}
+
+// WalletBalance is a wallet with the balance of its account, as GetUser
+// reports it
+type WalletBalance struct {
+	*Wallet
+	Balance int `json:"balance"`
+}
+
+// WalletPage is one page of the caller's wallets
+type WalletPage struct {
+	Wallets []*WalletBalance `json:"wallets"`
+	// Bookmark continues the listing, empty after the last page
+	Bookmark string `json:"bookmark"`
+}
+
+// CreateWallet opens the wallet name of the caller, an account of
+// walletType
+func (s *SmartContract) CreateWallet(ctx contractapi.TransactionContextInterface, name string, walletType string) (*Wallet, error) {
+	err := validate(
+		validation.ID("name", name),
+		validation.ID("walletType", walletType),
+	)
+	if err != nil {
+		return nil, err
+	}
+	if err := requireInitialized(ctx); err != nil {
+		return nil, err
+	}
+	owner, err := callerIdentity(ctx)
+	if err != nil {
+		return nil, err
+	}
+	account := owner.CommonName + "." + name
+	if err := validate(validation.AccountID("account", account)); err != nil {
+		return nil, err
+	}
+
+	batch := newWriteBatch(ctx)
+	key, existing, err := ownedWallet(batch, owner, name)
+	if err != nil {
+		return nil, err
+	}
+	if existing != nil {
+		return nil, newError(CodeAccountExists, "wallet %s exists", name)
+	}
+	idKey, err := accountKey(ctx, account)
+	if err != nil {
+		return nil, err
+	}
+	if record, err := batch.getState(idKey); err != nil || record != nil {
+		if err == nil {
+			err = newError(CodeAccountExists, "user %s exist", account)
+		}
+		return nil, err
+	}
+	if _, alias, err := accountAlias(batch, account); err != nil || alias != nil {
+		if err == nil {
+			err = newError(CodeAccountExists, "%s is an alias of %s", account, alias.Account)
+		}
+		return nil, err
+	}
+
+	err = batch.putUser(&User{ID: account, Type: walletType})
+	if err != nil {
+		return nil, err
+	}
+	err = appendAudit(batch, account, AuditCreate, 0, 0, fmt.Sprintf("wallet %s of %s", name, owner.CommonName))
+	if err != nil {
+		return nil, err
+	}
+	holderKey, err := ctx.GetStub().CreateCompositeKey(holderObjectType, []string{account})
+	if err != nil {
+		return nil, err
+	}
+	err = batch.putState(holderKey, owner)
+	if err != nil {
+		return nil, err
+	}
+
+	timestamp, err := txTimestamp(ctx.GetStub())
+	if err != nil {
+		return nil, err
+	}
+	wallet := &Wallet{Owner: owner, Name: name, Account: account, CreatedAt: timestamp}
+	err = batch.putState(key, wallet)
+	if err != nil {
+		return nil, err
+	}
+
+	err = batch.flush()
+	if err != nil {
+		return nil, err
+	}
+
+	return wallet, nil
+}
+
+// TransferBetweenWallets transfers value from the caller's wallet
+// fromWallet to its wallet toWallet. The limits of fromWallet apply.
+func (s *SmartContract) TransferBetweenWallets(ctx contractapi.TransactionContextInterface, fromWallet string, toWallet string, value int) (*Transaction, error) {
+	err := validate(
+		validation.ID("fromWallet", fromWallet),
+		validation.ID("toWallet", toWallet),
+		validation.Amount("value", value),
+	)
+	if err != nil {
+		return nil, err
+	}
+	if err := requireInitialized(ctx); err != nil {
+		return nil, err
+	}
+	owner, err := callerIdentity(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	batch := newWriteBatch(ctx)
+	accounts := make([]string, 2)
+	for i, name := range []string{fromWallet, toWallet} {
+		wallet, err := callerWallet(batch, owner, name)
+		if err != nil {
+			return nil, err
+		}
+		accounts[i] = wallet.Account
+	}
+	transaction, err := transferHelper(batch, accounts[0], accounts[1], value, false)
+	if err == nil {
+		err = batch.flush()
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to transfer: %w", err)
+	}
+
+	return transaction, nil
+}
+
+// GetWallet returns the caller's wallet name with its balance
+func (s *SmartContract) GetWallet(ctx contractapi.TransactionContextInterface, name string) (*WalletBalance, error) {
+	if err := validate(validation.ID("name", name)); err != nil {
+		return nil, err
+	}
+	owner, err := callerIdentity(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	wallet, err := callerWallet(newWriteBatch(ctx), owner, name)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.walletBalance(ctx, wallet)
+}
+
+// ListWallets returns a page of the caller's wallets with their balances,
+// by name
+func (s *SmartContract) ListWallets(ctx contractapi.TransactionContextInterface, pageSize int, bookmark string) (*WalletPage, error) {
+	pageSize, err := queryPolicy.pageSize(pageSize)
+	if err != nil {
+		return nil, err
+	}
+	owner, err := callerIdentity(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	stub := ctx.GetStub()
+	query := func(pageSize int32, bookmark string) (shim.StateQueryIteratorInterface, *peer.QueryResponseMetadata, error) {
+		return stub.GetStateByPartialCompositeKeyWithPagination(walletObjectType, []string{owner.MSPID, owner.CommonName}, pageSize, bookmark)
+	}
+
+	page := &WalletPage{Wallets: []*WalletBalance{}}
+	page.Bookmark, err = queryPolicy.scan(query, walletObjectType, pageSize, bookmark, func(kv *queryresult.KV) error {
+		var wallet Wallet
+		if err := decodeRecord(kv.Key, kv.Value, &wallet, "owner", "name", "account"); err != nil {
+			return err
+		}
+		balance, err := s.walletBalance(ctx, &wallet)
+		if err != nil {
+			return err
+		}
+		page.Wallets = append(page.Wallets, balance)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return page, nil
+}
+
+// walletBalance reads the balance of wallet
+func (s *SmartContract) walletBalance(ctx contractapi.TransactionContextInterface, wallet *Wallet) (*WalletBalance, error) {
+	user, err := s.GetUser(ctx, wallet.Account)
+	if err != nil {
+		return nil, err
+	}
+
+	return &WalletBalance{Wallet: wallet, Balance: user.Balance}, nil
+}
+
+// ownedWallet reads the wallet name of owner, nil if there is none, and
+// returns its key
+func ownedWallet(batch *writeBatch, owner Identity, name string) (string, *Wallet, error) {
+	key, err := batch.ctx.GetStub().CreateCompositeKey(walletObjectType, []string{owner.MSPID, owner.CommonName, name})
+	if err != nil {
+		return "", nil, err
+	}
+	data, err := batch.getState(key)
+	if err != nil || data == nil {
+		return key, nil, err
+	}
+
+	var wallet Wallet
+	err = decodeRecord(key, data, &wallet, "owner", "name", "account")
+	if err != nil {
+		return "", nil, err
+	}
+
+	return key, &wallet, nil
+}
+
+// callerWallet is ownedWallet failing if there is none
+func callerWallet(batch *writeBatch, owner Identity, name string) (*Wallet, error) {
+	_, wallet, err := ownedWallet(batch, owner, name)
+	if err == nil && wallet == nil {
+		err = newError(CodeInvalidArgument, "wallet %s does not exist", name)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return wallet, nil
+}
@@ -0,0 +1,60 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package chaincode_test
+
+import (
+	"testing"
+
+	"github.com/kkiu1756/my_fabric/src/chaincode-go/chaincode"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWallets(t *testing.T) {
+	l := adminLedger(t).WithAccount("bob", "user", 0)
+	paginate(l.Stub, l.State)
+	contract := &chaincode.SmartContract{}
+
+	l.WithCaller("Org1MSP", "alice", "client")
+	for _, name := range []string{"operating", "savings"} {
+		_, err := contract.CreateWallet(l.Context, name, "user")
+		require.NoError(t, err)
+	}
+	_, err := contract.CreateWallet(l.Context, "savings", "user")
+	assert.EqualError(t, err, "[ACCOUNT_EXISTS] wallet savings exists")
+
+	l.WithCaller("Org1MSP", "Admin@org1.example.com", chaincode.AdminOU)
+	_, err = contract.SetBalance(l.Context, "alice.operating", 100)
+	require.NoError(t, err)
+	_, err = contract.SetTransferLimit(l.Context, "alice.savings", 10, 0, chaincode.LimitPolicyReject)
+	require.NoError(t, err)
+
+	l.WithCaller("Org1MSP", "alice", "client")
+	_, err = contract.TransferBetweenWallets(l.Context, "operating", "savings", 60)
+	require.NoError(t, err)
+	l.AssertBalance("alice.operating", 40)
+	l.AssertBalance("alice.savings", 60)
+	_, err = contract.TransferBetweenWallets(l.Context, "savings", "operating", 20)
+	assert.EqualError(t, err, "failed to transfer: [LIMIT_EXCEEDED] transfer of 20 from alice.savings exceeds its limit of 10 per transfer")
+	_, err = contract.TransferFrom(l.Context, "alice.savings", "bob", 5)
+	require.NoError(t, err)
+
+	wallet, err := contract.GetWallet(l.Context, "savings")
+	require.NoError(t, err)
+	assert.Equal(t, "alice.savings", wallet.Account)
+	assert.Equal(t, 55, wallet.Balance)
+	page, err := contract.ListWallets(l.Context, 0, "")
+	require.NoError(t, err)
+	require.Len(t, page.Wallets, 2)
+	assert.Equal(t, "operating", page.Wallets[0].Name)
+	assert.Equal(t, 40, page.Wallets[0].Balance)
+
+	l.WithCaller("Org1MSP", "bob", "client")
+	_, err = contract.TransferBetweenWallets(l.Context, "operating", "savings", 10)
+	assert.EqualError(t, err, "[INVALID_ARGUMENT] wallet operating does not exist")
+	page, err = contract.ListWallets(l.Context, 0, "")
+	require.NoError(t, err)
+	assert.Empty(t, page.Wallets)
+}
@@ -0,0 +1,129 @@
+package chaincode
+
+import (
+	"crypto/sha256"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+	"github.com/kkiu1756/my_fabric/src/chaincode-go/validation"
+)
+
+// An org admin publishes a named whitelist, such as the accounts eligible
+// for a sale, as the merkle root of its accounts, see merkle.go. A gate
+// checks an account against it with the proof the caller supplies, so the
+// whitelist takes a single state entry however long it is. A leaf is
+// SHA-256(0x00 || account).
+
+// whitelistObjectType keys the whitelists by name
+const whitelistObjectType = "whitelist"
+
+// Whitelist is a published whitelist
+type Whitelist struct {
+	Name string `json:"name"`
+	// Root is the hex encoded merkle root of the accounts
+	Root string `json:"root"`
+	// PublishedAt is the proposal timestamp of the publication, RFC 3339
+	PublishedAt string `json:"publishedAt"`
+}
+
+// PublishWhitelist publishes whitelist name with the accounts of merkle
+// root, hex encoded, replacing any whitelist of that name. An empty root
+// removes the whitelist. Only an org admin can call it.
+func (s *SmartContract) PublishWhitelist(ctx contractapi.TransactionContextInterface, name string, root string) error {
+	errs := []error{validation.ID("name", name)}
+	if root != "" {
+		errs = append(errs, digestError("root", root))
+	}
+	if err := validate(errs...); err != nil {
+		return err
+	}
+	if err := requireInitialized(ctx); err != nil {
+		return err
+	}
+	if _, err := requireAdmin(ctx, "publish a whitelist"); err != nil {
+		return err
+	}
+
+	batch := newWriteBatch(ctx)
+	key, err := ctx.GetStub().CreateCompositeKey(whitelistObjectType, []string{name})
+	if err != nil {
+		return err
+	}
+	if root == "" {
+		batch.delState(key)
+	} else {
+		timestamp, err := txTimestamp(ctx.GetStub())
+		if err != nil {
+			return err
+		}
+		err = batch.putState(key, Whitelist{Name: name, Root: root, PublishedAt: timestamp})
+		if err != nil {
+			return err
+		}
+	}
+
+	return batch.flush()
+}
+
+// GetWhitelist returns whitelist name
+func (s *SmartContract) GetWhitelist(ctx contractapi.TransactionContextInterface, name string) (*Whitelist, error) {
+	if err := validate(validation.ID("name", name)); err != nil {
+		return nil, err
+	}
+
+	return whitelist(newWriteBatch(ctx), name)
+}
+
+// VerifyWhitelist reports whether proof, a JSON array of the hex encoded
+// sibling hashes from account's leaf to the root, shows account is on
+// whitelist name. It is the gate check of whitelisted flows.
+func (s *SmartContract) VerifyWhitelist(ctx contractapi.TransactionContextInterface, name string, account string, proof string) (bool, error) {
+	err := validate(
+		validation.ID("name", name),
+		validation.ID("account", account),
+	)
+	if err != nil {
+		return false, err
+	}
+	siblings, err := decodeMerkleProof(proof)
+	if err != nil {
+		return false, err
+	}
+
+	list, err := whitelist(newWriteBatch(ctx), name)
+	if err != nil {
+		return false, err
+	}
+
+	return merkleRoot(WhitelistLeaf(account), siblings) == list.Root, nil
+}
+
+// WhitelistLeaf returns the merkle leaf of account on a whitelist
+func WhitelistLeaf(account string) []byte {
+	h := sha256.New()
+	h.Write([]byte{0})
+	h.Write([]byte(account))
+	return h.Sum(nil)
+}
+
+// whitelist reads whitelist name
+func whitelist(batch *writeBatch, name string) (*Whitelist, error) {
+	key, err := batch.ctx.GetStub().CreateCompositeKey(whitelistObjectType, []string{name})
+	if err != nil {
+		return nil, err
+	}
+	data, err := batch.getState(key)
+	if err != nil {
+		return nil, err
+	}
+	if data == nil {
+		return nil, newError(CodeInvalidArgument, "no whitelist %s", name)
+	}
+
+	var list Whitelist
+	err = decodeRecord(key, data, &list, "name", "root")
+	if err != nil {
+		return nil, err
+	}
+
+	return &list, nil
+}
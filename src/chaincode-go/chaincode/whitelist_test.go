@@ -0,0 +1,59 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package chaincode_test
+
+import (
+	"encoding/hex"
+	"testing"
+
+	"github.com/kkiu1756/my_fabric/src/chaincode-go/chaincode"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVerifyWhitelist(t *testing.T) {
+	l := adminLedger(t)
+	contract := &chaincode.SmartContract{}
+
+	alice := chaincode.WhitelistLeaf("alice")
+	bob := chaincode.WhitelistLeaf("bob")
+	carol := chaincode.WhitelistLeaf("carol")
+	pair := chaincode.MerkleNode(alice, bob)
+	root := hex.EncodeToString(chaincode.MerkleNode(pair, carol))
+	require.NoError(t, contract.PublishWhitelist(l.Context, "sale", root))
+
+	list, err := contract.GetWhitelist(l.Context, "sale")
+	require.NoError(t, err)
+	assert.Equal(t, root, list.Root)
+
+	tests := []struct {
+		account string
+		proof   string
+		ok      bool
+	}{
+		{"alice", proofJSON(t, bob, carol), true},
+		{"carol", proofJSON(t, pair), true},
+		{"bob", proofJSON(t, bob, carol), false},
+		{"dave", proofJSON(t, pair), false},
+	}
+	for _, tt := range tests {
+		ok, err := contract.VerifyWhitelist(l.Context, "sale", tt.account, tt.proof)
+		require.NoError(t, err)
+		assert.Equal(t, tt.ok, ok, tt.account)
+	}
+
+	_, err = contract.VerifyWhitelist(l.Context, "sale", "alice", "[]")
+	require.NoError(t, err)
+	_, err = contract.VerifyWhitelist(l.Context, "sale", "alice", "not json")
+	assert.EqualError(t, err, "[INVALID_ARGUMENT] invalid proof: invalid character 'o' in literal null (expecting 'u')")
+
+	require.NoError(t, contract.PublishWhitelist(l.Context, "sale", ""))
+	_, err = contract.VerifyWhitelist(l.Context, "sale", "alice", proofJSON(t, bob, carol))
+	assert.EqualError(t, err, "[INVALID_ARGUMENT] no whitelist sale")
+
+	l.WithCaller("Org1MSP", "alice", "client")
+	err = contract.PublishWhitelist(l.Context, "sale", root)
+	assert.EqualError(t, err, "[UNAUTHORIZED] only an organization admin can publish a whitelist")
+}
@@ -0,0 +1,61 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+*/
+
+// Command clientgen writes a typed Go client of the token contract, and
+// optionally a TypeScript one, derived from the same metadata peers serve,
+// so client code follows the contract's transactions
+package main
+
+import (
+	"flag"
+	"io/ioutil"
+	"log"
+	"os"
+
+	"github.com/kkiu1756/my_fabric/src/chaincode-go/chaincode"
+	"github.com/kkiu1756/my_fabric/src/chaincode-go/tools/clientgen"
+	"github.com/kkiu1756/my_fabric/src/chaincode-go/tools/openapi"
+)
+
+func main() {
+	output := flag.String("o", "", "file to write the Go client to (default stdout)")
+	pkg := flag.String("package", "contract", "package name of the Go client")
+	tsOutput := flag.String("ts", "", "file to write a TypeScript client to (default none)")
+	srcDir := flag.String("src", "", "contract package directory to take parameter names and docs from (default none)")
+	flag.Parse()
+
+	md, err := openapi.GetMetadata(&chaincode.SmartContract{})
+	if err != nil {
+		log.Fatalf("Error reading contract metadata: %v", err)
+	}
+
+	var src *clientgen.Source
+	if *srcDir != "" {
+		src, err = clientgen.ParseSource(*srcDir, "SmartContract")
+		if err != nil {
+			log.Fatalf("Error reading contract source: %v", err)
+		}
+	}
+
+	client, err := clientgen.Go(md, *pkg, src)
+	if err != nil {
+		log.Fatalf("Error generating Go client: %v", err)
+	}
+	if *output == "" {
+		os.Stdout.Write(client)
+	} else if err := ioutil.WriteFile(*output, client, 0644); err != nil {
+		log.Fatalf("Error writing %s: %v", *output, err)
+	}
+
+	if *tsOutput == "" {
+		return
+	}
+	client, err = clientgen.TypeScript(md, src)
+	if err != nil {
+		log.Fatalf("Error generating TypeScript client: %v", err)
+	}
+	if err := ioutil.WriteFile(*tsOutput, client, 0644); err != nil {
+		log.Fatalf("Error writing %s: %v", *tsOutput, err)
+	}
+}
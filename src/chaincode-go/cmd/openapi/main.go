@@ -0,0 +1,56 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+*/
+
+// Command openapi writes the OpenAPI document for the token contract, derived
+// from the same metadata peers serve, for the REST gateway to load
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"io/ioutil"
+	"log"
+	"os"
+
+	"github.com/kkiu1756/my_fabric/src/chaincode-go/chaincode"
+	"github.com/kkiu1756/my_fabric/src/chaincode-go/tools/clientgen"
+	"github.com/kkiu1756/my_fabric/src/chaincode-go/tools/openapi"
+)
+
+func main() {
+	output := flag.String("o", "", "file to write the document to (default stdout)")
+	title := flag.String("title", "Token Chaincode API", "API title")
+	version := flag.String("version", "0.1.0", "API version")
+	srcDir := flag.String("src", "", "contract package directory to take parameter names from (default none)")
+	flag.Parse()
+
+	md, err := openapi.GetMetadata(&chaincode.SmartContract{})
+	if err != nil {
+		log.Fatalf("Error reading contract metadata: %v", err)
+	}
+
+	var params map[string][]string
+	if *srcDir != "" {
+		src, err := clientgen.ParseSource(*srcDir, "SmartContract")
+		if err != nil {
+			log.Fatalf("Error reading contract source: %v", err)
+		}
+		params = src.Params
+	}
+
+	doc, err := json.MarshalIndent(openapi.Generate(md, *title, *version, params), "", "  ")
+	if err != nil {
+		log.Fatalf("Error encoding OpenAPI document: %v", err)
+	}
+	doc = append(doc, '\n')
+
+	if *output == "" {
+		os.Stdout.Write(doc)
+		return
+	}
+
+	if err := ioutil.WriteFile(*output, doc, 0644); err != nil {
+		log.Fatalf("Error writing %s: %v", *output, err)
+	}
+}
@@ -0,0 +1,91 @@
+// Package iterate walks the results of world state queries, so a query
+// hands its results to a visit function one at a time instead of each
+// caller repeating the open, advance and close steps of a shim iterator.
+package iterate
+
+import (
+	"errors"
+
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+	"github.com/hyperledger/fabric-protos-go/ledger/queryresult"
+	"github.com/hyperledger/fabric-protos-go/peer"
+)
+
+// Stop ends a walk early when a visit function returns it. The walk then
+// returns nil.
+var Stop = errors.New("stop iteration")
+
+// Error reports a query the peer failed to open or advance, as opposed to
+// an error returned by a visit function
+type Error struct {
+	Err error
+}
+
+func (e *Error) Error() string {
+	return e.Err.Error()
+}
+
+// Unwrap returns the error of the peer
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// Visit is called with each result of a query, in key order
+type Visit func(kv *queryresult.KV) error
+
+// All calls visit for each result of iterator and closes it. It stops at
+// the first error, returning visit's errors as they are and wrapping the
+// iterator's in an *Error.
+func All(iterator shim.StateQueryIteratorInterface, visit Visit) error {
+	defer iterator.Close()
+
+	for iterator.HasNext() {
+		kv, err := iterator.Next()
+		if err != nil {
+			return &Error{Err: err}
+		}
+		err = visit(kv)
+		if err == Stop {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// PartialCompositeKey calls visit for each state whose composite key
+// starts with objectType and attributes
+func PartialCompositeKey(stub shim.ChaincodeStubInterface, objectType string, attributes []string, visit Visit) error {
+	iterator, err := stub.GetStateByPartialCompositeKey(objectType, attributes)
+	if err != nil {
+		return &Error{Err: err}
+	}
+
+	return All(iterator, visit)
+}
+
+// Query opens one page of a paginated query
+type Query func() (shim.StateQueryIteratorInterface, *peer.QueryResponseMetadata, error)
+
+// Page runs query and calls visit for each result of the page, returning
+// the page's metadata
+func Page(query Query, visit Visit) (*peer.QueryResponseMetadata, error) {
+	iterator, metadata, err := query()
+	if err != nil {
+		return nil, &Error{Err: err}
+	}
+
+	return metadata, All(iterator, visit)
+}
+
+// PartialCompositeKeyPage calls visit for each result of the page of up to
+// pageSize states from bookmark whose composite key starts with objectType
+// and attributes, and returns the page's metadata
+func PartialCompositeKeyPage(stub shim.ChaincodeStubInterface, objectType string, attributes []string, pageSize int32, bookmark string, visit Visit) (*peer.QueryResponseMetadata, error) {
+	return Page(func() (shim.StateQueryIteratorInterface, *peer.QueryResponseMetadata, error) {
+		return stub.GetStateByPartialCompositeKeyWithPagination(objectType, attributes, pageSize, bookmark)
+	}, visit)
+}
@@ -0,0 +1,155 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package iterate_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+	"github.com/hyperledger/fabric-protos-go/ledger/queryresult"
+	"github.com/hyperledger/fabric-protos-go/peer"
+	"github.com/kkiu1756/my_fabric/src/chaincode-go/chaincode/mocks"
+	"github.com/kkiu1756/my_fabric/src/chaincode-go/iterate"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// results iterates over keys, failing at failAt if it is not negative
+type results struct {
+	keys   []string
+	next   int
+	failAt int
+	closed bool
+}
+
+func newResults(keys ...string) *results {
+	return &results{keys: keys, failAt: -1}
+}
+
+func (r *results) HasNext() bool {
+	return r.next < len(r.keys)
+}
+
+func (r *results) Next() (*queryresult.KV, error) {
+	if r.next == r.failAt {
+		return nil, errors.New("iterator failed")
+	}
+	kv := &queryresult.KV{Key: r.keys[r.next], Value: []byte(r.keys[r.next])}
+	r.next++
+	return kv, nil
+}
+
+func (r *results) Close() error {
+	r.closed = true
+	return nil
+}
+
+// collect returns a visit function appending the visited keys to keys
+func collect(keys *[]string) iterate.Visit {
+	return func(kv *queryresult.KV) error {
+		*keys = append(*keys, kv.Key)
+		return nil
+	}
+}
+
+func TestAll(t *testing.T) {
+	iterator := newResults("a", "b", "c")
+	var keys []string
+	require.NoError(t, iterate.All(iterator, collect(&keys)))
+	assert.Equal(t, []string{"a", "b", "c"}, keys)
+	assert.True(t, iterator.closed)
+
+	iterator = newResults()
+	keys = nil
+	require.NoError(t, iterate.All(iterator, collect(&keys)))
+	assert.Empty(t, keys)
+	assert.True(t, iterator.closed)
+}
+
+func TestAllStops(t *testing.T) {
+	iterator := newResults("a", "b", "c")
+	var keys []string
+	err := iterate.All(iterator, func(kv *queryresult.KV) error {
+		if kv.Key == "b" {
+			return iterate.Stop
+		}
+		keys = append(keys, kv.Key)
+		return nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"a"}, keys)
+	assert.True(t, iterator.closed)
+}
+
+func TestAllFails(t *testing.T) {
+	iterator := newResults("a", "b", "c")
+	iterator.failAt = 1
+	var keys []string
+	err := iterate.All(iterator, collect(&keys))
+	assert.EqualError(t, err, "iterator failed")
+	var queryErr *iterate.Error
+	assert.True(t, errors.As(err, &queryErr), "iterator failures are wrapped in an *Error")
+	assert.Equal(t, []string{"a"}, keys)
+	assert.True(t, iterator.closed)
+
+	visitErr := errors.New("visit failed")
+	iterator = newResults("a", "b")
+	err = iterate.All(iterator, func(*queryresult.KV) error {
+		return visitErr
+	})
+	assert.Equal(t, visitErr, err, "visit failures are returned as they are")
+	assert.True(t, iterator.closed)
+}
+
+func TestPartialCompositeKey(t *testing.T) {
+	stub := &mocks.ChaincodeStub{}
+	iterator := newResults("a", "b")
+	stub.GetStateByPartialCompositeKeyReturns(iterator, nil)
+
+	var keys []string
+	require.NoError(t, iterate.PartialCompositeKey(stub, "delta", []string{"alice"}, collect(&keys)))
+	assert.Equal(t, []string{"a", "b"}, keys)
+	assert.True(t, iterator.closed)
+	objectType, attributes := stub.GetStateByPartialCompositeKeyArgsForCall(0)
+	assert.Equal(t, "delta", objectType)
+	assert.Equal(t, []string{"alice"}, attributes)
+
+	stub.GetStateByPartialCompositeKeyReturns(nil, errors.New("query failed"))
+	err := iterate.PartialCompositeKey(stub, "delta", []string{"alice"}, collect(&keys))
+	assert.EqualError(t, err, "query failed")
+	var queryErr *iterate.Error
+	assert.True(t, errors.As(err, &queryErr))
+}
+
+func TestPartialCompositeKeyPage(t *testing.T) {
+	stub := &mocks.ChaincodeStub{}
+	iterator := newResults("a", "b")
+	stub.GetStateByPartialCompositeKeyWithPaginationReturns(iterator, &peer.QueryResponseMetadata{FetchedRecordsCount: 2, Bookmark: "c"}, nil)
+
+	var keys []string
+	metadata, err := iterate.PartialCompositeKeyPage(stub, "holderrank", []string{}, 2, "a", collect(&keys))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"a", "b"}, keys)
+	assert.Equal(t, "c", metadata.GetBookmark())
+	assert.True(t, iterator.closed)
+	objectType, attributes, pageSize, bookmark := stub.GetStateByPartialCompositeKeyWithPaginationArgsForCall(0)
+	assert.Equal(t, "holderrank", objectType)
+	assert.Equal(t, []string{}, attributes)
+	assert.Equal(t, int32(2), pageSize)
+	assert.Equal(t, "a", bookmark)
+}
+
+func TestPageFails(t *testing.T) {
+	_, err := iterate.Page(func() (shim.StateQueryIteratorInterface, *peer.QueryResponseMetadata, error) {
+		return nil, nil, errors.New("query failed")
+	}, func(*queryresult.KV) error {
+		t.Fatal("visited a failed query")
+		return nil
+	})
+	assert.EqualError(t, err, "query failed")
+	var queryErr *iterate.Error
+	assert.True(t, errors.As(err, &queryErr))
+}
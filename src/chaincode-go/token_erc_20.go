@@ -2,11 +2,15 @@
 SPDX-License-Identifier: Apache-2.0
 */
 
+//go:generate go run ./cmd/openapi -o ../application-javascript/openapi.json -src ./chaincode
+//go:generate go run ./cmd/clientgen -o ../client/contract/contract.go -package contract -src ./chaincode
+
 package main
 
 import (
 	"log"
 
+	"github.com/hyperledger/fabric-chaincode-go/shim"
 	"github.com/hyperledger/fabric-contract-api-go/contractapi"
 	"github.com/kkiu1756/my_fabric/src/chaincode-go/chaincode"
 )
@@ -16,8 +20,9 @@ func main() {
 	if err != nil {
 		log.Panicf("Error creating token-erc-20 chaincode: %v", err)
 	}
+	tokenChaincode.Info.Version = chaincode.ContractVersion
 
-	if err := tokenChaincode.Start(); err != nil {
+	if err := shim.Start(chaincode.Recover(tokenChaincode)); err != nil {
 		log.Panicf("Error starting token-erc-20 chaincode: %v", err)
 	}
 }
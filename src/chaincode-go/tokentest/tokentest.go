@@ -0,0 +1,262 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+*/
+
+// Package tokentest builds populated mock ledgers for testing code that
+// calls the token contract, and asserts on the resulting state and events.
+//
+//	l := tokentest.NewLedger(t).
+//		WithAccount("alice", "user", 100).
+//		WithAccount("bob", "user", 0)
+//	_, err := contract.TransferFrom(l.Context, "alice", "bob", 30)
+//	require.NoError(t, err)
+//	l.AssertBalance("bob", 30)
+//	l.AssertEvent("Transfer", map[string]interface{}{"from": "alice", "to": "bob", "value": 30})
+package tokentest
+
+import (
+	"encoding/json"
+	"errors"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/golang/protobuf/ptypes"
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+
+	"github.com/kkiu1756/my_fabric/src/chaincode-go/chaincode"
+	"github.com/kkiu1756/my_fabric/src/chaincode-go/chaincode/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// DefaultTxID is the transaction ID the stub reports unless WithTxID is used
+const DefaultTxID = "tx1"
+
+// Ledger is world state behind a mock transaction context. Context can be
+// passed straight to contract methods; Stub is exposed for injecting
+// failures with the counterfeiter Returns helpers.
+type Ledger struct {
+	State   map[string][]byte
+	Stub    *mocks.ChaincodeStub
+	Context *mocks.TransactionContext
+	t       testing.TB
+	// strict rejects writes after paginated queries made since the
+	// transaction's first paginated query count
+	strict    bool
+	paginated int
+}
+
+// NewLedger returns a ledger holding only the contract's initialization
+// record, so mutating methods can be called straight away
+func NewLedger(t testing.TB) *Ledger {
+	l := &Ledger{
+		State:   map[string][]byte{},
+		Stub:    &mocks.ChaincodeStub{},
+		Context: &mocks.TransactionContext{},
+		t:       t,
+	}
+
+	l.Stub.GetStateStub = func(key string) ([]byte, error) {
+		return l.State[key], nil
+	}
+	l.Stub.PutStateStub = func(key string, value []byte) error {
+		if err := l.checkWrite(); err != nil {
+			return err
+		}
+		l.State[key] = value
+		return nil
+	}
+	l.Stub.DelStateStub = func(key string) error {
+		if err := l.checkWrite(); err != nil {
+			return err
+		}
+		delete(l.State, key)
+		return nil
+	}
+	l.Stub.GetTxIDReturns(DefaultTxID)
+	l.Stub.CreateCompositeKeyStub = shim.CreateCompositeKey
+	l.Context.GetStubReturns(l.Stub)
+	l.put(chaincode.InitializationKey, chaincode.Initialization{MSPID: "Org1MSP", AdminID: "admin", TXID: "init"})
+
+	return l
+}
+
+// Uninitialized removes the initialization record, as before Initialize
+func (l *Ledger) Uninitialized() *Ledger {
+	delete(l.State, chaincode.InitializationKey)
+	return l
+}
+
+// WithCaller sets the identity the stub reports as the transaction creator
+func (l *Ledger) WithCaller(mspID string, commonName string, ous ...string) *Ledger {
+	l.t.Helper()
+	l.Stub.GetCreatorReturns(Identity(l.t, mspID, commonName, ous...), nil)
+	return l
+}
+
+// WithAccount stores an account record
+func (l *Ledger) WithAccount(id string, userType string, balance int) *Ledger {
+	l.t.Helper()
+	l.put(id, chaincode.User{ID: id, Type: userType, Balance: balance})
+	return l
+}
+
+// WithTransaction stores a transfer record as TransferFrom writes it
+func (l *Ledger) WithTransaction(txID string, from string, to string, value int) *Ledger {
+	l.t.Helper()
+	l.put(txID, chaincode.Transaction{TXID: txID, Type: chaincode.TransactionTransfer, From: from, To: to, Value: value})
+	return l
+}
+
+// WithTxID sets the ID of the next transaction
+func (l *Ledger) WithTxID(txID string) *Ledger {
+	l.Stub.GetTxIDReturns(txID)
+	l.paginated = l.paginatedQueries()
+	return l
+}
+
+// RejectWritesAfterPagination makes writes fail once the transaction ran a
+// paginated query, as peers do when simulating it. WithTxID starts the
+// next transaction.
+func (l *Ledger) RejectWritesAfterPagination() *Ledger {
+	l.strict = true
+	l.paginated = l.paginatedQueries()
+	return l
+}
+
+func (l *Ledger) paginatedQueries() int {
+	return l.Stub.GetStateByRangeWithPaginationCallCount() +
+		l.Stub.GetStateByPartialCompositeKeyWithPaginationCallCount() +
+		l.Stub.GetQueryResultWithPaginationCallCount()
+}
+
+func (l *Ledger) checkWrite() error {
+	if l.strict && l.paginatedQueries() > l.paginated {
+		return errors.New("transaction with paginated queries cannot write")
+	}
+	return nil
+}
+
+// WithTimestamp sets the proposal timestamp the stub reports
+func (l *Ledger) WithTimestamp(ts time.Time) *Ledger {
+	l.t.Helper()
+
+	proposal, err := ptypes.TimestampProto(ts)
+	require.NoError(l.t, err)
+	l.Stub.GetTxTimestampReturns(proposal, nil)
+	return l
+}
+
+// WithChannel sets the channel the stub reports
+func (l *Ledger) WithChannel(channelID string) *Ledger {
+	l.Stub.GetChannelIDReturns(channelID)
+	return l
+}
+
+func (l *Ledger) put(key string, record interface{}) {
+	l.t.Helper()
+
+	data, err := json.Marshal(record)
+	require.NoError(l.t, err)
+	l.State[key] = data
+}
+
+// Account returns the stored account record
+func (l *Ledger) Account(id string) *chaincode.User {
+	l.t.Helper()
+
+	data, ok := l.State[id]
+	require.True(l.t, ok, "account %s does not exist", id)
+
+	var user chaincode.User
+	require.NoError(l.t, json.Unmarshal(data, &user))
+	return &user
+}
+
+// Accounts returns the IDs of all stored account records in order
+func (l *Ledger) Accounts() []string {
+	var ids []string
+	for key, data := range l.State {
+		var record map[string]json.RawMessage
+		if json.Unmarshal(data, &record) != nil {
+			continue
+		}
+		if _, ok := record["userId"]; ok {
+			ids = append(ids, key)
+		}
+	}
+	sort.Strings(ids)
+
+	return ids
+}
+
+// AssertBalance checks the balance of an account
+func (l *Ledger) AssertBalance(id string, expected int) {
+	l.t.Helper()
+	assert.Equal(l.t, expected, l.Account(id).Balance, "balance of %s", id)
+}
+
+// AssertTotal checks the sum of all account balances. Pending deltas of
+// hot accounts are not included, prune them first.
+func (l *Ledger) AssertTotal(expected int) {
+	l.t.Helper()
+
+	total := 0
+	for _, id := range l.Accounts() {
+		total += l.Account(id).Balance
+	}
+	assert.Equal(l.t, expected, total, "sum of balances")
+}
+
+// AssertNoAccount checks that id has no account record
+func (l *Ledger) AssertNoAccount(id string) {
+	l.t.Helper()
+	assert.NotContains(l.t, l.State, id)
+}
+
+// Transaction returns the stored transaction record
+func (l *Ledger) Transaction(txID string) chaincode.Transaction {
+	l.t.Helper()
+
+	data, ok := l.State[txID]
+	require.True(l.t, ok, "transaction %s does not exist", txID)
+
+	var tx chaincode.Transaction
+	require.NoError(l.t, json.Unmarshal(data, &tx))
+	return tx
+}
+
+// AssertTransaction checks the accounts and value of a stored transaction
+// record, ignoring its timestamp, channel and initiator
+func (l *Ledger) AssertTransaction(txID string, from string, to string, value int) {
+	l.t.Helper()
+
+	tx := l.Transaction(txID)
+	assert.Equal(l.t, txID, tx.TXID)
+	assert.Equal(l.t, from, tx.From, "sender")
+	assert.Equal(l.t, to, tx.To, "recipient")
+	assert.Equal(l.t, value, tx.Value)
+}
+
+// AssertEvent checks the last event set by the transaction. payload is
+// compared as JSON, so a map or the event struct's JSON shape both work.
+func (l *Ledger) AssertEvent(name string, payload interface{}) {
+	l.t.Helper()
+
+	count := l.Stub.SetEventCallCount()
+	require.NotZero(l.t, count, "no event was set")
+
+	actualName, actualPayload := l.Stub.SetEventArgsForCall(count - 1)
+	expected, err := json.Marshal(payload)
+	require.NoError(l.t, err)
+
+	assert.Equal(l.t, name, actualName)
+	assert.JSONEq(l.t, string(expected), string(actualPayload))
+}
+
+// AssertNoEvent checks that the transaction set no event
+func (l *Ledger) AssertNoEvent() {
+	l.t.Helper()
+	assert.Zero(l.t, l.Stub.SetEventCallCount(), "unexpected event")
+}
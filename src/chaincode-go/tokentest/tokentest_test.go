@@ -0,0 +1,64 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package tokentest_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/kkiu1756/my_fabric/src/chaincode-go/chaincode"
+	"github.com/kkiu1756/my_fabric/src/chaincode-go/tokentest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLedger(t *testing.T) {
+	l := tokentest.NewLedger(t).
+		WithAccount("alice", "user", 100).
+		WithAccount("bob", "merchant", 20).
+		WithTransaction("tx0", "bob", "alice", 5).
+		WithTxID("tx7")
+
+	assert.Equal(t, []string{"alice", "bob"}, l.Accounts())
+	assert.Equal(t, &chaincode.User{ID: "bob", Type: "merchant", Balance: 20}, l.Account("bob"))
+	l.AssertTotal(120)
+	l.AssertTransaction("tx0", "bob", "alice", 5)
+	l.AssertNoEvent()
+
+	contract := &chaincode.SmartContract{}
+	_, err := contract.TransferFrom(l.Context, "alice", "bob", 30)
+	require.NoError(t, err)
+
+	l.AssertBalance("alice", 70)
+	l.AssertBalance("bob", 50)
+	l.AssertTotal(120)
+	l.AssertTransaction("tx7", "alice", "bob", 30)
+	l.AssertEvent("Transfer", map[string]interface{}{"from": "alice", "to": "bob", "value": 30})
+
+	require.NoError(t, contract.DeleteUser(l.Context, "bob"))
+	l.AssertNoAccount("bob")
+	l.AssertTotal(70)
+}
+
+func TestLedgerFailureInjection(t *testing.T) {
+	l := tokentest.NewLedger(t).WithAccount("alice", "user", 100)
+	l.Stub.PutStateReturns(fmt.Errorf("unavailable"))
+
+	_, err := (&chaincode.SmartContract{}).SetBalance(l.Context, "alice", 5)
+	assert.EqualError(t, err, `[STATE_UNAVAILABLE] failed to write state "\x00accountcount\x00user\x0002\x00tx1\x00": unavailable`)
+	l.AssertBalance("alice", 100)
+}
+
+func TestLedgerRejectsWritesAfterPagination(t *testing.T) {
+	l := tokentest.NewLedger(t).WithAccount("alice", "user", 100).RejectWritesAfterPagination()
+
+	require.NoError(t, l.Stub.PutState("bob", []byte(`{}`)), "should write before a paginated query")
+	_, _, _ = l.Stub.GetStateByRangeWithPagination("", "", 10, "")
+	assert.EqualError(t, l.Stub.PutState("carol", []byte(`{}`)), "transaction with paginated queries cannot write")
+	assert.EqualError(t, l.Stub.DelState("alice"), "transaction with paginated queries cannot write")
+
+	l.WithTxID("tx2")
+	assert.NoError(t, l.Stub.PutState("carol", []byte(`{}`)), "should start over with the next transaction")
+}
@@ -0,0 +1,601 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+*/
+
+// Package clientgen converts contract API metadata into typed clients with
+// one method per contract transaction, in Go and in TypeScript. The
+// metadata names parameters param0, param1 and so on; Source supplies the
+// names and doc comments of the contract's own methods where it has them.
+package clientgen
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"os"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/go-openapi/spec"
+	"github.com/hyperledger/fabric-contract-api-go/metadata"
+	"github.com/kkiu1756/my_fabric/src/chaincode-go/tools/openapi"
+)
+
+// Header marks generated files, as go generate expects
+const Header = "Code generated by clientgen. DO NOT EDIT."
+
+// Source holds what the metadata lacks from the contract's Go source
+type Source struct {
+	// Params are the parameter names of each method, without the context
+	Params map[string][]string
+	// Docs are the doc comments of each method
+	Docs map[string]string
+}
+
+// ParseSource reads the parameter names and doc comments of the methods of
+// receiver from the Go files of the package in dir, skipping tests
+func ParseSource(dir string, receiver string) (*Source, error) {
+	fset := token.NewFileSet()
+	notTest := func(info os.FileInfo) bool { return !strings.HasSuffix(info.Name(), "_test.go") }
+	pkgs, err := parser.ParseDir(fset, dir, notTest, parser.ParseComments)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %v", dir, err)
+	}
+
+	src := &Source{Params: map[string][]string{}, Docs: map[string]string{}}
+	for _, pkg := range pkgs {
+		for _, file := range pkg.Files {
+			for _, decl := range file.Decls {
+				fn, ok := decl.(*ast.FuncDecl)
+				if !ok || fn.Recv == nil || receiverName(fn.Recv) != receiver {
+					continue
+				}
+
+				var params []string
+				for _, field := range fn.Type.Params.List {
+					for _, name := range field.Names {
+						params = append(params, name.Name)
+					}
+				}
+				// the first parameter is the transaction context
+				if len(params) > 0 {
+					params = params[1:]
+				}
+				src.Params[fn.Name.Name] = params
+				if fn.Doc != nil {
+					src.Docs[fn.Name.Name] = fn.Doc.Text()
+				}
+			}
+		}
+	}
+
+	return src, nil
+}
+
+func receiverName(recv *ast.FieldList) string {
+	if len(recv.List) != 1 {
+		return ""
+	}
+	expr := recv.List[0].Type
+	if star, ok := expr.(*ast.StarExpr); ok {
+		expr = star.X
+	}
+	if ident, ok := expr.(*ast.Ident); ok {
+		return ident.Name
+	}
+
+	return ""
+}
+
+// kind is the shape of a metadata schema
+type kind int
+
+const (
+	kindString kind = iota
+	kindInt
+	kindInt32
+	kindNumber
+	kindBool
+	kindObject
+	kindArray
+	kindMap
+	kindAny
+)
+
+// typ is a metadata schema resolved for code generation
+type typ struct {
+	kind kind
+	// name is the component of a kindObject
+	name string
+	// elem is the element of a kindArray or kindMap
+	elem *typ
+}
+
+// field is a property of a component
+type field struct {
+	name     string
+	typ      *typ
+	required bool
+}
+
+// object is a component of the metadata
+type object struct {
+	name   string
+	fields []*field
+}
+
+// param is a parameter of a transaction
+type param struct {
+	name string
+	typ  *typ
+}
+
+// method is a transaction of the contract
+type method struct {
+	name        string
+	transaction string
+	doc         string
+	evaluate    bool
+	params      []*param
+	// returns is nil for a transaction returning nothing
+	returns *typ
+}
+
+// model is the metadata resolved for code generation
+type model struct {
+	objects []*object
+	methods []*method
+}
+
+// resolve converts md into the types and methods to generate, sorted by
+// name. src may be nil.
+func resolve(md *metadata.ContractChaincodeMetadata, src *Source) (*model, error) {
+	if src == nil {
+		src = &Source{}
+	}
+	m := &model{}
+
+	objectNames := make([]string, 0, len(md.Components.Schemas))
+	for name := range md.Components.Schemas {
+		objectNames = append(objectNames, name)
+	}
+	sort.Strings(objectNames)
+	for _, name := range objectNames {
+		schema := md.Components.Schemas[name]
+		required := map[string]bool{}
+		for _, property := range schema.Required {
+			required[property] = true
+		}
+
+		o := &object{name: name}
+		propertyNames := make([]string, 0, len(schema.Properties))
+		for property := range schema.Properties {
+			propertyNames = append(propertyNames, property)
+		}
+		sort.Strings(propertyNames)
+		for _, property := range propertyNames {
+			s := schema.Properties[property]
+			t, err := schemaType(&s)
+			if err != nil {
+				return nil, fmt.Errorf("%s.%s: %v", name, property, err)
+			}
+			o.fields = append(o.fields, &field{name: property, typ: t, required: required[property]})
+		}
+		m.objects = append(m.objects, o)
+	}
+
+	contractNames := make([]string, 0, len(md.Contracts))
+	for name := range md.Contracts {
+		if name != openapi.SystemContractName {
+			contractNames = append(contractNames, name)
+		}
+	}
+	sort.Strings(contractNames)
+	for _, contractName := range contractNames {
+		contract := md.Contracts[contractName]
+		for _, tx := range contract.Transactions {
+			meth := &method{name: tx.Name, transaction: tx.Name, evaluate: openapi.IsEvaluate(tx), doc: src.Docs[tx.Name]}
+			if !contract.Default {
+				meth.name = exported(contractName) + tx.Name
+				meth.transaction = contractName + ":" + tx.Name
+				meth.doc = ""
+			}
+
+			names := src.Params[tx.Name]
+			if len(names) != len(tx.Parameters) || !contract.Default {
+				names = nil
+			}
+			for i, p := range tx.Parameters {
+				t, err := schemaType(p.Schema)
+				if err != nil {
+					return nil, fmt.Errorf("%s parameter %s: %v", meth.transaction, p.Name, err)
+				}
+				switch t.kind {
+				case kindString, kindInt, kindInt32, kindNumber, kindBool:
+				default:
+					return nil, fmt.Errorf("%s parameter %s: only strings, numbers and booleans are supported", meth.transaction, p.Name)
+				}
+				// unused parameters are named _ or _name in the source
+				name := p.Name
+				if names != nil && strings.TrimLeft(names[i], "_") != "" {
+					name = strings.TrimLeft(names[i], "_")
+				}
+				meth.params = append(meth.params, &param{name: name, typ: t})
+			}
+
+			if tx.Returns.Schema != nil {
+				t, err := schemaType(tx.Returns.Schema)
+				if err != nil {
+					return nil, fmt.Errorf("%s returns: %v", meth.transaction, err)
+				}
+				meth.returns = t
+			}
+			m.methods = append(m.methods, meth)
+		}
+	}
+	sort.Slice(m.methods, func(i, j int) bool { return m.methods[i].name < m.methods[j].name })
+
+	return m, nil
+}
+
+// schemaType resolves s, which is either a component reference or a
+// primitive, array or map schema
+func schemaType(s *spec.Schema) (*typ, error) {
+	if s == nil {
+		return &typ{kind: kindAny}, nil
+	}
+	// components reference each other by bare name, transactions by path
+	if ref := s.Ref.String(); ref != "" {
+		name := strings.TrimPrefix(ref, "#/components/schemas/")
+		if strings.ContainsAny(name, "#/") {
+			return nil, fmt.Errorf("unsupported reference %s", ref)
+		}
+		return &typ{kind: kindObject, name: name}, nil
+	}
+
+	switch {
+	case s.Type.Contains("string"):
+		return &typ{kind: kindString}, nil
+	case s.Type.Contains("boolean"):
+		return &typ{kind: kindBool}, nil
+	case s.Type.Contains("integer") && s.Format == "int32":
+		return &typ{kind: kindInt32}, nil
+	case s.Type.Contains("integer"):
+		return &typ{kind: kindInt}, nil
+	case s.Type.Contains("number"):
+		return &typ{kind: kindNumber}, nil
+	case s.Type.Contains("array"):
+		if s.Items == nil || s.Items.Schema == nil {
+			return nil, fmt.Errorf("array without item schema")
+		}
+		elem, err := schemaType(s.Items.Schema)
+		if err != nil {
+			return nil, err
+		}
+		return &typ{kind: kindArray, elem: elem}, nil
+	case s.Type.Contains("object") && s.AdditionalProperties != nil && s.AdditionalProperties.Schema != nil:
+		elem, err := schemaType(s.AdditionalProperties.Schema)
+		if err != nil {
+			return nil, err
+		}
+		return &typ{kind: kindMap, elem: elem}, nil
+	default:
+		return &typ{kind: kindAny}, nil
+	}
+}
+
+// goKeywords are the names a generated parameter cannot take, the Go
+// keywords and the names the generated methods use
+var goKeywords = map[string]bool{
+	"break": true, "case": true, "chan": true, "const": true, "continue": true, "default": true,
+	"defer": true, "else": true, "fallthrough": true, "for": true, "func": true, "go": true,
+	"goto": true, "if": true, "import": true, "interface": true, "map": true, "package": true,
+	"range": true, "return": true, "select": true, "struct": true, "switch": true, "type": true,
+	"var": true, "c": true, "payload": true, "result": true, "err": true, "json": true, "strconv": true,
+}
+
+// Go returns the gofmt'ed source of package pkg holding the components of
+// md as structs and a Client with a method per transaction
+func Go(md *metadata.ContractChaincodeMetadata, pkg string, src *Source) ([]byte, error) {
+	m, err := resolve(md, src)
+	if err != nil {
+		return nil, err
+	}
+
+	var body bytes.Buffer
+	usesJSON, usesStrconv := false, false
+	for _, o := range m.objects {
+		fmt.Fprintf(&body, "// %s is the %s component of the contract metadata\ntype %s struct {\n", o.name, o.name, o.name)
+		for _, f := range o.fields {
+			tag := f.name
+			if !f.required {
+				tag += ",omitempty"
+			}
+			fmt.Fprintf(&body, "\t%s %s `json:%q`\n", exported(f.name), goType(f.typ), tag)
+		}
+		body.WriteString("}\n\n")
+	}
+
+	for _, meth := range m.methods {
+		call, verb := "SubmitTransaction", "submits"
+		if meth.evaluate {
+			call, verb = "EvaluateTransaction", "evaluates"
+		}
+		if meth.doc != "" {
+			body.WriteString(goComment(meth.doc))
+		} else {
+			fmt.Fprintf(&body, "// %s %s the %s transaction\n", meth.name, verb, meth.transaction)
+		}
+
+		var params, args []string
+		args = append(args, fmt.Sprintf("%q", meth.transaction))
+		for _, p := range meth.params {
+			name := p.name
+			if goKeywords[name] {
+				name += "Arg"
+			}
+			params = append(params, name+" "+goType(p.typ))
+			arg := goArg(p.typ, name)
+			if arg != name {
+				usesStrconv = true
+			}
+			args = append(args, arg)
+		}
+
+		invoke := fmt.Sprintf("c.invoker.%s(%s)", call, strings.Join(args, ", "))
+		if meth.returns == nil {
+			fmt.Fprintf(&body, "func (c *Client) %s(%s) error {\n\t_, err := %s\n\treturn err\n}\n\n", meth.name, strings.Join(params, ", "), invoke)
+			continue
+		}
+
+		usesJSON = true
+		result := goType(meth.returns)
+		fmt.Fprintf(&body, "func (c *Client) %s(%s) (%s, error) {\n", meth.name, strings.Join(params, ", "), result)
+		fmt.Fprintf(&body, "\tvar result %s\n\tpayload, err := %s\n\tif err != nil {\n\t\treturn result, err\n\t}\n", result, invoke)
+		fmt.Fprintf(&body, "\terr = json.Unmarshal(payload, &result)\n\treturn result, err\n}\n\n")
+	}
+
+	var out bytes.Buffer
+	fmt.Fprintf(&out, "// %s\n\n", Header)
+	fmt.Fprintf(&out, "// Package %s is a typed client of the token contract\npackage %s\n\n", pkg, pkg)
+	out.WriteString("import (\n")
+	if usesJSON {
+		out.WriteString("\t\"encoding/json\"\n")
+	}
+	if usesStrconv {
+		out.WriteString("\t\"strconv\"\n")
+	}
+	out.WriteString(")\n\n")
+	out.WriteString(`// Invoker submits and evaluates contract transactions, as a gateway
+// Contract does
+type Invoker interface {
+	SubmitTransaction(name string, args ...string) ([]byte, error)
+	EvaluateTransaction(name string, args ...string) ([]byte, error)
+}
+
+// Client calls the contract's transactions through an Invoker
+type Client struct {
+	invoker Invoker
+}
+
+// New returns a client calling the contract through invoker
+func New(invoker Invoker) *Client {
+	return &Client{invoker: invoker}
+}
+
+`)
+	out.Write(body.Bytes())
+
+	source, err := format.Source(out.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("failed to format generated source: %v", err)
+	}
+
+	return source, nil
+}
+
+func goType(t *typ) string {
+	switch t.kind {
+	case kindString:
+		return "string"
+	case kindInt:
+		return "int"
+	case kindInt32:
+		return "int32"
+	case kindNumber:
+		return "float64"
+	case kindBool:
+		return "bool"
+	case kindObject:
+		return "*" + t.name
+	case kindArray:
+		return "[]" + goType(t.elem)
+	case kindMap:
+		return "map[string]" + goType(t.elem)
+	default:
+		return "interface{}"
+	}
+}
+
+// goArg returns the expression passing parameter name of type t as a
+// transaction argument
+func goArg(t *typ, name string) string {
+	switch t.kind {
+	case kindInt:
+		return "strconv.Itoa(" + name + ")"
+	case kindInt32:
+		return "strconv.FormatInt(int64(" + name + "), 10)"
+	case kindNumber:
+		return "strconv.FormatFloat(" + name + ", 'f', -1, 64)"
+	case kindBool:
+		return "strconv.FormatBool(" + name + ")"
+	default:
+		return name
+	}
+}
+
+func goComment(doc string) string {
+	var b strings.Builder
+	for _, line := range strings.Split(strings.TrimRight(doc, "\n"), "\n") {
+		if line == "" {
+			b.WriteString("//\n")
+			continue
+		}
+		b.WriteString("// " + line + "\n")
+	}
+
+	return b.String()
+}
+
+// tsKeywords are the names a generated parameter cannot take in
+// TypeScript
+var tsKeywords = map[string]bool{
+	"break": true, "case": true, "catch": true, "class": true, "const": true, "continue": true,
+	"debugger": true, "default": true, "delete": true, "do": true, "else": true, "enum": true,
+	"export": true, "extends": true, "false": true, "finally": true, "for": true, "function": true,
+	"if": true, "import": true, "in": true, "instanceof": true, "new": true, "null": true,
+	"return": true, "super": true, "switch": true, "this": true, "throw": true, "true": true,
+	"try": true, "typeof": true, "var": true, "void": true, "while": true, "with": true,
+	"payload": true,
+}
+
+// TypeScript returns a TypeScript module holding the components of md as
+// interfaces and a Client class with a method per transaction, lowerCamel
+// cased
+func TypeScript(md *metadata.ContractChaincodeMetadata, src *Source) ([]byte, error) {
+	m, err := resolve(md, src)
+	if err != nil {
+		return nil, err
+	}
+
+	var out bytes.Buffer
+	fmt.Fprintf(&out, "// %s\n\n", Header)
+	out.WriteString(`// Invoker submits and evaluates contract transactions, as a fabric-network
+// Contract does
+export interface Invoker {
+  submitTransaction(name: string, ...args: string[]): Promise<Buffer>;
+  evaluateTransaction(name: string, ...args: string[]): Promise<Buffer>;
+}
+
+`)
+	for _, o := range m.objects {
+		fmt.Fprintf(&out, "export interface %s {\n", o.name)
+		for _, f := range o.fields {
+			optional := "?"
+			if f.required {
+				optional = ""
+			}
+			fmt.Fprintf(&out, "  %s%s: %s;\n", f.name, optional, tsType(f.typ))
+		}
+		out.WriteString("}\n\n")
+	}
+
+	out.WriteString("// Client calls the contract's transactions through an Invoker\nexport class Client {\n  constructor(private readonly invoker: Invoker) {}\n")
+	for _, meth := range m.methods {
+		call := "submitTransaction"
+		if meth.evaluate {
+			call = "evaluateTransaction"
+		}
+
+		var params []string
+		args := []string{"'" + meth.transaction + "'"}
+		for _, p := range meth.params {
+			name := p.name
+			if tsKeywords[name] {
+				name += "Arg"
+			}
+			params = append(params, name+": "+tsType(p.typ))
+			if p.typ.kind == kindString {
+				args = append(args, name)
+			} else {
+				args = append(args, "String("+name+")")
+			}
+		}
+
+		out.WriteString("\n")
+		if meth.doc != "" {
+			out.WriteString(tsComment(meth.doc))
+		}
+		invoke := fmt.Sprintf("this.invoker.%s(%s)", call, strings.Join(args, ", "))
+		if meth.returns == nil {
+			fmt.Fprintf(&out, "  async %s(%s): Promise<void> {\n    await %s;\n  }\n", lowerFirst(meth.name), strings.Join(params, ", "), invoke)
+			continue
+		}
+		result := tsType(meth.returns)
+		fmt.Fprintf(&out, "  async %s(%s): Promise<%s> {\n", lowerFirst(meth.name), strings.Join(params, ", "), result)
+		fmt.Fprintf(&out, "    const payload = await %s;\n    return JSON.parse(payload.toString()) as %s;\n  }\n", invoke, result)
+	}
+	out.WriteString("}\n")
+
+	return out.Bytes(), nil
+}
+
+func tsType(t *typ) string {
+	switch t.kind {
+	case kindString:
+		return "string"
+	case kindInt, kindInt32, kindNumber:
+		return "number"
+	case kindBool:
+		return "boolean"
+	case kindObject:
+		return t.name
+	case kindArray:
+		return tsType(t.elem) + "[]"
+	case kindMap:
+		return "Record<string, " + tsType(t.elem) + ">"
+	default:
+		return "unknown"
+	}
+}
+
+func tsComment(doc string) string {
+	var b strings.Builder
+	b.WriteString("  /**\n")
+	doc = strings.Replace(strings.TrimRight(doc, "\n"), "*/", "*\\/", -1)
+	for _, line := range strings.Split(doc, "\n") {
+		b.WriteString(strings.TrimRight("   * "+line, " ") + "\n")
+	}
+	b.WriteString("   */\n")
+
+	return b.String()
+}
+
+// exported returns name, a JSON property or contract name, as an exported
+// Go identifier, upper casing a trailing Id as Go initialisms are
+func exported(name string) string {
+	var b strings.Builder
+	upper := true
+	for _, r := range name {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			upper = true
+			continue
+		}
+		if upper {
+			r = unicode.ToUpper(r)
+			upper = false
+		}
+		b.WriteRune(r)
+	}
+	s := b.String()
+	switch {
+	case strings.HasSuffix(s, "Id"):
+		s = strings.TrimSuffix(s, "Id") + "ID"
+	case strings.HasSuffix(s, "Ids"):
+		s = strings.TrimSuffix(s, "Ids") + "IDs"
+	}
+
+	return s
+}
+
+func lowerFirst(name string) string {
+	if name == "" {
+		return name
+	}
+
+	return strings.ToLower(name[:1]) + name[1:]
+}
@@ -0,0 +1,78 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package clientgen
+
+import (
+	"io/ioutil"
+	"testing"
+
+	"github.com/kkiu1756/my_fabric/src/chaincode-go/chaincode"
+	"github.com/kkiu1756/my_fabric/src/chaincode-go/tools/openapi"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGo(t *testing.T) {
+	md, err := openapi.GetMetadata(&chaincode.SmartContract{})
+	require.NoError(t, err)
+
+	client, err := Go(md, "token", nil)
+	require.NoError(t, err)
+	source := string(client)
+	assert.Contains(t, source, "// "+Header+"\n")
+	assert.Contains(t, source, "package token\n")
+	assert.Contains(t, source, "type User struct {\n\tBalance int    `json:\"balance\"`\n\tHot     bool   `json:\"hot,omitempty\"`\n", "should make optional properties omitempty")
+	assert.Contains(t, source, "func (c *Client) TransferFrom(param0 string, param1 string, param2 int) (*Transaction, error) {")
+	assert.Contains(t, source, `c.invoker.SubmitTransaction("TransferFrom", param0, param1, strconv.Itoa(param2))`)
+	assert.Contains(t, source, `c.invoker.EvaluateTransaction("GetUser", param0)`, "should evaluate evaluate transactions")
+	assert.Contains(t, source, "func (c *Client) DeleteUser(param0 string) error {")
+	assert.NotContains(t, source, "GetMetadata", "should not expose the system contract")
+
+	src, err := ParseSource("../../chaincode", "SmartContract")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"from", "to", "value"}, src.Params["TransferFrom"])
+	client, err = Go(md, "token", src)
+	require.NoError(t, err)
+	source = string(client)
+	assert.Contains(t, source, "// TransferFrom transfers the value amount from the \"from\" address to the \"to\" address\n")
+	assert.Contains(t, source, "func (c *Client) TransferFrom(from string, to string, value int) (*Transaction, error) {")
+}
+
+func TestGeneratedClientIsCurrent(t *testing.T) {
+	md, err := openapi.GetMetadata(&chaincode.SmartContract{})
+	require.NoError(t, err)
+	src, err := ParseSource("../../chaincode", "SmartContract")
+	require.NoError(t, err)
+
+	client, err := Go(md, "contract", src)
+	require.NoError(t, err)
+	generated, err := ioutil.ReadFile("../../../client/contract/contract.go")
+	require.NoError(t, err)
+	assert.Equal(t, string(client), string(generated), "run go generate in src/chaincode-go")
+}
+
+func TestTypeScript(t *testing.T) {
+	md, err := openapi.GetMetadata(&chaincode.SmartContract{})
+	require.NoError(t, err)
+	src, err := ParseSource("../../chaincode", "SmartContract")
+	require.NoError(t, err)
+
+	client, err := TypeScript(md, src)
+	require.NoError(t, err)
+	source := string(client)
+	assert.Contains(t, source, "export interface User {\n  balance: number;\n  hot?: boolean;\n")
+	assert.Contains(t, source, "  async transferFrom(from: string, to: string, value: number): Promise<Transaction> {\n"+
+		"    const payload = await this.invoker.submitTransaction('TransferFrom', from, to, String(value));\n")
+	assert.Contains(t, source, "  async deleteUser(id: string): Promise<void> {\n")
+	assert.Contains(t, source, "this.invoker.evaluateTransaction('GetUser', id)")
+}
+
+func TestExported(t *testing.T) {
+	assert.Equal(t, "UserID", exported("userId"))
+	assert.Equal(t, "ID", exported("id"))
+	assert.Equal(t, "RecipientIDs", exported("recipientIds"))
+	assert.Equal(t, "InitiatorMspID", exported("initiatorMspId"))
+	assert.Equal(t, "OrgHyperledgerFabric", exported("org.hyperledger.fabric"))
+}
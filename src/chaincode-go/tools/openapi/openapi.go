@@ -0,0 +1,255 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+*/
+
+// Package openapi converts contract API metadata into an OpenAPI 3 document
+// describing the REST gateway's generic transaction routes
+package openapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/go-openapi/spec"
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+	"github.com/hyperledger/fabric-chaincode-go/shimtest"
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+	"github.com/hyperledger/fabric-contract-api-go/metadata"
+)
+
+// SystemContractName is the contract every chaincode exposes for metadata
+const SystemContractName = "org.hyperledger.fabric"
+
+// PathPrefix is where the gateway mounts the generated transaction routes
+const PathPrefix = "/api"
+
+// Document is the subset of an OpenAPI 3 document the gateway needs
+type Document struct {
+	OpenAPI    string              `json:"openapi"`
+	Info       Info                `json:"info"`
+	Paths      map[string]PathItem `json:"paths"`
+	Components Components          `json:"components"`
+}
+
+// Info describes the API
+type Info struct {
+	Title       string `json:"title"`
+	Description string `json:"description,omitempty"`
+	Version     string `json:"version"`
+}
+
+// PathItem holds the operation for a single transaction
+type PathItem struct {
+	Get  *Operation `json:"get,omitempty"`
+	Post *Operation `json:"post,omitempty"`
+}
+
+// Operation describes how a transaction is invoked over HTTP. XTransaction
+// and XParameters let the gateway map a request onto the chaincode call.
+type Operation struct {
+	Tags         []string              `json:"tags"`
+	Summary      string                `json:"summary"`
+	OperationID  string                `json:"operationId"`
+	Parameters   []Parameter           `json:"parameters,omitempty"`
+	RequestBody  *RequestBody          `json:"requestBody,omitempty"`
+	Responses    map[string]Response   `json:"responses"`
+	Security     []map[string][]string `json:"security,omitempty"`
+	XTransaction string                `json:"x-transaction"`
+	XParameters  []string              `json:"x-parameters"`
+}
+
+// Parameter is a query parameter of an evaluate transaction
+type Parameter struct {
+	Name     string       `json:"name"`
+	In       string       `json:"in"`
+	Required bool         `json:"required"`
+	Schema   *spec.Schema `json:"schema"`
+}
+
+// RequestBody carries the arguments of a submit transaction
+type RequestBody struct {
+	Required bool                 `json:"required"`
+	Content  map[string]MediaType `json:"content"`
+}
+
+// Response is a possible HTTP response of an operation
+type Response struct {
+	Description string               `json:"description"`
+	Content     map[string]MediaType `json:"content,omitempty"`
+}
+
+// MediaType wraps a schema for a content type
+type MediaType struct {
+	Schema *spec.Schema `json:"schema"`
+}
+
+// Components holds the schemas and security schemes referenced by
+// operations
+type Components struct {
+	Schemas         map[string]metadata.ObjectMetadata `json:"schemas"`
+	SecuritySchemes map[string]SecurityScheme          `json:"securitySchemes,omitempty"`
+}
+
+// SecurityScheme describes how an operation authenticates
+type SecurityScheme struct {
+	Type   string `json:"type"`
+	Scheme string `json:"scheme"`
+}
+
+// AdminAuth is the security scheme of the submit operations: the gateway
+// takes them only with the bearer token of an admin
+const AdminAuth = "adminToken"
+
+// GetMetadata builds the chaincode for contract in memory and invokes the
+// system contract's GetMetadata, returning exactly what peers would return
+func GetMetadata(contract contractapi.ContractInterface) (*metadata.ContractChaincodeMetadata, error) {
+	chaincode, err := contractapi.NewChaincode(contract)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create chaincode: %v", err)
+	}
+
+	stub := shimtest.NewMockStub("metadata", chaincode)
+	response := stub.MockInvoke("metadata", [][]byte{[]byte(SystemContractName + ":GetMetadata")})
+	if response.Status != shim.OK {
+		return nil, fmt.Errorf("failed to get metadata: %s", response.Message)
+	}
+
+	var md metadata.ContractChaincodeMetadata
+	if err := json.Unmarshal(response.Payload, &md); err != nil {
+		return nil, fmt.Errorf("failed to parse metadata: %v", err)
+	}
+
+	return &md, nil
+}
+
+// Generate converts chaincode metadata into an OpenAPI document. Evaluate
+// transactions become GET operations taking query parameters and submit
+// transactions become POST operations taking a JSON body and an admin
+// bearer token. The metadata names parameters param0, param1 and so on;
+// params, which may be nil, maps the transactions of the default contract
+// to the parameter names of its source, see clientgen.ParseSource.
+func Generate(md *metadata.ContractChaincodeMetadata, title string, version string, params map[string][]string) *Document {
+	doc := &Document{
+		OpenAPI: "3.0.0",
+		Info:    Info{Title: title, Version: version},
+		Paths:   map[string]PathItem{},
+		Components: Components{
+			Schemas:         md.Components.Schemas,
+			SecuritySchemes: map[string]SecurityScheme{AdminAuth: {Type: "http", Scheme: "bearer"}},
+		},
+	}
+	if md.Info != nil && md.Info.Description != "" {
+		doc.Info.Description = md.Info.Description
+	}
+
+	contractNames := make([]string, 0, len(md.Contracts))
+	for name := range md.Contracts {
+		if name != SystemContractName {
+			contractNames = append(contractNames, name)
+		}
+	}
+	sort.Strings(contractNames)
+
+	for _, contractName := range contractNames {
+		contract := md.Contracts[contractName]
+		for _, tx := range contract.Transactions {
+			name := tx.Name
+			if !contract.Default {
+				name = contractName + ":" + tx.Name
+			}
+
+			var names []string
+			if contract.Default {
+				names = params[tx.Name]
+			}
+			op := operation(contractName, name, tx, names)
+			if IsEvaluate(tx) {
+				doc.Paths[PathPrefix+"/"+name] = PathItem{Get: op}
+			} else {
+				doc.Paths[PathPrefix+"/"+name] = PathItem{Post: op}
+			}
+		}
+	}
+
+	return doc
+}
+
+func operation(contractName string, name string, tx metadata.TransactionMetadata, names []string) *Operation {
+	op := &Operation{
+		Tags:         []string{contractName},
+		Summary:      name,
+		OperationID:  strings.Replace(name, ":", "_", -1),
+		XTransaction: name,
+		XParameters:  []string{},
+		Responses: map[string]Response{
+			"200": {Description: "Successful operation"},
+			"400": {Description: "Missing parameter or INVALID_ARGUMENT"},
+			"403": {Description: "UNAUTHORIZED"},
+			"404": {Description: "ACCOUNT_NOT_FOUND or TRANSACTION_NOT_FOUND"},
+			"409": {Description: "ACCOUNT_EXISTS, NOT_INITIALIZED, ALREADY_INITIALIZED, or MVCC_READ_CONFLICT after retries"},
+			"422": {Description: "INSUFFICIENT_BALANCE, BALANCE_OVERFLOW, or LIMIT_EXCEEDED"},
+			"423": {Description: "ACCOUNT_FROZEN"},
+			"500": {Description: "CORRUPT_RECORD, INTERNAL, or transaction failed"},
+			"503": {Description: "STATE_UNAVAILABLE"},
+		},
+	}
+
+	if tx.Returns.Schema != nil {
+		op.Responses["200"] = Response{
+			Description: "Successful operation",
+			Content:     map[string]MediaType{"application/json": {Schema: tx.Returns.Schema}},
+		}
+	}
+
+	if len(names) != len(tx.Parameters) {
+		names = nil
+	}
+	for i, param := range tx.Parameters {
+		// unused parameters are named _ or _name in the source
+		name := param.Name
+		if names != nil && strings.TrimLeft(names[i], "_") != "" {
+			name = strings.TrimLeft(names[i], "_")
+		}
+		op.XParameters = append(op.XParameters, name)
+	}
+
+	if IsEvaluate(tx) {
+		for i, param := range tx.Parameters {
+			op.Parameters = append(op.Parameters, Parameter{Name: op.XParameters[i], In: "query", Required: true, Schema: param.Schema})
+		}
+
+		return op
+	}
+
+	op.Security = []map[string][]string{{AdminAuth: {}}}
+	op.Responses["401"] = Response{Description: "Missing or unknown admin bearer token"}
+	if len(tx.Parameters) > 0 {
+		body := &spec.Schema{}
+		body.Typed("object", "")
+		body.Properties = map[string]spec.Schema{}
+		for i, param := range tx.Parameters {
+			body.Properties[op.XParameters[i]] = *param.Schema
+			body.Required = append(body.Required, op.XParameters[i])
+		}
+
+		op.RequestBody = &RequestBody{
+			Required: true,
+			Content:  map[string]MediaType{"application/json": {Schema: body}},
+		}
+	}
+
+	return op
+}
+
+// IsEvaluate reports whether tx is tagged as an evaluate transaction
+func IsEvaluate(tx metadata.TransactionMetadata) bool {
+	for _, tag := range tx.Tag {
+		if strings.EqualFold(tag, "evaluate") {
+			return true
+		}
+	}
+
+	return false
+}
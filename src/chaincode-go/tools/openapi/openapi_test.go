@@ -0,0 +1,71 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package openapi
+
+import (
+	"testing"
+
+	"github.com/kkiu1756/my_fabric/src/chaincode-go/chaincode"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGenerate(t *testing.T) {
+	md, err := GetMetadata(&chaincode.SmartContract{})
+	require.NoError(t, err)
+
+	doc := Generate(md, "Token", "1.0.0", nil)
+	assert.Equal(t, "3.0.0", doc.OpenAPI)
+	assert.Equal(t, "Token", doc.Info.Title)
+	assert.Contains(t, doc.Components.Schemas, "User", "should carry over component schemas")
+
+	for path := range doc.Paths {
+		assert.NotContains(t, path, SystemContractName, "should not expose the system contract")
+	}
+
+	getUser := doc.Paths["/api/GetUser"]
+	require.NotNil(t, getUser.Get, "evaluate transactions should be GET operations")
+	assert.Nil(t, getUser.Post)
+	assert.Equal(t, "GetUser", getUser.Get.XTransaction)
+	assert.Equal(t, []string{"param0"}, getUser.Get.XParameters)
+	require.Len(t, getUser.Get.Parameters, 1)
+	assert.Equal(t, "query", getUser.Get.Parameters[0].In)
+
+	transfer := doc.Paths["/api/TransferFrom"]
+	require.NotNil(t, transfer.Post, "submit transactions should be POST operations")
+	assert.Nil(t, transfer.Get)
+	assert.Equal(t, []string{"param0", "param1", "param2"}, transfer.Post.XParameters)
+	require.NotNil(t, transfer.Post.RequestBody)
+	body := transfer.Post.RequestBody.Content["application/json"].Schema
+	assert.Equal(t, []string{"param0", "param1", "param2"}, body.Required)
+	assert.Equal(t, "#/components/schemas/Transaction", transfer.Post.Responses["200"].Content["application/json"].Schema.Ref.String())
+	assert.Equal(t, []map[string][]string{{AdminAuth: {}}}, transfer.Post.Security, "submits should need an admin token")
+	assert.Nil(t, getUser.Get.Security, "queries should be open")
+}
+
+func TestGenerateNamesParameters(t *testing.T) {
+	md, err := GetMetadata(&chaincode.SmartContract{})
+	require.NoError(t, err)
+
+	doc := Generate(md, "Token", "1.0.0", map[string][]string{
+		"GetUser":      {"id"},
+		"TransferFrom": {"from", "to", "value"},
+		"CreateUser":   {"_id", "_type", "_balance"},
+		"DeleteUser":   {"id", "extra"},
+	})
+
+	getUser := doc.Paths["/api/GetUser"].Get
+	assert.Equal(t, []string{"id"}, getUser.XParameters)
+	assert.Equal(t, "id", getUser.Parameters[0].Name)
+
+	transfer := doc.Paths["/api/TransferFrom"].Post
+	assert.Equal(t, []string{"from", "to", "value"}, transfer.XParameters)
+	body := transfer.RequestBody.Content["application/json"].Schema
+	assert.Equal(t, []string{"from", "to", "value"}, body.Required)
+	assert.Contains(t, body.Properties, "value")
+
+	assert.Equal(t, []string{"id", "type", "balance"}, doc.Paths["/api/CreateUser"].Post.XParameters, "should trim the underscores of unused names")
+	assert.Equal(t, []string{"param0"}, doc.Paths["/api/DeleteUser"].Post.XParameters, "should keep the metadata names if the counts differ")
+}
@@ -0,0 +1,141 @@
+// Package validation checks contract method arguments before they touch
+// world state, so malformed input is rejected with an error naming the
+// argument instead of surfacing later as a confusing state or JSON failure.
+package validation
+
+import (
+	"errors"
+	"fmt"
+	"unicode/utf8"
+)
+
+const (
+	// MaxIDLength is the longest account or transaction id accepted, in
+	// bytes. Peer-generated transaction ids are 64 hex characters.
+	MaxIDLength = 64
+
+	// MaxAmount is the largest balance or transfer amount accepted. It is
+	// the largest integer a JavaScript client can represent exactly, so
+	// amounts survive the JSON round trip through the gateway.
+	MaxAmount = 1<<53 - 1
+
+	// MaxMemoLength is the longest memo accepted, in bytes
+	MaxMemoLength = 256
+)
+
+// ErrInvalidArgument is matched by errors.Is for every *Error
+var ErrInvalidArgument = errors.New("invalid argument")
+
+// Error reports an argument that failed validation
+type Error struct {
+	Field  string
+	Reason string
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("invalid %s: %s", e.Field, e.Reason)
+}
+
+// Is reports whether target is ErrInvalidArgument
+func (e *Error) Is(target error) bool {
+	return target == ErrInvalidArgument
+}
+
+// ID checks an account, type or transaction id: 1 to MaxIDLength
+// characters from A-Z, a-z, 0-9 and "-_.@". Restricting the charset keeps
+// ids out of the composite key namespace, which starts with U+0000.
+func ID(field string, id string) error {
+	if id == "" {
+		return &Error{field, "must not be empty"}
+	}
+	if len(id) > MaxIDLength {
+		return &Error{field, fmt.Sprintf("must be at most %d characters", MaxIDLength)}
+	}
+	for i := 0; i < len(id); i++ {
+		if !idChar(id[i]) {
+			return &Error{field, fmt.Sprintf("character %q not allowed, use letters, digits and -_.@", id[i:i+1])}
+		}
+	}
+
+	return nil
+}
+
+// AccountID checks the id of a new account. Besides ID's rules, it must not
+// start with "_", which CouchDB reserves for its own documents, or look
+// like a peer-generated transaction id, the key of the transaction record.
+func AccountID(field string, id string) error {
+	if err := ID(field, id); err != nil {
+		return err
+	}
+	if id[0] == '_' {
+		return &Error{field, `must not start with "_", which is reserved`}
+	}
+	if LooksLikeTxID(id) {
+		return &Error{field, "must not look like a transaction id"}
+	}
+
+	return nil
+}
+
+// LooksLikeTxID reports whether id has the shape of a peer-generated
+// transaction id: 64 lowercase hex characters
+func LooksLikeTxID(id string) bool {
+	if len(id) != 64 {
+		return false
+	}
+	for i := 0; i < len(id); i++ {
+		c := id[i]
+		if !('0' <= c && c <= '9' || 'a' <= c && c <= 'f') {
+			return false
+		}
+	}
+
+	return true
+}
+
+func idChar(c byte) bool {
+	switch {
+	case 'a' <= c && c <= 'z', 'A' <= c && c <= 'Z', '0' <= c && c <= '9':
+		return true
+	case c == '-', c == '_', c == '.', c == '@':
+		return true
+	}
+	return false
+}
+
+// Amount checks a balance or transfer amount is between 0 and MaxAmount
+func Amount(field string, amount int) error {
+	if amount < 0 {
+		return &Error{field, "must not be negative"}
+	}
+	if int64(amount) > MaxAmount {
+		return &Error{field, fmt.Sprintf("must be at most %d", int64(MaxAmount))}
+	}
+
+	return nil
+}
+
+// Memo checks a free-text memo is valid UTF-8 of at most MaxMemoLength
+// bytes. An empty memo is allowed.
+func Memo(field string, memo string) error {
+	if !utf8.ValidString(memo) {
+		return &Error{field, "must be valid UTF-8"}
+	}
+	if len(memo) > MaxMemoLength {
+		return &Error{field, fmt.Sprintf("must be at most %d bytes", MaxMemoLength)}
+	}
+
+	return nil
+}
+
+// First returns the first non-nil error, so a method can check all its
+// arguments in one statement
+func First(errs ...error) error {
+	for _, err := range errs {
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,89 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package validation_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/kkiu1756/my_fabric/src/chaincode-go/validation"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestID(t *testing.T) {
+	tests := []struct {
+		id  string
+		err string
+	}{
+		{id: "alice"},
+		{id: "Org1.user-01_x@example.com"},
+		{id: strings.Repeat("a", validation.MaxIDLength)},
+		{id: "", err: "invalid id: must not be empty"},
+		{id: strings.Repeat("a", validation.MaxIDLength+1), err: "invalid id: must be at most 64 characters"},
+		{id: "alice bob", err: `invalid id: character " " not allowed, use letters, digits and -_.@`},
+		{id: "\x00balance\x00alice\x00", err: `invalid id: character "\x00" not allowed, use letters, digits and -_.@`},
+		{id: "caf\xc3\xa9", err: `invalid id: character "\xc3" not allowed, use letters, digits and -_.@`},
+	}
+
+	for _, tt := range tests {
+		err := validation.ID("id", tt.id)
+		if tt.err == "" {
+			assert.NoError(t, err, "id %q", tt.id)
+			continue
+		}
+		assert.EqualError(t, err, tt.err, "id %q", tt.id)
+		assert.True(t, errors.Is(err, validation.ErrInvalidArgument))
+	}
+}
+
+func TestAccountID(t *testing.T) {
+	txid := "47552c4081e0cd919ecd4d090d07293376b686ebd11db20e4934c54a661080f5"
+	tests := []struct {
+		id  string
+		err string
+	}{
+		{id: "alice"},
+		{id: "alice_"},
+		{id: strings.ToUpper(txid)},
+		{id: txid[:63]},
+		{id: strings.Repeat("g", validation.MaxIDLength)},
+		{id: "", err: "invalid id: must not be empty"},
+		{id: "_users", err: `invalid id: must not start with "_", which is reserved`},
+		{id: txid, err: "invalid id: must not look like a transaction id"},
+		{id: strings.Repeat("a", validation.MaxIDLength), err: "invalid id: must not look like a transaction id"},
+	}
+
+	for _, tt := range tests {
+		err := validation.AccountID("id", tt.id)
+		if tt.err == "" {
+			assert.NoError(t, err, "id %q", tt.id)
+			continue
+		}
+		assert.EqualError(t, err, tt.err, "id %q", tt.id)
+		assert.True(t, errors.Is(err, validation.ErrInvalidArgument))
+	}
+}
+
+func TestAmount(t *testing.T) {
+	assert.NoError(t, validation.Amount("value", 0))
+	assert.NoError(t, validation.Amount("value", validation.MaxAmount))
+	assert.EqualError(t, validation.Amount("value", -1), "invalid value: must not be negative")
+	assert.EqualError(t, validation.Amount("value", validation.MaxAmount+1), "invalid value: must be at most 9007199254740991")
+}
+
+func TestMemo(t *testing.T) {
+	assert.NoError(t, validation.Memo("memo", ""))
+	assert.NoError(t, validation.Memo("memo", "rent für März"))
+	assert.NoError(t, validation.Memo("memo", strings.Repeat("x", validation.MaxMemoLength)))
+	assert.EqualError(t, validation.Memo("memo", strings.Repeat("x", validation.MaxMemoLength+1)), "invalid memo: must be at most 256 bytes")
+	assert.EqualError(t, validation.Memo("memo", "\xff"), "invalid memo: must be valid UTF-8")
+}
+
+func TestFirst(t *testing.T) {
+	assert.NoError(t, validation.First())
+	assert.NoError(t, validation.First(nil, nil))
+	assert.EqualError(t, validation.First(nil, validation.ID("to", ""), validation.Amount("value", -1)), "invalid to: must not be empty")
+}
@@ -0,0 +1,241 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+*/
+
+// Package blocks turns full block events delivered by a peer into the valid
+// token transactions they contain and hands them to a pluggable Sink.
+package blocks
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/hyperledger/fabric-protos-go/common"
+	"github.com/hyperledger/fabric-protos-go/ledger/rwset"
+	"github.com/hyperledger/fabric-protos-go/ledger/rwset/kvrwset"
+	"github.com/hyperledger/fabric-protos-go/peer"
+)
+
+// Event is a chaincode event emitted by a token transaction
+type Event struct {
+	Name    string `json:"name"`
+	Payload []byte `json:"payload"`
+}
+
+// Write is a single key written by a token transaction
+type Write struct {
+	Key      string `json:"key"`
+	Value    []byte `json:"value,omitempty"`
+	IsDelete bool   `json:"isDelete"`
+}
+
+// Read is a single key read by a token transaction, with the version it saw
+type Read struct {
+	Key         string `json:"key"`
+	BlockNumber uint64 `json:"blockNumber"`
+	TxNumber    uint64 `json:"txNumber"`
+}
+
+// Transaction is a valid transaction that invoked the token chaincode
+type Transaction struct {
+	BlockNumber uint64    `json:"blockNumber"`
+	TxID        string    `json:"txId"`
+	ChannelID   string    `json:"channelId"`
+	Timestamp   time.Time `json:"timestamp"`
+	Chaincode   string    `json:"chaincode"`
+	Events      []Event   `json:"events"`
+	Reads       []Read    `json:"reads"`
+	Writes      []Write   `json:"writes"`
+}
+
+// Block holds the token transactions extracted from a single block. Blocks
+// without token transactions are still delivered so sinks can track progress.
+type Block struct {
+	Number       uint64
+	PreviousHash []byte
+	DataHash     []byte
+	Transactions []Transaction
+	// Invalid counts token transactions that were rejected by the peer, of
+	// which MVCCConflicts failed with MVCC_READ_CONFLICT.
+	Invalid       int
+	MVCCConflicts int
+}
+
+// Sink receives the token transactions of every block in order
+type Sink interface {
+	HandleBlock(ctx context.Context, block *Block) error
+}
+
+// SinkFunc adapts an ordinary function to the Sink interface
+type SinkFunc func(ctx context.Context, block *Block) error
+
+// HandleBlock calls f(ctx, block)
+func (f SinkFunc) HandleBlock(ctx context.Context, block *Block) error {
+	return f(ctx, block)
+}
+
+// ParseBlock extracts the transactions that invoked chaincodeName from block.
+// Transactions the committing peer marked invalid are counted but skipped.
+func ParseBlock(block *common.Block, chaincodeName string) (*Block, error) {
+	if block.GetHeader() == nil {
+		return nil, fmt.Errorf("block has no header")
+	}
+
+	result := &Block{
+		Number:       block.Header.Number,
+		PreviousHash: block.Header.PreviousHash,
+		DataHash:     block.Header.DataHash,
+	}
+	filter := transactionFilter(block)
+
+	for i, envelopeBytes := range block.GetData().GetData() {
+		tx, err := parseEnvelope(envelopeBytes, chaincodeName)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse transaction %d of block %d: %v", i, result.Number, err)
+		}
+		if tx == nil {
+			continue
+		}
+
+		if i < len(filter) && peer.TxValidationCode(filter[i]) != peer.TxValidationCode_VALID {
+			result.Invalid++
+			if peer.TxValidationCode(filter[i]) == peer.TxValidationCode_MVCC_READ_CONFLICT {
+				result.MVCCConflicts++
+			}
+			continue
+		}
+
+		tx.BlockNumber = result.Number
+		result.Transactions = append(result.Transactions, *tx)
+	}
+
+	return result, nil
+}
+
+func transactionFilter(block *common.Block) []byte {
+	metadata := block.GetMetadata().GetMetadata()
+	if len(metadata) <= int(common.BlockMetadataIndex_TRANSACTIONS_FILTER) {
+		return nil
+	}
+
+	return metadata[common.BlockMetadataIndex_TRANSACTIONS_FILTER]
+}
+
+// parseEnvelope returns nil without error for transactions that are not
+// endorser transactions against chaincodeName
+func parseEnvelope(envelopeBytes []byte, chaincodeName string) (*Transaction, error) {
+	envelope := &common.Envelope{}
+	if err := proto.Unmarshal(envelopeBytes, envelope); err != nil {
+		return nil, err
+	}
+
+	payload := &common.Payload{}
+	if err := proto.Unmarshal(envelope.Payload, payload); err != nil {
+		return nil, err
+	}
+
+	channelHeader := &common.ChannelHeader{}
+	if err := proto.Unmarshal(payload.GetHeader().GetChannelHeader(), channelHeader); err != nil {
+		return nil, err
+	}
+	if common.HeaderType(channelHeader.Type) != common.HeaderType_ENDORSER_TRANSACTION {
+		return nil, nil
+	}
+
+	transaction := &peer.Transaction{}
+	if err := proto.Unmarshal(payload.Data, transaction); err != nil {
+		return nil, err
+	}
+
+	var tx *Transaction
+	for _, action := range transaction.Actions {
+		chaincodeAction, err := unmarshalChaincodeAction(action)
+		if err != nil {
+			return nil, err
+		}
+		if chaincodeAction.GetChaincodeId().GetName() != chaincodeName {
+			continue
+		}
+
+		if tx == nil {
+			tx = &Transaction{
+				TxID:      channelHeader.TxId,
+				ChannelID: channelHeader.ChannelId,
+				Chaincode: chaincodeName,
+			}
+			if ts := channelHeader.GetTimestamp(); ts != nil {
+				tx.Timestamp = time.Unix(ts.Seconds, int64(ts.Nanos)).UTC()
+			}
+		}
+
+		if err := appendResults(tx, chaincodeAction, chaincodeName); err != nil {
+			return nil, err
+		}
+	}
+
+	return tx, nil
+}
+
+func unmarshalChaincodeAction(action *peer.TransactionAction) (*peer.ChaincodeAction, error) {
+	actionPayload := &peer.ChaincodeActionPayload{}
+	if err := proto.Unmarshal(action.Payload, actionPayload); err != nil {
+		return nil, err
+	}
+
+	responsePayload := &peer.ProposalResponsePayload{}
+	if err := proto.Unmarshal(actionPayload.GetAction().GetProposalResponsePayload(), responsePayload); err != nil {
+		return nil, err
+	}
+
+	chaincodeAction := &peer.ChaincodeAction{}
+	if err := proto.Unmarshal(responsePayload.Extension, chaincodeAction); err != nil {
+		return nil, err
+	}
+
+	return chaincodeAction, nil
+}
+
+func appendResults(tx *Transaction, action *peer.ChaincodeAction, chaincodeName string) error {
+	if len(action.Events) > 0 {
+		chaincodeEvent := &peer.ChaincodeEvent{}
+		if err := proto.Unmarshal(action.Events, chaincodeEvent); err != nil {
+			return err
+		}
+		if chaincodeEvent.EventName != "" {
+			tx.Events = append(tx.Events, Event{Name: chaincodeEvent.EventName, Payload: chaincodeEvent.Payload})
+		}
+	}
+
+	txRWSet := &rwset.TxReadWriteSet{}
+	if err := proto.Unmarshal(action.Results, txRWSet); err != nil {
+		return err
+	}
+
+	for _, nsRWSet := range txRWSet.NsRwset {
+		if nsRWSet.Namespace != chaincodeName {
+			continue
+		}
+
+		kvRWSet := &kvrwset.KVRWSet{}
+		if err := proto.Unmarshal(nsRWSet.Rwset, kvRWSet); err != nil {
+			return err
+		}
+
+		for _, read := range kvRWSet.Reads {
+			r := Read{Key: read.Key}
+			if read.Version != nil {
+				r.BlockNumber = read.Version.BlockNum
+				r.TxNumber = read.Version.TxNum
+			}
+			tx.Reads = append(tx.Reads, r)
+		}
+
+		for _, write := range kvRWSet.Writes {
+			tx.Writes = append(tx.Writes, Write{Key: write.Key, Value: write.Value, IsDelete: write.IsDelete})
+		}
+	}
+
+	return nil
+}
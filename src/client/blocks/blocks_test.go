@@ -0,0 +1,327 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package blocks
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/hyperledger/fabric-protos-go/common"
+	"github.com/hyperledger/fabric-protos-go/ledger/rwset"
+	"github.com/hyperledger/fabric-protos-go/ledger/rwset/kvrwset"
+	"github.com/hyperledger/fabric-protos-go/peer"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// #########
+// HELPERS
+// #########
+
+func marshal(t *testing.T, m proto.Message) []byte {
+	data, err := proto.Marshal(m)
+	require.NoError(t, err)
+	return data
+}
+
+func endorserTransaction(t *testing.T, txID string, chaincodeName string, eventName string, writes ...*kvrwset.KVWrite) []byte {
+	results := marshal(t, &rwset.TxReadWriteSet{
+		NsRwset: []*rwset.NsReadWriteSet{
+			{Namespace: chaincodeName, Rwset: marshal(t, &kvrwset.KVRWSet{Writes: writes})},
+		},
+	})
+
+	action := &peer.ChaincodeAction{
+		ChaincodeId: &peer.ChaincodeID{Name: chaincodeName},
+		Results:     results,
+	}
+	if eventName != "" {
+		action.Events = marshal(t, &peer.ChaincodeEvent{EventName: eventName, Payload: []byte(`{}`), TxId: txID})
+	}
+
+	actionPayload := marshal(t, &peer.ChaincodeActionPayload{
+		Action: &peer.ChaincodeEndorsedAction{
+			ProposalResponsePayload: marshal(t, &peer.ProposalResponsePayload{Extension: marshal(t, action)}),
+		},
+	})
+
+	return envelope(t, common.HeaderType_ENDORSER_TRANSACTION, txID, marshal(t, &peer.Transaction{
+		Actions: []*peer.TransactionAction{{Payload: actionPayload}},
+	}))
+}
+
+func envelope(t *testing.T, headerType common.HeaderType, txID string, data []byte) []byte {
+	payload := marshal(t, &common.Payload{
+		Header: &common.Header{
+			ChannelHeader: marshal(t, &common.ChannelHeader{Type: int32(headerType), TxId: txID, ChannelId: "mychannel"}),
+		},
+		Data: data,
+	})
+
+	return marshal(t, &common.Envelope{Payload: payload})
+}
+
+func newBlock(number uint64, codes []peer.TxValidationCode, envelopes ...[]byte) *common.Block {
+	filter := make([]byte, len(codes))
+	for i, code := range codes {
+		filter[i] = byte(code)
+	}
+
+	metadata := make([][]byte, common.BlockMetadataIndex_TRANSACTIONS_FILTER+1)
+	metadata[common.BlockMetadataIndex_TRANSACTIONS_FILTER] = filter
+
+	return &common.Block{
+		Header:   &common.BlockHeader{Number: number},
+		Data:     &common.BlockData{Data: envelopes},
+		Metadata: &common.BlockMetadata{Metadata: metadata},
+	}
+}
+
+type sliceSource struct {
+	blocks []*common.Block
+	from   uint64
+}
+
+func (s *sliceSource) Blocks(ctx context.Context, from uint64) (<-chan *common.Block, error) {
+	s.from = from
+	ch := make(chan *common.Block, len(s.blocks))
+	for _, b := range s.blocks {
+		ch <- b
+	}
+	close(ch)
+	return ch, nil
+}
+
+type memoryCheckpointer struct {
+	next uint64
+}
+
+func (c *memoryCheckpointer) NextBlock(ctx context.Context) (uint64, error) {
+	return c.next, nil
+}
+
+func (c *memoryCheckpointer) SaveNextBlock(ctx context.Context, block uint64) error {
+	c.next = block
+	return nil
+}
+
+// #########
+// TESTS
+// #########
+
+func TestParseBlock(t *testing.T) {
+	block := newBlock(7,
+		[]peer.TxValidationCode{peer.TxValidationCode_VALID, peer.TxValidationCode_MVCC_READ_CONFLICT, peer.TxValidationCode_VALID, peer.TxValidationCode_VALID},
+		endorserTransaction(t, "tx1", "basic", "Transfer", &kvrwset.KVWrite{Key: "alice", Value: []byte(`{"balance":1}`)}),
+		endorserTransaction(t, "tx2", "basic", "Transfer", &kvrwset.KVWrite{Key: "bob"}),
+		endorserTransaction(t, "tx3", "othercc", "Other"),
+		envelope(t, common.HeaderType_CONFIG, "", nil),
+	)
+
+	parsed, err := ParseBlock(block, "basic")
+	require.NoError(t, err)
+
+	assert.Equal(t, uint64(7), parsed.Number, "should keep the block number")
+	assert.Equal(t, 1, parsed.Invalid, "should count the invalidated token transaction")
+	assert.Equal(t, 1, parsed.MVCCConflicts, "should count the MVCC conflict")
+	require.Len(t, parsed.Transactions, 1, "should only return valid token transactions")
+
+	tx := parsed.Transactions[0]
+	assert.Equal(t, "tx1", tx.TxID)
+	assert.Equal(t, "mychannel", tx.ChannelID)
+	assert.Equal(t, uint64(7), tx.BlockNumber)
+	assert.Equal(t, []Event{{Name: "Transfer", Payload: []byte(`{}`)}}, tx.Events)
+	assert.Equal(t, []Write{{Key: "alice", Value: []byte(`{"balance":1}`)}}, tx.Writes)
+
+	_, err = ParseBlock(&common.Block{}, "basic")
+	assert.EqualError(t, err, "block has no header", "should reject blocks without header")
+
+	_, err = ParseBlock(newBlock(1, nil, []byte("garbage")), "basic")
+	assert.Error(t, err, "should fail on malformed envelopes")
+}
+
+func TestListenerRun(t *testing.T) {
+	source := &sliceSource{blocks: []*common.Block{
+		newBlock(4, nil),
+		newBlock(5, []peer.TxValidationCode{peer.TxValidationCode_VALID}, endorserTransaction(t, "tx1", "basic", "Transfer")),
+		newBlock(6, nil),
+	}}
+	checkpointer := &memoryCheckpointer{next: 5}
+
+	var handled []uint64
+	sink := SinkFunc(func(ctx context.Context, block *Block) error {
+		handled = append(handled, block.Number)
+		return nil
+	})
+
+	metrics := NewMetrics(prometheus.NewRegistry())
+	err := NewListener(source, "basic", sink, checkpointer, WithMetrics(metrics)).Run(context.Background())
+	assert.NoError(t, err, "should stop cleanly when the source closes")
+	assert.Equal(t, float64(2), testutil.ToFloat64(metrics.blocks), "should count processed blocks")
+	assert.Equal(t, float64(1), testutil.ToFloat64(metrics.transactions), "should count delivered transactions")
+	assert.Equal(t, float64(6), testutil.ToFloat64(metrics.lastBlock), "should track the last block")
+	assert.Equal(t, uint64(5), source.from, "should subscribe from the checkpoint")
+	assert.Equal(t, []uint64{5, 6}, handled, "should skip blocks before the checkpoint")
+	assert.Equal(t, uint64(7), checkpointer.next, "should checkpoint after every block")
+
+	checkpointer = &memoryCheckpointer{next: 5}
+	sink = SinkFunc(func(ctx context.Context, block *Block) error {
+		return errors.New("sink error")
+	})
+	err = NewListener(source, "basic", sink, checkpointer).Run(context.Background())
+	assert.EqualError(t, err, "sink failed on block 5: sink error", "should stop when the sink fails")
+	assert.Equal(t, uint64(5), checkpointer.next, "should not advance the checkpoint past a failed block")
+}
+
+func TestListenerRetry(t *testing.T) {
+	source := &sliceSource{blocks: []*common.Block{newBlock(5, nil), newBlock(6, nil)}}
+	checkpointer := &memoryCheckpointer{next: 5}
+
+	failures := map[uint64]int{5: 2}
+	sink := SinkFunc(func(ctx context.Context, block *Block) error {
+		if failures[block.Number] > 0 {
+			failures[block.Number]--
+			return errors.New("sink error")
+		}
+		return nil
+	})
+
+	metrics := NewMetrics(prometheus.NewRegistry())
+	policy := RetryPolicy{Attempts: 3, Backoff: time.Millisecond, MaxBackoff: 2 * time.Millisecond}
+	err := NewListener(source, "basic", sink, checkpointer, WithMetrics(metrics), WithRetry(policy)).Run(context.Background())
+	assert.NoError(t, err, "should succeed within the attempts")
+	assert.Equal(t, float64(2), testutil.ToFloat64(metrics.deferred), "should count the retried failures")
+	assert.Equal(t, float64(2), testutil.ToFloat64(metrics.blocks))
+	assert.Equal(t, uint64(7), checkpointer.next)
+
+	checkpointer = &memoryCheckpointer{next: 5}
+	failures = map[uint64]int{5: 3}
+	err = NewListener(source, "basic", sink, checkpointer, WithRetry(policy)).Run(context.Background())
+	assert.EqualError(t, err, "sink failed on block 5: sink error", "should stop once the attempts are exhausted")
+	assert.Equal(t, uint64(5), checkpointer.next)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	sink = SinkFunc(func(ctx context.Context, block *Block) error {
+		cancel()
+		return errors.New("sink error")
+	})
+	err = NewListener(source, "basic", sink, checkpointer, WithRetry(RetryPolicy{Attempts: 3, Backoff: time.Hour})).Run(ctx)
+	assert.Equal(t, context.Canceled, err, "should stop waiting for a retry when cancelled")
+
+	assert.Equal(t, []time.Duration{time.Second, 2 * time.Second, 4 * time.Second, 5 * time.Second, 5 * time.Second}, []time.Duration{
+		RetryPolicy{Backoff: time.Second, MaxBackoff: 5 * time.Second}.backoff(1),
+		RetryPolicy{Backoff: time.Second, MaxBackoff: 5 * time.Second}.backoff(2),
+		RetryPolicy{Backoff: time.Second, MaxBackoff: 5 * time.Second}.backoff(3),
+		RetryPolicy{Backoff: time.Second, MaxBackoff: 5 * time.Second}.backoff(4),
+		RetryPolicy{Backoff: time.Second, MaxBackoff: 5 * time.Second}.backoff(40),
+	}, "should double the backoff up to the maximum")
+}
+
+func TestListenerDeadLetters(t *testing.T) {
+	source := &sliceSource{blocks: []*common.Block{
+		newBlock(5, nil),
+		newBlock(6, nil, []byte("garbage")),
+		newBlock(7, nil),
+	}}
+	checkpointer := &memoryCheckpointer{next: 5}
+
+	var handled []uint64
+	sink := SinkFunc(func(ctx context.Context, block *Block) error {
+		if block.Number == 5 {
+			return errors.New("sink error")
+		}
+		handled = append(handled, block.Number)
+		return nil
+	})
+	var letters []*DeadLetter
+	deadLetters := DeadLetterFunc(func(ctx context.Context, letter *DeadLetter) error {
+		letters = append(letters, letter)
+		return nil
+	})
+
+	metrics := NewMetrics(prometheus.NewRegistry())
+	err := NewListener(source, "basic", sink, checkpointer, WithMetrics(metrics), WithRetry(RetryPolicy{Attempts: 2}), WithDeadLetters(deadLetters)).Run(context.Background())
+	assert.NoError(t, err, "should move past the blocks it gives up on")
+	assert.Equal(t, []uint64{7}, handled)
+	assert.Equal(t, uint64(8), checkpointer.next)
+	assert.Equal(t, float64(2), testutil.ToFloat64(metrics.dropped), "should count the dead letters")
+	assert.Equal(t, float64(1), testutil.ToFloat64(metrics.deferred))
+	assert.Equal(t, float64(1), testutil.ToFloat64(metrics.blocks), "should not count dead letters as processed")
+
+	require.Len(t, letters, 2)
+	assert.Equal(t, uint64(5), letters[0].Number)
+	assert.Equal(t, 2, letters[0].Attempts)
+	assert.EqualError(t, letters[0].Err, "sink error")
+	assert.Equal(t, uint64(5), letters[0].Block.Number)
+	assert.Equal(t, uint64(6), letters[1].Number)
+	assert.Equal(t, 0, letters[1].Attempts, "should not hand a block that cannot be parsed to the sink")
+	assert.Nil(t, letters[1].Block)
+	assert.Same(t, source.blocks[1], letters[1].Raw)
+
+	checkpointer = &memoryCheckpointer{next: 5}
+	deadLetters = DeadLetterFunc(func(ctx context.Context, letter *DeadLetter) error {
+		return errors.New("disk full")
+	})
+	err = NewListener(source, "basic", sink, checkpointer, WithDeadLetters(deadLetters)).Run(context.Background())
+	assert.EqualError(t, err, "dead-letter sink failed on block 5: disk full", "should stop when the dead-letter sink fails")
+	assert.Equal(t, uint64(5), checkpointer.next)
+}
+
+func TestFileDeadLetters(t *testing.T) {
+	dir, err := ioutil.TempDir("", "deadletters")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "deadletters.jsonl")
+	deadLetters := NewFileDeadLetters(path)
+	block := newBlock(5, nil)
+	require.NoError(t, deadLetters.HandleDeadLetter(context.Background(), &DeadLetter{Number: 5, Raw: block, Attempts: 3, Err: errors.New("sink error")}))
+	require.NoError(t, deadLetters.HandleDeadLetter(context.Background(), &DeadLetter{Number: 6, Raw: newBlock(6, nil), Err: errors.New("bad block")}))
+
+	data, err := ioutil.ReadFile(path)
+	require.NoError(t, err)
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	require.Len(t, lines, 2, "should append a line per dead letter")
+
+	var line deadLetterLine
+	require.NoError(t, json.Unmarshal([]byte(lines[0]), &line))
+	assert.Equal(t, uint64(5), line.Number)
+	assert.Equal(t, 3, line.Attempts)
+	assert.Equal(t, "sink error", line.Error)
+	raw := &common.Block{}
+	require.NoError(t, proto.Unmarshal(line.Block, raw))
+	assert.True(t, proto.Equal(block, raw), "should keep the raw block")
+}
+
+func TestFileCheckpointer(t *testing.T) {
+	dir, err := ioutil.TempDir("", "checkpoint")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	checkpointer := NewFileCheckpointer(filepath.Join(dir, "nextblock.txt"))
+
+	next, err := checkpointer.NextBlock(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(0), next, "should start at block 0 without a checkpoint file")
+
+	require.NoError(t, checkpointer.SaveNextBlock(context.Background(), 42))
+	next, err = checkpointer.NextBlock(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(42), next, "should read back the saved checkpoint")
+
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "nextblock.txt"), []byte("abc"), 0600))
+	_, err = checkpointer.NextBlock(context.Background())
+	assert.Error(t, err, "should reject a corrupt checkpoint file")
+}
@@ -0,0 +1,155 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package blocks
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/kkiu1756/my_fabric/src/client/internal/sqlutil"
+)
+
+// Checkpointer durably records the next block a listener should process, so
+// a restarted listener resumes where it stopped instead of replaying the chain
+type Checkpointer interface {
+	NextBlock(ctx context.Context) (uint64, error)
+	SaveNextBlock(ctx context.Context, block uint64) error
+}
+
+// FileCheckpointer stores the next block number in a text file
+type FileCheckpointer struct {
+	path string
+}
+
+// NewFileCheckpointer returns a checkpointer backed by the file at path. A
+// missing file means the listener starts from block 0.
+func NewFileCheckpointer(path string) *FileCheckpointer {
+	return &FileCheckpointer{path: path}
+}
+
+// NextBlock reads the next block number from the checkpoint file
+func (c *FileCheckpointer) NextBlock(ctx context.Context) (uint64, error) {
+	data, err := ioutil.ReadFile(filepath.Clean(c.path))
+	if os.IsNotExist(err) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to read checkpoint file: %v", err)
+	}
+
+	block, err := strconv.ParseUint(strings.TrimSpace(string(data)), 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid checkpoint in %s: %v", c.path, err)
+	}
+
+	return block, nil
+}
+
+// SaveNextBlock replaces the checkpoint file atomically, so a crash midway
+// never leaves a truncated checkpoint behind
+func (c *FileCheckpointer) SaveNextBlock(ctx context.Context, block uint64) error {
+	tmp, err := ioutil.TempFile(filepath.Dir(c.path), filepath.Base(c.path)+".tmp")
+	if err != nil {
+		return fmt.Errorf("failed to create checkpoint file: %v", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.WriteString(strconv.FormatUint(block, 10)); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write checkpoint file: %v", err)
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to sync checkpoint file: %v", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to close checkpoint file: %v", err)
+	}
+
+	if err := os.Rename(tmp.Name(), c.path); err != nil {
+		return fmt.Errorf("failed to replace checkpoint file: %v", err)
+	}
+
+	return nil
+}
+
+// SQLCheckpointer stores the next block number in a database table keyed by
+// listener name, so several listeners can share one database
+type SQLCheckpointer struct {
+	db       *sql.DB
+	name     string
+	numbered bool
+}
+
+// NewSQLCheckpointer returns a checkpointer for the listener called name.
+// Set numberedPlaceholders for drivers that expect $1 style parameters
+// (e.g. PostgreSQL) rather than ?.
+func NewSQLCheckpointer(db *sql.DB, name string, numberedPlaceholders bool) *SQLCheckpointer {
+	return &SQLCheckpointer{db: db, name: name, numbered: numberedPlaceholders}
+}
+
+// CreateTable creates the checkpoint table if it does not exist yet
+func (c *SQLCheckpointer) CreateTable(ctx context.Context) error {
+	_, err := c.db.ExecContext(ctx, `CREATE TABLE IF NOT EXISTS block_checkpoints (
+		listener VARCHAR(255) PRIMARY KEY,
+		next_block BIGINT NOT NULL
+	)`)
+	if err != nil {
+		return fmt.Errorf("failed to create checkpoint table: %v", err)
+	}
+
+	return nil
+}
+
+// NextBlock reads the next block number for this listener
+func (c *SQLCheckpointer) NextBlock(ctx context.Context) (uint64, error) {
+	var block int64
+	err := c.db.QueryRowContext(ctx, c.query("SELECT next_block FROM block_checkpoints WHERE listener = ?"), c.name).Scan(&block)
+	if err == sql.ErrNoRows {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to read checkpoint: %v", err)
+	}
+
+	return uint64(block), nil
+}
+
+// SaveNextBlock updates the checkpoint row, inserting it on first use
+func (c *SQLCheckpointer) SaveNextBlock(ctx context.Context, block uint64) error {
+	tx, err := c.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin checkpoint transaction: %v", err)
+	}
+	defer tx.Rollback()
+
+	result, err := tx.ExecContext(ctx, c.query("UPDATE block_checkpoints SET next_block = ? WHERE listener = ?"), int64(block), c.name)
+	if err != nil {
+		return fmt.Errorf("failed to update checkpoint: %v", err)
+	}
+
+	updated, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to update checkpoint: %v", err)
+	}
+	if updated == 0 {
+		_, err = tx.ExecContext(ctx, c.query("INSERT INTO block_checkpoints (next_block, listener) VALUES (?, ?)"), int64(block), c.name)
+		if err != nil {
+			return fmt.Errorf("failed to insert checkpoint: %v", err)
+		}
+	}
+
+	return tx.Commit()
+}
+
+func (c *SQLCheckpointer) query(q string) string {
+	return sqlutil.Rebind(c.numbered, q)
+}
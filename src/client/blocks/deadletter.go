@@ -0,0 +1,149 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package blocks
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/hyperledger/fabric-protos-go/common"
+)
+
+// RetryPolicy decides how often a Listener hands a block to a failing sink
+// before giving up on it
+type RetryPolicy struct {
+	// Attempts is how many times the sink is given a block; less than 1
+	// means once
+	Attempts int
+	// Backoff is the wait before the first retry, doubled before every
+	// further retry up to MaxBackoff if that is set
+	Backoff    time.Duration
+	MaxBackoff time.Duration
+}
+
+func (p RetryPolicy) attempts() int {
+	if p.Attempts < 1 {
+		return 1
+	}
+	return p.Attempts
+}
+
+// backoff returns the wait after the failed attempt numbered attempt
+func (p RetryPolicy) backoff(attempt int) time.Duration {
+	wait := p.Backoff
+	for i := 1; i < attempt && wait > 0; i++ {
+		if p.MaxBackoff > 0 && wait >= p.MaxBackoff {
+			break
+		}
+		wait *= 2
+	}
+	if p.MaxBackoff > 0 && wait > p.MaxBackoff {
+		wait = p.MaxBackoff
+	}
+
+	return wait
+}
+
+// DeadLetter is a block a Listener gave up on: one the sink kept failing
+// on, or one that could not be parsed
+type DeadLetter struct {
+	Number uint64
+	// Block is the parsed block, nil if parsing failed
+	Block *Block
+	// Raw is the block as the source delivered it
+	Raw *common.Block
+	// Attempts counts the sink's attempts, 0 if parsing failed
+	Attempts int
+	Err      error
+}
+
+// DeadLetterSink receives the blocks a Listener gives up on. A listener
+// with a dead-letter sink moves past such a block once the dead-letter sink
+// accepted it; a dead-letter sink failure stops the listener.
+type DeadLetterSink interface {
+	HandleDeadLetter(ctx context.Context, letter *DeadLetter) error
+}
+
+// DeadLetterFunc adapts an ordinary function to the DeadLetterSink interface
+type DeadLetterFunc func(ctx context.Context, letter *DeadLetter) error
+
+// HandleDeadLetter calls f(ctx, letter)
+func (f DeadLetterFunc) HandleDeadLetter(ctx context.Context, letter *DeadLetter) error {
+	return f(ctx, letter)
+}
+
+// FileDeadLetters appends dead letters to a file as JSON lines holding the
+// block number, the attempts, the error and the raw block, protobuf encoded,
+// so the blocks can be inspected and fed to the sink again
+type FileDeadLetters struct {
+	path string
+	mu   sync.Mutex
+}
+
+// NewFileDeadLetters returns a dead-letter sink appending to the file at
+// path, which is created on the first dead letter
+func NewFileDeadLetters(path string) *FileDeadLetters {
+	return &FileDeadLetters{path: path}
+}
+
+// deadLetterLine is the JSON line of a dead letter
+type deadLetterLine struct {
+	Number   uint64 `json:"number"`
+	Attempts int    `json:"attempts"`
+	Error    string `json:"error"`
+	Block    []byte `json:"block"`
+}
+
+// HandleDeadLetter appends letter to the file and syncs it
+func (d *FileDeadLetters) HandleDeadLetter(ctx context.Context, letter *DeadLetter) error {
+	raw, err := proto.Marshal(letter.Raw)
+	if err != nil {
+		return fmt.Errorf("failed to encode block %d: %v", letter.Number, err)
+	}
+	line, err := json.Marshal(deadLetterLine{Number: letter.Number, Attempts: letter.Attempts, Error: fmt.Sprint(letter.Err), Block: raw})
+	if err != nil {
+		return fmt.Errorf("failed to encode dead letter of block %d: %v", letter.Number, err)
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	f, err := os.OpenFile(filepath.Clean(d.path), os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return fmt.Errorf("failed to open dead-letter file: %v", err)
+	}
+	if _, err := f.Write(append(line, '\n')); err != nil {
+		f.Close()
+		return fmt.Errorf("failed to write dead-letter file: %v", err)
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		return fmt.Errorf("failed to sync dead-letter file: %v", err)
+	}
+
+	return f.Close()
+}
+
+// sleep waits for d or until ctx is cancelled
+func sleep(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return ctx.Err()
+	}
+
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
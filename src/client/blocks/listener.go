@@ -0,0 +1,168 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package blocks
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/hyperledger/fabric-protos-go/common"
+)
+
+// Source delivers full blocks in order starting at a given block number. The
+// channel is closed when the source stops delivering.
+type Source interface {
+	Blocks(ctx context.Context, from uint64) (<-chan *common.Block, error)
+}
+
+// Listener feeds the token transactions of every block delivered by a Source
+// into a Sink, advancing the checkpoint only after the sink accepted a block.
+// A block the sink fails on is retried as the RetryPolicy allows. Without a
+// DeadLetterSink a block that still fails, or cannot be parsed, stops the
+// listener; on restart the same block is delivered again, so sinks must
+// tolerate seeing a block more than once. With one, the block is handed to
+// it instead and the listener moves on.
+type Listener struct {
+	source        Source
+	sink          Sink
+	checkpointer  Checkpointer
+	chaincodeName string
+	metrics       *Metrics
+	retry         RetryPolicy
+	deadLetters   DeadLetterSink
+}
+
+// Option configures optional Listener behaviour
+type Option func(*Listener)
+
+// WithMetrics records listener progress in m
+func WithMetrics(m *Metrics) Option {
+	return func(l *Listener) {
+		l.metrics = m
+	}
+}
+
+// WithRetry retries a block the sink fails on according to p
+func WithRetry(p RetryPolicy) Option {
+	return func(l *Listener) {
+		l.retry = p
+	}
+}
+
+// WithDeadLetters hands the blocks the listener gives up on to d instead of
+// stopping
+func WithDeadLetters(d DeadLetterSink) Option {
+	return func(l *Listener) {
+		l.deadLetters = d
+	}
+}
+
+// NewListener creates a listener for transactions against chaincodeName
+func NewListener(source Source, chaincodeName string, sink Sink, checkpointer Checkpointer, opts ...Option) *Listener {
+	l := &Listener{
+		source:        source,
+		sink:          sink,
+		checkpointer:  checkpointer,
+		chaincodeName: chaincodeName,
+	}
+	for _, opt := range opts {
+		opt(l)
+	}
+
+	return l
+}
+
+// Run processes blocks until ctx is cancelled, the source closes or a block
+// cannot be handled
+func (l *Listener) Run(ctx context.Context) error {
+	next, err := l.checkpointer.NextBlock(ctx)
+	if err != nil {
+		return err
+	}
+
+	blocks, err := l.source.Blocks(ctx, next)
+	if err != nil {
+		return fmt.Errorf("failed to subscribe from block %d: %v", next, err)
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case block, ok := <-blocks:
+			if !ok {
+				return nil
+			}
+			if block.GetHeader().GetNumber() < next {
+				continue
+			}
+
+			if err := l.process(ctx, block); err != nil {
+				return err
+			}
+			next = block.Header.Number + 1
+		}
+	}
+}
+
+func (l *Listener) process(ctx context.Context, block *common.Block) error {
+	parsed, err := ParseBlock(block, l.chaincodeName)
+	if err != nil {
+		if l.deadLetters == nil {
+			return err
+		}
+		return l.deadLetter(ctx, &DeadLetter{Number: block.GetHeader().GetNumber(), Raw: block, Err: err})
+	}
+
+	attempts, err := l.handle(ctx, parsed)
+	if err != nil {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if l.deadLetters == nil {
+			return fmt.Errorf("sink failed on block %d: %v", parsed.Number, err)
+		}
+		return l.deadLetter(ctx, &DeadLetter{Number: parsed.Number, Block: parsed, Raw: block, Attempts: attempts, Err: err})
+	}
+
+	if err := l.checkpointer.SaveNextBlock(ctx, parsed.Number+1); err != nil {
+		return fmt.Errorf("failed to checkpoint block %d: %v", parsed.Number, err)
+	}
+	l.metrics.observe(parsed, time.Now())
+
+	return nil
+}
+
+// handle hands block to the sink until it accepts it or the retry policy
+// is exhausted, returning the attempts made
+func (l *Listener) handle(ctx context.Context, block *Block) (int, error) {
+	attempts := l.retry.attempts()
+	for attempt := 1; ; attempt++ {
+		err := l.sink.HandleBlock(ctx, block)
+		if err == nil || attempt >= attempts {
+			return attempt, err
+		}
+		l.metrics.deferBlock()
+		if err := sleep(ctx, l.retry.backoff(attempt)); err != nil {
+			return attempt, err
+		}
+	}
+}
+
+// deadLetter hands letter to the dead-letter sink and moves the checkpoint
+// past its block
+func (l *Listener) deadLetter(ctx context.Context, letter *DeadLetter) error {
+	if err := l.deadLetters.HandleDeadLetter(ctx, letter); err != nil {
+		return fmt.Errorf("dead-letter sink failed on block %d: %v", letter.Number, err)
+	}
+
+	if err := l.checkpointer.SaveNextBlock(ctx, letter.Number+1); err != nil {
+		return fmt.Errorf("failed to checkpoint block %d: %v", letter.Number, err)
+	}
+	l.metrics.drop()
+
+	return nil
+}
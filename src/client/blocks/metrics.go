@@ -0,0 +1,119 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package blocks
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Metrics holds the Prometheus collectors updated by a Listener
+type Metrics struct {
+	blocks        prometheus.Counter
+	transactions  prometheus.Counter
+	invalid       prometheus.Counter
+	mvccConflicts prometheus.Counter
+	lastBlock     prometheus.Gauge
+	eventLag      prometheus.Gauge
+	deferred      prometheus.Counter
+	dropped       prometheus.Counter
+}
+
+// NewMetrics creates the listener collectors and registers them with reg
+func NewMetrics(reg prometheus.Registerer) *Metrics {
+	m := &Metrics{
+		blocks: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: "token",
+			Subsystem: "listener",
+			Name:      "blocks_total",
+			Help:      "Blocks processed by the listener.",
+		}),
+		transactions: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: "token",
+			Subsystem: "listener",
+			Name:      "transactions_total",
+			Help:      "Valid token transactions delivered to the sink.",
+		}),
+		invalid: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: "token",
+			Subsystem: "listener",
+			Name:      "invalid_transactions_total",
+			Help:      "Token transactions invalidated by the committing peer.",
+		}),
+		mvccConflicts: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: "token",
+			Subsystem: "listener",
+			Name:      "mvcc_conflicts_total",
+			Help:      "Token transactions invalidated with MVCC_READ_CONFLICT.",
+		}),
+		lastBlock: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: "token",
+			Subsystem: "listener",
+			Name:      "last_block",
+			Help:      "Number of the last block handed to the sink.",
+		}),
+		eventLag: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: "token",
+			Subsystem: "listener",
+			Name:      "event_lag_seconds",
+			Help:      "Delay between the latest token transaction timestamp and its processing.",
+		}),
+		deferred: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: "token",
+			Subsystem: "listener",
+			Name:      "deferred_blocks_total",
+			Help:      "Sink failures on a block that were retried.",
+		}),
+		dropped: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: "token",
+			Subsystem: "listener",
+			Name:      "dropped_blocks_total",
+			Help:      "Blocks handed to the dead-letter sink instead of the sink.",
+		}),
+	}
+
+	reg.MustRegister(m.blocks, m.transactions, m.invalid, m.mvccConflicts, m.lastBlock, m.eventLag, m.deferred, m.dropped)
+
+	return m
+}
+
+func (m *Metrics) observe(block *Block, now time.Time) {
+	if m == nil {
+		return
+	}
+
+	m.blocks.Inc()
+	m.transactions.Add(float64(len(block.Transactions)))
+	m.invalid.Add(float64(block.Invalid))
+	m.mvccConflicts.Add(float64(block.MVCCConflicts))
+	m.lastBlock.Set(float64(block.Number))
+
+	var latest time.Time
+	for _, tx := range block.Transactions {
+		if tx.Timestamp.After(latest) {
+			latest = tx.Timestamp
+		}
+	}
+	if !latest.IsZero() {
+		m.eventLag.Set(now.Sub(latest).Seconds())
+	}
+}
+
+func (m *Metrics) deferBlock() {
+	if m == nil {
+		return
+	}
+
+	m.deferred.Inc()
+}
+
+func (m *Metrics) drop() {
+	if m == nil {
+		return
+	}
+
+	m.dropped.Inc()
+}
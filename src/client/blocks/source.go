@@ -0,0 +1,68 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package blocks
+
+import (
+	"context"
+
+	"github.com/hyperledger/fabric-protos-go/common"
+	"github.com/hyperledger/fabric-sdk-go/pkg/client/event"
+	sdkcontext "github.com/hyperledger/fabric-sdk-go/pkg/common/providers/context"
+	"github.com/hyperledger/fabric-sdk-go/pkg/fab/events/deliverclient/seek"
+)
+
+// EventSource delivers full blocks from a peer's deliver service. The
+// identity behind the channel provider needs permission to read full blocks.
+type EventSource struct {
+	channelProvider sdkcontext.ChannelProvider
+}
+
+// NewEventSource creates a source from an SDK channel context, e.g.
+// sdk.ChannelContext("mychannel", fabsdk.WithUser("appUser"))
+func NewEventSource(channelProvider sdkcontext.ChannelProvider) *EventSource {
+	return &EventSource{channelProvider: channelProvider}
+}
+
+// Blocks subscribes to block events starting at block from
+func (s *EventSource) Blocks(ctx context.Context, from uint64) (<-chan *common.Block, error) {
+	client, err := event.New(
+		s.channelProvider,
+		event.WithBlockEvents(),
+		event.WithSeekType(seek.FromBlock),
+		event.WithBlockNum(from),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	registration, events, err := client.RegisterBlockEvent()
+	if err != nil {
+		return nil, err
+	}
+
+	blocks := make(chan *common.Block)
+	go func() {
+		defer close(blocks)
+		defer client.Unregister(registration)
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case e, ok := <-events:
+				if !ok {
+					return
+				}
+				select {
+				case blocks <- e.Block:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+
+	return blocks, nil
+}
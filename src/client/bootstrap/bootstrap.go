@@ -0,0 +1,105 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+*/
+
+// Package bootstrap loads the initial accounts of a token ledger from a JSON
+// artifact through the contract's BootstrapLedger transaction, one chunk per
+// transaction, and exports a ledger's accounts as such an artifact
+package bootstrap
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+)
+
+// DefaultChunkSize is the chaincode's MaxBootstrapAccounts
+const DefaultChunkSize = 500
+
+// Account is an initial account record, as the contract stores it
+type Account struct {
+	ID      string `json:"userId"`
+	Type    string `json:"type"`
+	Balance int    `json:"balance"`
+	Hot     bool   `json:"hot,omitempty"`
+}
+
+// Submitter submits transactions. The fabric-sdk-go gateway Contract
+// satisfies it.
+type Submitter interface {
+	SubmitTransaction(name string, args ...string) ([]byte, error)
+}
+
+// Progress reports how far a load got
+type Progress struct {
+	// Next is the index of the first account not yet committed, where a
+	// failed load resumes
+	Next     int
+	Created  int
+	Existing int
+}
+
+// ReadFile reads an artifact: a JSON array of accounts
+func ReadFile(path string) ([]Account, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	var accounts []Account
+	if err := decoder.Decode(&accounts); err != nil {
+		return nil, fmt.Errorf("invalid artifact %s: %v", path, err)
+	}
+
+	return accounts, nil
+}
+
+// Load submits accounts[start:] in chunks of chunkSize, calling progress
+// after each committed chunk if it is not nil. Chunks already committed by
+// an earlier attempt are accepted again, so a failed load can be resumed
+// from the returned Progress.Next.
+func Load(s Submitter, accounts []Account, start int, chunkSize int, progress func(Progress)) (Progress, error) {
+	if chunkSize < 1 {
+		chunkSize = DefaultChunkSize
+	}
+	if start < 0 || start > len(accounts) {
+		return Progress{}, fmt.Errorf("start %d outside the %d accounts", start, len(accounts))
+	}
+
+	p := Progress{Next: start}
+	for p.Next < len(accounts) {
+		end := p.Next + chunkSize
+		if end > len(accounts) {
+			end = len(accounts)
+		}
+
+		chunk, err := json.Marshal(accounts[p.Next:end])
+		if err != nil {
+			return p, err
+		}
+		payload, err := s.SubmitTransaction("BootstrapLedger", string(chunk))
+		if err != nil {
+			return p, fmt.Errorf("accounts %d to %d: %w", p.Next, end-1, err)
+		}
+
+		var result struct {
+			Created  int `json:"created"`
+			Existing int `json:"existing"`
+		}
+		if err := json.Unmarshal(payload, &result); err != nil {
+			return p, fmt.Errorf("accounts %d to %d: unexpected result %q: %v", p.Next, end-1, payload, err)
+		}
+
+		p.Next = end
+		p.Created += result.Created
+		p.Existing += result.Existing
+		if progress != nil {
+			progress(p)
+		}
+	}
+
+	return p, nil
+}
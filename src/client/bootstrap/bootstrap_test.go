@@ -0,0 +1,109 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package bootstrap
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeLedger accepts BootstrapLedger chunks like the contract: accounts it
+// already holds count as existing
+type fakeLedger struct {
+	accounts map[string]Account
+	chunks   []int
+	failAt   int
+}
+
+func (f *fakeLedger) SubmitTransaction(name string, args ...string) ([]byte, error) {
+	if name != "BootstrapLedger" || len(args) != 1 {
+		return nil, fmt.Errorf("unexpected transaction %s%v", name, args)
+	}
+	f.chunks = append(f.chunks, len(f.chunks))
+	if len(f.chunks) == f.failAt {
+		return nil, errors.New("transaction invalidated with status (MVCC_READ_CONFLICT)")
+	}
+
+	var chunk []Account
+	if err := json.Unmarshal([]byte(args[0]), &chunk); err != nil {
+		return nil, err
+	}
+	created, existing := 0, 0
+	for _, account := range chunk {
+		if _, ok := f.accounts[account.ID]; ok {
+			existing++
+			continue
+		}
+		f.accounts[account.ID] = account
+		created++
+	}
+
+	return json.Marshal(map[string]int{"created": created, "existing": existing})
+}
+
+func artifact(n int) []Account {
+	accounts := make([]Account, n)
+	for i := range accounts {
+		accounts[i] = Account{ID: fmt.Sprintf("user%d", i), Type: "user", Balance: i}
+	}
+	return accounts
+}
+
+func TestLoad(t *testing.T) {
+	ledger := &fakeLedger{accounts: map[string]Account{}}
+	var reported []Progress
+
+	p, err := Load(ledger, artifact(7), 0, 3, func(p Progress) { reported = append(reported, p) })
+	require.NoError(t, err)
+	assert.Equal(t, Progress{Next: 7, Created: 7}, p)
+	assert.Len(t, ledger.chunks, 3, "7 accounts in chunks of 3")
+	assert.Equal(t, []Progress{{Next: 3, Created: 3}, {Next: 6, Created: 6}, {Next: 7, Created: 7}}, reported)
+	assert.Equal(t, Account{ID: "user6", Type: "user", Balance: 6}, ledger.accounts["user6"])
+}
+
+func TestLoadResume(t *testing.T) {
+	ledger := &fakeLedger{accounts: map[string]Account{}, failAt: 2}
+	accounts := artifact(7)
+
+	p, err := Load(ledger, accounts, 0, 3, nil)
+	assert.EqualError(t, err, "accounts 3 to 5: transaction invalidated with status (MVCC_READ_CONFLICT)")
+	assert.Equal(t, Progress{Next: 3, Created: 3}, p, "should stop before the failed chunk")
+
+	// resubmitting from an earlier index is harmless
+	p, err = Load(ledger, accounts, 0, 3, nil)
+	require.NoError(t, err)
+	assert.Equal(t, Progress{Next: 7, Created: 4, Existing: 3}, p)
+	assert.Len(t, ledger.accounts, 7)
+}
+
+func TestLoadStart(t *testing.T) {
+	_, err := Load(&fakeLedger{}, artifact(2), 3, 0, nil)
+	assert.EqualError(t, err, "start 3 outside the 2 accounts")
+
+	p, err := Load(&fakeLedger{}, artifact(2), 2, 0, nil)
+	require.NoError(t, err)
+	assert.Equal(t, Progress{Next: 2}, p, "nothing left to load")
+}
+
+func TestReadFile(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "accounts.json")
+	require.NoError(t, ioutil.WriteFile(path, []byte(`[{"userId":"treasury","type":"issuer","balance":1000,"hot":true}]`), 0644))
+
+	accounts, err := ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, []Account{{ID: "treasury", Type: "issuer", Balance: 1000, Hot: true}}, accounts)
+
+	require.NoError(t, ioutil.WriteFile(path, []byte(`[{"id":"treasury"}]`), 0644))
+	_, err = ReadFile(path)
+	assert.EqualError(t, err, fmt.Sprintf(`invalid artifact %s: json: unknown field "id"`, path))
+}
@@ -0,0 +1,119 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package bootstrap
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"sync"
+)
+
+// DefaultPageSize is the number of accounts Export requests per
+// ListAccounts call
+const DefaultPageSize = 500
+
+// Evaluator evaluates transactions. The fabric-sdk-go gateway Contract
+// satisfies it.
+type Evaluator interface {
+	EvaluateTransaction(name string, args ...string) ([]byte, error)
+}
+
+// Export lists every account on the ledger in pages of pageSize, listing up
+// to workers shards in parallel, and passes each page to fn. fn is never
+// called concurrently, and the pages together form an artifact Load
+// accepts. Credits to hot accounts not yet folded in by PruneDeltas are not
+// part of their balance, so prune hot accounts before a backup.
+func Export(e Evaluator, workers int, pageSize int, fn func(shard int, accounts []Account) error) error {
+	if workers < 1 {
+		workers = 1
+	}
+	if pageSize < 1 {
+		pageSize = DefaultPageSize
+	}
+
+	payload, err := e.EvaluateTransaction("ShardCount")
+	if err != nil {
+		return err
+	}
+	shards, err := strconv.Atoi(string(payload))
+	if err != nil {
+		return fmt.Errorf("unexpected shard count %q: %v", payload, err)
+	}
+
+	shardc := make(chan int, shards)
+	for shard := 0; shard < shards; shard++ {
+		shardc <- shard
+	}
+	close(shardc)
+
+	var (
+		mu       sync.Mutex
+		firstErr error
+		wg       sync.WaitGroup
+	)
+	fail := func(err error) {
+		mu.Lock()
+		defer mu.Unlock()
+		if firstErr == nil {
+			firstErr = err
+		}
+	}
+	emit := func(shard int, accounts []Account) bool {
+		mu.Lock()
+		defer mu.Unlock()
+		if firstErr != nil {
+			return false
+		}
+		if err := fn(shard, accounts); err != nil {
+			firstErr = err
+			return false
+		}
+		return true
+	}
+
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for shard := range shardc {
+				if err := exportShard(e, shard, pageSize, emit); err != nil {
+					fail(fmt.Errorf("shard %d: %w", shard, err))
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	return firstErr
+}
+
+// exportShard lists one shard a page at a time, stopping when emit returns
+// false
+func exportShard(e Evaluator, shard int, pageSize int, emit func(int, []Account) bool) error {
+	bookmark := ""
+	for {
+		payload, err := e.EvaluateTransaction("ListAccounts", strconv.Itoa(shard), strconv.Itoa(pageSize), bookmark)
+		if err != nil {
+			return err
+		}
+
+		var page struct {
+			Accounts []Account `json:"accounts"`
+			Bookmark string    `json:"bookmark"`
+		}
+		if err := json.Unmarshal(payload, &page); err != nil {
+			return fmt.Errorf("unexpected page %q: %v", payload, err)
+		}
+
+		if len(page.Accounts) > 0 && !emit(shard, page.Accounts) {
+			return nil
+		}
+		if page.Bookmark == "" {
+			return nil
+		}
+		bookmark = page.Bookmark
+	}
+}
@@ -0,0 +1,73 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package bootstrap
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// shardedLedger answers ShardCount and ListAccounts from fixed shards; the
+// bookmark is the index of the next account in the shard
+type shardedLedger struct {
+	shards [][]Account
+	fail   int
+}
+
+func (l *shardedLedger) EvaluateTransaction(name string, args ...string) ([]byte, error) {
+	switch name {
+	case "ShardCount":
+		return []byte(strconv.Itoa(len(l.shards))), nil
+	case "ListAccounts":
+		shard, _ := strconv.Atoi(args[0])
+		if shard == l.fail {
+			return nil, errors.New("endorsement failure")
+		}
+		pageSize, _ := strconv.Atoi(args[1])
+		start, _ := strconv.Atoi(args[2])
+
+		accounts := l.shards[shard]
+		end, bookmark := len(accounts), ""
+		if start+pageSize < len(accounts) {
+			end, bookmark = start+pageSize, strconv.Itoa(start+pageSize)
+		}
+		return json.Marshal(map[string]interface{}{"accounts": accounts[start:end], "bookmark": bookmark})
+	}
+	return nil, fmt.Errorf("unexpected transaction %s", name)
+}
+
+func TestExport(t *testing.T) {
+	accounts := artifact(10)
+	ledger := &shardedLedger{shards: [][]Account{accounts[:5], accounts[5:7], {}, accounts[7:]}, fail: -1}
+
+	var exported []Account
+	err := Export(ledger, 3, 2, func(shard int, page []Account) error {
+		assert.LessOrEqual(t, len(page), 2)
+		exported = append(exported, page...)
+		return nil
+	})
+	require.NoError(t, err)
+
+	sort.Slice(exported, func(i, j int) bool { return exported[i].Balance < exported[j].Balance })
+	assert.Equal(t, accounts, exported)
+}
+
+func TestExportFailure(t *testing.T) {
+	ledger := &shardedLedger{shards: [][]Account{artifact(3), artifact(3)}, fail: 1}
+
+	err := Export(ledger, 1, 10, func(int, []Account) error { return nil })
+	assert.EqualError(t, err, "shard 1: endorsement failure")
+
+	ledger.fail = -1
+	err = Export(ledger, 2, 1, func(int, []Account) error { return errors.New("disk full") })
+	assert.EqualError(t, err, "disk full")
+}
@@ -0,0 +1,148 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+*/
+
+// Package clone copies the state of a token ledger into a fresh deployment,
+// such as a staging channel mirroring production. Export writes a dump of
+// ExportState pages, each signed by the exporting admin; Import submits
+// them to the target with ImportState, whose admin has registered the
+// exporter's certificate with SetImportSigner.
+package clone
+
+import (
+	"bufio"
+	"crypto"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+)
+
+// Sections are exported in this order, so a dump imports transactions and
+// accounts before the records that refer to them
+var Sections = []string{"records", "accounts", "deltas", "audit", "auditSeq", "auditorReads", "limits", "spends", "pendingTransfers", "reviews", "index", "confirmations", "suspensions", "holders", "guardians", "recoveries", "payments", "reversibles", "disputes", "dormancy", "demurrage", "snapshots", "distributions", "airdropClaims", "whitelists", "referrals", "referrers", "settlementAccounts", "settlementPeriods", "payouts", "items", "receipts", "refunds", "currencies", "currencyBalances", "currencyTransactions", "rates", "attestations", "rebases", "interest", "creditLines", "giftCards", "aliases", "wallets", "hierarchy", "treasuries", "treasuryPayments", "delegates", "revokedCertificates", "holderRanks", "accountCounts", "correlations", "accountTypes", "transactionTimes"}
+
+// Evaluator evaluates transactions. The fabric-sdk-go gateway Contract
+// satisfies it.
+type Evaluator interface {
+	EvaluateTransaction(name string, args ...string) ([]byte, error)
+}
+
+// Submitter submits transactions. The fabric-sdk-go gateway Contract
+// satisfies it.
+type Submitter interface {
+	SubmitTransaction(name string, args ...string) ([]byte, error)
+}
+
+// Page is one line of a dump: an ExportState page as the contract returned
+// it and the base64 signature of its checksum
+type Page struct {
+	Page      json.RawMessage `json:"page"`
+	Signature string          `json:"signature"`
+}
+
+// Progress reports the pages imported so far
+type Progress struct {
+	Pages    int
+	Imported int
+	Existing int
+}
+
+// Export writes every section of the ledger to w as a dump, one signed page
+// of at most pageSize records per line, 0 for the contract's default. It
+// returns the number of pages written.
+func Export(e Evaluator, signer crypto.Signer, pageSize int, w io.Writer) (int, error) {
+	out := bufio.NewWriter(w)
+	encoder := json.NewEncoder(out)
+	pages := 0
+	for _, section := range Sections {
+		bookmark := ""
+		for {
+			payload, err := e.EvaluateTransaction("ExportState", section, strconv.Itoa(pageSize), bookmark)
+			if err != nil {
+				return pages, fmt.Errorf("%s from %q: %w", section, bookmark, err)
+			}
+
+			var page struct {
+				Checksum string `json:"checksum"`
+				Bookmark string `json:"bookmark"`
+			}
+			if err := json.Unmarshal(payload, &page); err != nil {
+				return pages, fmt.Errorf("%s: unexpected result %q: %v", section, payload, err)
+			}
+
+			signature, err := sign(signer, page.Checksum)
+			if err != nil {
+				return pages, fmt.Errorf("%s: %v", section, err)
+			}
+			if err := encoder.Encode(Page{Page: payload, Signature: signature}); err != nil {
+				return pages, err
+			}
+			pages++
+
+			if page.Bookmark == "" {
+				break
+			}
+			bookmark = page.Bookmark
+		}
+	}
+
+	return pages, out.Flush()
+}
+
+// Import submits the pages of the dump read from r, calling progress after
+// each if it is not nil. Pages already imported are skipped by the
+// contract, so a failed import is resumed by running it again.
+func Import(s Submitter, r io.Reader, progress func(Progress)) (Progress, error) {
+	var p Progress
+	decoder := json.NewDecoder(r)
+	decoder.DisallowUnknownFields()
+	for {
+		var page Page
+		err := decoder.Decode(&page)
+		if err == io.EOF {
+			return p, nil
+		}
+		if err != nil {
+			return p, fmt.Errorf("page %d: %v", p.Pages, err)
+		}
+
+		payload, err := s.SubmitTransaction("ImportState", string(page.Page), page.Signature)
+		if err != nil {
+			return p, fmt.Errorf("page %d: %w", p.Pages, err)
+		}
+
+		var result struct {
+			Imported int `json:"imported"`
+			Existing int `json:"existing"`
+		}
+		if err := json.Unmarshal(payload, &result); err != nil {
+			return p, fmt.Errorf("page %d: unexpected result %q: %v", p.Pages, payload, err)
+		}
+
+		p.Pages++
+		p.Imported += result.Imported
+		p.Existing += result.Existing
+		if progress != nil {
+			progress(p)
+		}
+	}
+}
+
+// sign returns the base64 signature of the digest a hex checksum encodes
+func sign(signer crypto.Signer, checksum string) (string, error) {
+	digest, err := hex.DecodeString(checksum)
+	if err != nil || len(digest) != crypto.SHA256.Size() {
+		return "", fmt.Errorf("unexpected checksum %q", checksum)
+	}
+
+	signature, err := signer.Sign(rand.Reader, digest, crypto.SHA256)
+	if err != nil {
+		return "", err
+	}
+
+	return base64.StdEncoding.EncodeToString(signature), nil
+}
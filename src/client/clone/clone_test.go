@@ -0,0 +1,111 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package clone
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeLedger exports sections of fixed sizes, a record per page, and imports
+// pages by counting them. The bookmark is the index of the next record.
+type fakeLedger struct {
+	sections map[string]int
+	imported map[string]bool
+	fail     int
+}
+
+func (l *fakeLedger) EvaluateTransaction(name string, args ...string) ([]byte, error) {
+	if name != "ExportState" {
+		return nil, errors.New("unexpected transaction " + name)
+	}
+	next, _ := strconv.Atoi(args[2])
+	bookmark := ""
+	if next+1 < l.sections[args[0]] {
+		bookmark = strconv.Itoa(next + 1)
+	}
+
+	digest := sha256.Sum256([]byte(args[0] + args[2]))
+	return json.Marshal(map[string]interface{}{"section": args[0], "checksum": hex.EncodeToString(digest[:]), "bookmark": bookmark})
+}
+
+func (l *fakeLedger) SubmitTransaction(name string, args ...string) ([]byte, error) {
+	if name != "ImportState" {
+		return nil, errors.New("unexpected transaction " + name)
+	}
+	if len(l.imported) == l.fail {
+		return nil, errors.New("MVCC_READ_CONFLICT")
+	}
+	if l.imported[args[0]] {
+		return []byte(`{"imported":0,"existing":1}`), nil
+	}
+	l.imported[args[0]] = true
+	return []byte(`{"imported":1,"existing":0}`), nil
+}
+
+func TestExport(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	l := &fakeLedger{sections: map[string]int{"records": 2, "accounts": 0, "deltas": 1}}
+
+	var dump bytes.Buffer
+	pages, err := Export(l, key, 0, &dump)
+	require.NoError(t, err)
+	assert.Equal(t, 55, pages, "an empty section still exports a page")
+
+	lines := strings.Split(strings.TrimSpace(dump.String()), "\n")
+	require.Len(t, lines, 55)
+	var sections []string
+	for _, line := range lines {
+		var page Page
+		require.NoError(t, json.Unmarshal([]byte(line), &page))
+		var content struct {
+			Section  string `json:"section"`
+			Checksum string `json:"checksum"`
+		}
+		require.NoError(t, json.Unmarshal(page.Page, &content))
+		sections = append(sections, content.Section)
+
+		digest, _ := hex.DecodeString(content.Checksum)
+		signature, err := base64.StdEncoding.DecodeString(page.Signature)
+		require.NoError(t, err)
+		assert.True(t, ecdsa.VerifyASN1(&key.PublicKey, digest, signature))
+	}
+	assert.Equal(t, []string{"records", "records", "accounts", "deltas", "audit", "auditSeq", "auditorReads", "limits", "spends", "pendingTransfers", "reviews", "index", "confirmations", "suspensions", "holders", "guardians", "recoveries", "payments", "reversibles", "disputes", "dormancy", "demurrage", "snapshots", "distributions", "airdropClaims", "whitelists", "referrals", "referrers", "settlementAccounts", "settlementPeriods", "payouts", "items", "receipts", "refunds", "currencies", "currencyBalances", "currencyTransactions", "rates", "attestations", "rebases", "interest", "creditLines", "giftCards", "aliases", "wallets", "hierarchy", "treasuries", "treasuryPayments", "delegates", "revokedCertificates", "holderRanks", "accountCounts", "correlations", "accountTypes", "transactionTimes"}, sections)
+}
+
+func TestImport(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	source := &fakeLedger{sections: map[string]int{"records": 3}}
+	var dump bytes.Buffer
+	_, err = Export(source, key, 0, &dump)
+	require.NoError(t, err)
+
+	target := &fakeLedger{imported: map[string]bool{}, fail: 2}
+	var reported []Progress
+	progress, err := Import(target, bytes.NewReader(dump.Bytes()), func(p Progress) { reported = append(reported, p) })
+	assert.EqualError(t, err, "page 2: MVCC_READ_CONFLICT")
+	assert.Equal(t, Progress{Pages: 2, Imported: 2}, progress)
+	assert.Len(t, reported, 2)
+
+	target.fail = -1
+	progress, err = Import(target, bytes.NewReader(dump.Bytes()), nil)
+	require.NoError(t, err)
+	assert.Equal(t, Progress{Pages: 56, Imported: 54, Existing: 2}, progress, "a rerun skips the pages already imported")
+}
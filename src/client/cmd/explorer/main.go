@@ -0,0 +1,73 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+*/
+
+// Command explorer indexes token blocks into a SQLite database and serves the
+// explorer API, which the REST gateway proxies under /explorer
+package main
+
+import (
+	"context"
+	"database/sql"
+	"flag"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/hyperledger/fabric-sdk-go/pkg/core/config"
+	"github.com/hyperledger/fabric-sdk-go/pkg/fabsdk"
+	"github.com/kkiu1756/my_fabric/src/client/blocks"
+	"github.com/kkiu1756/my_fabric/src/client/explorer"
+	_ "github.com/mattn/go-sqlite3"
+)
+
+func main() {
+	configPath := flag.String("config", "connection-org1.yaml", "SDK connection profile")
+	channel := flag.String("channel", "mychannel", "channel name")
+	chaincode := flag.String("chaincode", "basic", "token chaincode name")
+	org := flag.String("org", "Org1", "organization of the identity")
+	user := flag.String("user", "User1", "identity allowed to read full blocks")
+	dbPath := flag.String("db", "explorer.db", "SQLite database file")
+	addr := flag.String("addr", ":8081", "HTTP listen address")
+	attempts := flag.Int("attempts", 1, "attempts to index a block before giving up on it")
+	backoff := flag.Duration("backoff", time.Second, "wait before the first retry, doubled per retry")
+	deadLetters := flag.String("dead-letters", "", "JSON lines file for blocks given up on; empty stops the listener instead")
+	flag.Parse()
+
+	db, err := sql.Open("sqlite3", *dbPath)
+	if err != nil {
+		log.Fatalf("Failed to open database: %v", err)
+	}
+	defer db.Close()
+
+	ctx := context.Background()
+	store := explorer.NewSQLStore(db, false)
+	if err := store.CreateTables(ctx); err != nil {
+		log.Fatalf("Failed to create tables: %v", err)
+	}
+	checkpointer := blocks.NewSQLCheckpointer(db, "explorer", false)
+	if err := checkpointer.CreateTable(ctx); err != nil {
+		log.Fatalf("Failed to create tables: %v", err)
+	}
+
+	sdk, err := fabsdk.New(config.FromFile(*configPath))
+	if err != nil {
+		log.Fatalf("Failed to create SDK: %v", err)
+	}
+	defer sdk.Close()
+
+	source := blocks.NewEventSource(sdk.ChannelContext(*channel, fabsdk.WithUser(*user), fabsdk.WithOrg(*org)))
+	opts := []blocks.Option{blocks.WithRetry(blocks.RetryPolicy{Attempts: *attempts, Backoff: *backoff, MaxBackoff: time.Minute})}
+	if *deadLetters != "" {
+		opts = append(opts, blocks.WithDeadLetters(blocks.NewFileDeadLetters(*deadLetters)))
+	}
+	listener := blocks.NewListener(source, *chaincode, explorer.NewIndexer(store), checkpointer, opts...)
+
+	go func() {
+		log.Fatalf("Listener stopped: %v", listener.Run(ctx))
+	}()
+
+	http.Handle("/explorer/", explorer.Handler(store))
+	log.Printf("Explorer listening on %s", *addr)
+	log.Fatal(http.ListenAndServe(*addr, nil))
+}
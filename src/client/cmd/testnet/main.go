@@ -0,0 +1,72 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+*/
+
+// Command testnet brings the test network up with the token chaincode
+// deployed, or tears it down.
+//
+//	testnet -dir ../../test-network -policy all up
+//	testnet -dir ../../test-network down
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/kkiu1756/my_fabric/src/client/testnet"
+)
+
+func main() {
+	dir := flag.String("dir", "../../test-network", "test-network directory")
+	channel := flag.String("channel", "mychannel", "channel name")
+	ca := flag.Bool("ca", false, "use Fabric CAs for crypto material")
+	database := flag.String("database", "leveldb", "state database (leveldb or couchdb)")
+	name := flag.String("chaincode", "basic", "chaincode name")
+	path := flag.String("path", "../src/chaincode-go", "chaincode path, relative to -dir")
+	policy := flag.String("policy", "any", "endorsement policy: any, all, or a policy expression")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "usage: %s [flags] up|deploy|down\n", os.Args[0])
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	n := testnet.New(*dir)
+	n.Channel = *channel
+	n.CA = *ca
+	n.Database = *database
+
+	cc := testnet.Chaincode{Name: *name, Path: *path, EndorsementPolicy: endorsementPolicy(*policy)}
+	ctx := context.Background()
+
+	var err error
+	switch flag.Arg(0) {
+	case "up":
+		if err = n.Up(ctx); err == nil {
+			err = n.Deploy(ctx, cc)
+		}
+	case "deploy":
+		err = n.Deploy(ctx, cc)
+	case "down":
+		err = n.Down(ctx)
+	default:
+		flag.Usage()
+		os.Exit(2)
+	}
+	if err != nil {
+		log.Fatal(err)
+	}
+}
+
+func endorsementPolicy(policy string) string {
+	switch policy {
+	case "any":
+		return testnet.AnyOrg
+	case "all":
+		return testnet.AllOrgs
+	}
+
+	return policy
+}
@@ -0,0 +1,162 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+*/
+
+// Command tokenbootstrap loads the initial accounts of a freshly initialized
+// token ledger from a JSON artifact, an array of {userId, type, balance}
+// records, submitting BootstrapLedger one chunk at a time as an org admin.
+// If a chunk fails, it prints the index to resume from with -start;
+// resubmitting chunks that already committed is harmless. With -export it
+// instead writes the ledger's accounts to the artifact, listing -workers
+// shards in parallel.
+//
+//	tokenbootstrap -config connection-org1.yaml -wallet wallet -identity admin \
+//	    -file accounts.json -chunk 500
+//	tokenbootstrap -config connection-org1.yaml -wallet wallet -identity appUser \
+//	    -file backup.json -export -workers 8
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"flag"
+	"log"
+	"os"
+
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/core"
+	"github.com/hyperledger/fabric-sdk-go/pkg/core/config"
+	"github.com/hyperledger/fabric-sdk-go/pkg/gateway"
+	"github.com/kkiu1756/my_fabric/src/client/bootstrap"
+	"github.com/kkiu1756/my_fabric/src/client/hsm"
+	"github.com/kkiu1756/my_fabric/src/client/wallet"
+)
+
+func main() {
+	configPath := flag.String("config", "connection-org1.yaml", "SDK connection profile")
+	walletPath := flag.String("wallet", "wallet", "file system wallet directory")
+	identity := flag.String("identity", "admin", "wallet label of an org admin to submit as")
+	channel := flag.String("channel", "mychannel", "channel name")
+	chaincode := flag.String("chaincode", "basic", "token chaincode name")
+	file := flag.String("file", "accounts.json", "JSON artifact listing the initial accounts")
+	chunk := flag.Int("chunk", bootstrap.DefaultChunkSize, "accounts per transaction")
+	start := flag.Int("start", 0, "index of the first account to load, to resume a failed load")
+	export := flag.Bool("export", false, "write the ledger's accounts to -file instead of loading it")
+	workers := flag.Int("workers", 4, "shards listed in parallel with -export")
+	var hsmConfig hsm.Config
+	flag.StringVar(&hsmConfig.Library, "hsm-lib", "", "PKCS#11 library for identities held in an HSM")
+	flag.StringVar(&hsmConfig.Label, "hsm-label", "", "PKCS#11 token label")
+	flag.StringVar(&hsmConfig.Pin, "hsm-pin", os.Getenv("TOKENBOOTSTRAP_HSM_PIN"), "PKCS#11 user PIN")
+	flag.Parse()
+
+	if *chunk < 1 || *chunk > bootstrap.DefaultChunkSize {
+		log.Fatalf("-chunk must be between 1 and %d", bootstrap.DefaultChunkSize)
+	}
+
+	store, err := wallet.NewFileStore(*walletPath)
+	if err != nil {
+		log.Fatalf("Failed to open wallet: %v", err)
+	}
+
+	gw, closeGateway, err := connect(config.FromFile(*configPath), store, *identity, hsmConfig)
+	if err != nil {
+		log.Fatalf("Failed to connect as %s: %v", *identity, err)
+	}
+	defer closeGateway()
+
+	network, err := gw.GetNetwork(*channel)
+	if err != nil {
+		log.Fatalf("Failed to get network: %v", err)
+	}
+
+	contract := network.GetContract(*chaincode)
+	if *export {
+		if err := exportFile(contract, *file, *workers); err != nil {
+			closeGateway()
+			log.Fatalf("Export failed: %v", err)
+		}
+		return
+	}
+
+	accounts, err := bootstrap.ReadFile(*file)
+	if err != nil {
+		closeGateway()
+		log.Fatalf("Failed to read accounts: %v", err)
+	}
+
+	progress, err := bootstrap.Load(contract, accounts, *start, *chunk, func(p bootstrap.Progress) {
+		log.Printf("Loaded %d of %d accounts", p.Next, len(accounts))
+	})
+	if err != nil {
+		closeGateway()
+		log.Fatalf("Bootstrap stopped: %v (resume with -start %d)", err, progress.Next)
+	}
+
+	log.Printf("Bootstrap complete: %d accounts created, %d already present", progress.Created, progress.Existing)
+}
+
+// exportFile writes the ledger's accounts to path as a JSON array, one
+// account per line
+func exportFile(e bootstrap.Evaluator, path string, workers int) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	w := bufio.NewWriter(f)
+	w.WriteString("[")
+	count := 0
+	err = bootstrap.Export(e, workers, bootstrap.DefaultPageSize, func(shard int, accounts []bootstrap.Account) error {
+		for _, account := range accounts {
+			data, err := json.Marshal(account)
+			if err != nil {
+				return err
+			}
+			if count > 0 {
+				w.WriteString(",")
+			}
+			w.WriteString("\n")
+			w.Write(data)
+			count++
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	w.WriteString("\n]\n")
+	if err := w.Flush(); err != nil {
+		return err
+	}
+
+	log.Printf("Exported %d accounts to %s", count, path)
+	return f.Close()
+}
+
+// connect opens a gateway as label, signing through the HSM if the wallet
+// holds only its certificate
+func connect(profile core.ConfigProvider, store wallet.Store, label string, hsmConfig hsm.Config) (*gateway.Gateway, func(), error) {
+	id, err := store.Get(label)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if id.HSM {
+		gw, err := hsm.Connect(profile, store, label, hsmConfig)
+		if err != nil {
+			return nil, nil, err
+		}
+		return gw.Gateway, gw.Close, nil
+	}
+
+	identity, err := wallet.IdentityOption(store, label)
+	if err != nil {
+		return nil, nil, err
+	}
+	gw, err := gateway.Connect(gateway.WithConfig(profile), identity)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return gw, gw.Close, nil
+}
@@ -0,0 +1,166 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+*/
+
+// Command tokenclone copies a token ledger into a fresh deployment, such as
+// a staging channel mirroring production. With -export it dumps the
+// source's state to -file, a signed ExportState page per line, signing with
+// the -identity admin's key. With -import it submits the dump to the
+// target; the target's admin first registers the exporter's certificate
+// with -trust. Pages already imported are skipped, so a failed import is
+// resumed by running it again.
+//
+//	tokenclone -config prod-org1.yaml -identity admin -export -file dump.jsonl
+//	tokenclone -config staging-org1.yaml -identity admin -trust prod-admin.pem
+//	tokenclone -config staging-org1.yaml -identity admin -import -file dump.jsonl
+package main
+
+import (
+	"flag"
+	"io/ioutil"
+	"log"
+	"os"
+
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/core"
+	"github.com/hyperledger/fabric-sdk-go/pkg/core/config"
+	"github.com/hyperledger/fabric-sdk-go/pkg/gateway"
+	"github.com/kkiu1756/my_fabric/src/client/clone"
+	"github.com/kkiu1756/my_fabric/src/client/hsm"
+	"github.com/kkiu1756/my_fabric/src/client/wallet"
+)
+
+func main() {
+	configPath := flag.String("config", "connection-org1.yaml", "SDK connection profile")
+	walletPath := flag.String("wallet", "wallet", "file system wallet directory")
+	identity := flag.String("identity", "admin", "wallet label of an org admin to submit as")
+	channel := flag.String("channel", "mychannel", "channel name")
+	chaincode := flag.String("chaincode", "basic", "token chaincode name")
+	file := flag.String("file", "dump.jsonl", "dump file")
+	export := flag.Bool("export", false, "dump the ledger to -file, signed with the identity's key")
+	importDump := flag.Bool("import", false, "import the dump in -file")
+	trust := flag.String("trust", "", "PEM certificate of the exporting admin to accept dumps from")
+	pageSize := flag.Int("page", 0, "records per page with -export, 0 for the contract's default")
+	var hsmConfig hsm.Config
+	flag.StringVar(&hsmConfig.Library, "hsm-lib", "", "PKCS#11 library for identities held in an HSM")
+	flag.StringVar(&hsmConfig.Label, "hsm-label", "", "PKCS#11 token label")
+	flag.StringVar(&hsmConfig.Pin, "hsm-pin", os.Getenv("TOKENCLONE_HSM_PIN"), "PKCS#11 user PIN")
+	flag.Parse()
+
+	modes := 0
+	for _, set := range []bool{*export, *importDump, *trust != ""} {
+		if set {
+			modes++
+		}
+	}
+	if modes != 1 {
+		log.Fatalf("Pass exactly one of -export, -import and -trust")
+	}
+
+	store, err := wallet.NewFileStore(*walletPath)
+	if err != nil {
+		log.Fatalf("Failed to open wallet: %v", err)
+	}
+
+	gw, closeGateway, err := connect(config.FromFile(*configPath), store, *identity, hsmConfig)
+	if err != nil {
+		log.Fatalf("Failed to connect as %s: %v", *identity, err)
+	}
+	defer closeGateway()
+
+	network, err := gw.GetNetwork(*channel)
+	if err != nil {
+		log.Fatalf("Failed to get network: %v", err)
+	}
+	contract := network.GetContract(*chaincode)
+
+	switch {
+	case *trust != "":
+		cert, err := ioutil.ReadFile(*trust)
+		if err != nil {
+			closeGateway()
+			log.Fatalf("Failed to read certificate: %v", err)
+		}
+		if _, err := contract.SubmitTransaction("SetImportSigner", string(cert)); err != nil {
+			closeGateway()
+			log.Fatalf("Failed to set the import signer: %v", err)
+		}
+		log.Printf("Dumps signed by %s are accepted", *trust)
+
+	case *export:
+		if err := exportFile(store, *identity, contract, *pageSize, *file); err != nil {
+			closeGateway()
+			log.Fatalf("Export failed: %v", err)
+		}
+
+	default:
+		f, err := os.Open(*file)
+		if err != nil {
+			closeGateway()
+			log.Fatalf("Failed to open dump: %v", err)
+		}
+		defer f.Close()
+
+		progress, err := clone.Import(contract, f, func(p clone.Progress) {
+			log.Printf("Imported %d pages", p.Pages)
+		})
+		if err != nil {
+			closeGateway()
+			log.Fatalf("Import stopped: %v (run again to resume)", err)
+		}
+		log.Printf("Import complete: %d records written, %d already present", progress.Imported, progress.Existing)
+	}
+}
+
+// exportFile dumps the ledger to path, signing with label's key
+func exportFile(store wallet.Store, label string, e clone.Evaluator, pageSize int, path string) error {
+	id, err := store.Get(label)
+	if err != nil {
+		return err
+	}
+	signer, err := id.Signer()
+	if err != nil {
+		return err
+	}
+
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	pages, err := clone.Export(e, signer, pageSize, f)
+	if err != nil {
+		return err
+	}
+
+	log.Printf("Exported %d pages to %s", pages, path)
+	return f.Close()
+}
+
+// connect opens a gateway as label, signing through the HSM if the wallet
+// holds only its certificate
+func connect(profile core.ConfigProvider, store wallet.Store, label string, hsmConfig hsm.Config) (*gateway.Gateway, func(), error) {
+	id, err := store.Get(label)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if id.HSM {
+		gw, err := hsm.Connect(profile, store, label, hsmConfig)
+		if err != nil {
+			return nil, nil, err
+		}
+		return gw.Gateway, gw.Close, nil
+	}
+
+	identity, err := wallet.IdentityOption(store, label)
+	if err != nil {
+		return nil, nil, err
+	}
+	gw, err := gateway.Connect(gateway.WithConfig(profile), identity)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return gw, gw.Close, nil
+}
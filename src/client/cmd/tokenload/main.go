@@ -0,0 +1,202 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+*/
+
+// Command tokenload drives a mix of token transactions at a target rate
+// through the Fabric gateway, spread over several wallet identities, and
+// prints latency percentiles and MVCC conflict rates. It is a lightweight
+// alternative to the Caliper benchmarks in ../benchmarks. Identities whose
+// wallet entry is an HSM-X.509 certificate sign through the PKCS#11 token
+// given by the -hsm flags (build with -tags pkcs11).
+//
+//	tokenload -config connection-org1.yaml -wallet wallet -identities appUser,appUser2 \
+//	    -rate 50 -duration 1m -mix transfer=80,balance=20 -accounts 100 -setup
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"math/rand"
+	"os"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/core"
+	"github.com/hyperledger/fabric-sdk-go/pkg/core/config"
+	"github.com/hyperledger/fabric-sdk-go/pkg/gateway"
+	"github.com/kkiu1756/my_fabric/src/client/hsm"
+	"github.com/kkiu1756/my_fabric/src/client/loadgen"
+	"github.com/kkiu1756/my_fabric/src/client/wallet"
+)
+
+func main() {
+	configPath := flag.String("config", "connection-org1.yaml", "SDK connection profile")
+	walletPath := flag.String("wallet", "wallet", "file system wallet directory")
+	identities := flag.String("identities", "appUser", "comma-separated wallet labels to submit as")
+	channel := flag.String("channel", "mychannel", "channel name")
+	chaincode := flag.String("chaincode", "basic", "token chaincode name")
+	rate := flag.Float64("rate", 10, "target requests per second")
+	duration := flag.Duration("duration", time.Minute, "run duration")
+	concurrency := flag.Int("concurrency", 100, "maximum requests in flight")
+	mix := flag.String("mix", "transfer=80,balance=20", "operation weights (transfer, balance)")
+	accounts := flag.Int("accounts", 100, "number of accounts to spread load over")
+	hot := flag.Bool("hot", false, "debit every transfer from the first account")
+	prefix := flag.String("prefix", "load_", "account ID prefix")
+	setup := flag.Bool("setup", false, "create the accounts before the run")
+	seed := flag.Int64("seed", time.Now().UnixNano(), "random seed, for repeatable request sequences")
+	var hsmConfig hsm.Config
+	flag.StringVar(&hsmConfig.Library, "hsm-lib", "", "PKCS#11 library for identities held in an HSM")
+	flag.StringVar(&hsmConfig.Label, "hsm-label", "", "PKCS#11 token label")
+	flag.StringVar(&hsmConfig.Pin, "hsm-pin", os.Getenv("TOKENLOAD_HSM_PIN"), "PKCS#11 user PIN")
+	flag.Parse()
+
+	if *accounts < 2 {
+		log.Fatalf("-accounts must be at least 2")
+	}
+
+	operations, err := parseMix(*mix, *prefix, *accounts, *hot)
+	if err != nil {
+		log.Fatalf("Invalid -mix: %v", err)
+	}
+
+	store, err := wallet.NewFileStore(*walletPath)
+	if err != nil {
+		log.Fatalf("Failed to open wallet: %v", err)
+	}
+
+	var invokers []loadgen.Invoker
+	for _, label := range strings.Split(*identities, ",") {
+		gw, closeGateway, err := connect(config.FromFile(*configPath), store, label, hsmConfig)
+		if err != nil {
+			log.Fatalf("Failed to connect as %s: %v", label, err)
+		}
+		defer closeGateway()
+
+		network, err := gw.GetNetwork(*channel)
+		if err != nil {
+			log.Fatalf("Failed to get network: %v", err)
+		}
+		invokers = append(invokers, network.GetContract(*chaincode))
+	}
+
+	if *setup {
+		for i := 0; i < *accounts; i++ {
+			id := accountID(*prefix, i)
+			if _, err := invokers[0].SubmitTransaction("CreateUser", id, "user", "1000000000"); err != nil {
+				log.Printf("Skipping account %s: %v", id, err)
+			}
+		}
+	}
+
+	report, err := loadgen.Run(context.Background(), loadgen.Config{
+		Rate:        *rate,
+		Duration:    *duration,
+		Concurrency: *concurrency,
+		Operations:  operations,
+		Seed:        *seed,
+	}, invokers)
+	if err != nil {
+		log.Fatalf("Load run failed: %v", err)
+	}
+
+	printReport(report)
+}
+
+// connect opens a gateway as label, signing through the HSM if the wallet
+// holds only its certificate
+func connect(profile core.ConfigProvider, store wallet.Store, label string, hsmConfig hsm.Config) (*gateway.Gateway, func(), error) {
+	id, err := store.Get(label)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if id.HSM {
+		gw, err := hsm.Connect(profile, store, label, hsmConfig)
+		if err != nil {
+			return nil, nil, err
+		}
+		return gw.Gateway, gw.Close, nil
+	}
+
+	identity, err := wallet.IdentityOption(store, label)
+	if err != nil {
+		return nil, nil, err
+	}
+	gw, err := gateway.Connect(gateway.WithConfig(profile), identity)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return gw, gw.Close, nil
+}
+
+func accountID(prefix string, i int) string {
+	return prefix + strconv.Itoa(i)
+}
+
+func parseMix(mix string, prefix string, accounts int, hot bool) ([]loadgen.Operation, error) {
+	var operations []loadgen.Operation
+	for _, entry := range strings.Split(mix, ",") {
+		parts := strings.SplitN(entry, "=", 2)
+		if len(parts) != 2 {
+			return nil, fmt.Errorf("expected name=weight, got %q", entry)
+		}
+		weight, err := strconv.Atoi(parts[1])
+		if err != nil {
+			return nil, fmt.Errorf("invalid weight for %s: %v", parts[0], err)
+		}
+
+		switch parts[0] {
+		case "transfer":
+			operations = append(operations, loadgen.Operation{
+				Name:   "transfer",
+				Weight: weight,
+				Submit: true,
+				Args: func(r *rand.Rand) (string, []string) {
+					from := 0
+					if !hot {
+						from = r.Intn(accounts)
+					}
+					to := r.Intn(accounts - 1)
+					if to >= from {
+						to++
+					}
+					return "TransferFrom", []string{accountID(prefix, from), accountID(prefix, to), "1"}
+				},
+			})
+		case "balance":
+			operations = append(operations, loadgen.Operation{
+				Name:   "balance",
+				Weight: weight,
+				Args: func(r *rand.Rand) (string, []string) {
+					return "GetUser", []string{accountID(prefix, r.Intn(accounts))}
+				},
+			})
+		default:
+			return nil, fmt.Errorf("unknown operation %q", parts[0])
+		}
+	}
+
+	return operations, nil
+}
+
+func printReport(report *loadgen.Report) {
+	fmt.Printf("elapsed %s, skipped %d (concurrency limit reached)\n\n", report.Elapsed.Round(time.Millisecond), report.Skipped)
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "operation\trequests\ttps\terrors\tmvcc\tmvcc rate\tp50\tp90\tp99\tmax")
+	for _, s := range report.Operations {
+		mvccRate := 0.0
+		if s.Requests > 0 {
+			mvccRate = float64(s.MVCCConflicts) / float64(s.Requests) * 100
+		}
+		fmt.Fprintf(w, "%s\t%d\t%.1f\t%d\t%d\t%.2f%%\t%s\t%s\t%s\t%s\n",
+			s.Name, s.Requests, float64(s.Requests)/report.Elapsed.Seconds(), s.Errors, s.MVCCConflicts, mvccRate,
+			s.P50.Round(time.Millisecond), s.P90.Round(time.Millisecond), s.P99.Round(time.Millisecond), s.Max.Round(time.Millisecond))
+	}
+	w.Flush()
+}
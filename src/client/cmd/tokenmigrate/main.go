@@ -0,0 +1,116 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+*/
+
+// Command tokenmigrate switches the token ledger's record encoding and
+// rewrites the existing account and transaction records in it, or with
+// -schema upgrades them to a new schema version after a chaincode upgrade,
+// a bounded step per transaction, as an org admin. Steps skip records already
+// converted, so a failed run is resumed by running it again. The total
+// supply is compared before and after, unless -verify=false. Run it while
+// the ledger is quiet: a step conflicts with transactions writing the keys
+// it visits.
+//
+//	tokenmigrate -config connection-org1.yaml -wallet wallet -identity admin \
+//	    -encoding protobuf -limit 500
+//	tokenmigrate -config connection-org1.yaml -wallet wallet -identity admin \
+//	    -schema 2
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/core"
+	"github.com/hyperledger/fabric-sdk-go/pkg/core/config"
+	"github.com/hyperledger/fabric-sdk-go/pkg/gateway"
+	"github.com/kkiu1756/my_fabric/src/client/hsm"
+	"github.com/kkiu1756/my_fabric/src/client/migrate"
+	"github.com/kkiu1756/my_fabric/src/client/wallet"
+)
+
+func main() {
+	configPath := flag.String("config", "connection-org1.yaml", "SDK connection profile")
+	walletPath := flag.String("wallet", "wallet", "file system wallet directory")
+	identity := flag.String("identity", "admin", "wallet label of an org admin to submit as")
+	channel := flag.String("channel", "mychannel", "channel name")
+	chaincode := flag.String("chaincode", "basic", "token chaincode name")
+	encoding := flag.String("encoding", "protobuf", "record encoding to migrate to: json or protobuf")
+	limit := flag.Int("limit", 0, "records visited per transaction, 0 for the contract's default")
+	verify := flag.Bool("verify", true, "compare the total supply before and after the migration")
+	schema := flag.Int("schema", 0, "schema version to upgrade the records to from the previous one, instead of changing the encoding")
+	var hsmConfig hsm.Config
+	flag.StringVar(&hsmConfig.Library, "hsm-lib", "", "PKCS#11 library for identities held in an HSM")
+	flag.StringVar(&hsmConfig.Label, "hsm-label", "", "PKCS#11 token label")
+	flag.StringVar(&hsmConfig.Pin, "hsm-pin", os.Getenv("TOKENMIGRATE_HSM_PIN"), "PKCS#11 user PIN")
+	flag.Parse()
+
+	store, err := wallet.NewFileStore(*walletPath)
+	if err != nil {
+		log.Fatalf("Failed to open wallet: %v", err)
+	}
+
+	gw, closeGateway, err := connect(config.FromFile(*configPath), store, *identity, hsmConfig)
+	if err != nil {
+		log.Fatalf("Failed to connect as %s: %v", *identity, err)
+	}
+	defer closeGateway()
+
+	network, err := gw.GetNetwork(*channel)
+	if err != nil {
+		log.Fatalf("Failed to get network: %v", err)
+	}
+
+	contract := network.GetContract(*chaincode)
+	progress := func(p migrate.Progress) {
+		log.Printf("Migrated %d records (%s)", p.Migrated, p.Range)
+	}
+	target := *encoding
+	run := func() (int, error) { return migrate.Run(contract, *encoding, *limit, progress) }
+	if *schema > 0 {
+		target = fmt.Sprintf("schema v%d", *schema)
+		run = func() (int, error) { return migrate.Schema(contract, *schema-1, *schema, *limit, progress) }
+	}
+	var total int
+	if *verify {
+		total, err = migrate.Verified(contract, *limit, run)
+	} else {
+		total, err = run()
+	}
+	if err != nil {
+		closeGateway()
+		log.Fatalf("Migration stopped after %d records: %v (run again to resume)", total, err)
+	}
+
+	log.Printf("Migration to %s complete: %d records rewritten", target, total)
+}
+
+// connect opens a gateway as label, signing through the HSM if the wallet
+// holds only its certificate
+func connect(profile core.ConfigProvider, store wallet.Store, label string, hsmConfig hsm.Config) (*gateway.Gateway, func(), error) {
+	id, err := store.Get(label)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if id.HSM {
+		gw, err := hsm.Connect(profile, store, label, hsmConfig)
+		if err != nil {
+			return nil, nil, err
+		}
+		return gw.Gateway, gw.Close, nil
+	}
+
+	identity, err := wallet.IdentityOption(store, label)
+	if err != nil {
+		return nil, nil, err
+	}
+	gw, err := gateway.Connect(gateway.WithConfig(profile), identity)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return gw, gw.Close, nil
+}
@@ -0,0 +1,2823 @@
+// Code generated by clientgen. DO NOT EDIT.
+
+// Package contract is a typed client of the token contract
+package contract
+
+import (
+	"encoding/json"
+	"strconv"
+)
+
+// Invoker submits and evaluates contract transactions, as a gateway
+// Contract does
+type Invoker interface {
+	SubmitTransaction(name string, args ...string) ([]byte, error)
+	EvaluateTransaction(name string, args ...string) ([]byte, error)
+}
+
+// Client calls the contract's transactions through an Invoker
+type Client struct {
+	invoker Invoker
+}
+
+// New returns a client calling the contract through invoker
+func New(invoker Invoker) *Client {
+	return &Client{invoker: invoker}
+}
+
+// Account is the Account component of the contract metadata
+type Account struct {
+	Balance int    `json:"balance"`
+	Hot     bool   `json:"hot"`
+	Pending int    `json:"pending"`
+	Type    string `json:"type"`
+	UserID  string `json:"userId"`
+}
+
+// AccountDistribution is the AccountDistribution component of the contract metadata
+type AccountDistribution struct {
+	Accounts int                 `json:"accounts"`
+	Types    []*AccountTypeCount `json:"types"`
+}
+
+// AccountLink is the AccountLink component of the contract metadata
+type AccountLink struct {
+	Account    string `json:"account"`
+	Parent     string `json:"parent"`
+	Restricted bool   `json:"restricted"`
+	UpdatedAt  string `json:"updatedAt"`
+}
+
+// AccountPage is the AccountPage component of the contract metadata
+type AccountPage struct {
+	Accounts []*User `json:"accounts"`
+	Bookmark string  `json:"bookmark"`
+}
+
+// AccountTypeCount is the AccountTypeCount component of the contract metadata
+type AccountTypeCount struct {
+	Accounts int            `json:"accounts"`
+	Buckets  []*BucketCount `json:"buckets"`
+	Type     string         `json:"type"`
+}
+
+// Airdrop is the Airdrop component of the contract metadata
+type Airdrop struct {
+	PublishedAt string `json:"publishedAt"`
+	Root        string `json:"root"`
+	Source      string `json:"source"`
+}
+
+// Alias is the Alias component of the contract metadata
+type Alias struct {
+	Account   string `json:"account"`
+	Name      string `json:"name"`
+	UpdatedAt string `json:"updatedAt"`
+}
+
+// Attestation is the Attestation component of the contract metadata
+type Attestation struct {
+	Amount      int    `json:"amount"`
+	Auditor     string `json:"auditor"`
+	Currency    string `json:"currency"`
+	Issuer      string `json:"issuer"`
+	IssuerMspID string `json:"issuerMspId"`
+	Period      string `json:"period"`
+	ReportHash  string `json:"reportHash"`
+	Timestamp   string `json:"timestamp"`
+	TxID        string `json:"txId"`
+}
+
+// AuditPage is the AuditPage component of the contract metadata
+type AuditPage struct {
+	Bookmark string         `json:"bookmark"`
+	Records  []*AuditRecord `json:"records"`
+}
+
+// AuditRecord is the AuditRecord component of the contract metadata
+type AuditRecord struct {
+	Account     string `json:"account"`
+	Action      string `json:"action"`
+	Actor       string `json:"actor"`
+	ActorMspID  string `json:"actorMspId"`
+	After       int    `json:"after"`
+	Before      int    `json:"before"`
+	Detail      string `json:"detail,omitempty"`
+	Seq         int    `json:"seq"`
+	TxID        string `json:"txId"`
+	TxTimestamp string `json:"txTimestamp"`
+}
+
+// AuditorRead is the AuditorRead component of the contract metadata
+type AuditorRead struct {
+	Actor       string `json:"actor"`
+	ActorMspID  string `json:"actorMspId"`
+	Function    string `json:"function"`
+	Target      string `json:"target"`
+	TxID        string `json:"txId"`
+	TxTimestamp string `json:"txTimestamp"`
+}
+
+// AuditorReadPage is the AuditorReadPage component of the contract metadata
+type AuditorReadPage struct {
+	Bookmark string         `json:"bookmark"`
+	Reads    []*AuditorRead `json:"reads"`
+}
+
+// BootstrapResult is the BootstrapResult component of the contract metadata
+type BootstrapResult struct {
+	Created  int `json:"created"`
+	Existing int `json:"existing"`
+}
+
+// BucketCount is the BucketCount component of the contract metadata
+type BucketCount struct {
+	Accounts int    `json:"accounts"`
+	Bucket   string `json:"bucket"`
+}
+
+// CashbackPolicy is the CashbackPolicy component of the contract metadata
+type CashbackPolicy struct {
+	Pool string `json:"pool"`
+	Rate int    `json:"rate"`
+}
+
+// CollisionPage is the CollisionPage component of the contract metadata
+type CollisionPage struct {
+	Bookmark   string          `json:"bookmark"`
+	Collisions []*KeyCollision `json:"collisions"`
+}
+
+// CreditExposure is the CreditExposure component of the contract metadata
+type CreditExposure struct {
+	Account   string `json:"account"`
+	Available int    `json:"available"`
+	Drawn     int    `json:"drawn"`
+	Limit     int    `json:"limit"`
+}
+
+// CreditLine is the CreditLine component of the contract metadata
+type CreditLine struct {
+	Account   string `json:"account"`
+	Limit     int    `json:"limit"`
+	UpdatedAt string `json:"updatedAt"`
+}
+
+// Currency is the Currency component of the contract metadata
+type Currency struct {
+	Name         string `json:"name"`
+	RegisteredAt string `json:"registeredAt"`
+	Supply       int    `json:"supply"`
+	Symbol       string `json:"symbol"`
+}
+
+// CurrencyTransaction is the CurrencyTransaction component of the contract metadata
+type CurrencyTransaction struct {
+	Currency    string `json:"currency"`
+	From        string `json:"from"`
+	Rate        int    `json:"rate,omitempty"`
+	Received    int    `json:"received,omitempty"`
+	Spread      int    `json:"spread,omitempty"`
+	To          string `json:"to"`
+	ToCurrency  string `json:"toCurrency,omitempty"`
+	TxID        string `json:"txId"`
+	TxTimestamp string `json:"txTimestamp"`
+	Type        string `json:"type"`
+	Value       int    `json:"value"`
+}
+
+// Delegate is the Delegate component of the contract metadata
+type Delegate struct {
+	Account           string    `json:"account"`
+	AllowedRecipients []string  `json:"allowedRecipients"`
+	Delegate          *Identity `json:"delegate"`
+	ExpiresAt         string    `json:"expiresAt"`
+	MaxAmount         int       `json:"maxAmount"`
+}
+
+// DemurragePolicy is the DemurragePolicy component of the contract metadata
+type DemurragePolicy struct {
+	Rate  int    `json:"rate"`
+	Since string `json:"since"`
+}
+
+// Dispute is the Dispute component of the contract metadata
+type Dispute struct {
+	Arbiter      string `json:"arbiter,omitempty"`
+	ArbiterMspID string `json:"arbiterMspId,omitempty"`
+	From         string `json:"from"`
+	OpenedAt     string `json:"openedAt"`
+	Reason       string `json:"reason"`
+	Refund       int    `json:"refund,omitempty"`
+	ResolvedAt   string `json:"resolvedAt,omitempty"`
+	Response     string `json:"response,omitempty"`
+	Ruling       string `json:"ruling,omitempty"`
+	Status       string `json:"status"`
+	To           string `json:"to"`
+	TransferID   string `json:"transferId"`
+	Value        int    `json:"value"`
+}
+
+// DistributedChunk is the DistributedChunk component of the contract metadata
+type DistributedChunk struct {
+	Payments []*SnapshotWeight `json:"payments"`
+	TxID     string            `json:"txId"`
+}
+
+// Distribution is the Distribution component of the contract metadata
+type Distribution struct {
+	Cursor     int    `json:"cursor"`
+	Done       bool   `json:"done"`
+	Paid       int    `json:"paid"`
+	SnapshotID string `json:"snapshotId"`
+	Source     string `json:"source"`
+	Total      int    `json:"total"`
+}
+
+// DormancyFlag is the DormancyFlag component of the contract metadata
+type DormancyFlag struct {
+	Account      string `json:"account"`
+	FlaggedAt    string `json:"flaggedAt"`
+	LastActivity string `json:"lastActivity,omitempty"`
+	SweepableAt  string `json:"sweepableAt"`
+}
+
+// EmissionSchedule is the EmissionSchedule component of the contract metadata
+type EmissionSchedule struct {
+	Amount int    `json:"amount"`
+	Epoch  string `json:"epoch"`
+	Pool   string `json:"pool"`
+	Start  string `json:"start"`
+}
+
+// EmissionState is the EmissionState component of the contract metadata
+type EmissionState struct {
+	Epochs      int    `json:"epochs"`
+	Minted      int    `json:"minted"`
+	MintedUntil string `json:"mintedUntil"`
+}
+
+// EscheatmentPolicy is the EscheatmentPolicy component of the contract metadata
+type EscheatmentPolicy struct {
+	Account  string `json:"account"`
+	Dormancy string `json:"dormancy"`
+	Grace    string `json:"grace"`
+}
+
+// GiftCard is the GiftCard component of the contract metadata
+type GiftCard struct {
+	CodeHash   string `json:"codeHash"`
+	ExpiresAt  string `json:"expiresAt"`
+	IssuedAt   string `json:"issuedAt"`
+	Issuer     string `json:"issuer"`
+	RedeemedAt string `json:"redeemedAt,omitempty"`
+	RedeemedBy string `json:"redeemedBy,omitempty"`
+	Value      int    `json:"value"`
+}
+
+// GiftCardBreakage is the GiftCardBreakage component of the contract metadata
+type GiftCardBreakage struct {
+	Bookmark string `json:"bookmark"`
+	Cards    int    `json:"cards"`
+	Value    int    `json:"value"`
+}
+
+// GuardianSet is the GuardianSet component of the contract metadata
+type GuardianSet struct {
+	Account   string      `json:"account"`
+	Delay     string      `json:"delay"`
+	Guardians []*Identity `json:"guardians"`
+	Threshold int         `json:"threshold"`
+}
+
+// Holder is the Holder component of the contract metadata
+type Holder struct {
+	Account string `json:"account"`
+	Balance int    `json:"balance"`
+	Type    string `json:"type"`
+}
+
+// Identity is the Identity component of the contract metadata
+type Identity struct {
+	CommonName string `json:"commonName"`
+	MspID      string `json:"mspId"`
+}
+
+// ImportResult is the ImportResult component of the contract metadata
+type ImportResult struct {
+	Existing int `json:"existing"`
+	Imported int `json:"imported"`
+}
+
+// Initialization is the Initialization component of the contract metadata
+type Initialization struct {
+	AdminID                string             `json:"adminId"`
+	Airdrop                *Airdrop           `json:"airdrop,omitempty"`
+	Cashback               *CashbackPolicy    `json:"cashback,omitempty"`
+	ConfirmationThreshold  int                `json:"confirmationThreshold,omitempty"`
+	ConfirmationWindow     string             `json:"confirmationWindow,omitempty"`
+	ConversionSpread       int                `json:"conversionSpread,omitempty"`
+	Demurrage              *DemurragePolicy   `json:"demurrage,omitempty"`
+	DisputeWindow          string             `json:"disputeWindow,omitempty"`
+	Emission               *EmissionSchedule  `json:"emission,omitempty"`
+	Encoding               string             `json:"encoding,omitempty"`
+	Escheatment            *EscheatmentPolicy `json:"escheatment,omitempty"`
+	EventVerbosity         string             `json:"eventVerbosity,omitempty"`
+	Interest               []*InterestPolicy  `json:"interest,omitempty"`
+	LargeTransferThreshold int                `json:"largeTransferThreshold,omitempty"`
+	LegacyEventsUntil      string             `json:"legacyEventsUntil,omitempty"`
+	MspID                  string             `json:"mspId"`
+	Rebase                 *RebaseFactor      `json:"rebase,omitempty"`
+	Referral               *ReferralProgram   `json:"referral,omitempty"`
+	Schema                 int                `json:"schema,omitempty"`
+	Shards                 int                `json:"shards,omitempty"`
+	TravelRuleThreshold    int                `json:"travelRuleThreshold,omitempty"`
+	TxID                   string             `json:"txId"`
+	Version                string             `json:"version,omitempty"`
+}
+
+// InterestPolicy is the InterestPolicy component of the contract metadata
+type InterestPolicy struct {
+	AccountType string `json:"accountType"`
+	Rate        int    `json:"rate"`
+	Since       string `json:"since"`
+}
+
+// Item is the Item component of the contract metadata
+type Item struct {
+	ID       string `json:"id"`
+	ListedAt string `json:"listedAt"`
+	Name     string `json:"name"`
+	Price    int    `json:"price"`
+	Seller   string `json:"seller"`
+	Stock    int    `json:"stock"`
+}
+
+// ItemPage is the ItemPage component of the contract metadata
+type ItemPage struct {
+	Bookmark string  `json:"bookmark"`
+	Items    []*Item `json:"items"`
+}
+
+// KeyCollision is the KeyCollision component of the contract metadata
+type KeyCollision struct {
+	Key    string `json:"key"`
+	Reason string `json:"reason"`
+}
+
+// LargeTransferReview is the LargeTransferReview component of the contract metadata
+type LargeTransferReview struct {
+	Currency    string `json:"currency,omitempty"`
+	From        string `json:"from"`
+	Threshold   int    `json:"threshold"`
+	To          string `json:"to"`
+	TxID        string `json:"txId"`
+	TxTimestamp string `json:"txTimestamp"`
+	Value       int    `json:"value"`
+}
+
+// MigrationPage is the MigrationPage component of the contract metadata
+type MigrationPage struct {
+	Bookmark string `json:"bookmark"`
+	Migrated int    `json:"migrated"`
+}
+
+// PaymentRequest is the PaymentRequest component of the contract metadata
+type PaymentRequest struct {
+	From        string `json:"from"`
+	ID          string `json:"id"`
+	Reference   string `json:"reference"`
+	To          string `json:"to"`
+	TxTimestamp string `json:"txTimestamp"`
+	Value       int    `json:"value"`
+}
+
+// Payout is the Payout component of the contract metadata
+type Payout struct {
+	Account   string `json:"account"`
+	Amount    int    `json:"amount"`
+	Period    string `json:"period"`
+	Seller    string `json:"seller"`
+	Timestamp string `json:"timestamp"`
+	TxID      string `json:"txId"`
+}
+
+// PendingConfirmation is the PendingConfirmation component of the contract metadata
+type PendingConfirmation struct {
+	ExpiresAt     string `json:"expiresAt"`
+	From          string `json:"from"`
+	ID            string `json:"id"`
+	Proposer      string `json:"proposer"`
+	ProposerMspID string `json:"proposerMspId"`
+	To            string `json:"to"`
+	TxTimestamp   string `json:"txTimestamp"`
+	Value         int    `json:"value"`
+}
+
+// PendingTransfer is the PendingTransfer component of the contract metadata
+type PendingTransfer struct {
+	From           string `json:"from"`
+	ID             string `json:"id"`
+	Requester      string `json:"requester"`
+	RequesterMspID string `json:"requesterMspId"`
+	To             string `json:"to"`
+	TxTimestamp    string `json:"txTimestamp"`
+	Value          int    `json:"value"`
+}
+
+// Rate is the Rate component of the contract metadata
+type Rate struct {
+	From      string `json:"from"`
+	Rate      int    `json:"rate"`
+	To        string `json:"to"`
+	UpdatedAt string `json:"updatedAt"`
+}
+
+// RebaseFactor is the RebaseFactor component of the contract metadata
+type RebaseFactor struct {
+	Cumulative  string `json:"cumulative"`
+	Denominator int    `json:"denominator"`
+	Generation  int    `json:"generation"`
+	Numerator   int    `json:"numerator"`
+	Timestamp   string `json:"timestamp"`
+	TxID        string `json:"txId"`
+}
+
+// RebasePage is the RebasePage component of the contract metadata
+type RebasePage struct {
+	Bookmark string          `json:"bookmark"`
+	Rebases  []*RebaseFactor `json:"rebases"`
+}
+
+// Receipt is the Receipt component of the contract metadata
+type Receipt struct {
+	Buyer     string `json:"buyer"`
+	Hash      string `json:"hash"`
+	ItemID    string `json:"itemId"`
+	Price     int    `json:"price"`
+	Seller    string `json:"seller"`
+	Timestamp string `json:"timestamp"`
+	TxID      string `json:"txId"`
+}
+
+// Recovery is the Recovery component of the contract metadata
+type Recovery struct {
+	Account   string      `json:"account"`
+	Approvals []*Identity `json:"approvals"`
+	NewHolder *Identity   `json:"newHolder"`
+	ReadyAt   string      `json:"readyAt,omitempty"`
+}
+
+// Referral is the Referral component of the contract metadata
+type Referral struct {
+	Account       string `json:"account"`
+	Bonus         int    `json:"bonus,omitempty"`
+	QualifiedTxID string `json:"qualifiedTxId,omitempty"`
+	Referrer      string `json:"referrer"`
+	RegisteredAt  string `json:"registeredAt"`
+}
+
+// ReferralProgram is the ReferralProgram component of the contract metadata
+type ReferralProgram struct {
+	Bonus          int    `json:"bonus"`
+	Cap            int    `json:"cap,omitempty"`
+	MaxPerReferrer int    `json:"maxPerReferrer,omitempty"`
+	MinTransfer    int    `json:"minTransfer"`
+	Pool           string `json:"pool"`
+}
+
+// RefundRecord is the RefundRecord component of the contract metadata
+type RefundRecord struct {
+	Amount    int    `json:"amount"`
+	Timestamp string `json:"timestamp"`
+	TxID      string `json:"txId"`
+}
+
+// RefundStatus is the RefundStatus component of the contract metadata
+type RefundStatus struct {
+	OriginalTxID string `json:"originalTxId"`
+	Refunded     int    `json:"refunded"`
+	Remaining    int    `json:"remaining"`
+	Value        int    `json:"value"`
+}
+
+// Refunds is the Refunds component of the contract metadata
+type Refunds struct {
+	OriginalTxID string          `json:"originalTxId"`
+	Refunded     int             `json:"refunded"`
+	Refunds      []*RefundRecord `json:"refunds"`
+	Value        int             `json:"value"`
+}
+
+// ReserveComparison is the ReserveComparison component of the contract metadata
+type ReserveComparison struct {
+	Supply  int `json:"supply"`
+	Surplus int `json:"surplus"`
+}
+
+// ReversibleTransfer is the ReversibleTransfer component of the contract metadata
+type ReversibleTransfer struct {
+	FinalAt     string `json:"finalAt"`
+	From        string `json:"from"`
+	ID          string `json:"id"`
+	To          string `json:"to"`
+	TxTimestamp string `json:"txTimestamp"`
+	Value       int    `json:"value"`
+}
+
+// ReviewPage is the ReviewPage component of the contract metadata
+type ReviewPage struct {
+	Bookmark string                 `json:"bookmark"`
+	Reviews  []*LargeTransferReview `json:"reviews"`
+}
+
+// RevokedCertificate is the RevokedCertificate component of the contract metadata
+type RevokedCertificate struct {
+	Fingerprint  string `json:"fingerprint"`
+	Reason       string `json:"reason,omitempty"`
+	Revoker      string `json:"revoker"`
+	RevokerMspID string `json:"revokerMspId"`
+	TxID         string `json:"txId"`
+	TxTimestamp  string `json:"txTimestamp"`
+}
+
+// RollupBalance is the RollupBalance component of the contract metadata
+type RollupBalance struct {
+	Account  string `json:"account"`
+	Accounts int    `json:"accounts"`
+	Balance  int    `json:"balance"`
+	Total    int    `json:"total"`
+}
+
+// SettlementAccount is the SettlementAccount component of the contract metadata
+type SettlementAccount struct {
+	Account string `json:"account"`
+	Seller  string `json:"seller"`
+}
+
+// SettlementBatch is the SettlementBatch component of the contract metadata
+type SettlementBatch struct {
+	Payouts []*Payout `json:"payouts"`
+	Settled []string  `json:"settled"`
+	Skipped []string  `json:"skipped"`
+}
+
+// SettlementReport is the SettlementReport component of the contract metadata
+type SettlementReport struct {
+	Bookmark string    `json:"bookmark"`
+	Payouts  []*Payout `json:"payouts"`
+}
+
+// Snapshot is the Snapshot component of the contract metadata
+type Snapshot struct {
+	ID          string            `json:"id"`
+	Timestamp   string            `json:"timestamp"`
+	TotalWeight int               `json:"totalWeight"`
+	Weights     []*SnapshotWeight `json:"weights"`
+}
+
+// SnapshotWeight is the SnapshotWeight component of the contract metadata
+type SnapshotWeight struct {
+	Account string `json:"account"`
+	Weight  int    `json:"weight"`
+}
+
+// StatePage is the StatePage component of the contract metadata
+type StatePage struct {
+	Bookmark string         `json:"bookmark"`
+	Checksum string         `json:"checksum"`
+	Records  []*StateRecord `json:"records"`
+	Schema   int            `json:"schema"`
+	Section  string         `json:"section"`
+	Shards   int            `json:"shards"`
+}
+
+// StateRecord is the StateRecord component of the contract metadata
+type StateRecord struct {
+	Key   string  `json:"key"`
+	Value []int32 `json:"value"`
+}
+
+// Statement is the Statement component of the contract metadata
+type Statement struct {
+	Account        string           `json:"account"`
+	ClosingBalance int              `json:"closingBalance"`
+	From           string           `json:"from"`
+	Lines          []*StatementLine `json:"lines"`
+	OpeningBalance int              `json:"openingBalance"`
+	To             string           `json:"to"`
+}
+
+// StatementLine is the StatementLine component of the contract metadata
+type StatementLine struct {
+	Amount       int    `json:"amount"`
+	Balance      int    `json:"balance"`
+	Counterparty string `json:"counterparty"`
+	TxID         string `json:"txId"`
+	TxTimestamp  string `json:"txTimestamp"`
+	Type         string `json:"type"`
+}
+
+// SupplyPage is the SupplyPage component of the contract metadata
+type SupplyPage struct {
+	Accounts int    `json:"accounts"`
+	Bookmark string `json:"bookmark"`
+	Supply   int    `json:"supply"`
+}
+
+// Suspension is the Suspension component of the contract metadata
+type Suspension struct {
+	Account        string `json:"account"`
+	AppealNote     string `json:"appealNote,omitempty"`
+	ExpiresAt      string `json:"expiresAt,omitempty"`
+	Note           string `json:"note,omitempty"`
+	Reason         string `json:"reason"`
+	Suspender      string `json:"suspender,omitempty"`
+	SuspenderMspID string `json:"suspenderMspId,omitempty"`
+	TxTimestamp    string `json:"txTimestamp,omitempty"`
+}
+
+// SuspensionPage is the SuspensionPage component of the contract metadata
+type SuspensionPage struct {
+	Bookmark    string        `json:"bookmark"`
+	Suspensions []*Suspension `json:"suspensions"`
+}
+
+// Transaction is the Transaction component of the contract metadata
+type Transaction struct {
+	ChannelID      string `json:"channelId"`
+	CorrelationID  string `json:"correlationId,omitempty"`
+	From           string `json:"from"`
+	Initiator      string `json:"initiator"`
+	InitiatorMspID string `json:"initiatorMspId"`
+	To             string `json:"to"`
+	TxID           string `json:"txId"`
+	TxTimestamp    string `json:"txTimestamp"`
+	Type           string `json:"type"`
+	Value          int    `json:"value"`
+}
+
+// TransactionPage is the TransactionPage component of the contract metadata
+type TransactionPage struct {
+	Bookmark     string         `json:"bookmark"`
+	Transactions []*Transaction `json:"transactions"`
+}
+
+// TransferLimit is the TransferLimit component of the contract metadata
+type TransferLimit struct {
+	Account     string `json:"account"`
+	DailyLimit  int    `json:"dailyLimit"`
+	MaxTransfer int    `json:"maxTransfer"`
+	Policy      string `json:"policy"`
+}
+
+// TravelRuleParty is the TravelRuleParty component of the contract metadata
+type TravelRuleParty struct {
+	Address string `json:"address,omitempty"`
+	ID      string `json:"id,omitempty"`
+	Name    string `json:"name"`
+}
+
+// TravelRuleRecord is the TravelRuleRecord component of the contract metadata
+type TravelRuleRecord struct {
+	Beneficiary *TravelRuleParty `json:"beneficiary"`
+	From        string           `json:"from"`
+	Originator  *TravelRuleParty `json:"originator"`
+	To          string           `json:"to"`
+	TxID        string           `json:"txId"`
+	Value       int              `json:"value"`
+}
+
+// Treasury is the Treasury component of the contract metadata
+type Treasury struct {
+	Account   string `json:"account"`
+	MspID     string `json:"mspId"`
+	Threshold int    `json:"threshold"`
+}
+
+// TreasuryPayment is the TreasuryPayment component of the contract metadata
+type TreasuryPayment struct {
+	Approver      string `json:"approver,omitempty"`
+	DecidedAt     string `json:"decidedAt,omitempty"`
+	DecisionTxID  string `json:"decisionTxId,omitempty"`
+	ID            string `json:"id"`
+	Memo          string `json:"memo,omitempty"`
+	ProposedAt    string `json:"proposedAt"`
+	Proposer      string `json:"proposer"`
+	ProposerMspID string `json:"proposerMspId"`
+	Status        string `json:"status"`
+	To            string `json:"to"`
+	Treasury      string `json:"treasury"`
+	Value         int    `json:"value"`
+}
+
+// UpgradeResult is the UpgradeResult component of the contract metadata
+type UpgradeResult struct {
+	Bookmark string `json:"bookmark"`
+	Migrated int    `json:"migrated"`
+	Previous string `json:"previous"`
+	Schema   int    `json:"schema"`
+	Version  string `json:"version"`
+}
+
+// User is the User component of the contract metadata
+type User struct {
+	Balance int    `json:"balance"`
+	Hot     bool   `json:"hot,omitempty"`
+	Rebase  int    `json:"rebase,omitempty"`
+	Type    string `json:"type"`
+	UserID  string `json:"userId"`
+}
+
+// VersionInfo is the VersionInfo component of the contract metadata
+type VersionInfo struct {
+	LedgerSchema  int    `json:"ledgerSchema"`
+	LedgerVersion string `json:"ledgerVersion"`
+	Schema        int    `json:"schema"`
+	Version       string `json:"version"`
+}
+
+// Wallet is the Wallet component of the contract metadata
+type Wallet struct {
+	Account   string    `json:"account"`
+	CreatedAt string    `json:"createdAt"`
+	Name      string    `json:"name"`
+	Owner     *Identity `json:"owner"`
+}
+
+// WalletBalance is the WalletBalance component of the contract metadata
+type WalletBalance struct {
+	Balance int `json:"balance"`
+}
+
+// WalletPage is the WalletPage component of the contract metadata
+type WalletPage struct {
+	Bookmark string           `json:"bookmark"`
+	Wallets  []*WalletBalance `json:"wallets"`
+}
+
+// Whitelist is the Whitelist component of the contract metadata
+type Whitelist struct {
+	Name        string `json:"name"`
+	PublishedAt string `json:"publishedAt"`
+	Root        string `json:"root"`
+}
+
+// AcceptPayment pays the payment requested by transaction id. Only the
+// holder of the payer's account can call it.
+func (c *Client) AcceptPayment(id string) (*Transaction, error) {
+	var result *Transaction
+	payload, err := c.invoker.SubmitTransaction("AcceptPayment", id)
+	if err != nil {
+		return result, err
+	}
+	err = json.Unmarshal(payload, &result)
+	return result, err
+}
+
+// AccrueInterest settles the interest due on account and returns it with
+// the new balance
+func (c *Client) AccrueInterest(account string) (*User, error) {
+	var result *User
+	payload, err := c.invoker.SubmitTransaction("AccrueInterest", account)
+	if err != nil {
+		return result, err
+	}
+	err = json.Unmarshal(payload, &result)
+	return result, err
+}
+
+// AddItem adds itemID, name, to the catalog of seller at price with stock
+// items to sell. Only the holder of the seller account can call it.
+func (c *Client) AddItem(sellerID string, itemID string, name string, price int, stock int) (*Item, error) {
+	var result *Item
+	payload, err := c.invoker.SubmitTransaction("AddItem", sellerID, itemID, name, strconv.Itoa(price), strconv.Itoa(stock))
+	if err != nil {
+		return result, err
+	}
+	err = json.Unmarshal(payload, &result)
+	return result, err
+}
+
+// AirdropClaimed reports whether account has claimed the published airdrop
+func (c *Client) AirdropClaimed(account string) (bool, error) {
+	var result bool
+	payload, err := c.invoker.EvaluateTransaction("AirdropClaimed", account)
+	if err != nil {
+		return result, err
+	}
+	err = json.Unmarshal(payload, &result)
+	return result, err
+}
+
+// ApproveRecovery approves binding account to the client of newMSPID whose
+// certificate common name is newCommonName. The first approval starts the
+// recovery; the one reaching the threshold starts its delay. Only a
+// guardian of the account can call it.
+func (c *Client) ApproveRecovery(account string, newMSPID string, newCommonName string) (*Recovery, error) {
+	var result *Recovery
+	payload, err := c.invoker.SubmitTransaction("ApproveRecovery", account, newMSPID, newCommonName)
+	if err != nil {
+		return result, err
+	}
+	err = json.Unmarshal(payload, &result)
+	return result, err
+}
+
+// ApproveTransfer executes the transfer requested by transaction id,
+// whatever the limits of its sender; it still counts towards the daily
+// total. Only an org admin can call it.
+func (c *Client) ApproveTransfer(id string) (*Transaction, error) {
+	var result *Transaction
+	payload, err := c.invoker.SubmitTransaction("ApproveTransfer", id)
+	if err != nil {
+		return result, err
+	}
+	err = json.Unmarshal(payload, &result)
+	return result, err
+}
+
+// ApproveTreasuryPayment executes the pending treasury payment id. Only an
+// admin of the treasury's organization can call it.
+func (c *Client) ApproveTreasuryPayment(id string) (*TreasuryPayment, error) {
+	var result *TreasuryPayment
+	payload, err := c.invoker.SubmitTransaction("ApproveTreasuryPayment", id)
+	if err != nil {
+		return result, err
+	}
+	err = json.Unmarshal(payload, &result)
+	return result, err
+}
+
+// AttestReserves publishes amount as the reserves backing currency symbol
+// in period, attested by auditor in the report of hex encoded SHA-256
+// reportHash. Only an issuer can call it.
+func (c *Client) AttestReserves(symbol string, period string, amount int, auditor string, reportHash string) (*Attestation, error) {
+	var result *Attestation
+	payload, err := c.invoker.SubmitTransaction("AttestReserves", symbol, period, strconv.Itoa(amount), auditor, reportHash)
+	if err != nil {
+		return result, err
+	}
+	err = json.Unmarshal(payload, &result)
+	return result, err
+}
+
+// BootstrapLedger creates the initial accounts listed in accountsJSON, a
+// JSON array of account records as GetUser returns them, and writes them
+// as CreateUser does. No account can be hot or take the name of an alias;
+// mark hot accounts with SetHotAccount after the load. A chunk is all or
+// nothing, and accounts already stored with an identical record are
+// skipped, so a load that stopped part way continues by resubmitting the
+// chunks from the first one that did not commit. Only an org admin can
+// call it.
+//
+// The chunk is recorded as one Transaction of type bootstrap whose value is
+// the total balance created.
+func (c *Client) BootstrapLedger(accountsJSON string) (*BootstrapResult, error) {
+	var result *BootstrapResult
+	payload, err := c.invoker.SubmitTransaction("BootstrapLedger", accountsJSON)
+	if err != nil {
+		return result, err
+	}
+	err = json.Unmarshal(payload, &result)
+	return result, err
+}
+
+// BurnCurrency debits amount of currency symbol from account, taking it
+// from the supply. Only an org admin can call it.
+func (c *Client) BurnCurrency(symbol string, account string, amount int) (*CurrencyTransaction, error) {
+	var result *CurrencyTransaction
+	payload, err := c.invoker.SubmitTransaction("BurnCurrency", symbol, account, strconv.Itoa(amount))
+	if err != nil {
+		return result, err
+	}
+	err = json.Unmarshal(payload, &result)
+	return result, err
+}
+
+// CancelRecovery stops the recovery of account underway. Only the account
+// holder can call it.
+func (c *Client) CancelRecovery(account string) error {
+	_, err := c.invoker.SubmitTransaction("CancelRecovery", account)
+	return err
+}
+
+// CancelTransfer drops the transfer proposed by transaction id. Its
+// proposer, its recipient or a compliance officer can call it.
+func (c *Client) CancelTransfer(id string) error {
+	_, err := c.invoker.SubmitTransaction("CancelTransfer", id)
+	return err
+}
+
+// ClaimAirdrop pays the caller's account its entitlement of amount in the
+// published airdrop. proof is a JSON array of the hex encoded sibling
+// hashes from the entitlement's leaf to the root.
+func (c *Client) ClaimAirdrop(amount int, proof string) (*Transaction, error) {
+	var result *Transaction
+	payload, err := c.invoker.SubmitTransaction("ClaimAirdrop", strconv.Itoa(amount), proof)
+	if err != nil {
+		return result, err
+	}
+	err = json.Unmarshal(payload, &result)
+	return result, err
+}
+
+// ClaimTransfer pays the reversible transfer sent by transaction id to its
+// recipient once its dispute window has ended, unless it is disputed, see
+// dispute.go. Anyone can call it.
+func (c *Client) ClaimTransfer(id string) (*Transaction, error) {
+	var result *Transaction
+	payload, err := c.invoker.SubmitTransaction("ClaimTransfer", id)
+	if err != nil {
+		return result, err
+	}
+	err = json.Unmarshal(payload, &result)
+	return result, err
+}
+
+// ClearDormancyFlag removes the dormancy flag of account. The account
+// holder or an org admin can call it.
+func (c *Client) ClearDormancyFlag(account string) error {
+	_, err := c.invoker.SubmitTransaction("ClearDormancyFlag", account)
+	return err
+}
+
+// CompactAccountCounts folds the count changes written by each transaction
+// into one entry per counter. Only an org admin can call it.
+func (c *Client) CompactAccountCounts() (*AccountDistribution, error) {
+	var result *AccountDistribution
+	payload, err := c.invoker.SubmitTransaction("CompactAccountCounts")
+	if err != nil {
+		return result, err
+	}
+	err = json.Unmarshal(payload, &result)
+	return result, err
+}
+
+// CompareReserves compares the reserves attested for currency symbol in
+// period with its current supply
+func (c *Client) CompareReserves(symbol string, period string) (*ReserveComparison, error) {
+	var result *ReserveComparison
+	payload, err := c.invoker.EvaluateTransaction("CompareReserves", symbol, period)
+	if err != nil {
+		return result, err
+	}
+	err = json.Unmarshal(payload, &result)
+	return result, err
+}
+
+// CompleteRecovery binds account to the new holder of its recovery, once
+// the threshold of guardians approved it and its delay has passed. Anyone
+// can call it.
+func (c *Client) CompleteRecovery(account string) (*Recovery, error) {
+	var result *Recovery
+	payload, err := c.invoker.SubmitTransaction("CompleteRecovery", account)
+	if err != nil {
+		return result, err
+	}
+	err = json.Unmarshal(payload, &result)
+	return result, err
+}
+
+// ConfirmTransfer executes the transfer proposed by transaction id. Only
+// its recipient or a compliance officer can call it, before the
+// confirmation window ends. The sender's limits apply as to any transfer.
+func (c *Client) ConfirmTransfer(id string) (*Transaction, error) {
+	var result *Transaction
+	payload, err := c.invoker.SubmitTransaction("ConfirmTransfer", id)
+	if err != nil {
+		return result, err
+	}
+	err = json.Unmarshal(payload, &result)
+	return result, err
+}
+
+// Convert burns amount of fromCurrency from the caller's account and mints
+// it in toCurrency at the published rate less the conversion spread,
+// rounded down
+func (c *Client) Convert(fromCurrency string, toCurrency string, amount int) (*CurrencyTransaction, error) {
+	var result *CurrencyTransaction
+	payload, err := c.invoker.SubmitTransaction("Convert", fromCurrency, toCurrency, strconv.Itoa(amount))
+	if err != nil {
+		return result, err
+	}
+	err = json.Unmarshal(payload, &result)
+	return result, err
+}
+
+// CreateUser submits the CreateUser transaction
+func (c *Client) CreateUser(id string, typeArg string, balance int) (*User, error) {
+	var result *User
+	payload, err := c.invoker.SubmitTransaction("CreateUser", id, typeArg, strconv.Itoa(balance))
+	if err != nil {
+		return result, err
+	}
+	err = json.Unmarshal(payload, &result)
+	return result, err
+}
+
+// CreateWallet opens the wallet name of the caller, an account of
+// walletType
+func (c *Client) CreateWallet(name string, walletType string) (*Wallet, error) {
+	var result *Wallet
+	payload, err := c.invoker.SubmitTransaction("CreateWallet", name, walletType)
+	if err != nil {
+		return result, err
+	}
+	err = json.Unmarshal(payload, &result)
+	return result, err
+}
+
+// DeclinePayment drops the payment requested by transaction id. Only the
+// holder of the payer's account can call it.
+func (c *Client) DeclinePayment(id string) error {
+	_, err := c.invoker.SubmitTransaction("DeclinePayment", id)
+	return err
+}
+
+// DelegatedTransfer transfers value from account to to on behalf of its
+// holder, within the scope of the caller's delegation, which is advisory
+// as TransferFrom is open to any client
+func (c *Client) DelegatedTransfer(account string, to string, value int) (*Transaction, error) {
+	var result *Transaction
+	payload, err := c.invoker.SubmitTransaction("DelegatedTransfer", account, to, strconv.Itoa(value))
+	if err != nil {
+		return result, err
+	}
+	err = json.Unmarshal(payload, &result)
+	return result, err
+}
+
+// DeleteUser submits the DeleteUser transaction
+func (c *Client) DeleteUser(id string) error {
+	_, err := c.invoker.SubmitTransaction("DeleteUser", id)
+	return err
+}
+
+// DistributeProRata pays the next chunk of the distribution of totalAmount
+// from sourceAccount to the accounts of snapshot snapshotID, starting the
+// distribution on the first call. Calls are repeated with the same
+// arguments until the returned chunk is done. Only the holder of the
+// source or an org admin can call it.
+func (c *Client) DistributeProRata(sourceAccount string, totalAmount int, snapshotID string) (*DistributedChunk, error) {
+	var result *DistributedChunk
+	payload, err := c.invoker.SubmitTransaction("DistributeProRata", sourceAccount, strconv.Itoa(totalAmount), snapshotID)
+	if err != nil {
+		return result, err
+	}
+	err = json.Unmarshal(payload, &result)
+	return result, err
+}
+
+// EscheatAccount sweeps the balance of account, flagged dormant and
+// inactive since, to the escheatment account once its grace period has
+// ended. Demurrage due is settled first; the sweep pays no referral bonus
+// or cashback. Only an org admin can call it.
+func (c *Client) EscheatAccount(account string) (*Transaction, error) {
+	var result *Transaction
+	payload, err := c.invoker.SubmitTransaction("EscheatAccount", account)
+	if err != nil {
+		return result, err
+	}
+	err = json.Unmarshal(payload, &result)
+	return result, err
+}
+
+// ExpireTransfer drops the transfer proposed by transaction id once its
+// confirmation window has ended. Anyone can call it.
+func (c *Client) ExpireTransfer(id string) error {
+	_, err := c.invoker.SubmitTransaction("ExpireTransfer", id)
+	return err
+}
+
+// ExportState returns a page of at most pageSize records of section, one of
+// Sections, from bookmark, which is empty for the first page. Only an org
+// admin can call it.
+func (c *Client) ExportState(section string, pageSize int, bookmark string) (*StatePage, error) {
+	var result *StatePage
+	payload, err := c.invoker.EvaluateTransaction("ExportState", section, strconv.Itoa(pageSize), bookmark)
+	if err != nil {
+		return result, err
+	}
+	err = json.Unmarshal(payload, &result)
+	return result, err
+}
+
+// FindKeyCollisions scans the world state for account records created
+// before account ids were restricted: ids starting with "_" or shaped like
+// a transaction id, whose record a transaction record can overwrite. The
+// accounts keep working; operators should move their balances to new ids.
+// It scans pageSize records from bookmark per call, bounded by the query
+// policy, so a full scan takes as many calls as the state is large.
+func (c *Client) FindKeyCollisions(pageSize int, bookmark string) (*CollisionPage, error) {
+	var result *CollisionPage
+	payload, err := c.invoker.EvaluateTransaction("FindKeyCollisions", strconv.Itoa(pageSize), bookmark)
+	if err != nil {
+		return result, err
+	}
+	err = json.Unmarshal(payload, &result)
+	return result, err
+}
+
+// FlagDormantAccount flags account as dormant if its balance has not
+// changed for the policy's dormancy period, starting its grace period.
+// Anyone can call it.
+func (c *Client) FlagDormantAccount(account string) (*DormancyFlag, error) {
+	var result *DormancyFlag
+	payload, err := c.invoker.SubmitTransaction("FlagDormantAccount", account)
+	if err != nil {
+		return result, err
+	}
+	err = json.Unmarshal(payload, &result)
+	return result, err
+}
+
+// GenerateStatement returns the statement of account from the start of
+// fromDate to the end of toDate, both YYYY-MM-DD in UTC. The opening
+// balance is worked back from the current balance, pending credits
+// included, through the later index entries. A deleted account has a
+// current balance of 0.
+func (c *Client) GenerateStatement(account string, fromDate string, toDate string) (*Statement, error) {
+	var result *Statement
+	payload, err := c.invoker.EvaluateTransaction("GenerateStatement", account, fromDate, toDate)
+	if err != nil {
+		return result, err
+	}
+	err = json.Unmarshal(payload, &result)
+	return result, err
+}
+
+// GetAccount returns the account stored under id with its pending
+// credits. It reads the account record once and queries the deltas only
+// for a hot account.
+func (c *Client) GetAccount(id string) (*Account, error) {
+	var result *Account
+	payload, err := c.invoker.EvaluateTransaction("GetAccount", id)
+	if err != nil {
+		return result, err
+	}
+	err = json.Unmarshal(payload, &result)
+	return result, err
+}
+
+// GetAccountDistribution returns the account counts by type and balance
+// bucket
+func (c *Client) GetAccountDistribution() (*AccountDistribution, error) {
+	var result *AccountDistribution
+	payload, err := c.invoker.EvaluateTransaction("GetAccountDistribution")
+	if err != nil {
+		return result, err
+	}
+	err = json.Unmarshal(payload, &result)
+	return result, err
+}
+
+// GetAlias returns the alias name
+func (c *Client) GetAlias(name string) (*Alias, error) {
+	var result *Alias
+	payload, err := c.invoker.EvaluateTransaction("GetAlias", name)
+	if err != nil {
+		return result, err
+	}
+	err = json.Unmarshal(payload, &result)
+	return result, err
+}
+
+// GetAttestation returns the attestation of the reserves of currency
+// symbol in period
+func (c *Client) GetAttestation(symbol string, period string) (*Attestation, error) {
+	var result *Attestation
+	payload, err := c.invoker.EvaluateTransaction("GetAttestation", symbol, period)
+	if err != nil {
+		return result, err
+	}
+	err = json.Unmarshal(payload, &result)
+	return result, err
+}
+
+// GetAuditTrail returns a page of at most pageSize records of account's
+// audit trail, oldest first, from bookmark, which is empty for the first
+// page
+func (c *Client) GetAuditTrail(account string, pageSize int, bookmark string) (*AuditPage, error) {
+	var result *AuditPage
+	payload, err := c.invoker.EvaluateTransaction("GetAuditTrail", account, strconv.Itoa(pageSize), bookmark)
+	if err != nil {
+		return result, err
+	}
+	err = json.Unmarshal(payload, &result)
+	return result, err
+}
+
+// GetAuditorReads returns a page of at most pageSize entries of the auditor
+// read log, in transaction id order, from bookmark, which is empty for the
+// first page. Only an org admin can call it.
+func (c *Client) GetAuditorReads(pageSize int, bookmark string) (*AuditorReadPage, error) {
+	var result *AuditorReadPage
+	payload, err := c.invoker.EvaluateTransaction("GetAuditorReads", strconv.Itoa(pageSize), bookmark)
+	if err != nil {
+		return result, err
+	}
+	err = json.Unmarshal(payload, &result)
+	return result, err
+}
+
+// GetCreditExposure returns the credit line of account and how much of it
+// is drawn
+func (c *Client) GetCreditExposure(account string) (*CreditExposure, error) {
+	var result *CreditExposure
+	payload, err := c.invoker.EvaluateTransaction("GetCreditExposure", account)
+	if err != nil {
+		return result, err
+	}
+	err = json.Unmarshal(payload, &result)
+	return result, err
+}
+
+// GetCurrency returns currency symbol with its supply
+func (c *Client) GetCurrency(symbol string) (*Currency, error) {
+	var result *Currency
+	payload, err := c.invoker.EvaluateTransaction("GetCurrency", symbol)
+	if err != nil {
+		return result, err
+	}
+	err = json.Unmarshal(payload, &result)
+	return result, err
+}
+
+// GetCurrencyBalance returns the balance of account in currency symbol
+func (c *Client) GetCurrencyBalance(symbol string, account string) (int, error) {
+	var result int
+	payload, err := c.invoker.EvaluateTransaction("GetCurrencyBalance", symbol, account)
+	if err != nil {
+		return result, err
+	}
+	err = json.Unmarshal(payload, &result)
+	return result, err
+}
+
+// GetCurrencyTransaction returns the currency operation of transaction txid
+func (c *Client) GetCurrencyTransaction(txid string) (*CurrencyTransaction, error) {
+	var result *CurrencyTransaction
+	payload, err := c.invoker.EvaluateTransaction("GetCurrencyTransaction", txid)
+	if err != nil {
+		return result, err
+	}
+	err = json.Unmarshal(payload, &result)
+	return result, err
+}
+
+// GetDelegate returns the delegation of account to the certificate
+// commonName of mspID
+func (c *Client) GetDelegate(account string, mspID string, commonName string) (*Delegate, error) {
+	var result *Delegate
+	payload, err := c.invoker.EvaluateTransaction("GetDelegate", account, mspID, commonName)
+	if err != nil {
+		return result, err
+	}
+	err = json.Unmarshal(payload, &result)
+	return result, err
+}
+
+// GetDispute returns the dispute of transfer id
+func (c *Client) GetDispute(id string) (*Dispute, error) {
+	var result *Dispute
+	payload, err := c.invoker.EvaluateTransaction("GetDispute", id)
+	if err != nil {
+		return result, err
+	}
+	err = json.Unmarshal(payload, &result)
+	return result, err
+}
+
+// GetDistribution returns the progress of the distribution of snapshot
+// snapshotID from sourceAccount
+func (c *Client) GetDistribution(snapshotID string, sourceAccount string) (*Distribution, error) {
+	var result *Distribution
+	payload, err := c.invoker.EvaluateTransaction("GetDistribution", snapshotID, sourceAccount)
+	if err != nil {
+		return result, err
+	}
+	err = json.Unmarshal(payload, &result)
+	return result, err
+}
+
+// GetDormancyFlag returns the dormancy flag of account
+func (c *Client) GetDormancyFlag(account string) (*DormancyFlag, error) {
+	var result *DormancyFlag
+	payload, err := c.invoker.EvaluateTransaction("GetDormancyFlag", account)
+	if err != nil {
+		return result, err
+	}
+	err = json.Unmarshal(payload, &result)
+	return result, err
+}
+
+// GetEmission returns what has been minted of the emission schedule
+func (c *Client) GetEmission() (*EmissionState, error) {
+	var result *EmissionState
+	payload, err := c.invoker.EvaluateTransaction("GetEmission")
+	if err != nil {
+		return result, err
+	}
+	err = json.Unmarshal(payload, &result)
+	return result, err
+}
+
+// GetGiftCard returns the gift card whose code hashes to codeHash
+func (c *Client) GetGiftCard(codeHash string) (*GiftCard, error) {
+	var result *GiftCard
+	payload, err := c.invoker.EvaluateTransaction("GetGiftCard", codeHash)
+	if err != nil {
+		return result, err
+	}
+	err = json.Unmarshal(payload, &result)
+	return result, err
+}
+
+// GetGiftCardBreakage sums the gift cards of issuer, or of every issuer if
+// it is "", that expired unredeemed, over one page of gift cards. Sum the
+// pages for the total.
+func (c *Client) GetGiftCardBreakage(issuer string, pageSize int, bookmark string) (*GiftCardBreakage, error) {
+	var result *GiftCardBreakage
+	payload, err := c.invoker.EvaluateTransaction("GetGiftCardBreakage", issuer, strconv.Itoa(pageSize), bookmark)
+	if err != nil {
+		return result, err
+	}
+	err = json.Unmarshal(payload, &result)
+	return result, err
+}
+
+// GetGuardians returns the guardians of account, none if it named none
+func (c *Client) GetGuardians(account string) (*GuardianSet, error) {
+	var result *GuardianSet
+	payload, err := c.invoker.EvaluateTransaction("GetGuardians", account)
+	if err != nil {
+		return result, err
+	}
+	err = json.Unmarshal(payload, &result)
+	return result, err
+}
+
+// GetItem returns itemID of seller
+func (c *Client) GetItem(sellerID string, itemID string) (*Item, error) {
+	var result *Item
+	payload, err := c.invoker.EvaluateTransaction("GetItem", sellerID, itemID)
+	if err != nil {
+		return result, err
+	}
+	err = json.Unmarshal(payload, &result)
+	return result, err
+}
+
+// GetLargeTransferReview returns the review of transfer txid
+func (c *Client) GetLargeTransferReview(txid string) (*LargeTransferReview, error) {
+	var result *LargeTransferReview
+	payload, err := c.invoker.EvaluateTransaction("GetLargeTransferReview", txid)
+	if err != nil {
+		return result, err
+	}
+	err = json.Unmarshal(payload, &result)
+	return result, err
+}
+
+// GetPaymentRequest returns the payment requested by transaction id
+func (c *Client) GetPaymentRequest(id string) (*PaymentRequest, error) {
+	var result *PaymentRequest
+	payload, err := c.invoker.EvaluateTransaction("GetPaymentRequest", id)
+	if err != nil {
+		return result, err
+	}
+	err = json.Unmarshal(payload, &result)
+	return result, err
+}
+
+// GetPendingConfirmation returns the transfer proposed by transaction id
+func (c *Client) GetPendingConfirmation(id string) (*PendingConfirmation, error) {
+	var result *PendingConfirmation
+	payload, err := c.invoker.EvaluateTransaction("GetPendingConfirmation", id)
+	if err != nil {
+		return result, err
+	}
+	err = json.Unmarshal(payload, &result)
+	return result, err
+}
+
+// GetPendingTransfer returns the transfer requested by transaction id
+func (c *Client) GetPendingTransfer(id string) (*PendingTransfer, error) {
+	var result *PendingTransfer
+	payload, err := c.invoker.EvaluateTransaction("GetPendingTransfer", id)
+	if err != nil {
+		return result, err
+	}
+	err = json.Unmarshal(payload, &result)
+	return result, err
+}
+
+// GetRate returns the published rate of fromCurrency in toCurrency
+func (c *Client) GetRate(fromCurrency string, toCurrency string) (*Rate, error) {
+	var result *Rate
+	payload, err := c.invoker.EvaluateTransaction("GetRate", fromCurrency, toCurrency)
+	if err != nil {
+		return result, err
+	}
+	err = json.Unmarshal(payload, &result)
+	return result, err
+}
+
+// GetRebaseHistory returns a page of the rebases, oldest first
+func (c *Client) GetRebaseHistory(pageSize int, bookmark string) (*RebasePage, error) {
+	var result *RebasePage
+	payload, err := c.invoker.EvaluateTransaction("GetRebaseHistory", strconv.Itoa(pageSize), bookmark)
+	if err != nil {
+		return result, err
+	}
+	err = json.Unmarshal(payload, &result)
+	return result, err
+}
+
+// GetReceipt returns the receipt of the purchase made by transaction id
+func (c *Client) GetReceipt(id string) (*Receipt, error) {
+	var result *Receipt
+	payload, err := c.invoker.EvaluateTransaction("GetReceipt", id)
+	if err != nil {
+		return result, err
+	}
+	err = json.Unmarshal(payload, &result)
+	return result, err
+}
+
+// GetRecovery returns the recovery of account underway, with no approvals
+// if there is none
+func (c *Client) GetRecovery(account string) (*Recovery, error) {
+	var result *Recovery
+	payload, err := c.invoker.EvaluateTransaction("GetRecovery", account)
+	if err != nil {
+		return result, err
+	}
+	err = json.Unmarshal(payload, &result)
+	return result, err
+}
+
+// GetReferral returns the referral of account
+func (c *Client) GetReferral(account string) (*Referral, error) {
+	var result *Referral
+	payload, err := c.invoker.EvaluateTransaction("GetReferral", account)
+	if err != nil {
+		return result, err
+	}
+	err = json.Unmarshal(payload, &result)
+	return result, err
+}
+
+// GetRefundStatus returns how much of the transfer txid has been refunded
+// and how much is left to refund
+func (c *Client) GetRefundStatus(txid string) (*RefundStatus, error) {
+	var result *RefundStatus
+	payload, err := c.invoker.EvaluateTransaction("GetRefundStatus", txid)
+	if err != nil {
+		return result, err
+	}
+	err = json.Unmarshal(payload, &result)
+	return result, err
+}
+
+// GetRefunds returns the refunds of the transfer originalTxID
+func (c *Client) GetRefunds(originalTxID string) (*Refunds, error) {
+	var result *Refunds
+	payload, err := c.invoker.EvaluateTransaction("GetRefunds", originalTxID)
+	if err != nil {
+		return result, err
+	}
+	err = json.Unmarshal(payload, &result)
+	return result, err
+}
+
+// GetReversibleTransfer returns the reversible transfer sent by transaction
+// id while it is in escrow
+func (c *Client) GetReversibleTransfer(id string) (*ReversibleTransfer, error) {
+	var result *ReversibleTransfer
+	payload, err := c.invoker.EvaluateTransaction("GetReversibleTransfer", id)
+	if err != nil {
+		return result, err
+	}
+	err = json.Unmarshal(payload, &result)
+	return result, err
+}
+
+// GetRevokedCertificate returns the revocation of the certificate with
+// fingerprint
+func (c *Client) GetRevokedCertificate(fingerprint string) (*RevokedCertificate, error) {
+	var result *RevokedCertificate
+	payload, err := c.invoker.EvaluateTransaction("GetRevokedCertificate", fingerprint)
+	if err != nil {
+		return result, err
+	}
+	err = json.Unmarshal(payload, &result)
+	return result, err
+}
+
+// GetRollupBalance returns the balance of account and of the accounts
+// under it
+func (c *Client) GetRollupBalance(account string) (*RollupBalance, error) {
+	var result *RollupBalance
+	payload, err := c.invoker.EvaluateTransaction("GetRollupBalance", account)
+	if err != nil {
+		return result, err
+	}
+	err = json.Unmarshal(payload, &result)
+	return result, err
+}
+
+// GetSettlementReport returns the totals of period and a page of its
+// payouts
+func (c *Client) GetSettlementReport(period string, pageSize int, bookmark string) (*SettlementReport, error) {
+	var result *SettlementReport
+	payload, err := c.invoker.EvaluateTransaction("GetSettlementReport", period, strconv.Itoa(pageSize), bookmark)
+	if err != nil {
+		return result, err
+	}
+	err = json.Unmarshal(payload, &result)
+	return result, err
+}
+
+// GetSnapshot returns snapshot id
+func (c *Client) GetSnapshot(id string) (*Snapshot, error) {
+	var result *Snapshot
+	payload, err := c.invoker.EvaluateTransaction("GetSnapshot", id)
+	if err != nil {
+		return result, err
+	}
+	err = json.Unmarshal(payload, &result)
+	return result, err
+}
+
+// GetSuspension returns the suspension of account, which may have expired,
+// with an empty reason if it has none
+func (c *Client) GetSuspension(account string) (*Suspension, error) {
+	var result *Suspension
+	payload, err := c.invoker.EvaluateTransaction("GetSuspension", account)
+	if err != nil {
+		return result, err
+	}
+	err = json.Unmarshal(payload, &result)
+	return result, err
+}
+
+// GetTopHolders returns the n accounts with the largest balances, largest
+// first, n being at most the maximum page size
+func (c *Client) GetTopHolders(n int) ([]*Holder, error) {
+	var result []*Holder
+	payload, err := c.invoker.EvaluateTransaction("GetTopHolders", strconv.Itoa(n))
+	if err != nil {
+		return result, err
+	}
+	err = json.Unmarshal(payload, &result)
+	return result, err
+}
+
+// GetTransaction evaluates the GetTransaction transaction
+func (c *Client) GetTransaction(txid string) (*Transaction, error) {
+	var result *Transaction
+	payload, err := c.invoker.EvaluateTransaction("GetTransaction", txid)
+	if err != nil {
+		return result, err
+	}
+	err = json.Unmarshal(payload, &result)
+	return result, err
+}
+
+// GetTransactionsByCorrelationID returns the transactions recorded with
+// correlation id, in transaction id order
+func (c *Client) GetTransactionsByCorrelationID(id string) ([]*Transaction, error) {
+	var result []*Transaction
+	payload, err := c.invoker.EvaluateTransaction("GetTransactionsByCorrelationID", id)
+	if err != nil {
+		return result, err
+	}
+	err = json.Unmarshal(payload, &result)
+	return result, err
+}
+
+// GetTransferLimit returns the limits on transfers from account, with an
+// empty policy if it has none
+func (c *Client) GetTransferLimit(account string) (*TransferLimit, error) {
+	var result *TransferLimit
+	payload, err := c.invoker.EvaluateTransaction("GetTransferLimit", account)
+	if err != nil {
+		return result, err
+	}
+	err = json.Unmarshal(payload, &result)
+	return result, err
+}
+
+// GetTravelRuleRecord returns the travel rule record of transfer txid. Only
+// peers of the collection's member orgs hold it.
+func (c *Client) GetTravelRuleRecord(txid string) (*TravelRuleRecord, error) {
+	var result *TravelRuleRecord
+	payload, err := c.invoker.EvaluateTransaction("GetTravelRuleRecord", txid)
+	if err != nil {
+		return result, err
+	}
+	err = json.Unmarshal(payload, &result)
+	return result, err
+}
+
+// GetTreasury returns the treasury account
+func (c *Client) GetTreasury(account string) (*Treasury, error) {
+	var result *Treasury
+	payload, err := c.invoker.EvaluateTransaction("GetTreasury", account)
+	if err != nil {
+		return result, err
+	}
+	err = json.Unmarshal(payload, &result)
+	return result, err
+}
+
+// GetTreasuryPayment returns the treasury payment proposed by transaction
+// id
+func (c *Client) GetTreasuryPayment(id string) (*TreasuryPayment, error) {
+	var result *TreasuryPayment
+	payload, err := c.invoker.EvaluateTransaction("GetTreasuryPayment", id)
+	if err != nil {
+		return result, err
+	}
+	err = json.Unmarshal(payload, &result)
+	return result, err
+}
+
+// GetUser evaluates the GetUser transaction
+func (c *Client) GetUser(id string) (*User, error) {
+	var result *User
+	payload, err := c.invoker.EvaluateTransaction("GetUser", id)
+	if err != nil {
+		return result, err
+	}
+	err = json.Unmarshal(payload, &result)
+	return result, err
+}
+
+// GetWallet returns the caller's wallet name with its balance
+func (c *Client) GetWallet(name string) (*WalletBalance, error) {
+	var result *WalletBalance
+	payload, err := c.invoker.EvaluateTransaction("GetWallet", name)
+	if err != nil {
+		return result, err
+	}
+	err = json.Unmarshal(payload, &result)
+	return result, err
+}
+
+// GetWhitelist returns whitelist name
+func (c *Client) GetWhitelist(name string) (*Whitelist, error) {
+	var result *Whitelist
+	payload, err := c.invoker.EvaluateTransaction("GetWhitelist", name)
+	if err != nil {
+		return result, err
+	}
+	err = json.Unmarshal(payload, &result)
+	return result, err
+}
+
+// ImportState writes the records of an ExportState page, given as JSON, to
+// a fresh deployment whose shard count and schema match the source. The
+// page's checksum must match its records, and signature, the base64 ASN.1
+// ECDSA signature of the checksum digest, must verify against the
+// certificate registered with SetImportSigner. Records already stored with
+// the same value are skipped, so an import that stopped part way is
+// resumed by resubmitting its pages; a record stored with a different value
+// fails the page. Only an org admin can call it.
+func (c *Client) ImportState(pageJSON string, signature string) (*ImportResult, error) {
+	var result *ImportResult
+	payload, err := c.invoker.SubmitTransaction("ImportState", pageJSON, signature)
+	if err != nil {
+		return result, err
+	}
+	err = json.Unmarshal(payload, &result)
+	return result, err
+}
+
+// IndexHolders ranks and types the accounts of shard, 0 on an unsharded
+// ledger, visiting at most pageSize records from bookmark, which is empty
+// for the first step. Accounts already indexed are left alone, so it can
+// be rerun.
+// Only an org admin can call it.
+func (c *Client) IndexHolders(shard int, pageSize int, bookmark string) (*MigrationPage, error) {
+	var result *MigrationPage
+	payload, err := c.invoker.SubmitTransaction("IndexHolders", strconv.Itoa(shard), strconv.Itoa(pageSize), bookmark)
+	if err != nil {
+		return result, err
+	}
+	err = json.Unmarshal(payload, &result)
+	return result, err
+}
+
+// Initialize enables the contract. Until an org admin has called it, every
+// mutating method fails with NOT_INITIALIZED. It can only be called once.
+func (c *Client) Initialize() (*Initialization, error) {
+	var result *Initialization
+	payload, err := c.invoker.SubmitTransaction("Initialize")
+	if err != nil {
+		return result, err
+	}
+	err = json.Unmarshal(payload, &result)
+	return result, err
+}
+
+// InitializeSharded enables the contract like Initialize, storing accounts
+// in the given number of hash-bucketed shards so they can be enumerated in
+// parallel with ListAccounts. The shard count cannot be changed later.
+func (c *Client) InitializeSharded(shards int) (*Initialization, error) {
+	var result *Initialization
+	payload, err := c.invoker.SubmitTransaction("InitializeSharded", strconv.Itoa(shards))
+	if err != nil {
+		return result, err
+	}
+	err = json.Unmarshal(payload, &result)
+	return result, err
+}
+
+// InitializeWithEmission enables the contract like Initialize, with an
+// emission of amount to pool every epoch, a Go duration such as "24h",
+// from now. The schedule cannot be changed later.
+func (c *Client) InitializeWithEmission(pool string, amount int, epoch string) (*Initialization, error) {
+	var result *Initialization
+	payload, err := c.invoker.SubmitTransaction("InitializeWithEmission", pool, strconv.Itoa(amount), epoch)
+	if err != nil {
+		return result, err
+	}
+	err = json.Unmarshal(payload, &result)
+	return result, err
+}
+
+// Initialized reports whether Initialize has been called
+func (c *Client) Initialized() (bool, error) {
+	var result bool
+	payload, err := c.invoker.EvaluateTransaction("Initialized")
+	if err != nil {
+		return result, err
+	}
+	err = json.Unmarshal(payload, &result)
+	return result, err
+}
+
+// InspectAccount returns the account stored under id, like GetAccount, and
+// logs the read. Only an auditor can call it.
+func (c *Client) InspectAccount(id string) (*Account, error) {
+	var result *Account
+	payload, err := c.invoker.SubmitTransaction("InspectAccount", id)
+	if err != nil {
+		return result, err
+	}
+	err = json.Unmarshal(payload, &result)
+	return result, err
+}
+
+// InspectAuditTrail returns a page of account's audit trail, like
+// GetAuditTrail, and logs the read. As it writes the log, it reads the
+// trail with an unpaginated query. Only an auditor can call it.
+func (c *Client) InspectAuditTrail(account string, pageSize int, bookmark string) (*AuditPage, error) {
+	var result *AuditPage
+	payload, err := c.invoker.SubmitTransaction("InspectAuditTrail", account, strconv.Itoa(pageSize), bookmark)
+	if err != nil {
+		return result, err
+	}
+	err = json.Unmarshal(payload, &result)
+	return result, err
+}
+
+// InspectTransaction returns the transaction record txid, like
+// GetTransaction, and logs the read. Only an auditor can call it.
+func (c *Client) InspectTransaction(txid string) (*Transaction, error) {
+	var result *Transaction
+	payload, err := c.invoker.SubmitTransaction("InspectTransaction", txid)
+	if err != nil {
+		return result, err
+	}
+	err = json.Unmarshal(payload, &result)
+	return result, err
+}
+
+// IssueGiftCard issues a gift card of value, paid by issuer, redeemable
+// until expiresAt, an RFC 3339 time, with the code whose hex encoded
+// SHA-256 is codeHash. Only the holder of issuer can call it.
+func (c *Client) IssueGiftCard(issuer string, codeHash string, value int, expiresAt string) (*GiftCard, error) {
+	var result *GiftCard
+	payload, err := c.invoker.SubmitTransaction("IssueGiftCard", issuer, codeHash, strconv.Itoa(value), expiresAt)
+	if err != nil {
+		return result, err
+	}
+	err = json.Unmarshal(payload, &result)
+	return result, err
+}
+
+// LiftSuspension unfreezes account. Only an org admin can call it.
+func (c *Client) LiftSuspension(account string) error {
+	_, err := c.invoker.SubmitTransaction("LiftSuspension", account)
+	return err
+}
+
+// ListAccounts returns a page of at most pageSize accounts of shard, in key
+// order, starting at bookmark, which is empty for the first page. The page
+// size and iteration time are bounded by the query policy. The shards
+// are independent, so clients can list them in parallel. On a ledger that
+// is not sharded the only shard is 0 and its pages may hold fewer accounts
+// than pageSize, as the range also holds transaction records, which are
+// skipped.
+func (c *Client) ListAccounts(shard int, pageSize int, bookmark string) (*AccountPage, error) {
+	var result *AccountPage
+	payload, err := c.invoker.EvaluateTransaction("ListAccounts", strconv.Itoa(shard), strconv.Itoa(pageSize), bookmark)
+	if err != nil {
+		return result, err
+	}
+	err = json.Unmarshal(payload, &result)
+	return result, err
+}
+
+// ListAccountsByBalance returns a page of the accounts whose balance as
+// last written is between minBalance and maxBalance, both included,
+// largest first, starting at bookmark, which is empty for the first page
+func (c *Client) ListAccountsByBalance(minBalance int, maxBalance int, pageSize int, bookmark string) (*AccountPage, error) {
+	var result *AccountPage
+	payload, err := c.invoker.EvaluateTransaction("ListAccountsByBalance", strconv.Itoa(minBalance), strconv.Itoa(maxBalance), strconv.Itoa(pageSize), bookmark)
+	if err != nil {
+		return result, err
+	}
+	err = json.Unmarshal(payload, &result)
+	return result, err
+}
+
+// ListAccountsByType returns a page of the accounts of accountType in id
+// order, starting at bookmark, which is empty for the first page
+func (c *Client) ListAccountsByType(accountType string, pageSize int, bookmark string) (*AccountPage, error) {
+	var result *AccountPage
+	payload, err := c.invoker.EvaluateTransaction("ListAccountsByType", accountType, strconv.Itoa(pageSize), bookmark)
+	if err != nil {
+		return result, err
+	}
+	err = json.Unmarshal(payload, &result)
+	return result, err
+}
+
+// ListItems returns a page of the catalog of seller in item id order
+func (c *Client) ListItems(sellerID string, pageSize int, bookmark string) (*ItemPage, error) {
+	var result *ItemPage
+	payload, err := c.invoker.EvaluateTransaction("ListItems", sellerID, strconv.Itoa(pageSize), bookmark)
+	if err != nil {
+		return result, err
+	}
+	err = json.Unmarshal(payload, &result)
+	return result, err
+}
+
+// ListLargeTransferReviews returns a page of at most pageSize reviews, in
+// transaction id order, from bookmark, which is empty for the first page
+func (c *Client) ListLargeTransferReviews(pageSize int, bookmark string) (*ReviewPage, error) {
+	var result *ReviewPage
+	payload, err := c.invoker.EvaluateTransaction("ListLargeTransferReviews", strconv.Itoa(pageSize), bookmark)
+	if err != nil {
+		return result, err
+	}
+	err = json.Unmarshal(payload, &result)
+	return result, err
+}
+
+// ListSuspendedAccounts returns a page of the suspensions in force, in
+// account order, from bookmark, which is empty for the first page. Expired
+// suspensions are skipped, so a page may hold fewer than pageSize.
+func (c *Client) ListSuspendedAccounts(pageSize int, bookmark string) (*SuspensionPage, error) {
+	var result *SuspensionPage
+	payload, err := c.invoker.EvaluateTransaction("ListSuspendedAccounts", strconv.Itoa(pageSize), bookmark)
+	if err != nil {
+		return result, err
+	}
+	err = json.Unmarshal(payload, &result)
+	return result, err
+}
+
+// ListTransactionsByTime returns a page of the transactions proposed at or
+// after fromTime and before toTime, both RFC 3339, oldest first, starting
+// at bookmark, which is empty for the first page
+func (c *Client) ListTransactionsByTime(fromTime string, toTime string, pageSize int, bookmark string) (*TransactionPage, error) {
+	var result *TransactionPage
+	payload, err := c.invoker.EvaluateTransaction("ListTransactionsByTime", fromTime, toTime, strconv.Itoa(pageSize), bookmark)
+	if err != nil {
+		return result, err
+	}
+	err = json.Unmarshal(payload, &result)
+	return result, err
+}
+
+// ListWallets returns a page of the caller's wallets with their balances,
+// by name
+func (c *Client) ListWallets(pageSize int, bookmark string) (*WalletPage, error) {
+	var result *WalletPage
+	payload, err := c.invoker.EvaluateTransaction("ListWallets", strconv.Itoa(pageSize), bookmark)
+	if err != nil {
+		return result, err
+	}
+	err = json.Unmarshal(payload, &result)
+	return result, err
+}
+
+// MigrateRecords rewrites the account and transaction records stored under
+// simple keys in the encoding selected with SetRecordEncoding, visiting at
+// most limit records from bookmark, which is empty for the first step.
+// Records already in that encoding are left alone, so an interrupted
+// migration can be rerun. On a sharded ledger the accounts are migrated
+// with MigrateShard. Only an org admin can call it.
+//
+// A step conflicts with transactions writing the keys it visits and is
+// then resubmitted, so run migrations when the ledger is quiet.
+func (c *Client) MigrateRecords(limit int, bookmark string) (*MigrationPage, error) {
+	var result *MigrationPage
+	payload, err := c.invoker.SubmitTransaction("MigrateRecords", strconv.Itoa(limit), bookmark)
+	if err != nil {
+		return result, err
+	}
+	err = json.Unmarshal(payload, &result)
+	return result, err
+}
+
+// MigrateShard rewrites the account records of one shard of a sharded
+// ledger like MigrateRecords. A shard's range cannot start at a bookmark,
+// so each step iterates the shard from its first account and skips those
+// before bookmark.
+func (c *Client) MigrateShard(shard int, limit int, bookmark string) (*MigrationPage, error) {
+	var result *MigrationPage
+	payload, err := c.invoker.SubmitTransaction("MigrateShard", strconv.Itoa(shard), strconv.Itoa(limit), bookmark)
+	if err != nil {
+		return result, err
+	}
+	err = json.Unmarshal(payload, &result)
+	return result, err
+}
+
+// MigrateState upgrades the records stored under simple keys from schema
+// fromVersion to toVersion, the next version, visiting at most pageSize
+// records from bookmark, which is empty for the first step. Records already
+// in the new layout are left alone, so an interrupted migration can be
+// rerun. The step returning an empty bookmark records toVersion as the
+// ledger's schema. Only an org admin can call it.
+//
+// Like MigrateRecords, run it when the ledger is quiet.
+func (c *Client) MigrateState(fromVersion int, toVersion int, pageSize int, bookmark string) (*MigrationPage, error) {
+	var result *MigrationPage
+	payload, err := c.invoker.SubmitTransaction("MigrateState", strconv.Itoa(fromVersion), strconv.Itoa(toVersion), strconv.Itoa(pageSize), bookmark)
+	if err != nil {
+		return result, err
+	}
+	err = json.Unmarshal(payload, &result)
+	return result, err
+}
+
+// MintCurrency credits account with amount of currency symbol, adding it
+// to the supply. Only an org admin can call it.
+func (c *Client) MintCurrency(symbol string, account string, amount int) (*CurrencyTransaction, error) {
+	var result *CurrencyTransaction
+	payload, err := c.invoker.SubmitTransaction("MintCurrency", symbol, account, strconv.Itoa(amount))
+	if err != nil {
+		return result, err
+	}
+	err = json.Unmarshal(payload, &result)
+	return result, err
+}
+
+// MintEmission mints to the pool the emission of every whole epoch since
+// the last call, and fails if none has ended. Anyone can call it.
+func (c *Client) MintEmission() (*Transaction, error) {
+	var result *Transaction
+	payload, err := c.invoker.SubmitTransaction("MintEmission")
+	if err != nil {
+		return result, err
+	}
+	err = json.Unmarshal(payload, &result)
+	return result, err
+}
+
+// OpenDispute disputes the reversible transfer sent by transaction id,
+// stating reason. Only the holder of the sending account can call it,
+// within the dispute window.
+func (c *Client) OpenDispute(id string, reason string) (*Dispute, error) {
+	var result *Dispute
+	payload, err := c.invoker.SubmitTransaction("OpenDispute", id, reason)
+	if err != nil {
+		return result, err
+	}
+	err = json.Unmarshal(payload, &result)
+	return result, err
+}
+
+// PendingDeltas returns the sum of the credits to account not yet folded
+// into its balance by PruneDeltas
+func (c *Client) PendingDeltas(account string) (int, error) {
+	var result int
+	payload, err := c.invoker.EvaluateTransaction("PendingDeltas", account)
+	if err != nil {
+		return result, err
+	}
+	err = json.Unmarshal(payload, &result)
+	return result, err
+}
+
+// PostUpgrade completes a chaincode upgrade. An org admin submits it once
+// after `peer lifecycle chaincode commit` of a new version: it runs the
+// schema migrations up to CurrentSchema, a page of the default page size
+// each, records ContractVersion and emits a ContractUpgraded event. On a
+// ledger upgraded from before typed events it opens the legacy event
+// window, see events.go. A
+// migration that does not fit in one page is left for MigrateState to
+// finish from the returned bookmark. It fails with ALREADY_INITIALIZED if
+// the ledger is already at this version.
+func (c *Client) PostUpgrade() (*UpgradeResult, error) {
+	var result *UpgradeResult
+	payload, err := c.invoker.SubmitTransaction("PostUpgrade")
+	if err != nil {
+		return result, err
+	}
+	err = json.Unmarshal(payload, &result)
+	return result, err
+}
+
+// ProposeTransfer records a transfer of more than the confirmation
+// threshold for its recipient to confirm. It moves no tokens, and the
+// sender's balance is only checked again on confirmation.
+func (c *Client) ProposeTransfer(from string, to string, value int) (*PendingConfirmation, error) {
+	var result *PendingConfirmation
+	payload, err := c.invoker.SubmitTransaction("ProposeTransfer", from, to, strconv.Itoa(value))
+	if err != nil {
+		return result, err
+	}
+	err = json.Unmarshal(payload, &result)
+	return result, err
+}
+
+// ProposeTreasuryPayment proposes paying value from treasury account to to,
+// executing it if value is within the treasury's threshold. Only a member
+// of the treasury's organization can call it.
+func (c *Client) ProposeTreasuryPayment(account string, to string, value int, memo string) (*TreasuryPayment, error) {
+	var result *TreasuryPayment
+	payload, err := c.invoker.SubmitTransaction("ProposeTreasuryPayment", account, to, strconv.Itoa(value), memo)
+	if err != nil {
+		return result, err
+	}
+	err = json.Unmarshal(payload, &result)
+	return result, err
+}
+
+// PruneDeltas folds the pending credits of a hot account into its balance.
+// It changes no effective balance, so any client may call it; it conflicts
+// with credits endorsed concurrently, so run it periodically rather than
+// after every credit.
+func (c *Client) PruneDeltas(account string) (*User, error) {
+	var result *User
+	payload, err := c.invoker.SubmitTransaction("PruneDeltas", account)
+	if err != nil {
+		return result, err
+	}
+	err = json.Unmarshal(payload, &result)
+	return result, err
+}
+
+// PublishAirdrop opens the airdrop of the entitlements with merkle root,
+// hex encoded, paid from source, replacing the airdrop published before.
+// An empty root closes the airdrop. Only an org admin can call it.
+func (c *Client) PublishAirdrop(root string, source string) (*Initialization, error) {
+	var result *Initialization
+	payload, err := c.invoker.SubmitTransaction("PublishAirdrop", root, source)
+	if err != nil {
+		return result, err
+	}
+	err = json.Unmarshal(payload, &result)
+	return result, err
+}
+
+// PublishWhitelist publishes whitelist name with the accounts of merkle
+// root, hex encoded, replacing any whitelist of that name. An empty root
+// removes the whitelist. Only an org admin can call it.
+func (c *Client) PublishWhitelist(name string, root string) error {
+	_, err := c.invoker.SubmitTransaction("PublishWhitelist", name, root)
+	return err
+}
+
+// Purchase pays price for one of itemID of seller from the caller's
+// account, failing unless price is the item's listed price and it is in
+// stock, and returns the purchase's receipt
+func (c *Client) Purchase(sellerID string, itemID string, price int) (*Receipt, error) {
+	var result *Receipt
+	payload, err := c.invoker.SubmitTransaction("Purchase", sellerID, itemID, strconv.Itoa(price))
+	if err != nil {
+		return result, err
+	}
+	err = json.Unmarshal(payload, &result)
+	return result, err
+}
+
+// Rebase scales every balance by factorNumerator/factorDenominator. Only
+// an org admin can call it.
+func (c *Client) Rebase(factorNumerator int, factorDenominator int) (*RebaseFactor, error) {
+	var result *RebaseFactor
+	payload, err := c.invoker.SubmitTransaction("Rebase", strconv.Itoa(factorNumerator), strconv.Itoa(factorDenominator))
+	if err != nil {
+		return result, err
+	}
+	err = json.Unmarshal(payload, &result)
+	return result, err
+}
+
+// RedeemGiftCard credits the value of the gift card with code to the
+// caller's account
+func (c *Client) RedeemGiftCard(code string) (*GiftCard, error) {
+	var result *GiftCard
+	payload, err := c.invoker.SubmitTransaction("RedeemGiftCard", code)
+	if err != nil {
+		return result, err
+	}
+	err = json.Unmarshal(payload, &result)
+	return result, err
+}
+
+// Refund returns amount of the transfer originalTxID from its recipient to
+// its sender. The refunds of a transfer total at most its value. Only the
+// holder of the recipient account or an arbiter can call it.
+func (c *Client) Refund(originalTxID string, amount int) (*Refunds, error) {
+	var result *Refunds
+	payload, err := c.invoker.SubmitTransaction("Refund", originalTxID, strconv.Itoa(amount))
+	if err != nil {
+		return result, err
+	}
+	err = json.Unmarshal(payload, &result)
+	return result, err
+}
+
+// RegisterAlias registers name as an alias of account. Only the holder of
+// account can call it.
+func (c *Client) RegisterAlias(name string, account string) (*Alias, error) {
+	var result *Alias
+	payload, err := c.invoker.SubmitTransaction("RegisterAlias", name, account)
+	if err != nil {
+		return result, err
+	}
+	err = json.Unmarshal(payload, &result)
+	return result, err
+}
+
+// RegisterCurrency registers currency symbol named name with no supply.
+// Only an org admin can call it.
+func (c *Client) RegisterCurrency(symbol string, name string) (*Currency, error) {
+	var result *Currency
+	payload, err := c.invoker.SubmitTransaction("RegisterCurrency", symbol, name)
+	if err != nil {
+		return result, err
+	}
+	err = json.Unmarshal(payload, &result)
+	return result, err
+}
+
+// RegisterDelegate lets the certificate commonName of mspID transfer up to
+// maxAmount at a time from account until expiresAt, an RFC 3339 time, to
+// the accounts in recipientsJSON, a JSON array of account ids, or to any
+// account if it is empty. It replaces an earlier registration of the same
+// delegate. Only the holder of account can call it.
+func (c *Client) RegisterDelegate(account string, mspID string, commonName string, maxAmount int, expiresAt string, recipientsJSON string) (*Delegate, error) {
+	var result *Delegate
+	payload, err := c.invoker.SubmitTransaction("RegisterDelegate", account, mspID, commonName, strconv.Itoa(maxAmount), expiresAt, recipientsJSON)
+	if err != nil {
+		return result, err
+	}
+	err = json.Unmarshal(payload, &result)
+	return result, err
+}
+
+// ReinstateCertificate lifts the revocation of the certificate with
+// fingerprint. Only an org admin can call it.
+func (c *Client) ReinstateCertificate(fingerprint string) error {
+	_, err := c.invoker.SubmitTransaction("ReinstateCertificate", fingerprint)
+	return err
+}
+
+// RejectTransfer drops the transfer requested by transaction id. Only an
+// org admin can call it.
+func (c *Client) RejectTransfer(id string) error {
+	_, err := c.invoker.SubmitTransaction("RejectTransfer", id)
+	return err
+}
+
+// RejectTreasuryPayment drops the pending treasury payment id. Only an
+// admin of the treasury's organization can call it.
+func (c *Client) RejectTreasuryPayment(id string) (*TreasuryPayment, error) {
+	var result *TreasuryPayment
+	payload, err := c.invoker.SubmitTransaction("RejectTreasuryPayment", id)
+	if err != nil {
+		return result, err
+	}
+	err = json.Unmarshal(payload, &result)
+	return result, err
+}
+
+// RemoveItem removes itemID from the catalog of seller. The receipts of
+// its purchases remain. Only the holder of the seller account can call it.
+func (c *Client) RemoveItem(sellerID string, itemID string) error {
+	_, err := c.invoker.SubmitTransaction("RemoveItem", sellerID, itemID)
+	return err
+}
+
+// RequestPayment bills from for value, to be paid to the caller's account.
+// reference is free text for the payer, such as an invoice number. It
+// moves no tokens.
+func (c *Client) RequestPayment(from string, value int, reference string) (*PaymentRequest, error) {
+	var result *PaymentRequest
+	payload, err := c.invoker.SubmitTransaction("RequestPayment", from, strconv.Itoa(value), reference)
+	if err != nil {
+		return result, err
+	}
+	err = json.Unmarshal(payload, &result)
+	return result, err
+}
+
+// RequestTransfer records a transfer from an account under
+// LimitPolicyApprove for an org admin to approve. It moves no tokens.
+func (c *Client) RequestTransfer(from string, to string, value int) (*PendingTransfer, error) {
+	var result *PendingTransfer
+	payload, err := c.invoker.SubmitTransaction("RequestTransfer", from, to, strconv.Itoa(value))
+	if err != nil {
+		return result, err
+	}
+	err = json.Unmarshal(payload, &result)
+	return result, err
+}
+
+// ResolveDispute rules on the dispute of transfer id with one of the
+// rulings. refund is the share returned to the sender under RulingSplit,
+// between 0 and the value exclusive, and must be 0 under the others. Only
+// an arbiter can call it. It returns the transfer record of the tokens it
+// moved.
+func (c *Client) ResolveDispute(id string, ruling string, refund int) (*Transaction, error) {
+	var result *Transaction
+	payload, err := c.invoker.SubmitTransaction("ResolveDispute", id, ruling, strconv.Itoa(refund))
+	if err != nil {
+		return result, err
+	}
+	err = json.Unmarshal(payload, &result)
+	return result, err
+}
+
+// RespondDispute records the recipient's response to the dispute of
+// transfer id. Only the holder of the receiving account can call it, while
+// the dispute is open.
+func (c *Client) RespondDispute(id string, response string) (*Dispute, error) {
+	var result *Dispute
+	payload, err := c.invoker.SubmitTransaction("RespondDispute", id, response)
+	if err != nil {
+		return result, err
+	}
+	err = json.Unmarshal(payload, &result)
+	return result, err
+}
+
+// ReverseTransfer returns the tokens of the reversible transfer sent by
+// transaction id to its sender. Only the holder of the sending account or
+// an arbiter can call it, within the dispute window.
+func (c *Client) ReverseTransfer(id string) (*Transaction, error) {
+	var result *Transaction
+	payload, err := c.invoker.SubmitTransaction("ReverseTransfer", id)
+	if err != nil {
+		return result, err
+	}
+	err = json.Unmarshal(payload, &result)
+	return result, err
+}
+
+// RevokeCertificate rejects every later call signed by the certificate
+// with fingerprint, for reason. Only an org admin can call it.
+func (c *Client) RevokeCertificate(fingerprint string, reason string) (*RevokedCertificate, error) {
+	var result *RevokedCertificate
+	payload, err := c.invoker.SubmitTransaction("RevokeCertificate", fingerprint, reason)
+	if err != nil {
+		return result, err
+	}
+	err = json.Unmarshal(payload, &result)
+	return result, err
+}
+
+// RevokeDelegate revokes the delegation of account to the certificate
+// commonName of mspID. Only the holder of account can call it.
+func (c *Client) RevokeDelegate(account string, mspID string, commonName string) error {
+	_, err := c.invoker.SubmitTransaction("RevokeDelegate", account, mspID, commonName)
+	return err
+}
+
+// SetAppealNote records note as the appeal of account's suspension. The
+// account holder or an org admin can call it.
+func (c *Client) SetAppealNote(account string, note string) (*Suspension, error) {
+	var result *Suspension
+	payload, err := c.invoker.SubmitTransaction("SetAppealNote", account, note)
+	if err != nil {
+		return result, err
+	}
+	err = json.Unmarshal(payload, &result)
+	return result, err
+}
+
+// SetBalance submits the SetBalance transaction
+func (c *Client) SetBalance(id string, balance int) (*User, error) {
+	var result *User
+	payload, err := c.invoker.SubmitTransaction("SetBalance", id, strconv.Itoa(balance))
+	if err != nil {
+		return result, err
+	}
+	err = json.Unmarshal(payload, &result)
+	return result, err
+}
+
+// SetCashback pays buyers rate basis points of their transfers to seller
+// accounts from pool; a 0 rate ends cashback. Only an org admin can call
+// it.
+func (c *Client) SetCashback(pool string, rate int) (*Initialization, error) {
+	var result *Initialization
+	payload, err := c.invoker.SubmitTransaction("SetCashback", pool, strconv.Itoa(rate))
+	if err != nil {
+		return result, err
+	}
+	err = json.Unmarshal(payload, &result)
+	return result, err
+}
+
+// SetConfirmationPolicy makes transfers of more than threshold wait for the
+// recipient's confirmation for at most window, a Go duration such as "72h".
+// A zero threshold turns confirmation off. Only an org admin can call it.
+func (c *Client) SetConfirmationPolicy(threshold int, window string) (*Initialization, error) {
+	var result *Initialization
+	payload, err := c.invoker.SubmitTransaction("SetConfirmationPolicy", strconv.Itoa(threshold), window)
+	if err != nil {
+		return result, err
+	}
+	err = json.Unmarshal(payload, &result)
+	return result, err
+}
+
+// SetConversionSpread makes conversions keep spread basis points of what
+// they would mint. Only an org admin can call it.
+func (c *Client) SetConversionSpread(spread int) (*Initialization, error) {
+	var result *Initialization
+	payload, err := c.invoker.SubmitTransaction("SetConversionSpread", strconv.Itoa(spread))
+	if err != nil {
+		return result, err
+	}
+	err = json.Unmarshal(payload, &result)
+	return result, err
+}
+
+// SetCreditLine lets account go negative by up to limit; 0 removes its
+// credit line. It cannot be lowered below the credit drawn. Only an org
+// admin can call it.
+func (c *Client) SetCreditLine(account string, limit int) (*CreditLine, error) {
+	var result *CreditLine
+	payload, err := c.invoker.SubmitTransaction("SetCreditLine", account, strconv.Itoa(limit))
+	if err != nil {
+		return result, err
+	}
+	err = json.Unmarshal(payload, &result)
+	return result, err
+}
+
+// SetDemurrage charges a holding fee of rate basis points a year on every
+// balance from now; 0 disables it. Changing the rate applies it to the
+// fees due since each balance last changed. Only an org admin can call it.
+func (c *Client) SetDemurrage(rate int) (*Initialization, error) {
+	var result *Initialization
+	payload, err := c.invoker.SubmitTransaction("SetDemurrage", strconv.Itoa(rate))
+	if err != nil {
+		return result, err
+	}
+	err = json.Unmarshal(payload, &result)
+	return result, err
+}
+
+// SetDisputeWindow sets how long, a Go duration such as "72h", reversible
+// transfers can be reversed; "" disables TransferReversible. Transfers
+// already in escrow keep their window. Only an org admin can call it.
+func (c *Client) SetDisputeWindow(window string) (*Initialization, error) {
+	var result *Initialization
+	payload, err := c.invoker.SubmitTransaction("SetDisputeWindow", window)
+	if err != nil {
+		return result, err
+	}
+	err = json.Unmarshal(payload, &result)
+	return result, err
+}
+
+// SetEscheatmentPolicy lets accounts inactive for dormancy be swept to
+// account grace after they are flagged, both Go durations such as "8760h".
+// An empty dormancy disables escheatment. Only an org admin can call it.
+func (c *Client) SetEscheatmentPolicy(dormancy string, grace string, account string) (*Initialization, error) {
+	var result *Initialization
+	payload, err := c.invoker.SubmitTransaction("SetEscheatmentPolicy", dormancy, grace, account)
+	if err != nil {
+		return result, err
+	}
+	err = json.Unmarshal(payload, &result)
+	return result, err
+}
+
+// SetEventVerbosity sets the detail of the events of recorded transactions
+// to minimal, standard or verbose. Only an org admin can call it.
+func (c *Client) SetEventVerbosity(verbosity string) (*Initialization, error) {
+	var result *Initialization
+	payload, err := c.invoker.SubmitTransaction("SetEventVerbosity", verbosity)
+	if err != nil {
+		return result, err
+	}
+	err = json.Unmarshal(payload, &result)
+	return result, err
+}
+
+// SetGuardians names the guardians of account, a JSON array of identities,
+// threshold of whom must approve a recovery, which then waits delay, a Go
+// duration such as "72h". It replaces the guardians of a recovery underway,
+// and drops the recovery. Only the account holder can call it.
+func (c *Client) SetGuardians(account string, guardiansJSON string, threshold int, delay string) (*GuardianSet, error) {
+	var result *GuardianSet
+	payload, err := c.invoker.SubmitTransaction("SetGuardians", account, guardiansJSON, strconv.Itoa(threshold), delay)
+	if err != nil {
+		return result, err
+	}
+	err = json.Unmarshal(payload, &result)
+	return result, err
+}
+
+// SetHotAccount marks or unmarks an account as hot. Unmarking prunes its
+// pending deltas first. Only an org admin can call it.
+func (c *Client) SetHotAccount(id string, hot bool) (*User, error) {
+	var result *User
+	payload, err := c.invoker.SubmitTransaction("SetHotAccount", id, strconv.FormatBool(hot))
+	if err != nil {
+		return result, err
+	}
+	err = json.Unmarshal(payload, &result)
+	return result, err
+}
+
+// SetImportSigner registers the PEM encoded certificate whose ECDSA key
+// signs the pages ImportState accepts, that of the admin exporting the
+// source ledger. Only an org admin can call it.
+func (c *Client) SetImportSigner(certificatePEM string) error {
+	_, err := c.invoker.SubmitTransaction("SetImportSigner", certificatePEM)
+	return err
+}
+
+// SetInterest makes the accounts of accountType earn rate basis points a
+// year from now; 0 stops them earning. Changing the rate applies it to the
+// interest due since each account last accrued. Only an org admin can call
+// it.
+func (c *Client) SetInterest(accountType string, rate int) (*Initialization, error) {
+	var result *Initialization
+	payload, err := c.invoker.SubmitTransaction("SetInterest", accountType, strconv.Itoa(rate))
+	if err != nil {
+		return result, err
+	}
+	err = json.Unmarshal(payload, &result)
+	return result, err
+}
+
+// SetLargeTransferThreshold flags every later transfer of more than
+// threshold for review; 0 stops flagging. Only an org admin can call it.
+func (c *Client) SetLargeTransferThreshold(threshold int) (*Initialization, error) {
+	var result *Initialization
+	payload, err := c.invoker.SubmitTransaction("SetLargeTransferThreshold", strconv.Itoa(threshold))
+	if err != nil {
+		return result, err
+	}
+	err = json.Unmarshal(payload, &result)
+	return result, err
+}
+
+// SetLegacyEventWindow moves the end of the window in which the legacy
+// Transfer event is still emitted to until, an RFC 3339 time; empty ends
+// it now. Only an org admin can call it.
+func (c *Client) SetLegacyEventWindow(until string) (*Initialization, error) {
+	var result *Initialization
+	payload, err := c.invoker.SubmitTransaction("SetLegacyEventWindow", until)
+	if err != nil {
+		return result, err
+	}
+	err = json.Unmarshal(payload, &result)
+	return result, err
+}
+
+// SetParentAccount places account under parent, restricting its transfers
+// to the tree if restricted; an empty parent detaches it. Only an org admin
+// can call it.
+func (c *Client) SetParentAccount(account string, parent string, restricted bool) (*AccountLink, error) {
+	var result *AccountLink
+	payload, err := c.invoker.SubmitTransaction("SetParentAccount", account, parent, strconv.FormatBool(restricted))
+	if err != nil {
+		return result, err
+	}
+	err = json.Unmarshal(payload, &result)
+	return result, err
+}
+
+// SetRate publishes rate, scaled by RateScale, as the rate of
+// fromCurrency in toCurrency. Only an oracle can call it.
+func (c *Client) SetRate(fromCurrency string, toCurrency string, rate int) (*Rate, error) {
+	var result *Rate
+	payload, err := c.invoker.SubmitTransaction("SetRate", fromCurrency, toCurrency, strconv.Itoa(rate))
+	if err != nil {
+		return result, err
+	}
+	err = json.Unmarshal(payload, &result)
+	return result, err
+}
+
+// SetRecordEncoding selects the encoding account and transaction records
+// are written with from now on, json or protobuf. Records already stored
+// stay readable; MigrateRecords and MigrateShard convert them. Only an org
+// admin can call it.
+func (c *Client) SetRecordEncoding(encoding string) (*Initialization, error) {
+	var result *Initialization
+	payload, err := c.invoker.SubmitTransaction("SetRecordEncoding", encoding)
+	if err != nil {
+		return result, err
+	}
+	err = json.Unmarshal(payload, &result)
+	return result, err
+}
+
+// SetReferralProgram pays bonus from pool to the referrer of each account
+// on its first transfer of at least minTransfer, up to cap in total and
+// maxPerReferrer referrals per referrer, 0 for no limit. A 0 bonus ends the
+// program. Only an org admin can call it.
+func (c *Client) SetReferralProgram(pool string, bonus int, minTransfer int, cap int, maxPerReferrer int) (*Initialization, error) {
+	var result *Initialization
+	payload, err := c.invoker.SubmitTransaction("SetReferralProgram", pool, strconv.Itoa(bonus), strconv.Itoa(minTransfer), strconv.Itoa(cap), strconv.Itoa(maxPerReferrer))
+	if err != nil {
+		return result, err
+	}
+	err = json.Unmarshal(payload, &result)
+	return result, err
+}
+
+// SetReferrer names account as the referrer of the caller's account. It
+// can be set once, before the caller's qualifying transfer; an account
+// cannot refer itself, the account it was referred by, or be referred by
+// the program's pool.
+func (c *Client) SetReferrer(account string) (*Referral, error) {
+	var result *Referral
+	payload, err := c.invoker.SubmitTransaction("SetReferrer", account)
+	if err != nil {
+		return result, err
+	}
+	err = json.Unmarshal(payload, &result)
+	return result, err
+}
+
+// SetSettlementAccount names account as where the payouts of seller go.
+// Only the holder of seller or an org admin can call it.
+func (c *Client) SetSettlementAccount(seller string, account string) (*SettlementAccount, error) {
+	var result *SettlementAccount
+	payload, err := c.invoker.SubmitTransaction("SetSettlementAccount", seller, account)
+	if err != nil {
+		return result, err
+	}
+	err = json.Unmarshal(payload, &result)
+	return result, err
+}
+
+// SetTransferLimit sets the limits on transfers from account. Zero for
+// both limits removes them. Only an org admin can call it.
+func (c *Client) SetTransferLimit(account string, maxTransfer int, dailyLimit int, policy string) (*TransferLimit, error) {
+	var result *TransferLimit
+	payload, err := c.invoker.SubmitTransaction("SetTransferLimit", account, strconv.Itoa(maxTransfer), strconv.Itoa(dailyLimit), policy)
+	if err != nil {
+		return result, err
+	}
+	err = json.Unmarshal(payload, &result)
+	return result, err
+}
+
+// SetTravelRuleThreshold requires travel rule information on every later
+// transfer of more than threshold; 0 requires none. Only an org admin can
+// call it.
+func (c *Client) SetTravelRuleThreshold(threshold int) (*Initialization, error) {
+	var result *Initialization
+	payload, err := c.invoker.SubmitTransaction("SetTravelRuleThreshold", strconv.Itoa(threshold))
+	if err != nil {
+		return result, err
+	}
+	err = json.Unmarshal(payload, &result)
+	return result, err
+}
+
+// SetTreasury makes account the treasury of the caller's organization,
+// paying up to threshold without approval. Only an org admin can call it,
+// and only an admin of the same organization can change it later.
+func (c *Client) SetTreasury(account string, threshold int) (*Treasury, error) {
+	var result *Treasury
+	payload, err := c.invoker.SubmitTransaction("SetTreasury", account, strconv.Itoa(threshold))
+	if err != nil {
+		return result, err
+	}
+	err = json.Unmarshal(payload, &result)
+	return result, err
+}
+
+// SettleSellers pays out the balances of the sellers in sellersJSON, a
+// JSON array of seller accounts, to their settlement accounts for period.
+// Only a settlement operator can call it.
+func (c *Client) SettleSellers(period string, sellersJSON string) (*SettlementBatch, error) {
+	var result *SettlementBatch
+	payload, err := c.invoker.SubmitTransaction("SettleSellers", period, sellersJSON)
+	if err != nil {
+		return result, err
+	}
+	err = json.Unmarshal(payload, &result)
+	return result, err
+}
+
+// ShardCount returns the number of shards ListAccounts splits the accounts
+// into, 1 for a ledger that is not sharded
+func (c *Client) ShardCount() (int, error) {
+	var result int
+	payload, err := c.invoker.EvaluateTransaction("ShardCount")
+	if err != nil {
+		return result, err
+	}
+	err = json.Unmarshal(payload, &result)
+	return result, err
+}
+
+// StreamState returns the records of namespace, one of Sections, from
+// cursor, which is empty for the first chunk, up to maxBytes of JSON
+// encoded records, or DefaultStreamBytes if it is 0. The chunk's Bookmark
+// is the cursor of the next one, empty after the last. A record larger
+// than maxBytes fails the call. Only an org admin can call it.
+func (c *Client) StreamState(namespace string, cursor string, maxBytes int) (*StatePage, error) {
+	var result *StatePage
+	payload, err := c.invoker.EvaluateTransaction("StreamState", namespace, cursor, strconv.Itoa(maxBytes))
+	if err != nil {
+		return result, err
+	}
+	err = json.Unmarshal(payload, &result)
+	return result, err
+}
+
+// SuspendAccount freezes account for reason, one of the suspension reason
+// codes, detailed by note, until expiresAt, an RFC 3339 time, or until
+// lifted if it is empty. Suspending a suspended account replaces its
+// suspension and clears its appeal note. Only an org admin can call it.
+func (c *Client) SuspendAccount(account string, reason string, note string, expiresAt string) (*Suspension, error) {
+	var result *Suspension
+	payload, err := c.invoker.SubmitTransaction("SuspendAccount", account, reason, note, expiresAt)
+	if err != nil {
+		return result, err
+	}
+	err = json.Unmarshal(payload, &result)
+	return result, err
+}
+
+// TakeSnapshot records the current balances of the accounts in
+// accountsJSON, a JSON array of account ids, pending credits and fees due
+// included, as snapshot id. Only an org admin can call it.
+func (c *Client) TakeSnapshot(id string, accountsJSON string) (*Snapshot, error) {
+	var result *Snapshot
+	payload, err := c.invoker.SubmitTransaction("TakeSnapshot", id, accountsJSON)
+	if err != nil {
+		return result, err
+	}
+	err = json.Unmarshal(payload, &result)
+	return result, err
+}
+
+// TotalSupply sums the balances of a page of the accounts of shard, as
+// ListAccounts lists them, including the credits pending on hot accounts.
+// Summing every page of every shard gives the ledger's total supply, which
+// a record migration must leave unchanged: compare it before and after.
+func (c *Client) TotalSupply(shard int, pageSize int, bookmark string) (*SupplyPage, error) {
+	var result *SupplyPage
+	payload, err := c.invoker.EvaluateTransaction("TotalSupply", strconv.Itoa(shard), strconv.Itoa(pageSize), bookmark)
+	if err != nil {
+		return result, err
+	}
+	err = json.Unmarshal(payload, &result)
+	return result, err
+}
+
+// TransferAlias makes alias name an alias of account instead. Only the
+// holder of the account it names can call it.
+func (c *Client) TransferAlias(name string, account string) (*Alias, error) {
+	var result *Alias
+	payload, err := c.invoker.SubmitTransaction("TransferAlias", name, account)
+	if err != nil {
+		return result, err
+	}
+	err = json.Unmarshal(payload, &result)
+	return result, err
+}
+
+// TransferBetweenWallets transfers value from the caller's wallet
+// fromWallet to its wallet toWallet. The limits of fromWallet apply.
+func (c *Client) TransferBetweenWallets(fromWallet string, toWallet string, value int) (*Transaction, error) {
+	var result *Transaction
+	payload, err := c.invoker.SubmitTransaction("TransferBetweenWallets", fromWallet, toWallet, strconv.Itoa(value))
+	if err != nil {
+		return result, err
+	}
+	err = json.Unmarshal(payload, &result)
+	return result, err
+}
+
+// TransferCurrency transfers value of currency symbol from the "from"
+// account to the "to" account
+func (c *Client) TransferCurrency(symbol string, from string, to string, value int) (*CurrencyTransaction, error) {
+	var result *CurrencyTransaction
+	payload, err := c.invoker.SubmitTransaction("TransferCurrency", symbol, from, to, strconv.Itoa(value))
+	if err != nil {
+		return result, err
+	}
+	err = json.Unmarshal(payload, &result)
+	return result, err
+}
+
+// TransferFrom transfers the value amount from the "from" address to the "to" address
+// This function triggers a Transferred event, see events.go
+func (c *Client) TransferFrom(from string, to string, value int) (*Transaction, error) {
+	var result *Transaction
+	payload, err := c.invoker.SubmitTransaction("TransferFrom", from, to, strconv.Itoa(value))
+	if err != nil {
+		return result, err
+	}
+	err = json.Unmarshal(payload, &result)
+	return result, err
+}
+
+// TransferReversible moves value from from into escrow for to, who can
+// claim it once the dispute window has passed
+func (c *Client) TransferReversible(from string, to string, value int) (*ReversibleTransfer, error) {
+	var result *ReversibleTransfer
+	payload, err := c.invoker.SubmitTransaction("TransferReversible", from, to, strconv.Itoa(value))
+	if err != nil {
+		return result, err
+	}
+	err = json.Unmarshal(payload, &result)
+	return result, err
+}
+
+// UpdateItem sets the name, price and stock of itemID of seller. Only the
+// holder of the seller account can call it.
+func (c *Client) UpdateItem(sellerID string, itemID string, name string, price int, stock int) (*Item, error) {
+	var result *Item
+	payload, err := c.invoker.SubmitTransaction("UpdateItem", sellerID, itemID, name, strconv.Itoa(price), strconv.Itoa(stock))
+	if err != nil {
+		return result, err
+	}
+	err = json.Unmarshal(payload, &result)
+	return result, err
+}
+
+// UserExist evaluates the UserExist transaction
+func (c *Client) UserExist(id string) (bool, error) {
+	var result bool
+	payload, err := c.invoker.EvaluateTransaction("UserExist", id)
+	if err != nil {
+		return result, err
+	}
+	err = json.Unmarshal(payload, &result)
+	return result, err
+}
+
+// VerifyReceipt reports whether details, the JSON encoding of a receipt,
+// are the details of the receipt of the purchase made by transaction id.
+// A hash in details is ignored.
+func (c *Client) VerifyReceipt(id string, details string) (bool, error) {
+	var result bool
+	payload, err := c.invoker.EvaluateTransaction("VerifyReceipt", id, details)
+	if err != nil {
+		return result, err
+	}
+	err = json.Unmarshal(payload, &result)
+	return result, err
+}
+
+// VerifyWhitelist reports whether proof, a JSON array of the hex encoded
+// sibling hashes from account's leaf to the root, shows account is on
+// whitelist name. It is the gate check of whitelisted flows.
+func (c *Client) VerifyWhitelist(name string, account string, proof string) (bool, error) {
+	var result bool
+	payload, err := c.invoker.EvaluateTransaction("VerifyWhitelist", name, account, proof)
+	if err != nil {
+		return result, err
+	}
+	err = json.Unmarshal(payload, &result)
+	return result, err
+}
+
+// Version returns the deployed contract and ledger versions
+func (c *Client) Version() (*VersionInfo, error) {
+	var result *VersionInfo
+	payload, err := c.invoker.EvaluateTransaction("Version")
+	if err != nil {
+		return result, err
+	}
+	err = json.Unmarshal(payload, &result)
+	return result, err
+}
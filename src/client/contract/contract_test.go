@@ -0,0 +1,63 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package contract
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type call struct {
+	submit bool
+	name   string
+	args   []string
+}
+
+type fakeInvoker struct {
+	calls  []call
+	result []byte
+	err    error
+}
+
+func (f *fakeInvoker) SubmitTransaction(name string, args ...string) ([]byte, error) {
+	f.calls = append(f.calls, call{true, name, args})
+	return f.result, f.err
+}
+
+func (f *fakeInvoker) EvaluateTransaction(name string, args ...string) ([]byte, error) {
+	f.calls = append(f.calls, call{false, name, args})
+	return f.result, f.err
+}
+
+func TestClient(t *testing.T) {
+	invoker := &fakeInvoker{result: []byte(`{"txId":"tx1","type":"transfer","from":"alice","to":"bob","value":30}`)}
+	client := New(invoker)
+
+	transaction, err := client.TransferFrom("alice", "bob", 30)
+	require.NoError(t, err)
+	assert.Equal(t, &Transaction{TxID: "tx1", Type: "transfer", From: "alice", To: "bob", Value: 30}, transaction)
+	assert.Equal(t, call{true, "TransferFrom", []string{"alice", "bob", "30"}}, invoker.calls[0], "should submit with the arguments as strings")
+
+	invoker.result = []byte(`[{"account":"alice","balance":100}]`)
+	holders, err := client.GetTopHolders(1)
+	require.NoError(t, err)
+	require.Len(t, holders, 1)
+	assert.Equal(t, "alice", holders[0].Account)
+	assert.Equal(t, call{false, "GetTopHolders", []string{"1"}}, invoker.calls[1], "should evaluate evaluate transactions")
+
+	invoker.result = []byte(`{"userId":"alice","type":"user","balance":100,"hot":true}`)
+	_, err = client.SetHotAccount("alice", true)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"alice", "true"}, invoker.calls[2].args)
+
+	invoker.err = errors.New("[ACCOUNT_NOT_FOUND] user alice does not exist")
+	err = client.DeleteUser("alice")
+	assert.EqualError(t, err, "[ACCOUNT_NOT_FOUND] user alice does not exist")
+	_, err = client.GetUser("alice")
+	assert.EqualError(t, err, "[ACCOUNT_NOT_FOUND] user alice does not exist")
+}
@@ -0,0 +1,100 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package explorer
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/kkiu1756/my_fabric/src/client/blocks"
+	_ "github.com/mattn/go-sqlite3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newStore(t *testing.T) *SQLStore {
+	db, err := sql.Open("sqlite3", ":memory:")
+	require.NoError(t, err)
+	db.SetMaxOpenConns(1)
+	t.Cleanup(func() { db.Close() })
+
+	store := NewSQLStore(db, false)
+	require.NoError(t, store.CreateTables(context.Background()))
+
+	return store
+}
+
+func transferBlock(number uint64, txID string, from string, to string) *blocks.Block {
+	return &blocks.Block{
+		Number:   number,
+		DataHash: []byte{0xab},
+		Invalid:  1,
+		Transactions: []blocks.Transaction{{
+			TxID:        txID,
+			BlockNumber: number,
+			Events:      []blocks.Event{{Name: "Transfer", Payload: []byte(`{"from":"` + from + `","to":"` + to + `","value":10}`)}},
+			Reads:       []blocks.Read{{Key: from, BlockNumber: number - 1}},
+			Writes: []blocks.Write{
+				{Key: from, Value: []byte(`{"userId":"` + from + `","type":"user","balance":90}`)},
+				{Key: to, Value: []byte(`{"userId":"` + to + `","type":"seller","balance":10}`)},
+				{Key: txID, Value: []byte(`{"txId":"` + txID + `","from":"` + from + `","to":"` + to + `","value":10}`)},
+			},
+		}},
+	}
+}
+
+func get(t *testing.T, handler http.Handler, path string, v interface{}) int {
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, path, nil))
+	if v != nil && rr.Code == http.StatusOK {
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), v))
+	}
+	return rr.Code
+}
+
+func TestIndexerAndHandler(t *testing.T) {
+	store := newStore(t)
+	indexer := NewIndexer(store)
+	ctx := context.Background()
+
+	require.NoError(t, indexer.HandleBlock(ctx, transferBlock(5, "tx5", "alice", "bob")))
+	require.NoError(t, indexer.HandleBlock(ctx, transferBlock(6, "tx6", "alice", "carol")))
+	require.NoError(t, indexer.HandleBlock(ctx, transferBlock(6, "tx6", "alice", "carol")), "should tolerate redelivered blocks")
+
+	handler := Handler(store)
+
+	var latest []BlockSummary
+	require.Equal(t, http.StatusOK, get(t, handler, "/explorer/blocks?limit=1", &latest))
+	require.Len(t, latest, 1)
+	assert.Equal(t, uint64(6), latest[0].Number, "should list newest blocks first")
+	assert.Equal(t, "ab", latest[0].DataHash)
+	assert.Equal(t, 1, latest[0].InvalidCount)
+
+	var block BlockSummary
+	require.Equal(t, http.StatusOK, get(t, handler, "/explorer/blocks/5", &block))
+	assert.Equal(t, []string{"tx5"}, block.TxIDs)
+	assert.Equal(t, http.StatusNotFound, get(t, handler, "/explorer/blocks/9", nil))
+	assert.Equal(t, http.StatusBadRequest, get(t, handler, "/explorer/blocks/x", nil))
+
+	var tx blocks.Transaction
+	require.Equal(t, http.StatusOK, get(t, handler, "/explorer/transactions/tx5", &tx))
+	assert.Equal(t, []blocks.Read{{Key: "alice", BlockNumber: 4}}, tx.Reads, "should include the read set")
+	assert.Len(t, tx.Writes, 3, "should include the write set")
+	assert.Equal(t, http.StatusNotFound, get(t, handler, "/explorer/transactions/missing", nil))
+
+	var account Account
+	require.Equal(t, http.StatusOK, get(t, handler, "/explorer/accounts/alice", &account))
+	assert.JSONEq(t, `{"userId":"alice","type":"user","balance":90}`, string(account.State))
+	assert.Equal(t, uint64(6), account.UpdatedBlock)
+	require.Len(t, account.Transactions, 2, "should list every transaction touching the account once")
+	assert.Equal(t, "tx6", account.Transactions[0].TxID)
+
+	assert.Equal(t, http.StatusNotFound, get(t, handler, "/explorer/accounts/tx5", nil), "transaction records are not accounts")
+	assert.Equal(t, http.StatusBadRequest, get(t, handler, "/explorer/blocks?limit=0", nil))
+}
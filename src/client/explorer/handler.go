@@ -0,0 +1,110 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package explorer
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+const (
+	defaultLimit = 20
+	maxLimit     = 100
+)
+
+// Handler serves the explorer API from store:
+//
+//	GET /explorer/blocks?limit=n        latest blocks, newest first
+//	GET /explorer/blocks/{number}       block summary with transaction IDs
+//	GET /explorer/transactions/{txId}   transaction with events and read/write sets
+//	GET /explorer/accounts/{id}?limit=n account state and recent transactions
+func Handler(store *SQLStore) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+			return
+		}
+
+		parts := strings.SplitN(strings.Trim(strings.TrimPrefix(r.URL.Path, "/explorer"), "/"), "/", 2)
+		limit, err := parseLimit(r)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		var result interface{}
+		switch {
+		case parts[0] == "blocks" && len(parts) == 1:
+			result, err = store.LatestBlocks(r.Context(), limit)
+
+		case parts[0] == "blocks":
+			number, parseErr := strconv.ParseUint(parts[1], 10, 64)
+			if parseErr != nil {
+				writeError(w, http.StatusBadRequest, "invalid block number")
+				return
+			}
+			block, lookupErr := store.Block(r.Context(), number)
+			result, err = block, lookupErr
+			if block == nil && err == nil {
+				writeError(w, http.StatusNotFound, "block not indexed")
+				return
+			}
+
+		case parts[0] == "transactions" && len(parts) == 2:
+			tx, lookupErr := store.Transaction(r.Context(), parts[1])
+			result, err = tx, lookupErr
+			if tx == nil && err == nil {
+				writeError(w, http.StatusNotFound, "transaction not indexed")
+				return
+			}
+
+		case parts[0] == "accounts" && len(parts) == 2:
+			account, lookupErr := store.Account(r.Context(), parts[1], limit)
+			result, err = account, lookupErr
+			if account == nil && err == nil {
+				writeError(w, http.StatusNotFound, "account not indexed")
+				return
+			}
+
+		default:
+			writeError(w, http.StatusNotFound, "not found")
+			return
+		}
+
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(result)
+	})
+}
+
+func parseLimit(r *http.Request) (int, error) {
+	value := r.URL.Query().Get("limit")
+	if value == "" {
+		return defaultLimit, nil
+	}
+
+	limit, err := strconv.Atoi(value)
+	if err != nil || limit < 1 {
+		return 0, fmt.Errorf("limit must be a positive integer")
+	}
+	if limit > maxLimit {
+		limit = maxLimit
+	}
+
+	return limit, nil
+}
+
+func writeError(w http.ResponseWriter, status int, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(map[string]string{"error": message})
+}
@@ -0,0 +1,73 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package explorer
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/kkiu1756/my_fabric/src/client/blocks"
+)
+
+// Indexer is a blocks.Sink that writes every block into a SQLStore
+type Indexer struct {
+	store *SQLStore
+}
+
+// NewIndexer creates an indexer writing to store
+func NewIndexer(store *SQLStore) *Indexer {
+	return &Indexer{store: store}
+}
+
+// HandleBlock indexes the block, linking each transaction to the accounts
+// named in its events and the account records it wrote
+func (i *Indexer) HandleBlock(ctx context.Context, block *blocks.Block) error {
+	accounts := map[string][]string{}
+	states := map[string][]byte{}
+
+	for _, tx := range block.Transactions {
+		seen := map[string]bool{}
+		add := func(account string) {
+			if account != "" && !seen[account] {
+				seen[account] = true
+				accounts[tx.TxID] = append(accounts[tx.TxID], account)
+			}
+		}
+
+		for _, event := range tx.Events {
+			var transfer struct {
+				From string `json:"from"`
+				To   string `json:"to"`
+			}
+			if json.Unmarshal(event.Payload, &transfer) == nil {
+				add(transfer.From)
+				add(transfer.To)
+			}
+		}
+
+		for _, write := range tx.Writes {
+			if write.IsDelete {
+				states[write.Key] = nil
+				continue
+			}
+			if isAccountRecord(write.Value) {
+				add(write.Key)
+				states[write.Key] = write.Value
+			}
+		}
+	}
+
+	return i.store.PutBlock(ctx, block, accounts, states)
+}
+
+// isAccountRecord recognises the contract's User records by their userId
+// field, which Transaction and other records do not have
+func isAccountRecord(value []byte) bool {
+	var record struct {
+		ID *string `json:"userId"`
+	}
+
+	return json.Unmarshal(value, &record) == nil && record.ID != nil
+}
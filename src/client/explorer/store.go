@@ -0,0 +1,262 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+*/
+
+// Package explorer indexes token blocks into a SQL database and serves
+// explorer-style HTTP endpoints (latest blocks, transaction detail with
+// read/write sets, account pages) from that index
+package explorer
+
+import (
+	"context"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+
+	"github.com/kkiu1756/my_fabric/src/client/blocks"
+	"github.com/kkiu1756/my_fabric/src/client/internal/sqlutil"
+)
+
+// BlockSummary is a row of the latest-blocks listing
+type BlockSummary struct {
+	Number       uint64   `json:"number"`
+	PreviousHash string   `json:"previousHash"`
+	DataHash     string   `json:"dataHash"`
+	TxCount      int      `json:"txCount"`
+	InvalidCount int      `json:"invalidCount"`
+	TxIDs        []string `json:"txIds,omitempty"`
+}
+
+// Account is an account page: the latest stored state and recent activity
+type Account struct {
+	ID           string               `json:"id"`
+	State        json.RawMessage      `json:"state"`
+	UpdatedBlock uint64               `json:"updatedBlock"`
+	Transactions []blocks.Transaction `json:"transactions"`
+}
+
+// SQLStore keeps the explorer index in a database/sql database
+type SQLStore struct {
+	db       *sql.DB
+	numbered bool
+}
+
+// NewSQLStore returns a store using db. Set numberedPlaceholders for drivers
+// that expect $1 style parameters (e.g. PostgreSQL).
+func NewSQLStore(db *sql.DB, numberedPlaceholders bool) *SQLStore {
+	return &SQLStore{db: db, numbered: numberedPlaceholders}
+}
+
+// CreateTables creates the index tables if they do not exist yet
+func (s *SQLStore) CreateTables(ctx context.Context) error {
+	statements := []string{
+		`CREATE TABLE IF NOT EXISTS explorer_blocks (
+			number BIGINT PRIMARY KEY,
+			previous_hash VARCHAR(64) NOT NULL,
+			data_hash VARCHAR(64) NOT NULL,
+			tx_count INTEGER NOT NULL,
+			invalid_count INTEGER NOT NULL
+		)`,
+		`CREATE TABLE IF NOT EXISTS explorer_transactions (
+			tx_id VARCHAR(64) PRIMARY KEY,
+			block_number BIGINT NOT NULL,
+			detail TEXT NOT NULL
+		)`,
+		`CREATE TABLE IF NOT EXISTS explorer_account_transactions (
+			account VARCHAR(255) NOT NULL,
+			tx_id VARCHAR(64) NOT NULL,
+			block_number BIGINT NOT NULL,
+			PRIMARY KEY (account, tx_id)
+		)`,
+		`CREATE TABLE IF NOT EXISTS explorer_accounts (
+			account VARCHAR(255) PRIMARY KEY,
+			state TEXT NOT NULL,
+			block_number BIGINT NOT NULL
+		)`,
+	}
+
+	for _, statement := range statements {
+		if _, err := s.db.ExecContext(ctx, statement); err != nil {
+			return fmt.Errorf("failed to create explorer tables: %v", err)
+		}
+	}
+
+	return nil
+}
+
+// PutBlock indexes a block. Re-indexing the same block replaces its rows, so
+// redelivery after a listener restart is harmless.
+func (s *SQLStore) PutBlock(ctx context.Context, block *blocks.Block, accounts map[string][]string, states map[string][]byte) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	number := int64(block.Number)
+	for _, q := range []string{
+		"DELETE FROM explorer_blocks WHERE number = ?",
+		"DELETE FROM explorer_transactions WHERE block_number = ?",
+		"DELETE FROM explorer_account_transactions WHERE block_number = ?",
+	} {
+		if _, err := tx.ExecContext(ctx, s.query(q), number); err != nil {
+			return fmt.Errorf("failed to clear block %d: %v", block.Number, err)
+		}
+	}
+
+	_, err = tx.ExecContext(ctx, s.query("INSERT INTO explorer_blocks (number, previous_hash, data_hash, tx_count, invalid_count) VALUES (?, ?, ?, ?, ?)"),
+		number, hex.EncodeToString(block.PreviousHash), hex.EncodeToString(block.DataHash), len(block.Transactions), block.Invalid)
+	if err != nil {
+		return fmt.Errorf("failed to index block %d: %v", block.Number, err)
+	}
+
+	for _, t := range block.Transactions {
+		detail, err := json.Marshal(t)
+		if err != nil {
+			return err
+		}
+		if _, err := tx.ExecContext(ctx, s.query("INSERT INTO explorer_transactions (tx_id, block_number, detail) VALUES (?, ?, ?)"), t.TxID, number, string(detail)); err != nil {
+			return fmt.Errorf("failed to index transaction %s: %v", t.TxID, err)
+		}
+
+		for _, account := range accounts[t.TxID] {
+			if _, err := tx.ExecContext(ctx, s.query("INSERT INTO explorer_account_transactions (account, tx_id, block_number) VALUES (?, ?, ?)"), account, t.TxID, number); err != nil {
+				return fmt.Errorf("failed to index account %s: %v", account, err)
+			}
+		}
+	}
+
+	for account, state := range states {
+		if _, err := tx.ExecContext(ctx, s.query("DELETE FROM explorer_accounts WHERE account = ?"), account); err != nil {
+			return err
+		}
+		if state == nil {
+			continue
+		}
+		if _, err := tx.ExecContext(ctx, s.query("INSERT INTO explorer_accounts (account, state, block_number) VALUES (?, ?, ?)"), account, string(state), number); err != nil {
+			return fmt.Errorf("failed to index account %s: %v", account, err)
+		}
+	}
+
+	return tx.Commit()
+}
+
+// LatestBlocks returns up to limit blocks, newest first
+func (s *SQLStore) LatestBlocks(ctx context.Context, limit int) ([]BlockSummary, error) {
+	rows, err := s.db.QueryContext(ctx, s.query("SELECT number, previous_hash, data_hash, tx_count, invalid_count FROM explorer_blocks ORDER BY number DESC LIMIT ?"), limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	summaries := []BlockSummary{}
+	for rows.Next() {
+		var b BlockSummary
+		if err := rows.Scan(&b.Number, &b.PreviousHash, &b.DataHash, &b.TxCount, &b.InvalidCount); err != nil {
+			return nil, err
+		}
+		summaries = append(summaries, b)
+	}
+
+	return summaries, rows.Err()
+}
+
+// Block returns a block summary including its token transaction IDs, or nil
+// if the block is not indexed
+func (s *SQLStore) Block(ctx context.Context, number uint64) (*BlockSummary, error) {
+	var b BlockSummary
+	err := s.db.QueryRowContext(ctx, s.query("SELECT number, previous_hash, data_hash, tx_count, invalid_count FROM explorer_blocks WHERE number = ?"), int64(number)).
+		Scan(&b.Number, &b.PreviousHash, &b.DataHash, &b.TxCount, &b.InvalidCount)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := s.db.QueryContext(ctx, s.query("SELECT tx_id FROM explorer_transactions WHERE block_number = ? ORDER BY tx_id"), int64(number))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	b.TxIDs = []string{}
+	for rows.Next() {
+		var txID string
+		if err := rows.Scan(&txID); err != nil {
+			return nil, err
+		}
+		b.TxIDs = append(b.TxIDs, txID)
+	}
+
+	return &b, rows.Err()
+}
+
+// Transaction returns the indexed transaction, or nil if it is unknown
+func (s *SQLStore) Transaction(ctx context.Context, txID string) (*blocks.Transaction, error) {
+	var detail string
+	err := s.db.QueryRowContext(ctx, s.query("SELECT detail FROM explorer_transactions WHERE tx_id = ?"), txID).Scan(&detail)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var t blocks.Transaction
+	if err := json.Unmarshal([]byte(detail), &t); err != nil {
+		return nil, fmt.Errorf("corrupt index entry for %s: %v", txID, err)
+	}
+
+	return &t, nil
+}
+
+// Account returns the account page with up to limit recent transactions, or
+// nil if the account never appeared in an indexed block
+func (s *SQLStore) Account(ctx context.Context, id string, limit int) (*Account, error) {
+	account := &Account{ID: id, Transactions: []blocks.Transaction{}}
+
+	var state string
+	err := s.db.QueryRowContext(ctx, s.query("SELECT state, block_number FROM explorer_accounts WHERE account = ?"), id).Scan(&state, &account.UpdatedBlock)
+	switch {
+	case err == sql.ErrNoRows:
+	case err != nil:
+		return nil, err
+	default:
+		account.State = json.RawMessage(state)
+	}
+
+	rows, err := s.db.QueryContext(ctx, s.query(`SELECT t.detail FROM explorer_account_transactions a
+		JOIN explorer_transactions t ON t.tx_id = a.tx_id
+		WHERE a.account = ? ORDER BY a.block_number DESC LIMIT ?`), id, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var detail string
+		if err := rows.Scan(&detail); err != nil {
+			return nil, err
+		}
+		var t blocks.Transaction
+		if err := json.Unmarshal([]byte(detail), &t); err != nil {
+			return nil, err
+		}
+		account.Transactions = append(account.Transactions, t)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if account.State == nil && len(account.Transactions) == 0 {
+		return nil, nil
+	}
+
+	return account, nil
+}
+
+func (s *SQLStore) query(q string) string {
+	return sqlutil.Rebind(s.numbered, q)
+}
@@ -0,0 +1,158 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+*/
+
+// Package hsm connects gateway clients whose private keys are held in an HSM.
+// Signing goes through the SDK's PKCS#11 crypto suite, which finds the key on
+// the token by the subject key identifier of the identity's certificate, so
+// the key never has to be written to disk.
+//
+// PKCS#11 needs cgo and is only compiled in with the pkcs11 build tag:
+//
+//	go build -tags pkcs11 ./...
+package hsm
+
+import (
+	"fmt"
+
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/core"
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/msp"
+	"github.com/hyperledger/fabric-sdk-go/pkg/fabsdk"
+	"github.com/hyperledger/fabric-sdk-go/pkg/fabsdk/factory/defcore"
+	"github.com/hyperledger/fabric-sdk-go/pkg/fabsdk/factory/defmsp"
+	"github.com/hyperledger/fabric-sdk-go/pkg/gateway"
+	mspimpl "github.com/hyperledger/fabric-sdk-go/pkg/msp"
+	"github.com/kkiu1756/my_fabric/src/client/wallet"
+)
+
+// Config identifies the PKCS#11 token an identity's key is held in
+type Config struct {
+	// Library is the path of the vendor's PKCS#11 module, e.g.
+	// /usr/lib/softhsm/libsofthsm2.so
+	Library string
+	Label   string
+	Pin     string
+	// SecurityLevel and HashFamily select the signature hash, 256 and SHA2
+	// by default
+	SecurityLevel int
+	HashFamily    string
+	// SoftVerify verifies signatures in software instead of on the token
+	SoftVerify bool
+}
+
+func (c Config) validate() error {
+	if c.Library == "" {
+		return fmt.Errorf("PKCS#11 library path is required")
+	}
+	if c.Label == "" {
+		return fmt.Errorf("PKCS#11 token label is required")
+	}
+
+	return nil
+}
+
+// Gateway is a gateway connection that signs through an HSM
+type Gateway struct {
+	*gateway.Gateway
+	sdk *fabsdk.FabricSDK
+}
+
+// Close releases the connection and its PKCS#11 session
+func (g *Gateway) Close() {
+	g.Gateway.Close()
+	g.sdk.Close()
+}
+
+// Connect opens a gateway as label, an HSM identity in store, signing with
+// the token described by cfg
+func Connect(config core.ConfigProvider, store wallet.Store, label string, cfg Config, options ...gateway.Option) (*Gateway, error) {
+	if err := cfg.validate(); err != nil {
+		return nil, err
+	}
+
+	id, err := store.Get(label)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get identity %s: %v", label, err)
+	}
+	if !id.HSM {
+		return nil, fmt.Errorf("identity %s is not an HSM identity", label)
+	}
+
+	suite, err := newCryptoSuite(cryptoConfig{cfg})
+	if err != nil {
+		return nil, fmt.Errorf("failed to open PKCS#11 token %s: %v", cfg.Label, err)
+	}
+
+	user := &msp.UserData{ID: label, MSPID: id.MSPID, EnrollmentCertificate: []byte(id.Certificate)}
+	sdk, err := fabsdk.New(config, fabsdk.WithCorePkg(&corePkg{suite: suite}), fabsdk.WithMSPPkg(&mspPkg{user: user}))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create SDK: %v", err)
+	}
+
+	gw, err := gateway.Connect(gateway.WithSDK(sdk), gateway.WithUser(label), options...)
+	if err != nil {
+		sdk.Close()
+		return nil, fmt.Errorf("failed to connect as %s: %v", label, err)
+	}
+
+	return &Gateway{Gateway: gw, sdk: sdk}, nil
+}
+
+// corePkg replaces the configured crypto suite with the PKCS#11 one
+type corePkg struct {
+	defcore.ProviderFactory
+	suite core.CryptoSuite
+}
+
+func (f *corePkg) CreateCryptoSuiteProvider(config core.CryptoSuiteConfig) (core.CryptoSuite, error) {
+	return f.suite, nil
+}
+
+// mspPkg serves the identity's certificate from memory instead of the
+// connection profile's credential store
+type mspPkg struct {
+	defmsp.ProviderFactory
+	user *msp.UserData
+}
+
+func (f *mspPkg) CreateUserStore(config msp.IdentityConfig) (msp.UserStore, error) {
+	store := mspimpl.NewMemoryUserStore()
+	if err := store.Store(f.user); err != nil {
+		return nil, err
+	}
+
+	return store, nil
+}
+
+// cryptoConfig adapts Config to the SDK crypto suite configuration
+type cryptoConfig struct {
+	Config
+}
+
+func (c cryptoConfig) IsSecurityEnabled() bool { return true }
+
+func (c cryptoConfig) SecurityAlgorithm() string {
+	if c.HashFamily == "" {
+		return "SHA2"
+	}
+	return c.HashFamily
+}
+
+func (c cryptoConfig) SecurityLevel() int {
+	if c.Config.SecurityLevel == 0 {
+		return 256
+	}
+	return c.Config.SecurityLevel
+}
+
+func (c cryptoConfig) SecurityProvider() string { return "pkcs11" }
+
+func (c cryptoConfig) SoftVerify() bool { return c.Config.SoftVerify }
+
+func (c cryptoConfig) SecurityProviderLibPath() string { return c.Library }
+
+func (c cryptoConfig) SecurityProviderPin() string { return c.Pin }
+
+func (c cryptoConfig) SecurityProviderLabel() string { return c.Label }
+
+func (c cryptoConfig) KeyStorePath() string { return "" }
@@ -0,0 +1,49 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package hsm
+
+import (
+	"testing"
+
+	"github.com/hyperledger/fabric-sdk-go/pkg/core/config"
+	"github.com/kkiu1756/my_fabric/src/client/wallet"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCryptoConfig(t *testing.T) {
+	c := cryptoConfig{Config{Library: "/usr/lib/softhsm/libsofthsm2.so", Label: "fabric", Pin: "98765432"}}
+	assert.True(t, c.IsSecurityEnabled())
+	assert.Equal(t, "pkcs11", c.SecurityProvider())
+	assert.Equal(t, "SHA2", c.SecurityAlgorithm())
+	assert.Equal(t, 256, c.SecurityLevel())
+	assert.Equal(t, "/usr/lib/softhsm/libsofthsm2.so", c.SecurityProviderLibPath())
+	assert.Equal(t, "fabric", c.SecurityProviderLabel())
+	assert.Equal(t, "98765432", c.SecurityProviderPin())
+	assert.False(t, c.SoftVerify())
+
+	c = cryptoConfig{Config{SecurityLevel: 384, HashFamily: "SHA3", SoftVerify: true}}
+	assert.Equal(t, "SHA3", c.SecurityAlgorithm())
+	assert.Equal(t, 384, c.SecurityLevel())
+	assert.True(t, c.SoftVerify())
+}
+
+func TestConnectValidation(t *testing.T) {
+	store := wallet.NewMemoryStore()
+	profile := config.FromFile("connection-org1.yaml")
+	cfg := Config{Library: "/usr/lib/softhsm/libsofthsm2.so", Label: "fabric"}
+
+	_, err := Connect(profile, store, "user1", Config{Label: "fabric"})
+	assert.EqualError(t, err, "PKCS#11 library path is required")
+
+	_, err = Connect(profile, store, "user1", Config{Library: cfg.Library})
+	assert.EqualError(t, err, "PKCS#11 token label is required")
+
+	_, err = Connect(profile, store, "user1", cfg)
+	assert.EqualError(t, err, "failed to get identity user1: identity not found")
+
+	assert.NoError(t, store.Put("user1", &wallet.Identity{MSPID: "Org1MSP", Certificate: "CERT", Key: "KEY"}))
+	_, err = Connect(profile, store, "user1", cfg)
+	assert.EqualError(t, err, "identity user1 is not an HSM identity")
+}
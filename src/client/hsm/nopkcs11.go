@@ -0,0 +1,18 @@
+//go:build !pkcs11
+// +build !pkcs11
+
+/*
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package hsm
+
+import (
+	"fmt"
+
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/core"
+)
+
+func newCryptoSuite(config core.CryptoSuiteConfig) (core.CryptoSuite, error) {
+	return nil, fmt.Errorf("PKCS#11 support not compiled in, rebuild with -tags pkcs11")
+}
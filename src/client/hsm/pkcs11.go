@@ -0,0 +1,17 @@
+//go:build pkcs11
+// +build pkcs11
+
+/*
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package hsm
+
+import (
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/core"
+	"github.com/hyperledger/fabric-sdk-go/pkg/core/cryptosuite/bccsp/pkcs11"
+)
+
+func newCryptoSuite(config core.CryptoSuiteConfig) (core.CryptoSuite, error) {
+	return pkcs11.GetSuiteByConfig(config)
+}
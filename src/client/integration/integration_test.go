@@ -0,0 +1,259 @@
+//go:build integration
+// +build integration
+
+/*
+SPDX-License-Identifier: Apache-2.0
+*/
+
+// Package integration runs the token chaincode on a real two organisation
+// test network. It brings the network up, deploys the chaincode with an
+// endorsement policy requiring both organisations and tears everything down
+// afterwards:
+//
+//	go test -tags=integration ./integration/
+//
+// Set TEST_NETWORK_DIR to use another test-network checkout and
+// KEEP_NETWORK=1 to leave the network running for inspection.
+package integration
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/fab"
+	"github.com/hyperledger/fabric-sdk-go/pkg/core/config"
+	"github.com/hyperledger/fabric-sdk-go/pkg/gateway"
+	"github.com/kkiu1756/my_fabric/src/client/testnet"
+	"github.com/kkiu1756/my_fabric/src/client/wallet"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var network *testnet.Network
+
+// #########
+// HELPERS
+// #########
+
+type user struct {
+	ID      string `json:"userId"`
+	Type    string `json:"type"`
+	Balance int    `json:"balance"`
+}
+
+type transaction struct {
+	TXID  string `json:"txId"`
+	From  string `json:"from"`
+	To    string `json:"to"`
+	Value int    `json:"value"`
+}
+
+func TestMain(m *testing.M) {
+	dir := os.Getenv("TEST_NETWORK_DIR")
+	if dir == "" {
+		dir = filepath.Join("..", "..", "..", "test-network")
+	}
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		log.Fatalf("Failed to resolve test network directory: %v", err)
+	}
+
+	os.Setenv("DISCOVERY_AS_LOCALHOST", "true")
+	network = testnet.New(dir)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Minute)
+	err = network.Up(ctx)
+	if err == nil {
+		err = network.Deploy(ctx, testnet.Chaincode{EndorsementPolicy: testnet.AllOrgs})
+	}
+	cancel()
+	if err == nil {
+		err = initialize()
+	}
+
+	code := 1
+	if err != nil {
+		log.Printf("Failed to start test network: %v", err)
+	} else {
+		code = m.Run()
+	}
+
+	if os.Getenv("KEEP_NETWORK") == "" {
+		if err := network.Down(context.Background()); err != nil {
+			log.Printf("Failed to tear down test network: %v", err)
+		}
+	}
+	os.Exit(code)
+}
+
+// connect returns the contract as seen by id, a member of org
+func connect(id *wallet.Identity, org int) (*gateway.Contract, func(), error) {
+	store := wallet.NewMemoryStore()
+	label := "org" + strconv.Itoa(org)
+	if err := store.Put(label, id); err != nil {
+		return nil, nil, err
+	}
+	identity, err := wallet.IdentityOption(store, label)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	gw, err := gateway.Connect(gateway.WithConfig(config.FromFile(network.ConnectionProfile(org))), identity)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	nw, err := gw.GetNetwork(network.Channel)
+	if err != nil {
+		gw.Close()
+		return nil, nil, err
+	}
+
+	return nw.GetContract("basic"), gw.Close, nil
+}
+
+// contract connects as User1 of org
+func contract(t *testing.T, org int) *gateway.Contract {
+	id, err := network.Identity(org)
+	require.NoError(t, err)
+
+	c, closeGateway, err := connect(id, org)
+	require.NoError(t, err)
+	t.Cleanup(closeGateway)
+
+	return c
+}
+
+// initialize calls Initialize as the Org1 admin. A kept network is
+// already initialized.
+func initialize() error {
+	admin, err := network.AdminIdentity(1)
+	if err != nil {
+		return err
+	}
+
+	c, closeGateway, err := connect(admin, 1)
+	if err != nil {
+		return err
+	}
+	defer closeGateway()
+
+	_, err = c.SubmitTransaction("Initialize")
+	if err != nil && !strings.Contains(err.Error(), "[ALREADY_INITIALIZED]") {
+		return fmt.Errorf("failed to initialize contract: %w", err)
+	}
+
+	return nil
+}
+
+// uniqueID keeps accounts of separate runs against a kept network apart
+func uniqueID(name string) string {
+	return fmt.Sprintf("%s-%d", name, time.Now().UnixNano())
+}
+
+func getUser(t *testing.T, c *gateway.Contract, id string) user {
+	result, err := c.EvaluateTransaction("GetUser", id)
+	require.NoError(t, err)
+
+	var u user
+	require.NoError(t, json.Unmarshal(result, &u))
+	return u
+}
+
+func waitForEvent(t *testing.T, events <-chan *fab.CCEvent, txID string) *fab.CCEvent {
+	timeout := time.After(time.Minute)
+	for {
+		select {
+		case e := <-events:
+			if e.TxID == txID {
+				return e
+			}
+		case <-timeout:
+			t.Fatalf("no event for transaction %s", txID)
+		}
+	}
+}
+
+// #########
+// TESTS
+// #########
+
+func TestTransferAcrossOrganisations(t *testing.T) {
+	org1 := contract(t, 1)
+	org2 := contract(t, 2)
+	alice, bob := uniqueID("alice"), uniqueID("bob")
+
+	_, err := org1.SubmitTransaction("CreateUser", alice, "user", "100")
+	require.NoError(t, err)
+	_, err = org2.SubmitTransaction("CreateUser", bob, "user", "20")
+	require.NoError(t, err)
+
+	registration, events, err := org1.RegisterEvent("Transfer")
+	require.NoError(t, err)
+	defer org1.Unregister(registration)
+
+	result, err := org2.SubmitTransaction("TransferFrom", alice, bob, "30")
+	require.NoError(t, err)
+
+	var tx transaction
+	require.NoError(t, json.Unmarshal(result, &tx))
+	assert.Equal(t, transaction{TXID: tx.TXID, From: alice, To: bob, Value: 30}, tx)
+
+	event := waitForEvent(t, events, tx.TXID)
+	assert.Equal(t, "Transfer", event.EventName)
+	assert.JSONEq(t, fmt.Sprintf(`{"from":%q,"to":%q,"value":30}`, alice, bob), string(event.Payload))
+
+	assert.Equal(t, 70, getUser(t, org1, alice).Balance)
+	assert.Equal(t, 50, getUser(t, org1, bob).Balance)
+	assert.Equal(t, 70, getUser(t, org2, alice).Balance, "both organisations should see the same state")
+
+	recorded, err := org1.EvaluateTransaction("GetTransaction", tx.TXID)
+	require.NoError(t, err)
+	assert.JSONEq(t, string(result), string(recorded))
+}
+
+func TestRejectedTransferLeavesState(t *testing.T) {
+	org1 := contract(t, 1)
+	alice, bob := uniqueID("alice"), uniqueID("bob")
+
+	_, err := org1.SubmitTransaction("CreateUser", alice, "user", "10")
+	require.NoError(t, err)
+	_, err = org1.SubmitTransaction("CreateUser", bob, "user", "0")
+	require.NoError(t, err)
+
+	_, err = org1.SubmitTransaction("TransferFrom", alice, bob, "11")
+	assert.Error(t, err)
+	_, err = org1.SubmitTransaction("TransferFrom", alice, alice, "1")
+	assert.Error(t, err)
+
+	assert.Equal(t, 10, getUser(t, org1, alice).Balance)
+	assert.Equal(t, 0, getUser(t, org1, bob).Balance)
+}
+
+func TestAdministration(t *testing.T) {
+	org1 := contract(t, 1)
+	org2 := contract(t, 2)
+	carol := uniqueID("carol")
+
+	_, err := org1.SubmitTransaction("CreateUser", carol, "merchant", "5")
+	require.NoError(t, err)
+	_, err = org2.SubmitTransaction("CreateUser", carol, "merchant", "5")
+	assert.Error(t, err, "should not create an account twice")
+
+	_, err = org2.SubmitTransaction("SetBalance", carol, "500")
+	require.NoError(t, err)
+	assert.Equal(t, user{ID: carol, Type: "merchant", Balance: 500}, getUser(t, org1, carol))
+
+	_, err = org1.SubmitTransaction("DeleteUser", carol)
+	require.NoError(t, err)
+	_, err = org2.EvaluateTransaction("GetUser", carol)
+	assert.Error(t, err)
+}
@@ -0,0 +1,32 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+*/
+
+// Package sqlutil holds helpers shared by the database-backed stores
+package sqlutil
+
+import (
+	"strconv"
+	"strings"
+)
+
+// Rebind rewrites ? placeholders in q to $1, $2, ... when numbered is set,
+// for drivers such as PostgreSQL that do not accept ?
+func Rebind(numbered bool, q string) string {
+	if !numbered {
+		return q
+	}
+
+	var b strings.Builder
+	n := 0
+	for _, r := range q {
+		if r == '?' {
+			n++
+			b.WriteString("$" + strconv.Itoa(n))
+			continue
+		}
+		b.WriteRune(r)
+	}
+
+	return b.String()
+}
@@ -0,0 +1,16 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package sqlutil
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRebind(t *testing.T) {
+	assert.Equal(t, "UPDATE t SET a = $1 WHERE b = $2", Rebind(true, "UPDATE t SET a = ? WHERE b = ?"))
+	assert.Equal(t, "UPDATE t SET a = ? WHERE b = ?", Rebind(false, "UPDATE t SET a = ? WHERE b = ?"))
+}
@@ -0,0 +1,209 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+*/
+
+// Package loadgen drives a weighted mix of token transactions at a target
+// rate and reports latency percentiles, error and MVCC conflict rates
+package loadgen
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Invoker submits or evaluates transactions as one client identity. The
+// fabric-sdk-go gateway Contract satisfies it.
+type Invoker interface {
+	SubmitTransaction(name string, args ...string) ([]byte, error)
+	EvaluateTransaction(name string, args ...string) ([]byte, error)
+}
+
+// Operation is one kind of request in the mix
+type Operation struct {
+	Name   string
+	Weight int
+	Submit bool
+	// Args returns the transaction name and arguments for the next request
+	Args func(r *rand.Rand) (string, []string)
+}
+
+// Config describes a load run
+type Config struct {
+	Rate        float64
+	Duration    time.Duration
+	Concurrency int
+	Operations  []Operation
+	Seed        int64
+}
+
+// Stats summarises the requests of one operation
+type Stats struct {
+	Name          string
+	Requests      int
+	Errors        int
+	MVCCConflicts int
+	P50           time.Duration
+	P90           time.Duration
+	P99           time.Duration
+	Max           time.Duration
+}
+
+// Report is the outcome of a load run
+type Report struct {
+	Elapsed time.Duration
+	// Skipped counts requests not sent because Concurrency requests were
+	// already in flight, i.e. the network could not keep up with Rate
+	Skipped    int
+	Operations []Stats
+}
+
+type result struct {
+	op      int
+	latency time.Duration
+	err     error
+}
+
+// Run sends requests until cfg.Duration elapses or ctx is cancelled,
+// spreading them round-robin over invokers
+func Run(ctx context.Context, cfg Config, invokers []Invoker) (*Report, error) {
+	if cfg.Rate <= 0 {
+		return nil, fmt.Errorf("rate must be positive")
+	}
+	if len(invokers) == 0 {
+		return nil, fmt.Errorf("at least one invoker is required")
+	}
+	totalWeight := 0
+	for _, op := range cfg.Operations {
+		if op.Weight < 0 {
+			return nil, fmt.Errorf("operation %s has a negative weight", op.Name)
+		}
+		totalWeight += op.Weight
+	}
+	if totalWeight == 0 {
+		return nil, fmt.Errorf("operation weights must add up to more than zero")
+	}
+	if cfg.Concurrency < 1 {
+		cfg.Concurrency = 1
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, cfg.Duration)
+	defer cancel()
+
+	r := rand.New(rand.NewSource(cfg.Seed))
+	results := make(chan result, cfg.Concurrency)
+	inFlight := make(chan struct{}, cfg.Concurrency)
+	var wg sync.WaitGroup
+
+	latencies := make([][]time.Duration, len(cfg.Operations))
+	report := &Report{Operations: make([]Stats, len(cfg.Operations))}
+	for i, op := range cfg.Operations {
+		report.Operations[i].Name = op.Name
+	}
+
+	collected := make(chan struct{})
+	go func() {
+		defer close(collected)
+		for res := range results {
+			stats := &report.Operations[res.op]
+			stats.Requests++
+			latencies[res.op] = append(latencies[res.op], res.latency)
+			if res.err != nil {
+				stats.Errors++
+				if IsMVCCConflict(res.err) {
+					stats.MVCCConflicts++
+				}
+			}
+		}
+	}()
+
+	start := time.Now()
+	ticker := time.NewTicker(time.Duration(float64(time.Second) / cfg.Rate))
+	defer ticker.Stop()
+
+	for sent := 0; ; sent++ {
+		select {
+		case <-ctx.Done():
+			wg.Wait()
+			close(results)
+			<-collected
+			report.Elapsed = time.Since(start)
+			for i := range report.Operations {
+				fillPercentiles(&report.Operations[i], latencies[i])
+			}
+			return report, nil
+		case <-ticker.C:
+		}
+
+		select {
+		case inFlight <- struct{}{}:
+		default:
+			report.Skipped++
+			continue
+		}
+
+		index := pick(r, cfg.Operations, totalWeight)
+		op := cfg.Operations[index]
+		name, args := op.Args(r)
+		invoker := invokers[sent%len(invokers)]
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() { <-inFlight }()
+
+			begin := time.Now()
+			var err error
+			if op.Submit {
+				_, err = invoker.SubmitTransaction(name, args...)
+			} else {
+				_, err = invoker.EvaluateTransaction(name, args...)
+			}
+			results <- result{op: index, latency: time.Since(begin), err: err}
+		}()
+	}
+}
+
+// IsMVCCConflict reports whether err is a commit failure caused by a
+// conflicting concurrent write to a key the transaction read
+func IsMVCCConflict(err error) bool {
+	return err != nil && strings.Contains(err.Error(), "MVCC_READ_CONFLICT")
+}
+
+func pick(r *rand.Rand, ops []Operation, totalWeight int) int {
+	n := r.Intn(totalWeight)
+	for i, op := range ops {
+		if n < op.Weight {
+			return i
+		}
+		n -= op.Weight
+	}
+
+	return len(ops) - 1
+}
+
+func fillPercentiles(stats *Stats, latencies []time.Duration) {
+	if len(latencies) == 0 {
+		return
+	}
+
+	sort.Slice(latencies, func(i, j int) bool { return latencies[i] < latencies[j] })
+	stats.P50 = percentile(latencies, 50)
+	stats.P90 = percentile(latencies, 90)
+	stats.P99 = percentile(latencies, 99)
+	stats.Max = latencies[len(latencies)-1]
+}
+
+// percentile uses the nearest-rank method on sorted latencies
+func percentile(sorted []time.Duration, p int) time.Duration {
+	rank := (p*len(sorted) + 99) / 100
+	if rank < 1 {
+		rank = 1
+	}
+
+	return sorted[rank-1]
+}
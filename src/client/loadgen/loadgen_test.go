@@ -0,0 +1,101 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package loadgen
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeInvoker struct {
+	mu        sync.Mutex
+	submitted int
+	evaluated int
+}
+
+func (f *fakeInvoker) SubmitTransaction(name string, args ...string) ([]byte, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.submitted++
+	if f.submitted%2 == 0 {
+		return nil, errors.New("transaction invalidated with status (MVCC_READ_CONFLICT)")
+	}
+	return nil, nil
+}
+
+func (f *fakeInvoker) EvaluateTransaction(name string, args ...string) ([]byte, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.evaluated++
+	return nil, nil
+}
+
+func TestRun(t *testing.T) {
+	args := func(r *rand.Rand) (string, []string) { return "Tx", nil }
+	invokers := []Invoker{new(fakeInvoker), new(fakeInvoker)}
+
+	report, err := Run(context.Background(), Config{
+		Rate:        500,
+		Duration:    200 * time.Millisecond,
+		Concurrency: 10,
+		Operations: []Operation{
+			{Name: "submit", Weight: 1, Submit: true, Args: args},
+			{Name: "evaluate", Weight: 1, Args: args},
+		},
+	}, invokers)
+	require.NoError(t, err)
+
+	submit, evaluate := report.Operations[0], report.Operations[1]
+	assert.NotZero(t, submit.Requests)
+	assert.NotZero(t, evaluate.Requests)
+	assert.Zero(t, evaluate.Errors)
+	assert.Equal(t, submit.Errors, submit.MVCCConflicts, "should classify MVCC conflicts")
+
+	f0, f1 := invokers[0].(*fakeInvoker), invokers[1].(*fakeInvoker)
+	assert.NotZero(t, f0.submitted+f0.evaluated, "should use every invoker")
+	assert.NotZero(t, f1.submitted+f1.evaluated, "should use every invoker")
+	assert.Equal(t, submit.Requests+evaluate.Requests, f0.submitted+f0.evaluated+f1.submitted+f1.evaluated)
+}
+
+func TestRunValidation(t *testing.T) {
+	args := func(r *rand.Rand) (string, []string) { return "Tx", nil }
+	ops := []Operation{{Name: "op", Weight: 1, Args: args}}
+
+	_, err := Run(context.Background(), Config{Rate: 0, Duration: time.Second, Operations: ops}, []Invoker{new(fakeInvoker)})
+	assert.EqualError(t, err, "rate must be positive")
+
+	_, err = Run(context.Background(), Config{Rate: 1, Duration: time.Second, Operations: ops}, nil)
+	assert.EqualError(t, err, "at least one invoker is required")
+
+	_, err = Run(context.Background(), Config{Rate: 1, Duration: time.Second, Operations: []Operation{{Name: "op", Args: args}}}, []Invoker{new(fakeInvoker)})
+	assert.EqualError(t, err, "operation weights must add up to more than zero")
+}
+
+func TestPercentile(t *testing.T) {
+	var latencies []time.Duration
+	for i := 1; i <= 100; i++ {
+		latencies = append(latencies, time.Duration(i)*time.Millisecond)
+	}
+
+	stats := Stats{}
+	fillPercentiles(&stats, latencies)
+	assert.Equal(t, 50*time.Millisecond, stats.P50)
+	assert.Equal(t, 90*time.Millisecond, stats.P90)
+	assert.Equal(t, 99*time.Millisecond, stats.P99)
+	assert.Equal(t, 100*time.Millisecond, stats.Max)
+
+	stats = Stats{}
+	fillPercentiles(&stats, []time.Duration{time.Second})
+	assert.Equal(t, time.Second, stats.P99, "single sample is every percentile")
+}
@@ -0,0 +1,176 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+*/
+
+// Package migrate converts the account and transaction records of a token
+// ledger between the JSON and protobuf encodings, or to the next schema
+// version after a chaincode upgrade, a bounded step per transaction
+package migrate
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+)
+
+// Contract submits and evaluates transactions. The fabric-sdk-go gateway
+// Contract satisfies it.
+type Contract interface {
+	SubmitTransaction(name string, args ...string) ([]byte, error)
+	EvaluateTransaction(name string, args ...string) ([]byte, error)
+}
+
+// Progress reports a committed migration step
+type Progress struct {
+	// Range is "records" for the simple keys, "shard N", or "schema vN"
+	Range string
+	// Migrated counts the records rewritten so far
+	Migrated int
+}
+
+// Run selects encoding for new records, then rewrites the existing ones
+// in steps of at most limit records, 0 for the contract's default, calling
+// progress after each step if it is not nil. Steps skip records already
+// in the encoding, so a failed run is resumed by running it again.
+func Run(c Contract, encoding string, limit int, progress func(Progress)) (int, error) {
+	if _, err := c.SubmitTransaction("SetRecordEncoding", encoding); err != nil {
+		return 0, err
+	}
+
+	total := 0
+	if err := steps(c, limit, progress, &total, "MigrateRecords", "records"); err != nil {
+		return total, err
+	}
+
+	shards, err := shardCount(c)
+	if err != nil {
+		return total, err
+	}
+	if shards == 1 {
+		return total, nil
+	}
+
+	for shard := 0; shard < shards; shard++ {
+		if err := steps(c, limit, progress, &total, "MigrateShard", fmt.Sprintf("shard %d", shard), strconv.Itoa(shard)); err != nil {
+			return total, err
+		}
+	}
+
+	return total, nil
+}
+
+// Schema upgrades the records from schema version from to to, the next
+// version, with MigrateState steps of at most limit records like Run. The
+// last step records the new version, after which a rerun fails.
+func Schema(c Contract, from int, to int, limit int, progress func(Progress)) (int, error) {
+	total := 0
+	err := steps(c, limit, progress, &total, "MigrateState", fmt.Sprintf("schema v%d", to), strconv.Itoa(from), strconv.Itoa(to))
+	return total, err
+}
+
+func shardCount(c Contract) (int, error) {
+	payload, err := c.EvaluateTransaction("ShardCount")
+	if err != nil {
+		return 0, err
+	}
+	shards, err := strconv.Atoi(string(payload))
+	if err != nil {
+		return 0, fmt.Errorf("unexpected shard count %q: %v", payload, err)
+	}
+
+	return shards, nil
+}
+
+// steps submits name with args, limit and a bookmark until the range is
+// done, adding the records migrated to total
+func steps(c Contract, limit int, progress func(Progress), total *int, name string, rangeName string, args ...string) error {
+	bookmark := ""
+	for {
+		payload, err := c.SubmitTransaction(name, append(args, strconv.Itoa(limit), bookmark)...)
+		if err != nil {
+			return fmt.Errorf("%s from %q: %w", rangeName, bookmark, err)
+		}
+
+		var page struct {
+			Migrated int    `json:"migrated"`
+			Bookmark string `json:"bookmark"`
+		}
+		if err := json.Unmarshal(payload, &page); err != nil {
+			return fmt.Errorf("%s: unexpected result %q: %v", rangeName, payload, err)
+		}
+
+		*total += page.Migrated
+		if progress != nil {
+			progress(Progress{Range: rangeName, Migrated: *total})
+		}
+		if page.Bookmark == "" {
+			return nil
+		}
+		bookmark = page.Bookmark
+	}
+}
+
+// Supply returns the ledger's total supply, summing TotalSupply over every
+// page of every shard, pages of at most limit accounts
+func Supply(c Contract, limit int) (int, error) {
+	shards, err := shardCount(c)
+	if err != nil {
+		return 0, err
+	}
+
+	total := 0
+	for shard := 0; shard < shards; shard++ {
+		bookmark := ""
+		for {
+			payload, err := c.EvaluateTransaction("TotalSupply", strconv.Itoa(shard), strconv.Itoa(limit), bookmark)
+			if err != nil {
+				return 0, fmt.Errorf("supply of shard %d from %q: %w", shard, bookmark, err)
+			}
+
+			var page struct {
+				Supply   int    `json:"supply"`
+				Bookmark string `json:"bookmark"`
+			}
+			if err := json.Unmarshal(payload, &page); err != nil {
+				return 0, fmt.Errorf("supply of shard %d: unexpected result %q: %v", shard, payload, err)
+			}
+
+			if page.Supply > math.MaxInt64-total {
+				return 0, fmt.Errorf("total supply overflows")
+			}
+			total += page.Supply
+			if page.Bookmark == "" {
+				break
+			}
+			bookmark = page.Bookmark
+		}
+	}
+
+	return total, nil
+}
+
+// Verified runs a migration between two Supply queries and fails if they
+// differ: rewriting records must not change any balance. The ledger must be
+// quiet, or transfers committed meanwhile are reported as a difference.
+func Verified(c Contract, limit int, run func() (int, error)) (int, error) {
+	before, err := Supply(c, limit)
+	if err != nil {
+		return 0, fmt.Errorf("before migration: %w", err)
+	}
+
+	migrated, err := run()
+	if err != nil {
+		return migrated, err
+	}
+
+	after, err := Supply(c, limit)
+	if err != nil {
+		return migrated, fmt.Errorf("after migration: %w", err)
+	}
+	if after != before {
+		return migrated, fmt.Errorf("total supply changed from %d to %d", before, after)
+	}
+
+	return migrated, nil
+}
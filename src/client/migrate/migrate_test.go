@@ -0,0 +1,134 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package migrate
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeContract migrates ranges of pending records: records[""] for the
+// simple keys and records["N"] for shard N. The bookmark is the number of
+// records already visited.
+type fakeContract struct {
+	encoding string
+	records  map[string]int
+	calls    []string
+	fail     string
+	// supply is every shard's TotalSupply, changed by drift per step
+	supply int
+	drift  int
+}
+
+func (f *fakeContract) SubmitTransaction(name string, args ...string) ([]byte, error) {
+	call := fmt.Sprint(name, " ", args)
+	f.calls = append(f.calls, call)
+	if call == f.fail {
+		return nil, errors.New("MVCC_READ_CONFLICT")
+	}
+
+	switch name {
+	case "SetRecordEncoding":
+		f.encoding = args[0]
+		return nil, nil
+	case "MigrateRecords", "MigrateShard", "MigrateState":
+		shard := ""
+		if name == "MigrateShard" {
+			shard, args = args[0], args[1:]
+		}
+		if name == "MigrateState" {
+			args = args[2:]
+		}
+		limit, _ := strconv.Atoi(args[0])
+		if limit == 0 {
+			limit = 100
+		}
+		start, _ := strconv.Atoi(args[1])
+		f.supply += f.drift
+
+		end, bookmark := f.records[shard], ""
+		if start+limit < end {
+			end, bookmark = start+limit, strconv.Itoa(start+limit)
+		}
+		return json.Marshal(map[string]interface{}{"migrated": end - start, "bookmark": bookmark})
+	}
+	return nil, fmt.Errorf("unexpected transaction %s", name)
+}
+
+func (f *fakeContract) EvaluateTransaction(name string, args ...string) ([]byte, error) {
+	if name == "TotalSupply" {
+		return json.Marshal(map[string]interface{}{"accounts": 1, "supply": f.supply, "bookmark": ""})
+	}
+	if name != "ShardCount" {
+		return nil, fmt.Errorf("unexpected transaction %s", name)
+	}
+	shards := len(f.records) - 1
+	if shards == 0 {
+		shards = 1
+	}
+	return []byte(strconv.Itoa(shards)), nil
+}
+
+func TestRun(t *testing.T) {
+	c := &fakeContract{records: map[string]int{"": 3}}
+
+	var reported []Progress
+	total, err := Run(c, "protobuf", 2, func(p Progress) { reported = append(reported, p) })
+	require.NoError(t, err)
+	assert.Equal(t, 3, total)
+	assert.Equal(t, "protobuf", c.encoding)
+	assert.Equal(t, []string{"SetRecordEncoding [protobuf]", "MigrateRecords [2 ]", "MigrateRecords [2 2]"}, c.calls)
+	assert.Equal(t, []Progress{{"records", 2}, {"records", 3}}, reported)
+}
+
+func TestRunSharded(t *testing.T) {
+	c := &fakeContract{records: map[string]int{"": 1, "0": 2, "1": 0}}
+
+	total, err := Run(c, "json", 0, nil)
+	require.NoError(t, err)
+	assert.Equal(t, 3, total)
+	assert.Equal(t, []string{"SetRecordEncoding [json]", "MigrateRecords [0 ]", "MigrateShard [0 0 ]", "MigrateShard [1 0 ]"}, c.calls)
+}
+
+func TestRunFailure(t *testing.T) {
+	c := &fakeContract{records: map[string]int{"": 1, "0": 5, "1": 0}, fail: "MigrateShard [0 2 2]"}
+
+	total, err := Run(c, "protobuf", 2, nil)
+	assert.EqualError(t, err, `shard 0 from "2": MVCC_READ_CONFLICT`)
+	assert.Equal(t, 3, total, "steps committed before the failure")
+}
+
+func TestSchema(t *testing.T) {
+	c := &fakeContract{records: map[string]int{"": 3}}
+
+	var reported []Progress
+	total, err := Schema(c, 1, 2, 2, func(p Progress) { reported = append(reported, p) })
+	require.NoError(t, err)
+	assert.Equal(t, 3, total)
+	assert.Equal(t, []string{"MigrateState [1 2 2 ]", "MigrateState [1 2 2 2]"}, c.calls)
+	assert.Equal(t, []Progress{{"schema v2", 2}, {"schema v2", 3}}, reported)
+}
+
+func TestVerified(t *testing.T) {
+	c := &fakeContract{records: map[string]int{"": 3, "0": 0, "1": 0}, supply: 50}
+
+	supply, err := Supply(c, 0)
+	require.NoError(t, err)
+	assert.Equal(t, 100, supply, "two shards")
+
+	total, err := Verified(c, 0, func() (int, error) { return Run(c, "protobuf", 2, nil) })
+	require.NoError(t, err)
+	assert.Equal(t, 3, total)
+
+	c.drift = 1
+	_, err = Verified(c, 0, func() (int, error) { return Schema(c, 1, 2, 2, nil) })
+	assert.EqualError(t, err, "total supply changed from 100 to 104")
+}
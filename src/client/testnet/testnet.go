@@ -0,0 +1,175 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+*/
+
+// Package testnet drives the docker compose test network in test-network:
+// bringing it up with a channel, deploying the token chaincode with a chosen
+// endorsement policy and tearing it down again. The integration tests use it
+// to get a fresh network per run.
+package testnet
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+
+	"github.com/kkiu1756/my_fabric/src/client/wallet"
+)
+
+// Endorsement policies for the two organisation test network
+const (
+	AnyOrg  = "OR('Org1MSP.peer','Org2MSP.peer')"
+	AllOrgs = "AND('Org1MSP.peer','Org2MSP.peer')"
+)
+
+// Chaincode describes a chaincode deployment. Zero values fall back to the
+// defaults of network.sh, except Name, Path and Language which default to
+// the token chaincode in src/chaincode-go.
+type Chaincode struct {
+	Name              string
+	Path              string
+	Language          string
+	Version           string
+	Sequence          int
+	EndorsementPolicy string
+	InitFunction      string
+}
+
+// Network is a test network managed through network.sh
+type Network struct {
+	// Dir is the test-network directory containing network.sh
+	Dir     string
+	Channel string
+	// CA uses Fabric CAs rather than cryptogen for the crypto material
+	CA bool
+	// Database is leveldb or couchdb
+	Database string
+	Stdout   io.Writer
+	Stderr   io.Writer
+}
+
+// New returns a network for the test-network directory dir on mychannel
+func New(dir string) *Network {
+	return &Network{
+		Dir:      dir,
+		Channel:  "mychannel",
+		Database: "leveldb",
+		Stdout:   os.Stdout,
+		Stderr:   os.Stderr,
+	}
+}
+
+// Up starts the orderer and peers and creates the channel
+func (n *Network) Up(ctx context.Context) error {
+	if err := n.command(ctx, n.upArgs()...).Run(); err != nil {
+		return fmt.Errorf("failed to bring up test network: %v", err)
+	}
+
+	return nil
+}
+
+// Deploy packages, installs, approves and commits the chaincode
+func (n *Network) Deploy(ctx context.Context, cc Chaincode) error {
+	args, err := n.deployArgs(cc)
+	if err != nil {
+		return err
+	}
+
+	if err := n.command(ctx, args...).Run(); err != nil {
+		return fmt.Errorf("failed to deploy chaincode %s: %v", args[2], err)
+	}
+
+	return nil
+}
+
+// Down stops the network and removes its containers, volumes and crypto
+// material
+func (n *Network) Down(ctx context.Context) error {
+	if err := n.command(ctx, "down").Run(); err != nil {
+		return fmt.Errorf("failed to tear down test network: %v", err)
+	}
+
+	return nil
+}
+
+// ConnectionProfile returns the path of the SDK connection profile that
+// network.sh generates for org (1 or 2)
+func (n *Network) ConnectionProfile(org int) string {
+	domain := orgDomain(org)
+	return filepath.Join(n.Dir, "organizations", "peerOrganizations", domain, "connection-org"+strconv.Itoa(org)+".yaml")
+}
+
+// Identity returns the User1 identity of org
+func (n *Network) Identity(org int) (*wallet.Identity, error) {
+	return n.user(org, "User1")
+}
+
+// AdminIdentity returns the Admin identity of org, which the token
+// contract requires for Initialize
+func (n *Network) AdminIdentity(org int) (*wallet.Identity, error) {
+	return n.user(org, "Admin")
+}
+
+func (n *Network) user(org int, name string) (*wallet.Identity, error) {
+	domain := orgDomain(org)
+	msp := filepath.Join(n.Dir, "organizations", "peerOrganizations", domain, "users", name+"@"+domain, "msp")
+
+	return wallet.FromMSP("Org"+strconv.Itoa(org)+"MSP", msp)
+}
+
+func (n *Network) upArgs() []string {
+	args := []string{"up", "createChannel", "-c", n.Channel, "-s", n.Database}
+	if n.CA {
+		args = append(args, "-ca")
+	}
+
+	return args
+}
+
+func (n *Network) deployArgs(cc Chaincode) ([]string, error) {
+	if cc.Name == "" {
+		cc.Name = "basic"
+	}
+	if cc.Language == "" {
+		cc.Language = "go"
+	}
+	if cc.Path == "" {
+		cc.Path = filepath.Join("..", "src", "chaincode-go")
+	}
+	if cc.Sequence < 0 {
+		return nil, fmt.Errorf("invalid chaincode sequence %d", cc.Sequence)
+	}
+
+	args := []string{"deployCC", "-ccn", cc.Name, "-ccp", cc.Path, "-ccl", cc.Language, "-c", n.Channel}
+	if cc.Version != "" {
+		args = append(args, "-ccv", cc.Version)
+	}
+	if cc.Sequence > 0 {
+		args = append(args, "-ccs", strconv.Itoa(cc.Sequence))
+	}
+	if cc.EndorsementPolicy != "" {
+		args = append(args, "-ccep", cc.EndorsementPolicy)
+	}
+	if cc.InitFunction != "" {
+		args = append(args, "-cci", cc.InitFunction)
+	}
+
+	return args, nil
+}
+
+func (n *Network) command(ctx context.Context, args ...string) *exec.Cmd {
+	cmd := exec.CommandContext(ctx, "./network.sh", args...)
+	cmd.Dir = n.Dir
+	cmd.Stdout = n.Stdout
+	cmd.Stderr = n.Stderr
+
+	return cmd
+}
+
+func orgDomain(org int) string {
+	return "org" + strconv.Itoa(org) + ".example.com"
+}
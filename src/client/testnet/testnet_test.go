@@ -0,0 +1,81 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package testnet
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUpArgs(t *testing.T) {
+	n := New("test-network")
+	assert.Equal(t, []string{"up", "createChannel", "-c", "mychannel", "-s", "leveldb"}, n.upArgs())
+
+	n.CA = true
+	n.Database = "couchdb"
+	n.Channel = "tokens"
+	assert.Equal(t, []string{"up", "createChannel", "-c", "tokens", "-s", "couchdb", "-ca"}, n.upArgs())
+}
+
+func TestDeployArgs(t *testing.T) {
+	n := New("test-network")
+
+	args, err := n.deployArgs(Chaincode{})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"deployCC", "-ccn", "basic", "-ccp", filepath.Join("..", "src", "chaincode-go"), "-ccl", "go", "-c", "mychannel"}, args)
+
+	args, err = n.deployArgs(Chaincode{Name: "token", Path: "/cc", Version: "2.0", Sequence: 2, EndorsementPolicy: AllOrgs, InitFunction: "Init"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		"deployCC", "-ccn", "token", "-ccp", "/cc", "-ccl", "go", "-c", "mychannel",
+		"-ccv", "2.0", "-ccs", "2", "-ccep", AllOrgs, "-cci", "Init",
+	}, args)
+
+	_, err = n.deployArgs(Chaincode{Sequence: -1})
+	assert.EqualError(t, err, "invalid chaincode sequence -1")
+}
+
+func TestCommand(t *testing.T) {
+	n := New("/fabric/test-network")
+	cmd := n.command(context.Background(), "down")
+	assert.Equal(t, "/fabric/test-network", cmd.Dir)
+	assert.Equal(t, []string{"./network.sh", "down"}, cmd.Args)
+}
+
+func TestIdentity(t *testing.T) {
+	dir, err := ioutil.TempDir("", "testnet")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	for _, user := range []string{"User1", "Admin"} {
+		msp := filepath.Join(dir, "organizations", "peerOrganizations", "org1.example.com", "users", user+"@org1.example.com", "msp")
+		require.NoError(t, os.MkdirAll(filepath.Join(msp, "signcerts"), 0755))
+		require.NoError(t, os.MkdirAll(filepath.Join(msp, "keystore"), 0755))
+		require.NoError(t, ioutil.WriteFile(filepath.Join(msp, "signcerts", "cert.pem"), []byte(user+" CERT"), 0644))
+		require.NoError(t, ioutil.WriteFile(filepath.Join(msp, "keystore", "abc_sk"), []byte("KEY"), 0600))
+	}
+
+	n := New(dir)
+	id, err := n.Identity(1)
+	require.NoError(t, err)
+	assert.Equal(t, "Org1MSP", id.MSPID)
+	assert.Equal(t, "User1 CERT", id.Certificate)
+	assert.Equal(t, "KEY", id.Key)
+
+	admin, err := n.AdminIdentity(1)
+	require.NoError(t, err)
+	assert.Equal(t, "Admin CERT", admin.Certificate)
+
+	_, err = n.Identity(2)
+	assert.Error(t, err)
+
+	assert.Equal(t, filepath.Join(dir, "organizations", "peerOrganizations", "org2.example.com", "connection-org2.yaml"), n.ConnectionProfile(2))
+}
@@ -0,0 +1,156 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package wallet
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+)
+
+const idExtension = ".id"
+
+// FileStore keeps one <label>.id file per identity in a directory
+type FileStore struct {
+	dir string
+}
+
+// NewFileStore returns a store in dir, creating it if needed
+func NewFileStore(dir string) (*FileStore, error) {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, fmt.Errorf("failed to create wallet directory: %v", err)
+	}
+
+	return &FileStore{dir: dir}, nil
+}
+
+// Put writes the identity, replacing any existing one with the same label
+func (s *FileStore) Put(label string, id *Identity) error {
+	data, err := marshalIdentity(id)
+	if err != nil {
+		return fmt.Errorf("failed to marshal identity: %v", err)
+	}
+
+	path := s.path(label)
+	tmp := path + ".tmp"
+	if err := ioutil.WriteFile(tmp, data, 0600); err != nil {
+		return fmt.Errorf("failed to write identity: %v", err)
+	}
+
+	return os.Rename(tmp, path)
+}
+
+// Get reads the identity stored under label
+func (s *FileStore) Get(label string) (*Identity, error) {
+	data, err := ioutil.ReadFile(s.path(label))
+	if os.IsNotExist(err) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read identity: %v", err)
+	}
+
+	id, err := unmarshalIdentity(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse identity %s: %v", label, err)
+	}
+
+	return id, nil
+}
+
+// List returns the stored labels in order
+func (s *FileStore) List() ([]string, error) {
+	files, err := ioutil.ReadDir(s.dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read wallet directory: %v", err)
+	}
+
+	var labels []string
+	for _, f := range files {
+		if !f.IsDir() && strings.HasSuffix(f.Name(), idExtension) {
+			labels = append(labels, strings.TrimSuffix(f.Name(), idExtension))
+		}
+	}
+
+	return labels, nil
+}
+
+// Remove deletes the identity stored under label
+func (s *FileStore) Remove(label string) error {
+	err := os.Remove(s.path(label))
+	if os.IsNotExist(err) {
+		return ErrNotFound
+	}
+
+	return err
+}
+
+func (s *FileStore) path(label string) string {
+	return filepath.Join(s.dir, label+idExtension)
+}
+
+// MemoryStore keeps identities in memory, for tests and short-lived tools
+type MemoryStore struct {
+	mu         sync.RWMutex
+	identities map[string]Identity
+}
+
+// NewMemoryStore returns an empty store
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{identities: make(map[string]Identity)}
+}
+
+// Put stores a copy of id under label
+func (s *MemoryStore) Put(label string, id *Identity) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.identities[label] = *id
+	return nil
+}
+
+// Get returns a copy of the identity stored under label
+func (s *MemoryStore) Get(label string) (*Identity, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	id, ok := s.identities[label]
+	if !ok {
+		return nil, ErrNotFound
+	}
+
+	return &id, nil
+}
+
+// List returns the stored labels in order
+func (s *MemoryStore) List() ([]string, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	labels := make([]string, 0, len(s.identities))
+	for label := range s.identities {
+		labels = append(labels, label)
+	}
+	sort.Strings(labels)
+
+	return labels, nil
+}
+
+// Remove deletes the identity stored under label
+func (s *MemoryStore) Remove(label string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.identities[label]; !ok {
+		return ErrNotFound
+	}
+	delete(s.identities, label)
+
+	return nil
+}
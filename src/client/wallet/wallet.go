@@ -0,0 +1,232 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+*/
+
+// Package wallet stores the X.509 identities client tools sign with. Stores
+// are pluggable; FileStore uses the same <label>.id layout as the
+// fabric-network file system wallet, so the Go tools and the JavaScript
+// gateway can share one wallet directory.
+package wallet
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"reflect"
+
+	"github.com/hyperledger/fabric-sdk-go/pkg/gateway"
+)
+
+// ErrNotFound is returned by Store.Get for an unknown label
+var ErrNotFound = errors.New("identity not found")
+
+// Identity is an X.509 identity with PEM encoded certificate and key. HSM
+// identities have no Key; their private key stays in a PKCS#11 token and
+// they connect through the hsm package.
+type Identity struct {
+	MSPID       string
+	Certificate string
+	Key         string
+	HSM         bool
+}
+
+// Store persists identities by label. Implement it to keep identities in a
+// database or secret manager.
+type Store interface {
+	Put(label string, id *Identity) error
+	Get(label string) (*Identity, error)
+	List() ([]string, error)
+	Remove(label string) error
+}
+
+// X509 converts the identity for use in a gateway wallet
+func (id *Identity) X509() *gateway.X509Identity {
+	return gateway.NewX509Identity(id.MSPID, id.Certificate, id.Key)
+}
+
+// Signer parses the identity's private key, to sign data other than
+// transactions with it
+func (id *Identity) Signer() (crypto.Signer, error) {
+	if id.HSM {
+		return nil, fmt.Errorf("private key is held in an HSM")
+	}
+	block, _ := pem.Decode([]byte(id.Key))
+	if block == nil {
+		return nil, fmt.Errorf("private key is not PEM encoded")
+	}
+
+	key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		key, err = x509.ParseECPrivateKey(block.Bytes)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse private key: %v", err)
+	}
+	signer, ok := key.(crypto.Signer)
+	if !ok {
+		return nil, fmt.Errorf("private key cannot sign")
+	}
+
+	return signer, nil
+}
+
+// IdentityOption loads label from store and returns the gateway option
+// connecting as it
+func IdentityOption(store Store, label string) (gateway.IdentityOption, error) {
+	id, err := store.Get(label)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get identity %s: %v", label, err)
+	}
+	if id.HSM {
+		return nil, fmt.Errorf("identity %s is held in an HSM", label)
+	}
+
+	w := gateway.NewInMemoryWallet()
+	if err := w.Put(label, id.X509()); err != nil {
+		return nil, fmt.Errorf("failed to load identity %s: %v", label, err)
+	}
+
+	return gateway.WithIdentity(w, label), nil
+}
+
+// FromMSP reads the identity in an MSP directory, such as the ones cryptogen
+// and the Fabric CA client write under organizations/
+func FromMSP(mspID string, dir string) (*Identity, error) {
+	cert, err := readSingleFile(filepath.Join(dir, "signcerts"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read certificate: %v", err)
+	}
+	key, err := readSingleFile(filepath.Join(dir, "keystore"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read private key: %v", err)
+	}
+
+	return &Identity{MSPID: mspID, Certificate: string(cert), Key: string(key)}, nil
+}
+
+// ImportMSP stores the identity in an MSP directory under label
+func ImportMSP(store Store, label string, mspID string, dir string) error {
+	id, err := FromMSP(mspID, dir)
+	if err != nil {
+		return err
+	}
+
+	return store.Put(label, id)
+}
+
+// ImportEnrollment stores the certificate returned by a Fabric CA enrollment
+// together with the private key the enrollment request was generated with
+func ImportEnrollment(store Store, label string, mspID string, certPEM []byte, key crypto.Signer) error {
+	block, _ := pem.Decode(certPEM)
+	if block == nil {
+		return fmt.Errorf("enrollment certificate is not PEM encoded")
+	}
+	cert, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		return fmt.Errorf("failed to parse enrollment certificate: %v", err)
+	}
+	if !publicKeysEqual(cert.PublicKey, key.Public()) {
+		return fmt.Errorf("private key does not match the enrollment certificate")
+	}
+
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		return fmt.Errorf("failed to marshal private key: %v", err)
+	}
+
+	return store.Put(label, &Identity{
+		MSPID:       mspID,
+		Certificate: string(certPEM),
+		Key:         string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})),
+	})
+}
+
+// ImportHSM stores an enrollment certificate whose private key is held in a
+// PKCS#11 token
+func ImportHSM(store Store, label string, mspID string, certPEM []byte) error {
+	block, _ := pem.Decode(certPEM)
+	if block == nil {
+		return fmt.Errorf("enrollment certificate is not PEM encoded")
+	}
+	if _, err := x509.ParseCertificate(block.Bytes); err != nil {
+		return fmt.Errorf("failed to parse enrollment certificate: %v", err)
+	}
+
+	return store.Put(label, &Identity{MSPID: mspID, Certificate: string(certPEM), HSM: true})
+}
+
+func publicKeysEqual(a, b crypto.PublicKey) bool {
+	if ka, ok := a.(*ecdsa.PublicKey); ok {
+		kb, ok := b.(*ecdsa.PublicKey)
+		return ok && ka.X.Cmp(kb.X) == 0 && ka.Y.Cmp(kb.Y) == 0 && ka.Curve == kb.Curve
+	}
+
+	return reflect.DeepEqual(a, b)
+}
+
+// readSingleFile reads the only file in dir, as cryptogen and the CA name
+// certificates and keys differently
+func readSingleFile(dir string) ([]byte, error) {
+	files, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	if len(files) != 1 {
+		return nil, fmt.Errorf("expected one file in %s, found %d", dir, len(files))
+	}
+
+	return ioutil.ReadFile(filepath.Join(dir, files[0].Name()))
+}
+
+const (
+	x509Type    = "X.509"
+	hsmX509Type = "HSM-X.509"
+)
+
+// identityJSON is the fabric-network wallet format
+type identityJSON struct {
+	Credentials struct {
+		Certificate string `json:"certificate"`
+		PrivateKey  string `json:"privateKey,omitempty"`
+	} `json:"credentials"`
+	MSPID   string `json:"mspId"`
+	Type    string `json:"type"`
+	Version int    `json:"version"`
+}
+
+func marshalIdentity(id *Identity) ([]byte, error) {
+	var v identityJSON
+	v.Credentials.Certificate = id.Certificate
+	v.Credentials.PrivateKey = id.Key
+	v.MSPID = id.MSPID
+	v.Type = x509Type
+	if id.HSM {
+		v.Type = hsmX509Type
+	}
+	v.Version = 1
+
+	return json.Marshal(v)
+}
+
+func unmarshalIdentity(data []byte) (*Identity, error) {
+	var v identityJSON
+	if err := json.Unmarshal(data, &v); err != nil {
+		return nil, err
+	}
+	if v.Type != x509Type && v.Type != hsmX509Type {
+		return nil, fmt.Errorf("unsupported identity type %q", v.Type)
+	}
+
+	return &Identity{
+		MSPID:       v.MSPID,
+		Certificate: v.Credentials.Certificate,
+		Key:         v.Credentials.PrivateKey,
+		HSM:         v.Type == hsmX509Type,
+	}, nil
+}
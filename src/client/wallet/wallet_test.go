@@ -0,0 +1,191 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package wallet
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io/ioutil"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/hyperledger/fabric-sdk-go/pkg/gateway"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// #########
+// HELPERS
+// #########
+
+func tempDir(t *testing.T) string {
+	dir, err := ioutil.TempDir("", "wallet")
+	require.NoError(t, err)
+	t.Cleanup(func() { os.RemoveAll(dir) })
+	return dir
+}
+
+func selfSigned(t *testing.T, key *ecdsa.PrivateKey) []byte {
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "user1"},
+		NotBefore:    time.Now(),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+}
+
+func testStore(t *testing.T, store Store) {
+	id := &Identity{MSPID: "Org1MSP", Certificate: "CERT", Key: "KEY"}
+	require.NoError(t, store.Put("user1", id))
+	require.NoError(t, store.Put("admin", id))
+
+	got, err := store.Get("user1")
+	require.NoError(t, err)
+	assert.Equal(t, id, got)
+
+	labels, err := store.List()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"admin", "user1"}, labels)
+
+	require.NoError(t, store.Remove("user1"))
+	_, err = store.Get("user1")
+	assert.Equal(t, ErrNotFound, err)
+	assert.Equal(t, ErrNotFound, store.Remove("user1"))
+}
+
+// #########
+// TESTS
+// #########
+
+func TestFileStore(t *testing.T) {
+	store, err := NewFileStore(tempDir(t))
+	require.NoError(t, err)
+	testStore(t, store)
+}
+
+func TestMemoryStore(t *testing.T) {
+	testStore(t, NewMemoryStore())
+}
+
+func TestFileStoreCompatibleWithGatewayWallet(t *testing.T) {
+	dir := tempDir(t)
+	store, err := NewFileStore(dir)
+	require.NoError(t, err)
+	require.NoError(t, store.Put("user1", &Identity{MSPID: "Org1MSP", Certificate: "CERT", Key: "KEY"}))
+
+	w, err := gateway.NewFileSystemWallet(dir)
+	require.NoError(t, err)
+	id, err := w.Get("user1")
+	require.NoError(t, err)
+	x509id := id.(*gateway.X509Identity)
+	assert.Equal(t, "Org1MSP", x509id.MspID)
+	assert.Equal(t, "CERT", x509id.Certificate())
+
+	require.NoError(t, w.Put("user2", gateway.NewX509Identity("Org2MSP", "CERT2", "KEY2")))
+	got, err := store.Get("user2")
+	require.NoError(t, err)
+	assert.Equal(t, &Identity{MSPID: "Org2MSP", Certificate: "CERT2", Key: "KEY2"}, got)
+}
+
+func TestImportMSP(t *testing.T) {
+	dir := tempDir(t)
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "signcerts"), 0755))
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "keystore"), 0755))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "signcerts", "cert.pem"), []byte("CERT"), 0644))
+
+	store := NewMemoryStore()
+	err := ImportMSP(store, "user1", "Org1MSP", dir)
+	assert.EqualError(t, err, "failed to read private key: expected one file in "+filepath.Join(dir, "keystore")+", found 0")
+
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "keystore", "priv_sk"), []byte("KEY"), 0600))
+	require.NoError(t, ImportMSP(store, "user1", "Org1MSP", dir))
+
+	got, err := store.Get("user1")
+	require.NoError(t, err)
+	assert.Equal(t, &Identity{MSPID: "Org1MSP", Certificate: "CERT", Key: "KEY"}, got)
+}
+
+func TestImportEnrollment(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	other, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	cert := selfSigned(t, key)
+
+	store := NewMemoryStore()
+	err = ImportEnrollment(store, "user1", "Org1MSP", cert, other)
+	assert.EqualError(t, err, "private key does not match the enrollment certificate")
+
+	err = ImportEnrollment(store, "user1", "Org1MSP", []byte("garbage"), key)
+	assert.EqualError(t, err, "enrollment certificate is not PEM encoded")
+
+	require.NoError(t, ImportEnrollment(store, "user1", "Org1MSP", cert, key))
+	got, err := store.Get("user1")
+	require.NoError(t, err)
+	assert.Equal(t, string(cert), got.Certificate)
+
+	block, _ := pem.Decode([]byte(got.Key))
+	require.NotNil(t, block)
+	parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	require.NoError(t, err)
+	assert.Equal(t, key.D, parsed.(*ecdsa.PrivateKey).D)
+}
+
+func TestIdentitySigner(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	store := NewMemoryStore()
+	require.NoError(t, ImportEnrollment(store, "user1", "Org1MSP", selfSigned(t, key), key))
+	id, err := store.Get("user1")
+	require.NoError(t, err)
+
+	signer, err := id.Signer()
+	require.NoError(t, err)
+	assert.Equal(t, key.Public(), signer.Public())
+
+	_, err = (&Identity{Key: "KEY"}).Signer()
+	assert.EqualError(t, err, "private key is not PEM encoded")
+	_, err = (&Identity{HSM: true}).Signer()
+	assert.EqualError(t, err, "private key is held in an HSM")
+}
+
+func TestIdentityOption(t *testing.T) {
+	store := NewMemoryStore()
+	_, err := IdentityOption(store, "user1")
+	assert.EqualError(t, err, "failed to get identity user1: identity not found")
+
+	require.NoError(t, store.Put("user1", &Identity{MSPID: "Org1MSP", Certificate: "CERT", Key: "KEY"}))
+	option, err := IdentityOption(store, "user1")
+	require.NoError(t, err)
+	assert.NotNil(t, option)
+}
+
+func TestImportHSM(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	cert := selfSigned(t, key)
+
+	store, err := NewFileStore(tempDir(t))
+	require.NoError(t, err)
+	assert.EqualError(t, ImportHSM(store, "hsmuser", "Org1MSP", []byte("garbage")), "enrollment certificate is not PEM encoded")
+	require.NoError(t, ImportHSM(store, "hsmuser", "Org1MSP", cert))
+
+	got, err := store.Get("hsmuser")
+	require.NoError(t, err)
+	assert.Equal(t, &Identity{MSPID: "Org1MSP", Certificate: string(cert), HSM: true}, got)
+
+	_, err = IdentityOption(store, "hsmuser")
+	assert.EqualError(t, err, "identity hsmuser is held in an HSM")
+}
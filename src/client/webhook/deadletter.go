@@ -0,0 +1,73 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// FileDeadLetterQueue appends dead deliveries to a file, one JSON object per
+// line, for operators to inspect and replay
+type FileDeadLetterQueue struct {
+	mu   sync.Mutex
+	path string
+}
+
+// NewFileDeadLetterQueue returns a queue appending to the file at path
+func NewFileDeadLetterQueue(path string) *FileDeadLetterQueue {
+	return &FileDeadLetterQueue{path: path}
+}
+
+// Put appends delivery to the file
+func (q *FileDeadLetterQueue) Put(ctx context.Context, delivery Delivery) error {
+	line, err := json.Marshal(delivery)
+	if err != nil {
+		return err
+	}
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	f, err := os.OpenFile(filepath.Clean(q.path), os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return fmt.Errorf("failed to open dead-letter file: %v", err)
+	}
+
+	if _, err := f.Write(append(line, '\n')); err != nil {
+		f.Close()
+		return fmt.Errorf("failed to write dead-letter file: %v", err)
+	}
+
+	return f.Close()
+}
+
+// MemoryDeadLetterQueue keeps dead deliveries in memory
+type MemoryDeadLetterQueue struct {
+	mu         sync.Mutex
+	deliveries []Delivery
+}
+
+// Put records delivery
+func (q *MemoryDeadLetterQueue) Put(ctx context.Context, delivery Delivery) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	q.deliveries = append(q.deliveries, delivery)
+
+	return nil
+}
+
+// Deliveries returns the recorded deliveries
+func (q *MemoryDeadLetterQueue) Deliveries() []Delivery {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	return append([]Delivery(nil), q.deliveries...)
+}
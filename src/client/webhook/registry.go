@@ -0,0 +1,208 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package webhook
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Registry stores webhook subscriptions
+type Registry interface {
+	List(ctx context.Context) ([]Subscription, error)
+	Add(ctx context.Context, subscription Subscription) (Subscription, error)
+	Remove(ctx context.Context, id string) error
+}
+
+// MemoryRegistry keeps subscriptions in memory, optionally persisting them
+// to a JSON file so they survive restarts
+type MemoryRegistry struct {
+	mu            sync.RWMutex
+	subscriptions map[string]Subscription
+	path          string
+}
+
+// NewMemoryRegistry returns an empty, non-persistent registry
+func NewMemoryRegistry() *MemoryRegistry {
+	return &MemoryRegistry{subscriptions: map[string]Subscription{}}
+}
+
+// NewFileRegistry loads the subscriptions stored at path, if any, and
+// rewrites the file on every change
+func NewFileRegistry(path string) (*MemoryRegistry, error) {
+	r := &MemoryRegistry{subscriptions: map[string]Subscription{}, path: path}
+
+	data, err := ioutil.ReadFile(filepath.Clean(path))
+	if os.IsNotExist(err) {
+		return r, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read subscriptions: %v", err)
+	}
+
+	var subscriptions []Subscription
+	if err := json.Unmarshal(data, &subscriptions); err != nil {
+		return nil, fmt.Errorf("failed to parse subscriptions: %v", err)
+	}
+	for _, s := range subscriptions {
+		r.subscriptions[s.ID] = s
+	}
+
+	return r, nil
+}
+
+// List returns all subscriptions ordered by ID
+func (r *MemoryRegistry) List(ctx context.Context) ([]Subscription, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return r.sorted(), nil
+}
+
+// Add validates and stores a subscription, assigning an ID when it has none
+func (r *MemoryRegistry) Add(ctx context.Context, subscription Subscription) (Subscription, error) {
+	u, err := url.Parse(subscription.URL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return Subscription{}, fmt.Errorf("invalid webhook url %q", subscription.URL)
+	}
+	if subscription.MinAmount < 0 {
+		return Subscription{}, fmt.Errorf("minAmount cannot be negative")
+	}
+
+	if subscription.ID == "" {
+		id := make([]byte, 8)
+		if _, err := rand.Read(id); err != nil {
+			return Subscription{}, err
+		}
+		subscription.ID = hex.EncodeToString(id)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.subscriptions[subscription.ID]; ok {
+		return Subscription{}, fmt.Errorf("subscription %s already exists", subscription.ID)
+	}
+	r.subscriptions[subscription.ID] = subscription
+
+	if err := r.save(); err != nil {
+		delete(r.subscriptions, subscription.ID)
+		return Subscription{}, err
+	}
+
+	return subscription, nil
+}
+
+// Remove deletes a subscription
+func (r *MemoryRegistry) Remove(ctx context.Context, id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	subscription, ok := r.subscriptions[id]
+	if !ok {
+		return fmt.Errorf("subscription %s does not exist", id)
+	}
+	delete(r.subscriptions, id)
+
+	if err := r.save(); err != nil {
+		r.subscriptions[id] = subscription
+		return err
+	}
+
+	return nil
+}
+
+func (r *MemoryRegistry) sorted() []Subscription {
+	subscriptions := make([]Subscription, 0, len(r.subscriptions))
+	for _, s := range r.subscriptions {
+		subscriptions = append(subscriptions, s)
+	}
+	sort.Slice(subscriptions, func(i, j int) bool { return subscriptions[i].ID < subscriptions[j].ID })
+
+	return subscriptions
+}
+
+func (r *MemoryRegistry) save() error {
+	if r.path == "" {
+		return nil
+	}
+
+	data, err := json.MarshalIndent(r.sorted(), "", "  ")
+	if err != nil {
+		return err
+	}
+
+	tmp := r.path + ".tmp"
+	if err := ioutil.WriteFile(tmp, data, 0600); err != nil {
+		return fmt.Errorf("failed to write subscriptions: %v", err)
+	}
+
+	return os.Rename(tmp, r.path)
+}
+
+// Handler serves the registration API:
+//
+//	GET    /webhooks       list subscriptions (secrets omitted)
+//	POST   /webhooks       register a subscription
+//	DELETE /webhooks/{id}  remove a subscription
+func Handler(registry Registry) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := strings.Trim(strings.TrimPrefix(r.URL.Path, "/webhooks"), "/")
+
+		switch {
+		case r.Method == http.MethodGet && id == "":
+			subscriptions, err := registry.List(r.Context())
+			if err != nil {
+				writeJSON(w, http.StatusInternalServerError, map[string]string{"error": err.Error()})
+				return
+			}
+			for i := range subscriptions {
+				subscriptions[i].Secret = ""
+			}
+			writeJSON(w, http.StatusOK, subscriptions)
+
+		case r.Method == http.MethodPost && id == "":
+			var subscription Subscription
+			if err := json.NewDecoder(r.Body).Decode(&subscription); err != nil {
+				writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid subscription JSON"})
+				return
+			}
+			subscription, err := registry.Add(r.Context(), subscription)
+			if err != nil {
+				writeJSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+				return
+			}
+			subscription.Secret = ""
+			writeJSON(w, http.StatusCreated, subscription)
+
+		case r.Method == http.MethodDelete && id != "":
+			if err := registry.Remove(r.Context(), id); err != nil {
+				writeJSON(w, http.StatusNotFound, map[string]string{"error": err.Error()})
+				return
+			}
+			w.WriteHeader(http.StatusNoContent)
+
+		default:
+			writeJSON(w, http.StatusMethodNotAllowed, map[string]string{"error": "method not allowed"})
+		}
+	})
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
@@ -0,0 +1,264 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+*/
+
+// Package webhook delivers token events from the block listener to HTTP
+// endpoints registered by consumers. Payloads are signed with the
+// subscription secret, failed deliveries are retried with backoff and
+// deliveries that keep failing are handed to a dead-letter queue.
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"time"
+
+	"github.com/kkiu1756/my_fabric/src/client/blocks"
+)
+
+// SignatureHeader carries the hex HMAC-SHA256 of the body keyed with the
+// subscription secret, prefixed with "sha256="
+const SignatureHeader = "X-Token-Signature"
+
+// Subscription is a consumer endpoint with its event filters. Empty filters
+// match everything.
+type Subscription struct {
+	ID         string   `json:"id"`
+	URL        string   `json:"url"`
+	Secret     string   `json:"secret,omitempty"`
+	Accounts   []string `json:"accounts,omitempty"`
+	EventTypes []string `json:"eventTypes,omitempty"`
+	MinAmount  int      `json:"minAmount,omitempty"`
+}
+
+// Payload is the JSON body posted to subscribers
+type Payload struct {
+	DeliveryID  string          `json:"deliveryId"`
+	Event       string          `json:"event"`
+	TxID        string          `json:"txId"`
+	BlockNumber uint64          `json:"blockNumber"`
+	Timestamp   time.Time       `json:"timestamp"`
+	From        string          `json:"from,omitempty"`
+	To          string          `json:"to,omitempty"`
+	Value       int             `json:"value"`
+	Data        json.RawMessage `json:"data"`
+}
+
+// Delivery is a payload addressed to one subscription
+type Delivery struct {
+	Subscription Subscription `json:"subscription"`
+	Payload      Payload      `json:"payload"`
+	Attempts     int          `json:"attempts"`
+	LastError    string       `json:"lastError"`
+}
+
+// DeadLetterQueue stores deliveries that exhausted their retries
+type DeadLetterQueue interface {
+	Put(ctx context.Context, delivery Delivery) error
+}
+
+// RetryPolicy controls redelivery of failed webhook calls
+type RetryPolicy struct {
+	MaxAttempts    int
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+}
+
+// DefaultRetryPolicy tries five times, backing off from one second
+var DefaultRetryPolicy = RetryPolicy{MaxAttempts: 5, InitialBackoff: time.Second, MaxBackoff: 30 * time.Second}
+
+// Dispatcher is a blocks.Sink that posts matching events to subscribers
+type Dispatcher struct {
+	registry   Registry
+	deadLetter DeadLetterQueue
+	client     *http.Client
+	retry      RetryPolicy
+	sleep      func(ctx context.Context, d time.Duration) error
+}
+
+// NewDispatcher creates a dispatcher delivering to the subscriptions in registry
+func NewDispatcher(registry Registry, deadLetter DeadLetterQueue, client *http.Client, retry RetryPolicy) *Dispatcher {
+	if client == nil {
+		client = &http.Client{Timeout: 10 * time.Second}
+	}
+	if retry.MaxAttempts < 1 {
+		retry.MaxAttempts = 1
+	}
+
+	return &Dispatcher{
+		registry:   registry,
+		deadLetter: deadLetter,
+		client:     client,
+		retry:      retry,
+		sleep:      sleepContext,
+	}
+}
+
+// HandleBlock delivers every event of the block to the matching
+// subscriptions. It only fails when a delivery can neither be made nor
+// dead-lettered, so the listener retries the block later.
+func (d *Dispatcher) HandleBlock(ctx context.Context, block *blocks.Block) error {
+	subscriptions, err := d.registry.List(ctx)
+	if err != nil {
+		return err
+	}
+
+	for _, tx := range block.Transactions {
+		for i, event := range tx.Events {
+			payload := newPayload(tx, i, event)
+			for _, subscription := range subscriptions {
+				if !Matches(subscription, payload) {
+					continue
+				}
+				if err := d.deliver(ctx, subscription, payload); err != nil {
+					return err
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+func (d *Dispatcher) deliver(ctx context.Context, subscription Subscription, payload Payload) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	delivery := Delivery{Subscription: subscription, Payload: payload}
+	backoff := d.retry.InitialBackoff
+	for delivery.Attempts < d.retry.MaxAttempts {
+		if delivery.Attempts > 0 {
+			if err := d.sleep(ctx, backoff); err != nil {
+				return err
+			}
+			backoff *= 2
+			if d.retry.MaxBackoff > 0 && backoff > d.retry.MaxBackoff {
+				backoff = d.retry.MaxBackoff
+			}
+		}
+
+		delivery.Attempts++
+		err = d.post(ctx, subscription, payload.Event, body)
+		if err == nil {
+			return nil
+		}
+		delivery.LastError = err.Error()
+	}
+
+	if d.deadLetter == nil {
+		return fmt.Errorf("failed to deliver %s to %s: %s", payload.DeliveryID, subscription.URL, delivery.LastError)
+	}
+
+	return d.deadLetter.Put(ctx, delivery)
+}
+
+func (d *Dispatcher) post(ctx context.Context, subscription Subscription, eventName string, body []byte) error {
+	req, err := http.NewRequest(http.MethodPost, subscription.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req = req.WithContext(ctx)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Token-Event", eventName)
+	if subscription.Secret != "" {
+		req.Header.Set(SignatureHeader, Sign(subscription.Secret, body))
+	}
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	io.Copy(ioutil.Discard, resp.Body)
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("unexpected status %s", resp.Status)
+	}
+
+	return nil
+}
+
+// Sign returns the signature header value for body
+func Sign(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verify checks a signature header value, for use by webhook consumers
+func Verify(secret string, body []byte, signature string) bool {
+	return hmac.Equal([]byte(Sign(secret, body)), []byte(signature))
+}
+
+// Matches reports whether payload passes the subscription filters
+func Matches(subscription Subscription, payload Payload) bool {
+	if len(subscription.EventTypes) > 0 && !contains(subscription.EventTypes, payload.Event) {
+		return false
+	}
+	if len(subscription.Accounts) > 0 && !contains(subscription.Accounts, payload.From) && !contains(subscription.Accounts, payload.To) {
+		return false
+	}
+
+	return payload.Value >= subscription.MinAmount
+}
+
+func newPayload(tx blocks.Transaction, index int, event blocks.Event) Payload {
+	payload := Payload{
+		DeliveryID:  fmt.Sprintf("%s-%d", tx.TxID, index),
+		Event:       event.Name,
+		TxID:        tx.TxID,
+		BlockNumber: tx.BlockNumber,
+		Timestamp:   tx.Timestamp,
+		Data:        json.RawMessage(event.Payload),
+	}
+
+	// token events share the from/to/value layout; anything else is passed
+	// through in Data only
+	var transfer struct {
+		From  string `json:"from"`
+		To    string `json:"to"`
+		Value int    `json:"value"`
+	}
+	if json.Unmarshal(event.Payload, &transfer) == nil {
+		payload.From = transfer.From
+		payload.To = transfer.To
+		payload.Value = transfer.Value
+	} else {
+		payload.Data = nil
+	}
+
+	return payload
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+
+	return false
+}
+
+func sleepContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
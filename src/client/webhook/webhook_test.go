@@ -0,0 +1,162 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/kkiu1756/my_fabric/src/client/blocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// #########
+// HELPERS
+// #########
+
+type recorder struct {
+	mu       sync.Mutex
+	bodies   [][]byte
+	headers  []http.Header
+	failures int
+}
+
+func (rec *recorder) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	rec.mu.Lock()
+	defer rec.mu.Unlock()
+
+	if rec.failures > 0 {
+		rec.failures--
+		w.WriteHeader(http.StatusServiceUnavailable)
+		return
+	}
+
+	body, _ := ioutil.ReadAll(r.Body)
+	rec.bodies = append(rec.bodies, body)
+	rec.headers = append(rec.headers, r.Header)
+}
+
+func transferBlock() *blocks.Block {
+	return &blocks.Block{
+		Number: 3,
+		Transactions: []blocks.Transaction{
+			{
+				TxID:        "tx1",
+				BlockNumber: 3,
+				Events:      []blocks.Event{{Name: "Transfer", Payload: []byte(`{"from":"alice","to":"bob","value":500}`)}},
+			},
+			{
+				TxID:        "tx2",
+				BlockNumber: 3,
+				Events:      []blocks.Event{{Name: "Transfer", Payload: []byte(`{"from":"carol","to":"dave","value":5}`)}},
+			},
+		},
+	}
+}
+
+func newDispatcher(registry Registry, dlq DeadLetterQueue) *Dispatcher {
+	d := NewDispatcher(registry, dlq, nil, RetryPolicy{MaxAttempts: 3, InitialBackoff: time.Millisecond})
+	d.sleep = func(ctx context.Context, d time.Duration) error { return nil }
+	return d
+}
+
+// #########
+// TESTS
+// #########
+
+func TestDispatcherDelivers(t *testing.T) {
+	rec := new(recorder)
+	server := httptest.NewServer(rec)
+	defer server.Close()
+
+	registry := NewMemoryRegistry()
+	_, err := registry.Add(context.Background(), Subscription{ID: "s1", URL: server.URL, Secret: "secret", Accounts: []string{"bob"}})
+	require.NoError(t, err)
+
+	err = newDispatcher(registry, nil).HandleBlock(context.Background(), transferBlock())
+	require.NoError(t, err)
+
+	require.Len(t, rec.bodies, 1, "should only deliver events matching the account filter")
+	assert.True(t, Verify("secret", rec.bodies[0], rec.headers[0].Get(SignatureHeader)), "should sign the payload")
+	assert.Equal(t, "Transfer", rec.headers[0].Get("X-Token-Event"))
+
+	var payload Payload
+	require.NoError(t, json.Unmarshal(rec.bodies[0], &payload))
+	assert.Equal(t, "tx1-0", payload.DeliveryID)
+	assert.Equal(t, "alice", payload.From)
+	assert.Equal(t, "bob", payload.To)
+	assert.Equal(t, 500, payload.Value)
+}
+
+func TestDispatcherRetriesAndDeadLetters(t *testing.T) {
+	rec := &recorder{failures: 2}
+	server := httptest.NewServer(rec)
+	defer server.Close()
+
+	registry := NewMemoryRegistry()
+	_, err := registry.Add(context.Background(), Subscription{ID: "s1", URL: server.URL, MinAmount: 100})
+	require.NoError(t, err)
+
+	dlq := new(MemoryDeadLetterQueue)
+	err = newDispatcher(registry, dlq).HandleBlock(context.Background(), transferBlock())
+	require.NoError(t, err)
+	assert.Len(t, rec.bodies, 1, "should succeed on the third attempt")
+	assert.Empty(t, dlq.Deliveries())
+
+	rec.failures = 10
+	err = newDispatcher(registry, dlq).HandleBlock(context.Background(), transferBlock())
+	require.NoError(t, err)
+	require.Len(t, dlq.Deliveries(), 1, "should dead-letter after exhausting retries")
+	assert.Equal(t, 3, dlq.Deliveries()[0].Attempts)
+	assert.Contains(t, dlq.Deliveries()[0].LastError, "503")
+
+	rec.failures = 10
+	err = newDispatcher(registry, nil).HandleBlock(context.Background(), transferBlock())
+	assert.Error(t, err, "should fail the block without a dead-letter queue")
+}
+
+func TestMatches(t *testing.T) {
+	payload := Payload{Event: "Transfer", From: "alice", To: "bob", Value: 10}
+
+	assert.True(t, Matches(Subscription{}, payload), "empty filters should match")
+	assert.True(t, Matches(Subscription{Accounts: []string{"alice"}}, payload))
+	assert.False(t, Matches(Subscription{Accounts: []string{"carol"}}, payload))
+	assert.True(t, Matches(Subscription{EventTypes: []string{"Transfer"}}, payload))
+	assert.False(t, Matches(Subscription{EventTypes: []string{"Mint"}}, payload))
+	assert.False(t, Matches(Subscription{MinAmount: 11}, payload))
+}
+
+func TestHandler(t *testing.T) {
+	registry := NewMemoryRegistry()
+	handler := Handler(registry)
+
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, httptest.NewRequest(http.MethodPost, "/webhooks", strings.NewReader(`{"url":"ftp://x"}`)))
+	assert.Equal(t, http.StatusBadRequest, rr.Code, "should reject non-http urls")
+
+	rr = httptest.NewRecorder()
+	handler.ServeHTTP(rr, httptest.NewRequest(http.MethodPost, "/webhooks", strings.NewReader(`{"url":"https://example.com/hook","secret":"s"}`)))
+	require.Equal(t, http.StatusCreated, rr.Code)
+	var created Subscription
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &created))
+	assert.NotEmpty(t, created.ID, "should assign an ID")
+	assert.Empty(t, created.Secret, "should not echo the secret")
+
+	rr = httptest.NewRecorder()
+	handler.ServeHTTP(rr, httptest.NewRequest(http.MethodDelete, "/webhooks/"+created.ID, nil))
+	assert.Equal(t, http.StatusNoContent, rr.Code)
+
+	rr = httptest.NewRecorder()
+	handler.ServeHTTP(rr, httptest.NewRequest(http.MethodDelete, "/webhooks/"+created.ID, nil))
+	assert.Equal(t, http.StatusNotFound, rr.Code)
+}